@@ -43,6 +43,171 @@ const METABOLISM_PAGE_SIZE int = 1000
 // ページ境界での矛盾見逃しを防ぐために使用されます。
 const METABOLISM_OVERLAP_SIZE int = 100
 
+// CYPHER_QUERY_MAX_ROWS は、Cypherクエリ (QUERY_TYPE_CYCLER) で返却する最大行数です。
+// これを超える行は切り捨てられます。
+const CYPHER_QUERY_MAX_ROWS int = 1000
+
+// CYPHER_QUERY_TIMEOUT_MS は、Cypherクエリ (QUERY_TYPE_CYCLER) のタイムアウト（ミリ秒）です。
+const CYPHER_QUERY_TIMEOUT_MS uint64 = 10000
+
+// NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS は、自然言語クエリ (QUERY_TYPE_NATURAL_LANGUAGE) で
+// Cypher の生成・実行を試行する最大回数です。失敗時はエラー内容を LLM に渡して再生成します。
+const NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS int = 3
+
+// NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS は、自然言語クエリで回答生成時に LLM に渡す最大行数です。
+const NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS int = 100
+
+// DEFAULT_COT_ROUNDS は、推論型クエリ (QUERY_TYPE_GRAPH_COMPLETION_COT) で
+// 回答案の批評と追加検索を繰り返す最大ラウンド数のデフォルト値です。
+const DEFAULT_COT_ROUNDS int = 3
+
+// COT_MAX_FOLLOW_UP_QUERIES は、推論型クエリの1ラウンドで実行する追加検索クエリの最大数です。
+const COT_MAX_FOLLOW_UP_QUERIES int = 3
+
+// FEEDBACK_CORRECTION_CHUNK_SIZE は、フィードバックの訂正文を新しい知識として取り込む際のチャンクサイズ（文字数）です。
+const FEEDBACK_CORRECTION_CHUNK_SIZE int = 1000
+
+// FEEDBACK_CORRECTION_CHUNK_OVERLAP は、フィードバックの訂正文を取り込む際のチャンクのオーバーラップ（文字数）です。
+const FEEDBACK_CORRECTION_CHUNK_OVERLAP int = 100
+
+// HYBRID_RRF_K は、ハイブリッド検索 (QUERY_TYPE_HYBRID) の Reciprocal Rank Fusion の定数 k です。
+// 各ランキングの順位 r に対して w / (k + r) を加算します。k が大きいほど上位と下位の差が小さくなります。
+const HYBRID_RRF_K float64 = 60
+
+// HYBRID_CANDIDATE_MULTIPLIER は、ハイブリッド検索で各ランキングから取得する候補数の倍率です（chunk_topk × 倍率）。
+const HYBRID_CANDIDATE_MULTIPLIER int = 3
+
+// DEFAULT_HYBRID_WEIGHT_* は、ハイブリッド検索の各ランキングのデフォルトの重みです。
+// FTS の3レイヤーは同じチャンクにヒットしやすいため、字句一致の合計がベクトル類似度を上回る程度に設定しています。
+const (
+	DEFAULT_HYBRID_WEIGHT_VECTOR          float64 = 1.0
+	DEFAULT_HYBRID_WEIGHT_FTS_NOUNS       float64 = 0.5
+	DEFAULT_HYBRID_WEIGHT_FTS_NOUNS_VERBS float64 = 0.5
+	DEFAULT_HYBRID_WEIGHT_FTS_KEYWORDS    float64 = 0.5
+	DEFAULT_HYBRID_WEIGHT_GRAPH           float64 = 0.5
+)
+
+// DEFAULT_VECTOR_SEARCH_EFS は、ベクトルインデックス (HNSW) を使った近似最近傍検索の探索幅 efs のデフォルト値です。
+// 大きいほど再現率が上がり、検索は遅くなります。
+const DEFAULT_VECTOR_SEARCH_EFS int = 200
+
+// VECTOR_SEARCH_OVERSAMPLING は、ベクトルインデックスで検索する際に取得する候補数の倍率です（topk × 倍率）。
+// インデックスは memory_group を区別しないため、多めに取得してから memory_group で絞り込みます。
+const VECTOR_SEARCH_OVERSAMPLING int = 4
+
+// DEFAULT_CHUNK_MAX_RETRIES は、Absorb のグラフ抽出に失敗したチャンクを再試行する回数のデフォルト値です。
+const DEFAULT_CHUNK_MAX_RETRIES int = 2
+
+// CHUNK_RETRY_BACKOFF_MS は、グラフ抽出の再試行までの待機時間（ミリ秒）です。再試行のたびに2倍になります。
+const CHUNK_RETRY_BACKOFF_MS int = 1000
+
+// CHECKPOINT_RETENTION_HOURS は、失敗した Absorb のチェックポイント（チャンク・抽出済みグラフ）を保持する時間です。
+// この時間内に同じ入力で Absorb を再実行すると、完了済みのチャンクから再開します。
+const CHECKPOINT_RETENTION_HOURS int = 168
+
+// EMBEDDING_MIGRATION_BATCH_SIZE は、埋め込みモデルの移行で1回に読み込んで再ベクトル化する行数です。
+const EMBEDDING_MIGRATION_BATCH_SIZE int = 100
+
+// ENTITY_RESOLUTION_SIMILARITY_THRESHOLD は、Absorb のエンティティ解決で、名前の埋め込みのコサイン類似度がこの値以上のエンティティを同一とみなす閾値です。
+// 名前を正規化して一致するエンティティは、類似度に関わらず同一とみなします。
+const ENTITY_RESOLUTION_SIMILARITY_THRESHOLD float64 = 0.92
+
+// ENTITY_RESOLUTION_TOP_K は、Absorb のエンティティ解決で、新しいエンティティごとに照合する既存のエンティティ数です。
+const ENTITY_RESOLUTION_TOP_K int = 5
+
+// ENTITY_RESOLUTION_MAX_ALIASES は、エンティティに記録する別名（aliases）の最大数です。
+const ENTITY_RESOLUTION_MAX_ALIASES int = 50
+
+// ENTITY_RESOLUTION_MAX_BLOCK_SIZE は、Absorb のエンティティ解決で、バッチ内の比較相手を絞り込むブロッキングキー（名前の単語）ごとのグループ数の上限です。
+// "株式会社" のようなありふれた語で比較の対象が増えすぎないよう、この数に達したキーにはそれ以上グループを登録しません。
+const ENTITY_RESOLUTION_MAX_BLOCK_SIZE int = 50
+
+// COMMUNITY_RESOLUTION は、コミュニティ検出（Louvain 法）の解像度パラメータです。
+// 大きいほど細かいコミュニティに分かれます。
+const COMMUNITY_RESOLUTION float64 = 1.0
+
+// COMMUNITY_MAX_LEVELS は、コミュニティの階層の最大数です。
+const COMMUNITY_MAX_LEVELS int = 3
+
+// COMMUNITY_MIN_SIZE は、コミュニティとして保存する最小のノード数です。これより小さいクラスタは保存しません。
+const COMMUNITY_MIN_SIZE int = 3
+
+// COMMUNITY_MAX_SUMMARIES は、コミュニティの更新1回で新たに LLM で要約するコミュニティの最大数です。
+// 所属ノードと内部のエッジが変わっていないコミュニティは、以前の要約を再利用するため数えません。
+const COMMUNITY_MAX_SUMMARIES int = 100
+
+// COMMUNITY_SUMMARY_MAX_TRIPLES は、コミュニティを要約する際に LLM に渡すトリプルの最大数です（太い順）。
+const COMMUNITY_SUMMARY_MAX_TRIPLES int = 50
+
+// GLOBAL_QUERY_MAP_BATCH_CHARS は、グローバル検索 (QUERY_TYPE_GLOBAL) の map で1回に LLM に渡すコミュニティ要約の最大文字数です。
+const GLOBAL_QUERY_MAP_BATCH_CHARS int = 12000
+
+// GLOBAL_QUERY_MAX_POINTS は、グローバル検索の reduce で回答に使用する要点の最大数です（スコアの高い順）。
+const GLOBAL_QUERY_MAX_POINTS int = 30
+
+// DEFAULT_PATH_K は、エンティティ間の経路検索で返す経路数のデフォルト値です。
+const DEFAULT_PATH_K int = 3
+
+// DEFAULT_PATH_MAX_HOPS は、エンティティ間の経路検索で許容するホップ数（エッジ数）のデフォルト値です。
+const DEFAULT_PATH_MAX_HOPS int = 4
+
+// PATH_MAX_HOPS_LIMIT は、エンティティ間の経路検索で指定できるホップ数の上限です。
+const PATH_MAX_HOPS_LIMIT int = 6
+
+// PATH_MAX_EXPLORED_NODES は、エンティティ間の経路検索で、起点側・終点側のそれぞれから探索するノード数の上限です。
+// 密なグラフで探索範囲が爆発しないよう、これを超えた時点でその側の探索を打ち切ります。
+const PATH_MAX_EXPLORED_NODES int = 5000
+
+// GRAPH_BROWSE_DEFAULT_LIMIT は、グラフ閲覧 API（エンティティの検索・隣接ノードの一覧）で1ページに返す件数のデフォルト値です。
+const GRAPH_BROWSE_DEFAULT_LIMIT int = 20
+
+// GRAPH_SUBGRAPH_DEFAULT_DEPTH は、エンティティを中心としたサブグラフ（エゴネットワーク）の深さのデフォルト値です。
+const GRAPH_SUBGRAPH_DEFAULT_DEPTH int = 1
+
+// GRAPH_SUBGRAPH_DEFAULT_MAX_NODES は、エンティティを中心としたサブグラフに含めるノード数の上限のデフォルト値です。
+// 中心から近い順（同じ深さでは太いエッジで接続された順）に含め、上限に達した時点で打ち切ります。
+const GRAPH_SUBGRAPH_DEFAULT_MAX_NODES int = 100
+
+// PROVIDER_MAX_RETRIES は、チャットモデル・埋め込みモデルの呼び出しがレート制限（429）やサーバーエラー（5xx）で失敗した場合に、
+// 同じモデルで再試行する回数です。再試行しても失敗した場合は、次のフォールバック先のモデルを使用します。
+const PROVIDER_MAX_RETRIES int = 3
+
+// PROVIDER_RETRY_BACKOFF_MS は、プロバイダーの呼び出しを再試行するまでの待機時間（ミリ秒）です。再試行のたびに2倍になります。
+const PROVIDER_RETRY_BACKOFF_MS int = 500
+
+// PROVIDER_RETRY_MAX_BACKOFF_MS は、プロバイダーの呼び出しを再試行するまでの待機時間の上限（ミリ秒）です。
+const PROVIDER_RETRY_MAX_BACKOFF_MS int = 30000
+
+// PROVIDER_RETRY_BUDGET は、1回のオペレーション（Absorb / Memify / Query など）全体で、プロバイダーの呼び出しを再試行できる回数の上限です。
+// 障害中のプロバイダーに対して再試行を繰り返し、オペレーションが長時間終わらなくなることを防ぎます。
+const PROVIDER_RETRY_BUDGET int = 50
+
+// CIRCUIT_BREAKER_FAILURE_THRESHOLD は、プロバイダーのサーキットブレーカーが開く（呼び出しを止める）までの連続失敗回数です。
+const CIRCUIT_BREAKER_FAILURE_THRESHOLD int = 5
+
+// CIRCUIT_BREAKER_COOLDOWN_SEC は、サーキットブレーカーが開いてから、試しに呼び出しを再開するまでの秒数です。
+const CIRCUIT_BREAKER_COOLDOWN_SEC int = 30
+
+// MAX_CHAT_MODEL_FALLBACKS は、Cube に設定できるフォールバック先のチャットモデルの最大数です。
+const MAX_CHAT_MODEL_FALLBACKS int = 5
+
+// DEFAULT_JOB_WORKERS は、非同期ジョブ（Absorb / Memify）を並行して実行するワーカー数のデフォルト値です。
+const DEFAULT_JOB_WORKERS int = 2
+
+// JOB_POLL_INTERVAL_SECONDS は、ワーカーが jobs テーブルに実行待ちのジョブがないか確認する間隔（秒）です。
+// ジョブの登録時はすぐに通知されるため、これは他のサーバーで登録されたジョブや取りこぼしへの備えです。
+const JOB_POLL_INTERVAL_SECONDS int = 5
+
+// JOB_PROGRESS_UPDATE_INTERVAL_MS は、ジョブの進捗（最後のイベント）を DB に書き込む最短間隔（ミリ秒）です。
+const JOB_PROGRESS_UPDATE_INTERVAL_MS int = 1000
+
+// JOB_MAX_ATTEMPTS は、サーバーの再起動で中断されたジョブを再実行する最大回数です。
+// これを超えたジョブは、再起動のたびに同じ箇所で落ちている可能性があるため failed にします。
+const JOB_MAX_ATTEMPTS int = 3
+
+// JOB_FILES_DIR_NAME は、非同期 Absorb の取り込み対象ファイルを実行まで保管するディレクトリ名です（DB_DIR_PATH 直下）。
+const JOB_FILES_DIR_NAME = "jobs"
+
 type DbInfo struct {
 	Host     string
 	Port     string
@@ -0,0 +1,21 @@
+package jobstatus
+
+// JobStatus は非同期ジョブ（Absorb / Memify）の状態です。
+type JobStatus string
+
+const (
+	QUEUED    JobStatus = "queued"    // 実行待ち
+	RUNNING   JobStatus = "running"   // 実行中
+	SUCCEEDED JobStatus = "succeeded" // 正常終了
+	FAILED    JobStatus = "failed"    // 異常終了
+	CANCELLED JobStatus = "cancelled" // キャンセル
+)
+
+func (s JobStatus) Val() string {
+	return string(s)
+}
+
+// IsFinished は、ジョブが終了状態（succeeded / failed / cancelled）かどうかを返します。
+func (s JobStatus) IsFinished() bool {
+	return s == SUCCEEDED || s == FAILED || s == CANCELLED
+}
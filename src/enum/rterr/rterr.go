@@ -60,6 +60,7 @@ var (
 	ValidRegex            = err{code: 45, msg: "This field must be a valid regex."}
 	ValidDetailedRuleJson = err{code: 46, msg: "This field must be correct detailed-rule-json format."}
 	ValidCttsHostIdent    = err{code: 47, msg: "This field must be a valid pair of ctts_host and ctts_ident."}
+	Conflict              = err{code: 48, msg: "Conflict."}
 )
 
 func (e *err) Code() uint16 {
@@ -70,6 +70,8 @@ func AutoMigrateNDb(db *gorm.DB) error {
 			&model.CubeLineage{},
 			&model.Export{},
 			&model.BurnedKey{},
+			&model.CubeQuery{},
+			&model.Job{},
 		)
 	})
 	return err
@@ -1,6 +1,7 @@
 package rt
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,7 +16,11 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/t-kawata/mycute/config"
 	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/lib/eventbus"
 	"github.com/t-kawata/mycute/lib/s3client"
+	"github.com/t-kawata/mycute/mode/rt/rtbl"
+	"github.com/t-kawata/mycute/mode/rt/rtjob"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
 	"github.com/t-kawata/mycute/pkg/cuber"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 
@@ -41,6 +46,7 @@ type RTFlags struct {
 	CorsOnAtRT                bool
 	DBDirPath                 string
 	CuberConfig               types.CuberConfig
+	JobWorkers                int
 }
 
 func MainOfRT() {
@@ -84,6 +90,8 @@ func MainOfRT() {
 	DB_DIR_PATH := os.Getenv("DB_DIR_PATH")
 	CUBER_STORAGE_IDLE_TIMEOUT_MINUTES := os.Getenv("CUBER_STORAGE_IDLE_TIMEOUT_MINUTES")
 	CUBER_CRYPTO_SECRET_KEY := os.Getenv("CUBER_CRYPTO_SECRET_KEY")
+	CUBER_VECTOR_SEARCH_EFS := os.Getenv("CUBER_VECTOR_SEARCH_EFS") // 任意（未指定の場合はデフォルト）
+	CUBER_JOB_WORKERS := os.Getenv("CUBER_JOB_WORKERS")             // 任意（未指定の場合はデフォルト）
 	if CUBER_S3_USE_LOCAL == "" {
 		l.Warn(fmt.Sprintf("Failed to read CUBER_S3_USE_LOCAL from env file (%s).", flgs.Dotenv))
 		return
@@ -159,6 +167,13 @@ func MainOfRT() {
 		StorageIdleTimeoutMinutes:    flgs.StorageIdleTimeoutMinutes,
 		Logger:                       l,
 	}
+	if CUBER_VECTOR_SEARCH_EFS != "" {
+		flgs.CuberConfig.VectorSearchEfs = common.StrToInt(CUBER_VECTOR_SEARCH_EFS)
+	}
+	flgs.JobWorkers = config.DEFAULT_JOB_WORKERS
+	if CUBER_JOB_WORKERS != "" {
+		flgs.JobWorkers = common.StrToInt(CUBER_JOB_WORKERS)
+	}
 
 	// CuberService Initialization (Application Singleton)
 	cuberService, err := cuber.NewCuberService(flgs.CuberConfig)
@@ -207,7 +222,35 @@ func MainOfRT() {
 	if len(sk) == 0 {
 		sk = config.DEFAULT_SKEY
 	}
-	MapRequest(r, l, env, hc, &hn, db, &sk, &flgs, s3c, cuberService)
+
+	// 非同期ジョブ（Absorb / Memify）のワーカー
+	// 前回の停止で中断されたジョブを実行待ちに戻してから起動する
+	jobQueue := rtjob.NewJobQueue(flgs.JobWorkers, time.Duration(config.JOB_POLL_INTERVAL_SECONDS)*time.Second, l)
+	jobUtil := &rtutil.RtUtil{
+		Logger:          l,
+		Env:             env,
+		Client:          hc,
+		Hostname:        &hn,
+		DB:              db,
+		SKey:            sk,
+		CuberCryptoSkey: flgs.CuberCryptoSKey,
+		S3c:             s3c,
+		DBDirPath:       &flgs.DBDirPath,
+		CuberService:    cuberService,
+		EventBus:        eventbus.New(), // ジョブの実行時にジョブごとのものに置き換えられる
+		JobQueue:        jobQueue,
+	}
+	if err := rtbl.RecoverJobs(jobUtil); err != nil {
+		l.Error(fmt.Sprintf("Failed to recover jobs: %s", err.Error()))
+		return
+	}
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	jobQueue.Start(jobCtx, func(ctx context.Context) bool {
+		return rtbl.RunNextJob(ctx, jobUtil)
+	})
+
+	MapRequest(r, l, env, hc, &hn, db, &sk, &flgs, s3c, cuberService, jobQueue)
 
 	err = r.Run(fmt.Sprintf(":%d", config.REST_PORT))
 	if err != nil {
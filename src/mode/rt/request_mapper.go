@@ -8,6 +8,7 @@ import (
 	"github.com/t-kawata/mycute/lib/httpclient"
 	"github.com/t-kawata/mycute/lib/s3client"
 	"github.com/t-kawata/mycute/mode/rt/rthandler/hv1"
+	"github.com/t-kawata/mycute/mode/rt/rtjob"
 	"github.com/t-kawata/mycute/mode/rt/rtmiddleware"
 	"github.com/t-kawata/mycute/mode/rt/rtutil"
 	"github.com/t-kawata/mycute/pkg/cuber"
@@ -15,14 +16,14 @@ import (
 	"gorm.io/gorm"
 )
 
-func MapRequest(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, flgs *RTFlags, s3c *s3client.S3Client, cuberService *cuber.CuberService) {
+func MapRequest(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, flgs *RTFlags, s3c *s3client.S3Client, cuberService *cuber.CuberService, jobQueue *rtjob.JobQueue) {
 	rtutil.RegisterValidations()
 
 	/**********************
 	 * v1 mapping
 	 **********************/
 	v1 := r.Group("/v1")
-	v1.Use(rtmiddleware.AuthMiddleware(r, l, env, hc, hn, db, sk, &flgs.CuberCryptoSKey, s3c, &flgs.DBDirPath, cuberService))
+	v1.Use(rtmiddleware.AuthMiddleware(r, l, env, hc, hn, db, sk, &flgs.CuberCryptoSKey, s3c, &flgs.DBDirPath, cuberService, jobQueue))
 	{
 
 		// ChatModel
@@ -228,6 +229,14 @@ func MapRequest(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.Ht
 			}
 			hv1.QueryCube(c, u, ju)
 		})
+		cubes.POST("/feedback", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.FeedbackCube(c, u, ju)
+		})
 		cubes.PUT("/memify", func(c *gin.Context) {
 			u, ju, ok := GetUtil(c)
 			if !ok {
@@ -236,6 +245,118 @@ func MapRequest(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.Ht
 			}
 			hv1.MemifyCube(c, u, ju)
 		})
+		cubes.PUT("/cypher", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.SetCubeCypher(c, u, ju)
+		})
+		cubes.PUT("/fallbacks", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.SetCubeChatModelFallbacks(c, u, ju)
+		})
+		cubes.GET("/prompts", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.ListCubePrompts(c, u, ju)
+		})
+		cubes.PUT("/prompts", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.SetCubePrompt(c, u, ju)
+		})
+		cubes.DELETE("/prompts", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.ResetCubePrompt(c, u, ju)
+		})
+		cubes.GET("/ontology", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.GetCubeOntology(c, u, ju)
+		})
+		cubes.PUT("/ontology", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.SetCubeOntology(c, u, ju)
+		})
+		cubes.DELETE("/ontology", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.DeleteCubeOntology(c, u, ju)
+		})
+		cubes.GET("/graph/entities", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.SearchCubeGraphEntities(c, u, ju)
+		})
+		cubes.GET("/graph/entity", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.GetCubeGraphEntity(c, u, ju)
+		})
+		cubes.GET("/graph/neighbors", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.ListCubeGraphNeighbors(c, u, ju)
+		})
+		cubes.GET("/graph/subgraph", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.GetCubeGraphSubgraph(c, u, ju)
+		})
+		cubes.POST("/graph/paths", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.FindCubeGraphPaths(c, u, ju)
+		})
+		cubes.POST("/embeddings/migrate", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.MigrateCubeEmbeddings(c, u, ju)
+		})
 		cubes.DELETE("/delete", func(c *gin.Context) {
 			u, ju, ok := GetUtil(c)
 			if !ok {
@@ -244,6 +365,49 @@ func MapRequest(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.Ht
 			}
 			hv1.DeleteCube(c, u, ju)
 		})
+		cubes.GET("/data", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.ListCubeData(c, u, ju)
+		})
+		cubes.DELETE("/data/:data_id", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.DeleteCubeData(c, u, ju)
+		})
+		cubes.GET("/operations", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.ListOperations(c, u, ju)
+		})
+		cubes.POST("/operations/:operation_id/cancel", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.CancelOperation(c, u, ju)
+		})
+
+		// Jobs
+		jobs := v1.Group("/jobs")
+		jobs.GET("/:job_id", func(c *gin.Context) {
+			u, ju, ok := GetUtil(c)
+			if !ok {
+				c.JSON(http.StatusForbidden, nil)
+				return
+			}
+			hv1.GetJob(c, u, ju)
+		})
 
 	}
 
@@ -63,6 +63,10 @@ func NotFoundCustomMsg[T any](c *gin.Context, res *T, msg string) bool {
 	return errRes(c, res, http.StatusNotFound, "system", rterr.NotFound.Code(), msg)
 }
 
+func ConflictCustomMsg[T any](c *gin.Context, res *T, msg string) bool {
+	return errRes(c, res, http.StatusConflict, "system", rterr.Conflict.Code(), msg)
+}
+
 func InternalServerError[T any](c *gin.Context, res *T) bool {
 	return errRes(c, res, http.StatusInternalServerError, "system", rterr.InternalServerError.Code(), rterr.InternalServerError.Msg())
 }
@@ -2,7 +2,7 @@ package rtbl
 
 import (
 	"bytes"
-	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/enum/jobstatus"
 	"github.com/t-kawata/mycute/lib/common"
 	"github.com/t-kawata/mycute/lib/mycrypto"
 	"github.com/t-kawata/mycute/mode/rt/rtreq"
@@ -21,7 +22,9 @@ import (
 	"github.com/t-kawata/mycute/mode/rt/rtutil"
 	"github.com/t-kawata/mycute/model"
 	"github.com/t-kawata/mycute/pkg/cuber"
+	"github.com/t-kawata/mycute/pkg/cuber/checkpoint"
 	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 	"github.com/t-kawata/mycute/pkg/cuber/utils"
@@ -70,7 +73,53 @@ func getCube(u *rtutil.RtUtil, id uint, apxID uint, vdrID uint) (*model.Cube, er
 	return &cube, nil
 }
 
-func fetchChatModelConfig(u *rtutil.RtUtil, chatModelID uint, apxID uint, vdrID uint) (types.ChatModelConfig, error) {
+// fetchChatModelConfig は、チャットモデルの設定を取得します。
+// Cube にフォールバック先のチャットモデルが設定されている場合は、その設定（設定順）を Fallbacks に含めます。
+// 削除されたチャットモデルと、指定されたチャットモデルと同じものは Fallbacks に含めません。
+func fetchChatModelConfig(u *rtutil.RtUtil, cube *model.Cube, chatModelID uint, apxID uint, vdrID uint) (types.ChatModelConfig, error) {
+	chatConf, err := loadChatModelConfig(u, chatModelID, apxID, vdrID)
+	if err != nil {
+		return types.ChatModelConfig{}, err
+	}
+	fallbackIDs, err := getChatModelFallbackIDs(cube)
+	if err != nil {
+		return types.ChatModelConfig{}, fmt.Errorf("failed to parse chat model fallbacks: %w", err)
+	}
+	for _, id := range fallbackIDs {
+		if id == chatModelID {
+			continue
+		}
+		if len(chatConf.Fallbacks) >= appconfig.MAX_CHAT_MODEL_FALLBACKS {
+			break
+		}
+		fallback, err := loadChatModelConfig(u, id, apxID, vdrID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return types.ChatModelConfig{}, err
+		}
+		chatConf.Fallbacks = append(chatConf.Fallbacks, fallback)
+	}
+	return chatConf, nil
+}
+
+// getChatModelFallbackIDs は、Cube に設定されたフォールバック先のチャットモデルのIDを設定順に返します。
+func getChatModelFallbackIDs(cube *model.Cube) ([]uint, error) {
+	if len(cube.ChatModelFallbacks) == 0 {
+		return []uint{}, nil
+	}
+	ids, err := common.ParseDatatypesJson[[]uint](&cube.ChatModelFallbacks)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		return []uint{}, nil
+	}
+	return ids, nil
+}
+
+func loadChatModelConfig(u *rtutil.RtUtil, chatModelID uint, apxID uint, vdrID uint) (types.ChatModelConfig, error) {
 	var chatModel model.ChatModel
 	if err := u.DB.Where("id = ? AND apx_id = ? AND vdr_id = ?", chatModelID, apxID, vdrID).First(&chatModel).Error; err != nil {
 		return types.ChatModelConfig{}, err
@@ -89,6 +138,46 @@ func fetchChatModelConfig(u *rtutil.RtUtil, chatModelID uint, apxID uint, vdrID
 	}, nil
 }
 
+// recordCubeUsage は、Absorb / Memify のトークン使用量を CubeModelStat と CubeContributor に加算します。
+// どちらも MemoryGroup を含む階層構造（Cube > MemoryGroup > モデル）で集計します。
+// tx: 呼び出し元のトランザクション（Limit 更新と同じトランザクションで実行する）
+func recordCubeUsage(tx *gorm.DB, cube *model.Cube, memoryGroup string, actionType types.ActionType, contributorName string, usage types.TokenUsage) error {
+	// usage.Details は map[string]TokenUsage
+	for modelName, detail := range usage.Details {
+		var ms model.CubeModelStat
+		if err := tx.Where("cube_id = ? AND memory_group = ? AND model_name = ? AND action_type = ? AND apx_id = ? AND vdr_id = ?",
+			cube.ID, memoryGroup, modelName, actionType, cube.ApxID, cube.VdrID).
+			FirstOrCreate(&ms, model.CubeModelStat{
+				CubeID: cube.ID, MemoryGroup: memoryGroup, ModelName: modelName, ActionType: string(actionType),
+				ApxID: cube.ApxID, VdrID: cube.VdrID,
+			}).Error; err != nil {
+			return err
+		}
+		ms.InputTokens += detail.InputTokens
+		ms.OutputTokens += detail.OutputTokens
+		ms.EmbeddingCacheHits += detail.EmbeddingCacheHits
+		ms.EmbeddingCacheMisses += detail.EmbeddingCacheMisses
+		if err := tx.Save(&ms).Error; err != nil {
+			return err
+		}
+		var cc model.CubeContributor
+		if err := tx.Where("cube_id = ? AND memory_group = ? AND contributor_name = ? AND model_name = ? AND apx_id = ? AND vdr_id = ?",
+			cube.ID, memoryGroup, contributorName, modelName, cube.ApxID, cube.VdrID).
+			FirstOrCreate(&cc, model.CubeContributor{
+				CubeID: cube.ID, MemoryGroup: memoryGroup, ContributorName: contributorName, ModelName: modelName,
+				ApxID: cube.ApxID, VdrID: cube.VdrID,
+			}).Error; err != nil {
+			return err
+		}
+		cc.InputTokens += detail.InputTokens
+		cc.OutputTokens += detail.OutputTokens
+		if err := tx.Save(&cc).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SearchCubes は条件に一致するCubeを検索し、詳細情報を返します。
 func SearchCubes(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SearchCubesReq, res *rtres.SearchCubesRes) bool {
 	cubes := []model.Cube{}
@@ -120,23 +209,28 @@ func SearchCubes(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq
 			lineageRes = []rtres.LineageRes{}
 			memoryGroupsRes = []rtres.MemoryGroupStatsRes{}
 		}
+		fallbackIDs, err := getChatModelFallbackIDs(&cube)
+		if err != nil {
+			fallbackIDs = []uint{}
+		}
 		results = append(results, rtres.SearchCubesResData{
 			Cube: rtres.SearchCubesResCube{
-				ID:                 cube.ID,
-				UUID:               cube.UUID,
-				Name:               cube.Name,
-				Description:        cube.Description,
-				ExpireAt:           common.ParseDatetimeToStr(cube.ExpireAt),
-				Permissions:        permissions,
-				SourceExportID:     cube.SourceExportID,
-				ApxID:              cube.ApxID,
-				VdrID:              cube.VdrID,
-				CreatedAt:          common.ParseDatetimeToStr(&cube.CreatedAt),
-				UpdatedAt:          common.ParseDatetimeToStr(&cube.UpdatedAt),
-				EmbeddingProvider:  cube.EmbeddingProvider,
-				EmbeddingBaseURL:   cube.EmbeddingBaseURL,
-				EmbeddingModel:     cube.EmbeddingModel,
-				EmbeddingDimension: cube.EmbeddingDimension,
+				ID:                   cube.ID,
+				UUID:                 cube.UUID,
+				Name:                 cube.Name,
+				Description:          cube.Description,
+				ExpireAt:             common.ParseDatetimeToStr(cube.ExpireAt),
+				Permissions:          permissions,
+				SourceExportID:       cube.SourceExportID,
+				ApxID:                cube.ApxID,
+				VdrID:                cube.VdrID,
+				CreatedAt:            common.ParseDatetimeToStr(&cube.CreatedAt),
+				UpdatedAt:            common.ParseDatetimeToStr(&cube.UpdatedAt),
+				EmbeddingProvider:    cube.EmbeddingProvider,
+				EmbeddingBaseURL:     cube.EmbeddingBaseURL,
+				EmbeddingModel:       cube.EmbeddingModel,
+				EmbeddingDimension:   cube.EmbeddingDimension,
+				ChatModelFallbackIDs: fallbackIDs,
 			},
 			Lineage:      lineageRes,
 			MemoryGroups: memoryGroupsRes,
@@ -174,19 +268,24 @@ func GetCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Get
 		lineageRes = []rtres.LineageRes{}
 		memoryGroupsRes = []rtres.MemoryGroupStatsRes{}
 	}
+	fallbackIDs, err := getChatModelFallbackIDs(cube)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, "Failed to parse chat model fallbacks.")
+	}
 	data := rtres.GetCubeResData{
 		Cube: rtres.GetCubeResCube{
-			ID:             cube.ID,
-			UUID:           cube.UUID,
-			Name:           cube.Name,
-			Description:    cube.Description,
-			ExpireAt:       common.ParseDatetimeToStr(cube.ExpireAt),
-			Permissions:    permissions,
-			SourceExportID: cube.SourceExportID,
-			ApxID:          cube.ApxID,
-			VdrID:          cube.VdrID,
-			CreatedAt:      common.ParseDatetimeToStr(&cube.CreatedAt),
-			UpdatedAt:      common.ParseDatetimeToStr(&cube.UpdatedAt),
+			ID:                   cube.ID,
+			UUID:                 cube.UUID,
+			Name:                 cube.Name,
+			Description:          cube.Description,
+			ExpireAt:             common.ParseDatetimeToStr(cube.ExpireAt),
+			Permissions:          permissions,
+			SourceExportID:       cube.SourceExportID,
+			ApxID:                cube.ApxID,
+			VdrID:                cube.VdrID,
+			CreatedAt:            common.ParseDatetimeToStr(&cube.CreatedAt),
+			UpdatedAt:            common.ParseDatetimeToStr(&cube.UpdatedAt),
+			ChatModelFallbackIDs: fallbackIDs,
 		},
 		Lineage:      lineageRes,
 		MemoryGroups: memoryGroupsRes,
@@ -223,6 +322,7 @@ func CreateCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		ExportLimit: 0, RekeyLimit: 0, GenKeyLimit: 0,
 		AbsorbLimit: 0, MemifyLimit: 0, QueryLimit: 0,
 		AllowStats:        true,
+		AllowCypher:       false, // 任意の Cypher クエリは明示的に許可した場合のみ実行できる（SetCubeCypher）
 		MemifyConfigLimit: map[string]any{},
 		QueryTypeLimit:    []uint8{},
 	}
@@ -260,8 +360,34 @@ func CreateCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 	return OK(c, &data, res)
 }
 
+// saveAbsorbInputs は、Absorb の取り込み対象（content / file）を dir に保存し、保存したファイルのパスを返します。
+// テキスト（content）とアップロードファイル（file）を同じディレクトリに配置します。
+// アップロードファイルは元のファイル名（拡張子）を維持し、抽出器の選択と Data.Name に使用します。
+func saveAbsorbInputs(c *gin.Context, dir string, req *rtreq.AbsorbCubeReq) ([]string, error) {
+	var filePaths []string
+	if req.Content != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("Failed to create dir: %s", err.Error())
+		}
+		contentFile := filepath.Join(dir, fmt.Sprintf("%s.txt", *common.GenUUID()))
+		if err := os.WriteFile(contentFile, []byte(req.Content), 0644); err != nil {
+			return nil, fmt.Errorf("Failed to write content file: %s", err.Error())
+		}
+		filePaths = append(filePaths, contentFile)
+	}
+	for i, fh := range req.Files {
+		// 同名ファイルの衝突を避けるため、連番のサブディレクトリに保存する
+		uploadedFile := filepath.Join(dir, fmt.Sprintf("%d", i), filepath.Base(fh.Filename))
+		if err := c.SaveUploadedFile(fh, uploadedFile); err != nil {
+			return nil, fmt.Errorf("Failed to save uploaded file '%s': %s", fh.Filename, err.Error())
+		}
+		filePaths = append(filePaths, uploadedFile)
+	}
+	return filePaths, nil
+}
+
 // AbsorbCube はコンテンツをCubeに取り込みます。
-// SSEストリーミングモードに対応。
+// SSEストリーミングモードと、ジョブとしての非同期実行（async）に対応。
 func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.AbsorbCubeReq, res *rtres.AbsorbCubeRes) bool {
 	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
 	// 1. Cubeの取得と権限チェック
@@ -287,12 +413,20 @@ func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		}
 		shouldUpdateLimit = true
 	}
+	// 非同期実行の場合はジョブとして登録して終了
+	if req.Async {
+		return submitAbsorbJob(c, u, ids, cube, &perm, req, res)
+	}
 	// 3. 一時ファイル作成
-	tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("%s.txt", *common.GenUUID()))
-	if err := os.WriteFile(tempFile, []byte(req.Content), 0644); err != nil {
-		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to write temp file: %s", err.Error()))
+	tempDir, err := os.MkdirTemp("", "cuber-absorb-*")
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create temp dir: %s", err.Error()))
+	}
+	defer os.RemoveAll(tempDir) // 関数終了時に削除
+	filePaths, err := saveAbsorbInputs(c, tempDir, req)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
 	}
-	defer os.Remove(tempFile) // 関数終了時に削除
 	// 4. Cuber 呼び出し
 	// CuberServiceの初期化は不要 (Singleton in RtUtil)
 	if u.CuberService == nil {
@@ -309,11 +443,17 @@ func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to decrypt embedding API key: %s", err.Error()))
 	}
 	// Fetch Chat Model Config
-	chatConf, err := fetchChatModelConfig(u, req.ChatModelID, *ids.ApxID, *ids.VdrID)
+	chatConf, err := fetchChatModelConfig(u, cube, req.ChatModelID, *ids.ApxID, *ids.VdrID)
 	if err != nil {
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to fetch chat model: %s", err.Error()))
 	}
 
+	// オペレーションとして登録（POST /v1/cubes/operations/:operation_id/cancel でキャンセル可能）
+	ctx, operationID, finishOperation, err := startOperation(c, u, ids, types.ACTION_TYPE_ABSORB, cube.UUID, req.MemoryGroup)
+	if err != nil {
+		return startOperationFailed(c, res, err)
+	}
+	defer finishOperation()
 	// 5. ストリーミング設定
 	var streamWriter *rtstream.StreamWriter
 	if req.Stream {
@@ -326,6 +466,9 @@ func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		// ストリーム送信ゴルーチン
 		go func() {
 			defer streamWriter.Done() // ゴルーチン終了時にDoneを呼び出す
+			// 最初のイベントでオペレーションIDを通知（キャンセルに使用）
+			fmt.Fprint(c.Writer, rtstream.CreateSSEOperationEvent(operationID))
+			c.Writer.Flush()
 			ticker := time.NewTicker(streamWriter.MinDelay())
 			defer ticker.Stop()
 			for {
@@ -357,8 +500,6 @@ func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 	dataCh := make(chan event.StreamEvent)
 	resultCh := make(chan AbsorbResult, 1)
 	isEn := req.IsEn
-	ctx, cancel := context.WithCancel(c.Request.Context())
-	defer cancel()
 	// MemoryGroup 設定を UPSERT
 	memoryGroupConfig := &storage.MemoryGroupConfig{
 		ID:                         req.MemoryGroup,
@@ -378,10 +519,12 @@ func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to upsert memory group config: %s", upsertErr.Error()))
 	}
 	go func() {
-		u, e := u.CuberService.Absorb(ctx, u.EventBus, cubeDbFilePath, req.MemoryGroup, []string{tempFile},
+		u, e := u.CuberService.Absorb(ctx, u.EventBus, cubeDbFilePath, req.MemoryGroup, filePaths, req.SourceID,
 			types.CognifyConfig{
-				ChunkSize:    req.ChunkSize,
-				ChunkOverlap: req.ChunkOverlap,
+				ChunkSize:           req.ChunkSize,
+				ChunkOverlap:        req.ChunkOverlap,
+				ChunkMaxRetries:     req.ChunkMaxRetries,
+				MaxFailedChunkRatio: req.MaxFailedChunkRatio,
 			},
 			embeddingConfig,
 			chatConf,
@@ -433,10 +576,11 @@ AbsorbLoop:
 			err = result.Err
 			// Close <working> tag if it was opened
 			if req.Stream && workingTagSent {
-				streamWriter.Write(WORKING_TAG_CLOSE)
+				streamWriter.Write(common.TOpe(err != nil && cuber.IsOperationCancelled(ctx), WORKING_TAG_CLOSE_ABORT, WORKING_TAG_CLOSE))
 			}
 			break AbsorbLoop
-		case <-ctx.Done():
+		case <-c.Request.Context().Done():
+			// クライアントの切断時のみ中断する（キャンセル時はロールバックの完了を resultCh で待つ）
 			if req.Stream && streamWriter != nil {
 				if workingTagSent {
 					streamWriter.Write(WORKING_TAG_CLOSE_ABORT)
@@ -448,6 +592,9 @@ AbsorbLoop:
 		}
 	}
 	// 7. エラーチェック（ストリーミング含む）
+	if err != nil && cuber.IsOperationCancelled(ctx) {
+		return operationCancelled(c, res, streamWriter, "Absorb")
+	}
 	if err != nil {
 		if req.Stream && streamWriter != nil {
 			// エラーメッセージをストリームで送信
@@ -485,44 +632,11 @@ AbsorbLoop:
 			}
 		}
 		// Stats & Contributor 更新 (MemoryGroup を含む階層構造)
-		// usage.Details は map[string]TokenUsage
-		for modelName, detail := range usage.Details {
-			// CubeModelStat (Train) - MemoryGroup を必ず含める
-			var ms model.CubeModelStat
-			if err := tx.Where("cube_id = ? AND memory_group = ? AND model_name = ? AND action_type = ? AND apx_id = ? AND vdr_id = ?",
-				cube.ID, req.MemoryGroup, modelName, types.ACTION_TYPE_ABSORB, *ids.ApxID, *ids.VdrID).
-				FirstOrCreate(&ms, model.CubeModelStat{
-					CubeID: cube.ID, MemoryGroup: req.MemoryGroup, ModelName: modelName, ActionType: string(types.ACTION_TYPE_ABSORB),
-					ApxID: *ids.ApxID, VdrID: *ids.VdrID,
-				}).Error; err != nil {
-				return err
-			}
-			ms.InputTokens += detail.InputTokens
-			ms.OutputTokens += detail.OutputTokens
-			if err := tx.Save(&ms).Error; err != nil {
-				return err
-			}
-			contributorName, err := getJwtUsrName(u, ids.ApxID, ids.VdrID, ids.UsrID)
-			if err != nil {
-				return fmt.Errorf("Failed to get contributor name: %s", err.Error())
-			}
-			// CubeContributor (Train) - MemoryGroup を必ず含める
-			var cc model.CubeContributor
-			if err := tx.Where("cube_id = ? AND memory_group = ? AND contributor_name = ? AND model_name = ? AND apx_id = ? AND vdr_id = ?",
-				cube.ID, req.MemoryGroup, contributorName, modelName, *ids.ApxID, *ids.VdrID).
-				FirstOrCreate(&cc, model.CubeContributor{
-					CubeID: cube.ID, MemoryGroup: req.MemoryGroup, ContributorName: contributorName, ModelName: modelName,
-					ApxID: *ids.ApxID, VdrID: *ids.VdrID,
-				}).Error; err != nil {
-				return err
-			}
-			cc.InputTokens += detail.InputTokens
-			cc.OutputTokens += detail.OutputTokens
-			if err := tx.Save(&cc).Error; err != nil {
-				return err
-			}
+		contributorName, err := getJwtUsrName(u, ids.ApxID, ids.VdrID, ids.UsrID)
+		if err != nil {
+			return fmt.Errorf("Failed to get contributor name: %s", err.Error())
 		}
-		return nil
+		return recordCubeUsage(tx, cube, req.MemoryGroup, types.ACTION_TYPE_ABSORB, contributorName, usage)
 	})
 	if err != nil {
 		if req.Stream && streamWriter != nil {
@@ -1251,6 +1365,11 @@ func ImportCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 	if err := u.CuberService.VerifyEmbeddingConfiguration(c.Request.Context(), verConfig); err != nil {
 		return BadRequestCustomMsg(c, res, fmt.Sprintf("Live embedding verification failed: %s", err.Error()))
 	}
+	// 9-2. ベクトルインデックスの再構築
+	// インデックスが利用できなくても検索は全件走査で動作するため、失敗はログのみとする
+	if err := cuber.RebuildCubeVectorIndexes(cubeDbFilePath, importedEmbConfig, u.Logger); err != nil {
+		utils.LogWarn(u.Logger, fmt.Sprintf("Failed to rebuild vector indexes of imported cube (%s): %s", cubeDbFilePath, err.Error()))
+	}
 	// 10. Transaction: Cube作成 + Lineage作成
 	// Encrypt API Key
 	encryptedEmbeddingApiKey, err := mycrypto.Encrypt(req.EmbeddingApiKey, u.CuberCryptoSkey)
@@ -1297,14 +1416,16 @@ func ImportCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		// Stats Usage作成
 		for _, stat := range importedStats {
 			statRecord := model.CubeModelStat{
-				CubeID:       newCube.ID,
-				MemoryGroup:  stat.MemoryGroup,
-				ModelName:    stat.ModelName,
-				ActionType:   stat.ActionType,
-				InputTokens:  stat.InputTokens,
-				OutputTokens: stat.OutputTokens,
-				ApxID:        *ids.ApxID,
-				VdrID:        *ids.VdrID,
+				CubeID:               newCube.ID,
+				MemoryGroup:          stat.MemoryGroup,
+				ModelName:            stat.ModelName,
+				ActionType:           stat.ActionType,
+				InputTokens:          stat.InputTokens,
+				OutputTokens:         stat.OutputTokens,
+				EmbeddingCacheHits:   stat.EmbeddingCacheHits,
+				EmbeddingCacheMisses: stat.EmbeddingCacheMisses,
+				ApxID:                *ids.ApxID,
+				VdrID:                *ids.VdrID,
 			}
 			if err := tx.Create(&statRecord).Error; err != nil {
 				return err
@@ -1478,6 +1599,29 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 			return ForbiddenCustomMsg(c, res, fmt.Sprintf("Query type not allowed: %d", queryType))
 		}
 	}
+	isCypher := types.QueryType(queryType) == types.QUERY_TYPE_CYCLER
+	if isCypher && !perm.AllowCypher {
+		return ForbiddenCustomMsg(c, res, "Cypher query is not allowed for this cube.")
+	}
+	// 時系列検索の対象期間
+	var timeRange types.TimeRange
+	if req.From != "" {
+		timeRange.From, _ = common.ParseStrToDatetime(&req.From)
+	}
+	if req.To != "" {
+		timeRange.To, _ = common.ParseStrToDatetime(&req.To)
+	}
+	if !timeRange.From.IsZero() && !timeRange.To.IsZero() && timeRange.To.Before(timeRange.From) {
+		return BadRequestCustomMsg(c, res, "'to' must not be earlier than 'from'.")
+	}
+	// ハイブリッド検索の各ランキングの重み（全て0の場合はデフォルト）
+	hybridWeights := types.HybridWeights{
+		Vector:        req.HybridWeightVector,
+		FtsNouns:      req.HybridWeightFtsNouns,
+		FtsNounsVerbs: req.HybridWeightFtsNounsVerbs,
+		FtsKeywords:   req.HybridWeightFtsKeywords,
+		Graph:         req.HybridWeightGraph,
+	}
 	// 4. CuberService.Query() 呼び出し準備
 	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
 	if err != nil {
@@ -1511,10 +1655,16 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 	}
 
 	// 6. Fetch Chat Model Config
-	chatConf, err := fetchChatModelConfig(u, req.ChatModelID, *ids.ApxID, *ids.VdrID)
+	chatConf, err := fetchChatModelConfig(u, cube, req.ChatModelID, *ids.ApxID, *ids.VdrID)
 	if err != nil {
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to fetch chat model: %s", err.Error()))
 	}
+	// オペレーションとして登録（POST /v1/cubes/operations/:operation_id/cancel でキャンセル可能）
+	ctx, operationID, finishOperation, err := startOperation(c, u, ids, types.ACTION_TYPE_QUERY, cube.UUID, req.MemoryGroup)
+	if err != nil {
+		return startOperationFailed(c, res, err)
+	}
+	defer finishOperation()
 	// 5. ストリーミング設定
 	var streamWriter *rtstream.StreamWriter
 	if req.Stream {
@@ -1526,6 +1676,9 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 		requestUUID := common.GenUUID() // リクエスト単位で共通のID
 		go func() {
 			defer streamWriter.Done() // ゴルーチン終了時にDoneを呼び出す
+			// 最初のイベントでオペレーションIDを通知（キャンセルに使用）
+			fmt.Fprint(c.Writer, rtstream.CreateSSEOperationEvent(operationID))
+			c.Writer.Flush()
 			ticker := time.NewTicker(streamWriter.MinDelay())
 			defer ticker.Stop()
 			for {
@@ -1552,16 +1705,22 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 		Chunks    *string
 		Summaries *string
 		Graph     *[]*storage.Triple
+		Citations *[]*storage.Citation
+		Table     *storage.CypherResult
 		Usage     types.TokenUsage
 		Err       error
 	}
 	dataCh := make(chan event.StreamEvent)
 	resultCh := make(chan QueryResult, 1)
 	isEn := req.IsEn
-	ctx, cancel := context.WithCancel(c.Request.Context())
-	defer cancel()
 	go func() {
-		ans, chk, sum, grp, _, usg, e := u.CuberService.Query(ctx, u.EventBus, cubeDBFilePath, req.MemoryGroup, req.Text,
+		if isCypher {
+			// Cypherクエリは LLM を使用せず、グラフに対して直接実行する
+			tbl, e := u.CuberService.CypherQuery(ctx, cubeDBFilePath, req.MemoryGroup, req.Text, embeddingConfig)
+			resultCh <- QueryResult{Table: tbl, Err: e}
+			return
+		}
+		ans, chk, sum, grp, cit, _, usg, e := u.CuberService.Query(ctx, u.EventBus, cubeDBFilePath, req.MemoryGroup, req.Text,
 			types.QueryConfig{
 				QueryType:               types.QueryType(queryType),
 				SummaryTopk:             req.SummaryTopk,
@@ -1571,6 +1730,10 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 				FtsTopk:                 req.FtsTopk,
 				ThicknessThreshold:      req.ThicknessThreshold,      // Thickness足切り閾値
 				ConflictResolutionStage: req.ConflictResolutionStage, // 矛盾解決ステージ
+				CotRounds:               req.CotRounds,               // 推論型クエリの最大ラウンド数
+				TimeRange:               timeRange,                   // 時系列検索の対象期間
+				HybridWeights:           hybridWeights,               // ハイブリッド検索の各ランキングの重み
+				CommunityLevel:          req.CommunityLevel,          // グローバル検索で使用するコミュニティの階層
 				IsEn:                    isEn,
 			},
 			embeddingConfig,
@@ -1583,6 +1746,7 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 			Chunks:    chk,
 			Summaries: sum,
 			Graph:     grp,
+			Citations: cit,
 			Usage:     usg,
 			Err:       e,
 		}
@@ -1592,6 +1756,8 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.Q
 		chunks    *string
 		summaries *string
 		graph     *[]*storage.Triple
+		citations *[]*storage.Citation
+		table     *storage.CypherResult
 		usage     types.TokenUsage
 	)
 	workingTagSent := false
@@ -1632,14 +1798,17 @@ QueryLoop:
 			chunks = result.Chunks
 			summaries = result.Summaries
 			graph = result.Graph
+			citations = result.Citations
+			table = result.Table
 			usage = result.Usage
 			err = result.Err
 			// Close <working> tag if it was opened
 			if req.Stream && workingTagSent {
-				streamWriter.Write(WORKING_TAG_CLOSE)
+				streamWriter.Write(common.TOpe(err != nil && cuber.IsOperationCancelled(ctx), WORKING_TAG_CLOSE_ABORT, WORKING_TAG_CLOSE))
 			}
 			break QueryLoop
-		case <-ctx.Done():
+		case <-c.Request.Context().Done():
+			// クライアントの切断時のみ中断する（キャンセル時はロールバックの完了を resultCh で待つ）
 			if req.Stream && streamWriter != nil {
 				if workingTagSent {
 					streamWriter.Write(WORKING_TAG_CLOSE_ABORT)
@@ -1651,6 +1820,9 @@ QueryLoop:
 		}
 	}
 	// 7. エラーチェック
+	if err != nil && cuber.IsOperationCancelled(ctx) {
+		return operationCancelled(c, res, streamWriter, "Query")
+	}
 	if err != nil {
 		if req.Stream && streamWriter != nil {
 			errorMsg := fmt.Sprintf("\nError: Query failed - %s", err.Error())
@@ -1661,18 +1833,22 @@ QueryLoop:
 			streamWriter.Close()
 			streamWriter.Wait()
 		}
+		if errors.Is(err, cuber.ErrInvalidCypherQuery) {
+			return BadRequestCustomMsg(c, res, fmt.Sprintf("Query failed: %s", err.Error()))
+		}
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Query failed: %s", err.Error()))
 	}
-	// 8. トークン使用量の厳格チェック
-	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+	// 8. トークン使用量の厳格チェック（LLM を使用しない Cypher クエリ、埋め込みが全てキャッシュにヒットしたクエリは対象外）
+	if !isCypher && usage.InputTokens == 0 && usage.OutputTokens == 0 && usage.EmbeddingCacheHits == 0 {
 		if req.Stream && streamWriter != nil {
 			streamWriter.Close()
 			streamWriter.Wait()
 		}
 		return InternalServerErrorCustomMsg(c, res, "Token accounting failed: no tokens recorded.")
 	}
-	// 9. DBトランザクションで Limit更新 + CubeModelStat 更新
+	// 9. DBトランザクションで Limit更新 + CubeModelStat 更新 + クエリの記録
 	var newQueryLimit int
+	queryID := common.GenUUID()
 	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
 		var txCube model.Cube
 		if err := tx.Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).First(&txCube).Error; err != nil {
@@ -1709,11 +1885,30 @@ QueryLoop:
 				})
 			ms.InputTokens += detail.InputTokens
 			ms.OutputTokens += detail.OutputTokens
+			ms.EmbeddingCacheHits += detail.EmbeddingCacheHits
+			ms.EmbeddingCacheMisses += detail.EmbeddingCacheMisses
 			if err := tx.Save(&ms).Error; err != nil {
 				return err
 			}
 		}
 		// CubeContributor は更新しない（Queryは利用であり貢献ではない）
+		// クエリの記録（フィードバックで強化・弱化するエッジを特定するため、回答の根拠となったエッジを保持）
+		queryEdges := []model.CubeQueryEdge{}
+		if graph != nil {
+			for _, triple := range *graph {
+				queryEdges = append(queryEdges, model.CubeQueryEdge{SourceID: triple.Edge.SourceID, Type: triple.Edge.Type, TargetID: triple.Edge.TargetID})
+			}
+		}
+		edgesJSON, err := common.ToJson(queryEdges)
+		if err != nil {
+			return fmt.Errorf("Failed to convert query edges to JSON: %s", err.Error())
+		}
+		if err := tx.Create(&model.CubeQuery{
+			UUID: *queryID, CubeID: cube.ID, MemoryGroup: req.MemoryGroup, QueryType: queryType, Text: req.Text,
+			Edges: datatypes.JSON(edgesJSON), UsrID: *ids.UsrID, ApxID: cube.ApxID, VdrID: cube.VdrID,
+		}).Error; err != nil {
+			return fmt.Errorf("Failed to record query: %s", err.Error())
+		}
 		return nil
 	})
 	if txErr != nil {
@@ -1725,10 +1920,13 @@ QueryLoop:
 	}
 	// 10. レスポンス
 	data := rtres.QueryCubeResData{
+		QueryID:      *queryID,
 		Answer:       answer,
 		Chunks:       chunks,
 		Summaries:    summaries,
 		Graph:        graph,
+		Citations:    citations,
+		Table:        table,
 		InputTokens:  usage.InputTokens,
 		OutputTokens: usage.OutputTokens,
 		QueryLimit:   newQueryLimit,
@@ -1757,7 +1955,198 @@ QueryLoop:
 	return OK(c, &data, res)
 }
 
+// FeedbackCube は、クエリの回答に対するフィードバックを、回答の根拠として使用されたエッジに反映します。
+// 訂正文が指定された場合は、エッジを弱化した上で、訂正文を新しい知識として取り込みます。
+// 訂正文の取り込みは、同期実行ではオペレーションとして登録し、async の場合はジョブとして実行します。
+func FeedbackCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.FeedbackCubeReq, res *rtres.FeedbackCubeRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	// 1. 入力チェック（訂正は否定的なフィードバックとして扱う）
+	feedback := req.Rating
+	if req.Correction != "" {
+		if req.Rating == "up" {
+			return BadRequestCustomMsg(c, res, "'rating' must not be 'up' when 'correction' is specified.")
+		}
+		if req.ChatModelID == 0 {
+			return BadRequestCustomMsg(c, res, "'chat_model_id' is required when 'correction' is specified.")
+		}
+		feedback = "correction"
+	}
+	if feedback == "" {
+		return BadRequestCustomMsg(c, res, "Either 'rating' or 'correction' is required.")
+	}
+	// 2. Cubeの取得と権限チェック
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	perm, err := common.ParseDatatypesJson[model.CubePermissions](&cube.Permissions)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, "Failed to parse permissions.")
+	}
+	// 訂正文の取り込みは Absorb として回数制限を消費する（消費は取り込みの完了時にトランザクション内で行う）
+	if req.Correction != "" && perm.AbsorbLimit < 0 {
+		return BadRequestCustomMsg(c, res, "Absorb limit exceeded.")
+	}
+	// 3. クエリ記録の取得
+	var cubeQuery model.CubeQuery
+	if err := u.DB.Where("uuid = ? AND cube_id = ? AND apx_id = ? AND vdr_id = ?", req.QueryID, cube.ID, cube.ApxID, cube.VdrID).First(&cubeQuery).Error; err != nil {
+		return NotFoundCustomMsg(c, res, "Query not found.")
+	}
+	if cubeQuery.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the user who ran the query can give feedback.")
+	}
+	queryEdges, err := common.ParseDatatypesJson[[]model.CubeQueryEdge](&cubeQuery.Edges)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, "Failed to parse query edges.")
+	}
+	if u.CuberService == nil {
+		return InternalServerErrorCustomMsg(c, res, "CuberService is not available.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get cube path: %s", err.Error()))
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to decrypt embedding API key: %s", err.Error()))
+	}
+	embeddingConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}
+	var chatConf types.ChatModelConfig
+	if req.Correction != "" {
+		chatConf, err = fetchChatModelConfig(u, cube, req.ChatModelID, *ids.ApxID, *ids.VdrID)
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to fetch chat model: %s", err.Error()))
+		}
+	}
+	// 4. フィードバック済みとして確保（同じクエリへの重複したフィードバックを防ぐ）
+	claim := u.DB.Model(&model.CubeQuery{}).Where("id = ? AND feedback = ?", cubeQuery.ID, "").
+		Updates(map[string]any{"feedback": feedback, "feedback_at": time.Now()})
+	if claim.Error != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to record feedback: %s", claim.Error.Error()))
+	}
+	if claim.RowsAffected == 0 {
+		return BadRequestCustomMsg(c, res, "Feedback has already been given for this query.")
+	}
+	releaseClaim := func() {
+		u.DB.Model(&model.CubeQuery{}).Where("id = ?", cubeQuery.ID).Updates(map[string]any{"feedback": "", "feedback_at": nil})
+	}
+	// 5. 回答の根拠として使用されたエッジに反映
+	edges := make([]*storage.Edge, 0, len(queryEdges))
+	for _, qe := range queryEdges {
+		edges = append(edges, &storage.Edge{SourceID: qe.SourceID, Type: qe.Type, TargetID: qe.TargetID})
+	}
+	result, err := u.CuberService.Feedback(c.Request.Context(), cubeDBFilePath, cubeQuery.MemoryGroup, edges, feedback == "up", embeddingConfig)
+	if err != nil {
+		releaseClaim()
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Feedback failed: %s", err.Error()))
+	}
+	// 6. 訂正文を新しい知識として取り込む
+	var usage types.TokenUsage
+	var jobUUID string
+	newAbsorbLimit := perm.AbsorbLimit
+	if req.Correction != "" && req.Async {
+		// 非同期実行の場合はジョブとして登録する（エッジへの反映は完了しているため、失敗してもフィードバックの記録は残す）
+		jobUUID, err = submitCorrectionJob(u, ids, cube, cubeQuery.MemoryGroup, req)
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create job: %s", err.Error()))
+		}
+	} else if req.Correction != "" {
+		tempDir, err := os.MkdirTemp("", "cuber-feedback-*")
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create temp dir: %s", err.Error()))
+		}
+		defer os.RemoveAll(tempDir) // 関数終了時に削除
+		tempFile := filepath.Join(tempDir, fmt.Sprintf("%s.txt", *common.GenUUID()))
+		if err := os.WriteFile(tempFile, []byte(req.Correction), 0644); err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to write temp file: %s", err.Error()))
+		}
+		// オペレーションとして登録（POST /v1/cubes/operations/:operation_id/cancel でキャンセル可能）
+		ctx, _, finishOperation, err := startOperation(c, u, ids, types.ACTION_TYPE_ABSORB, cube.UUID, cubeQuery.MemoryGroup)
+		if err != nil {
+			return startOperationFailed(c, res, err)
+		}
+		defer finishOperation()
+		usage, err = u.CuberService.Absorb(ctx, u.EventBus, cubeDBFilePath, cubeQuery.MemoryGroup, []string{tempFile}, "",
+			types.CognifyConfig{
+				ChunkSize:    appconfig.FEEDBACK_CORRECTION_CHUNK_SIZE,
+				ChunkOverlap: appconfig.FEEDBACK_CORRECTION_CHUNK_OVERLAP,
+			},
+			embeddingConfig,
+			chatConf,
+			nil,
+			req.IsEn,
+		)
+		// エッジへの反映は完了しているため、取り込みに失敗してもフィードバックの記録は残す
+		if err != nil && cuber.IsOperationCancelled(ctx) {
+			return operationCancelled(c, res, nil, "Correction absorb")
+		}
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to absorb correction: %s", err.Error()))
+		}
+		if usage.InputTokens < 0 || usage.OutputTokens < 0 {
+			return InternalServerErrorCustomMsg(c, res, "Invalid token usage reported.")
+		}
+		// 7. DBトランザクション (Limit更新 & Stats更新)
+		contributorName, err := getJwtUsrName(u, ids.ApxID, ids.VdrID, ids.UsrID)
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get contributor name: %s", err.Error()))
+		}
+		err = u.DB.Transaction(func(tx *gorm.DB) error {
+			// Cubeを再取得して最新のLimitを消費
+			var txCube model.Cube
+			if err := tx.Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).First(&txCube).Error; err != nil {
+				return err
+			}
+			txPerm, err := common.ParseDatatypesJson[model.CubePermissions](&txCube.Permissions)
+			if err != nil {
+				return err
+			}
+			if txPerm.AbsorbLimit > 0 {
+				txPerm.AbsorbLimit--
+				if txPerm.AbsorbLimit == 0 {
+					txPerm.AbsorbLimit = -1 // 0は無制限なので、使い切ったら-1(禁止)にする
+				}
+				newJSONStr, err := common.ToJson(txPerm)
+				if err != nil {
+					return err
+				}
+				txCube.Permissions = datatypes.JSON(newJSONStr)
+				if err := tx.Save(&txCube).Error; err != nil {
+					return err
+				}
+			}
+			newAbsorbLimit = txPerm.AbsorbLimit
+			// 訂正文の取り込みは Absorb として記録する
+			return recordCubeUsage(tx, &txCube, cubeQuery.MemoryGroup, types.ACTION_TYPE_ABSORB, contributorName, usage)
+		})
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("DB update failed: %s", err.Error()))
+		}
+	}
+	// 8. レスポンス
+	data := rtres.FeedbackCubeResData{
+		QueryID:      cubeQuery.UUID,
+		Feedback:     feedback,
+		Strengthened: result.Strengthened,
+		Weakened:     result.Weakened,
+		Pruned:       result.Pruned,
+		Missing:      result.Missing,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		AbsorbLimit:  newAbsorbLimit,
+		JobID:        jobUUID,
+	}
+	return OK(c, &data, res)
+}
+
 // MemifyCube はCubeを自己強化します。
+// SSEストリーミングモードと、ジョブとしての非同期実行（async）に対応。
 func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.MemifyCubeReq, res *rtres.MemifyCubeRes) bool {
 	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
 	// 1. Cubeの取得と権限チェック
@@ -1822,10 +2211,20 @@ func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 	}
 
 	// Fetch Chat Model Config
-	chatConf, err := fetchChatModelConfig(u, req.ChatModelID, *ids.ApxID, *ids.VdrID)
+	chatConf, err := fetchChatModelConfig(u, cube, req.ChatModelID, *ids.ApxID, *ids.VdrID)
 	if err != nil {
 		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to fetch chat model: %s", err.Error()))
 	}
+	// 非同期実行の場合はジョブとして登録して終了
+	if req.Async {
+		return submitMemifyJob(c, u, ids, cube, &perm, epochs, req, res)
+	}
+	// オペレーションとして登録（POST /v1/cubes/operations/:operation_id/cancel でキャンセル可能）
+	ctx, operationID, finishOperation, err := startOperation(c, u, ids, types.ACTION_TYPE_MEMIFY, cube.UUID, req.MemoryGroup)
+	if err != nil {
+		return startOperationFailed(c, res, err)
+	}
+	defer finishOperation()
 	// 5. ストリーミング設定
 	var streamWriter *rtstream.StreamWriter
 	if req.Stream {
@@ -1837,6 +2236,9 @@ func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 		requestUUID := common.GenUUID() // リクエスト単位で共通のID
 		go func() {
 			defer streamWriter.Done() // ゴルーチン終了時にDoneを呼び出す
+			// 最初のイベントでオペレーションIDを通知（キャンセルに使用）
+			fmt.Fprint(c.Writer, rtstream.CreateSSEOperationEvent(operationID))
+			c.Writer.Flush()
 			ticker := time.NewTicker(streamWriter.MinDelay())
 			defer ticker.Stop()
 			for {
@@ -1865,8 +2267,6 @@ func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.
 	dataCh := make(chan event.StreamEvent)
 	resultCh := make(chan MemifyResult, 1)
 	isEn := req.IsEn
-	ctx, cancel := context.WithCancel(c.Request.Context())
-	defer cancel()
 	go func() {
 		u, e := u.CuberService.Memify(ctx, u.EventBus, cubeDBFilePath, req.MemoryGroup,
 			&types.MemifyConfig{
@@ -1917,10 +2317,11 @@ MemifyLoop:
 			err = result.Err
 			// Close <working> tag if it was opened
 			if req.Stream && workingTagSent {
-				streamWriter.Write(WORKING_TAG_CLOSE)
+				streamWriter.Write(common.TOpe(err != nil && cuber.IsOperationCancelled(ctx), WORKING_TAG_CLOSE_ABORT, WORKING_TAG_CLOSE))
 			}
 			break MemifyLoop
-		case <-ctx.Done():
+		case <-c.Request.Context().Done():
+			// クライアントの切断時のみ中断する（キャンセル時はロールバックの完了を resultCh で待つ）
 			if req.Stream && streamWriter != nil {
 				if workingTagSent {
 					streamWriter.Write(WORKING_TAG_CLOSE_ABORT)
@@ -1932,6 +2333,9 @@ MemifyLoop:
 		}
 	}
 	// 7. エラーチェック
+	if err != nil && cuber.IsOperationCancelled(ctx) {
+		return operationCancelled(c, res, streamWriter, "Memify")
+	}
 	if err != nil {
 		if req.Stream && streamWriter != nil {
 			errorMsg := fmt.Sprintf("\nError: Memify failed - %s", err.Error())
@@ -1979,35 +2383,8 @@ MemifyLoop:
 		if err := tx.Save(&txCube).Error; err != nil {
 			return err
 		}
-		// Stats Update (ActionType="memify")
-		for modelName, detail := range usage.Details {
-			var ms model.CubeModelStat
-			tx.Where("cube_id = ? AND memory_group = ? AND model_name = ? AND action_type = ? AND apx_id = ? AND vdr_id = ?",
-				cube.ID, req.MemoryGroup, modelName, types.ACTION_TYPE_MEMIFY, cube.ApxID, cube.VdrID).
-				FirstOrCreate(&ms, model.CubeModelStat{
-					CubeID: cube.ID, MemoryGroup: req.MemoryGroup, ModelName: modelName, ActionType: string(types.ACTION_TYPE_MEMIFY),
-					ApxID: cube.ApxID, VdrID: cube.VdrID,
-				})
-			ms.InputTokens += detail.InputTokens
-			ms.OutputTokens += detail.OutputTokens
-			if err := tx.Save(&ms).Error; err != nil {
-				return err
-			}
-			// CubeContributor 更新
-			var cc model.CubeContributor
-			tx.Where("cube_id = ? AND memory_group = ? AND contributor_name = ? AND model_name = ? AND apx_id = ? AND vdr_id = ?",
-				cube.ID, req.MemoryGroup, contributorName, modelName, cube.ApxID, cube.VdrID).
-				FirstOrCreate(&cc, model.CubeContributor{
-					CubeID: cube.ID, MemoryGroup: req.MemoryGroup, ContributorName: contributorName, ModelName: modelName,
-					ApxID: cube.ApxID, VdrID: cube.VdrID,
-				})
-			cc.InputTokens += detail.InputTokens
-			cc.OutputTokens += detail.OutputTokens
-			if err := tx.Save(&cc).Error; err != nil {
-				return err
-			}
-		}
-		return nil
+		// Stats & Contributor 更新 (ActionType="memify")
+		return recordCubeUsage(tx, cube, req.MemoryGroup, types.ACTION_TYPE_MEMIFY, contributorName, usage)
 	})
 	if txErr != nil {
 		if req.Stream && streamWriter != nil {
@@ -2046,62 +2423,652 @@ MemifyLoop:
 	return OK(c, &data, res)
 }
 
-// DeleteCube はCubeを削除します。
-func DeleteCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.DeleteCubeReq, res *rtres.DeleteCubeRes) bool {
-	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
-	// 1. Cubeの取得と所有者チェック
+// SetCubeChatModelFallbacks は、Cube のフォールバック先のチャットモデルを設定します。
+// Absorb / Memify / Query / Feedback で指定されたチャットモデルが再試行しても失敗した場合に、設定した順に使用されます。
+func SetCubeChatModelFallbacks(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SetCubeChatModelFallbacksReq, res *rtres.SetCubeChatModelFallbacksRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
 	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
 	if err != nil {
 		return NotFoundCustomMsg(c, res, "Cube not found.")
 	}
-	// 所有者チェック
 	if cube.UsrID != *ids.UsrID {
-		return ForbiddenCustomMsg(c, res, "Only the owner can delete the cube.")
+		return ForbiddenCustomMsg(c, res, "Only the owner can set the chat model fallbacks.")
 	}
-	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
-	if err != nil {
-		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get cube path: %s", err.Error()))
+	fallbackIDs := req.ChatModelIDs
+	if fallbackIDs == nil {
+		fallbackIDs = []uint{}
 	}
-	// 2. DBトランザクションで関連データ削除
-	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
-		// CubeModelStat 削除
-		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeModelStat{}).Error; err != nil {
-			return err
+	if len(fallbackIDs) > 0 {
+		var count int64
+		if err := u.DB.Model(&model.ChatModel{}).Where("id IN ? AND apx_id = ? AND vdr_id = ?", fallbackIDs, *ids.ApxID, *ids.VdrID).Count(&count).Error; err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to check chat models: %s", err.Error()))
 		}
-		// CubeContributor 削除
-		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeContributor{}).Error; err != nil {
-			return err
+		if int(count) != len(fallbackIDs) {
+			return NotFoundCustomMsg(c, res, "Chat model not found.")
 		}
-		// CubeLineage 削除
-		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeLineage{}).Error; err != nil {
+	}
+	fallbacksJSON, err := json.Marshal(fallbackIDs)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to marshal chat model fallbacks: %s", err.Error()))
+	}
+	if err := u.DB.Model(cube).Update("chat_model_fallbacks", datatypes.JSON(fallbacksJSON)).Error; err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to update chat model fallbacks: %s", err.Error()))
+	}
+	return OK(c, &rtres.SetCubeChatModelFallbacksResData{ChatModelFallbackIDs: fallbackIDs}, res)
+}
+
+// SetCubeCypher は、Cube で Cypher クエリ (QUERY_TYPE_CYCLER) の実行を許可するかを設定します。
+// インポートした Cube の権限は鍵によって決まるため、自身で作成した Cube のみ変更できます。
+func SetCubeCypher(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SetCubeCypherReq, res *rtres.SetCubeCypherRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can change the cypher permission.")
+	}
+	if cube.SourceExportID != nil {
+		return ForbiddenCustomMsg(c, res, "The cypher permission of an imported cube is determined by its key.")
+	}
+	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
+		// Cubeを再取得して最新の権限に反映
+		var txCube model.Cube
+		if err := tx.Where("id = ?", cube.ID).First(&txCube).Error; err != nil {
 			return err
 		}
-		// Export 削除
-		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.Export{}).Error; err != nil {
+		txPerm, err := common.ParseDatatypesJson[model.CubePermissions](&txCube.Permissions)
+		if err != nil {
 			return err
 		}
-		// Cube 削除
-		if err := tx.Delete(&cube).Error; err != nil {
+		txPerm.AllowCypher = req.AllowCypher
+		newJSONStr, err := common.ToJson(txPerm)
+		if err != nil {
 			return err
 		}
-		return nil
+		return tx.Model(&txCube).Update("permissions", datatypes.JSON(newJSONStr)).Error
 	})
 	if txErr != nil {
-		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Transaction failed: %s", txErr.Error()))
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to update cypher permission: %s", txErr.Error()))
 	}
-	// 3. 物理ファイル（KuzuDBファイル）削除（トランザクション成功後に削除）
-	// cubeDBFilePath のデータベースファイルの存在を確認して、存在していたら削除
-	if _, err := os.Stat(cubeDBFilePath); err == nil {
-		if err := os.Remove(cubeDBFilePath); err != nil {
-			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to delete cube db file: %s", err.Error()))
-		}
-	} else {
-		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("cubeDBFilePath not found: %s", err.Error()))
+	return OK(c, &rtres.SetCubeCypherResData{AllowCypher: req.AllowCypher}, res)
+}
+
+// ListCubePrompts は、メモリーグループで使用されるプロンプトの一覧（上書きの履歴を含む）を返します。
+func ListCubePrompts(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.ListCubePromptsReq, res *rtres.ListCubePromptsRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
 	}
-	return OK[rtres.DeleteCubeRes](c, nil, res)
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can view the prompts.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	overrides, err := u.CuberService.GetPromptOverrides(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, false, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get prompt overrides: %s", err.Error()))
+	}
+	return OK(c, new(rtres.ListCubePromptsResData).Of(overrides), res)
 }
 
-// CheckInheritance は親子間の権限継承ルールを検証します。
+// SetCubePrompt は、メモリーグループのプロンプトを上書きします。
+// 上書きは新しいバージョンとして保存され、以前のバージョンは履歴として残ります。
+func SetCubePrompt(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SetCubePromptReq, res *rtres.SetCubePromptRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can override the prompts.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	override, err := u.CuberService.SavePromptOverride(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, prompts.Name(req.Name), req.Content, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to save prompt override: %s", err.Error()))
+	}
+	return OK(c, &rtres.SetCubePromptResData{Name: override.Name, Version: override.Version}, res)
+}
+
+// ResetCubePrompt は、メモリーグループのプロンプトの上書きを無効にし、デフォルトのプロンプトに戻します。
+func ResetCubePrompt(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.ResetCubePromptReq, res *rtres.ResetCubePromptRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can reset the prompts.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	reset, err := u.CuberService.ResetPromptOverride(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, prompts.Name(req.Name), embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to reset prompt override: %s", err.Error()))
+	}
+	if !reset {
+		return NotFoundCustomMsg(c, res, "Prompt override not found.")
+	}
+	return OK[rtres.ResetCubePromptRes](c, nil, res)
+}
+
+// GetCubeOntology は、メモリーグループのオントロジーを返します。
+func GetCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.GetCubeOntologyReq, res *rtres.GetCubeOntologyRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can view the ontology.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	onto, err := u.CuberService.GetOntology(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get ontology: %s", err.Error()))
+	}
+	if onto == nil {
+		return NotFoundCustomMsg(c, res, "Ontology not defined.")
+	}
+	return OK(c, onto, res)
+}
+
+// SetCubeOntology は、メモリーグループのオントロジーを保存します（既存の定義は置き換えられます）。
+func SetCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SetCubeOntologyReq, res *rtres.SetCubeOntologyRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can set the ontology.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	if err := u.CuberService.SaveOntology(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.Ontology, embeddingConfig); err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to save ontology: %s", err.Error()))
+	}
+	return OK(c, req.Ontology, res)
+}
+
+// DeleteCubeOntology は、メモリーグループのオントロジーを削除します。
+func DeleteCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.DeleteCubeOntologyReq, res *rtres.DeleteCubeOntologyRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can delete the ontology.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	deleted, err := u.CuberService.DeleteOntology(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to delete ontology: %s", err.Error()))
+	}
+	if !deleted {
+		return NotFoundCustomMsg(c, res, "Ontology not defined.")
+	}
+	return OK[rtres.DeleteCubeOntologyRes](c, nil, res)
+}
+
+// FindCubeGraphPaths は、2つのエンティティ間の経路を検索し、必要に応じてチャットモデルで説明します。
+// 説明を生成した場合のみ、Query の回数制限を消費し、トークン使用量を記録します。
+func FindCubeGraphPaths(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.FindCubeGraphPathsReq, res *rtres.FindCubeGraphPathsRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	// 1. 入力チェック
+	if req.Narrate && req.ChatModelID == 0 {
+		return BadRequestCustomMsg(c, res, "'chat_model_id' is required when 'narrate' is true.")
+	}
+	sourceID := utils.NormalizeForGraph(req.Source)
+	targetID := utils.NormalizeForGraph(req.Target)
+	if sourceID == "" || targetID == "" {
+		return BadRequestCustomMsg(c, res, "'source' and 'target' must not be empty.")
+	}
+	if sourceID == targetID {
+		return BadRequestCustomMsg(c, res, "'source' and 'target' must be different entities.")
+	}
+	// 2. Cubeの取得と権限チェック
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	perm, err := common.ParseDatatypesJson[model.CubePermissions](&cube.Permissions)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, "Failed to parse permissions.")
+	}
+	if perm.QueryLimit < 0 {
+		return ForbiddenCustomMsg(c, res, "Query limit exceeded.")
+	}
+	// 3. MemoryGroup 存在チェック
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	st, err := u.CuberService.GetOrOpenStorage(cubeDBFilePath, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to open storage: %s", err.Error()))
+	}
+	mgConfig, err := st.Graph.GetMemoryGroupConfig(c.Request.Context(), req.MemoryGroup)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to check memory group: %s", err.Error()))
+	}
+	if mgConfig == nil {
+		return NotFoundCustomMsg(c, res, fmt.Sprintf("Memory group '%s' not found in this cube.", req.MemoryGroup))
+	}
+	// 4. チャットモデルの取得（説明を生成する場合のみ）
+	var chatConf *types.ChatModelConfig
+	if req.Narrate {
+		conf, err := fetchChatModelConfig(u, cube, req.ChatModelID, *ids.ApxID, *ids.VdrID)
+		if err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to fetch chat model: %s", err.Error()))
+		}
+		chatConf = &conf
+	}
+	// 5. 経路の検索
+	pathQuery := storage.PathQuery{
+		SourceID:           sourceID,
+		TargetID:           targetID,
+		K:                  common.TOpe(req.K > 0, req.K, appconfig.DEFAULT_PATH_K),
+		MaxHops:            min(common.TOpe(req.MaxHops > 0, req.MaxHops, appconfig.DEFAULT_PATH_MAX_HOPS), appconfig.PATH_MAX_HOPS_LIMIT),
+		ThicknessThreshold: common.TOpe(req.ThicknessThreshold > 0, req.ThicknessThreshold, appconfig.DEFAULT_THICKNESS_THRESHOLD),
+		EdgeTypes:          req.EdgeTypes,
+	}
+	paths, narration, usage, err := u.CuberService.FindPaths(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, pathQuery, embeddingConfig, chatConf, req.IsEn)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to find paths: %s", err.Error()))
+	}
+	if paths == nil {
+		paths = []*storage.Path{}
+	}
+	data := rtres.FindCubeGraphPathsResData{
+		Paths:      paths,
+		Narration:  narration,
+		QueryLimit: perm.QueryLimit,
+	}
+	if narration == nil {
+		return OK(c, &data, res)
+	}
+	// 6. トークン使用量の厳格チェック
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+		return InternalServerErrorCustomMsg(c, res, "Token accounting failed: no tokens recorded.")
+	}
+	// 7. DBトランザクションで Limit更新 + CubeModelStat 更新
+	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
+		var txCube model.Cube
+		if err := tx.Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).First(&txCube).Error; err != nil {
+			return err
+		}
+		txPerm, err := common.ParseDatatypesJson[model.CubePermissions](&txCube.Permissions)
+		if err != nil {
+			return err
+		}
+		if txPerm.QueryLimit > 0 {
+			txPerm.QueryLimit--
+			if txPerm.QueryLimit == 0 {
+				txPerm.QueryLimit = -1 // 0は無制限を意味するので、-1に変更して禁止にする
+			}
+		}
+		data.QueryLimit = txPerm.QueryLimit
+		newPermJSON, err := common.ToJson(txPerm)
+		if err != nil {
+			return fmt.Errorf("Failed to convert permissions to JSON: %s", err.Error())
+		}
+		txCube.Permissions = datatypes.JSON(newPermJSON)
+		if err := tx.Save(&txCube).Error; err != nil {
+			return fmt.Errorf("Failed to update cube: %s", err.Error())
+		}
+		// Stats Update (ActionType="query")
+		for modelName, detail := range usage.Details {
+			var ms model.CubeModelStat
+			if err := tx.Where("cube_id = ? AND memory_group = ? AND model_name = ? AND action_type = ? AND apx_id = ? AND vdr_id = ?",
+				cube.ID, req.MemoryGroup, modelName, types.ACTION_TYPE_QUERY, cube.ApxID, cube.VdrID).
+				FirstOrCreate(&ms, model.CubeModelStat{
+					CubeID: cube.ID, MemoryGroup: req.MemoryGroup, ModelName: modelName, ActionType: string(types.ACTION_TYPE_QUERY),
+					ApxID: cube.ApxID, VdrID: cube.VdrID,
+				}).Error; err != nil {
+				return err
+			}
+			ms.InputTokens += detail.InputTokens
+			ms.OutputTokens += detail.OutputTokens
+			if err := tx.Save(&ms).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Transaction failed: %s", txErr.Error()))
+	}
+	data.InputTokens = usage.InputTokens
+	data.OutputTokens = usage.OutputTokens
+	return OK(c, &data, res)
+}
+
+// SearchCubeGraphEntities は、名前または別名で知識グラフのエンティティを検索します。
+func SearchCubeGraphEntities(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.SearchCubeGraphEntitiesReq, res *rtres.SearchCubeGraphEntitiesRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	cubeDBFilePath, embeddingConfig, ok := openCubeGraph(c, u, ids, req.CubeID, req.MemoryGroup, res)
+	if !ok {
+		return false
+	}
+	limit := common.TOpe(req.Limit > 0, req.Limit, appconfig.GRAPH_BROWSE_DEFAULT_LIMIT)
+	entities, total, err := u.CuberService.SearchEntities(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.Q, req.Type, req.Offset, limit, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to search entities: %s", err.Error()))
+	}
+	return OK(c, &rtres.SearchCubeGraphEntitiesResData{Entities: entities, Total: total}, res)
+}
+
+// GetCubeGraphEntity は、知識グラフのエンティティの詳細を返します。
+func GetCubeGraphEntity(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.GetCubeGraphEntityReq, res *rtres.GetCubeGraphEntityRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	cubeDBFilePath, embeddingConfig, ok := openCubeGraph(c, u, ids, req.CubeID, req.MemoryGroup, res)
+	if !ok {
+		return false
+	}
+	entity, err := u.CuberService.GetEntity(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.EntityID, embeddingConfig)
+	if err != nil {
+		if errors.Is(err, cuber.ErrEntityNotFound) {
+			return NotFoundCustomMsg(c, res, "Entity not found.")
+		}
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get entity: %s", err.Error()))
+	}
+	return OK(c, new(rtres.GetCubeGraphEntityResData).Of(entity), res)
+}
+
+// ListCubeGraphNeighbors は、エンティティに隣接するノードを、接続するエッジの Thickness が大きい順に返します。
+func ListCubeGraphNeighbors(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.ListCubeGraphNeighborsReq, res *rtres.ListCubeGraphNeighborsRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	cubeDBFilePath, embeddingConfig, ok := openCubeGraph(c, u, ids, req.CubeID, req.MemoryGroup, res)
+	if !ok {
+		return false
+	}
+	limit := common.TOpe(req.Limit > 0, req.Limit, appconfig.GRAPH_BROWSE_DEFAULT_LIMIT)
+	neighbors, total, err := u.CuberService.GetEntityNeighbors(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.EntityID, req.Offset, limit, embeddingConfig)
+	if err != nil {
+		if errors.Is(err, cuber.ErrEntityNotFound) {
+			return NotFoundCustomMsg(c, res, "Entity not found.")
+		}
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get neighbors: %s", err.Error()))
+	}
+	return OK(c, new(rtres.ListCubeGraphNeighborsResData).Of(neighbors, total), res)
+}
+
+// GetCubeGraphSubgraph は、エンティティを中心としたサブグラフ（エゴネットワーク）を返します。
+func GetCubeGraphSubgraph(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.GetCubeGraphSubgraphReq, res *rtres.GetCubeGraphSubgraphRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	cubeDBFilePath, embeddingConfig, ok := openCubeGraph(c, u, ids, req.CubeID, req.MemoryGroup, res)
+	if !ok {
+		return false
+	}
+	depth := common.TOpe(req.Depth > 0, req.Depth, appconfig.GRAPH_SUBGRAPH_DEFAULT_DEPTH)
+	maxNodes := common.TOpe(req.MaxNodes > 0, req.MaxNodes, appconfig.GRAPH_SUBGRAPH_DEFAULT_MAX_NODES)
+	subgraph, err := u.CuberService.GetEntitySubgraph(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.EntityID, depth, maxNodes, req.MinThickness, embeddingConfig)
+	if err != nil {
+		if errors.Is(err, cuber.ErrEntityNotFound) {
+			return NotFoundCustomMsg(c, res, "Entity not found.")
+		}
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get subgraph: %s", err.Error()))
+	}
+	return OK(c, &rtres.GetCubeGraphSubgraphResData{Nodes: subgraph.Nodes, Triples: subgraph.Triples, Truncated: subgraph.Truncated}, res)
+}
+
+// openCubeGraph は、グラフ閲覧 API に共通の権限チェック（QueryCube と同様の Cube の取得・Query の回数制限・メモリーグループの存在）を行い、
+// Cube の DB ファイルパスと埋め込みモデル設定を返します。
+// チェックに失敗した場合はエラーレスポンスを書き込み、false を返します。
+func openCubeGraph[T any](c *gin.Context, u *rtutil.RtUtil, ids *common.IDs, cubeID uint, memoryGroup string, res *T) (string, types.EmbeddingModelConfig, bool) {
+	cube, err := getCube(u, cubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	perm, err := common.ParseDatatypesJson[model.CubePermissions](&cube.Permissions)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, InternalServerErrorCustomMsg(c, res, "Failed to parse permissions.")
+	}
+	if perm.QueryLimit < 0 {
+		return "", types.EmbeddingModelConfig{}, ForbiddenCustomMsg(c, res, "Query limit exceeded.")
+	}
+	cubeDBFilePath, embeddingConfig, err := getCubeStorageConfig(u, cube, ids)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	st, err := u.CuberService.GetOrOpenStorage(cubeDBFilePath, embeddingConfig)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to open storage: %s", err.Error()))
+	}
+	mgConfig, err := st.Graph.GetMemoryGroupConfig(c.Request.Context(), memoryGroup)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to check memory group: %s", err.Error()))
+	}
+	if mgConfig == nil {
+		return "", types.EmbeddingModelConfig{}, NotFoundCustomMsg(c, res, fmt.Sprintf("Memory group '%s' not found in this cube.", memoryGroup))
+	}
+	return cubeDBFilePath, embeddingConfig, true
+}
+
+// getCubeStorageConfig は、Cube の DB ファイルパスと埋め込みモデル設定（復号した API キーを含む）を返します。
+func getCubeStorageConfig(u *rtutil.RtUtil, cube *model.Cube, ids *common.IDs) (string, types.EmbeddingModelConfig, error) {
+	if u.CuberService == nil {
+		return "", types.EmbeddingModelConfig{}, fmt.Errorf("CuberService is not available.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, fmt.Errorf("Failed to get cube path: %s", err.Error())
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return "", types.EmbeddingModelConfig{}, fmt.Errorf("Failed to decrypt embedding API key: %s", err.Error())
+	}
+	return cubeDBFilePath, types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}, nil
+}
+
+// MigrateCubeEmbeddings は、Cube の埋め込みモデルの移行をジョブとして登録し、ジョブIDを返します。
+// 全行の再ベクトル化には時間がかかるため、常にジョブとして実行します。
+func MigrateCubeEmbeddings(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.MigrateCubeEmbeddingsReq, res *rtres.MigrateCubeEmbeddingsRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can migrate the embedding model.")
+	}
+	if cube.EmbeddingProvider == req.EmbeddingProvider && cube.EmbeddingModel == req.EmbeddingModel && cube.EmbeddingDimension == req.EmbeddingDimension {
+		return BadRequestCustomMsg(c, res, "The cube already uses this embedding model.")
+	}
+	// 同じ Cube の移行が実行待ち・実行中の場合は登録しない
+	var inProgress int64
+	if err := u.DB.Model(&model.Job{}).
+		Where("cube_id = ? AND type = ? AND status IN ?", cube.ID, string(types.ACTION_TYPE_MIGRATE_EMBEDDINGS), []string{jobstatus.QUEUED.Val(), jobstatus.RUNNING.Val()}).
+		Count(&inProgress).Error; err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to check jobs: %s", err.Error()))
+	}
+	if inProgress > 0 {
+		return BadRequestCustomMsg(c, res, "An embedding migration of this cube is already in progress.")
+	}
+	encryptedEmbeddingApiKey, err := mycrypto.Encrypt(req.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to encrypt embedding API key: %s", err.Error()))
+	}
+	jobUUID := *common.GenUUID()
+	params := model.JobMigrateEmbeddingsParams{
+		EmbeddingProvider:  req.EmbeddingProvider,
+		EmbeddingModel:     req.EmbeddingModel,
+		EmbeddingDimension: req.EmbeddingDimension,
+		EmbeddingBaseURL:   req.EmbeddingBaseURL,
+		EmbeddingApiKey:    encryptedEmbeddingApiKey,
+		IsEn:               req.IsEn,
+	}
+	if err := createJob(u, jobUUID, types.ACTION_TYPE_MIGRATE_EMBEDDINGS, cube, "", ids, params); err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create job: %s", err.Error()))
+	}
+	return OK(c, &rtres.MigrateCubeEmbeddingsResData{JobID: jobUUID}, res)
+}
+
+// DeleteCube はCubeを削除します。
+func DeleteCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.DeleteCubeReq, res *rtres.DeleteCubeRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	// 1. Cubeの取得と所有者チェック
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	// 所有者チェック
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can delete the cube.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get cube path: %s", err.Error()))
+	}
+	// 2. DBトランザクションで関連データ削除
+	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
+		// CubeModelStat 削除
+		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeModelStat{}).Error; err != nil {
+			return err
+		}
+		// CubeContributor 削除
+		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeContributor{}).Error; err != nil {
+			return err
+		}
+		// CubeLineage 削除
+		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.CubeLineage{}).Error; err != nil {
+			return err
+		}
+		// Export 削除
+		if err := tx.Where("cube_id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).Delete(&model.Export{}).Error; err != nil {
+			return err
+		}
+		// Cube 削除
+		if err := tx.Delete(&cube).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	if txErr != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Transaction failed: %s", txErr.Error()))
+	}
+	// 3. 物理ファイル（KuzuDBファイル）削除（トランザクション成功後に削除）
+	// cubeDBFilePath のデータベースファイルの存在を確認して、存在していたら削除
+	if _, err := os.Stat(cubeDBFilePath); err == nil {
+		if err := os.Remove(cubeDBFilePath); err != nil {
+			return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to delete cube db file: %s", err.Error()))
+		}
+	} else {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("cubeDBFilePath not found: %s", err.Error()))
+	}
+	// 失敗した Absorb のチェックポイントも削除（失敗しても Cube の削除は完了している）
+	os.RemoveAll(checkpoint.DirPath(cubeDBFilePath))
+	return OK[rtres.DeleteCubeRes](c, nil, res)
+}
+
+// ListCubeData は、メモリーグループに取り込まれたデータ（文書）の一覧を返します。
+func ListCubeData(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.ListCubeDataReq, res *rtres.ListCubeDataRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	// 1. Cubeの取得
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, "Failed to get cube path.")
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to decrypt embedding API key: %s", err.Error()))
+	}
+	embeddingConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}
+	// 2. MemoryGroup 存在チェック
+	st, err := u.CuberService.GetOrOpenStorage(cubeDBFilePath, embeddingConfig)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to open storage: %s", err.Error()))
+	}
+	mgConfig, err := st.Graph.GetMemoryGroupConfig(c.Request.Context(), req.MemoryGroup)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to check memory group: %s", err.Error()))
+	}
+	if mgConfig == nil {
+		return NotFoundCustomMsg(c, res, fmt.Sprintf("Memory group '%s' not found in this cube.", req.MemoryGroup))
+	}
+	// 3. データ一覧の取得
+	dataList, err := st.Vector.GetDataList(c.Request.Context(), req.MemoryGroup)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get data list: %s", err.Error()))
+	}
+	return OK(c, new(rtres.ListCubeDataResData).Of(dataList), res)
+}
+
+// DeleteCubeData は、取り込み済みのデータ（文書）と、それに由来する知識を削除します。
+func DeleteCubeData(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.DeleteCubeDataReq, res *rtres.DeleteCubeDataRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey()))
+	// 1. Cubeの取得と所有者チェック
+	cube, err := getCube(u, req.CubeID, *ids.ApxID, *ids.VdrID)
+	if err != nil {
+		return NotFoundCustomMsg(c, res, "Cube not found.")
+	}
+	if cube.UsrID != *ids.UsrID {
+		return ForbiddenCustomMsg(c, res, "Only the owner can delete data from the cube.")
+	}
+	if u.CuberService == nil {
+		return InternalServerErrorCustomMsg(c, res, "CuberService is not available.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, ids.ApxID, ids.VdrID, ids.UsrID)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to get cube path: %s", err.Error()))
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to decrypt embedding API key: %s", err.Error()))
+	}
+	embeddingConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}
+	// 2. 削除（Data → Document → Chunk → Summary → 出典がこのデータのみのエッジ・ノード）
+	if err := u.CuberService.DeleteData(c.Request.Context(), cubeDBFilePath, req.MemoryGroup, req.DataID, embeddingConfig); err != nil {
+		if errors.Is(err, cuber.ErrDataNotFound) {
+			return NotFoundCustomMsg(c, res, "Data not found.")
+		}
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to delete data: %s", err.Error()))
+	}
+	return OK[rtres.DeleteCubeDataRes](c, nil, res)
+}
+
+// CheckInheritance は親子間の権限継承ルールを検証します。
 func CheckInheritance(parent model.CubePermissions, child model.CubePermissions, pExpire, cExpire *time.Time) error {
 	// 1. 禁止であるはずの機能や制限が子の時点で復活してしまっていないかチェック
 	//     - 親が禁止(-1)なら、子も禁止(-1)でなければならない
@@ -2139,6 +3106,9 @@ func CheckInheritance(parent model.CubePermissions, child model.CubePermissions,
 	if !parent.AllowStats && child.AllowStats { // 親が禁止なら、子も禁止でなければならない
 		return fmt.Errorf("AllowStats: Cannot enable stats (parent forbidden, value = %t).", parent.AllowStats)
 	}
+	if !parent.AllowCypher && child.AllowCypher { // 親が禁止なら、子も禁止でなければならない
+		return fmt.Errorf("AllowCypher: Cannot enable cypher (parent forbidden, value = %t).", parent.AllowCypher)
+	}
 	// 2. Expire チェック
 	// 親に期限がある場合、子はそれより前でなければならない
 	if pExpire != nil {
@@ -2189,10 +3159,12 @@ func fetchMemoryGroupStats(db *gorm.DB, cubeID, apxID, vdrID uint) ([]rtres.Memo
 			}
 		}
 		mgMap[s.MemoryGroup].Stats = append(mgMap[s.MemoryGroup].Stats, rtres.ModelStatRes{
-			ModelName:    s.ModelName,
-			ActionType:   s.ActionType,
-			InputTokens:  s.InputTokens,
-			OutputTokens: s.OutputTokens,
+			ModelName:            s.ModelName,
+			ActionType:           s.ActionType,
+			InputTokens:          s.InputTokens,
+			OutputTokens:         s.OutputTokens,
+			EmbeddingCacheHits:   s.EmbeddingCacheHits,
+			EmbeddingCacheMisses: s.EmbeddingCacheMisses,
 		})
 	}
 	for _, c := range contribs {
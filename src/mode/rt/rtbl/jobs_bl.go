@@ -0,0 +1,685 @@
+package rtbl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/enum/jobstatus"
+	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/lib/mycrypto"
+	"github.com/t-kawata/mycute/mode/rt/rtreq"
+	"github.com/t-kawata/mycute/mode/rt/rtres"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
+	"github.com/t-kawata/mycute/model"
+	"github.com/t-kawata/mycute/pkg/cuber"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// GetJob は非同期ジョブの状態を返します。
+// 参照できるのはジョブを登録した本人のみです。
+func GetJob(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.GetJobReq, res *rtres.GetJobRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	var job model.Job
+	if err := u.DB.Where("uuid = ? AND apx_id = ? AND vdr_id = ? AND usr_id = ?", req.JobID, *ids.ApxID, *ids.VdrID, *ids.UsrID).First(&job).Error; err != nil {
+		return NotFoundCustomMsg(c, res, "Job not found.")
+	}
+	return OK(c, new(rtres.GetJobResData).Of(&job), res)
+}
+
+// RunNextJob は、実行待ちのジョブを1件取り出して実行します。
+// ワーカープール（rtjob.JobQueue）から繰り返し呼び出され、実行したジョブがなかった場合は false を返します。
+//
+// 引数:
+//   - ctx: ワーカーのコンテキスト（キャンセルされた場合、実行中のジョブは running のまま残り、次回起動時に再実行される）
+//   - u: リクエストに紐づかない RtUtil（EventBus はジョブごとに作成する）
+//
+// 返り値:
+//   - bool: ジョブを実行した場合は true
+func RunNextJob(ctx context.Context, u *rtutil.RtUtil) bool {
+	job, err := claimNextJob(u)
+	if err != nil {
+		utils.LogWarn(u.Logger, fmt.Sprintf("RunNextJob: Failed to claim job: %s", err.Error()))
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	runJob(ctx, u, job)
+	return true
+}
+
+// RecoverJobs は、サーバーの停止により running のまま残ったジョブを実行待ちに戻します。
+// サーバーの起動時、ワーカーの起動前に1回だけ呼び出します。
+// 対象は自身のホストで実行していたジョブのみで、他のサーバーが実行中のジョブには影響しません。
+// JOB_MAX_ATTEMPTS 回実行を開始しても終わらなかったジョブは、再実行せずに failed にします。
+func RecoverJobs(u *rtutil.RtUtil) error {
+	var jobs []model.Job
+	if err := u.DB.Where("status = ? AND host = ?", jobstatus.RUNNING.Val(), jobHost(u)).Find(&jobs).Error; err != nil {
+		return fmt.Errorf("Failed to find running jobs: %s", err.Error())
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Attempts >= appconfig.JOB_MAX_ATTEMPTS {
+			finishJob(u, job, nil, nil, types.TokenUsage{}, fmt.Errorf("Interrupted %d times by server restarts.", job.Attempts))
+			continue
+		}
+		if err := u.DB.Model(&model.Job{}).Where("id = ? AND status = ?", job.ID, jobstatus.RUNNING.Val()).
+			Update("status", jobstatus.QUEUED.Val()).Error; err != nil {
+			return fmt.Errorf("Failed to requeue job '%s': %s", job.UUID, err.Error())
+		}
+		utils.LogInfo(u.Logger, fmt.Sprintf("RecoverJobs: Requeued interrupted job '%s' (%s).", job.UUID, job.Type))
+	}
+	return nil
+}
+
+// submitAbsorbJob は、Absorb をジョブとして登録し、ジョブIDを返します。
+// 取り込み対象（content / file）は、実行時まで DB_DIR_PATH/jobs/<ジョブID>/ に保存します。
+// AbsorbLimit は実行の終了時に消費するため、レスポンスの absorb_limit は登録時点の値です。
+func submitAbsorbJob(c *gin.Context, u *rtutil.RtUtil, ids *common.IDs, cube *model.Cube, perm *model.CubePermissions, req *rtreq.AbsorbCubeReq, res *rtres.AbsorbCubeRes) bool {
+	jobUUID := *common.GenUUID()
+	jobDir := getJobDirPath(u, jobUUID)
+	filePaths, err := saveAbsorbInputs(c, jobDir, req)
+	if err != nil {
+		os.RemoveAll(jobDir)
+		return InternalServerErrorCustomMsg(c, res, err.Error())
+	}
+	params := model.JobAbsorbParams{
+		FilePaths:                  filePaths,
+		SourceID:                   req.SourceID,
+		ChunkSize:                  req.ChunkSize,
+		ChunkOverlap:               req.ChunkOverlap,
+		ChunkMaxRetries:            req.ChunkMaxRetries,
+		MaxFailedChunkRatio:        req.MaxFailedChunkRatio,
+		ChatModelID:                req.ChatModelID,
+		IsEn:                       req.IsEn,
+		HalfLifeDays:               req.HalfLifeDays,
+		PruneThreshold:             req.PruneThreshold,
+		MinSurvivalProtectionHours: req.MinSurvivalProtectionHours,
+		MdlKNeighbors:              req.MdlKNeighbors,
+	}
+	if err := createJob(u, jobUUID, types.ACTION_TYPE_ABSORB, cube, req.MemoryGroup, ids, params); err != nil {
+		os.RemoveAll(jobDir)
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create job: %s", err.Error()))
+	}
+	data := rtres.AbsorbCubeResData{AbsorbLimit: perm.AbsorbLimit, JobID: jobUUID}
+	return OK(c, &data, res)
+}
+
+// submitCorrectionJob は、フィードバックの訂正文の取り込みを Absorb のジョブとして登録し、ジョブIDを返します。
+// 訂正文は、実行時まで DB_DIR_PATH/jobs/<ジョブID>/ に保存します。
+func submitCorrectionJob(u *rtutil.RtUtil, ids *common.IDs, cube *model.Cube, memoryGroup string, req *rtreq.FeedbackCubeReq) (string, error) {
+	jobUUID := *common.GenUUID()
+	jobDir := getJobDirPath(u, jobUUID)
+	if err := os.MkdirAll(jobDir, 0755); err != nil {
+		return "", fmt.Errorf("Failed to create job dir: %s", err.Error())
+	}
+	filePath := filepath.Join(jobDir, fmt.Sprintf("%s.txt", *common.GenUUID()))
+	if err := os.WriteFile(filePath, []byte(req.Correction), 0644); err != nil {
+		os.RemoveAll(jobDir)
+		return "", fmt.Errorf("Failed to write correction: %s", err.Error())
+	}
+	params := model.JobAbsorbParams{
+		FilePaths:    []string{filePath},
+		ChunkSize:    appconfig.FEEDBACK_CORRECTION_CHUNK_SIZE,
+		ChunkOverlap: appconfig.FEEDBACK_CORRECTION_CHUNK_OVERLAP,
+		ChatModelID:  req.ChatModelID,
+		IsEn:         req.IsEn,
+		IsCorrection: true,
+	}
+	if err := createJob(u, jobUUID, types.ACTION_TYPE_ABSORB, cube, memoryGroup, ids, params); err != nil {
+		os.RemoveAll(jobDir)
+		return "", err
+	}
+	return jobUUID, nil
+}
+
+// submitMemifyJob は、Memify をジョブとして登録し、ジョブIDを返します。
+// MemifyLimit は実行の終了時に消費するため、レスポンスの memify_limit は登録時点の値です。
+func submitMemifyJob(c *gin.Context, u *rtutil.RtUtil, ids *common.IDs, cube *model.Cube, perm *model.CubePermissions, epochs int, req *rtreq.MemifyCubeReq, res *rtres.MemifyCubeRes) bool {
+	jobUUID := *common.GenUUID()
+	params := model.JobMemifyParams{
+		Epochs:                  epochs,
+		PrioritizeUnknowns:      req.PrioritizeUnknowns,
+		ConflictResolutionStage: req.ConflictResolutionStage,
+		ChatModelID:             req.ChatModelID,
+		IsEn:                    req.IsEn,
+	}
+	if err := createJob(u, jobUUID, types.ACTION_TYPE_MEMIFY, cube, req.MemoryGroup, ids, params); err != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to create job: %s", err.Error()))
+	}
+	data := rtres.MemifyCubeResData{MemifyLimit: perm.MemifyLimit, JobID: jobUUID}
+	return OK(c, &data, res)
+}
+
+// createJob は、ジョブを実行待ち（queued）として jobs テーブルに登録し、ワーカーに通知します。
+func createJob(u *rtutil.RtUtil, jobUUID string, jobType types.ActionType, cube *model.Cube, memoryGroup string, ids *common.IDs, params any) error {
+	paramsJSON, err := common.ToJson(params)
+	if err != nil {
+		return err
+	}
+	job := model.Job{
+		UUID:        jobUUID,
+		Type:        string(jobType),
+		Status:      jobstatus.QUEUED.Val(),
+		CubeID:      cube.ID,
+		MemoryGroup: memoryGroup,
+		Params:      datatypes.JSON(paramsJSON),
+		UsrID:       *ids.UsrID,
+		ApxID:       *ids.ApxID,
+		VdrID:       *ids.VdrID,
+	}
+	if err := u.DB.Create(&job).Error; err != nil {
+		return err
+	}
+	if u.JobQueue != nil {
+		u.JobQueue.Notify()
+	}
+	return nil
+}
+
+// getJobDirPath は、ジョブの取り込み対象ファイルを保管するディレクトリのパスを返します。
+func getJobDirPath(u *rtutil.RtUtil, jobUUID string) string {
+	return filepath.Join(*u.DBDirPath, appconfig.JOB_FILES_DIR_NAME, jobUUID)
+}
+
+// jobHost は、ジョブを実行するサーバーのホスト名を返します。
+func jobHost(u *rtutil.RtUtil) string {
+	if u.Hostname == nil {
+		return ""
+	}
+	return *u.Hostname
+}
+
+// claimNextJob は、最も古い実行待ちのジョブを running に変更して返します。実行待ちのジョブがない場合は nil を返します。
+// 状態の変更は status = queued を条件とした UPDATE で行うため、複数のワーカー（サーバー）が同じジョブを実行することはありません。
+// 取得したサーバーのホスト名を記録し、再起動時には自身のジョブのみを再実行します（RecoverJobs）。
+func claimNextJob(u *rtutil.RtUtil) (*model.Job, error) {
+	for {
+		var job model.Job
+		if err := u.DB.Where("status = ?", jobstatus.QUEUED.Val()).Order("id").First(&job).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		now := time.Now()
+		r := u.DB.Model(&model.Job{}).Where("id = ? AND status = ?", job.ID, jobstatus.QUEUED.Val()).Updates(map[string]any{
+			"status":     jobstatus.RUNNING.Val(),
+			"started_at": now,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"host":       jobHost(u),
+		})
+		if r.Error != nil {
+			return nil, r.Error
+		}
+		if r.RowsAffected == 1 {
+			job.Status = jobstatus.RUNNING.Val()
+			job.StartedAt = &now
+			job.Attempts++
+			job.Host = jobHost(u)
+			return &job, nil
+		}
+		// 他のワーカーが先に取得した場合は、次のジョブを探す
+	}
+}
+
+// runJob は、ジョブを種類に応じて実行し、結果を jobs テーブルに記録します。
+func runJob(ctx context.Context, u *rtutil.RtUtil, job *model.Job) {
+	// EventBus はリクエスト単位で作成するものなので、ジョブごとに作成する
+	ju := *u
+	ju.EventBus = eventbus.New()
+	progress := &jobProgress{u: &ju, jobID: job.ID}
+	// ジョブIDをオペレーションIDとして登録し、POST /v1/cubes/operations/:operation_id/cancel でキャンセルできるようにする
+	var cubeUUID string
+	if cube, err := getCube(u, job.CubeID, job.ApxID, job.VdrID); err == nil {
+		cubeUUID = cube.UUID
+	}
+	opCtx, _, finishOperation, err := u.CuberService.StartOperation(ctx, job.UUID, types.ActionType(job.Type), cubeUUID, job.MemoryGroup, operationOwner(job.ApxID, job.VdrID, job.UsrID))
+	if err != nil {
+		finishJob(&ju, job, progress, nil, types.TokenUsage{}, err)
+		return
+	}
+	utils.LogInfo(u.Logger, fmt.Sprintf("RunJob: Started job '%s' (%s, attempt %d).", job.UUID, job.Type, job.Attempts))
+	var (
+		result any
+		usage  types.TokenUsage
+	)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("Panic: %v", r)
+			}
+		}()
+		switch types.ActionType(job.Type) {
+		case types.ACTION_TYPE_ABSORB:
+			result, usage, err = runAbsorbJob(opCtx, &ju, job, progress)
+		case types.ACTION_TYPE_MEMIFY:
+			result, usage, err = runMemifyJob(opCtx, &ju, job, progress)
+		case types.ACTION_TYPE_MIGRATE_EMBEDDINGS:
+			result, usage, err = runMigrateEmbeddingsJob(opCtx, &ju, job, progress)
+		default:
+			err = fmt.Errorf("Unknown job type: %s", job.Type)
+		}
+	}()
+	cancelled := err != nil && cuber.IsOperationCancelled(opCtx)
+	finishOperation()
+	if cancelled {
+		err = cuber.ErrOperationCancelled
+	} else if err != nil && ctx.Err() != nil {
+		// サーバーの停止による中断は失敗として扱わず、running のまま残して次回起動時に再実行する
+		utils.LogWarn(u.Logger, fmt.Sprintf("RunJob: Job '%s' was interrupted: %s", job.UUID, err.Error()))
+		return
+	}
+	finishJob(&ju, job, progress, result, usage, err)
+}
+
+// finishJob は、ジョブを終了状態（succeeded / failed / cancelled）にして、結果・エラー・トークン使用量・最後の進捗を記録します。
+// jobErr が cuber.ErrOperationCancelled の場合は cancelled にします。
+// 非同期 Absorb の取り込み対象ファイルは、ジョブの終了時に削除します。
+func finishJob(u *rtutil.RtUtil, job *model.Job, progress *jobProgress, result any, usage types.TokenUsage, jobErr error) {
+	now := time.Now()
+	updates := map[string]any{
+		"finished_at":   now,
+		"input_tokens":  usage.InputTokens,
+		"output_tokens": usage.OutputTokens,
+	}
+	if progress != nil && progress.eventCount > 0 {
+		updates["progress"] = progress.eventName
+		updates["progress_message"] = progress.message
+		updates["event_count"] = progress.eventCount
+	}
+	if errors.Is(jobErr, cuber.ErrOperationCancelled) {
+		updates["status"] = jobstatus.CANCELLED.Val()
+		updates["error"] = jobErr.Error()
+		utils.LogInfo(u.Logger, fmt.Sprintf("RunJob: Job '%s' was cancelled.", job.UUID))
+	} else if jobErr != nil {
+		updates["status"] = jobstatus.FAILED.Val()
+		updates["error"] = jobErr.Error()
+		utils.LogWarn(u.Logger, fmt.Sprintf("RunJob: Job '%s' failed: %s", job.UUID, jobErr.Error()))
+	} else {
+		updates["status"] = jobstatus.SUCCEEDED.Val()
+		resultJSON, err := common.ToJson(result)
+		if err == nil {
+			updates["result"] = datatypes.JSON(resultJSON)
+		}
+		utils.LogInfo(u.Logger, fmt.Sprintf("RunJob: Job '%s' succeeded.", job.UUID))
+	}
+	if err := u.DB.Model(&model.Job{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		utils.LogWarn(u.Logger, fmt.Sprintf("RunJob: Failed to record result of job '%s': %s", job.UUID, err.Error()))
+	}
+	if types.ActionType(job.Type) == types.ACTION_TYPE_ABSORB {
+		os.RemoveAll(getJobDirPath(u, job.UUID))
+	}
+}
+
+// runAbsorbJob は、ジョブとして登録された Absorb を実行し、Limit・統計を更新します。
+// 処理内容は AbsorbCube（同期実行）と同じですが、AbsorbLimit は実行時の値を再確認し、トランザクション内で消費します。
+func runAbsorbJob(ctx context.Context, u *rtutil.RtUtil, job *model.Job, progress *jobProgress) (any, types.TokenUsage, error) {
+	var usage types.TokenUsage
+	params, err := common.ParseDatatypesJson[model.JobAbsorbParams](&job.Params)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to parse job params: %s", err.Error())
+	}
+	progress.isEn = params.IsEn
+	cube, err := getCube(u, job.CubeID, job.ApxID, job.VdrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Cube not found: %s", err.Error())
+	}
+	perm, err := common.ParseDatatypesJson[model.CubePermissions](&cube.Permissions)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to parse permissions: %s", err.Error())
+	}
+	// 登録後に他のリクエストやジョブで使い切られている場合は実行しない
+	if perm.AbsorbLimit < 0 {
+		return nil, usage, errors.New("Absorb limit exceeded.")
+	}
+	cubeDbFilePath, err := u.GetCubeDBFilePath(&cube.UUID, &job.ApxID, &job.VdrID, &job.UsrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to get cube path: %s", err.Error())
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to decrypt embedding API key: %s", err.Error())
+	}
+	chatConf, err := fetchChatModelConfig(u, cube, params.ChatModelID, job.ApxID, job.VdrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to fetch chat model: %s", err.Error())
+	}
+	embeddingConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}
+	// MemoryGroup 設定を UPSERT（フィードバックの訂正文の取り込みでは、既存の設定を変更しない）
+	if !params.IsCorrection {
+		memoryGroupConfig := &storage.MemoryGroupConfig{
+			ID:                         job.MemoryGroup,
+			HalfLifeDays:               common.TOpe(params.HalfLifeDays > 0, params.HalfLifeDays, appconfig.DEFAULT_HALF_LIFE_DAYS),
+			PruneThreshold:             common.TOpe(params.PruneThreshold > 0, params.PruneThreshold, appconfig.DEFAULT_PRUNE_THRESHOLD),
+			MinSurvivalProtectionHours: common.TOpe(params.MinSurvivalProtectionHours > 0, params.MinSurvivalProtectionHours, appconfig.DEFAULT_MIN_SURVIVAL_PROTECTION_HOURS),
+			MdlKNeighbors:              common.TOpe(params.MdlKNeighbors > 0, params.MdlKNeighbors, appconfig.MDL_K_NEIGHBORS),
+		}
+		if err := u.CuberService.UpsertMemoryGroupConfig(ctx, cubeDbFilePath, embeddingConfig, memoryGroupConfig); err != nil {
+			return nil, usage, fmt.Errorf("Failed to upsert memory group config: %s", err.Error())
+		}
+	}
+	usage, err = progress.track(func(dataCh chan<- event.StreamEvent) (types.TokenUsage, error) {
+		return u.CuberService.Absorb(ctx, u.EventBus, cubeDbFilePath, job.MemoryGroup, params.FilePaths, params.SourceID,
+			types.CognifyConfig{
+				ChunkSize:           params.ChunkSize,
+				ChunkOverlap:        params.ChunkOverlap,
+				ChunkMaxRetries:     params.ChunkMaxRetries,
+				MaxFailedChunkRatio: params.MaxFailedChunkRatio,
+			},
+			embeddingConfig,
+			chatConf,
+			dataCh,
+			params.IsEn,
+		)
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("Absorb failed: %s", err.Error())
+	}
+	if usage.InputTokens < 0 || usage.OutputTokens < 0 {
+		return nil, usage, errors.New("Invalid token usage reported.")
+	}
+	contributorName, err := getJwtUsrName(u, &job.ApxID, &job.VdrID, &job.UsrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to get contributor name: %s", err.Error())
+	}
+	// DBトランザクション (Limit更新 & Stats更新)
+	var newAbsorbLimit int
+	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
+		var txCube model.Cube
+		if err := tx.Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).First(&txCube).Error; err != nil {
+			return err
+		}
+		txPerm, err := common.ParseDatatypesJson[model.CubePermissions](&txCube.Permissions)
+		if err != nil {
+			return err
+		}
+		if txPerm.AbsorbLimit > 0 {
+			txPerm.AbsorbLimit--
+			if txPerm.AbsorbLimit == 0 {
+				txPerm.AbsorbLimit = -1 // 0は無制限なので、使い切ったら-1(禁止)にする
+			}
+			newPermJSON, err := common.ToJson(txPerm)
+			if err != nil {
+				return err
+			}
+			txCube.Permissions = datatypes.JSON(newPermJSON)
+			if err := tx.Save(&txCube).Error; err != nil {
+				return err
+			}
+		}
+		newAbsorbLimit = txPerm.AbsorbLimit
+		return recordCubeUsage(tx, &txCube, job.MemoryGroup, types.ACTION_TYPE_ABSORB, contributorName, usage)
+	})
+	if txErr != nil {
+		return nil, usage, fmt.Errorf("DB update failed: %s", txErr.Error())
+	}
+	return rtres.AbsorbCubeResData{
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		AbsorbLimit:  newAbsorbLimit,
+	}, usage, nil
+}
+
+// runMemifyJob は、ジョブとして登録された Memify を実行し、Limit・統計を更新します。
+// 処理内容は MemifyCube（同期実行）と同じですが、MemifyLimit は実行時の値を再確認します。
+func runMemifyJob(ctx context.Context, u *rtutil.RtUtil, job *model.Job, progress *jobProgress) (any, types.TokenUsage, error) {
+	var usage types.TokenUsage
+	params, err := common.ParseDatatypesJson[model.JobMemifyParams](&job.Params)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to parse job params: %s", err.Error())
+	}
+	progress.isEn = params.IsEn
+	cube, err := getCube(u, job.CubeID, job.ApxID, job.VdrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Cube not found: %s", err.Error())
+	}
+	perm, err := common.ParseDatatypesJson[model.CubePermissions](&cube.Permissions)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to parse permissions: %s", err.Error())
+	}
+	// 登録後に他のリクエストやジョブで使い切られている場合は実行しない
+	if perm.MemifyLimit < 0 {
+		return nil, usage, errors.New("Memify limit exceeded.")
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, &job.ApxID, &job.VdrID, &job.UsrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to get cube path: %s", err.Error())
+	}
+	decryptedEmbeddingApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to decrypt embedding API key: %s", err.Error())
+	}
+	chatConf, err := fetchChatModelConfig(u, cube, params.ChatModelID, job.ApxID, job.VdrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to fetch chat model: %s", err.Error())
+	}
+	embeddingConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    decryptedEmbeddingApiKey,
+	}
+	usage, err = progress.track(func(dataCh chan<- event.StreamEvent) (types.TokenUsage, error) {
+		return u.CuberService.Memify(ctx, u.EventBus, cubeDBFilePath, job.MemoryGroup,
+			&types.MemifyConfig{
+				RecursiveDepth:          params.Epochs - 1, // epochs=1 means depth=0
+				PrioritizeUnknowns:      params.PrioritizeUnknowns,
+				ConflictResolutionStage: int(params.ConflictResolutionStage),
+			},
+			embeddingConfig,
+			chatConf,
+			dataCh,
+			params.IsEn,
+		)
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("Memify failed: %s", err.Error())
+	}
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+		return nil, usage, errors.New("Token accounting failed: no tokens recorded.")
+	}
+	contributorName, err := getJwtUsrName(u, &job.ApxID, &job.VdrID, &job.UsrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to get contributor name: %s", err.Error())
+	}
+	// DBトランザクションで Limit更新 + CubeModelStat + CubeContributor 更新
+	var newMemifyLimit int
+	txErr := u.DB.Transaction(func(tx *gorm.DB) error {
+		var txCube model.Cube
+		if err := tx.Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID).First(&txCube).Error; err != nil {
+			return err
+		}
+		txPerm, err := common.ParseDatatypesJson[model.CubePermissions](&txCube.Permissions)
+		if err != nil {
+			return err
+		}
+		if txPerm.MemifyLimit > 0 {
+			txPerm.MemifyLimit--
+			if txPerm.MemifyLimit == 0 {
+				txPerm.MemifyLimit = -1 // 0は無制限を意味するので、-1に変更して禁止にする
+			}
+			newPermJSON, err := common.ToJson(txPerm)
+			if err != nil {
+				return err
+			}
+			txCube.Permissions = datatypes.JSON(newPermJSON)
+			if err := tx.Save(&txCube).Error; err != nil {
+				return err
+			}
+		}
+		newMemifyLimit = txPerm.MemifyLimit
+		return recordCubeUsage(tx, &txCube, job.MemoryGroup, types.ACTION_TYPE_MEMIFY, contributorName, usage)
+	})
+	if txErr != nil {
+		return nil, usage, fmt.Errorf("Transaction failed: %s", txErr.Error())
+	}
+	return rtres.MemifyCubeResData{
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		MemifyLimit:  newMemifyLimit,
+	}, usage, nil
+}
+
+// runMigrateEmbeddingsJob は、ジョブとして登録された埋め込みモデルの移行を実行します。
+// Cube の埋め込みモデル設定は、Cube のカラムの置き換えと同じタイミング（Cube のトランザクション内）で更新します。
+func runMigrateEmbeddingsJob(ctx context.Context, u *rtutil.RtUtil, job *model.Job, progress *jobProgress) (any, types.TokenUsage, error) {
+	var usage types.TokenUsage
+	params, err := common.ParseDatatypesJson[model.JobMigrateEmbeddingsParams](&job.Params)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to parse job params: %s", err.Error())
+	}
+	progress.isEn = params.IsEn
+	cube, err := getCube(u, job.CubeID, job.ApxID, job.VdrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Cube not found: %s", err.Error())
+	}
+	cubeDBFilePath, err := u.GetCubeDBFilePath(&cube.UUID, &job.ApxID, &job.VdrID, &job.UsrID)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to get cube path: %s", err.Error())
+	}
+	currentApiKey, err := mycrypto.Decrypt(cube.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to decrypt embedding API key: %s", err.Error())
+	}
+	newApiKey, err := mycrypto.Decrypt(params.EmbeddingApiKey, u.CuberCryptoSkey)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Failed to decrypt new embedding API key: %s", err.Error())
+	}
+	currentConfig := types.EmbeddingModelConfig{
+		Provider:  cube.EmbeddingProvider,
+		Model:     cube.EmbeddingModel,
+		Dimension: cube.EmbeddingDimension,
+		BaseURL:   cube.EmbeddingBaseURL,
+		ApiKey:    currentApiKey,
+	}
+	newConfig := types.EmbeddingModelConfig{
+		Provider:  params.EmbeddingProvider,
+		Model:     params.EmbeddingModel,
+		Dimension: params.EmbeddingDimension,
+		BaseURL:   params.EmbeddingBaseURL,
+		ApiKey:    newApiKey,
+	}
+	cubeEmbeddingFields := func(provider string, modelName string, dimension uint, baseURL string, encryptedApiKey string) map[string]any {
+		return map[string]any{
+			"embedding_provider":  provider,
+			"embedding_model":     modelName,
+			"embedding_dimension": dimension,
+			"embedding_base_url":  baseURL,
+			"embedding_api_key":   encryptedApiKey,
+		}
+	}
+	cubeQuery := func() *gorm.DB {
+		return u.DB.Model(&model.Cube{}).Where("id = ? AND apx_id = ? AND vdr_id = ?", cube.ID, cube.ApxID, cube.VdrID)
+	}
+	cubeUpdated := false
+	onComplete := func() error {
+		if err := cubeQuery().Updates(cubeEmbeddingFields(params.EmbeddingProvider, params.EmbeddingModel, params.EmbeddingDimension, params.EmbeddingBaseURL, params.EmbeddingApiKey)).Error; err != nil {
+			return fmt.Errorf("Failed to update cube: %s", err.Error())
+		}
+		cubeUpdated = true
+		return nil
+	}
+	var migrated int
+	usage, err = progress.track(func(dataCh chan<- event.StreamEvent) (types.TokenUsage, error) {
+		n, usage, err := u.CuberService.MigrateEmbeddings(ctx, u.EventBus, cubeDBFilePath, currentConfig, newConfig, onComplete, dataCh)
+		migrated = n
+		return usage, err
+	})
+	if err != nil {
+		// Cube の更新後にコミットに失敗した場合は、Cube の設定を元に戻す
+		if cubeUpdated {
+			if rerr := cubeQuery().Updates(cubeEmbeddingFields(cube.EmbeddingProvider, cube.EmbeddingModel, cube.EmbeddingDimension, cube.EmbeddingBaseURL, cube.EmbeddingApiKey)).Error; rerr != nil {
+				utils.LogWarn(u.Logger, fmt.Sprintf("RunJob: Failed to restore embedding settings of cube %d: %s", cube.ID, rerr.Error()))
+			}
+		}
+		return nil, usage, fmt.Errorf("Embedding migration failed: %s", err.Error())
+	}
+	return rtres.MigrateCubeEmbeddingsJobResult{
+		MigratedCount:      migrated,
+		EmbeddingProvider:  params.EmbeddingProvider,
+		EmbeddingModel:     params.EmbeddingModel,
+		EmbeddingDimension: params.EmbeddingDimension,
+		InputTokens:        usage.InputTokens,
+		OutputTokens:       usage.OutputTokens,
+	}, usage, nil
+}
+
+// jobProgress は、実行中のジョブで発火したイベント（EVENT_ABSORB_* / EVENT_MEMIFY_*）を進捗として jobs テーブルに記録します。
+// イベントごとに書き込むと DB への負荷が大きいため、JOB_PROGRESS_UPDATE_INTERVAL_MS 以上の間隔を空けて最新の状態のみを書き込みます。
+type jobProgress struct {
+	u          *rtutil.RtUtil
+	jobID      uint
+	isEn       bool      // イベントメッセージの言語
+	eventName  string    // 最後に発火したイベント名
+	message    string    // 最後に発火したイベントのメッセージ
+	eventCount int       // これまでに発火したイベント数
+	savedAt    time.Time // 最後に DB に書き込んだ時刻
+}
+
+// track は、run を実行しながら、run に渡したチャネルに届くイベントを進捗として記録します。
+// run は別のゴルーチンで実行するため、run のパニックはここで復旧してエラーとして返します。
+func (p *jobProgress) track(run func(dataCh chan<- event.StreamEvent) (types.TokenUsage, error)) (types.TokenUsage, error) {
+	type runResult struct {
+		Usage types.TokenUsage
+		Err   error
+	}
+	dataCh := make(chan event.StreamEvent)
+	resultCh := make(chan runResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				resultCh <- runResult{Err: fmt.Errorf("Panic: %v", r)}
+			}
+		}()
+		usage, err := run(dataCh)
+		resultCh <- runResult{Usage: usage, Err: err}
+	}()
+	for {
+		select {
+		case evt := <-dataCh:
+			p.observe(evt)
+		case result := <-resultCh:
+			return result.Usage, result.Err
+		}
+	}
+}
+
+// observe は、イベントを最新の進捗として保持し、前回の書き込みから一定時間が経過していれば DB に書き込みます。
+func (p *jobProgress) observe(evt event.StreamEvent) {
+	p.eventCount++
+	p.eventName = string(evt.EventName)
+	if msg, err := event.FormatEvent(evt, p.isEn); err == nil {
+		p.message = msg
+	}
+	if time.Since(p.savedAt) < time.Duration(appconfig.JOB_PROGRESS_UPDATE_INTERVAL_MS)*time.Millisecond {
+		return
+	}
+	p.savedAt = time.Now()
+	if err := p.u.DB.Model(&model.Job{}).Where("id = ?", p.jobID).Updates(map[string]any{
+		"progress":         p.eventName,
+		"progress_message": p.message,
+		"event_count":      p.eventCount,
+	}).Error; err != nil {
+		utils.LogWarn(p.u.Logger, fmt.Sprintf("RunJob: Failed to record progress of job %d: %s", p.jobID, err.Error()))
+	}
+}
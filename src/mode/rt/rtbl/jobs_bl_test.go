@@ -0,0 +1,260 @@
+package rtbl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/enum/jobstatus"
+	"github.com/t-kawata/mycute/mode/rt/rtreq"
+	"github.com/t-kawata/mycute/mode/rt/rtres"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
+	"github.com/t-kawata/mycute/model"
+	"github.com/t-kawata/mycute/pkg/cuber"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testJobHost = "host-a"
+
+// newTestJobUtil は、jobs テーブルだけを持つメモリ上の SQLite を DB とする RtUtil を返します。
+func newTestJobUtil(t *testing.T) *rtutil.RtUtil {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1) // メモリ上のデータベースは接続ごとに別になるため
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&model.Job{}); err != nil {
+		t.Fatalf("Failed to migrate jobs: %v", err)
+	}
+	hostname := testJobHost
+	dbDirPath := t.TempDir()
+	return &rtutil.RtUtil{DB: db, Hostname: &hostname, DBDirPath: &dbDirPath, CuberService: &cuber.CuberService{}}
+}
+
+// mustCreateJob は、指定した状態のジョブを登録します。
+func mustCreateJob(t *testing.T, u *rtutil.RtUtil, job model.Job) model.Job {
+	t.Helper()
+	if job.Type == "" {
+		job.Type = string(types.ACTION_TYPE_MEMIFY)
+	}
+	if job.ApxID == 0 {
+		job.ApxID, job.VdrID, job.UsrID = 1, 2, 3
+	}
+	if err := u.DB.Create(&job).Error; err != nil {
+		t.Fatalf("Failed to create job: %v", err)
+	}
+	return job
+}
+
+// mustGetJob は、ジョブを UUID で取得し直します。
+func mustGetJob(t *testing.T, u *rtutil.RtUtil, jobUUID string) model.Job {
+	t.Helper()
+	var job model.Job
+	if err := u.DB.Where("uuid = ?", jobUUID).First(&job).Error; err != nil {
+		t.Fatalf("Failed to get job '%s': %v", jobUUID, err)
+	}
+	return job
+}
+
+func TestClaimNextJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		jobs     []model.Job
+		takenBy  string // 取得の直前に他のワーカーが running にするジョブ
+		want     string // 取得されるジョブ（空の場合は nil）
+		attempts int
+	}{
+		{
+			name: "no jobs",
+		},
+		{
+			name: "oldest queued job",
+			jobs: []model.Job{
+				{UUID: "j1", Status: jobstatus.SUCCEEDED.Val()},
+				{UUID: "j2", Status: jobstatus.QUEUED.Val(), Attempts: 1},
+				{UUID: "j3", Status: jobstatus.QUEUED.Val()},
+			},
+			want:     "j2",
+			attempts: 2,
+		},
+		{
+			name: "running and cancelled jobs are skipped",
+			jobs: []model.Job{
+				{UUID: "j1", Status: jobstatus.RUNNING.Val()},
+				{UUID: "j2", Status: jobstatus.CANCELLED.Val()},
+			},
+		},
+		{
+			name: "job taken by another worker",
+			jobs: []model.Job{
+				{UUID: "j1", Status: jobstatus.QUEUED.Val()},
+				{UUID: "j2", Status: jobstatus.QUEUED.Val()},
+			},
+			takenBy:  "j1",
+			want:     "j2",
+			attempts: 1,
+		},
+		{
+			name: "only job taken by another worker",
+			jobs: []model.Job{
+				{UUID: "j1", Status: jobstatus.QUEUED.Val()},
+			},
+			takenBy: "j1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestJobUtil(t)
+			for _, job := range tt.jobs {
+				mustCreateJob(t, u, job)
+			}
+			if tt.takenBy != "" {
+				// 実行待ちのジョブを読み取った直後に、他のワーカーが同じジョブを取得した状況を再現する
+				taken := false
+				u.DB.Callback().Query().After("gorm:query").Register("test:take_job", func(db *gorm.DB) {
+					if taken {
+						return
+					}
+					taken = true
+					db.Session(&gorm.Session{NewDB: true}).Model(&model.Job{}).Where("uuid = ?", tt.takenBy).
+						Updates(map[string]any{"status": jobstatus.RUNNING.Val(), "host": "host-b"})
+				})
+			}
+			got, err := claimNextJob(u)
+			if err != nil {
+				t.Fatalf("claimNextJob failed: %v", err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("Expected no job, got '%s'", got.UUID)
+				}
+				return
+			}
+			if got == nil || got.UUID != tt.want {
+				t.Fatalf("Expected job '%s', got %+v", tt.want, got)
+			}
+			stored := mustGetJob(t, u, tt.want)
+			if stored.Status != jobstatus.RUNNING.Val() || stored.Host != testJobHost || stored.Attempts != tt.attempts || stored.StartedAt == nil {
+				t.Errorf("Unexpected claimed job: status=%s host=%s attempts=%d started_at=%v", stored.Status, stored.Host, stored.Attempts, stored.StartedAt)
+			}
+			if got.Status != stored.Status || got.Host != stored.Host || got.Attempts != stored.Attempts {
+				t.Errorf("Returned job differs from stored job: %+v", got)
+			}
+			if tt.takenBy != "" {
+				if taken := mustGetJob(t, u, tt.takenBy); taken.Host != "host-b" || taken.Attempts != 0 {
+					t.Errorf("Job taken by another worker was claimed again: host=%s attempts=%d", taken.Host, taken.Attempts)
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverJobs(t *testing.T) {
+	u := newTestJobUtil(t)
+	jobs := []model.Job{
+		{UUID: "own-running", Status: jobstatus.RUNNING.Val(), Host: testJobHost, Attempts: 1},
+		{UUID: "own-exhausted", Status: jobstatus.RUNNING.Val(), Host: testJobHost, Attempts: appconfig.JOB_MAX_ATTEMPTS},
+		{UUID: "other-running", Status: jobstatus.RUNNING.Val(), Host: "host-b", Attempts: 1},
+		{UUID: "own-queued", Status: jobstatus.QUEUED.Val()},
+		{UUID: "own-succeeded", Status: jobstatus.SUCCEEDED.Val(), Host: testJobHost, Attempts: 1},
+	}
+	for _, job := range jobs {
+		mustCreateJob(t, u, job)
+	}
+	if err := RecoverJobs(u); err != nil {
+		t.Fatalf("RecoverJobs failed: %v", err)
+	}
+	tests := []struct {
+		uuid       string
+		wantStatus jobstatus.JobStatus
+	}{
+		{"own-running", jobstatus.QUEUED},
+		{"own-exhausted", jobstatus.FAILED},
+		{"other-running", jobstatus.RUNNING},
+		{"own-queued", jobstatus.QUEUED},
+		{"own-succeeded", jobstatus.SUCCEEDED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uuid, func(t *testing.T) {
+			if got := mustGetJob(t, u, tt.uuid); got.Status != tt.wantStatus.Val() {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus.Val())
+			}
+		})
+	}
+}
+
+func TestCancelQueuedJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	apxID, vdrID, usrID := uint(1), uint(2), uint(3)
+	finishedAt := time.Now()
+	tests := []struct {
+		name       string
+		job        model.Job
+		cancelID   string
+		wantCode   int
+		wantStatus jobstatus.JobStatus
+	}{
+		{
+			name:       "queued job",
+			job:        model.Job{UUID: "j1", Status: jobstatus.QUEUED.Val()},
+			cancelID:   "j1",
+			wantCode:   http.StatusOK,
+			wantStatus: jobstatus.CANCELLED,
+		},
+		{
+			name:       "queued job of another user",
+			job:        model.Job{UUID: "j1", Status: jobstatus.QUEUED.Val(), ApxID: 1, VdrID: 2, UsrID: 4},
+			cancelID:   "j1",
+			wantCode:   http.StatusNotFound,
+			wantStatus: jobstatus.QUEUED,
+		},
+		{
+			name:       "finished job",
+			job:        model.Job{UUID: "j1", Status: jobstatus.SUCCEEDED.Val(), FinishedAt: &finishedAt},
+			cancelID:   "j1",
+			wantCode:   http.StatusConflict,
+			wantStatus: jobstatus.SUCCEEDED,
+		},
+		{
+			name:       "unknown operation",
+			job:        model.Job{UUID: "j1", Status: jobstatus.QUEUED.Val()},
+			cancelID:   "unknown",
+			wantCode:   http.StatusNotFound,
+			wantStatus: jobstatus.QUEUED,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestJobUtil(t)
+			mustCreateJob(t, u, tt.job)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			ju := &rtutil.JwtUsr{ApxID: &apxID, VdrID: &vdrID, UsrID: &usrID}
+			req := &rtreq.CancelOperationReq{OperationID: tt.cancelID}
+			res := &rtres.CancelOperationRes{Errors: []rtres.Err{}}
+			CancelOperation(c, u, ju, req, res)
+			if w.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+			got := mustGetJob(t, u, tt.job.UUID)
+			if got.Status != tt.wantStatus.Val() {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus.Val())
+			}
+			if tt.wantStatus == jobstatus.CANCELLED && got.FinishedAt == nil {
+				t.Errorf("finished_at is not set")
+			}
+		})
+	}
+}
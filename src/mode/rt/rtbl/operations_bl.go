@@ -0,0 +1,161 @@
+package rtbl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/t-kawata/mycute/enum/jobstatus"
+	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/mode/rt/rtreq"
+	"github.com/t-kawata/mycute/mode/rt/rtres"
+	"github.com/t-kawata/mycute/mode/rt/rtstream"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
+	"github.com/t-kawata/mycute/model"
+	"github.com/t-kawata/mycute/pkg/cuber"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+// OPERATION_ID_HEADER は、同期実行の Absorb / Memify / Query のオペレーションIDを受け渡すヘッダーです。
+// リクエストに UUID 形式で指定するとそのIDでオペレーションを登録するため、
+// レスポンスを待たずにキャンセルできます。指定しない場合はサーバーが生成したIDをレスポンスヘッダーで返します。
+// ストリームモードでは、最初の SSE イベント（event: operation）でもオペレーションIDを送信します。
+const OPERATION_ID_HEADER = "X-Operation-ID"
+
+// errInvalidOperationID は、リクエストで指定されたオペレーションIDが UUID 形式でない場合に返されます。
+var errInvalidOperationID = errors.New("Operation ID must be a UUID.")
+
+// ListOperations は、リクエストしたユーザーが実行中のオペレーションの一覧を返します。
+func ListOperations(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.ListOperationsReq, res *rtres.ListOperationsRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	if u.CuberService == nil {
+		return InternalServerErrorCustomMsg(c, res, "CuberService is not available.")
+	}
+	ops := u.CuberService.ListOperations(operationOwner(*ids.ApxID, *ids.VdrID, *ids.UsrID))
+	return OK(c, new(rtres.ListOperationsResData).Of(ops), res)
+}
+
+// CancelOperation は、実行中のオペレーション（Absorb / Memify / Query）をキャンセルします。
+// キャンセルは要求のみで、オペレーションの終了（Cube のロールバック）は待ちません。
+// 非同期ジョブはジョブIDがオペレーションIDとなり、実行待ちのジョブはその場で cancelled になります。
+// キャンセルできるのはオペレーションを開始した本人のみです。
+func CancelOperation(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr, req *rtreq.CancelOperationReq, res *rtres.CancelOperationRes) bool {
+	ids := ju.IDs(!(ju.IsApx() || ju.IsFromKey())) // USRだけが使用可能なので
+	if u.CuberService == nil {
+		return InternalServerErrorCustomMsg(c, res, "CuberService is not available.")
+	}
+	// 1. 実行中のオペレーション
+	if op, ok := u.CuberService.GetOperation(req.OperationID); ok && op.Owner == operationOwner(*ids.ApxID, *ids.VdrID, *ids.UsrID) {
+		if op.Cancelled {
+			return ConflictCustomMsg(c, res, "Operation is already being cancelled.")
+		}
+		if cancelled, err := u.CuberService.CancelOperation(req.OperationID); err == nil {
+			return OK(c, cancellingOperationResData(cancelled), res)
+		}
+		// 確認とキャンセルの間に終了した場合は、非同期ジョブとして確認する
+	}
+	// 2. 実行待ちの非同期ジョブ（まだオペレーションとして登録されていない）
+	// 同期実行のオペレーションは終了すると記録が残らないため、終了後は未知のIDと同じく 404 となる
+	var job model.Job
+	if err := u.DB.Where("uuid = ? AND apx_id = ? AND vdr_id = ? AND usr_id = ?", req.OperationID, *ids.ApxID, *ids.VdrID, *ids.UsrID).First(&job).Error; err != nil {
+		return NotFoundCustomMsg(c, res, "Operation not found.")
+	}
+	r := u.DB.Model(&model.Job{}).Where("id = ? AND status = ?", job.ID, jobstatus.QUEUED.Val()).Updates(map[string]any{
+		"status":      jobstatus.CANCELLED.Val(),
+		"finished_at": time.Now(),
+	})
+	if r.Error != nil {
+		return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to cancel job: %s", r.Error.Error()))
+	}
+	if r.RowsAffected == 0 {
+		// 確認と更新の間に実行を開始した場合は、実行中のオペレーションとしてキャンセルする
+		if cancelled, err := u.CuberService.CancelOperation(job.UUID); err == nil {
+			return OK(c, cancellingOperationResData(cancelled), res)
+		}
+		return ConflictCustomMsg(c, res, "Operation has already finished.")
+	}
+	if types.ActionType(job.Type) == types.ACTION_TYPE_ABSORB {
+		os.RemoveAll(getJobDirPath(u, job.UUID))
+	}
+	return OK(c, &rtres.CancelOperationResData{
+		OperationID:   job.UUID,
+		OperationType: job.Type,
+		MemoryGroup:   job.MemoryGroup,
+		Status:        jobstatus.CANCELLED.Val(),
+	}, res)
+}
+
+// cancellingOperationResData は、キャンセルを要求した実行中のオペレーションのレスポンスデータを返します。
+func cancellingOperationResData(op cuber.Operation) *rtres.CancelOperationResData {
+	return &rtres.CancelOperationResData{
+		OperationID:   op.ID,
+		OperationType: string(op.Type),
+		MemoryGroup:   op.MemoryGroup,
+		Status:        "cancelling",
+	}
+}
+
+// startOperation は、同期実行の Absorb / Memify / Query をオペレーションとして登録し、
+// オペレーションIDを X-Operation-ID ヘッダーに設定します。
+// リクエストの X-Operation-ID ヘッダーにIDが指定されている場合は、そのIDで登録します。
+// 返されたコンテキストで CuberService を呼び出し、終了時に返された関数を呼び出してください。
+// ストリームモードでは、返されたオペレーションIDを最初の SSE イベントとして送信してください。
+// エラーは startOperationFailed でレスポンスに変換します。
+func startOperation(c *gin.Context, u *rtutil.RtUtil, ids *common.IDs, opType types.ActionType, cubeUUID string, memoryGroup string) (context.Context, string, func(), error) {
+	requested := c.GetHeader(OPERATION_ID_HEADER)
+	if requested != "" {
+		if _, err := uuid.Parse(requested); err != nil {
+			return nil, "", nil, errInvalidOperationID
+		}
+		// 非同期ジョブのIDと重複すると、ジョブの実行やキャンセルと衝突するため拒否する
+		var count int64
+		if err := u.DB.Model(&model.Job{}).Where("uuid = ?", requested).Count(&count).Error; err != nil {
+			return nil, "", nil, fmt.Errorf("Failed to check operation ID: %w", err)
+		}
+		if count > 0 {
+			return nil, "", nil, cuber.ErrOperationAlreadyRunning
+		}
+	}
+	ctx, operationID, finish, err := u.CuberService.StartOperation(c.Request.Context(), requested, opType, cubeUUID, memoryGroup, operationOwner(*ids.ApxID, *ids.VdrID, *ids.UsrID))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	c.Header(OPERATION_ID_HEADER, operationID)
+	return ctx, operationID, finish, nil
+}
+
+// startOperationFailed は、startOperation のエラーをレスポンスに変換します。
+// 不正なオペレーションIDは 400、使用中のオペレーションIDは 409 となります。
+func startOperationFailed[T any](c *gin.Context, res *T, err error) bool {
+	switch {
+	case errors.Is(err, errInvalidOperationID):
+		return BadRequestCustomMsg(c, res, err.Error())
+	case errors.Is(err, cuber.ErrOperationAlreadyRunning):
+		return ConflictCustomMsg(c, res, "Operation ID is already in use.")
+	}
+	return InternalServerErrorCustomMsg(c, res, fmt.Sprintf("Failed to start operation: %s", err.Error()))
+}
+
+// operationOwner は、オペレーションの所有者の識別子を返します。
+func operationOwner(apxID uint, vdrID uint, usrID uint) string {
+	return fmt.Sprintf("%d-%d-%d", apxID, vdrID, usrID)
+}
+
+// operationCancelled は、キャンセルされたオペレーションのレスポンスを返します。
+// ストリームモードでは、キャンセルされた旨をストリームに送信してからストリームを閉じます。
+func operationCancelled[T any](c *gin.Context, res *T, streamWriter *rtstream.StreamWriter, label string) bool {
+	msg := fmt.Sprintf("%s was cancelled.", label)
+	if streamWriter != nil {
+		tokens := rtstream.Tokenize(fmt.Sprintf("\n%s\n", msg), TOKEN_SIZE)
+		for _, token := range tokens {
+			streamWriter.Write(token)
+		}
+		streamWriter.Close()
+		streamWriter.Wait()
+	}
+	return BadRequestCustomMsg(c, res, msg)
+}
@@ -90,14 +90,27 @@ func CreateCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 // @Description - `is_en`: 抽出アルゴリズムの最適化モード (true: 英語, false: 日本語)。抽出されるグラフの言語は入力テキストの言語を維持します。
 // @Description - `as_json`: ストリームモード時の最終出力形式 (true: JSON, false: 自然言語テキスト)。ストリーム時に is_en に応じた読みやすいメッセージではなくJSON文字列を受け取りたい場合にtrueを指定します。
 // @Description - `conflict_resolution_stage`: 矛盾解決の深度 (0: 無効, 1: 決定論的ルールのみ, 2: LLMによる高度な裁定)
-// @Accept application/json
+// @Description - `multipart/form-data` で送信する場合、`file` フィールド（複数可）にファイルを添付できる。その他のパラメータは同名のフォームフィールドで指定する
+// @Description - 対応形式: PDF, DOCX, XLSX, PPTX, EPUB, CSV/TSV, テキスト/Markdown/HTML。ページ・シート・スライド・章の区切りは Document のメタデータに記録される
+// @Description - `content` と `file` はいずれか一方が必須（併用可）
+// @Description - `source_id`: 論理的な文書ID（任意）。同じ `source_id` で再度取り込むと、以前の版のチャンク・要約・グラフへの寄与が取り消され、新しい版に置き換えられる。他の文書でも裏付けられているエッジは重みを下げて残る
+// @Description - `async`: true の場合、ジョブとして登録して即座に `job_id` を返す（`stream` と併用不可）。進捗・トークン使用量・結果は GET /v1/jobs/{job_id} で確認する
+// @Description - `async` を省略した場合（デフォルト: false）は同期実行となり、ジョブは作成されない。サーバーの停止で中断した同期実行は再実行されないため、長時間の処理には `async` = true を推奨する
+// @Description - `chunk_max_retries`: グラフ抽出に失敗したチャンクを再試行する回数 (0-10, デフォルト: 2)
+// @Description - `max_failed_chunk_ratio`: 再試行しても失敗したチャンクをスキップして続行できる割合 (0-1, デフォルト: 0 = 1チャンクでも失敗したらエラー)
+// @Description - 失敗した Absorb のチャンクと抽出済みのグラフはチェックポイントとして保存され、同じ入力で再実行すると完了済みのチャンクから再開する
+// @Accept application/json,multipart/form-data
+// @Description - `X-Operation-ID` リクエストヘッダーに UUID を指定すると、そのIDでオペレーションを登録する。レスポンスを待たずに `POST /v1/cubes/operations/{operation_id}/cancel` でキャンセルできる（使用中のIDは 409）
+// @Description - `X-Operation-ID` を省略した場合はサーバーがIDを生成し、レスポンスヘッダー `X-Operation-ID` で返す。ストリームモードでは、最初の SSE イベント（`event: operation`、`data: {"operation_id": "..."}`）でも返す
 // @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param X-Operation-ID header string false "オペレーションID（UUID。省略時はサーバーが生成）"
 // @Param json body AbsorbCubeParam true "json"
 // @Success 200 {object} AbsorbCubeRes{errors=[]int}
 // @Failure 400 {object} ErrRes
 // @Failure 401 {object} ErrRes
 // @Failure 403 {object} ErrRes
 // @Failure 404 {object} ErrRes
+// @Failure 409 {object} ErrRes
 // @Failure 500 {object} ErrRes
 func AbsorbCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
@@ -246,6 +259,50 @@ func ReKeyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 // @Description | 9 | QUERY_TYPE_GET_GRAPH_SUMMARY_TO_ANSWER | 知識グラフを、クエリにダイレクトに答えられる形式の要約文で取得 (言語はis_enで制御) |
 // @Description | 10 | QUERY_TYPE_ANSWER_BY_PRE_MADE_SUMMARIES_AND_GRAPH_SUMMARY | 事前に作成された要約リストと、知識グラフ要約を用いて質問に回答 (言語はis_enで制御) |
 // @Description | 11 | QUERY_TYPE_ANSWER_BY_CHUNKS_AND_GRAPH_SUMMARY | ベクトル検索によるチャンクと知識グラフ要約を用いて質問に回答 (言語はis_enで制御) |
+// @Description | 20 | QUERY_TYPE_CYCLER | `text` に記述した読み取り専用の Cypher クエリを実行し、結果を `table` に表形式で返す |
+// @Description | 21 | QUERY_TYPE_NATURAL_LANGUAGE | 質問を LLM で Cypher クエリに変換して実行し、その結果から回答 (件数の集計など、正確な値が必要な質問向け。言語はis_enで制御) |
+// @Description | 22 | QUERY_TYPE_GRAPH_COMPLETION_COT | チャンクと知識グラフで回答案を作り、LLM による批評と追加検索を繰り返してから回答 (複数の事実をつなぐ質問向け。言語はis_enで制御) |
+// @Description | 26 | QUERY_TYPE_TEMPORAL | `from` / `to` の期間に観測されたチャンクと知識グラフから、時間の経過を踏まえて回答 (「3月時点の状況」「先週からの変化」など。言語はis_enで制御) |
+// @Description | 29 | QUERY_TYPE_HYBRID | ベクトル検索・FTS (3レイヤー)・知識グラフの各ランキングを Reciprocal Rank Fusion で融合したチャンクと知識グラフから回答 (固有名詞や型番など、字句の一致が重要な質問向け。言語はis_enで制御) |
+// @Description | 30 | QUERY_TYPE_GLOBAL | 知識グラフのコミュニティの要約を map-reduce して回答 (「取り込んだ知識全体の主なテーマは？」など、知識全体にわたる質問向け。言語はis_enで制御) |
+// @Description ---
+// @Description ### Cypher クエリ (type = 20)
+// @Description - Cube の権限 `allow_cypher` が true の場合のみ実行できる（作成直後の Cube では false。所有者が PUT /v1/cubes/cypher で許可する）
+// @Description - 読み取り専用: CREATE / MERGE / SET / DELETE / REMOVE / DROP / ALTER / COMMENT / COPY / LOAD / CALL 等を含むクエリ、および複数ステートメントは拒否される (400)。クエリは読み取り専用トランザクションで実行される
+// @Description - 参照できるテーブル: GraphNode, GraphEdge, Chunk, Document, Data, Summary, Entity, Rule, Unknown, Capability, EdgeProvenance, HAS_DOCUMENT, HAS_CHUNK, NEXT_CHUNK
+// @Description - MATCH 句のノード・リレーションには `memory_group` の条件が自動的に付与される。パターン内で `memory_group` を指定することはできない。可変長リレーション（例: `[:GraphEdge*1..3]`）は使用できない
+// @Description - 結果は最大1000行で打ち切られ (`table.truncated` = true)、10秒を超えるクエリは中断される
+// @Description - ノード・リレーションの値は `_label` とプロパティのオブジェクトとして返される (embedding は除外)
+// @Description - LLM を使用しないため、トークンは消費されない (QueryLimit は消費される)
+// @Description - 例: `MATCH (a:GraphNode)-[e:GraphEdge]->(b:GraphNode) WHERE e.type = 'works_for' RETURN a.id, b.id LIMIT 10`
+// @Description - type = 21 (自然言語) では、LLM が生成したクエリに同じ検証・制限が適用される。検証や実行に失敗した場合はエラー内容を渡して最大3回まで再生成する (`allow_cypher` は不要)
+// @Description ---
+// @Description ### 推論型クエリ (type = 22)
+// @Description - `chunk_topk` と `entity_topk` が必須 (各検索ごとの取得数)
+// @Description - 回答案を LLM が批評し、不足があれば追加の検索クエリ (1ラウンド最大3件) でチャンクとグラフを再検索する
+// @Description - `cot_rounds`: 批評と追加検索の最大ラウンド数 (0 = デフォルト3, 最大5)。回答案が十分と判断されるか、新しい情報が得られなくなった時点で終了する
+// @Description - 各ラウンドの批評と追加クエリは、ストリームで `QUERY_REASONING_FOLLOW_UP` / `QUERY_REASONING_SUFFICIENT` イベントとして通知される
+// @Description - `graph` と `citations` には全ラウンドで取得した知識が含まれる
+// @Description ---
+// @Description ### 時系列クエリ (type = 26)
+// @Description - `chunk_topk` と `entity_topk` が必須
+// @Description - `from` / `to`: 対象期間 (形式: `2025-03-01T00:00:00`、サーバーのローカル時刻)。省略した側は無制限
+// @Description - チャンクは元データの取り込み日時、知識グラフはエッジの観測日時 (`edge.unix`) で絞り込まれる。`thickness_threshold` の時間減衰は期間の終端を基準に計算される
+// @Description - 「現在の勤務先」のような排他的な関係に複数の値が観測されている場合、矛盾解決で破棄せずに変遷として回答に含める (`conflict_resolution_stage` は無視される)
+// @Description ---
+// @Description ### ハイブリッド検索 (type = 29)
+// @Description - `chunk_topk` と `entity_topk` が必須。各ランキングの候補数は `chunk_topk` の3倍で、融合後の上位 `chunk_topk` 件のチャンクを回答に使用する
+// @Description - ランキング: チャンクのベクトル類似度、チャンクの FTS (名詞 / 名詞+動詞 / 全内容語)、知識グラフ上の中心性 (チャンクを出典とするエッジの Thickness × 両端ノードの次数)
+// @Description - 融合スコア: 各ランキングでの順位 r に対して `重み / (60 + r)` の合計
+// @Description - `hybrid_weight_vector` / `hybrid_weight_fts_nouns` / `hybrid_weight_fts_nouns_verbs` / `hybrid_weight_fts_keywords` / `hybrid_weight_graph`: 各ランキングの重み (0〜10)。0 のランキングは使用しない。全て 0 (省略) の場合はデフォルト (1.0 / 0.5 / 0.5 / 0.5 / 0.5)
+// @Description - 融合結果は、ストリームで `QUERY_HYBRID_FUSION_END` イベントとして通知される。`chunks` には融合後のチャンクが含まれる
+// @Description ---
+// @Description ### グローバル検索 (type = 30)
+// @Description - Absorb / Memify の完了時に、知識グラフを Louvain 法で階層的なコミュニティに分割し (エッジの重みは Thickness)、各コミュニティのタイトルと要約を LLM で生成して Cube に保存している。所属ノードと内部のエッジが変わっていないコミュニティは以前の要約を再利用する
+// @Description - `community_level`: 使用するコミュニティの階層 (0 = 最上位の大きなコミュニティ, 数字が大きいほど細かい)。存在しない階層を指定した場合は最も細かい階層を使用する
+// @Description - map: 要約を重要度 (コミュニティ内の Thickness の合計) の高い順に分割し、各部分から質問に関連する要点を重要度スコア付きで抽出する
+// @Description - reduce: スコアの高い要点 (最大30件) から回答を生成する
+// @Description - `chunk_topk` / `entity_topk` 等は使用しない。コミュニティがまだ作成されていない場合、回答は空になる
 // @Description ---
 // @Description ### FTS (Full-Text Search) によるエンティティ拡張
 // @Description `fts_topk` が 1 以上の時、ベクトル検索でヒットしたエンティティ名をキーワードとしてチャンクを全文検索し、関連エンティティを補強します。
@@ -256,13 +313,24 @@ func ReKeyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 // @Description 精度向上のため中間推論（Reasoning）は常に英語で行われますが、最終回答は指定された言語で直接生成されます。
 // @Description `conflict_resolution_stage` を指定することで、回答生成前に最新の知識矛盾を解消し、より正確な根拠に基づいた回答が可能になります。
 // @Description - `as_json`: ストリームモード時の最終出力形式 (true: JSON, false: 自然言語テキスト)。ストリーム時に is_en に応じた読みやすいメッセージではなくJSON文字列を受け取りたい場合にtrueを指定します。
+// @Description ---
+// @Description ### 出典 (citations)
+// @Description レスポンスの `citations` には、回答の根拠となったチャンクの出典（ファイル名 `file_name`、チャンク順序 `chunk_index`、`data_id`、`source_id`）が関連度順に含まれます。
+// @Description ベクトル検索でヒットしたチャンクと、`graph` の各エッジの抽出元チャンク（`edge.source_chunk_ids`）が対象です。出典管理の導入前に取り込まれた知識や Memify で生成された知識には出典がありません。
+// @Description ---
+// @Description ### フィードバック (query_id)
+// @Description レスポンスの `query_id` を `POST /v1/cubes/feedback` に指定すると、回答の根拠となった `graph` のエッジを評価 (up / down) や訂正によって強化・弱化できます。
+// @Description - `X-Operation-ID` リクエストヘッダーに UUID を指定すると、そのIDでオペレーションを登録する。レスポンスを待たずに `POST /v1/cubes/operations/{operation_id}/cancel` でキャンセルできる（使用中のIDは 409）
+// @Description - `X-Operation-ID` を省略した場合はサーバーがIDを生成し、レスポンスヘッダー `X-Operation-ID` で返す。ストリームモードでは、最初の SSE イベント（`event: operation`、`data: {"operation_id": "..."}`）でも返す
 // @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param X-Operation-ID header string false "オペレーションID（UUID。省略時はサーバーが生成）"
 // @Param json body QueryCubeParam true "json"
 // @Success 200 {object} QueryCubeRes{errors=[]int}
 // @Failure 400 {object} ErrRes
 // @Failure 401 {object} ErrRes
 // @Failure 403 {object} ErrRes
 // @Failure 404 {object} ErrRes
+// @Failure 409 {object} ErrRes
 // @Failure 500 {object} ErrRes
 func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
@@ -275,6 +343,48 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 	}
 }
 
+// @Tags v1 Cube
+// @Router /v1/cubes/feedback [post]
+// @Summary クエリの回答にフィードバックする (Feedback)
+// @Description - USR によってのみ使用できる
+// @Description - クエリを実行した本人のみ実行でき、1つのクエリに対して1回のみフィードバックできる
+// @Description - `query_id` には `POST /v1/cubes/query` のレスポンスの `query_id` を指定する
+// @Description - 回答の根拠として使用されたエッジ（レスポンスの `graph`）の `weight` / `confidence` を、代謝モデルに従って更新する
+// @Description ### 評価 (rating)
+// @Description | rating | 効果 |
+// @Description | :--- | :--- |
+// @Description | up | エッジを強化する（confidence を α、weight を α/2 だけ増加し、観測時刻を現在時刻に更新） |
+// @Description | down | エッジを弱化する（confidence を δ だけ減少）。生存スコア weight × confidence が淘汰閾値を下回ったエッジは削除される |
+// @Description ---
+// @Description ### 訂正 (correction)
+// @Description - `correction` に正しい内容を指定すると、`rating` = down と同様にエッジを弱化した上で、訂正文を新しい知識として取り込む (Absorb)
+// @Description - 訂正文の取り込みには `chat_model_id` が必須で、Absorb の回数制限 (`absorb_limit`) を1回消費する
+// @Description - `rating` = up と `correction` は同時に指定できない
+// @Description - 訂正文の取り込みはオペレーションとして登録され、レスポンスヘッダー `X-Operation-ID` の ID で `POST /v1/cubes/operations/{operation_id}/cancel` によりキャンセルできる（エッジへの反映は取り消されない）
+// @Description - `async` = true の場合、エッジへの反映後に訂正文の取り込みをジョブとして登録し、`job_id` を返す。`absorb_limit` はジョブの完了時に消費されるため、レスポンスの値は登録時点のもの
+// @Description - `async` を省略した場合（デフォルト: false）は訂正文の取り込みを同期実行し、ジョブは作成されない
+// @Description - `X-Operation-ID` リクエストヘッダーに UUID を指定すると、訂正文の取り込みをそのIDのオペレーションとして登録する（使用中のIDは 409）
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param X-Operation-ID header string false "オペレーションID（UUID。省略時はサーバーが生成）"
+// @Param json body FeedbackCubeParam true "json"
+// @Success 200 {object} FeedbackCubeRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 409 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func FeedbackCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.FeedbackCubeReqBind(c, u); ok {
+		rtbl.FeedbackCube(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
 // @Tags v1 Cube
 // @Router /v1/cubes/memify [put]
 // @Summary Cubeを自己強化する (Memify)
@@ -286,14 +396,20 @@ func QueryCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 // @Description - `conflict_resolution_stage`: 矛盾解決の深度 (0: 無効, 1: 決定論的ルールのみ, 2: LLMによる高度な裁定)
 // @Description - MDL (Minimum Description Length) 原理に基づき、情報価値の低い（弱接続な）ノードや孤立ノードを自動的に削除してグラフ構造を最適化します。
 // @Description - `as_json`: ストリームモード時の最終出力形式 (true: JSON, false: 自然言語テキスト)。ストリーム時に is_en に応じた読みやすいメッセージではなくJSON文字列を受け取りたい場合にtrueを指定します。
+// @Description - `async`: true の場合、ジョブとして登録して即座に `job_id` を返す（`stream` と併用不可）。進捗・トークン使用量・結果は GET /v1/jobs/{job_id} で確認する
+// @Description - `async` を省略した場合（デフォルト: false）は同期実行となり、ジョブは作成されない。サーバーの停止で中断した同期実行は再実行されないため、長時間の処理には `async` = true を推奨する
 // @Accept application/json
+// @Description - `X-Operation-ID` リクエストヘッダーに UUID を指定すると、そのIDでオペレーションを登録する。レスポンスを待たずに `POST /v1/cubes/operations/{operation_id}/cancel` でキャンセルできる（使用中のIDは 409）
+// @Description - `X-Operation-ID` を省略した場合はサーバーがIDを生成し、レスポンスヘッダー `X-Operation-ID` で返す。ストリームモードでは、最初の SSE イベント（`event: operation`、`data: {"operation_id": "..."}`）でも返す
 // @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param X-Operation-ID header string false "オペレーションID（UUID。省略時はサーバーが生成）"
 // @Param json body MemifyCubeParam true "json"
 // @Success 200 {object} MemifyCubeRes{errors=[]int}
 // @Failure 400 {object} ErrRes
 // @Failure 401 {object} ErrRes
 // @Failure 403 {object} ErrRes
 // @Failure 404 {object} ErrRes
+// @Failure 409 {object} ErrRes
 // @Failure 500 {object} ErrRes
 func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
@@ -306,6 +422,426 @@ func MemifyCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 	}
 }
 
+// @Tags v1 Cube
+// @Router /v1/cubes/cypher [put]
+// @Summary Cypher クエリの実行可否を設定する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - Cypher クエリ (query_type = 20) の実行を許可するかを設定する（権限 `allow_cypher`）
+// @Description - 新しく作成した Cube では許可されていない。利用する場合はこの API で許可する
+// @Description - インポートした Cube の権限は鍵によって決まるため、変更できない
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body SetCubeCypherParam true "json"
+// @Success 200 {object} SetCubeCypherRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func SetCubeCypher(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.SetCubeCypherReqBind(c, u); ok {
+		rtbl.SetCubeCypher(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/fallbacks [put]
+// @Summary フォールバック先のチャットモデルを設定する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - Absorb / Memify / Query / Feedback で指定したチャットモデルが、レート制限（429）・サーバーエラー（5xx）・タイムアウトで再試行しても失敗した場合に、`chat_model_ids` の順に使用するチャットモデルを設定する
+// @Description - プロバイダーごとのサーキットブレーカーが開いている（連続して失敗している）チャットモデルは呼び出さずに、次のチャットモデルを使用する
+// @Description - 実際に応答したチャットモデルのトークン使用量が、そのチャットモデルの名前で統計（CubeModelStat）に記録される
+// @Description - 全件を置き換える。空配列を指定するとフォールバックを解除する
+// @Description - 設定できるのは最大5件まで。重複は不可
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body SetCubeChatModelFallbacksParam true "json"
+// @Success 200 {object} SetCubeChatModelFallbacksRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func SetCubeChatModelFallbacks(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.SetCubeChatModelFallbacksReqBind(c, u); ok {
+		rtbl.SetCubeChatModelFallbacks(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/prompts [get]
+// @Summary メモリーグループのプロンプトの一覧を取得する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - 指定したメモリーグループの Absorb / Memify / Query で使用されるプロンプトを、上書きできる全てのプロンプトについて返す
+// @Description - `content` は実際に使用されるプロンプト（上書きがない場合はデフォルト）
+// @Description - `placeholders` は上書きに含める必要がある書式指定子（出現順）。空の場合は不要
+// @Description - `versions` は上書きの履歴。無効にしたバージョンも含む
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Success 200 {object} ListCubePromptsRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func ListCubePrompts(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.ListCubePromptsReqBind(c, u); ok {
+		rtbl.ListCubePrompts(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/prompts [put]
+// @Summary メモリーグループのプロンプトを上書きする
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - 指定したメモリーグループで、`name` のプロンプトの代わりに `content` を使用する（以降の Absorb / Memify / Query から適用される）
+// @Description - 上書きは新しいバージョンとして保存され、以前のバージョンは無効になる（履歴として残る）
+// @Description - デフォルトのプロンプトに書式指定子（例: `%s`）が含まれる場合は、同じ書式指定子を同じ順で含める必要がある。それ以外の `%` は `%%` と記述する
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body SetCubePromptParam true "json"
+// @Success 200 {object} SetCubePromptRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func SetCubePrompt(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.SetCubePromptReqBind(c, u); ok {
+		rtbl.SetCubePrompt(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/prompts [delete]
+// @Summary メモリーグループのプロンプトの上書きを解除する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - 指定したメモリーグループの `name` のプロンプトの上書きを無効にし、デフォルトのプロンプトに戻す（履歴は残る）
+// @Description - 有効な上書きがない場合は 404 を返す
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Param name query string true "Prompt Name"
+// @Success 200 {object} ResetCubePromptRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func ResetCubePrompt(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.ResetCubePromptReqBind(c, u); ok {
+		rtbl.ResetCubePrompt(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/ontology [get]
+// @Summary メモリーグループのオントロジーを取得する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - オントロジーが定義されていない場合は 404 を返す
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Success 200 {object} GetCubeOntologyRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func GetCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.GetCubeOntologyReqBind(c, u); ok {
+		rtbl.GetCubeOntology(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/ontology [put]
+// @Summary メモリーグループのオントロジーを設定する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - メモリーグループで使用するエンティティタイプ・関係タイプを定義する（既存の定義は置き換えられる）
+// @Description - 定義したタイプは Absorb のグラフ抽出プロンプトに追加され、抽出されたタイプは同義語（`synonyms`）から正式名（`name`）に正規化される
+// @Description - `domain` / `range` を指定した関係は、ソース・ターゲットのエンティティタイプが一致しない場合に除外される
+// @Description - `strict` が true の場合は、定義にないタイプのノード・エッジを除外する
+// @Description - `cardinality` が `one` の関係は排他的な関係として扱われ、Memify / Query の矛盾解決（Stage 1）でソースごとに1つの値だけが残る（組み込みの排他的関係リストの代わりに使用される）
+// @Description - タイプ名・同義語は小文字化などで正規化される。保存済みのノード・エッジのタイプは変更されない
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body SetCubeOntologyParam true "json"
+// @Success 200 {object} SetCubeOntologyRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func SetCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.SetCubeOntologyReqBind(c, u); ok {
+		rtbl.SetCubeOntology(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/ontology [delete]
+// @Summary メモリーグループのオントロジーを削除する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - 削除後は、LLM が自由にタイプを決め、排他的な関係は組み込みのリストで判定される
+// @Description - オントロジーが定義されていない場合は 404 を返す
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Success 200 {object} DeleteCubeOntologyRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func DeleteCubeOntology(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.DeleteCubeOntologyReqBind(c, u); ok {
+		rtbl.DeleteCubeOntology(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/graph/paths [post]
+// @Summary 2つのエンティティ間の経路を検索する
+// @Description - USR によってのみ使用できる
+// @Description - 知識グラフ上で `source` から `target` までの経路を、ホップ数（エッジ数）の少ない順に最大 `k` 件返す
+// @Description - ホップ数が同じ経路は、経路上のエッジの Thickness が大きいものが優先される。各経路の `thickness` は経路上のエッジの Thickness の最小値
+// @Description - エッジの向きは無視して辿る。`triples` の各エッジは保存されている向きのまま返される
+// @Description - `source` / `target` にはエンティティ名を指定する（グラフ抽出時と同様に正規化して照合する）
+// @Description ### 絞り込み
+// @Description | パラメータ | 説明 |
+// @Description | :--- | :--- |
+// @Description | max_hops | 経路のホップ数の上限（1〜6、省略時は 4） |
+// @Description | thickness_threshold | Thickness がこの値未満のエッジは辿らない（省略時は 0.3） |
+// @Description | edge_types | 辿るエッジのタイプ（省略時は全てのタイプ） |
+// @Description - 探索は `source` 側・`target` 側の双方から行い、それぞれ最大 5000 ノードで打ち切る。エッジの非常に多いエンティティ同士では、`max_hops` 以内の経路があっても返されないことがある
+// @Description ---
+// @Description ### 経路の説明 (narrate)
+// @Description - `narrate` = true の場合、`chat_model_id` のチャットモデルで経路を自然な文章として説明し、`narration` に返す
+// @Description - 説明を生成した場合は、Query の回数制限 (`query_limit`) を1回消費する（経路がなかった場合は消費しない）
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body FindCubeGraphPathsParam true "json"
+// @Success 200 {object} FindCubeGraphPathsRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func FindCubeGraphPaths(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.FindCubeGraphPathsReqBind(c, u); ok {
+		rtbl.FindCubeGraphPaths(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/graph/entities [get]
+// @Summary 知識グラフのエンティティを検索する
+// @Description - USR によってのみ使用できる
+// @Description - 名前または別名（エンティティ解決で記録された aliases）に `q` を含むエンティティを返す（グラフ抽出時と同様に正規化して照合する）
+// @Description - 名前が `q` と一致するもの、`q` で始まるもの、`q` を含むもの、別名のみが一致するものの順に並ぶ
+// @Description - `q` を省略した場合は全てのエンティティを ID 順に返す
+// @Description - Query の回数制限 (`query_limit`) は消費しないが、回数制限を使い切った Cube では使用できない
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Param q query string false "検索文字列"
+// @Param type query string false "エンティティのタイプ（例: Person）"
+// @Param offset query int false "スキップする件数"
+// @Param limit query int false "返す最大件数（1〜100、省略時は 20）"
+// @Success 200 {object} SearchCubeGraphEntitiesRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func SearchCubeGraphEntities(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.SearchCubeGraphEntitiesReqBind(c, u); ok {
+		rtbl.SearchCubeGraphEntities(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/graph/entity [get]
+// @Summary 知識グラフのエンティティを取得する
+// @Description - USR によってのみ使用できる
+// @Description - エンティティのタイプ・プロパティ・別名 (`aliases`)・接続するエッジ数 (`degree`) を返す
+// @Description - `entity_id` には `GET /v1/cubes/graph/entities` のレスポンスの `id` を指定する
+// @Description - Query の回数制限 (`query_limit`) は消費しないが、回数制限を使い切った Cube では使用できない
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Param entity_id query string true "Entity ID"
+// @Success 200 {object} GetCubeGraphEntityRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func GetCubeGraphEntity(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.GetCubeGraphEntityReqBind(c, u); ok {
+		rtbl.GetCubeGraphEntity(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/graph/neighbors [get]
+// @Summary エンティティに隣接するノードの一覧を取得する
+// @Description - USR によってのみ使用できる
+// @Description - エンティティと直接つながっているノードを、接続するエッジの Thickness が大きい順に返す
+// @Description - 同じノードと複数のエッジでつながっている場合は、エッジごとに1件返す
+// @Description - `direction` は、out = エンティティ → 隣接ノード、in = 隣接ノード → エンティティ
+// @Description - Query の回数制限 (`query_limit`) は消費しないが、回数制限を使い切った Cube では使用できない
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Param entity_id query string true "Entity ID"
+// @Param offset query int false "スキップする件数"
+// @Param limit query int false "返す最大件数（1〜100、省略時は 20）"
+// @Success 200 {object} ListCubeGraphNeighborsRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func ListCubeGraphNeighbors(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.ListCubeGraphNeighborsReqBind(c, u); ok {
+		rtbl.ListCubeGraphNeighbors(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/graph/subgraph [get]
+// @Summary エンティティを中心としたサブグラフを取得する
+// @Description - USR によってのみ使用できる
+// @Description - エンティティから `depth` ホップ以内のノード（エゴネットワーク）と、それらの間のトリプルを返す
+// @Description - ノードは中心から近い順に、同じ深さでは太いエッジでつながったものから順に含め、`max_nodes` に達した時点で打ち切る（`truncated` = true）
+// @Description - Thickness が `min_thickness` 未満のエッジは辿らず、結果にも含めない
+// @Description - Query の回数制限 (`query_limit`) は消費しないが、回数制限を使い切った Cube では使用できない
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Param entity_id query string true "Entity ID"
+// @Param depth query int false "深さ（1〜3、省略時は 1）"
+// @Param max_nodes query int false "ノード数の上限（1〜500、省略時は 100）"
+// @Param min_thickness query number false "辿るエッジの Thickness の下限（0〜1、省略時は 0）"
+// @Success 200 {object} GetCubeGraphSubgraphRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func GetCubeGraphSubgraph(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.GetCubeGraphSubgraphReqBind(c, u); ok {
+		rtbl.GetCubeGraphSubgraph(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/embeddings/migrate [post]
+// @Summary 埋め込みモデルを移行する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - Cube の埋め込みモデル（プロバイダー・モデル・次元数）を変更し、Chunk / Entity / Summary / Rule / Unknown / Capability を新しいモデルで再ベクトル化する
+// @Description - Cube を作り直さないため、Memify の結果を含む既存の知識はそのまま保持される
+// @Description - 常にジョブとして実行され、`job_id` を返す。進捗・トークン使用量・結果は GET /v1/jobs/{job_id} で確認し、POST /v1/cubes/operations/{job_id}/cancel でキャンセルできる
+// @Description - 移行が完了するまで、Query / Absorb / Memify は現在の埋め込みモデルで実行できる。完了時に Cube の埋め込みモデル設定が切り替わる
+// @Description - 失敗・キャンセルした場合、Cube は移行前の状態のまま残る
+// @Description - 新しい埋め込みモデルの設定は、Cube の作成時と同様に検証される（実際に埋め込みを作成して次元数を確認する）
+// @Accept application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param json body MigrateCubeEmbeddingsParam true "json"
+// @Success 200 {object} MigrateCubeEmbeddingsRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func MigrateCubeEmbeddings(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.MigrateCubeEmbeddingsReqBind(c, u); ok {
+		rtbl.MigrateCubeEmbeddings(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
 // @Tags v1 Cube
 // @Router /v1/cubes/delete [delete]
 // @Summary Cubeを削除する (Delete)
@@ -329,3 +865,117 @@ func DeleteCube(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
 		rtbl.BadRequest(c, &res)
 	}
 }
+
+// @Tags v1 Cube
+// @Router /v1/cubes/data [get]
+// @Summary 取り込み済みデータの一覧を取得する
+// @Description - USR によってのみ使用できる
+// @Description - 指定したメモリーグループに Absorb で取り込まれたデータ（文書）の一覧を返す
+// @Description - 返却される `id` は `DELETE /v1/cubes/data/{data_id}` で使用できる
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Success 200 {object} ListCubeDataRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func ListCubeData(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.ListCubeDataReqBind(c, u); ok {
+		rtbl.ListCubeData(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/data/{data_id} [delete]
+// @Summary 取り込み済みデータを削除する
+// @Description - USR によってのみ使用できる
+// @Description - Cube の所有者のみ実行できる
+// @Description - 指定したデータ（文書）と、そこから生成された Document / Chunk / 要約 / FTS インデックスエントリを削除する
+// @Description - このデータのみを出典とするエッジは削除され、それにより孤立したノードも削除される
+// @Description - 他の文書でも裏付けられているエッジは、重みを下げて残る
+// @Description - このデータのみに由来するエンティティの別名と、このデータのテキストの埋め込みキャッシュも削除される
+// @Description - 削除したエッジに関わるコミュニティの要約は消去され、次回の Absorb / Memify で要約し直される
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param data_id path string true "Data ID"
+// @Param cube_id query int true "Cube ID"
+// @Param memory_group query string true "Memory Group"
+// @Success 200 {object} DeleteCubeDataRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func DeleteCubeData(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.DeleteCubeDataReqBind(c, u); ok {
+		rtbl.DeleteCubeData(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/operations [get]
+// @Summary 実行中のオペレーションの一覧を取得する
+// @Description - USR によってのみ使用できる
+// @Description - 自分が実行中の Absorb / Memify / Query（実行中の非同期ジョブを含む）を開始時刻の順に返す
+// @Description - 返却される `operation_id` は `POST /v1/cubes/operations/{operation_id}/cancel` で使用できる
+// @Description - 同期実行の Absorb / Memify / Query は、レスポンスヘッダー `X-Operation-ID` でもオペレーションIDを返す（ストリームモードでは最初の SSE イベント `event: operation` でも届く）
+// @Description - 同期実行の Absorb / Memify / Query は、リクエストヘッダー `X-Operation-ID` に UUID を指定すると、そのIDでオペレーションを登録する（ストリームでないリクエストをレスポンスの前にキャンセルするために使用する）
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Success 200 {object} ListOperationsRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func ListOperations(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.ListOperationsReqBind(c, u); ok {
+		rtbl.ListOperations(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
+
+// @Tags v1 Cube
+// @Router /v1/cubes/operations/{operation_id}/cancel [post]
+// @Summary 実行中のオペレーションをキャンセルする
+// @Description - USR によってのみ使用できる（キャンセルできるのはオペレーションを開始した本人のみ）
+// @Description - 実行中の Absorb / Memify / Query をキャンセルし、Cube への変更をロールバックする
+// @Description - キャンセルの要求のみを行い、ロールバックの完了は待たない（`status` = cancelling）
+// @Description - キャンセルされたリクエストには、ストリームに `INFO_OPERATION_CANCELLED` イベントが送信され、「... was cancelled.」のエラーが返る。Limit は消費されない
+// @Description - 非同期ジョブはジョブIDで指定する。実行中のジョブは failed ではなく cancelled になり、実行待ちのジョブはその場で cancelled になる（`status` = cancelled）
+// @Description - 同期実行のオペレーションは、レスポンスヘッダー・SSE イベント、またはリクエストヘッダー `X-Operation-ID` で指定したIDで指定する
+// @Description - 未知のID、および終了した同期実行のオペレーションは 404、終了済みのジョブとキャンセル中のオペレーションは 409 となる
+// @Produce application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param operation_id path string true "Operation ID（非同期ジョブの場合はジョブID）"
+// @Success 200 {object} CancelOperationRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 409 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func CancelOperation(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.CancelOperationReqBind(c, u); ok {
+		rtbl.CancelOperation(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
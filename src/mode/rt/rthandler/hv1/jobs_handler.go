@@ -0,0 +1,40 @@
+package hv1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/t-kawata/mycute/enum/usrtype"
+	"github.com/t-kawata/mycute/mode/rt/rtbl"
+	"github.com/t-kawata/mycute/mode/rt/rtreq"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
+)
+
+// @Tags v1 Job
+// @Router /v1/jobs/{job_id} [get]
+// @Summary 非同期ジョブの状態を取得する
+// @Description - USR によってのみ使用できる
+// @Description - `async` = true で登録した Absorb / Memify のジョブ、および埋め込みモデルの移行のジョブの状態を返す（参照できるのはジョブを登録した本人のみ）
+// @Description - `status`: queued（実行待ち）, running（実行中）, succeeded（正常終了）, failed（異常終了）, cancelled（キャンセル。`POST /v1/cubes/operations/{job_id}/cancel` でキャンセルできる）
+// @Description - `progress` / `progress_message`: 最後に発火したイベント（ABSORB_* / MEMIFY_*）の名前とメッセージ。進捗は一定間隔でまとめて記録される
+// @Description - `input_tokens` / `output_tokens`: 終了時のトークン使用量
+// @Description - `result`: 正常終了時の結果。同期実行時のレスポンスの data と同じ形式（AbsorbCubeResData / MemifyCubeResData）
+// @Description - ジョブは DB に保存され、サーバーが再起動しても実行待ち・実行中のジョブは再実行される（`attempts` は実行を開始した回数）
+// @Description - ジョブが作成されるのは `async` = true を指定したリクエストのみ。`async` を省略した（デフォルトの）同期実行の Absorb / Memify はジョブとして記録されず、この API では参照できない。再起動時の再実行の対象にもならない
+// @Produce application/json
+// @Param Authorization header string true "token" example(Bearer ??????????)
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} GetJobRes{errors=[]int}
+// @Failure 400 {object} ErrRes
+// @Failure 401 {object} ErrRes
+// @Failure 403 {object} ErrRes
+// @Failure 404 {object} ErrRes
+// @Failure 500 {object} ErrRes
+func GetJob(c *gin.Context, u *rtutil.RtUtil, ju *rtutil.JwtUsr) {
+	if rtbl.RejectUsr(c, u, ju, []usrtype.UsrType{usrtype.KEY, usrtype.APX, usrtype.VDR}) {
+		return
+	}
+	if req, res, ok := rtreq.GetJobReqBind(c, u); ok {
+		rtbl.GetJob(c, u, ju, &req, &res)
+	} else {
+		rtbl.BadRequest(c, &res)
+	}
+}
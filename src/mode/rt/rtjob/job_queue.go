@@ -0,0 +1,83 @@
+package rtjob
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunNextFunc は、実行待ちのジョブを1件取り出して実行する関数です。
+// 実行したジョブがなかった場合は false を返します。
+type RunNextFunc func(ctx context.Context) bool
+
+// JobQueue は非同期ジョブを実行するワーカープール。
+// キューの実体は DB（jobs テーブル）であり、JobQueue はワーカーの起動と、新しいジョブの登録をワーカーに通知する役割のみを持つ。
+// 通知を取りこぼした場合や、他のサーバーで登録されたジョブも、pollInterval ごとの確認で実行される。
+type JobQueue struct {
+	workers      int
+	pollInterval time.Duration
+	notify       chan struct{}
+	logger       *zap.Logger
+	startOnce    sync.Once
+	wg           sync.WaitGroup
+}
+
+// NewJobQueue は新しい JobQueue を作成する。
+// workers: 並行して実行するワーカー数（1未満の場合は1）
+// pollInterval: 通知がない場合にワーカーが実行待ちのジョブを確認する間隔
+func NewJobQueue(workers int, pollInterval time.Duration, l *zap.Logger) *JobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &JobQueue{
+		workers:      workers,
+		pollInterval: pollInterval,
+		notify:       make(chan struct{}, workers),
+		logger:       l,
+	}
+}
+
+// Start はワーカーを起動する。2回目以降の呼び出しは無視される。
+// 各ワーカーは runNext が false を返すまでジョブを実行し続け、その後は通知か pollInterval の経過を待つ。
+// ctx がキャンセルされると、実行中のジョブの終了後にワーカーは停止する。
+func (q *JobQueue) Start(ctx context.Context, runNext RunNextFunc) {
+	q.startOnce.Do(func() {
+		for i := 0; i < q.workers; i++ {
+			q.wg.Add(1)
+			go q.work(ctx, runNext)
+		}
+		q.logger.Info("JobQueue: Started workers.", zap.Int("workers", q.workers))
+	})
+}
+
+// Notify は新しいジョブが登録されたことをワーカーに通知する。
+// 全てのワーカーが実行中の場合でもブロックしない。
+func (q *JobQueue) Notify() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Wait は全てのワーカーが停止するまでブロックする。
+func (q *JobQueue) Wait() {
+	q.wg.Wait()
+}
+
+func (q *JobQueue) work(ctx context.Context, runNext RunNextFunc) {
+	defer q.wg.Done()
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil && runNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.notify:
+		case <-ticker.C:
+		}
+	}
+}
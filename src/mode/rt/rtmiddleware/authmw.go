@@ -12,6 +12,7 @@ import (
 	"github.com/t-kawata/mycute/lib/eventbus"
 	"github.com/t-kawata/mycute/lib/httpclient"
 	"github.com/t-kawata/mycute/lib/s3client"
+	"github.com/t-kawata/mycute/mode/rt/rtjob"
 	"github.com/t-kawata/mycute/mode/rt/rtres"
 	"github.com/t-kawata/mycute/mode/rt/rtutil"
 	"github.com/t-kawata/mycute/pkg/cuber"
@@ -23,7 +24,7 @@ const UTIL_KEY = "RUTIL"
 
 const JWT_U_KEY = "JWT_U"
 
-func AuthMiddleware(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, cuberCryptoSkey *string, s3c *s3client.S3Client, dbDirPath *string, cuberService *cuber.CuberService) gin.HandlerFunc {
+func AuthMiddleware(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, cuberCryptoSkey *string, s3c *s3client.S3Client, dbDirPath *string, cuberService *cuber.CuberService, jobQueue *rtjob.JobQueue) gin.HandlerFunc {
 	authSkipTargets := []string{
 		"/v1/keys/check",
 		"/v1/keys/generate",
@@ -33,7 +34,7 @@ func AuthMiddleware(r *gin.Engine, l *zap.Logger, env *config.Env, hc *httpclien
 		"/v1/usrs/auth/:apx_id/:vdr_id",
 	}
 	return func(c *gin.Context) {
-		u := initRequest(l, env, hc, hn, db, sk, cuberCryptoSkey, s3c, dbDirPath, cuberService)
+		u := initRequest(l, env, hc, hn, db, sk, cuberCryptoSkey, s3c, dbDirPath, cuberService, jobQueue)
 		ju := &rtutil.JwtUsr{}
 		fp := c.FullPath()
 		if slices.Contains(authSkipTargets, fp) {
@@ -107,7 +108,7 @@ func authFailed(c *gin.Context, res *rtres.DummyRes) {
 	c.Abort()
 }
 
-func initRequest(l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, cuberCryptoSkey *string, s3c *s3client.S3Client, dbDirPath *string, cuberService *cuber.CuberService) (u *rtutil.RtUtil) {
+func initRequest(l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *string, db *gorm.DB, sk *string, cuberCryptoSkey *string, s3c *s3client.S3Client, dbDirPath *string, cuberService *cuber.CuberService, jobQueue *rtjob.JobQueue) (u *rtutil.RtUtil) {
 	u = &rtutil.RtUtil{
 		Logger:          l,
 		Env:             env,
@@ -120,6 +121,7 @@ func initRequest(l *zap.Logger, env *config.Env, hc *httpclient.HttpClient, hn *
 		DBDirPath:       dbDirPath,
 		CuberService:    cuberService,
 		EventBus:        eventbus.New(), // リクエスト単位でイベントを発行できるようにする
+		JobQueue:        jobQueue,
 	}
 	return
 }
@@ -27,11 +27,15 @@ type ImportCubeParam struct {
 type AbsorbCubeParam struct {
 	CubeID                     uint    `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
 	MemoryGroup                string  `json:"memory_group" swaggertype:"string" format:"" example:"legal_expert"`
-	Content                    string  `json:"content" swaggertype:"string" format:"" example:"Knowledge base for Go development"`
+	Content                    string  `json:"content" swaggertype:"string" format:"" example:"Knowledge base for Go development"` // multipart/form-data 時は file（複数可）でファイルを添付可能
+	SourceID                   string  `json:"source_id" swaggertype:"string" format:"" example:"contracts/2024-001"`
 	ChunkSize                  int     `json:"chunk_size" swaggertype:"integer" format:"" example:"512"`
 	ChunkOverlap               int     `json:"chunk_overlap" swaggertype:"integer" format:"" example:"16"`
+	ChunkMaxRetries            int     `json:"chunk_max_retries" swaggertype:"integer" format:"" example:"2"`
+	MaxFailedChunkRatio        float64 `json:"max_failed_chunk_ratio" swaggertype:"number" format:"" example:"0.1"`
 	ChatModelID                uint    `json:"chat_model_id" swaggertype:"integer" format:"" example:"1"`
 	Stream                     bool    `json:"stream" swaggertype:"boolean" format:"" example:"false"`
+	Async                      bool    `json:"async" swaggertype:"boolean" format:"" example:"false"`
 	AsJson                     bool    `json:"as_json" swaggertype:"boolean" format:"" example:"false"`
 	IsEn                       bool    `json:"is_en" swaggertype:"boolean" format:"" example:"false"`
 	HalfLifeDays               float64 `json:"half_life_days" swaggertype:"number" format:"" example:"30"`
@@ -40,27 +44,104 @@ type AbsorbCubeParam struct {
 	MdlKNeighbors              int     `json:"mdl_k_neighbors" swaggertype:"integer" format:"" example:"5"`
 } // @name AbsorbCubeParam
 
+type SetCubeCypherParam struct {
+	CubeID      uint `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
+	AllowCypher bool `json:"allow_cypher" swaggertype:"boolean" format:"" example:"true"`
+} // @name SetCubeCypherParam
+
+type SetCubeChatModelFallbacksParam struct {
+	CubeID       uint   `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
+	ChatModelIDs []uint `json:"chat_model_ids" swaggertype:"array,integer" format:"" example:"2,3"`
+} // @name SetCubeChatModelFallbacksParam
+
+type SetCubePromptParam struct {
+	CubeID      uint   `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
+	MemoryGroup string `json:"memory_group" swaggertype:"string" format:"" example:"legal_expert"`
+	Name        string `json:"name" swaggertype:"string" format:"" example:"GENERATE_GRAPH_JA_PROMPT"`
+	Content     string `json:"content" swaggertype:"string" format:"" example:"あなたは法律文書から知識グラフを抽出する専門家です..."`
+} // @name SetCubePromptParam
+
+type OntologyEntityTypeParam struct {
+	Name        string   `json:"name" swaggertype:"string" format:"" example:"person"`
+	Description string   `json:"description" swaggertype:"string" format:"" example:"人物"`
+	Synonyms    []string `json:"synonyms" swaggertype:"array,string" format:"" example:"人物,people"`
+} // @name OntologyEntityTypeParam
+
+type OntologyRelationTypeParam struct {
+	Name        string   `json:"name" swaggertype:"string" format:"" example:"works_at"`
+	Description string   `json:"description" swaggertype:"string" format:"" example:"現在の勤務先"`
+	Domain      []string `json:"domain" swaggertype:"array,string" format:"" example:"person"`
+	Range       []string `json:"range" swaggertype:"array,string" format:"" example:"organization"`
+	Cardinality string   `json:"cardinality" swaggertype:"string" format:"" example:"one"`
+	Synonyms    []string `json:"synonyms" swaggertype:"array,string" format:"" example:"employed_by,勤務先"`
+} // @name OntologyRelationTypeParam
+
+type OntologyParam struct {
+	EntityTypes   []OntologyEntityTypeParam   `json:"entity_types"`
+	RelationTypes []OntologyRelationTypeParam `json:"relation_types"`
+	Strict        bool                        `json:"strict" swaggertype:"boolean" format:"" example:"false"`
+} // @name OntologyParam
+
+type SetCubeOntologyParam struct {
+	CubeID      uint          `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
+	MemoryGroup string        `json:"memory_group" swaggertype:"string" format:"" example:"legal_expert"`
+	Ontology    OntologyParam `json:"ontology"`
+} // @name SetCubeOntologyParam
+
+type FindCubeGraphPathsParam struct {
+	CubeID             uint     `json:"cube_id" swaggertype:"integer" example:"1"`
+	MemoryGroup        string   `json:"memory_group" swaggertype:"string" example:"legal_expert"`
+	Source             string   `json:"source" swaggertype:"string" example:"A社"`
+	Target             string   `json:"target" swaggertype:"string" example:"B氏"`
+	K                  int      `json:"k" swaggertype:"integer" example:"3"`
+	MaxHops            int      `json:"max_hops" swaggertype:"integer" example:"4"`
+	ThicknessThreshold float64  `json:"thickness_threshold" swaggertype:"number" example:"0.3"`
+	EdgeTypes          []string `json:"edge_types" swaggertype:"array,string" example:"WORKS_AT,FOUNDED"`
+	Narrate            bool     `json:"narrate" swaggertype:"boolean" example:"true"`
+	ChatModelID        uint     `json:"chat_model_id" swaggertype:"integer" example:"1"`
+	IsEn               bool     `json:"is_en" swaggertype:"boolean" example:"false"`
+} // @name FindCubeGraphPathsParam
+
+type MigrateCubeEmbeddingsParam struct {
+	CubeID             uint   `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
+	EmbeddingProvider  string `json:"embedding_provider" swaggertype:"string" format:"" example:"openai"`
+	EmbeddingModel     string `json:"embedding_model" swaggertype:"string" format:"" example:"text-embedding-3-large"`
+	EmbeddingDimension uint   `json:"embedding_dimension" swaggertype:"integer" format:"" example:"3072"`
+	EmbeddingApiKey    string `json:"embedding_api_key" swaggertype:"string" format:"" example:"sk-..."`
+	EmbeddingBaseURL   string `json:"embedding_base_url" swaggertype:"string" format:"" example:""`
+	IsEn               bool   `json:"is_en" swaggertype:"boolean" format:"" example:"false"`
+} // @name MigrateCubeEmbeddingsParam
+
 type ReKeyCubeParam struct {
 	CubeID uint   `json:"cube_id" swaggertype:"integer" format:"" example:"1"`
 	Key    string `json:"key" swaggertype:"string" format:"" example:"alknas38msd..."`
 } // @name ReKeyCubeParam
 
 type QueryCubeParam struct {
-	CubeID                  uint    `form:"cube_id" swaggertype:"integer" example:"1"`
-	MemoryGroup             string  `form:"memory_group" swaggertype:"string" example:"legal_expert"`
-	Text                    string  `form:"text" swaggertype:"string" example:"契約違反の場合の対処法は？"`
-	Type                    uint8   `form:"type" swaggertype:"integer" example:"1"`
-	SummaryTopk             int     `form:"summary_topk" swaggertype:"integer" example:"3"`
-	ChunkTopk               int     `form:"chunk_topk" swaggertype:"integer" example:"3"`
-	EntityTopk              int     `form:"entity_topk" swaggertype:"integer" example:"3"`
-	FtsType                 uint8   `form:"fts_type" swaggertype:"integer" example:"0"` // 0=nouns, 1=nouns_verbs, 2=all
-	FtsTopk                 int     `form:"fts_topk" swaggertype:"integer" example:"0"` // 0=disabled
-	ThicknessThreshold      float64 `form:"thickness_threshold" swaggertype:"number" example:"0.3"`
-	ConflictResolutionStage uint8   `form:"conflict_resolution_stage" swaggertype:"integer" example:"2"` // 0=none, 1=stage1, 2=stage1+2
-	ChatModelID             uint    `form:"chat_model_id" swaggertype:"integer" example:"1"`
-	Stream                  bool    `form:"stream" swaggertype:"boolean" example:"false"`
-	AsJson                  bool    `form:"as_json" swaggertype:"boolean" example:"false"`
-	IsEn                    bool    `form:"is_en" swaggertype:"boolean" example:"false"`
+	CubeID                    uint    `form:"cube_id" swaggertype:"integer" example:"1"`
+	MemoryGroup               string  `form:"memory_group" swaggertype:"string" example:"legal_expert"`
+	Text                      string  `form:"text" swaggertype:"string" example:"契約違反の場合の対処法は？"`
+	Type                      uint8   `form:"type" swaggertype:"integer" example:"1"`
+	SummaryTopk               int     `form:"summary_topk" swaggertype:"integer" example:"3"`
+	ChunkTopk                 int     `form:"chunk_topk" swaggertype:"integer" example:"3"`
+	EntityTopk                int     `form:"entity_topk" swaggertype:"integer" example:"3"`
+	FtsType                   uint8   `form:"fts_type" swaggertype:"integer" example:"0"` // 0=nouns, 1=nouns_verbs, 2=all
+	FtsTopk                   int     `form:"fts_topk" swaggertype:"integer" example:"0"` // 0=disabled
+	ThicknessThreshold        float64 `form:"thickness_threshold" swaggertype:"number" example:"0.3"`
+	ConflictResolutionStage   uint8   `form:"conflict_resolution_stage" swaggertype:"integer" example:"2"`                // 0=none, 1=stage1, 2=stage1+2
+	CotRounds                 int     `form:"cot_rounds" swaggertype:"integer" example:"3"`                               // 0=default (type=22 only)
+	From                      string  `form:"from" swaggertype:"string" format:"date-time" example:"2025-03-01T00:00:00"` // type=26 only, empty=unbounded
+	To                        string  `form:"to" swaggertype:"string" format:"date-time" example:"2025-03-31T23:59:59"`   // type=26 only, empty=unbounded
+	HybridWeightVector        float64 `form:"hybrid_weight_vector" swaggertype:"number" example:"1.0"`                    // type=29 only
+	HybridWeightFtsNouns      float64 `form:"hybrid_weight_fts_nouns" swaggertype:"number" example:"0.5"`                 // type=29 only
+	HybridWeightFtsNounsVerbs float64 `form:"hybrid_weight_fts_nouns_verbs" swaggertype:"number" example:"0.5"`           // type=29 only
+	HybridWeightFtsKeywords   float64 `form:"hybrid_weight_fts_keywords" swaggertype:"number" example:"0.5"`              // type=29 only
+	HybridWeightGraph         float64 `form:"hybrid_weight_graph" swaggertype:"number" example:"0.5"`                     // type=29 only, all 0=default
+	CommunityLevel            int     `form:"community_level" swaggertype:"integer" example:"0"`                          // type=30 only, 0=top level
+	ChatModelID               uint    `form:"chat_model_id" swaggertype:"integer" example:"1"`
+	Stream                    bool    `form:"stream" swaggertype:"boolean" example:"false"`
+	AsJson                    bool    `form:"as_json" swaggertype:"boolean" example:"false"`
+	IsEn                      bool    `form:"is_en" swaggertype:"boolean" example:"false"`
 } // @name QueryCubeParam
 
 type MemifyCubeParam struct {
@@ -71,6 +152,17 @@ type MemifyCubeParam struct {
 	ConflictResolutionStage uint8  `json:"conflict_resolution_stage" swaggertype:"integer" example:"2"` // 0=none, 1=stage1, 2=stage1+2
 	ChatModelID             uint   `json:"chat_model_id" swaggertype:"integer" example:"1"`
 	Stream                  bool   `json:"stream" swaggertype:"boolean" example:"false"`
+	Async                   bool   `json:"async" swaggertype:"boolean" example:"false"`
 	AsJson                  bool   `json:"as_json" swaggertype:"boolean" example:"false"`
 	IsEn                    bool   `json:"is_en" swaggertype:"boolean" example:"false"`
 } // @name MemifyCubeParam
+
+type FeedbackCubeParam struct {
+	CubeID      uint   `json:"cube_id" swaggertype:"integer" example:"1"`
+	QueryID     string `json:"query_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	Rating      string `json:"rating" swaggertype:"string" example:"down"`
+	Correction  string `json:"correction" swaggertype:"string" example:"契約違反の違約金は契約金額の20%です。"`
+	ChatModelID uint   `json:"chat_model_id" swaggertype:"integer" example:"1"`
+	IsEn        bool   `json:"is_en" swaggertype:"boolean" example:"false"`
+	Async       bool   `json:"async" swaggertype:"boolean" example:"false"`
+} // @name FeedbackCubeParam
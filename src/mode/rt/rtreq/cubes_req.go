@@ -2,14 +2,21 @@ package rtreq
 
 import (
 	"encoding/json"
+	"mime/multipart"
+	"strings"
 
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	appconfig "github.com/t-kawata/mycute/config"
 	"github.com/t-kawata/mycute/lib/common"
 	"github.com/t-kawata/mycute/mode/rt/rtres"
 	"github.com/t-kawata/mycute/mode/rt/rtutil"
 	"github.com/t-kawata/mycute/model"
+	"github.com/t-kawata/mycute/pkg/cuber/extractor"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 	"github.com/t-kawata/mycute/pkg/cuber/validator"
 )
@@ -93,29 +100,57 @@ func CreateCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (CreateCubeReq, rtres.C
 }
 
 type AbsorbCubeReq struct {
-	CubeID                     uint    `json:"cube_id" binding:"required,gte=1"`
-	MemoryGroup                string  `json:"memory_group" binding:"required,max=64"`
-	Content                    string  `json:"content" binding:"required"`
-	ChunkSize                  int     `json:"chunk_size" binding:"gte=25"`
-	ChunkOverlap               int     `json:"chunk_overlap" binding:"gte=0"`
-	ChatModelID                uint    `json:"chat_model_id" binding:"required,gte=1"`
-	Stream                     bool    `json:"stream" binding:""`
-	AsJson                     bool    `json:"as_json"`                                                 // true=JSON output, false=natural language (default)
-	IsEn                       bool    `json:"is_en"`                                                   // true=English, false=Japanese (default)
-	HalfLifeDays               float64 `json:"half_life_days" binding:"omitempty,gte=1"`                // 価値が半減する日数 (デフォルト: 30)
-	PruneThreshold             float64 `json:"prune_threshold" binding:"omitempty,gte=0,lte=1"`         // 削除対象となるThickness閾値 (デフォルト: 0.1)
-	MinSurvivalProtectionHours float64 `json:"min_survival_protection_hours" binding:"omitempty,gte=0"` // 新規知識の最低生存保護期間 (デフォルト: 72時間)
-	MdlKNeighbors              int     `json:"mdl_k_neighbors" binding:"omitempty,gte=1"`               // MDL判定時の近傍ノード数 (デフォルト: 5)
+	CubeID                     uint                    `json:"cube_id" form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup                string                  `json:"memory_group" form:"memory_group" binding:"required,max=64"`
+	Content                    string                  `json:"content" form:"content"`                                 // content と file のいずれか（または両方）が必須
+	Files                      []*multipart.FileHeader `json:"-" form:"file"`                                          // multipart/form-data 時のアップロードファイル（複数可）
+	SourceID                   string                  `json:"source_id" form:"source_id" binding:"omitempty,max=255"` // 論理的な文書ID（同じ値で再度取り込むと旧版を置き換える）
+	ChunkSize                  int                     `json:"chunk_size" form:"chunk_size" binding:"gte=25"`
+	ChunkOverlap               int                     `json:"chunk_overlap" form:"chunk_overlap" binding:"gte=0"`
+	ChunkMaxRetries            int                     `json:"chunk_max_retries" form:"chunk_max_retries" binding:"omitempty,gte=0,lte=10"`          // グラフ抽出に失敗したチャンクの再試行回数 (デフォルト: 2)
+	MaxFailedChunkRatio        float64                 `json:"max_failed_chunk_ratio" form:"max_failed_chunk_ratio" binding:"omitempty,gte=0,lte=1"` // 失敗したチャンクをスキップして続行できる割合 (デフォルト: 0 = スキップしない)
+	ChatModelID                uint                    `json:"chat_model_id" form:"chat_model_id" binding:"required,gte=1"`
+	Stream                     bool                    `json:"stream" form:"stream" binding:""`
+	Async                      bool                    `json:"async" form:"async"`                                                                           // true=ジョブとして非同期実行し、job_id を返す（stream と併用不可）。false（デフォルト）=同期実行し、ジョブは作成しない
+	AsJson                     bool                    `json:"as_json" form:"as_json"`                                                                       // true=JSON output, false=natural language (default)
+	IsEn                       bool                    `json:"is_en" form:"is_en"`                                                                           // true=English, false=Japanese (default)
+	HalfLifeDays               float64                 `json:"half_life_days" form:"half_life_days" binding:"omitempty,gte=1"`                               // 価値が半減する日数 (デフォルト: 30)
+	PruneThreshold             float64                 `json:"prune_threshold" form:"prune_threshold" binding:"omitempty,gte=0,lte=1"`                       // 削除対象となるThickness閾値 (デフォルト: 0.1)
+	MinSurvivalProtectionHours float64                 `json:"min_survival_protection_hours" form:"min_survival_protection_hours" binding:"omitempty,gte=0"` // 新規知識の最低生存保護期間 (デフォルト: 72時間)
+	MdlKNeighbors              int                     `json:"mdl_k_neighbors" form:"mdl_k_neighbors" binding:"omitempty,gte=1"`                             // MDL判定時の近傍ノード数 (デフォルト: 5)
 }
 
+// AbsorbCubeReqBind binds JSON or multipart form data for Absorb API
+// - application/json: content にテキストを指定
+// - multipart/form-data: file（複数可）に PDF/DOCX/XLSX/PPTX/EPUB/CSV 等のファイルを指定（content との併用も可）
 func AbsorbCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (AbsorbCubeReq, rtres.AbsorbCubeRes, bool) {
 	ok := true
 	req := AbsorbCubeReq{}
 	res := rtres.AbsorbCubeRes{Errors: []rtres.Err{}}
-	if err := c.ShouldBindJSON(&req); err != nil {
+	var err error
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		err = c.ShouldBindWith(&req, binding.FormMultipart)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
 		res.Errors = u.GetValidationErrs(err)
+		return req, res, false
+	}
+	if req.Async && req.Stream {
+		res.Errors = append(res.Errors, rtres.Err{Field: "async", Message: "async cannot be used with stream."})
+		ok = false
+	}
+	if req.Content == "" && len(req.Files) == 0 {
+		res.Errors = append(res.Errors, rtres.Err{Field: "content", Message: "Either content or file is required."})
 		ok = false
 	}
+	for _, f := range req.Files {
+		if !extractor.IsSupported(f.Filename) {
+			res.Errors = append(res.Errors, rtres.Err{Field: "file", Message: fmt.Sprintf("Unsupported file type: %s (supported: %s)", f.Filename, strings.Join(extractor.SupportedExtensions(), ", "))})
+			ok = false
+		}
+	}
 	return req, res, ok
 }
 
@@ -230,21 +265,30 @@ func ReKeyCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (ReKeyCubeReq, rtres.ReK
 }
 
 type QueryCubeReq struct {
-	CubeID                  uint    `json:"cube_id" binding:"required,gte=1"`
-	MemoryGroup             string  `json:"memory_group" binding:"required,max=64"`
-	Text                    string  `json:"text" binding:"required"`
-	Type                    uint8   `json:"type" binding:"required,gte=1,lte=11"`                      // 検索タイプ
-	SummaryTopk             int     `json:"summary_topk" binding:"omitempty,gte=0"`                    // 要約文の上位k件を取得
-	ChunkTopk               int     `json:"chunk_topk" binding:"omitempty,gte=0"`                      // チャンクの上位k件を取得
-	EntityTopk              int     `json:"entity_topk" binding:"omitempty,gte=0"`                     // エンティティの上位k件を対象にグラフを取得
-	FtsType                 uint8   `json:"fts_type" binding:"omitempty,gte=0,lte=2"`                  // FTSレイヤー: 0=nouns, 1=nouns_verbs, 2=all
-	FtsTopk                 int     `json:"fts_topk" binding:"omitempty,gte=0"`                        // FTS拡張Top-K (0=disabled)
-	ThicknessThreshold      float64 `json:"thickness_threshold" binding:"omitempty,gte=0,lte=1"`       // エッジ足切り閾値 (デフォルト: 0.3)
-	ConflictResolutionStage uint8   `json:"conflict_resolution_stage" binding:"omitempty,gte=0,lte=2"` // 矛盾解決ステージ: 0=なし, 1=Stage1のみ, 2=Stage1+2
-	ChatModelID             uint    `json:"chat_model_id" binding:"required,gte=1"`
-	Stream                  bool    `json:"stream" binding:""`
-	AsJson                  bool    `json:"as_json"` // true=JSON output, false=natural language (default)
-	IsEn                    bool    `json:"is_en"`   // true=English, false=Japanese (default)
+	CubeID                    uint    `json:"cube_id" binding:"required,gte=1"`
+	MemoryGroup               string  `json:"memory_group" binding:"required,max=64"`
+	Text                      string  `json:"text" binding:"required"`
+	Type                      uint8   `json:"type" binding:"required,gte=1,lte=30"`                           // 検索タイプ (20=Cypher の場合、text に Cypher クエリを指定)
+	SummaryTopk               int     `json:"summary_topk" binding:"omitempty,gte=0"`                         // 要約文の上位k件を取得
+	ChunkTopk                 int     `json:"chunk_topk" binding:"omitempty,gte=0"`                           // チャンクの上位k件を取得
+	EntityTopk                int     `json:"entity_topk" binding:"omitempty,gte=0"`                          // エンティティの上位k件を対象にグラフを取得
+	FtsType                   uint8   `json:"fts_type" binding:"omitempty,gte=0,lte=2"`                       // FTSレイヤー: 0=nouns, 1=nouns_verbs, 2=all
+	FtsTopk                   int     `json:"fts_topk" binding:"omitempty,gte=0"`                             // FTS拡張Top-K (0=disabled)
+	ThicknessThreshold        float64 `json:"thickness_threshold" binding:"omitempty,gte=0,lte=1"`            // エッジ足切り閾値 (デフォルト: 0.3)
+	ConflictResolutionStage   uint8   `json:"conflict_resolution_stage" binding:"omitempty,gte=0,lte=2"`      // 矛盾解決ステージ: 0=なし, 1=Stage1のみ, 2=Stage1+2
+	CotRounds                 int     `json:"cot_rounds" binding:"omitempty,gte=0,lte=5"`                     // 推論型クエリ (22) の最大ラウンド数 (0=デフォルト)
+	From                      string  `json:"from" binding:"omitempty,datetime"`                              // 時系列検索 (26) の期間の開始日時 (空=無制限)
+	To                        string  `json:"to" binding:"omitempty,datetime"`                                // 時系列検索 (26) の期間の終了日時 (空=無制限)
+	HybridWeightVector        float64 `json:"hybrid_weight_vector" binding:"omitempty,gte=0,lte=10"`          // ハイブリッド検索 (29) のベクトル類似度の重み
+	HybridWeightFtsNouns      float64 `json:"hybrid_weight_fts_nouns" binding:"omitempty,gte=0,lte=10"`       // ハイブリッド検索 (29) の FTS（名詞）の重み
+	HybridWeightFtsNounsVerbs float64 `json:"hybrid_weight_fts_nouns_verbs" binding:"omitempty,gte=0,lte=10"` // ハイブリッド検索 (29) の FTS（名詞 + 動詞）の重み
+	HybridWeightFtsKeywords   float64 `json:"hybrid_weight_fts_keywords" binding:"omitempty,gte=0,lte=10"`    // ハイブリッド検索 (29) の FTS（全内容語）の重み
+	HybridWeightGraph         float64 `json:"hybrid_weight_graph" binding:"omitempty,gte=0,lte=10"`           // ハイブリッド検索 (29) のグラフ中心性の重み (全て0=デフォルト)
+	CommunityLevel            int     `json:"community_level" binding:"omitempty,gte=0,lte=10"`               // グローバル検索 (30) で使用するコミュニティの階層 (0=最上位)
+	ChatModelID               uint    `json:"chat_model_id" binding:"required,gte=1"`
+	Stream                    bool    `json:"stream" binding:""`
+	AsJson                    bool    `json:"as_json"` // true=JSON output, false=natural language (default)
+	IsEn                      bool    `json:"is_en"`   // true=English, false=Japanese (default)
 }
 
 func QueryCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (QueryCubeReq, rtres.QueryCubeRes, bool) {
@@ -266,6 +310,7 @@ type MemifyCubeReq struct {
 	ConflictResolutionStage uint8  `json:"conflict_resolution_stage"` // 0=none, 1=stage1, 2=stage1+2
 	ChatModelID             uint   `json:"chat_model_id" binding:"required,gte=1"`
 	Stream                  bool   `json:"stream" binding:""`
+	Async                   bool   `json:"async"`   // true=ジョブとして非同期実行し、job_id を返す（stream と併用不可）。false（デフォルト）=同期実行し、ジョブは作成しない
 	AsJson                  bool   `json:"as_json"` // true=JSON output, false=natural language (default)
 	IsEn                    bool   `json:"is_en"`   // true=English, false=Japanese (default)
 }
@@ -274,9 +319,324 @@ func MemifyCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (MemifyCubeReq, rtres.M
 	ok := true
 	req := MemifyCubeReq{}
 	res := rtres.MemifyCubeRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		return req, res, false
+	}
+	if req.Async && req.Stream {
+		res.Errors = append(res.Errors, rtres.Err{Field: "async", Message: "async cannot be used with stream."})
+		ok = false
+	}
+	return req, res, ok
+}
+
+type SetCubeCypherReq struct {
+	CubeID      uint `json:"cube_id" binding:"required,gte=1"`
+	AllowCypher bool `json:"allow_cypher"` // true=Cypher クエリの実行を許可する
+}
+
+func SetCubeCypherReqBind(c *gin.Context, u *rtutil.RtUtil) (SetCubeCypherReq, rtres.SetCubeCypherRes, bool) {
+	ok := true
+	req := SetCubeCypherReq{}
+	res := rtres.SetCubeCypherRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type SetCubeChatModelFallbacksReq struct {
+	CubeID       uint   `json:"cube_id" binding:"required,gte=1"`
+	ChatModelIDs []uint `json:"chat_model_ids" binding:"dive,gte=1"` // 使用する順。空の場合はフォールバックを解除する
+}
+
+func SetCubeChatModelFallbacksReqBind(c *gin.Context, u *rtutil.RtUtil) (SetCubeChatModelFallbacksReq, rtres.SetCubeChatModelFallbacksRes, bool) {
+	ok := true
+	req := SetCubeChatModelFallbacksReq{}
+	res := rtres.SetCubeChatModelFallbacksRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+		return req, res, ok
+	}
+	if len(req.ChatModelIDs) > appconfig.MAX_CHAT_MODEL_FALLBACKS {
+		res.Errors = append(res.Errors, rtres.Err{Field: "chat_model_ids", Message: fmt.Sprintf("Up to %d fallback chat models can be set.", appconfig.MAX_CHAT_MODEL_FALLBACKS)})
+		ok = false
+	}
+	seen := map[uint]bool{}
+	for _, id := range req.ChatModelIDs {
+		if seen[id] {
+			res.Errors = append(res.Errors, rtres.Err{Field: "chat_model_ids", Message: fmt.Sprintf("Duplicate chat model id: %d", id)})
+			ok = false
+			break
+		}
+		seen[id] = true
+	}
+	return req, res, ok
+}
+
+type ListCubePromptsReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+}
+
+func ListCubePromptsReqBind(c *gin.Context, u *rtutil.RtUtil) (ListCubePromptsReq, rtres.ListCubePromptsRes, bool) {
+	ok := true
+	req := ListCubePromptsReq{}
+	res := rtres.ListCubePromptsRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type SetCubePromptReq struct {
+	CubeID      uint   `json:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `json:"memory_group" binding:"required,max=64"`
+	Name        string `json:"name" binding:"required,max=100"`      // プロンプトの名前（GET /v1/cubes/prompts の name）
+	Content     string `json:"content" binding:"required,max=50000"` // 上書きするプロンプト
+}
+
+func SetCubePromptReqBind(c *gin.Context, u *rtutil.RtUtil) (SetCubePromptReq, rtres.SetCubePromptRes, bool) {
+	ok := true
+	req := SetCubePromptReq{}
+	res := rtres.SetCubePromptRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+		return req, res, ok
+	}
+	if _, exists := prompts.Default(prompts.Name(req.Name)); !exists {
+		res.Errors = append(res.Errors, rtres.Err{Field: "name", Message: fmt.Sprintf("Unknown prompt name: %s", req.Name)})
+		ok = false
+		return req, res, ok
+	}
+	if err := prompts.ValidateOverride(prompts.Name(req.Name), req.Content); err != nil {
+		res.Errors = append(res.Errors, rtres.Err{Field: "content", Message: err.Error()})
+		ok = false
+	}
+	return req, res, ok
+}
+
+type ResetCubePromptReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+	Name        string `form:"name" binding:"required,max=100"`
+}
+
+func ResetCubePromptReqBind(c *gin.Context, u *rtutil.RtUtil) (ResetCubePromptReq, rtres.ResetCubePromptRes, bool) {
+	ok := true
+	req := ResetCubePromptReq{}
+	res := rtres.ResetCubePromptRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+		return req, res, ok
+	}
+	if _, exists := prompts.Default(prompts.Name(req.Name)); !exists {
+		res.Errors = append(res.Errors, rtres.Err{Field: "name", Message: fmt.Sprintf("Unknown prompt name: %s", req.Name)})
+		ok = false
+	}
+	return req, res, ok
+}
+
+type GetCubeOntologyReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+}
+
+func GetCubeOntologyReqBind(c *gin.Context, u *rtutil.RtUtil) (GetCubeOntologyReq, rtres.GetCubeOntologyRes, bool) {
+	ok := true
+	req := GetCubeOntologyReq{}
+	res := rtres.GetCubeOntologyRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type SetCubeOntologyReq struct {
+	CubeID      uint               `json:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string             `json:"memory_group" binding:"required,max=64"`
+	Ontology    *ontology.Ontology `json:"ontology" binding:"required"`
+}
+
+func SetCubeOntologyReqBind(c *gin.Context, u *rtutil.RtUtil) (SetCubeOntologyReq, rtres.SetCubeOntologyRes, bool) {
+	ok := true
+	req := SetCubeOntologyReq{}
+	res := rtres.SetCubeOntologyRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+		return req, res, ok
+	}
+	// タイプ名・同義語の重複、domain/range の参照先などを検証（タイプ名は正規化される）
+	if err := req.Ontology.Validate(); err != nil {
+		res.Errors = append(res.Errors, rtres.Err{Field: "ontology", Message: err.Error()})
+		ok = false
+	}
+	return req, res, ok
+}
+
+type DeleteCubeOntologyReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+}
+
+func DeleteCubeOntologyReqBind(c *gin.Context, u *rtutil.RtUtil) (DeleteCubeOntologyReq, rtres.DeleteCubeOntologyRes, bool) {
+	ok := true
+	req := DeleteCubeOntologyReq{}
+	res := rtres.DeleteCubeOntologyRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type FindCubeGraphPathsReq struct {
+	CubeID             uint     `json:"cube_id" binding:"required,gte=1"`
+	MemoryGroup        string   `json:"memory_group" binding:"required,max=64"`
+	Source             string   `json:"source" binding:"required,max=255"`                           // 起点のエンティティ名
+	Target             string   `json:"target" binding:"required,max=255"`                           // 終点のエンティティ名
+	K                  int      `json:"k" binding:"omitempty,gte=1,lte=10"`                          // 返す経路の最大数（省略時は DEFAULT_PATH_K）
+	MaxHops            int      `json:"max_hops" binding:"omitempty,gte=1,lte=6"`                    // 経路のホップ数の上限（省略時は DEFAULT_PATH_MAX_HOPS）
+	ThicknessThreshold float64  `json:"thickness_threshold" binding:"omitempty,gte=0,lte=1"`         // Thickness がこの値未満のエッジは辿らない（0 の場合は DEFAULT_THICKNESS_THRESHOLD）
+	EdgeTypes          []string `json:"edge_types" binding:"omitempty,max=50,dive,required,max=255"` // 辿るエッジのタイプ（省略時は全て）
+	Narrate            bool     `json:"narrate"`                                                     // true の場合は経路の説明を生成する
+	ChatModelID        uint     `json:"chat_model_id" binding:"omitempty,gte=1"`                     // narrate=true の場合は必須
+	IsEn               bool     `json:"is_en"`                                                       // true=English, false=Japanese (default)
+}
+
+func FindCubeGraphPathsReqBind(c *gin.Context, u *rtutil.RtUtil) (FindCubeGraphPathsReq, rtres.FindCubeGraphPathsRes, bool) {
+	ok := true
+	req := FindCubeGraphPathsReq{}
+	res := rtres.FindCubeGraphPathsRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type SearchCubeGraphEntitiesReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+	Q           string `form:"q" binding:"max=255"`
+	Type        string `form:"type" binding:"max=255"`
+	Offset      int    `form:"offset" binding:"gte=0"`
+	Limit       int    `form:"limit" binding:"omitempty,gte=1,lte=100"` // 省略時は GRAPH_BROWSE_DEFAULT_LIMIT
+}
+
+func SearchCubeGraphEntitiesReqBind(c *gin.Context, u *rtutil.RtUtil) (SearchCubeGraphEntitiesReq, rtres.SearchCubeGraphEntitiesRes, bool) {
+	ok := true
+	req := SearchCubeGraphEntitiesReq{}
+	res := rtres.SearchCubeGraphEntitiesRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type GetCubeGraphEntityReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+	EntityID    string `form:"entity_id" binding:"required,max=255"`
+}
+
+func GetCubeGraphEntityReqBind(c *gin.Context, u *rtutil.RtUtil) (GetCubeGraphEntityReq, rtres.GetCubeGraphEntityRes, bool) {
+	ok := true
+	req := GetCubeGraphEntityReq{}
+	res := rtres.GetCubeGraphEntityRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type ListCubeGraphNeighborsReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+	EntityID    string `form:"entity_id" binding:"required,max=255"`
+	Offset      int    `form:"offset" binding:"gte=0"`
+	Limit       int    `form:"limit" binding:"omitempty,gte=1,lte=100"` // 省略時は GRAPH_BROWSE_DEFAULT_LIMIT
+}
+
+func ListCubeGraphNeighborsReqBind(c *gin.Context, u *rtutil.RtUtil) (ListCubeGraphNeighborsReq, rtres.ListCubeGraphNeighborsRes, bool) {
+	ok := true
+	req := ListCubeGraphNeighborsReq{}
+	res := rtres.ListCubeGraphNeighborsRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type GetCubeGraphSubgraphReq struct {
+	CubeID       uint    `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup  string  `form:"memory_group" binding:"required,max=64"`
+	EntityID     string  `form:"entity_id" binding:"required,max=255"`
+	Depth        int     `form:"depth" binding:"omitempty,gte=1,lte=3"`       // 省略時は GRAPH_SUBGRAPH_DEFAULT_DEPTH
+	MaxNodes     int     `form:"max_nodes" binding:"omitempty,gte=1,lte=500"` // 省略時は GRAPH_SUBGRAPH_DEFAULT_MAX_NODES
+	MinThickness float64 `form:"min_thickness" binding:"omitempty,gte=0,lte=1"`
+}
+
+func GetCubeGraphSubgraphReqBind(c *gin.Context, u *rtutil.RtUtil) (GetCubeGraphSubgraphReq, rtres.GetCubeGraphSubgraphRes, bool) {
+	ok := true
+	req := GetCubeGraphSubgraphReq{}
+	res := rtres.GetCubeGraphSubgraphRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type MigrateCubeEmbeddingsReq struct {
+	CubeID             uint   `json:"cube_id" binding:"required,gte=1"`
+	EmbeddingProvider  string `json:"embedding_provider" binding:"required,max=50"`
+	EmbeddingModel     string `json:"embedding_model" binding:"required,max=100"`
+	EmbeddingDimension uint   `json:"embedding_dimension" binding:"required,gte=1"` // 0は不可
+	EmbeddingApiKey    string `json:"embedding_api_key" binding:"required"`
+	EmbeddingBaseURL   string `json:"embedding_base_url" binding:"omitempty,max=255"`
+	IsEn               bool   `json:"is_en"` // 進捗メッセージの言語 (true=English, false=Japanese)
+}
+
+func MigrateCubeEmbeddingsReqBind(c *gin.Context, u *rtutil.RtUtil) (MigrateCubeEmbeddingsReq, rtres.MigrateCubeEmbeddingsRes, bool) {
+	ok := true
+	req := MigrateCubeEmbeddingsReq{}
+	res := rtres.MigrateCubeEmbeddingsRes{Errors: []rtres.Err{}}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		res.Errors = u.GetValidationErrs(err)
 		ok = false
+		return req, res, ok
+	}
+	// 埋め込みモデル関連のバリデーション実行
+	if err := validator.ValidateEmbeddingConfig(req.EmbeddingProvider, req.EmbeddingModel, req.EmbeddingDimension); err != nil {
+		res.Errors = append(res.Errors, rtres.Err{Field: "embedding_provider", Message: fmt.Sprintf("Invalid embedding configuration: %s", err.Error())})
+		res.Errors = append(res.Errors, rtres.Err{Field: "embedding_model", Message: fmt.Sprintf("Invalid embedding configuration: %s", err.Error())})
+		res.Errors = append(res.Errors, rtres.Err{Field: "embedding_dimension", Message: fmt.Sprintf("Invalid embedding configuration: %s", err.Error())})
+		ok = false
+	}
+	// Live Test Embedding
+	if ok {
+		embConfig := types.EmbeddingModelConfig{
+			Provider:  req.EmbeddingProvider,
+			Model:     req.EmbeddingModel,
+			Dimension: req.EmbeddingDimension,
+			BaseURL:   req.EmbeddingBaseURL,
+			ApiKey:    req.EmbeddingApiKey,
+		}
+		if err := u.CuberService.VerifyEmbeddingConfiguration(c.Request.Context(), embConfig); err != nil {
+			res.Errors = append(res.Errors, rtres.Err{Field: "embedding_config", Message: fmt.Sprintf("Live embedding verification failed: %s", err.Error())})
+			ok = false
+		}
 	}
 	return req, res, ok
 }
@@ -295,3 +655,85 @@ func DeleteCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (DeleteCubeReq, rtres.D
 	}
 	return req, res, ok
 }
+
+type ListCubeDataReq struct {
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+}
+
+func ListCubeDataReqBind(c *gin.Context, u *rtutil.RtUtil) (ListCubeDataReq, rtres.ListCubeDataRes, bool) {
+	ok := true
+	req := ListCubeDataReq{}
+	res := rtres.ListCubeDataRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type DeleteCubeDataReq struct {
+	DataID      string `form:"-" binding:"required,max=64"` // パスパラメータ
+	CubeID      uint   `form:"cube_id" binding:"required,gte=1"`
+	MemoryGroup string `form:"memory_group" binding:"required,max=64"`
+}
+
+func DeleteCubeDataReqBind(c *gin.Context, u *rtutil.RtUtil) (DeleteCubeDataReq, rtres.DeleteCubeDataRes, bool) {
+	ok := true
+	req := DeleteCubeDataReq{DataID: c.Param("data_id")}
+	res := rtres.DeleteCubeDataRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type FeedbackCubeReq struct {
+	CubeID      uint   `json:"cube_id" binding:"required,gte=1"`
+	QueryID     string `json:"query_id" binding:"required,max=36"`       // QueryCube のレスポンスの query_id
+	Rating      string `json:"rating" binding:"omitempty,oneof=up down"` // up=回答が正しい, down=回答が誤っている（correction 指定時は down とみなす）
+	Correction  string `json:"correction" binding:"omitempty,max=10000"` // 正しい内容（指定すると新しい知識として取り込まれる）
+	ChatModelID uint   `json:"chat_model_id" binding:"omitempty,gte=1"`  // correction 指定時は必須（取り込みに使用）
+	IsEn        bool   `json:"is_en"`                                    // true=English, false=Japanese (default)
+	Async       bool   `json:"async"`                                    // true=訂正文の取り込みをジョブとして非同期実行し、job_id を返す
+}
+
+func FeedbackCubeReqBind(c *gin.Context, u *rtutil.RtUtil) (FeedbackCubeReq, rtres.FeedbackCubeRes, bool) {
+	ok := true
+	req := FeedbackCubeReq{}
+	res := rtres.FeedbackCubeRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type ListOperationsReq struct{}
+
+func ListOperationsReqBind(c *gin.Context, u *rtutil.RtUtil) (ListOperationsReq, rtres.ListOperationsRes, bool) {
+	ok := true
+	req := ListOperationsReq{}
+	res := rtres.ListOperationsRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
+
+type CancelOperationReq struct {
+	OperationID string `binding:"required"` // Path Paramなのでjsonタグ不要（未知のIDは 404 とするため長さは検証しない）
+}
+
+func CancelOperationReqBind(c *gin.Context, u *rtutil.RtUtil) (CancelOperationReq, rtres.CancelOperationRes, bool) {
+	ok := true
+	req := CancelOperationReq{OperationID: c.Param("operation_id")}
+	res := rtres.CancelOperationRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBind(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
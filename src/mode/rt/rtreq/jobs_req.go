@@ -0,0 +1,22 @@
+package rtreq
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/t-kawata/mycute/mode/rt/rtres"
+	"github.com/t-kawata/mycute/mode/rt/rtutil"
+)
+
+type GetJobReq struct {
+	JobID string `binding:"required,max=36"` // Path Paramなのでjsonタグ不要
+}
+
+func GetJobReqBind(c *gin.Context, u *rtutil.RtUtil) (GetJobReq, rtres.GetJobRes, bool) {
+	ok := true
+	req := GetJobReq{JobID: c.Param("job_id")}
+	res := rtres.GetJobRes{Errors: []rtres.Err{}}
+	if err := c.ShouldBind(&req); err != nil {
+		res.Errors = u.GetValidationErrs(err)
+		ok = false
+	}
+	return req, res, ok
+}
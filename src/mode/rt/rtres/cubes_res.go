@@ -3,25 +3,29 @@ package rtres
 import (
 	"github.com/t-kawata/mycute/lib/common"
 	"github.com/t-kawata/mycute/model"
+	"github.com/t-kawata/mycute/pkg/cuber"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 )
 
 type SearchCubesResCube struct {
-	ID                 uint                  `json:"id" swaggertype:"integer" example:"1"`
-	UUID               string                `json:"uuid" swaggertype:"string" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name               string                `json:"name" swaggertype:"string" example:"MyCube"`
-	Description        string                `json:"description" swaggertype:"string" example:"This is my cube"`
-	ExpireAt           string                `json:"expire_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	Permissions        model.CubePermissions `json:"permissions" swaggertype:"string" example:"{}"`
-	SourceExportID     *uint                 `json:"source_export_id" swaggertype:"integer" example:"1"`
-	ApxID              uint                  `json:"apx_id" swaggertype:"integer" example:"1"`
-	VdrID              uint                  `json:"vdr_id" swaggertype:"integer" example:"1"`
-	CreatedAt          string                `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	UpdatedAt          string                `json:"updated_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	EmbeddingProvider  string                `json:"embedding_provider"`
-	EmbeddingBaseURL   string                `json:"embedding_base_url"`
-	EmbeddingModel     string                `json:"embedding_model"`
-	EmbeddingDimension uint                  `json:"embedding_dimension"`
+	ID                   uint                  `json:"id" swaggertype:"integer" example:"1"`
+	UUID                 string                `json:"uuid" swaggertype:"string" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name                 string                `json:"name" swaggertype:"string" example:"MyCube"`
+	Description          string                `json:"description" swaggertype:"string" example:"This is my cube"`
+	ExpireAt             string                `json:"expire_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	Permissions          model.CubePermissions `json:"permissions" swaggertype:"string" example:"{}"`
+	SourceExportID       *uint                 `json:"source_export_id" swaggertype:"integer" example:"1"`
+	ApxID                uint                  `json:"apx_id" swaggertype:"integer" example:"1"`
+	VdrID                uint                  `json:"vdr_id" swaggertype:"integer" example:"1"`
+	CreatedAt            string                `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	UpdatedAt            string                `json:"updated_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	EmbeddingProvider    string                `json:"embedding_provider"`
+	EmbeddingBaseURL     string                `json:"embedding_base_url"`
+	EmbeddingModel       string                `json:"embedding_model"`
+	EmbeddingDimension   uint                  `json:"embedding_dimension"`
+	ChatModelFallbackIDs []uint                `json:"chat_model_fallback_ids" swaggertype:"array,integer" example:"2,3"` // フォールバック先の ChatModel の ID（使用する順）
 }
 
 type SearchCubesResData struct {
@@ -36,21 +40,22 @@ type SearchCubesRes struct {
 } // @name SearchCubesRes
 
 type GetCubeResCube struct {
-	ID                 uint                  `json:"id" swaggertype:"integer" example:"1"`
-	UUID               string                `json:"uuid" swaggertype:"string" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name               string                `json:"name" swaggertype:"string" example:"MyCube"`
-	Description        string                `json:"description" swaggertype:"string" example:"This is my cube"`
-	ExpireAt           string                `json:"expire_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	Permissions        model.CubePermissions `json:"permissions" swaggertype:"string" example:"{}"`
-	SourceExportID     *uint                 `json:"source_export_id" swaggertype:"integer" example:"1"`
-	ApxID              uint                  `json:"apx_id" swaggertype:"integer" example:"1"`
-	VdrID              uint                  `json:"vdr_id" swaggertype:"integer" example:"1"`
-	CreatedAt          string                `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	UpdatedAt          string                `json:"updated_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
-	EmbeddingProvider  string                `json:"embedding_provider"`
-	EmbeddingBaseURL   string                `json:"embedding_base_url"`
-	EmbeddingModel     string                `json:"embedding_model"`
-	EmbeddingDimension uint                  `json:"embedding_dimension"`
+	ID                   uint                  `json:"id" swaggertype:"integer" example:"1"`
+	UUID                 string                `json:"uuid" swaggertype:"string" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name                 string                `json:"name" swaggertype:"string" example:"MyCube"`
+	Description          string                `json:"description" swaggertype:"string" example:"This is my cube"`
+	ExpireAt             string                `json:"expire_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	Permissions          model.CubePermissions `json:"permissions" swaggertype:"string" example:"{}"`
+	SourceExportID       *uint                 `json:"source_export_id" swaggertype:"integer" example:"1"`
+	ApxID                uint                  `json:"apx_id" swaggertype:"integer" example:"1"`
+	VdrID                uint                  `json:"vdr_id" swaggertype:"integer" example:"1"`
+	CreatedAt            string                `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	UpdatedAt            string                `json:"updated_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	EmbeddingProvider    string                `json:"embedding_provider"`
+	EmbeddingBaseURL     string                `json:"embedding_base_url"`
+	EmbeddingModel       string                `json:"embedding_model"`
+	EmbeddingDimension   uint                  `json:"embedding_dimension"`
+	ChatModelFallbackIDs []uint                `json:"chat_model_fallback_ids" swaggertype:"array,integer" example:"2,3"` // フォールバック先の ChatModel の ID（使用する順）
 }
 
 type GetCubeResData struct {
@@ -64,23 +69,30 @@ func (d *GetCubeResData) Of(m *model.Cube, lineage *[]LineageRes, memoryGroups *
 	if err != nil {
 		return nil
 	}
+	fallbackIDs := []uint{}
+	if len(m.ChatModelFallbacks) > 0 {
+		if fallbackIDs, err = common.ParseDatatypesJson[[]uint](&m.ChatModelFallbacks); err != nil || fallbackIDs == nil {
+			fallbackIDs = []uint{}
+		}
+	}
 	data := GetCubeResData{}
 	data.Cube = GetCubeResCube{
-		ID:                 m.ID,
-		UUID:               m.UUID,
-		Name:               m.Name,
-		Description:        m.Description,
-		ExpireAt:           common.ParseDatetimeToStr(m.ExpireAt),
-		Permissions:        permisions,
-		SourceExportID:     m.SourceExportID,
-		ApxID:              m.ApxID,
-		VdrID:              m.VdrID,
-		CreatedAt:          common.ParseDatetimeToStr(&m.CreatedAt),
-		UpdatedAt:          common.ParseDatetimeToStr(&m.UpdatedAt),
-		EmbeddingProvider:  m.EmbeddingProvider,
-		EmbeddingBaseURL:   m.EmbeddingBaseURL,
-		EmbeddingModel:     m.EmbeddingModel,
-		EmbeddingDimension: m.EmbeddingDimension,
+		ID:                   m.ID,
+		UUID:                 m.UUID,
+		Name:                 m.Name,
+		Description:          m.Description,
+		ExpireAt:             common.ParseDatetimeToStr(m.ExpireAt),
+		Permissions:          permisions,
+		SourceExportID:       m.SourceExportID,
+		ApxID:                m.ApxID,
+		VdrID:                m.VdrID,
+		CreatedAt:            common.ParseDatetimeToStr(&m.CreatedAt),
+		UpdatedAt:            common.ParseDatetimeToStr(&m.UpdatedAt),
+		EmbeddingProvider:    m.EmbeddingProvider,
+		EmbeddingBaseURL:     m.EmbeddingBaseURL,
+		EmbeddingModel:       m.EmbeddingModel,
+		EmbeddingDimension:   m.EmbeddingDimension,
+		ChatModelFallbackIDs: fallbackIDs,
 	}
 	data.Lineage = *lineage
 	data.MemoryGroups = *memoryGroups
@@ -105,9 +117,10 @@ type CreateCubeRes struct {
 } // @name CreateCubeRes
 
 type AbsorbCubeResData struct {
-	InputTokens  int64 `json:"input_tokens"`
-	OutputTokens int64 `json:"output_tokens"`
-	AbsorbLimit  int   `json:"absorb_limit"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	AbsorbLimit  int    `json:"absorb_limit"`
+	JobID        string `json:"job_id,omitempty"` // async=true の場合のみ（トークン使用量は GET /v1/jobs/{job_id} で確認する）
 } // @name AbsorbCubeResData
 
 type AbsorbCubeRes struct {
@@ -125,6 +138,9 @@ type ModelStatRes struct {
 	ActionType   string `json:"action_type"` // "training" or "search"
 	InputTokens  int64  `json:"input_tokens"`
 	OutputTokens int64  `json:"output_tokens"`
+	// 埋め込みキャッシュのヒット数・ミス数（埋め込みモデルのみ。ヒットした分はトークンを消費していない）
+	EmbeddingCacheHits   int64 `json:"embedding_cache_hits"`
+	EmbeddingCacheMisses int64 `json:"embedding_cache_misses"`
 } // @name ModelStatRes
 
 // ContributorRes は貢献者ごとの使用量です。
@@ -181,13 +197,16 @@ type ReKeyCubeRes struct {
 } // @name ReKeyCubeRes
 
 type QueryCubeResData struct {
-	Answer       *string            `json:"answer" swaggertype:"string" example:"契約違反の場合は..."`
-	Chunks       *string            `json:"chunks" swaggertype:"string" example:"契約違反の場合は..."`
-	Summaries    *string            `json:"summaries" swaggertype:"string" example:"契約違反の場合は..."`
-	Graph        *[]*storage.Triple `json:"graph" swaggertype:"array,string"`
-	InputTokens  int64              `json:"input_tokens" swaggertype:"integer" example:"1500"`
-	OutputTokens int64              `json:"output_tokens" swaggertype:"integer" example:"500"`
-	QueryLimit   int                `json:"query_limit" swaggertype:"integer" example:"-1"`
+	QueryID      string                `json:"query_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"` // フィードバック (POST /v1/cubes/feedback) で使用するクエリID
+	Answer       *string               `json:"answer" swaggertype:"string" example:"契約違反の場合は..."`
+	Chunks       *string               `json:"chunks" swaggertype:"string" example:"契約違反の場合は..."`
+	Summaries    *string               `json:"summaries" swaggertype:"string" example:"契約違反の場合は..."`
+	Graph        *[]*storage.Triple    `json:"graph" swaggertype:"array,string"`
+	Citations    *[]*storage.Citation  `json:"citations" swaggertype:"array,object"` // 根拠となったチャンクの出典（ファイル名・チャンク順序）
+	Table        *storage.CypherResult `json:"table" swaggertype:"object"`           // Cypherクエリ (type=20) の表形式の結果
+	InputTokens  int64                 `json:"input_tokens" swaggertype:"integer" example:"1500"`
+	OutputTokens int64                 `json:"output_tokens" swaggertype:"integer" example:"500"`
+	QueryLimit   int                   `json:"query_limit" swaggertype:"integer" example:"-1"`
 } // @name QueryCubeResData
 
 type QueryCubeRes struct {
@@ -196,9 +215,10 @@ type QueryCubeRes struct {
 } // @name QueryCubeRes
 
 type MemifyCubeResData struct {
-	InputTokens  int64 `json:"input_tokens" swaggertype:"integer" example:"5000"`
-	OutputTokens int64 `json:"output_tokens" swaggertype:"integer" example:"2000"`
-	MemifyLimit  int   `json:"memify_limit" swaggertype:"integer" example:"-1"`
+	InputTokens  int64  `json:"input_tokens" swaggertype:"integer" example:"5000"`
+	OutputTokens int64  `json:"output_tokens" swaggertype:"integer" example:"2000"`
+	MemifyLimit  int    `json:"memify_limit" swaggertype:"integer" example:"-1"`
+	JobID        string `json:"job_id,omitempty" swaggertype:"string" example:""` // async=true の場合のみ（トークン使用量は GET /v1/jobs/{job_id} で確認する）
 } // @name MemifyCubeResData
 
 type MemifyCubeRes struct {
@@ -206,6 +226,304 @@ type MemifyCubeRes struct {
 	Errors []Err             `json:"errors"`
 } // @name MemifyCubeRes
 
+type SetCubeCypherResData struct {
+	AllowCypher bool `json:"allow_cypher" swaggertype:"boolean" example:"true"` // 設定後の Cypher クエリの実行可否
+} // @name SetCubeCypherResData
+
+type SetCubeCypherRes struct {
+	Data   SetCubeCypherResData `json:"data"`
+	Errors []Err                `json:"errors"`
+} // @name SetCubeCypherRes
+
+type SetCubeChatModelFallbacksResData struct {
+	ChatModelFallbackIDs []uint `json:"chat_model_fallback_ids" swaggertype:"array,integer" example:"2,3"` // 設定後のフォールバック先の ChatModel の ID（使用する順）
+} // @name SetCubeChatModelFallbacksResData
+
+type SetCubeChatModelFallbacksRes struct {
+	Data   SetCubeChatModelFallbacksResData `json:"data"`
+	Errors []Err                            `json:"errors"`
+} // @name SetCubeChatModelFallbacksRes
+
+type ListCubePromptsResVersion struct {
+	Version   int64  `json:"version" swaggertype:"integer" example:"2"`
+	IsActive  bool   `json:"is_active" swaggertype:"boolean" example:"true"`
+	CreatedAt string `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+} // @name ListCubePromptsResVersion
+
+type ListCubePromptsResData struct {
+	Name         string                      `json:"name" swaggertype:"string" example:"GENERATE_GRAPH_JA_PROMPT"`
+	Content      string                      `json:"content" swaggertype:"string" example:"あなたは法律文書から知識グラフを抽出する専門家です..."` // 実際に使用されるプロンプト
+	Placeholders []string                    `json:"placeholders" swaggertype:"array,string" example:"%s"`                // 上書きに必要な書式指定子（出現順）
+	IsOverridden bool                        `json:"is_overridden" swaggertype:"boolean" example:"true"`
+	Version      int64                       `json:"version" swaggertype:"integer" example:"2"` // 有効な上書きのバージョン（デフォルトの場合は 0）
+	Versions     []ListCubePromptsResVersion `json:"versions"`                                  // 上書きの履歴（バージョン順）
+} // @name ListCubePromptsResData
+
+func (d *ListCubePromptsResData) Of(overrides []*storage.PromptOverride) *[]ListCubePromptsResData {
+	byName := map[prompts.Name][]*storage.PromptOverride{}
+	for _, o := range overrides {
+		byName[prompts.Name(o.Name)] = append(byName[prompts.Name(o.Name)], o)
+	}
+	data := []ListCubePromptsResData{}
+	for _, name := range prompts.Names() {
+		content, _ := prompts.Default(name)
+		item := ListCubePromptsResData{
+			Name:         string(name),
+			Placeholders: prompts.Placeholders(content),
+			Versions:     []ListCubePromptsResVersion{},
+		}
+		if item.Placeholders == nil {
+			item.Placeholders = []string{}
+		}
+		for _, o := range byName[name] {
+			if o.IsActive {
+				content = o.Content
+				item.IsOverridden = true
+				item.Version = o.Version
+			}
+			item.Versions = append(item.Versions, ListCubePromptsResVersion{
+				Version:   o.Version,
+				IsActive:  o.IsActive,
+				CreatedAt: common.ParseDatetimeToStr(&o.CreatedAt),
+			})
+		}
+		item.Content = content
+		data = append(data, item)
+	}
+	return &data
+}
+
+type ListCubePromptsRes struct {
+	Data   []ListCubePromptsResData `json:"data"`
+	Errors []Err                    `json:"errors"`
+} // @name ListCubePromptsRes
+
+type SetCubePromptResData struct {
+	Name    string `json:"name" swaggertype:"string" example:"GENERATE_GRAPH_JA_PROMPT"`
+	Version int64  `json:"version" swaggertype:"integer" example:"2"` // 保存した上書きのバージョン
+} // @name SetCubePromptResData
+
+type SetCubePromptRes struct {
+	Data   SetCubePromptResData `json:"data"`
+	Errors []Err                `json:"errors"`
+} // @name SetCubePromptRes
+
+type ResetCubePromptRes struct {
+	Errors []Err `json:"errors"`
+} // @name ResetCubePromptRes
+
+type GetCubeOntologyRes struct {
+	Data   ontology.Ontology `json:"data" swaggertype:"object"` // タイプ名は正規化済み
+	Errors []Err             `json:"errors"`
+} // @name GetCubeOntologyRes
+
+type SetCubeOntologyRes struct {
+	Data   ontology.Ontology `json:"data" swaggertype:"object"` // 保存したオントロジー（タイプ名は正規化済み）
+	Errors []Err             `json:"errors"`
+} // @name SetCubeOntologyRes
+
+type DeleteCubeOntologyRes struct {
+	Errors []Err `json:"errors"`
+} // @name DeleteCubeOntologyRes
+
+type FindCubeGraphPathsResData struct {
+	Paths        []*storage.Path `json:"paths" swaggertype:"array,object"`                       // 経路（ホップ数の少ない順）
+	Narration    *string         `json:"narration" swaggertype:"string" example:"A社はB氏が創業した..."` // 経路の説明（narrate=true の場合のみ）
+	InputTokens  int64           `json:"input_tokens" swaggertype:"integer" example:"1500"`
+	OutputTokens int64           `json:"output_tokens" swaggertype:"integer" example:"500"`
+	QueryLimit   int             `json:"query_limit" swaggertype:"integer" example:"-1"`
+} // @name FindCubeGraphPathsResData
+
+type FindCubeGraphPathsRes struct {
+	Data   FindCubeGraphPathsResData `json:"data"`
+	Errors []Err                     `json:"errors"`
+} // @name FindCubeGraphPathsRes
+
+type SearchCubeGraphEntitiesResData struct {
+	Entities []*storage.Node `json:"entities" swaggertype:"array,object"`
+	Total    int             `json:"total" swaggertype:"integer" example:"42"` // 条件に一致したエンティティの総数
+} // @name SearchCubeGraphEntitiesResData
+
+type SearchCubeGraphEntitiesRes struct {
+	Data   SearchCubeGraphEntitiesResData `json:"data"`
+	Errors []Err                          `json:"errors"`
+} // @name SearchCubeGraphEntitiesRes
+
+type GetCubeGraphEntityResData struct {
+	ID         string         `json:"id" swaggertype:"string" example:"トヨタ自動車"`
+	Type       string         `json:"type" swaggertype:"string" example:"Organization"`
+	Properties map[string]any `json:"properties" swaggertype:"object"`
+	Aliases    []string       `json:"aliases" swaggertype:"array,string" example:"トヨタ,toyota"` // エンティティ解決で記録された別名
+	Degree     int            `json:"degree" swaggertype:"integer" example:"12"`               // 接続するエッジ数
+} // @name GetCubeGraphEntityResData
+
+func (d *GetCubeGraphEntityResData) Of(m *cuber.EntityDetail) *GetCubeGraphEntityResData {
+	aliases := m.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return &GetCubeGraphEntityResData{
+		ID:         m.Node.ID,
+		Type:       m.Node.Type,
+		Properties: m.Node.Properties,
+		Aliases:    aliases,
+		Degree:     m.Degree,
+	}
+}
+
+type GetCubeGraphEntityRes struct {
+	Data   GetCubeGraphEntityResData `json:"data"`
+	Errors []Err                     `json:"errors"`
+} // @name GetCubeGraphEntityRes
+
+type CubeGraphNeighborRes struct {
+	Node      *storage.Node `json:"node" swaggertype:"object"`
+	Edge      *storage.Edge `json:"edge" swaggertype:"object"`
+	Direction string        `json:"direction" swaggertype:"string" example:"out"` // "out": エンティティ -> node, "in": node -> エンティティ
+} // @name CubeGraphNeighborRes
+
+type ListCubeGraphNeighborsResData struct {
+	Neighbors []CubeGraphNeighborRes `json:"neighbors"`
+	Total     int                    `json:"total" swaggertype:"integer" example:"12"` // 隣接ノードの総数
+} // @name ListCubeGraphNeighborsResData
+
+func (d *ListCubeGraphNeighborsResData) Of(ms []*cuber.EntityNeighbor, total int) *ListCubeGraphNeighborsResData {
+	data := ListCubeGraphNeighborsResData{Neighbors: []CubeGraphNeighborRes{}, Total: total}
+	for _, m := range ms {
+		data.Neighbors = append(data.Neighbors, CubeGraphNeighborRes{Node: m.Node, Edge: m.Edge, Direction: m.Direction})
+	}
+	return &data
+}
+
+type ListCubeGraphNeighborsRes struct {
+	Data   ListCubeGraphNeighborsResData `json:"data"`
+	Errors []Err                         `json:"errors"`
+} // @name ListCubeGraphNeighborsRes
+
+type GetCubeGraphSubgraphResData struct {
+	Nodes     []*storage.Node   `json:"nodes" swaggertype:"array,object"`                // 中心のエンティティから近い順
+	Triples   []*storage.Triple `json:"triples" swaggertype:"array,object"`              // nodes の間のトリプル
+	Truncated bool              `json:"truncated" swaggertype:"boolean" example:"false"` // max_nodes により打ち切った場合は true
+} // @name GetCubeGraphSubgraphResData
+
+type GetCubeGraphSubgraphRes struct {
+	Data   GetCubeGraphSubgraphResData `json:"data"`
+	Errors []Err                       `json:"errors"`
+} // @name GetCubeGraphSubgraphRes
+
+type MigrateCubeEmbeddingsResData struct {
+	JobID string `json:"job_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"` // 進捗・結果は GET /v1/jobs/{job_id} で確認する
+} // @name MigrateCubeEmbeddingsResData
+
+type MigrateCubeEmbeddingsRes struct {
+	Data   MigrateCubeEmbeddingsResData `json:"data"`
+	Errors []Err                        `json:"errors"`
+} // @name MigrateCubeEmbeddingsRes
+
+// MigrateCubeEmbeddingsJobResult は、埋め込みモデルの移行ジョブの結果（GET /v1/jobs/{job_id} の result）です。
+type MigrateCubeEmbeddingsJobResult struct {
+	MigratedCount      int    `json:"migrated_count" swaggertype:"integer" example:"1200"` // 再ベクトル化した行数
+	EmbeddingProvider  string `json:"embedding_provider" swaggertype:"string" example:"openai"`
+	EmbeddingModel     string `json:"embedding_model" swaggertype:"string" example:"text-embedding-3-large"`
+	EmbeddingDimension uint   `json:"embedding_dimension" swaggertype:"integer" example:"3072"`
+	InputTokens        int64  `json:"input_tokens" swaggertype:"integer" example:"250000"`
+	OutputTokens       int64  `json:"output_tokens" swaggertype:"integer" example:"0"`
+} // @name MigrateCubeEmbeddingsJobResult
+
 type DeleteCubeRes struct {
 	Errors []Err `json:"errors"`
 } // @name DeleteCubeRes
+
+type ListCubeDataResData struct {
+	ID          string `json:"id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	Name        string `json:"name" swaggertype:"string" example:"contract.pdf"`
+	Extension   string `json:"extension" swaggertype:"string" example:".pdf"`
+	MimeType    string `json:"mime_type" swaggertype:"string" example:"application/pdf"`
+	ContentHash string `json:"content_hash" swaggertype:"string" example:"9f86d081884c7d65..."`
+	SourceID    string `json:"source_id" swaggertype:"string" example:"contracts/2024-001"`
+	CreatedAt   string `json:"created_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+} // @name ListCubeDataResData
+
+func (d *ListCubeDataResData) Of(ms []*storage.Data) *[]ListCubeDataResData {
+	data := []ListCubeDataResData{}
+	for _, m := range ms {
+		data = append(data, ListCubeDataResData{
+			ID:          m.ID,
+			Name:        m.Name,
+			Extension:   m.Extension,
+			MimeType:    m.MimeType,
+			ContentHash: m.ContentHash,
+			SourceID:    m.SourceID,
+			CreatedAt:   common.ParseDatetimeToStr(&m.CreatedAt),
+		})
+	}
+	return &data
+}
+
+type ListCubeDataRes struct {
+	Data   []ListCubeDataResData `json:"data"`
+	Errors []Err                 `json:"errors"`
+} // @name ListCubeDataRes
+
+type DeleteCubeDataRes struct {
+	Errors []Err `json:"errors"`
+} // @name DeleteCubeDataRes
+
+type FeedbackCubeResData struct {
+	QueryID      string `json:"query_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	Feedback     string `json:"feedback" swaggertype:"string" example:"correction"` // "up", "down", "correction"
+	Strengthened int    `json:"strengthened" swaggertype:"integer" example:"0"`     // 強化されたエッジ数
+	Weakened     int    `json:"weakened" swaggertype:"integer" example:"3"`         // 弱化されたエッジ数
+	Pruned       int    `json:"pruned" swaggertype:"integer" example:"1"`           // 弱化により削除されたエッジ数
+	Missing      int    `json:"missing" swaggertype:"integer" example:"0"`          // 既に存在しなかったエッジ数
+	InputTokens  int64  `json:"input_tokens" swaggertype:"integer" example:"1500"`  // 訂正文の取り込みに使用したトークン数
+	OutputTokens int64  `json:"output_tokens" swaggertype:"integer" example:"500"`
+	AbsorbLimit  int    `json:"absorb_limit" swaggertype:"integer" example:"-1"`
+	JobID        string `json:"job_id,omitempty" swaggertype:"string" example:""` // async=true で訂正文を指定した場合のみ（トークン使用量は GET /v1/jobs/{job_id} で確認する）
+} // @name FeedbackCubeResData
+
+type FeedbackCubeRes struct {
+	Data   FeedbackCubeResData `json:"data"`
+	Errors []Err               `json:"errors"`
+} // @name FeedbackCubeRes
+
+type ListOperationsResData struct {
+	OperationID   string `json:"operation_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	OperationType string `json:"operation_type" swaggertype:"string" example:"absorb"` // "absorb", "memify", "query", "migrate_embeddings"
+	CubeUUID      string `json:"cube_uuid" swaggertype:"string" example:"0b8f7c3e-1d2a-4b5c-9e6f-7a8b9c0d1e2f"`
+	MemoryGroup   string `json:"memory_group" swaggertype:"string" example:"legal_expert"`
+	StartedAt     string `json:"started_at" swaggertype:"string" format:"date-time" example:"2025-01-01T00:00:00"`
+	Cancelled     bool   `json:"cancelled" swaggertype:"boolean" example:"false"` // キャンセル済み（ロールバック中）
+} // @name ListOperationsResData
+
+func (d *ListOperationsResData) Of(ops []cuber.Operation) *[]ListOperationsResData {
+	data := []ListOperationsResData{}
+	for _, op := range ops {
+		data = append(data, ListOperationsResData{
+			OperationID:   op.ID,
+			OperationType: string(op.Type),
+			CubeUUID:      op.CubeUUID,
+			MemoryGroup:   op.MemoryGroup,
+			StartedAt:     common.ParseDatetimeToStr(&op.StartedAt),
+			Cancelled:     op.Cancelled,
+		})
+	}
+	return &data
+}
+
+type ListOperationsRes struct {
+	Data   []ListOperationsResData `json:"data"`
+	Errors []Err                   `json:"errors"`
+} // @name ListOperationsRes
+
+type CancelOperationResData struct {
+	OperationID   string `json:"operation_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	OperationType string `json:"operation_type" swaggertype:"string" example:"absorb"` // "absorb", "memify", "query", "migrate_embeddings"
+	MemoryGroup   string `json:"memory_group" swaggertype:"string" example:"legal_expert"`
+	Status        string `json:"status" swaggertype:"string" example:"cancelling"` // "cancelling"（実行中・ロールバック中）, "cancelled"（実行待ちのジョブ）
+} // @name CancelOperationResData
+
+type CancelOperationRes struct {
+	Data   CancelOperationResData `json:"data"`
+	Errors []Err                  `json:"errors"`
+} // @name CancelOperationRes
@@ -0,0 +1,56 @@
+package rtres
+
+import (
+	"encoding/json"
+
+	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/model"
+)
+
+type GetJobResData struct {
+	JobID           string          `json:"job_id" swaggertype:"string" example:"6f1c2b1e-3a4d-5e6f-8a9b-0c1d2e3f4a5b"`
+	Type            string          `json:"type" swaggertype:"string" example:"absorb"`    // "absorb", "memify", "migrate_embeddings"
+	Status          string          `json:"status" swaggertype:"string" example:"running"` // "queued", "running", "succeeded", "failed", "cancelled"
+	CubeID          uint            `json:"cube_id" swaggertype:"integer" example:"1"`
+	MemoryGroup     string          `json:"memory_group" swaggertype:"string" example:"legal_expert"`
+	Progress        string          `json:"progress" swaggertype:"string" example:"ABSORB_GRAPH_REQUEST_END"` // 最後に発火したイベント名
+	ProgressMessage string          `json:"progress_message" swaggertype:"string" example:""`                 // 最後に発火したイベントのメッセージ
+	EventCount      int             `json:"event_count" swaggertype:"integer" example:"42"`
+	InputTokens     int64           `json:"input_tokens" swaggertype:"integer" example:"5000"`
+	OutputTokens    int64           `json:"output_tokens" swaggertype:"integer" example:"2000"`
+	Result          json.RawMessage `json:"result" swaggertype:"object"` // status = succeeded の場合のみ（AbsorbCubeResData / MemifyCubeResData / MigrateCubeEmbeddingsJobResult）
+	Error           string          `json:"error" swaggertype:"string" example:""`
+	Attempts        int             `json:"attempts" swaggertype:"integer" example:"1"`
+	CreatedAt       string          `json:"created_at" swaggertype:"string" example:"2025-03-01T00:00:00"`
+	StartedAt       string          `json:"started_at" swaggertype:"string" example:"2025-03-01T00:00:01"`
+	FinishedAt      string          `json:"finished_at" swaggertype:"string" example:""`
+} // @name GetJobResData
+
+func (d *GetJobResData) Of(m *model.Job) *GetJobResData {
+	data := GetJobResData{
+		JobID:           m.UUID,
+		Type:            m.Type,
+		Status:          m.Status,
+		CubeID:          m.CubeID,
+		MemoryGroup:     m.MemoryGroup,
+		Progress:        m.Progress,
+		ProgressMessage: m.ProgressMessage,
+		EventCount:      m.EventCount,
+		InputTokens:     m.InputTokens,
+		OutputTokens:    m.OutputTokens,
+		Error:           m.Error,
+		Attempts:        m.Attempts,
+		CreatedAt:       common.ParseDatetimeToStr(&m.CreatedAt),
+		StartedAt:       common.ParseDatetimeToStr(m.StartedAt),
+		FinishedAt:      common.ParseDatetimeToStr(m.FinishedAt),
+	}
+	if len(m.Result) > 0 {
+		data.Result = json.RawMessage(m.Result)
+	}
+	return &data
+}
+
+type GetJobRes struct {
+	Data   GetJobResData `json:"data"`
+	Errors []Err         `json:"errors"`
+} // @name GetJobRes
@@ -66,6 +66,15 @@ func (sw *StreamWriter) MinDelay() time.Duration {
 	return sw.minDelay
 }
 
+// CreateSSEOperationEvent はオペレーションIDを通知するSSEイベントを生成する。
+// ストリームの最初に送信し、クライアントが実行中にキャンセルできるようにする。
+// OpenAI互換のチャンクと区別するため "operation" という名前付きイベントとして送信する。
+// operationID: POST /v1/cubes/operations/:operation_id/cancel に指定するID
+func CreateSSEOperationEvent(operationID string) string {
+	jsonBytes, _ := json.Marshal(map[string]string{"operation_id": operationID})
+	return fmt.Sprintf("event: operation\ndata: %s\n\n", string(jsonBytes))
+}
+
 // CreateSSEChunk はOpenAI互換のSSEチャンクを生成する。
 // requestId: リクエスト単位で共通のID（呼び出し元で生成すること）
 // modelName: モデル名（例: "cuber-absorb"）
@@ -0,0 +1,33 @@
+package rtstream
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCreateSSEOperationEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		operationID string
+	}{
+		{name: "uuid", operationID: "4f9c2d1e-8b7a-4c3d-9e2f-1a0b9c8d7e6f"},
+		{name: "needs escaping", operationID: `a"b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CreateSSEOperationEvent(tt.operationID)
+			if !strings.HasPrefix(got, "event: operation\ndata: ") || !strings.HasSuffix(got, "\n\n") {
+				t.Fatalf("Unexpected event format: %q", got)
+			}
+			data := strings.TrimSuffix(strings.TrimPrefix(got, "event: operation\ndata: "), "\n\n")
+			var payload map[string]string
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				t.Fatalf("Failed to unmarshal data: %v", err)
+			}
+			if payload["operation_id"] != tt.operationID {
+				t.Errorf("operation_id = %q, want %q", payload["operation_id"], tt.operationID)
+			}
+		})
+	}
+}
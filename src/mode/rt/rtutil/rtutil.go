@@ -20,6 +20,7 @@ import (
 	"github.com/t-kawata/mycute/lib/eventbus"
 	"github.com/t-kawata/mycute/lib/httpclient"
 	"github.com/t-kawata/mycute/lib/s3client"
+	"github.com/t-kawata/mycute/mode/rt/rtjob"
 	"github.com/t-kawata/mycute/mode/rt/rtres"
 	"github.com/t-kawata/mycute/model"
 	"github.com/t-kawata/mycute/pkg/cuber"
@@ -40,6 +41,7 @@ type RtUtil struct {
 	CuberService    *cuber.CuberService
 	CuberCryptoSkey string
 	EventBus        *eventbus.EventBus
+	JobQueue        *rtjob.JobQueue
 }
 
 type JwtUsr struct {
@@ -7,6 +7,7 @@ import (
 	"unicode"
 
 	"github.com/t-kawata/mycute/mode/rt/rtres"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/tools/query"
 )
 
@@ -67,9 +68,25 @@ func FormatQueryResDataAsText(data *rtres.QueryCubeResData, isEn bool) string {
 			sb.WriteString("\n\n")
 		}
 
+		if data.Citations != nil && len(*data.Citations) > 0 {
+			sb.WriteString("**Sources:**\n")
+			writeCitations(&sb, *data.Citations, isEn)
+			sb.WriteString("\n")
+		}
+
+		if data.Table != nil {
+			sb.WriteString(fmt.Sprintf("**Result:** %d rows\n", len(data.Table.Rows)))
+			writeCypherTable(&sb, data.Table)
+			if data.Table.Truncated {
+				sb.WriteString("(Truncated: the row limit was reached.)\n")
+			}
+			sb.WriteString("\n")
+		}
+
 		sb.WriteString(fmt.Sprintf("- Input tokens used: %d\n", data.InputTokens))
 		sb.WriteString(fmt.Sprintf("- Output tokens used: %d\n", data.OutputTokens))
-		sb.WriteString(fmt.Sprintf("- Remaining query limit: %s", formatLimit(data.QueryLimit, isEn)))
+		sb.WriteString(fmt.Sprintf("- Remaining query limit: %s\n", formatLimit(data.QueryLimit, isEn)))
+		sb.WriteString(fmt.Sprintf("- Query ID (for feedback): %s", data.QueryID))
 	} else {
 		sb.WriteString("問い合わせが完了しました。\n\n")
 
@@ -99,14 +116,81 @@ func FormatQueryResDataAsText(data *rtres.QueryCubeResData, isEn bool) string {
 			sb.WriteString("\n\n")
 		}
 
+		if data.Citations != nil && len(*data.Citations) > 0 {
+			sb.WriteString("**出典:**\n")
+			writeCitations(&sb, *data.Citations, isEn)
+			sb.WriteString("\n")
+		}
+
+		if data.Table != nil {
+			sb.WriteString(fmt.Sprintf("**結果:** %d行\n", len(data.Table.Rows)))
+			writeCypherTable(&sb, data.Table)
+			if data.Table.Truncated {
+				sb.WriteString("（行数の上限に達したため、以降の結果は省略されました）\n")
+			}
+			sb.WriteString("\n")
+		}
+
 		sb.WriteString(fmt.Sprintf("- 使用した入力トークン数: %d\n", data.InputTokens))
 		sb.WriteString(fmt.Sprintf("- 使用した出力トークン数: %d\n", data.OutputTokens))
-		sb.WriteString(fmt.Sprintf("- 残りのクエリ可能回数: %s", formatLimit(data.QueryLimit, isEn)))
+		sb.WriteString(fmt.Sprintf("- 残りのクエリ可能回数: %s\n", formatLimit(data.QueryLimit, isEn)))
+		sb.WriteString(fmt.Sprintf("- クエリID（フィードバック用）: %s", data.QueryID))
 	}
 
 	return sb.String()
 }
 
+// writeCitations は出典を「- ファイル名 (チャンク番号)」形式で書き出します。
+// チャンク番号は人が読むため1始まりで表示します。
+func writeCitations(sb *strings.Builder, citations []*storage.Citation, isEn bool) {
+	for _, c := range citations {
+		if isEn {
+			sb.WriteString(fmt.Sprintf("- %s (chunk #%d)\n", c.FileName, c.ChunkIndex+1))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s (チャンク #%d)\n", c.FileName, c.ChunkIndex+1))
+		}
+	}
+}
+
+// writeCypherTable は Cypher クエリの結果を Markdown の表として書き出します。
+// ノードやリストなどの値は JSON 文字列で表示します。
+func writeCypherTable(sb *strings.Builder, table *storage.CypherResult) {
+	if len(table.Columns) == 0 {
+		return
+	}
+	sb.WriteString("| " + strings.Join(table.Columns, " | ") + " |\n")
+	sb.WriteString(strings.Repeat("|---", len(table.Columns)) + "|\n")
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = formatCypherCell(v)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+// formatCypherCell は表のセルに表示する文字列を返します。
+func formatCypherCell(v any) string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		s = ""
+	case string:
+		s = val
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err != nil {
+			s = fmt.Sprint(val)
+		} else {
+			s = string(b)
+		}
+	default:
+		s = fmt.Sprint(val)
+	}
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
 // FormatMemifyResDataAsText は MemifyCubeResData を読みやすいテキストに変換します。
 func FormatMemifyResDataAsText(data *rtres.MemifyCubeResData, isEn bool) string {
 	if isEn {
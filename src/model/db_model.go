@@ -102,6 +102,7 @@ type CubePermissions struct { // ========= 注意: gorm 用のモデルではな
 	MemifyLimit int  `json:"memify_limit"` // 自己強化可能回数
 	QueryLimit  int  `json:"query_limit"`  // クエリ利用可能回数
 	AllowStats  bool `json:"allow_stats"`  // 統計情報の閲覧可否 (true: 許可)
+	AllowCypher bool `json:"allow_cypher"` // Cypherクエリ (QUERY_TYPE_CYCLER) の実行可否 (true: 許可)
 	// Memify 実行時の epoch 数などの上限を設定します。
 	MemifyConfigLimit map[string]any `json:"memify_config_limit"`
 	// Query 実行時に指定可能な query_type のリスト。
@@ -122,6 +123,7 @@ type Cube struct {
 	EmbeddingApiKey    string         `gorm:"size:1024;not null;default:''" json:"-"`
 	ExpireAt           *time.Time     `gorm:"default:null"`
 	Permissions        datatypes.JSON `gorm:"default:null"`
+	ChatModelFallbacks datatypes.JSON `gorm:"default:null"` // フォールバック先の ChatModel の ID のリスト（使用する順）
 	SourceExportID     *uint          `gorm:"default:null"` // Link to Export record for ReKey
 	ApxID              uint           `gorm:"index:cube_apxid_vdrid_usrid_idx;index:cube_apxid_vdrid_uuid_idx;index:cube_apxid_vdrid_id_idx"`
 	VdrID              uint           `gorm:"index:cube_apxid_vdrid_usrid_idx;index:cube_apxid_vdrid_uuid_idx;index:cube_apxid_vdrid_id_idx"`
@@ -137,17 +139,20 @@ func (Cube) TableName() string {
 // CubeModelStat は Cube のモデルごとのトークン消費量を記録します。
 // MemoryGroup を最上位の粒度として含み、「どの専門分野に」「どのモデルで」「どれだけ使われたか」を把握できます。
 type CubeModelStat struct {
-	ID           uint      `gorm:"primarykey" json:"id"`
-	CubeID       uint      `gorm:"index:model_stat_cube_idx;not null;index:idx_cube_mg_model_action,unique,priority:1" json:"cube_id"`
-	MemoryGroup  string    `gorm:"size:64;not null;index:idx_cube_mg_model_action,unique,priority:2" json:"memory_group"` // e.g. "legal_expert"
-	ModelName    string    `gorm:"size:100;not null;index:idx_cube_mg_model_action,unique,priority:3" json:"model_name"`
-	ActionType   string    `gorm:"size:6;not null;index:idx_cube_mg_model_action,unique,priority:4" json:"action_type"` // "absorb", "memify", "query"
-	InputTokens  int64     `gorm:"default:0" json:"input_tokens"`
-	OutputTokens int64     `gorm:"default:0" json:"output_tokens"`
-	ApxID        uint      `gorm:"index:model_stat_apxid_vdrid_idx;not null" json:"apx_id"`
-	VdrID        uint      `gorm:"index:model_stat_apxid_vdrid_idx;not null" json:"vdr_id"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uint   `gorm:"primarykey" json:"id"`
+	CubeID       uint   `gorm:"index:model_stat_cube_idx;not null;index:idx_cube_mg_model_action,unique,priority:1" json:"cube_id"`
+	MemoryGroup  string `gorm:"size:64;not null;index:idx_cube_mg_model_action,unique,priority:2" json:"memory_group"` // e.g. "legal_expert"
+	ModelName    string `gorm:"size:100;not null;index:idx_cube_mg_model_action,unique,priority:3" json:"model_name"`
+	ActionType   string `gorm:"size:6;not null;index:idx_cube_mg_model_action,unique,priority:4" json:"action_type"` // "absorb", "memify", "query"
+	InputTokens  int64  `gorm:"default:0" json:"input_tokens"`
+	OutputTokens int64  `gorm:"default:0" json:"output_tokens"`
+	// 埋め込みキャッシュのヒット数・ミス数（埋め込みモデルの行のみ）
+	EmbeddingCacheHits   int64     `gorm:"default:0" json:"embedding_cache_hits"`
+	EmbeddingCacheMisses int64     `gorm:"default:0" json:"embedding_cache_misses"`
+	ApxID                uint      `gorm:"index:model_stat_apxid_vdrid_idx;not null" json:"apx_id"`
+	VdrID                uint      `gorm:"index:model_stat_apxid_vdrid_idx;not null" json:"vdr_id"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 func (CubeModelStat) TableName() string {
@@ -234,3 +239,103 @@ type BurnedKey struct {
 func (BurnedKey) TableName() string {
 	return "burned_keys"
 }
+
+// CubeQuery は Cube に対して実行されたクエリの記録です。
+// 回答の根拠として使用されたエッジを保持し、フィードバック（POST /v1/cubes/feedback）の対象を特定するために使用します。
+type CubeQuery struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	UUID        string         `gorm:"size:36;index:cube_query_uuid_idx;unique;not null" json:"uuid"` // クエリID（レスポンスの query_id）
+	CubeID      uint           `gorm:"index:cube_query_cube_idx;not null" json:"cube_id"`
+	MemoryGroup string         `gorm:"size:64;not null" json:"memory_group"`
+	QueryType   uint8          `gorm:"not null;default:0" json:"query_type"`
+	Text        string         `gorm:"type:text" json:"text"`
+	Edges       datatypes.JSON `gorm:"default:null" json:"edges"`                   // 回答の根拠として使用されたエッジ ([]CubeQueryEdge)
+	Feedback    string         `gorm:"size:10;not null;default:''" json:"feedback"` // "" (未評価), "up", "down", "correction"
+	FeedbackAt  *time.Time     `gorm:"default:null" json:"feedback_at"`
+	UsrID       uint           `gorm:"not null" json:"usr_id"` // クエリを実行したUsrID（フィードバックできるのは本人のみ）
+
+	ApxID     uint      `gorm:"index:cube_query_apxid_vdrid_idx;not null" json:"apx_id"`
+	VdrID     uint      `gorm:"index:cube_query_apxid_vdrid_idx;not null" json:"vdr_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (CubeQuery) TableName() string {
+	return "cube_queries"
+}
+
+// CubeQueryEdge は CubeQuery の edges カラム（JSON）に格納されるエッジの識別子です。
+type CubeQueryEdge struct { // ========= 注意: gorm 用のモデルではない =========
+	SourceID string `json:"source_id"`
+	Type     string `json:"type"`
+	TargetID string `json:"target_id"`
+}
+
+// Job は Absorb / Memify / 埋め込みモデルの移行を非同期に実行するジョブです。
+// jobs テーブル自体が永続化されたキューを兼ねており、サーバーの再起動後も未完了のジョブは再実行されます。
+type Job struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	UUID            string         `gorm:"size:36;index:job_uuid_idx;unique;not null" json:"uuid"`               // ジョブID（レスポンスの job_id）
+	Type            string         `gorm:"size:20;not null" json:"type"`                                         // "absorb", "memify", "migrate_embeddings"
+	Status          string         `gorm:"size:20;index:job_status_idx;not null;default:'queued'" json:"status"` // "queued", "running", "succeeded", "failed"
+	CubeID          uint           `gorm:"index:job_cube_idx;not null" json:"cube_id"`                           // 対象の Cube
+	MemoryGroup     string         `gorm:"size:64;not null" json:"memory_group"`                                 // 対象のメモリーグループ
+	Params          datatypes.JSON `gorm:"default:null" json:"params"`                                           // 実行パラメータ (JobAbsorbParams / JobMemifyParams / JobMigrateEmbeddingsParams)
+	Progress        string         `gorm:"size:100;not null;default:''" json:"progress"`                         // 最後に発火したイベント名 (EVENT_ABSORB_* / EVENT_MEMIFY_* / EVENT_MIGRATE_EMBEDDINGS_*)
+	ProgressMessage string         `gorm:"type:text" json:"progress_message"`                                    // 最後に発火したイベントのメッセージ
+	EventCount      int            `gorm:"not null;default:0" json:"event_count"`                                // これまでに発火したイベント数
+	InputTokens     int64          `gorm:"not null;default:0" json:"input_tokens"`                               // 終了時のトークン使用量
+	OutputTokens    int64          `gorm:"not null;default:0" json:"output_tokens"`                              // 終了時のトークン使用量
+	Result          datatypes.JSON `gorm:"default:null" json:"result"`                                           // 正常終了時の結果 (AbsorbCubeResData / MemifyCubeResData / MigrateCubeEmbeddingsJobResult)
+	Error           string         `gorm:"type:text" json:"error"`                                               // 異常終了時のエラー
+	Attempts        int            `gorm:"not null;default:0" json:"attempts"`                                   // 実行を開始した回数（再起動による再実行を含む）
+	Host            string         `gorm:"size:255;not null;default:''" json:"host"`                             // 実行中のサーバーのホスト名（再起動時に再実行するジョブの判定に使用）
+	StartedAt       *time.Time     `gorm:"default:null" json:"started_at"`
+	FinishedAt      *time.Time     `gorm:"default:null" json:"finished_at"`
+	UsrID           uint           `gorm:"not null" json:"usr_id"` // ジョブを登録したUsrID（参照できるのは本人のみ）
+
+	ApxID     uint      `gorm:"index:job_apxid_vdrid_idx;not null" json:"apx_id"`
+	VdrID     uint      `gorm:"index:job_apxid_vdrid_idx;not null" json:"vdr_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// JobAbsorbParams は Job の params カラム（JSON）に格納される Absorb の実行パラメータです。
+type JobAbsorbParams struct { // ========= 注意: gorm 用のモデルではない =========
+	FilePaths                  []string `json:"file_paths"` // ジョブ用ディレクトリに保存した取り込み対象ファイル
+	SourceID                   string   `json:"source_id"`
+	ChunkSize                  int      `json:"chunk_size"`
+	ChunkOverlap               int      `json:"chunk_overlap"`
+	ChunkMaxRetries            int      `json:"chunk_max_retries"`
+	MaxFailedChunkRatio        float64  `json:"max_failed_chunk_ratio"`
+	ChatModelID                uint     `json:"chat_model_id"`
+	IsEn                       bool     `json:"is_en"`
+	HalfLifeDays               float64  `json:"half_life_days"`
+	PruneThreshold             float64  `json:"prune_threshold"`
+	MinSurvivalProtectionHours float64  `json:"min_survival_protection_hours"`
+	MdlKNeighbors              int      `json:"mdl_k_neighbors"`
+	IsCorrection               bool     `json:"is_correction"` // フィードバックの訂正文の取り込み（MemoryGroup 設定を変更しない）
+}
+
+// JobMemifyParams は Job の params カラム（JSON）に格納される Memify の実行パラメータです。
+type JobMemifyParams struct { // ========= 注意: gorm 用のモデルではない =========
+	Epochs                  int   `json:"epochs"`
+	PrioritizeUnknowns      bool  `json:"prioritize_unknowns"`
+	ConflictResolutionStage uint8 `json:"conflict_resolution_stage"`
+	ChatModelID             uint  `json:"chat_model_id"`
+	IsEn                    bool  `json:"is_en"`
+}
+
+// JobMigrateEmbeddingsParams は Job の params カラム（JSON）に格納される埋め込みモデルの移行の実行パラメータです。
+type JobMigrateEmbeddingsParams struct { // ========= 注意: gorm 用のモデルではない =========
+	EmbeddingProvider  string `json:"embedding_provider"`
+	EmbeddingModel     string `json:"embedding_model"`
+	EmbeddingDimension uint   `json:"embedding_dimension"`
+	EmbeddingBaseURL   string `json:"embedding_base_url"`
+	EmbeddingApiKey    string `json:"embedding_api_key"` // 暗号化済み（Cube.EmbeddingApiKey と同じ形式）
+	IsEn               bool   `json:"is_en"`
+}
@@ -0,0 +1,258 @@
+// Package checkpoint は、Absorb の中間結果（チャンク、チャンクごとに抽出したグラフ）をファイルに保存します。
+// Absorb は Cube のトランザクション内で実行され、失敗した場合は全てロールバックされるため、
+// 中間結果は Cube の外に保存し、同じ入力で Absorb を再実行した際に完了済みのチャンクから再開できるようにします。
+//
+// ディレクトリ構成（Cube の DB ファイルと同じディレクトリ）:
+//
+//	<cube_uuid>.checkpoints/
+//	  <data_id>/
+//	    chunks.json         ドキュメントとチャンク（embedding を含む）
+//	    graphs/<chunk_id>.json  チャンクから抽出したグラフ（正規化前）
+//
+// データIDは内容のハッシュとメモリーグループから決まるため、同じ入力を同じメモリーグループに取り込むと同じチェックポイントが使用されます。
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+const (
+	CHUNKS_FILE_NAME = "chunks.json"
+	GRAPHS_DIR_NAME  = "graphs"
+)
+
+// ChunksCheckpoint は、1つのデータのチャンク化の結果です。
+// チャンクの設定が異なる場合は再利用しません。
+type ChunksCheckpoint struct {
+	ChunkSize    int               `json:"chunk_size"`
+	ChunkOverlap int               `json:"chunk_overlap"`
+	Document     *storage.Document `json:"document"`
+	Chunks       []*storage.Chunk  `json:"chunks"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// Store は、1回の Absorb で使用するチェックポイントの保存先です。
+// nil の Store は何も保存・復元しません。
+type Store struct {
+	dir          string
+	chunkSize    int
+	chunkOverlap int
+	mu           sync.Mutex
+	documents    map[string]string // ドキュメントID -> データID（グラフの保存先の解決に使用）
+}
+
+// DirPath は、Cube のチェックポイントを保存するディレクトリのパスを返します。
+func DirPath(cubeDbFilePath string) string {
+	return strings.TrimSuffix(cubeDbFilePath, filepath.Ext(cubeDbFilePath)) + ".checkpoints"
+}
+
+// NewStore は、Cube のチェックポイントの Store を作成します。
+// 引数:
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - chunkSize, chunkOverlap: 今回のチャンクの設定（異なる設定で作成されたチャンクは再利用しない）
+func NewStore(cubeDbFilePath string, chunkSize int, chunkOverlap int) *Store {
+	return &Store{
+		dir:          DirPath(cubeDbFilePath),
+		chunkSize:    chunkSize,
+		chunkOverlap: chunkOverlap,
+		documents:    map[string]string{},
+	}
+}
+
+// LoadChunks は、データのチャンク化の結果を復元します。
+// チェックポイントがない場合、読み込めない場合、チャンクの設定が異なる場合は false を返します。
+func (s *Store) LoadChunks(dataID string) (*storage.Document, []*storage.Chunk, bool) {
+	if s == nil {
+		return nil, nil, false
+	}
+	var cp ChunksCheckpoint
+	if err := readJSON(filepath.Join(s.dir, dataID, CHUNKS_FILE_NAME), &cp); err != nil {
+		return nil, nil, false
+	}
+	if cp.Document == nil || len(cp.Chunks) == 0 || cp.ChunkSize != s.chunkSize || cp.ChunkOverlap != s.chunkOverlap {
+		return nil, nil, false
+	}
+	s.mu.Lock()
+	s.documents[cp.Document.ID] = dataID
+	s.mu.Unlock()
+	return cp.Document, cp.Chunks, true
+}
+
+// SaveChunks は、データのチャンク化の結果を保存します。
+// 以前のチェックポイント（異なる設定で作成されたチャンクとそのグラフ）は破棄します。
+func (s *Store) SaveChunks(dataID string, doc *storage.Document, chunks []*storage.Chunk) error {
+	if s == nil {
+		return nil
+	}
+	dataDir := filepath.Join(s.dir, dataID)
+	if err := os.RemoveAll(dataDir); err != nil {
+		return fmt.Errorf("Checkpoint: Failed to reset %s: %w", dataDir, err)
+	}
+	if err := writeJSON(filepath.Join(dataDir, CHUNKS_FILE_NAME), &ChunksCheckpoint{
+		ChunkSize:    s.chunkSize,
+		ChunkOverlap: s.chunkOverlap,
+		Document:     doc,
+		Chunks:       chunks,
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.documents[doc.ID] = dataID
+	s.mu.Unlock()
+	return nil
+}
+
+// LoadGraph は、チャンクから抽出したグラフを復元します。チェックポイントがない場合は false を返します。
+// エッジには抽出元のチャンクIDを設定します。
+func (s *Store) LoadGraph(chunk *storage.Chunk) (*storage.GraphData, bool) {
+	path, ok := s.graphPath(chunk)
+	if !ok {
+		return nil, false
+	}
+	var graphData storage.GraphData
+	if err := readJSON(path, &graphData); err != nil {
+		return nil, false
+	}
+	for _, edge := range graphData.Edges {
+		edge.ChunkID = chunk.ID
+	}
+	return &graphData, true
+}
+
+// SaveGraph は、チャンクから抽出したグラフ（正規化前）を保存します。
+// チャンク化の結果が保存されていないチャンクの場合は何もしません。
+func (s *Store) SaveGraph(chunk *storage.Chunk, graphData *storage.GraphData) error {
+	path, ok := s.graphPath(chunk)
+	if !ok {
+		return nil
+	}
+	return writeJSON(path, graphData)
+}
+
+// CountGraphs は、データのチャンクのうち、グラフの抽出が完了しているチャンクの数を返します。
+func (s *Store) CountGraphs(dataID string) int {
+	if s == nil {
+		return 0
+	}
+	entries, err := os.ReadDir(filepath.Join(s.dir, dataID, GRAPHS_DIR_NAME))
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			count++
+		}
+	}
+	return count
+}
+
+// Delete は、データのチェックポイントを削除します。Absorb のコミット後に呼び出します。
+func (s *Store) Delete(dataID string) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.RemoveAll(filepath.Join(s.dir, dataID)); err != nil {
+		return fmt.Errorf("Checkpoint: Failed to delete checkpoint of %s: %w", dataID, err)
+	}
+	// 空になったディレクトリは残さない（失敗しても問題ない）
+	os.Remove(s.dir)
+	return nil
+}
+
+// PruneExpired は、最終更新から maxAge 以上経過したチェックポイントを削除します。
+// 再実行されないまま残った、失敗した Absorb のチェックポイントの掃除に使用します。
+//
+// 返り値:
+//   - int: 削除したデータのチェックポイントの数
+//   - error: ディレクトリの読み込みに失敗した場合
+func PruneExpired(cubeDbFilePath string, maxAge time.Duration) (int, error) {
+	dir := DirPath(cubeDbFilePath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("Checkpoint: Failed to read %s: %w", dir, err)
+	}
+	threshold := time.Now().Add(-maxAge)
+	pruned := 0
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dataDir := filepath.Join(dir, e.Name())
+		if latestModTime(dataDir).Before(threshold) {
+			if err := os.RemoveAll(dataDir); err == nil {
+				pruned++
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// graphPath は、チャンクのグラフの保存先を返します。
+func (s *Store) graphPath(chunk *storage.Chunk) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	dataID, ok := s.documents[chunk.DocumentID]
+	s.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(s.dir, dataID, GRAPHS_DIR_NAME, chunk.ID+".json"), true
+}
+
+// latestModTime は、ディレクトリ配下のファイルの最終更新日時のうち最も新しいものを返します。
+func latestModTime(dir string) time.Time {
+	var latest time.Time
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
+}
+
+// writeJSON は、一時ファイルに書き込んでからリネームすることで、書き込み途中のファイルが残らないように保存します。
+func writeJSON(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Checkpoint: Failed to create directory for %s: %w", path, err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("Checkpoint: Failed to marshal %s: %w", path, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("Checkpoint: Failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Checkpoint: Failed to rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
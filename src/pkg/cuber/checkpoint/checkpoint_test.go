@@ -0,0 +1,225 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+const (
+	testChunkSize    = 512
+	testChunkOverlap = 64
+)
+
+// newTestStore は、一時ディレクトリの Cube に対する Store と、その DB ファイルパスを返します。
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	dbFilePath := filepath.Join(t.TempDir(), "cube.db")
+	return NewStore(dbFilePath, testChunkSize, testChunkOverlap), dbFilePath
+}
+
+// testChunks は、ドキュメント doc1 と、そのチャンク c1, c2 を返します。
+func testChunks() (*storage.Document, []*storage.Chunk) {
+	doc := &storage.Document{ID: "doc1", DataID: "data1", Text: "first second"}
+	chunks := []*storage.Chunk{
+		{ID: "c1", DocumentID: "doc1", Text: "first", ChunkIndex: 0, Embedding: []float32{1, 0}},
+		{ID: "c2", DocumentID: "doc1", Text: "second", ChunkIndex: 1, Embedding: []float32{0, 1}},
+	}
+	return doc, chunks
+}
+
+func TestLoadChunks(t *testing.T) {
+	tests := []struct {
+		name         string
+		save         bool
+		chunkSize    int
+		chunkOverlap int
+		want         bool
+	}{
+		{name: "no checkpoint", chunkSize: testChunkSize, chunkOverlap: testChunkOverlap},
+		{name: "same settings", save: true, chunkSize: testChunkSize, chunkOverlap: testChunkOverlap, want: true},
+		{name: "different chunk size", save: true, chunkSize: testChunkSize * 2, chunkOverlap: testChunkOverlap},
+		{name: "different chunk overlap", save: true, chunkSize: testChunkSize, chunkOverlap: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, dbFilePath := newTestStore(t)
+			doc, chunks := testChunks()
+			if tt.save {
+				if err := s.SaveChunks("data1", doc, chunks); err != nil {
+					t.Fatalf("SaveChunks failed: %v", err)
+				}
+			}
+			// 再実行時の Absorb は新しい Store で復元する
+			resumed := NewStore(dbFilePath, tt.chunkSize, tt.chunkOverlap)
+			gotDoc, gotChunks, ok := resumed.LoadChunks("data1")
+			if ok != tt.want {
+				t.Fatalf("LoadChunks() ok = %v, want %v", ok, tt.want)
+			}
+			if !ok {
+				return
+			}
+			if gotDoc.ID != doc.ID || len(gotChunks) != len(chunks) {
+				t.Fatalf("Unexpected chunks: doc=%+v chunks=%d", gotDoc, len(gotChunks))
+			}
+			for i, c := range gotChunks {
+				if c.ID != chunks[i].ID || c.Text != chunks[i].Text || c.ChunkIndex != chunks[i].ChunkIndex || len(c.Embedding) != len(chunks[i].Embedding) {
+					t.Errorf("chunk %d = %+v, want %+v", i, c, chunks[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGraphCheckpoint(t *testing.T) {
+	graph := &storage.GraphData{
+		Nodes: []*storage.Node{{ID: "Alice", Type: "Person"}, {ID: "Acme", Type: "Organization"}},
+		Edges: []*storage.Edge{{SourceID: "Alice", TargetID: "Acme", Type: "WORKS_AT"}},
+	}
+	tests := []struct {
+		name       string
+		resume     bool // 新しい Store で LoadChunks してからグラフを復元する
+		rechunk    bool // グラフの保存後に SaveChunks でチャンクを作り直す
+		unknownDoc bool // チャンク化の結果が保存されていないドキュメントのチャンク
+		wantLoaded bool
+		wantCount  int
+	}{
+		{name: "same store", wantLoaded: true, wantCount: 1},
+		{name: "resumed store", resume: true, wantLoaded: true, wantCount: 1},
+		{name: "rechunked data", rechunk: true},
+		{name: "chunk of unknown document", unknownDoc: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, dbFilePath := newTestStore(t)
+			doc, chunks := testChunks()
+			if err := s.SaveChunks("data1", doc, chunks); err != nil {
+				t.Fatalf("SaveChunks failed: %v", err)
+			}
+			chunk := chunks[0]
+			if tt.unknownDoc {
+				chunk = &storage.Chunk{ID: "c9", DocumentID: "doc9"}
+			}
+			if err := s.SaveGraph(chunk, graph); err != nil {
+				t.Fatalf("SaveGraph failed: %v", err)
+			}
+			if tt.rechunk {
+				if err := s.SaveChunks("data1", doc, chunks); err != nil {
+					t.Fatalf("SaveChunks failed: %v", err)
+				}
+			}
+			if tt.resume {
+				s = NewStore(dbFilePath, testChunkSize, testChunkOverlap)
+				if _, _, ok := s.LoadChunks("data1"); !ok {
+					t.Fatalf("LoadChunks failed")
+				}
+			}
+			if got := s.CountGraphs("data1"); got != tt.wantCount {
+				t.Errorf("CountGraphs() = %d, want %d", got, tt.wantCount)
+			}
+			got, ok := s.LoadGraph(chunk)
+			if ok != tt.wantLoaded {
+				t.Fatalf("LoadGraph() ok = %v, want %v", ok, tt.wantLoaded)
+			}
+			if !ok {
+				return
+			}
+			if len(got.Nodes) != 2 || len(got.Edges) != 1 {
+				t.Fatalf("Unexpected graph: %d nodes, %d edges", len(got.Nodes), len(got.Edges))
+			}
+			// 抽出元のチャンクIDは JSON に保存されないため、復元時に設定される
+			if got.Edges[0].ChunkID != chunk.ID {
+				t.Errorf("edge chunk ID = %q, want %q", got.Edges[0].ChunkID, chunk.ID)
+			}
+			if _, ok := s.LoadGraph(chunks[1]); ok {
+				t.Errorf("Graph of a chunk that was not extracted was loaded")
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	s, dbFilePath := newTestStore(t)
+	doc, chunks := testChunks()
+	for _, dataID := range []string{"data1", "data2"} {
+		if err := s.SaveChunks(dataID, doc, chunks); err != nil {
+			t.Fatalf("SaveChunks failed: %v", err)
+		}
+	}
+	tests := []struct {
+		dataID      string
+		wantDirLeft bool // チェックポイントのディレクトリが残るかどうか
+	}{
+		{dataID: "data1", wantDirLeft: true},
+		{dataID: "data2", wantDirLeft: false},
+		{dataID: "unknown", wantDirLeft: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataID, func(t *testing.T) {
+			if err := s.Delete(tt.dataID); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(DirPath(dbFilePath), tt.dataID)); !os.IsNotExist(err) {
+				t.Errorf("Checkpoint of %s was not deleted: %v", tt.dataID, err)
+			}
+			if _, err := os.Stat(DirPath(dbFilePath)); (err == nil) != tt.wantDirLeft {
+				t.Errorf("checkpoint directory left = %v, want %v", err == nil, tt.wantDirLeft)
+			}
+		})
+	}
+}
+
+func TestPruneExpired(t *testing.T) {
+	s, dbFilePath := newTestStore(t)
+	doc, chunks := testChunks()
+	old := time.Now().Add(-48 * time.Hour)
+	tests := []struct {
+		dataID    string
+		age       time.Time // ファイルの最終更新日時（ゼロ値の場合は変更しない）
+		wantKept  bool
+		withGraph bool // 最近抽出したグラフを持つ
+	}{
+		{dataID: "fresh", wantKept: true},
+		{dataID: "expired", age: old},
+		{dataID: "resumed recently", age: old, withGraph: true, wantKept: true},
+	}
+	for _, tt := range tests {
+		if err := s.SaveChunks(tt.dataID, doc, chunks); err != nil {
+			t.Fatalf("SaveChunks failed: %v", err)
+		}
+		if !tt.age.IsZero() {
+			dataDir := filepath.Join(DirPath(dbFilePath), tt.dataID)
+			for _, path := range []string{filepath.Join(dataDir, CHUNKS_FILE_NAME), dataDir} {
+				if err := os.Chtimes(path, tt.age, tt.age); err != nil {
+					t.Fatalf("Chtimes failed: %v", err)
+				}
+			}
+		}
+		if tt.withGraph {
+			if err := s.SaveGraph(chunks[0], &storage.GraphData{}); err != nil {
+				t.Fatalf("SaveGraph failed: %v", err)
+			}
+		}
+	}
+	pruned, err := PruneExpired(dbFilePath, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("PruneExpired failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("pruned = %d, want 1", pruned)
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataID, func(t *testing.T) {
+			_, err := os.Stat(filepath.Join(DirPath(dbFilePath), tt.dataID))
+			if kept := err == nil; kept != tt.wantKept {
+				t.Errorf("kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+	if pruned, err := PruneExpired(filepath.Join(t.TempDir(), "missing.db"), time.Hour); err != nil || pruned != 0 {
+		t.Errorf("PruneExpired() without checkpoints = %d, %v", pruned, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,19 +20,27 @@ import (
 	"github.com/cloudwego/eino/schema"
 	"github.com/ikawaha/kagome-dict/ipa"
 	"github.com/ikawaha/kagome/v2/tokenizer"
+	appconfig "github.com/t-kawata/mycute/config"
 	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/checkpoint"
 	"github.com/t-kawata/mycute/pkg/cuber/db/ladybugdb"
+	"github.com/t-kawata/mycute/pkg/cuber/embedcache"
 	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
 	"github.com/t-kawata/mycute/pkg/cuber/pipeline"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/providers"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/chunking"
+	"github.com/t-kawata/mycute/pkg/cuber/tasks/community"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/graph"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/ingestion"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/memify"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/metacognition"
+	"github.com/t-kawata/mycute/pkg/cuber/tasks/resolution"
 	storageTaskPkg "github.com/t-kawata/mycute/pkg/cuber/tasks/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/tasks/summarization"
+	"github.com/t-kawata/mycute/pkg/cuber/tools/cypher"
 	"github.com/t-kawata/mycute/pkg/cuber/tools/query"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 	"github.com/t-kawata/mycute/pkg/cuber/utils"
@@ -39,6 +48,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrDataNotFound は、指定されたデータがメモリーグループ内に存在しない場合に返されます。
+var ErrDataNotFound = errors.New("data not found")
+
+// ErrInvalidCypherQuery は、Cypherクエリが読み取り専用・スコープの検証に失敗した場合に返されます。
+var ErrInvalidCypherQuery = errors.New("invalid cypher query")
+
 type StorageSet struct {
 	Vector     storage.VectorStorage // ベクトルストレージ（LadybugDB）
 	Graph      storage.GraphStorage  // グラフストレージ（LadybugDB）
@@ -56,6 +71,9 @@ type CuberService struct {
 	Kagome     *tokenizer.Tokenizer   // 日本語形態素解析器（Kagome）- シングルトンとして全コンポーネントで共有
 	closeCh    chan struct{}          // サービス終了通知用チャネル
 	Logger     *zap.Logger
+
+	operations   map[string]*Operation // 実行中のオペレーション（キーはオペレーションID）
+	operationsMu sync.RWMutex          // operations へのアクセス保護
 }
 
 // NewCuberService は、CuberServiceの新しいインスタンスを作成します。
@@ -119,6 +137,10 @@ func NewCuberService(config types.CuberConfig) (*CuberService, error) {
 	if config.S3RetentionHours == 0 {
 		config.S3RetentionHours = 24
 	}
+	// ベクトルインデックス設定
+	if config.VectorSearchEfs == 0 {
+		config.VectorSearchEfs = appconfig.DEFAULT_VECTOR_SEARCH_EFS
+	}
 	// Graph Metabolism設定
 	if config.GraphMetabolismAlpha == 0 {
 		config.GraphMetabolismAlpha = 0.2
@@ -173,6 +195,7 @@ func NewCuberService(config types.CuberConfig) (*CuberService, error) {
 		Kagome:     kagome,
 		closeCh:    closeCh,
 		Logger:     config.Logger,
+		operations: make(map[string]*Operation),
 	}
 
 	// Start StorageGC routine
@@ -243,25 +266,68 @@ func (s *CuberService) createTempEmbedder(ctx context.Context, config types.Embe
 		APIKey:    config.ApiKey,
 		BaseURL:   config.BaseURL,
 		ModelName: config.Model,
+		Dimension: config.Dimension,
 	}
 	einoRawEmb, err := providers.NewEmbedder(ctx, embConfig)
 	if err != nil {
 		return nil, fmt.Errorf("createTempEmbedder: failed to create raw embedder: %w", err)
 	}
-	return query.NewEinoEmbedderAdapter(einoRawEmb, config.Model), nil
+	// Embedding models are never swapped for a fallback because that would change the cube's vector space.
+	breaker := providers.GetCircuitBreaker(embConfig.Type, embConfig.BaseURL, appconfig.CIRCUIT_BREAKER_FAILURE_THRESHOLD, time.Duration(appconfig.CIRCUIT_BREAKER_COOLDOWN_SEC)*time.Second)
+	resilientEmb := providers.NewResilientEmbedder(einoRawEmb, config.Model, breaker, providerRetryPolicy(), s.Logger)
+	return query.NewEinoEmbedderAdapter(resilientEmb, config.Model, providers.GetEmbeddingBatchLimits(embConfig.Type)), nil
+}
+
+// createCachedEmbedder creates a temporary embedder that consults the cube's embedding cache before calling the provider.
+func (s *CuberService) createCachedEmbedder(ctx context.Context, st *StorageSet, config types.EmbeddingModelConfig) (storage.Embedder, error) {
+	embedder, err := s.createTempEmbedder(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return embedcache.NewCachedEmbedder(embedder, st.Vector, config, s.Logger), nil
 }
 
 // createTempChatModel creates a temporary chat model instance for a specific operation.
+// The returned model retries transient provider errors, skips providers whose circuit breaker is open,
+// and falls back to config.Fallbacks in order when the primary model keeps failing.
 func (s *CuberService) createTempChatModel(ctx context.Context, config types.ChatModelConfig) (model.ToolCallingChatModel, error) {
-	pConfig := providers.ProviderConfig{
-		Type:        providers.ProviderType(config.Provider),
-		APIKey:      config.ApiKey,
-		BaseURL:     config.BaseURL,
-		ModelName:   config.Model,
-		MaxTokens:   config.MaxTokens,
-		Temperature: config.Temperature,
-	}
-	return providers.NewChatModel(ctx, pConfig)
+	configs := append([]types.ChatModelConfig{config}, config.Fallbacks...)
+	candidates := make([]providers.ChatModelCandidate, 0, len(configs))
+	for i, c := range configs {
+		pConfig := providers.ProviderConfig{
+			Type:        providers.ProviderType(c.Provider),
+			APIKey:      c.ApiKey,
+			BaseURL:     c.BaseURL,
+			ModelName:   c.Model,
+			MaxTokens:   c.MaxTokens,
+			Temperature: c.Temperature,
+		}
+		chatModel, err := providers.NewChatModel(ctx, pConfig)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			// A misconfigured fallback must not prevent the primary model from being used
+			utils.LogWarn(s.Logger, "createTempChatModel: Skipping fallback chat model", zap.String("model", c.Model), zap.Error(err))
+			continue
+		}
+		candidates = append(candidates, providers.ChatModelCandidate{
+			ModelName: c.Model,
+			Model:     chatModel,
+			Breaker:   providers.GetCircuitBreaker(pConfig.Type, pConfig.BaseURL, appconfig.CIRCUIT_BREAKER_FAILURE_THRESHOLD, time.Duration(appconfig.CIRCUIT_BREAKER_COOLDOWN_SEC)*time.Second),
+		})
+	}
+	return providers.NewResilientChatModel(candidates, providerRetryPolicy(), s.Logger), nil
+}
+
+// providerRetryPolicy returns the retry policy for provider calls built from the application settings.
+func providerRetryPolicy() providers.RetryPolicy {
+	return providers.RetryPolicy{
+		MaxRetries:     appconfig.PROVIDER_MAX_RETRIES,
+		InitialBackoff: time.Duration(appconfig.PROVIDER_RETRY_BACKOFF_MS) * time.Millisecond,
+		MaxBackoff:     time.Duration(appconfig.PROVIDER_RETRY_MAX_BACKOFF_MS) * time.Millisecond,
+		RetryBudget:    appconfig.PROVIDER_RETRY_BUDGET,
+	}
 }
 
 // VerifyChatModelConfiguration validates the chat model configuration by running a live test.
@@ -350,6 +416,7 @@ func (s *CuberService) GetOrOpenStorage(cubeDbFilePath string, embeddingModelCon
 	if err != nil {
 		return nil, fmt.Errorf("Failed to open LadybugDB at %s: %w", cubeDbFilePath, err)
 	}
+	ladybugSt.VectorSearchEfs = s.Config.VectorSearchEfs
 	// Ensure schema (lazy init)
 	if err := ladybugSt.EnsureSchema(context.Background(), embeddingModelConfig); err != nil {
 		ladybugSt.Close()
@@ -374,6 +441,106 @@ func (s *CuberService) UpsertMemoryGroupConfig(ctx context.Context, cubeDbFilePa
 	return st.Graph.UpsertMemoryGroup(ctx, config)
 }
 
+// withMemoryGroupSettings は、メモリーグループのプロンプトの上書きとオントロジーを読み込み、コンテキストに設定します。
+// 各タスクは prompts.Get でプロンプトを解決し、ontology.FromContext でオントロジーを参照します。
+func (s *CuberService) withMemoryGroupSettings(ctx context.Context, st *StorageSet, memoryGroup string) (context.Context, error) {
+	overrides, err := st.Graph.GetPromptOverrides(ctx, memoryGroup, true)
+	if err != nil {
+		return ctx, fmt.Errorf("Failed to get prompt overrides: %w", err)
+	}
+	active := make(map[prompts.Name]string, len(overrides))
+	for _, o := range overrides {
+		active[prompts.Name(o.Name)] = o.Content
+	}
+	ctx = prompts.WithResolver(ctx, prompts.NewResolver(active))
+	onto, err := s.loadOntology(ctx, st, memoryGroup)
+	if err != nil {
+		return ctx, err
+	}
+	return ontology.WithOntology(ctx, onto), nil
+}
+
+// loadOntology は、メモリーグループのオントロジーを読み込みます。定義されていない場合は nil を返します。
+func (s *CuberService) loadOntology(ctx context.Context, st *StorageSet, memoryGroup string) (*ontology.Ontology, error) {
+	definition, err := st.Graph.GetOntology(ctx, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get ontology: %w", err)
+	}
+	if definition == "" {
+		return nil, nil
+	}
+	onto, err := ontology.Parse(definition)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ontology for memory group %s: %w", memoryGroup, err)
+	}
+	return onto, nil
+}
+
+// GetPromptOverrides は、メモリーグループのプロンプトの上書きを取得します。
+// activeOnly が false の場合は、無効になったバージョン（履歴）も含めて返します。
+func (s *CuberService) GetPromptOverrides(ctx context.Context, cubeDbFilePath string, memoryGroup string, activeOnly bool, embeddingModelConfig types.EmbeddingModelConfig) ([]*storage.PromptOverride, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("GetPromptOverrides: Failed to get storage: %w", err)
+	}
+	return st.Graph.GetPromptOverrides(ctx, memoryGroup, activeOnly)
+}
+
+// SavePromptOverride は、メモリーグループのプロンプトの上書きを検証し、新しいバージョンとして保存します。
+// 保存した上書きは、以降の Absorb / Memify / Query から使用されます。
+func (s *CuberService) SavePromptOverride(ctx context.Context, cubeDbFilePath string, memoryGroup string, name prompts.Name, content string, embeddingModelConfig types.EmbeddingModelConfig) (*storage.PromptOverride, error) {
+	if err := prompts.ValidateOverride(name, content); err != nil {
+		return nil, fmt.Errorf("SavePromptOverride: %w", err)
+	}
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("SavePromptOverride: Failed to get storage: %w", err)
+	}
+	return st.Graph.SavePromptOverride(ctx, memoryGroup, string(name), content)
+}
+
+// ResetPromptOverride は、メモリーグループのプロンプトの上書きを無効にし、デフォルトのプロンプトに戻します。
+// 上書きの履歴は残ります。有効な上書きがなかった場合は false を返します。
+func (s *CuberService) ResetPromptOverride(ctx context.Context, cubeDbFilePath string, memoryGroup string, name prompts.Name, embeddingModelConfig types.EmbeddingModelConfig) (bool, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return false, fmt.Errorf("ResetPromptOverride: Failed to get storage: %w", err)
+	}
+	return st.Graph.DeactivatePromptOverride(ctx, memoryGroup, string(name))
+}
+
+// GetOntology は、メモリーグループのオントロジーを取得します。定義されていない場合は nil を返します。
+func (s *CuberService) GetOntology(ctx context.Context, cubeDbFilePath string, memoryGroup string, embeddingModelConfig types.EmbeddingModelConfig) (*ontology.Ontology, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("GetOntology: Failed to get storage: %w", err)
+	}
+	return s.loadOntology(ctx, st, memoryGroup)
+}
+
+// SaveOntology は、メモリーグループのオントロジーを検証して保存します（既存の定義は置き換えられます）。
+// 保存したオントロジーは、以降の Absorb（グラフ抽出）と、Memify / Query の矛盾解決から使用されます。
+// 保存済みのノード・エッジのタイプは変更されません。
+func (s *CuberService) SaveOntology(ctx context.Context, cubeDbFilePath string, memoryGroup string, onto *ontology.Ontology, embeddingModelConfig types.EmbeddingModelConfig) error {
+	if err := onto.Validate(); err != nil {
+		return fmt.Errorf("SaveOntology: %w", err)
+	}
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return fmt.Errorf("SaveOntology: Failed to get storage: %w", err)
+	}
+	return st.Graph.SaveOntology(ctx, memoryGroup, onto.String())
+}
+
+// DeleteOntology は、メモリーグループのオントロジーを削除します。定義されていなかった場合は false を返します。
+func (s *CuberService) DeleteOntology(ctx context.Context, cubeDbFilePath string, memoryGroup string, embeddingModelConfig types.EmbeddingModelConfig) (bool, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return false, fmt.Errorf("DeleteOntology: Failed to get storage: %w", err)
+	}
+	return st.Graph.DeleteOntology(ctx, memoryGroup)
+}
+
 // startStorageGCRoutine periodically checks for idle storage connections and closes them.
 func (s *CuberService) startStorageGCRoutine() {
 	ticker := time.NewTicker(1 * time.Minute) // Check every minute
@@ -452,6 +619,38 @@ func CreateCubeDB(dbFilePath string, embeddingModelConfig types.EmbeddingModelCo
 	return nil
 }
 
+// RebuildCubeVectorIndexes は、既存の Cube データベースのベクトルインデックスを作成し直します。
+// インポートしたデータベースファイルは、エクスポート元のインデックスをそのまま含む（または含まない）ため、
+// 取り込み後に再構築して検索に使用できる状態にします。
+//
+// 引数:
+//   - dbFilePath: LadybugDB データベースのパス
+//   - embeddingModelConfig: 埋め込みモデル設定
+//   - logger: ロガー
+//
+// 返り値:
+//   - error: エラーが発生した場合
+func RebuildCubeVectorIndexes(dbFilePath string, embeddingModelConfig types.EmbeddingModelConfig, logger *zap.Logger) error {
+	kagome, err := tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos())
+	if err != nil {
+		return fmt.Errorf("RebuildCubeVectorIndexes: failed to initialize Kagome: %w", err)
+	}
+	ladybugSt, err := ladybugdb.NewLadybugDBStorage(dbFilePath, kagome, logger)
+	if err != nil {
+		return fmt.Errorf("RebuildCubeVectorIndexes: failed to open LadybugDBStorage: %w", err)
+	}
+	defer ladybugSt.Close()
+	// 旧バージョンでエクスポートされた Cube に不足しているテーブル・カラムを補う
+	if err := ladybugSt.EnsureSchema(context.Background(), embeddingModelConfig); err != nil {
+		return fmt.Errorf("RebuildCubeVectorIndexes: failed to apply schema: %w", err)
+	}
+	if err := ladybugSt.RebuildVectorIndexes(context.Background()); err != nil {
+		return fmt.Errorf("RebuildCubeVectorIndexes: %w", err)
+	}
+	utils.LogInfo(logger, "Rebuilt vector indexes of Cube", zap.String("path", dbFilePath))
+	return nil
+}
+
 // getUUIDFromDBFilePath は、CubeのDBファイルパスからUUIDを抽出します。
 // 例: "/path/to/apxID-vdrID-usrID/uuid.db" → "uuid"
 func getUUIDFromDBFilePath(cubeDbFilePath string) string {
@@ -467,9 +666,13 @@ func getUUIDFromDBFilePath(cubeDbFilePath string) string {
 //  3. 知識グラフをLadybugDBに保存
 //  4. 処理済みファイルを自動削除（クリーンアップ）
 //
+// チャンクとチャンクごとに抽出したグラフは Cube の外にチェックポイントとして保存されます。
+// Absorb が失敗（ロールバック）した場合でも、同じ入力で再実行すると完了済みのチャンクから再開します。
+// チェックポイントは Absorb の成功時に削除され、失敗したまま CHECKPOINT_RETENTION_HOURS を過ぎたものは次回の Absorb で削除されます。
+//
 // 使用例:
 //
-//	svc.Absorb(ctx, "path/to/cube.db", "legal_expert", []string{"doc.txt"})
+//	svc.Absorb(ctx, eb, "path/to/cube.db", "legal_expert", []string{"doc.txt"}, "contracts/2024-001", ...)
 //	svc.Query(ctx, "path/to/cube.db", "legal_expert", search.SearchTypeGraphCompletion, "質問")
 //
 // 引数:
@@ -477,6 +680,8 @@ func getUUIDFromDBFilePath(cubeDbFilePath string) string {
 //   - cubeDbFilePath: CubeのDBファイルパス
 //   - memoryGroup: メモリグループ名（LadybugDB内のmemory_groupとして使用される）
 //   - filePaths: 取り込むファイルパスのリスト
+//   - sourceID: 論理的な文書ID（空なら未指定）。同じ sourceID で以前に取り込まれた文書は、
+//     今回の取り込み成功後に、そのチャンク・要約・グラフへの寄与ごと置き換えられる
 //   - cognifyConfig: Cognify設定
 //   - embeddingModelConfig: 埋め込みモデル設定
 //   - chatModelConfig: チャットモデル設定
@@ -490,6 +695,7 @@ func (s *CuberService) Absorb(
 	cubeDbFilePath string,
 	memoryGroup string,
 	filePaths []string,
+	sourceID string,
 	cognifyConfig types.CognifyConfig,
 	embeddingModelConfig types.EmbeddingModelConfig,
 	chatModelConfig types.ChatModelConfig,
@@ -521,19 +727,40 @@ func (s *CuberService) Absorb(
 		})
 		return totalUsage, fmt.Errorf("Absorb: Failed to open storage for cube %s: %w", cubeUUID, err)
 	}
+	ctx, err = s.withMemoryGroupSettings(ctx, st, memoryGroup)
+	if err != nil {
+		eventbus.Emit(eb, string(event.EVENT_ABSORB_ERROR), event.AbsorbErrorPayload{
+			BasePayload: event.NewBasePayload(memoryGroup),
+			Error:       err,
+		})
+		return totalUsage, fmt.Errorf("Absorb: %w", err)
+	}
+
+	// 失敗した Absorb のチェックポイントのうち、保持期間を過ぎたものを削除
+	if pruned, err := checkpoint.PruneExpired(cubeDbFilePath, time.Duration(appconfig.CHECKPOINT_RETENTION_HOURS)*time.Hour); err != nil {
+		utils.LogWarn(s.Logger, "Absorb: Failed to prune expired checkpoints", zap.Error(err))
+	} else if pruned > 0 {
+		utils.LogInfo(s.Logger, "Absorb: Pruned expired checkpoints", zap.Int("count", pruned))
+	}
+	checkpoints := checkpoint.NewStore(cubeDbFilePath, cognifyConfig.ChunkSize, cognifyConfig.ChunkOverlap)
+	var dataList, duplicateList []*storage.Data
 
 	// ========================================
 	// Transaction Start
 	// ========================================
 	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
 		// 1. ファイルの取り込み（add）
-		usage1, err := s.add(txCtx, eb, cubeDbFilePath, memoryGroup, filePaths, embeddingModelConfig)
+		var (
+			usage1 types.TokenUsage
+			err    error
+		)
+		dataList, duplicateList, usage1, err = s.add(txCtx, eb, cubeDbFilePath, memoryGroup, filePaths, sourceID, embeddingModelConfig)
 		if err != nil {
 			return err
 		}
 		totalUsage.Add(usage1)
 		// Create temp embedder
-		embedder, err := s.createTempEmbedder(txCtx, embeddingModelConfig)
+		embedder, err := s.createCachedEmbedder(txCtx, st, embeddingModelConfig)
 		if err != nil {
 			return fmt.Errorf("Absorb: Failed to create embedder: %w", err)
 		}
@@ -543,24 +770,42 @@ func (s *CuberService) Absorb(
 			return fmt.Errorf("Absorb: Failed to create chat model: %w", err)
 		}
 		// 2. 知識グラフの構築（cognify）
-		usage2, err := s.cognify(txCtx, eb, cubeDbFilePath, memoryGroup, cognifyConfig, embeddingModelConfig, embedder, chatModel, chatModelConfig.Model, isEn)
+		usage2, err := s.cognify(txCtx, eb, cubeDbFilePath, memoryGroup, dataList, cognifyConfig, embeddingModelConfig, embedder, chatModel, chatModelConfig.Model, isEn, checkpoints)
 		if err != nil {
 			return err
 		}
 		totalUsage.Add(usage2)
+		// 3. 同じ sourceID を持つ旧版の置き換え
+		if sourceID != "" {
+			if err := s.supersedeSource(txCtx, st, memoryGroup, sourceID, dataList, duplicateList, embeddingModelConfig); err != nil {
+				return err
+			}
+		}
 
 		return nil
 	})
 	if err != nil {
+		emitIfOperationCancelled(ctx, eb, types.ACTION_TYPE_ABSORB, memoryGroup)
 		eventbus.Emit(eb, string(event.EVENT_ABSORB_ERROR), event.AbsorbErrorPayload{
 			BasePayload: event.NewBasePayload(memoryGroup),
 			Error:       err,
 		})
 		return totalUsage, fmt.Errorf("Absorb: Cognify phase failed: %w", err)
 	}
+	// コミット済みのため、チェックポイントは不要
+	for _, data := range dataList {
+		if err := checkpoints.Delete(data.ID); err != nil {
+			utils.LogWarn(s.Logger, "Absorb: Failed to delete checkpoint", zap.String("data_id", data.ID), zap.Error(err))
+		}
+	}
 
 	// ========================================
-	// 3. ストレージのフラッシュ (Checkpoint)
+	// 3. コミュニティの更新
+	// ========================================
+	// グローバル検索で使用するコミュニティと要約を、取り込んだ知識に合わせて更新
+	totalUsage.Add(s.refreshCommunities(ctx, st, memoryGroup, chatModelConfig, isEn))
+	// ========================================
+	// 4. ストレージのフラッシュ (Checkpoint)
 	// ========================================
 	// WALの内容をメインDBにマージし、外部ツールからの可読性を確保
 	if err := st.Vector.Checkpoint(); err != nil {
@@ -591,24 +836,27 @@ func (s *CuberService) Absorb(
 //   - cubeDbFilePath: CubeのDBファイルパス
 //   - memoryGroup: メモリグループ名（memory_groupとして使用）
 //   - filePaths: 取り込むファイルパスのリスト
+//   - sourceID: 論理的な文書ID（空なら未指定）
 //
 // 返り値:
+//   - []*storage.Data: 今回新たに取り込まれたデータのリスト（重複としてスキップされたものは含まない）
+//   - []*storage.Data: 取り込み済みのデータと同じ内容のため、重複としてスキップされたデータのリスト
 //   - types.TokenUsage: トークン使用量
 //   - error: エラーが発生した場合
-func (s *CuberService) add(txCtx context.Context, eb *eventbus.EventBus, cubeDbFilePath string, memoryGroup string, filePaths []string, embeddingModelConfig types.EmbeddingModelConfig) (types.TokenUsage, error) {
+func (s *CuberService) add(txCtx context.Context, eb *eventbus.EventBus, cubeDbFilePath string, memoryGroup string, filePaths []string, sourceID string, embeddingModelConfig types.EmbeddingModelConfig) ([]*storage.Data, []*storage.Data, types.TokenUsage, error) {
 	var usage types.TokenUsage
 	utils.LogDebug(s.Logger, "Add: Processing files", zap.String("group", memoryGroup), zap.Int("count", len(filePaths)))
 	// Storage retrieval
 	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
 	if err != nil {
-		return usage, fmt.Errorf("Add: Failed to get storage: %w", err)
+		return nil, nil, usage, fmt.Errorf("Add: Failed to get storage: %w", err)
 	}
 	// ========================================
 	// 1. タスクの作成
 	// ========================================
 	// IngestTaskを作成
 	// このタスクは、ファイルを読み込んでLadybugDBに保存します
-	ingestTask := ingestion.NewIngestTask(st.Vector, memoryGroup, s.S3Client, s.Logger, eb)
+	ingestTask := ingestion.NewIngestTask(st.Vector, memoryGroup, sourceID, s.S3Client, s.Logger, eb)
 	// ========================================
 	// 2. パイプラインの作成
 	// ========================================
@@ -618,12 +866,26 @@ func (s *CuberService) add(txCtx context.Context, eb *eventbus.EventBus, cubeDbF
 	// 3. パイプラインの実行
 	// ========================================
 	// ファイルパスのリストを入力としてパイプラインを実行
-	_, usage, err = p.Run(txCtx, filePaths)
+	result, usage, err := p.Run(txCtx, filePaths)
 	if err != nil {
-		return usage, fmt.Errorf("Add: Pipeline execution failed: %w", err)
+		return nil, nil, usage, fmt.Errorf("Add: Pipeline execution failed: %w", err)
 	}
-	utils.LogDebug(s.Logger, "Add: Completed", zap.Int64("total_tokens", usage.InputTokens+usage.OutputTokens))
-	return usage, nil
+	ingested, ok := result.([]*storage.Data)
+	if !ok {
+		return nil, nil, usage, fmt.Errorf("Add: Expected []*storage.Data output, got %T", result)
+	}
+	// 重複としてスキップされたデータ（RawDataLocationなし）は処理済みのため、cognify の対象から除外
+	dataList := make([]*storage.Data, 0, len(ingested))
+	var duplicateList []*storage.Data
+	for _, data := range ingested {
+		if data.RawDataLocation != "" {
+			dataList = append(dataList, data)
+		} else {
+			duplicateList = append(duplicateList, data)
+		}
+	}
+	utils.LogDebug(s.Logger, "Add: Completed", zap.Int("ingested", len(dataList)), zap.Int("duplicates", len(duplicateList)), zap.Int64("total_tokens", usage.InputTokens+usage.OutputTokens))
+	return dataList, duplicateList, usage, nil
 }
 
 // cognify は、取り込まれたデータを処理して知識グラフを構築する内部メソッドです。
@@ -645,11 +907,13 @@ func (s *CuberService) add(txCtx context.Context, eb *eventbus.EventBus, cubeDbF
 //   - ctx: コンテキスト
 //   - cubeDbFilePath: CubeのDBファイルパス
 //   - memoryGroup: メモリグループ名（memory_groupとして使用）
+//   - dataList: 処理対象のデータ（add で新たに取り込まれたもの）
 //   - config: CognifyConfig構造体
 //   - embeddingModelConfig: EmbeddingModelConfig構造体
 //   - embedder: Embedderインスタンス
 //   - chatModel: LLMインスタンス
 //   - modelName: モデル名
+//   - checkpoints: チャンク・抽出済みグラフのチェックポイント（nil の場合は保存・復元しない）
 //
 // 返り値:
 //   - types.TokenUsage: トークン使用量
@@ -659,12 +923,14 @@ func (s *CuberService) cognify(
 	eb *eventbus.EventBus,
 	cubeDbFilePath string,
 	memoryGroup string,
+	dataList []*storage.Data,
 	config types.CognifyConfig,
 	embeddingModelConfig types.EmbeddingModelConfig,
 	embedder storage.Embedder,
 	chatModel model.ToolCallingChatModel,
 	modelName string,
 	isEn bool,
+	checkpoints *checkpoint.Store,
 ) (types.TokenUsage, error) {
 	var usage types.TokenUsage
 	utils.LogDebug(s.Logger, "Cognify: Starting pipeline", zap.String("group", memoryGroup), zap.String("model", modelName))
@@ -678,9 +944,16 @@ func (s *CuberService) cognify(
 	// ========================================
 	// ChunkingTask: テキストをconfig.ChunkSize文字のチャンクに分割
 	// config.ChunkOverlap文字のオーバーラップを設定
-	chunkingTask := chunking.NewChunkingTask(config.ChunkSize, config.ChunkOverlap, st.Vector, embedder, s.Kagome, s.S3Client, s.Logger, eb, isEn)
+	chunkingTask := chunking.NewChunkingTask(config.ChunkSize, config.ChunkOverlap, st.Vector, embedder, s.Kagome, s.S3Client, s.Logger, eb, isEn, checkpoints)
 	// GraphExtractionTask: LLMを使用してテキストからエンティティと関係を抽出
-	graphTask := graph.NewGraphExtractionTask(chatModel, modelName, memoryGroup, s.Logger, eb, isEn)
+	// 失敗したチャンクは config.ChunkMaxRetries 回まで再試行し、config.MaxFailedChunkRatio までスキップを許容
+	chunkMaxRetries := config.ChunkMaxRetries
+	if chunkMaxRetries <= 0 {
+		chunkMaxRetries = appconfig.DEFAULT_CHUNK_MAX_RETRIES
+	}
+	graphTask := graph.NewGraphExtractionTask(chatModel, modelName, memoryGroup, s.Logger, eb, isEn, checkpoints, chunkMaxRetries, time.Duration(appconfig.CHUNK_RETRY_BACKOFF_MS)*time.Millisecond, config.MaxFailedChunkRatio)
+	// EntityResolutionTask: 抽出したエンティティを既存のエンティティと照合し、正規ノードに統合
+	resolutionTask := resolution.NewEntityResolutionTask(st.Vector, st.Graph, embedder, memoryGroup, s.Logger)
 	// StorageTask: チャンクとグラフをデータベースに保存
 	storageTask := storageTaskPkg.NewStorageTask(st.Vector, st.Graph, embedder, memoryGroup, s.Logger, eb)
 	// SummarizationTask: チャンクの要約を生成
@@ -688,22 +961,18 @@ func (s *CuberService) cognify(
 	// ========================================
 	// 2. パイプラインの作成
 	// ========================================
-	// 4つのタスクを順番に実行するパイプラインを作成
+	// 5つのタスクを順番に実行するパイプラインを作成
 	p := pipeline.NewPipeline([]pipeline.Task{
 		chunkingTask,      // 1. チャンク化
 		graphTask,         // 2. グラフ抽出
-		storageTask,       // 3. ストレージ
-		summarizationTask, // 4. 要約
+		resolutionTask,    // 3. エンティティ解決
+		storageTask,       // 4. ストレージ
+		summarizationTask, // 5. 要約
 	})
 	// ========================================
-	// 3. 入力データの取得
+	// 3. 入力データの確認
 	// ========================================
-	// メモリーグループでフィルタリングされたデータリストを取得
-	// このデータは Add() で取り込まれたものです
-	dataList, err := st.Vector.GetDataList(txCtx, memoryGroup)
-	if err != nil {
-		return usage, fmt.Errorf("Cognify: Failed to fetch input data: %w", err)
-	}
+	// 既に処理済みのデータを再処理しないよう、add() で新たに取り込まれたデータのみを対象とする
 	// データが存在しない場合は処理をスキップ
 	if len(dataList) == 0 {
 		utils.LogDebug(s.Logger, "Cognify: No data to process", zap.String("group", memoryGroup))
@@ -737,6 +1006,274 @@ func (s *CuberService) cognify(
 	return usage, nil
 }
 
+// DeleteData は、取り込み済みのデータ（1つの文書）を知識ベースから完全に削除します。
+// 個人情報の削除要求などに対応するため、データに由来する全ての情報を取り除きます。
+//
+// 削除対象:
+//   - Data / Document / Chunk ノードと、その間のリレーション（HAS_DOCUMENT, HAS_CHUNK, NEXT_CHUNK）
+//   - チャンクの FTS インデックスエントリ（ノード削除に伴い更新される）
+//   - チャンクの要約（Summary）
+//   - このデータのみを出典とするエッジと、それにより孤立したノード（およびそのEntity embedding）
+//
+// 他の文書でも裏付けられているエッジは削除されず、重みを下げて残ります。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - dataID: 削除するデータのID
+//   - embeddingModelConfig: 埋め込みモデル設定
+//
+// 返り値:
+//   - error: エラーが発生した場合（データが存在しない場合は ErrDataNotFound）
+func (s *CuberService) DeleteData(ctx context.Context, cubeDbFilePath string, memoryGroup string, dataID string, embeddingModelConfig types.EmbeddingModelConfig) error {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return fmt.Errorf("DeleteData: Failed to open storage: %w", err)
+	}
+	data, err := st.Vector.GetDataByID(ctx, dataID, memoryGroup)
+	if err != nil {
+		return fmt.Errorf("DeleteData: Failed to get data: %w", err)
+	}
+	if data == nil {
+		return ErrDataNotFound
+	}
+	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
+		return s.retractData(txCtx, st, memoryGroup, dataID, nil, embeddingModelConfig)
+	})
+	if err != nil {
+		return fmt.Errorf("DeleteData: Failed to delete data %s: %w", dataID, err)
+	}
+	// 未処理のまま残っている元ファイルがあれば削除
+	if data.RawDataLocation != "" {
+		if err := s.S3Client.Del(data.RawDataLocation); err != nil {
+			utils.LogWarn(s.Logger, "DeleteData: Failed to delete file", zap.String("location", data.RawDataLocation), zap.Error(err))
+		}
+	}
+	// WALの内容をメインDBにマージし、削除を確定させる
+	if err := st.Vector.Checkpoint(); err != nil {
+		utils.LogWarn(s.Logger, "DeleteData: Failed to checkpoint storage", zap.Error(err))
+	}
+	utils.LogInfo(s.Logger, "DeleteData: Deleted data", zap.String("data_id", dataID), zap.String("name", data.Name), zap.String("group", memoryGroup))
+	return nil
+}
+
+// supersedeSource は、同じ sourceID で以前に取り込まれた文書（旧版）を取り消します。
+// 今回取り込んだデータ（newDataList）と、内容が変わらず重複としてスキップされたデータ（duplicateList）は対象外です。
+// 重複としてスキップされたデータは現在の版の一部として sourceID を引き継ぎ、そのチャンクに由来する知識もそのまま残します。
+// 新旧両方の版に含まれる事実（新しい版が再度抽出したエッジ）は、新しい版の weight のまま残します。
+//
+// 注意: このメソッドはAbsorbのトランザクション内で、cognify の成功後に呼び出されます。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - st: ストレージセット
+//   - memoryGroup: メモリグループ名
+//   - sourceID: 論理的な文書ID
+//   - newDataList: 今回新たに取り込まれたデータのリスト
+//   - duplicateList: 重複としてスキップされたデータのリスト
+//   - embeddingModelConfig: 埋め込みモデル設定（埋め込みキャッシュの消去に使用）
+//
+// 返り値:
+//   - error: エラーが発生した場合
+func (s *CuberService) supersedeSource(ctx context.Context, st *StorageSet, memoryGroup string, sourceID string, newDataList []*storage.Data, duplicateList []*storage.Data, embeddingModelConfig types.EmbeddingModelConfig) error {
+	existing, err := st.Vector.GetDataBySourceID(ctx, sourceID, memoryGroup)
+	if err != nil {
+		return fmt.Errorf("Supersede: Failed to get data for source %s: %w", sourceID, err)
+	}
+	for _, data := range duplicateList {
+		if err := st.Vector.SetDataSourceID(ctx, data.ID, sourceID, memoryGroup); err != nil {
+			return fmt.Errorf("Supersede: Failed to set source of data %s: %w", data.ID, err)
+		}
+	}
+	current := make(map[string]bool, len(newDataList)+len(duplicateList))
+	var keepChunkIDs []string
+	for _, data := range slices.Concat(newDataList, duplicateList) {
+		current[data.ID] = true
+		chunkIDs, err := st.Vector.GetChunkIDsByDataID(ctx, data.ID, memoryGroup)
+		if err != nil {
+			return fmt.Errorf("Supersede: Failed to get chunks of data %s: %w", data.ID, err)
+		}
+		keepChunkIDs = append(keepChunkIDs, chunkIDs...)
+	}
+	for _, data := range existing {
+		if current[data.ID] {
+			continue
+		}
+		if err := s.retractData(ctx, st, memoryGroup, data.ID, keepChunkIDs, embeddingModelConfig); err != nil {
+			return fmt.Errorf("Supersede: Failed to retract data %s: %w", data.ID, err)
+		}
+		utils.LogInfo(s.Logger, "Supersede: Retracted previous version", zap.String("source_id", sourceID), zap.String("data_id", data.ID), zap.String("name", data.Name))
+	}
+	return nil
+}
+
+// retractData は、取り込み済みのデータを知識ベースから取り消します。
+// この関数は以下の処理を行います：
+//  1. データから生成されたチャンクIDを取得
+//  2. チャンクに由来するエッジを取り消し（他の出典が残るエッジは重みを下げて残す）
+//  3. チャンクの要約と、孤立したノードのEntity embeddingを削除
+//  4. チャンクだけに由来する別名（エンティティ解決で記録されたもの）を削除
+//  5. 取り消したエッジに関わるコミュニティの要約を消去（次回のコミュニティ更新で要約し直される）
+//  6. チャンク・要約・孤立したエンティティ名の埋め込みキャッシュを削除
+//  7. データ・ドキュメント・チャンクを削除
+//
+// 注意: 埋め込みキャッシュのキーは埋め込みモデルごとに異なるため、削除されるのは現在の埋め込みモデルのキャッシュのみです。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - st: ストレージセット
+//   - memoryGroup: メモリグループ名
+//   - dataID: 取り消すデータのID
+//   - keepChunkIDs: 置き換え後の新しい版のチャンクID（新しい版が再度抽出したエッジは弱化しない。削除のみの場合は nil）
+//   - embeddingModelConfig: 埋め込みモデル設定（埋め込みキャッシュのキーの計算に使用）
+//
+// 返り値:
+//   - error: エラーが発生した場合
+func (s *CuberService) retractData(ctx context.Context, st *StorageSet, memoryGroup string, dataID string, keepChunkIDs []string, embeddingModelConfig types.EmbeddingModelConfig) error {
+	chunkIDs, err := st.Vector.GetChunkIDsByDataID(ctx, dataID, memoryGroup)
+	if err != nil {
+		return fmt.Errorf("Retract: Failed to get chunks: %w", err)
+	}
+	summaryIDs := make([]string, 0, len(chunkIDs))
+	for _, chunkID := range chunkIDs {
+		summaryIDs = append(summaryIDs, summarization.GenerateSummaryID(chunkID))
+	}
+	// 埋め込みキャッシュを削除するため、削除前に埋め込みの元になったテキストを取得しておく
+	var embeddedTexts []string
+	for _, target := range []struct {
+		table types.TableName
+		ids   []string
+	}{{types.TABLE_NAME_CHUNK, chunkIDs}, {types.TABLE_NAME_SUMMARY, summaryIDs}} {
+		texts, err := st.Vector.GetEmbeddingTextsByIDs(ctx, target.table, target.ids, memoryGroup)
+		if err != nil {
+			return fmt.Errorf("Retract: Failed to get %s texts: %w", target.table, err)
+		}
+		for _, text := range texts {
+			embeddedTexts = append(embeddedTexts, text)
+		}
+	}
+	res, err := st.Graph.RetractChunks(ctx, chunkIDs, keepChunkIDs, memoryGroup)
+	if err != nil {
+		return fmt.Errorf("Retract: Failed to retract graph: %w", err)
+	}
+	entityTexts, err := st.Vector.GetEmbeddingTextsByIDs(ctx, types.TABLE_NAME_ENTITY, res.OrphanNodeIDs, memoryGroup)
+	if err != nil {
+		return fmt.Errorf("Retract: Failed to get entity texts: %w", err)
+	}
+	for _, text := range entityTexts {
+		embeddedTexts = append(embeddedTexts, text)
+	}
+	if err := st.Vector.DeleteEmbeddings(ctx, types.TABLE_NAME_SUMMARY, summaryIDs, memoryGroup); err != nil {
+		return fmt.Errorf("Retract: Failed to delete summaries: %w", err)
+	}
+	if err := st.Vector.DeleteEmbeddings(ctx, types.TABLE_NAME_ENTITY, res.OrphanNodeIDs, memoryGroup); err != nil {
+		return fmt.Errorf("Retract: Failed to delete entity embeddings: %w", err)
+	}
+	retractedAliases, err := s.retractAliases(ctx, st, memoryGroup, chunkIDs)
+	if err != nil {
+		return err
+	}
+	invalidated, err := s.invalidateCommunities(ctx, st, memoryGroup, res.AffectedNodeIDs, res.OrphanNodeIDs)
+	if err != nil {
+		return err
+	}
+	cacheKeys := make([]string, 0, len(embeddedTexts))
+	for _, text := range embeddedTexts {
+		cacheKeys = append(cacheKeys, embedcache.CacheKey(embeddingModelConfig, text))
+	}
+	if err := st.Vector.DeleteCachedEmbeddings(ctx, cacheKeys); err != nil {
+		return fmt.Errorf("Retract: Failed to delete cached embeddings: %w", err)
+	}
+	if err := st.Vector.DeleteData(ctx, dataID, memoryGroup); err != nil {
+		return fmt.Errorf("Retract: Failed to delete data: %w", err)
+	}
+	utils.LogDebug(s.Logger, "Retract: Completed",
+		zap.String("data_id", dataID),
+		zap.Int("chunks", len(chunkIDs)),
+		zap.Int("deleted_edges", res.DeletedEdges),
+		zap.Int("weakened_edges", res.WeakenedEdges),
+		zap.Int("orphan_nodes", len(res.OrphanNodeIDs)),
+		zap.Int("retracted_aliases", retractedAliases),
+		zap.Int("invalidated_communities", invalidated),
+		zap.Int("cache_keys", len(cacheKeys)))
+	return nil
+}
+
+// retractAliases は、取り消すチャンクだけに由来する別名を、別名の出典を記録したノードから削除します。
+// 返り値は、別名を更新したノードの数です。
+func (s *CuberService) retractAliases(ctx context.Context, st *StorageSet, memoryGroup string, chunkIDs []string) (int, error) {
+	if len(chunkIDs) == 0 {
+		return 0, nil
+	}
+	nodes, err := st.Graph.GetNodesByPropertyText(ctx, chunkIDs, memoryGroup)
+	if err != nil {
+		return 0, fmt.Errorf("Retract: Failed to find nodes with aliases: %w", err)
+	}
+	retracted := make(map[string]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		retracted[id] = true
+	}
+	var updated []*storage.Node
+	for _, node := range nodes {
+		if resolution.RetractAliases(node, retracted) {
+			updated = append(updated, node)
+		}
+	}
+	if err := st.Graph.AddNodes(ctx, updated); err != nil {
+		return 0, fmt.Errorf("Retract: Failed to update aliases: %w", err)
+	}
+	return len(updated), nil
+}
+
+// invalidateCommunities は、取り消したエッジの端点を含むコミュニティのタイトルと要約を消去し、
+// 削除された孤立ノードを所属ノードから除きます。
+// 署名も消去するため、次回のコミュニティ更新（Absorb / Memify の完了時）で以前の要約が再利用されることはありません。
+// 返り値は、要約を消去したコミュニティの数です。
+func (s *CuberService) invalidateCommunities(ctx context.Context, st *StorageSet, memoryGroup string, affectedNodeIDs []string, orphanNodeIDs []string) (int, error) {
+	if len(affectedNodeIDs) == 0 {
+		return 0, nil
+	}
+	communities, err := st.Graph.GetCommunities(ctx, memoryGroup, -1)
+	if err != nil {
+		return 0, fmt.Errorf("Retract: Failed to get communities: %w", err)
+	}
+	affected := make(map[string]bool, len(affectedNodeIDs))
+	for _, id := range affectedNodeIDs {
+		affected[id] = true
+	}
+	orphans := make(map[string]bool, len(orphanNodeIDs))
+	for _, id := range orphanNodeIDs {
+		orphans[id] = true
+	}
+	now := time.Now()
+	invalidated := 0
+	for _, c := range communities {
+		hit := false
+		members := make([]string, 0, len(c.MemberIDs))
+		for _, id := range c.MemberIDs {
+			hit = hit || affected[id]
+			if !orphans[id] {
+				members = append(members, id)
+			}
+		}
+		if !hit {
+			continue
+		}
+		c.Title, c.Summary, c.Signature = "", "", ""
+		c.MemberIDs = members
+		c.UpdatedAt = now
+		invalidated++
+	}
+	if invalidated == 0 {
+		return 0, nil
+	}
+	if err := st.Graph.ReplaceCommunities(ctx, memoryGroup, communities); err != nil {
+		return 0, fmt.Errorf("Retract: Failed to invalidate communities: %w", err)
+	}
+	return invalidated, nil
+}
+
 // Query は、クエリ（質問）に基づいて知識グラフを検索し、回答を生成します。
 //
 // クエリタイプに応じて、以下の処理が行われます：
@@ -755,6 +1292,7 @@ func (s *CuberService) cognify(
 //
 // 返り値:
 //   - string: クエリ結果
+//   - citations: 回答の根拠となったチャンクの出典（ファイル名・チャンク順序）
 //   - types.TokenUsage: トークン使用量
 //   - error: エラーが発生した場合
 func (s *CuberService) Query(
@@ -768,7 +1306,7 @@ func (s *CuberService) Query(
 	chatModelConfig types.ChatModelConfig,
 	dataCh chan<- event.StreamEvent,
 	isEn bool,
-) (answer *string, chunks *string, summaries *string, graph *[]*storage.Triple, embedding *[]float32, usage types.TokenUsage, err error) {
+) (answer *string, chunks *string, summaries *string, graph *[]*storage.Triple, citations *[]*storage.Citation, embedding *[]float32, usage types.TokenUsage, err error) {
 	// Register Events
 	if dataCh != nil {
 		event.RegisterQueryStreamer(eb, dataCh)
@@ -776,7 +1314,11 @@ func (s *CuberService) Query(
 	}
 	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
 	if err != nil {
-		return nil, nil, nil, nil, nil, types.TokenUsage{}, fmt.Errorf("Query: Failed to get storage: %w", err)
+		return nil, nil, nil, nil, nil, nil, types.TokenUsage{}, fmt.Errorf("Query: Failed to get storage: %w", err)
+	}
+	ctx, err = s.withMemoryGroupSettings(ctx, st, memoryGroup)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, types.TokenUsage{}, fmt.Errorf("Query: %w", err)
 	}
 	utils.LogDebug(s.Logger, "Query: Executing", zap.String("cube", getUUIDFromDBFilePath(cubeDbFilePath)), zap.String("text", text))
 	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
@@ -784,7 +1326,7 @@ func (s *CuberService) Query(
 		// 1. 検索ツールの作成
 		// ========================================
 		// Create temp embedder
-		embedder, err := s.createTempEmbedder(txCtx, embeddingModelConfig)
+		embedder, err := s.createCachedEmbedder(txCtx, st, embeddingModelConfig)
 		if err != nil {
 			return fmt.Errorf("Query: Failed to create embedder: %w", err)
 		}
@@ -803,11 +1345,141 @@ func (s *CuberService) Query(
 		// Inject language setting into queryConfig
 		queryConfig.IsEn = isEn
 		var qusage types.TokenUsage
-		answer, chunks, summaries, graph, embedding, qusage, err = queryTool.Query(txCtx, text, queryConfig)
+		answer, chunks, summaries, graph, citations, embedding, qusage, err = queryTool.Query(txCtx, text, queryConfig)
 		usage.Add(qusage)
 		return err
 	})
-	return answer, chunks, summaries, graph, embedding, usage, err
+	if err != nil {
+		emitIfOperationCancelled(ctx, eb, types.ACTION_TYPE_QUERY, memoryGroup)
+	}
+	return answer, chunks, summaries, graph, citations, embedding, usage, err
+}
+
+// CypherQuery は、利用者が記述した読み取り専用の Cypher クエリを実行し、表形式の結果を返します (QUERY_TYPE_CYCLER)。
+// クエリは tools/cypher により検証され、MATCH 句のパターンには memory_group の条件が注入されるため、
+// 他のメモリーグループのデータは参照できません。
+// 行数は CYPHER_QUERY_MAX_ROWS、実行時間は CYPHER_QUERY_TIMEOUT_MS で制限されます。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名（クエリのスコープ）
+//   - cypherText: Cypher クエリ
+//   - embeddingModelConfig: エンベディングモデル設定（ストレージのオープンに使用）
+//
+// 返り値:
+//   - *storage.CypherResult: 実行結果
+//   - error: エラーが発生した場合（検証に失敗した場合は ErrInvalidCypherQuery をラップ）
+func (s *CuberService) CypherQuery(ctx context.Context, cubeDbFilePath string, memoryGroup string, cypherText string, embeddingModelConfig types.EmbeddingModelConfig) (*storage.CypherResult, error) {
+	scoped, err := cypher.ScopeReadOnlyQuery(cypherText, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCypherQuery, err)
+	}
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("CypherQuery: Failed to get storage: %w", err)
+	}
+	utils.LogDebug(s.Logger, "CypherQuery: Executing", zap.String("cube", getUUIDFromDBFilePath(cubeDbFilePath)), zap.String("query", scoped))
+	result, err := st.Graph.ExecuteReadOnlyQuery(ctx, scoped, appconfig.CYPHER_QUERY_MAX_ROWS, appconfig.CYPHER_QUERY_TIMEOUT_MS)
+	if err != nil {
+		return nil, fmt.Errorf("CypherQuery: %w", err)
+	}
+	return result, nil
+}
+
+// FindPaths は、2つのエンティティ間の経路を、ホップ数の少ない順に最大 pathQuery.K 件取得します。
+// chatModelConfig が指定された場合は、経路をチャットモデルで自然な文章として説明します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - pathQuery: 経路検索の条件（SourceID, TargetID は utils.NormalizeForGraph で正規化済みであること）
+//   - embeddingModelConfig: エンベディングモデル設定（ストレージのオープンに使用）
+//   - chatModelConfig: チャットモデル設定（nil の場合は説明を生成しない）
+//   - isEn: true の場合は英語、false の場合は日本語で説明する
+//
+// 返り値:
+//   - paths: 経路
+//   - narration: 経路の説明（chatModelConfig が nil の場合、または経路がない場合は nil）
+//   - usage: トークン使用量
+//   - err: エラーが発生した場合
+func (s *CuberService) FindPaths(
+	ctx context.Context,
+	cubeDbFilePath string,
+	memoryGroup string,
+	pathQuery storage.PathQuery,
+	embeddingModelConfig types.EmbeddingModelConfig,
+	chatModelConfig *types.ChatModelConfig,
+	isEn bool,
+) (paths []*storage.Path, narration *string, usage types.TokenUsage, err error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, nil, usage, fmt.Errorf("FindPaths: Failed to get storage: %w", err)
+	}
+	paths, err = st.Graph.FindPaths(ctx, pathQuery, memoryGroup)
+	if err != nil {
+		return nil, nil, usage, fmt.Errorf("FindPaths: %w", err)
+	}
+	utils.LogDebug(s.Logger, "FindPaths: Found paths", zap.String("cube", getUUIDFromDBFilePath(cubeDbFilePath)),
+		zap.String("source", pathQuery.SourceID), zap.String("target", pathQuery.TargetID), zap.Int("paths", len(paths)))
+	if chatModelConfig == nil || len(paths) == 0 {
+		return paths, nil, usage, nil
+	}
+	ctx, err = s.withMemoryGroupSettings(ctx, st, memoryGroup)
+	if err != nil {
+		return nil, nil, usage, fmt.Errorf("FindPaths: %w", err)
+	}
+	chatModel, err := s.createTempChatModel(ctx, *chatModelConfig)
+	if err != nil {
+		return nil, nil, usage, fmt.Errorf("FindPaths: Failed to create chat model: %w", err)
+	}
+	queryTool := query.NewGraphCompletionTool(st.Vector, st.Graph, chatModel, nil, s.Kagome, memoryGroup, chatModelConfig.Model, s.Logger, nil)
+	text, usage, err := queryTool.NarratePaths(ctx, pathQuery.SourceID, pathQuery.TargetID, paths, isEn)
+	if err != nil {
+		return nil, nil, usage, fmt.Errorf("FindPaths: %w", err)
+	}
+	return paths, &text, usage, nil
+}
+
+// Feedback は、クエリの回答に対するユーザーのフィードバックを、回答の根拠として使用されたエッジに反映します。
+// 肯定的なフィードバックは GraphMetabolismAlpha で強化し、否定的なフィードバックは GraphMetabolismDelta で弱化します。
+// 弱化により生存スコア S = W × C が GraphMetabolismPruneThreshold を下回ったエッジは削除されます。
+// 訂正文の取り込みは Absorb で行います（このメソッドはエッジの代謝のみを扱います）。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - edges: 回答の根拠として使用されたエッジ（SourceID, Type, TargetID のみ使用）
+//   - positive: true の場合は強化、false の場合は弱化
+//   - embeddingModelConfig: 埋め込みモデル設定（ストレージのオープンに使用）
+//
+// 返り値:
+//   - metacognition.FeedbackResult: 適用結果
+//   - error: エラーが発生した場合
+func (s *CuberService) Feedback(ctx context.Context, cubeDbFilePath string, memoryGroup string, edges []*storage.Edge, positive bool, embeddingModelConfig types.EmbeddingModelConfig) (metacognition.FeedbackResult, error) {
+	var result metacognition.FeedbackResult
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return result, fmt.Errorf("Feedback: Failed to open storage: %w", err)
+	}
+	feedbackTask := metacognition.NewFeedbackTask(st.Graph, memoryGroup,
+		s.Config.GraphMetabolismAlpha, s.Config.GraphMetabolismDelta, s.Config.GraphMetabolismPruneThreshold, s.Logger)
+	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
+		var txErr error
+		result, txErr = feedbackTask.ApplyFeedback(txCtx, edges, positive)
+		return txErr
+	})
+	if err != nil {
+		return result, fmt.Errorf("Feedback: Failed to apply feedback: %w", err)
+	}
+	if err := st.Vector.Checkpoint(); err != nil {
+		utils.LogWarn(s.Logger, "Feedback: Failed to checkpoint storage", zap.Error(err))
+	}
+	utils.LogInfo(s.Logger, "Feedback: Applied feedback", zap.String("group", memoryGroup), zap.Bool("positive", positive),
+		zap.Int("strengthened", result.Strengthened), zap.Int("weakened", result.Weakened), zap.Int("pruned", result.Pruned), zap.Int("missing", result.Missing))
+	return result, nil
 }
 
 // Memify は、既存の知識グラフに対して強化処理を適用します。
@@ -857,6 +1529,7 @@ func (s *CuberService) Memify(
 
 	defer func() {
 		if err != nil {
+			emitIfOperationCancelled(ctx, eb, types.ACTION_TYPE_MEMIFY, memoryGroup)
 			eventbus.Emit(eb, string(event.EVENT_MEMIFY_ERROR), event.MemifyErrorPayload{
 				BasePayload: event.NewBasePayload(memoryGroup),
 				Error:       err,
@@ -874,6 +1547,10 @@ func (s *CuberService) Memify(
 	if err != nil {
 		return totalUsage, fmt.Errorf("Memify: Failed to get storage: %w", err)
 	}
+	ctx, err = s.withMemoryGroupSettings(ctx, st, memoryGroup)
+	if err != nil {
+		return totalUsage, fmt.Errorf("Memify: %w", err)
+	}
 
 	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
 		// Create temp chat model
@@ -885,7 +1562,7 @@ func (s *CuberService) Memify(
 		// Phase A: Unknown解決フェーズ (Priority High)
 		// ========================================
 		// Create temp embedder for Memify
-		embedder, err := s.createTempEmbedder(txCtx, embeddingModelConfig)
+		embedder, err := s.createCachedEmbedder(txCtx, st, embeddingModelConfig)
 		if err != nil {
 			return fmt.Errorf("Memify: Failed to create embedder: %w", err)
 		}
@@ -918,6 +1595,10 @@ func (s *CuberService) Memify(
 				utils.LogWarn(s.Logger, "Failed to get unresolved unknowns", zap.Error(err))
 			} else {
 				for _, unknown := range unknowns {
+					// キャンセルされた場合は残りの Unknown を処理せずに終了する（トランザクションはロールバックされる）
+					if txCtx.Err() != nil {
+						return context.Cause(txCtx)
+					}
 					// Emit Unknown Item Start
 					eventbus.Emit(eb, string(event.EVENT_MEMIFY_UNKNOWN_ITEM_START), event.MemifyUnknownItemStartPayload{
 						BasePayload: event.NewBasePayload(memoryGroup),
@@ -947,6 +1628,9 @@ func (s *CuberService) Memify(
 		// 再帰的にコアロジックを実行
 		// RecursiveDepth=0 の場合は1回のみ実行 (level 0 <= 0 for 1 iteration)
 		for level := 0; level <= memifyConfig.RecursiveDepth; level++ {
+			if txCtx.Err() != nil {
+				return context.Cause(txCtx)
+			}
 			utils.LogDebug(s.Logger, "Memify: Recursive Level", zap.Int("level", level), zap.Int("max_depth", memifyConfig.RecursiveDepth))
 
 			// Emit Expansion Loop Start
@@ -997,8 +1681,30 @@ func (s *CuberService) Memify(
 
 		return nil
 	})
+	if err != nil {
+		return totalUsage, err
+	}
+	// グローバル検索で使用するコミュニティと要約を、洗練された知識に合わせて更新
+	totalUsage.Add(s.refreshCommunities(ctx, st, memoryGroup, chatModelConfig, isEn))
+	return totalUsage, nil
+}
 
-	return totalUsage, err
+// refreshCommunities は、メモリーグループのコミュニティを検出し直し、要約を更新します。
+// Absorb / Memify の完了後に呼び出されます。知識の取り込み自体は完了しているため、
+// 失敗してもログを出力するだけでエラーは返しません（次回の更新で再試行されます）。
+func (s *CuberService) refreshCommunities(ctx context.Context, st *StorageSet, memoryGroup string, chatModelConfig types.ChatModelConfig, isEn bool) types.TokenUsage {
+	var usage types.TokenUsage
+	chatModel, err := s.createTempChatModel(ctx, chatModelConfig)
+	if err != nil {
+		utils.LogWarn(s.Logger, "RefreshCommunities: Failed to create chat model", zap.Error(err))
+		return usage
+	}
+	task := community.NewCommunityTask(st.Graph, chatModel, chatModelConfig.Model, memoryGroup, isEn, s.Logger)
+	_, _, usage, err = task.Run(ctx)
+	if err != nil {
+		utils.LogWarn(s.Logger, "RefreshCommunities: Failed to refresh communities", zap.String("group", memoryGroup), zap.Error(err))
+	}
+	return usage
 }
 
 // memifyBulkProcess は、全テキストを一括で処理します。
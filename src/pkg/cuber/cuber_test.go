@@ -0,0 +1,216 @@
+package cuber
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+// TestAbsorbSupersedeKeepsUnchangedFiles は、同じ source_id で文書を取り込み直したとき、
+// 内容が変わったファイルの旧版だけが取り消され、変わらなかったファイルの知識は残ることを確認します。
+func TestAbsorbSupersedeKeepsUnchangedFiles(t *testing.T) {
+	registerTestMockFixtures(t)
+	c := newTestCube(t)
+	ctx := context.Background()
+	const sourceID = "handbook"
+	unchanged := c.writeFile(t, "policy.txt", "Alice works at Acme Corporation.")
+	changed := c.writeFile(t, "team.txt", "Acme Corporation has 10 engineers.")
+	c.absorb(t, []string{unchanged, changed}, sourceID)
+	// source_id なしで取り込んだファイルも、同じ内容のまま文書に加えれば source_id を引き継ぐ
+	added := c.writeFile(t, "faq.txt", "Alice answers questions about Acme Corporation.")
+	c.absorb(t, []string{added}, "")
+
+	st := c.storage(t)
+	before, err := st.Vector.GetDataBySourceID(ctx, sourceID, testMockMemoryGroup)
+	if err != nil {
+		t.Fatalf("GetDataBySourceID failed: %v", err)
+	}
+	if len(before) != 2 {
+		t.Fatalf("Expected 2 data before superseding, got %d", len(before))
+	}
+	ids := map[string]string{} // ファイル名 -> データID
+	for _, data := range before {
+		ids[data.Name] = data.ID
+	}
+	all, err := st.Vector.GetDataList(ctx, testMockMemoryGroup)
+	if err != nil {
+		t.Fatalf("GetDataList failed: %v", err)
+	}
+	for _, data := range all {
+		if data.Name == "faq.txt" {
+			ids[data.Name] = data.ID
+		}
+	}
+	unchangedChunkIDs, err := st.Vector.GetChunkIDsByDataID(ctx, ids["policy.txt"], testMockMemoryGroup)
+	if err != nil || len(unchangedChunkIDs) == 0 {
+		t.Fatalf("Expected chunks of the unchanged file, got %v (%v)", unchangedChunkIDs, err)
+	}
+
+	countNodes := func() int {
+		t.Helper()
+		nodes, err := c.s.CypherQuery(ctx, c.cubeDbFilePath, testMockMemoryGroup, "MATCH (n:GraphNode) RETURN n.id", c.embeddingConfig)
+		if err != nil {
+			t.Fatalf("CypherQuery failed: %v", err)
+		}
+		return len(nodes.Rows)
+	}
+	nodeCount := countNodes()
+	if nodeCount < 2 {
+		t.Fatalf("Expected the extracted entities to be stored, got %d nodes", nodeCount)
+	}
+
+	// 1ファイルだけ内容を変えて、同じ source_id で取り込み直す
+	c.writeFile(t, "team.txt", "Acme Corporation has 12 engineers.")
+	c.absorb(t, []string{unchanged, changed, added}, sourceID)
+
+	after, err := st.Vector.GetDataBySourceID(ctx, sourceID, testMockMemoryGroup)
+	if err != nil {
+		t.Fatalf("GetDataBySourceID failed: %v", err)
+	}
+	if len(after) != 3 {
+		t.Fatalf("Expected 3 data after superseding, got %d", len(after))
+	}
+	for _, data := range after {
+		switch data.Name {
+		case "policy.txt", "faq.txt":
+			if data.ID != ids[data.Name] {
+				t.Errorf("Expected the unchanged file %s to keep its data %s, got %s", data.Name, ids[data.Name], data.ID)
+			}
+		case "team.txt":
+			if data.ID == ids["team.txt"] {
+				t.Errorf("Expected the previous version of the changed file to be replaced")
+			}
+		default:
+			t.Errorf("Unexpected data: %s", data.Name)
+		}
+	}
+	if old, err := st.Vector.GetDataByID(ctx, ids["team.txt"], testMockMemoryGroup); err != nil || old != nil {
+		t.Errorf("Expected the previous version to be deleted, got %v (%v)", old, err)
+	}
+	// 変わらなかったファイルのチャンクと、そこから抽出したエンティティは残る
+	chunkIDs, err := st.Vector.GetChunkIDsByDataID(ctx, ids["policy.txt"], testMockMemoryGroup)
+	if err != nil || len(chunkIDs) != len(unchangedChunkIDs) {
+		t.Errorf("Expected the chunks of the unchanged file to be kept, got %v (%v)", chunkIDs, err)
+	}
+	if got := countNodes(); got != nodeCount {
+		t.Errorf("Expected the entities to be kept, got %d nodes, want %d", got, nodeCount)
+	}
+}
+
+// TestDeleteDataRetractsKnowledge は、データを削除したとき、他のデータでも裏付けられているエンティティは残り、
+// 取り消したエッジに関わるコミュニティの要約が消去されることを確認します。
+func TestDeleteDataRetractsKnowledge(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string // 取り込むファイル名 -> 内容
+		deleteName  string
+		wantNodes   int
+		wantMembers int // 要約を消去したコミュニティに残る所属ノード数
+	}{
+		{
+			name: "entities shared with remaining data survive",
+			files: map[string]string{
+				"policy.txt": "Alice works at Acme Corporation.",
+				"team.txt":   "Alice joined Acme Corporation in 2020.",
+			},
+			deleteName:  "team.txt",
+			wantNodes:   2,
+			wantMembers: 3,
+		},
+		{
+			name: "entities only in deleted data are removed",
+			files: map[string]string{
+				"policy.txt": "Alice works at Acme Corporation.",
+			},
+			deleteName:  "policy.txt",
+			wantNodes:   0,
+			wantMembers: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerTestMockFixtures(t)
+			c := newTestCube(t)
+			ctx := context.Background()
+			var paths []string
+			for name, content := range tt.files {
+				paths = append(paths, c.writeFile(t, name, content))
+			}
+			c.absorb(t, paths, "")
+			st := c.storage(t)
+			nodeIDs := func() []string {
+				t.Helper()
+				res, err := c.s.CypherQuery(ctx, c.cubeDbFilePath, testMockMemoryGroup, "MATCH (n:GraphNode) RETURN n.id", c.embeddingConfig)
+				if err != nil {
+					t.Fatalf("CypherQuery failed: %v", err)
+				}
+				ids := make([]string, 0, len(res.Rows))
+				for _, row := range res.Rows {
+					ids = append(ids, fmt.Sprint(row[0]))
+				}
+				return ids
+			}
+			before := nodeIDs()
+			if len(before) != 2 {
+				t.Fatalf("Expected the extracted entities to be stored, got %v", before)
+			}
+			// 抽出したエンティティを含むコミュニティと、無関係なコミュニティを要約済みの状態で用意する
+			communities := []*storage.Community{
+				{ID: "c-affected", MemoryGroup: testMockMemoryGroup, Title: "Acme", Summary: "Alice works at Acme.", Signature: "sig-affected", MemberIDs: append(slices.Clone(before), "other")},
+				{ID: "c-unrelated", MemoryGroup: testMockMemoryGroup, Title: "Other", Summary: "Unrelated.", Signature: "sig-unrelated", MemberIDs: []string{"x", "y", "z"}},
+			}
+			if err := st.Graph.ReplaceCommunities(ctx, testMockMemoryGroup, communities); err != nil {
+				t.Fatalf("ReplaceCommunities failed: %v", err)
+			}
+			all, err := st.Vector.GetDataList(ctx, testMockMemoryGroup)
+			if err != nil {
+				t.Fatalf("GetDataList failed: %v", err)
+			}
+			var deleteID string
+			for _, data := range all {
+				if data.Name == tt.deleteName {
+					deleteID = data.ID
+				}
+			}
+			if err := c.s.DeleteData(ctx, c.cubeDbFilePath, testMockMemoryGroup, deleteID, c.embeddingConfig); err != nil {
+				t.Fatalf("DeleteData failed: %v", err)
+			}
+
+			if got := nodeIDs(); len(got) != tt.wantNodes {
+				t.Errorf("Expected %d nodes after deletion, got %v", tt.wantNodes, got)
+			}
+			remaining, err := st.Vector.GetDataList(ctx, testMockMemoryGroup)
+			if err != nil {
+				t.Fatalf("GetDataList failed: %v", err)
+			}
+			if len(remaining) != len(tt.files)-1 {
+				t.Errorf("Expected %d data after deletion, got %d", len(tt.files)-1, len(remaining))
+			}
+			after, err := st.Graph.GetCommunities(ctx, testMockMemoryGroup, -1)
+			if err != nil {
+				t.Fatalf("GetCommunities failed: %v", err)
+			}
+			for _, community := range after {
+				switch community.ID {
+				case "c-affected":
+					if community.Title != "" || community.Summary != "" || community.Signature != "" {
+						t.Errorf("Expected the affected community to be invalidated, got %+v", community)
+					}
+					if len(community.MemberIDs) != tt.wantMembers {
+						t.Errorf("Expected %d members in the affected community, got %v", tt.wantMembers, community.MemberIDs)
+					}
+				case "c-unrelated":
+					if community.Summary != "Unrelated." || community.Signature != "sig-unrelated" {
+						t.Errorf("Expected the unrelated community to be kept, got %+v", community)
+					}
+				}
+			}
+			if len(after) != 2 {
+				t.Errorf("Expected 2 communities, got %d", len(after))
+			}
+		})
+	}
+}
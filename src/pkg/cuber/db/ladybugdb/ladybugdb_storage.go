@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ikawaha/kagome/v2/tokenizer"
+	appconfig "github.com/t-kawata/mycute/config"
 	"github.com/t-kawata/mycute/lib/common"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
@@ -22,14 +24,29 @@ type contextKey string
 
 const TX_CONN_KEY contextKey = "TX_CONN"
 
+// TX_ROLLBACK_KEY は、トランザクションのロールバック時に実行する関数（*[]func()）を保持するコンテキストキーです。
+const TX_ROLLBACK_KEY contextKey = "TX_ROLLBACK"
+
 // LadybugDBStorage は、LadybugDBを使用した統合ストレージ実装です。
 // VectorStorage と GraphStorage の両インターフェースを実装します。
 type LadybugDBStorage struct {
-	db     *ladybug.Database
-	conn   *ladybug.Connection  // デフォルト接続（非トランザクション用）
-	kagome *tokenizer.Tokenizer // 日本語形態素解析器（Kagome）- FTS用
-	Logger *zap.Logger
-	mu     sync.Mutex // トランザクションのシリアライズ用
+	db              *ladybug.Database
+	conn            *ladybug.Connection  // デフォルト接続（非トランザクション用）
+	kagome          *tokenizer.Tokenizer // 日本語形態素解析器（Kagome）- FTS用
+	Logger          *zap.Logger
+	mu              sync.Mutex               // トランザクションのシリアライズ用
+	VectorSearchEfs int                      // ベクトルインデックス検索の探索幅 efs（大きいほど再現率が上がり、遅くなる）
+	vectorIndexes   map[types.TableName]bool // ベクトルインデックスが利用可能なテーブル
+	vectorIndexMu   sync.RWMutex             // vectorIndexes の保護用
+}
+
+// VECTOR_INDEXED_TABLES は、ベクトルインデックス (HNSW) を作成する埋め込みテーブルです。
+// Unknown / Capability は件数が少ないため、全件走査で検索します。
+var VECTOR_INDEXED_TABLES = []types.TableName{
+	types.TABLE_NAME_CHUNK,
+	types.TABLE_NAME_ENTITY,
+	types.TABLE_NAME_SUMMARY,
+	types.TABLE_NAME_RULE,
 }
 
 // コンパイル時チェック: インターフェースを満たしているか確認
@@ -58,28 +75,32 @@ func NewLadybugDBStorage(dbPath string, kagome *tokenizer.Tokenizer, l *zap.Logg
 		return nil, fmt.Errorf("Failed to open LadybugDB connection: %w", err)
 	}
 
-	// FTS拡張のインストールとロード
-	// これにより create_fts_index, query_fts_index 等の関数が利用可能になります
+	// FTS拡張・ベクトル拡張のインストールとロード
+	// これにより create_fts_index, query_fts_index, CREATE_VECTOR_INDEX, QUERY_VECTOR_INDEX 等の関数が利用可能になります
 	// LadybugDB/KuzuDBでは大文字小文字両方で試行
-	ftsCommands := []string{
+	extensionCommands := []string{
 		"INSTALL fts",
 		"LOAD EXTENSION fts",
+		"INSTALL vector",
+		"LOAD EXTENSION vector",
 	}
-	for _, cmd := range ftsCommands {
+	for _, cmd := range extensionCommands {
 		if result, err := conn.Query(cmd); err != nil {
-			utils.LogWarn(l, fmt.Sprintf("LadybugDB: FTS command failed: %s", cmd), zap.Error(err))
+			utils.LogWarn(l, fmt.Sprintf("LadybugDB: Extension command failed: %s", cmd), zap.Error(err))
 			// 拡張が見つからない場合や既にロード済みの場合があるので、エラーは無視
 		} else {
 			result.Close()
-			utils.LogDebug(l, fmt.Sprintf("LadybugDB: FTS command succeeded: %s", cmd))
+			utils.LogDebug(l, fmt.Sprintf("LadybugDB: Extension command succeeded: %s", cmd))
 		}
 	}
 
 	return &LadybugDBStorage{
-		db:     db,
-		conn:   conn,
-		kagome: kagome,
-		Logger: l,
+		db:              db,
+		conn:            conn,
+		kagome:          kagome,
+		Logger:          l,
+		VectorSearchEfs: appconfig.DEFAULT_VECTOR_SEARCH_EFS,
+		vectorIndexes:   make(map[types.TableName]bool),
 	}, nil
 }
 
@@ -139,8 +160,9 @@ func (s *LadybugDBStorage) Transaction(ctx context.Context, fn func(txCtx contex
 		result.Close()
 	}
 
-	// 3. 接続を Context に埋め込んで実行
-	txCtx := context.WithValue(ctx, TX_CONN_KEY, conn)
+	// 3. 接続とロールバック時の処理を Context に埋め込んで実行
+	var rollbackHooks []func()
+	txCtx := context.WithValue(context.WithValue(ctx, TX_CONN_KEY, conn), TX_ROLLBACK_KEY, &rollbackHooks)
 
 	// パニック復旧のための無名関数
 	err = func() (err error) {
@@ -152,16 +174,23 @@ func (s *LadybugDBStorage) Transaction(ctx context.Context, fn func(txCtx contex
 		return fn(txCtx)
 	}()
 
+	// fn が正常に終了していても、ctx がキャンセル済み（オペレーションのキャンセルなど）であればコミットしない
+	if err == nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+
 	if err != nil {
 		// ロールバック
 		if res, rerr := conn.Query("ROLLBACK"); rerr == nil {
 			res.Close()
 		}
+		runRollbackHooks(rollbackHooks)
 		return err
 	}
 
 	// 4. コミット
 	if result, err := conn.Query("COMMIT"); err != nil {
+		runRollbackHooks(rollbackHooks)
 		return fmt.Errorf("LadybugDB: Commit failed: %w", err)
 	} else {
 		result.Close()
@@ -169,6 +198,21 @@ func (s *LadybugDBStorage) Transaction(ctx context.Context, fn func(txCtx contex
 	return nil
 }
 
+// onRollback は、トランザクション内で変更したメモリ上の状態を、ロールバック時に元に戻す関数を登録します。
+// トランザクション外で呼ばれた場合は何もしません。
+func onRollback(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(TX_ROLLBACK_KEY).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+	}
+}
+
+// runRollbackHooks は、登録された関数を登録と逆の順に実行します。
+func runRollbackHooks(hooks []func()) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
 func (s *LadybugDBStorage) Checkpoint() error {
 	if s.conn != nil {
 		s.mu.Lock()
@@ -210,6 +254,7 @@ func (s *LadybugDBStorage) EnsureSchema(ctx context.Context, config types.Embedd
 			extension STRING,
 			mime_type STRING,
 			content_hash STRING,
+			source_id STRING,
 			owner_id STRING,
 			created_at TIMESTAMP,
 			PRIMARY KEY (id)
@@ -285,6 +330,58 @@ func (s *LadybugDBStorage) EnsureSchema(ctx context.Context, config types.Embedd
 			embedding %s,
 			PRIMARY KEY (id)
 		)`, vectorType),
+		// EdgeProvenance: エッジの出典（どのチャンクから抽出されたか）
+		// GraphEdge はリレーションのため、出典はエッジのキー（source_id, type, target_id）で参照する
+		`CREATE NODE TABLE EdgeProvenance (
+			id STRING,
+			memory_group STRING,
+			source_id STRING,
+			target_id STRING,
+			type STRING,
+			chunk_id STRING,
+			document_id STRING,
+			PRIMARY KEY (id)
+		)`,
+		// EmbeddingCache: 埋め込みキャッシュ（メモリーグループをまたいで共有）
+		// キーに埋め込みモデルと次元数を含むため、embedding は次元数を固定しない FLOAT[] とする
+		`CREATE NODE TABLE EmbeddingCache (
+			id STRING,
+			embedding FLOAT[],
+			created_at TIMESTAMP,
+			PRIMARY KEY (id)
+		)`,
+		// PromptOverride: メモリーグループごとのプロンプトの上書き（バージョンごとに1行）
+		`CREATE NODE TABLE PromptOverride (
+			id STRING,
+			memory_group STRING,
+			name STRING,
+			version INT64,
+			content STRING,
+			is_active BOOLEAN,
+			created_at TIMESTAMP,
+			PRIMARY KEY (id)
+		)`,
+		// Ontology: メモリーグループごとのオントロジー定義（JSON）
+		`CREATE NODE TABLE Ontology (
+			id STRING,
+			definition STRING,
+			updated_at TIMESTAMP,
+			PRIMARY KEY (id)
+		)`,
+		// Community: メモリーグループごとのコミュニティと要約（更新のたびに置き換えられる）
+		`CREATE NODE TABLE Community (
+			id STRING,
+			memory_group STRING,
+			level INT64,
+			parent_id STRING,
+			title STRING,
+			summary STRING,
+			member_ids STRING,
+			weight DOUBLE,
+			signature STRING,
+			updated_at TIMESTAMP,
+			PRIMARY KEY (id)
+		)`,
 		// MemoryGroup: メモリーグループごとの代謝パラメータ
 		`CREATE NODE TABLE MemoryGroup (
 			id STRING,
@@ -337,7 +434,18 @@ func (s *LadybugDBStorage) EnsureSchema(ctx context.Context, config types.Embedd
 			return err
 		}
 	}
-	// 3. FTS Indexes (Full-Text Search)
+	// 3. Column Migrations
+	// ---------------------------------------------------------
+	// 既存の Cube に対して、後から追加されたカラムを追加します。
+	columnMigrations := []string{
+		`ALTER TABLE Data ADD IF NOT EXISTS source_id STRING DEFAULT ''`,
+	}
+	for _, query := range columnMigrations {
+		if err := s.createTable(ctx, query); err != nil {
+			return err
+		}
+	}
+	// 4. FTS Indexes (Full-Text Search)
 	// ---------------------------------------------------------
 	// LadybugDB v0.11.3+ では FTS 拡張がプリインストールされています。
 	// 各レイヤーのキーワードカラムに対して BM25 ベースの FTS インデックスを作成します。
@@ -356,10 +464,260 @@ func (s *LadybugDBStorage) EnsureSchema(ctx context.Context, config types.Embedd
 			// FTS インデックス作成失敗はスキーマ全体の失敗にはしない
 		}
 	}
+	// 5. Vector Indexes (HNSW)
+	// ---------------------------------------------------------
+	// 埋め込みテーブルの embedding カラムに対してコサイン距離の HNSW インデックスを作成します。
+	// 作成済みの場合はそのまま利用します。作成に失敗したテーブルは Query で全件走査にフォールバックします。
+	for _, tableName := range VECTOR_INDEXED_TABLES {
+		if err := s.createVectorIndex(ctx, tableName); err != nil {
+			utils.LogWarn(s.Logger, fmt.Sprintf("Vector index creation skipped or failed: %v", err))
+			// ベクトルインデックス作成失敗はスキーマ全体の失敗にはしない
+			continue
+		}
+		s.setVectorIndexReady(tableName, true)
+	}
 	utils.LogDebug(s.Logger, "LadybugDB: Schema creation completed")
 	return nil
 }
 
+// vectorIndexName は、テーブルのベクトルインデックス名を返します（例: "Chunk" → "chunk_embedding_idx"）。
+func vectorIndexName(tableName types.TableName) string {
+	return strings.ToLower(string(tableName)) + "_embedding_idx"
+}
+
+// createVectorIndex はベクトルインデックス作成を実行し、既存の場合はスキップするヘルパー関数です。
+func (s *LadybugDBStorage) createVectorIndex(ctx context.Context, tableName types.TableName) error {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	// 構文: CREATE_VECTOR_INDEX('テーブル名', 'インデックス名', 'カラム名', metric := 'cosine')
+	query := fmt.Sprintf(`CALL CREATE_VECTOR_INDEX('%s', '%s', 'embedding', metric := 'cosine')`, tableName, vectorIndexName(tableName))
+	result, err := conn.Query(query)
+	if err != nil {
+		errMsg := strings.ToLower(err.Error())
+		// 既存のインデックスはスキップ
+		if strings.Contains(errMsg, "exists") || strings.Contains(errMsg, "already") {
+			return nil
+		}
+		return fmt.Errorf("failed to create vector index on %s: %w", tableName, err)
+	}
+	result.Close()
+	return nil
+}
+
+// dropVectorIndex はベクトルインデックスを削除するヘルパー関数です。存在しない場合は何もしません。
+func (s *LadybugDBStorage) dropVectorIndex(ctx context.Context, tableName types.TableName) error {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	query := fmt.Sprintf(`CALL DROP_VECTOR_INDEX('%s', '%s')`, tableName, vectorIndexName(tableName))
+	result, err := conn.Query(query)
+	if err != nil {
+		errMsg := strings.ToLower(err.Error())
+		if strings.Contains(errMsg, "not exist") || strings.Contains(errMsg, "doesn't exist") || strings.Contains(errMsg, "not found") {
+			return nil
+		}
+		return fmt.Errorf("failed to drop vector index on %s: %w", tableName, err)
+	}
+	result.Close()
+	return nil
+}
+
+// isVectorIndexReady は、テーブルのベクトルインデックスが利用可能かどうかを返します。
+func (s *LadybugDBStorage) isVectorIndexReady(tableName types.TableName) bool {
+	s.vectorIndexMu.RLock()
+	defer s.vectorIndexMu.RUnlock()
+	return s.vectorIndexes[tableName]
+}
+
+// setVectorIndexReady は、テーブルのベクトルインデックスが利用可能かどうかを記録します。
+func (s *LadybugDBStorage) setVectorIndexReady(tableName types.TableName, ready bool) {
+	s.vectorIndexMu.Lock()
+	defer s.vectorIndexMu.Unlock()
+	s.vectorIndexes[tableName] = ready
+}
+
+// RebuildVectorIndexes は、埋め込みテーブルのベクトルインデックスを削除して作成し直します。
+// 再構築中のテーブルは全件走査で検索されます。全テーブルの再構築を試み、失敗したテーブルがあればまとめてエラーを返します。
+func (s *LadybugDBStorage) RebuildVectorIndexes(ctx context.Context) error {
+	var errs []string
+	for _, tableName := range VECTOR_INDEXED_TABLES {
+		s.setVectorIndexReady(tableName, false)
+		if err := s.dropVectorIndex(ctx, tableName); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := s.createVectorIndex(ctx, tableName); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		s.setVectorIndexReady(tableName, true)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to rebuild vector indexes: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// MIGRATION_EMBEDDING_COLUMN は、埋め込みモデルの移行中に移行先のベクトルを保存するカラムです。
+// 移行の完了時に embedding カラムと置き換えます。
+const MIGRATION_EMBEDDING_COLUMN = "embedding_next"
+
+// PrepareEmbeddingMigration は、埋め込みテーブルに移行先の次元数のカラム（embedding_next）を追加します。
+// 前回中断した移行のカラムが残っている場合は、次元数が異なる可能性があるため削除してから追加します。
+func (s *LadybugDBStorage) PrepareEmbeddingMigration(ctx context.Context, dimension uint) error {
+	if err := s.AbortEmbeddingMigration(ctx); err != nil {
+		return err
+	}
+	for _, tableName := range types.EMBEDDING_TABLE_NAMES {
+		query := fmt.Sprintf(`ALTER TABLE %s ADD %s FLOAT[%d]`, tableName, MIGRATION_EMBEDDING_COLUMN, dimension)
+		if err := s.execDDL(ctx, query); err != nil {
+			return fmt.Errorf("Failed to prepare embedding migration of %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// GetPendingEmbeddings は、移行先のカラムが未設定の行を ID 順に最大 limit 件返します。
+func (s *LadybugDBStorage) GetPendingEmbeddings(ctx context.Context, tableName types.TableName, afterID string, limit int) ([]*storage.EmbeddingSource, error) {
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE n.%s IS NULL AND n.id > '%s'
+		RETURN n.id, n.text
+		ORDER BY n.id
+		LIMIT %d
+	`, tableName, MIGRATION_EMBEDDING_COLUMN, escapeString(afterID), limit)
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get pending embeddings of %s: %w", tableName, err)
+	}
+	defer result.Close()
+	var sources []*storage.EmbeddingSource
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		src := &storage.EmbeddingSource{}
+		if v, _ := row.GetValue(0); v != nil {
+			src.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			src.Text = getString(v)
+		}
+		sources = append(sources, src)
+		row.Close()
+	}
+	return sources, nil
+}
+
+// CountPendingEmbeddings は、移行先のカラムが未設定の行数を返します。
+func (s *LadybugDBStorage) CountPendingEmbeddings(ctx context.Context, tableName types.TableName) (int, error) {
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE n.%s IS NULL
+		RETURN count(n)
+	`, tableName, MIGRATION_EMBEDDING_COLUMN)
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count pending embeddings of %s: %w", tableName, err)
+	}
+	defer result.Close()
+	count := 0
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return 0, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		if v, _ := row.GetValue(0); v != nil {
+			count = int(getInt64(v))
+		}
+		row.Close()
+	}
+	return count, nil
+}
+
+// SaveMigratedEmbedding は、移行先のカラムにベクトルを保存します。
+// 移行先のカラムにはベクトルインデックスがないため、SET で更新できます。
+func (s *LadybugDBStorage) SaveMigratedEmbedding(ctx context.Context, tableName types.TableName, id string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("Embedding of %s '%s' is empty.", tableName, id)
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s {id: '%s'})
+		SET n.%s = %s
+	`, tableName, escapeString(id), MIGRATION_EMBEDDING_COLUMN, formatVectorForLadybugDB(vector))
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	result, err := conn.Query(query)
+	if err != nil {
+		return fmt.Errorf("Failed to save migrated embedding: %w", err)
+	}
+	result.Close()
+	return nil
+}
+
+// CompleteEmbeddingMigration は、ベクトルインデックスと旧モデルの embedding カラムを削除し、
+// 移行先のカラムを embedding に名前変更します。
+// 完了後、ベクトルインデックスが作成し直されるまでの検索は全件走査になります。
+// トランザクション内で呼ばれ、ロールバックされた場合は、インデックスが残るためインデックスの利用可否を元に戻します。
+func (s *LadybugDBStorage) CompleteEmbeddingMigration(ctx context.Context) error {
+	for _, tableName := range VECTOR_INDEXED_TABLES {
+		wasReady := s.isVectorIndexReady(tableName)
+		s.setVectorIndexReady(tableName, false)
+		onRollback(ctx, func() { s.setVectorIndexReady(tableName, wasReady) })
+		if err := s.dropVectorIndex(ctx, tableName); err != nil {
+			return err
+		}
+	}
+	for _, tableName := range types.EMBEDDING_TABLE_NAMES {
+		queries := []string{
+			fmt.Sprintf(`ALTER TABLE %s DROP embedding`, tableName),
+			fmt.Sprintf(`ALTER TABLE %s RENAME %s TO embedding`, tableName, MIGRATION_EMBEDDING_COLUMN),
+		}
+		for _, query := range queries {
+			if err := s.execDDL(ctx, query); err != nil {
+				return fmt.Errorf("Failed to complete embedding migration of %s: %w", tableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AbortEmbeddingMigration は、移行先のカラムを削除します。カラムが存在しない場合は何もしません。
+func (s *LadybugDBStorage) AbortEmbeddingMigration(ctx context.Context) error {
+	for _, tableName := range types.EMBEDDING_TABLE_NAMES {
+		query := fmt.Sprintf(`ALTER TABLE %s DROP IF EXISTS %s`, tableName, MIGRATION_EMBEDDING_COLUMN)
+		if err := s.execDDL(ctx, query); err != nil {
+			return fmt.Errorf("Failed to abort embedding migration of %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// execDDL は、スキーマを変更するクエリを実行するヘルパー関数です。
+// createTable と異なり、"already exists" などのエラーも失敗として返します。
+func (s *LadybugDBStorage) execDDL(ctx context.Context, query string) error {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	result, err := conn.Query(query)
+	if err != nil {
+		return err
+	}
+	result.Close()
+	return nil
+}
+
 // createFtsIndex は FTS インデックス作成を実行し、既存の場合はスキップするヘルパー関数です。
 func (s *LadybugDBStorage) createFtsIndex(ctx context.Context, query string) error {
 	conn := s.getConn(ctx)
@@ -423,6 +781,7 @@ func (s *LadybugDBStorage) SaveData(ctx context.Context, data *storage.Data) err
 			d.extension = '%s',
 			d.mime_type = '%s',
 			d.content_hash = '%s',
+			d.source_id = '%s',
 			d.owner_id = '%s',
 			d.created_at = timestamp('%s')
 		ON MATCH SET 
@@ -432,6 +791,7 @@ func (s *LadybugDBStorage) SaveData(ctx context.Context, data *storage.Data) err
 			d.extension = '%s',
 			d.mime_type = '%s',
 			d.content_hash = '%s',
+			d.source_id = '%s',
 			d.owner_id = '%s',
 			d.created_at = timestamp('%s')
 	`,
@@ -444,6 +804,7 @@ func (s *LadybugDBStorage) SaveData(ctx context.Context, data *storage.Data) err
 		escapeString(data.Extension),
 		escapeString(data.MimeType),
 		escapeString(data.ContentHash),
+		escapeString(data.SourceID),
 		escapeString(data.OwnerID),
 		createdAt,
 		// ON MATCH SET
@@ -453,6 +814,7 @@ func (s *LadybugDBStorage) SaveData(ctx context.Context, data *storage.Data) err
 		escapeString(data.Extension),
 		escapeString(data.MimeType),
 		escapeString(data.ContentHash),
+		escapeString(data.SourceID),
 		escapeString(data.OwnerID),
 		createdAt,
 	)
@@ -502,8 +864,8 @@ func (s *LadybugDBStorage) GetDataByID(ctx context.Context, id string, memoryGro
 	query := fmt.Sprintf(`
 		MATCH (d:Data)
 		WHERE d.id = '%s' AND d.memory_group = '%s'
-		RETURN d.id, d.memory_group, d.name, d.raw_data_location, d.original_data_location, d.extension, d.mime_type, d.content_hash, d.owner_id, d.created_at
-	`, escapeString(id), escapeString(memoryGroup))
+		RETURN %s
+	`, escapeString(id), escapeString(memoryGroup), dataReturnColumns)
 	result, err := s.getConn(ctx).Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get data by id: %w", err)
@@ -515,49 +877,17 @@ func (s *LadybugDBStorage) GetDataByID(ctx context.Context, id string, memoryGro
 			return nil, fmt.Errorf("Failed to get next row: %w", err)
 		}
 		defer row.Close()
-		// GetValueのインデックスはRETURN句の順序に対応
-		data := &storage.Data{}
-		if v, _ := row.GetValue(0); v != nil {
-			data.ID = getString(v)
-		}
-		if v, _ := row.GetValue(1); v != nil {
-			data.MemoryGroup = getString(v)
-		}
-		if v, _ := row.GetValue(2); v != nil {
-			data.Name = getString(v)
-		}
-		if v, _ := row.GetValue(3); v != nil {
-			data.RawDataLocation = getString(v)
-		}
-		if v, _ := row.GetValue(4); v != nil {
-			data.OriginalDataLocation = getString(v)
-		}
-		if v, _ := row.GetValue(5); v != nil {
-			data.Extension = getString(v)
-		}
-		if v, _ := row.GetValue(6); v != nil {
-			data.MimeType = getString(v)
-		}
-		if v, _ := row.GetValue(7); v != nil {
-			data.ContentHash = getString(v)
-		}
-		if v, _ := row.GetValue(8); v != nil {
-			data.OwnerID = getString(v)
-		}
-		if v, _ := row.GetValue(9); v != nil {
-			data.CreatedAt = parseTimestamp(v)
-		}
-		return data, nil
+		return parseDataRow(row), nil
 	}
-	return nil, fmt.Errorf("data not found")
+	return nil, nil // 存在しない場合はnilを返す
 }
 
 func (s *LadybugDBStorage) GetDataList(ctx context.Context, memoryGroup string) ([]*storage.Data, error) {
 	query := fmt.Sprintf(`
 		MATCH (d:Data)
 		WHERE d.memory_group = '%s'
-		RETURN d.id, d.memory_group, d.name, d.raw_data_location, d.original_data_location, d.extension, d.mime_type, d.content_hash, d.owner_id, d.created_at
-	`, escapeString(memoryGroup))
+		RETURN %s
+	`, escapeString(memoryGroup), dataReturnColumns)
 	result, err := s.getConn(ctx).Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get data list: %w", err)
@@ -569,88 +899,251 @@ func (s *LadybugDBStorage) GetDataList(ctx context.Context, memoryGroup string)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to get next row: %w", err)
 		}
-
-		data := &storage.Data{}
-		if v, _ := row.GetValue(0); v != nil {
-			data.ID = getString(v)
-		}
-		if v, _ := row.GetValue(1); v != nil {
-			data.MemoryGroup = getString(v)
-		}
-		if v, _ := row.GetValue(2); v != nil {
-			data.Name = getString(v)
-		}
-		if v, _ := row.GetValue(3); v != nil {
-			data.RawDataLocation = getString(v)
-		}
-		if v, _ := row.GetValue(4); v != nil {
-			data.OriginalDataLocation = getString(v)
-		}
-		if v, _ := row.GetValue(5); v != nil {
-			data.Extension = getString(v)
-		}
-		if v, _ := row.GetValue(6); v != nil {
-			data.MimeType = getString(v)
-		}
-		if v, _ := row.GetValue(7); v != nil {
-			data.ContentHash = getString(v)
-		}
-		if v, _ := row.GetValue(8); v != nil {
-			data.OwnerID = getString(v)
-		}
-		if v, _ := row.GetValue(9); v != nil {
-			data.CreatedAt = parseTimestamp(v)
-		}
-		dataList = append(dataList, data)
+		dataList = append(dataList, parseDataRow(row))
 		row.Close() // 手動でClose
 	}
 	return dataList, nil
 }
 
-func (s *LadybugDBStorage) GetDocumentByID(ctx context.Context, id string, memoryGroup string) (*storage.Document, error) {
+func (s *LadybugDBStorage) GetDataBySourceID(ctx context.Context, sourceID string, memoryGroup string) ([]*storage.Data, error) {
 	query := fmt.Sprintf(`
-		MATCH (d:%s)
-		WHERE d.id = '%s' AND d.memory_group = '%s'
-		RETURN d.id, d.memory_group, d.data_id, d.text, d.metadata
-	`, types.TABLE_NAME_DOCUMENT, escapeString(id), escapeString(memoryGroup))
+		MATCH (d:Data)
+		WHERE d.source_id = '%s' AND d.memory_group = '%s'
+		RETURN %s
+	`, escapeString(sourceID), escapeString(memoryGroup), dataReturnColumns)
 	result, err := s.getConn(ctx).Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to get document by id: %w", err)
+		return nil, fmt.Errorf("Failed to get data by source id: %w", err)
 	}
 	defer result.Close()
-	if result.HasNext() {
+	var dataList []*storage.Data
+	for result.HasNext() {
 		row, err := result.Next()
 		if err != nil {
 			return nil, fmt.Errorf("Failed to get next row: %w", err)
 		}
-		defer row.Close()
-		doc := &storage.Document{}
-		if v, _ := row.GetValue(0); v != nil {
-			doc.ID = getString(v)
-		}
-		if v, _ := row.GetValue(1); v != nil {
-			doc.MemoryGroup = getString(v)
-		}
-		if v, _ := row.GetValue(2); v != nil {
-			doc.DataID = getString(v)
-		}
-		if v, _ := row.GetValue(3); v != nil {
-			doc.Text = getString(v)
-		}
-		if v, _ := row.GetValue(4); v != nil {
-			metaStr := getString(v)
-			json.Unmarshal([]byte(metaStr), &doc.MetaData)
-		}
-		return doc, nil
+		dataList = append(dataList, parseDataRow(row))
+		row.Close()
 	}
-	return nil, fmt.Errorf("document not found")
+	return dataList, nil
 }
 
-func (s *LadybugDBStorage) SaveDocument(ctx context.Context, document *storage.Document) error {
+func (s *LadybugDBStorage) SetDataSourceID(ctx context.Context, dataID string, sourceID string, memoryGroup string) error {
 	if err := s.checkContext(ctx); err != nil {
 		return err
 	}
-	// MetadataはJSON文字列として保存
+	query := fmt.Sprintf(`
+		MATCH (d:Data {id: '%s', memory_group: '%s'})
+		SET d.source_id = '%s'
+	`, escapeString(dataID), escapeString(memoryGroup), escapeString(sourceID))
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	result, err := conn.Query(query)
+	if err != nil {
+		return fmt.Errorf("Failed to set source id of data: %w", err)
+	}
+	result.Close()
+	return nil
+}
+
+func (s *LadybugDBStorage) GetChunkIDsByDataID(ctx context.Context, dataID string, memoryGroup string) ([]string, error) {
+	query := fmt.Sprintf(`
+		MATCH (doc:%s {memory_group: '%s'})-[:HAS_CHUNK]->(c:%s)
+		WHERE doc.data_id = '%s'
+		RETURN c.id
+	`, types.TABLE_NAME_DOCUMENT, escapeString(memoryGroup), types.TABLE_NAME_CHUNK, escapeString(dataID))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get chunk ids by data id: %w", err)
+	}
+	defer result.Close()
+	var chunkIDs []string
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		if v, _ := row.GetValue(0); v != nil {
+			chunkIDs = append(chunkIDs, getString(v))
+		}
+		row.Close()
+	}
+	return chunkIDs, nil
+}
+
+// GetCitations は、Chunk -> Document -> Data を辿り、チャンクの出典情報を取得します。
+func (s *LadybugDBStorage) GetCitations(ctx context.Context, chunkIDs []string, memoryGroup string) ([]*storage.Citation, error) {
+	if len(chunkIDs) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (d:%s {memory_group: '%s'})-[:HAS_DOCUMENT]->(doc:%s)-[:HAS_CHUNK]->(c:%s)
+		WHERE c.id IN %s
+		RETURN c.id, c.chunk_index, doc.id, d.id, d.name, d.source_id
+	`, types.TABLE_NAME_DATA, escapeString(memoryGroup), types.TABLE_NAME_DOCUMENT, types.TABLE_NAME_CHUNK,
+		formatStringList(chunkIDs))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get citations: %w", err)
+	}
+	defer result.Close()
+	citationMap := make(map[string]*storage.Citation, len(chunkIDs))
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		c := &storage.Citation{}
+		if v, _ := row.GetValue(0); v != nil {
+			c.ChunkID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			c.ChunkIndex = int(getInt64(v))
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			c.DocumentID = getString(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			c.DataID = getString(v)
+		}
+		if v, _ := row.GetValue(4); v != nil {
+			c.FileName = getString(v)
+		}
+		if v, _ := row.GetValue(5); v != nil {
+			c.SourceID = getString(v)
+		}
+		row.Close()
+		citationMap[c.ChunkID] = c
+	}
+	// 呼び出し側の順序（関連度順など）を維持する
+	citations := make([]*storage.Citation, 0, len(citationMap))
+	for _, id := range chunkIDs {
+		if c, ok := citationMap[id]; ok {
+			citations = append(citations, c)
+			delete(citationMap, id)
+		}
+	}
+	return citations, nil
+}
+
+// GetChunkTextsByIDs は、チャンクIDからチャンクの本文を取得します。
+func (s *LadybugDBStorage) GetChunkTextsByIDs(ctx context.Context, chunkIDs []string, memoryGroup string) (map[string]string, error) {
+	texts := make(map[string]string, len(chunkIDs))
+	if len(chunkIDs) == 0 {
+		return texts, nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.memory_group = '%s' AND c.id IN %s
+		RETURN c.id, c.text
+	`, types.TABLE_NAME_CHUNK, escapeString(memoryGroup), formatStringList(chunkIDs))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get chunk texts: %w", err)
+	}
+	defer result.Close()
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		idVal, _ := row.GetValue(0)
+		textVal, _ := row.GetValue(1)
+		if idVal != nil && textVal != nil {
+			texts[getString(idVal)] = getString(textVal)
+		}
+		row.Close()
+	}
+	return texts, nil
+}
+
+// DeleteData は、Data -> Document -> Chunk の順に辿れるノードを全て削除します。
+// リレーション（HAS_DOCUMENT, HAS_CHUNK, NEXT_CHUNK）は DETACH DELETE により同時に削除されます。
+func (s *LadybugDBStorage) DeleteData(ctx context.Context, dataID string, memoryGroup string) error {
+	if err := s.checkContext(ctx); err != nil {
+		return err
+	}
+	queries := []string{
+		// 1. Chunk
+		fmt.Sprintf(`
+			MATCH (doc:%s {memory_group: '%s'})-[:HAS_CHUNK]->(c:%s)
+			WHERE doc.data_id = '%s'
+			DETACH DELETE c
+		`, types.TABLE_NAME_DOCUMENT, escapeString(memoryGroup), types.TABLE_NAME_CHUNK, escapeString(dataID)),
+		// 2. Document
+		fmt.Sprintf(`
+			MATCH (doc:%s {memory_group: '%s'})
+			WHERE doc.data_id = '%s'
+			DETACH DELETE doc
+		`, types.TABLE_NAME_DOCUMENT, escapeString(memoryGroup), escapeString(dataID)),
+		// 3. Data
+		fmt.Sprintf(`
+			MATCH (d:%s {id: '%s', memory_group: '%s'})
+			DETACH DELETE d
+		`, types.TABLE_NAME_DATA, escapeString(dataID), escapeString(memoryGroup)),
+	}
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	for _, query := range queries {
+		if result, err := conn.Query(query); err != nil {
+			return fmt.Errorf("Failed to delete data %s: %w", dataID, err)
+		} else {
+			result.Close()
+		}
+	}
+	return nil
+}
+
+func (s *LadybugDBStorage) GetDocumentByID(ctx context.Context, id string, memoryGroup string) (*storage.Document, error) {
+	query := fmt.Sprintf(`
+		MATCH (d:%s)
+		WHERE d.id = '%s' AND d.memory_group = '%s'
+		RETURN d.id, d.memory_group, d.data_id, d.text, d.metadata
+	`, types.TABLE_NAME_DOCUMENT, escapeString(id), escapeString(memoryGroup))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get document by id: %w", err)
+	}
+	defer result.Close()
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		defer row.Close()
+		doc := &storage.Document{}
+		if v, _ := row.GetValue(0); v != nil {
+			doc.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			doc.MemoryGroup = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			doc.DataID = getString(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			doc.Text = getString(v)
+		}
+		if v, _ := row.GetValue(4); v != nil {
+			metaStr := getString(v)
+			json.Unmarshal([]byte(metaStr), &doc.MetaData)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("document not found")
+}
+
+func (s *LadybugDBStorage) SaveDocument(ctx context.Context, document *storage.Document) error {
+	if err := s.checkContext(ctx); err != nil {
+		return err
+	}
+	// MetadataはJSON文字列として保存
 	metaJSON, _ := json.Marshal(document.MetaData)
 	metaStr := string(metaJSON)
 	// 1. Documentノード作成 (MERGE)
@@ -714,46 +1207,35 @@ func (s *LadybugDBStorage) SaveChunk(ctx context.Context, chunk *storage.Chunk)
 	if err := s.checkContext(ctx); err != nil {
 		return err
 	}
-	// 1. Chunkノード作成 (MERGE)
+	// 1. Chunkノード作成
+	// ベクトルインデックスが張られた embedding カラムは SET で更新できないため、
+	// 既存のチャンクは削除してから作成し直す（HAS_CHUNK は 2. で、それ以外の削除で失われるリレーションは 3. で張り直す）
 	// Embeddingがあれば保存 (FLOAT[1536])
 	embeddingStr := "NULL"
 	if len(chunk.Embedding) > 0 {
 		embeddingStr = formatVectorForLadybugDB(chunk.Embedding)
 	}
+	queryDelete := fmt.Sprintf(`
+		MATCH (c:%s {id: '%s', memory_group: '%s'})
+		DETACH DELETE c
+	`, types.TABLE_NAME_CHUNK, escapeString(chunk.ID), escapeString(chunk.MemoryGroup))
 	queryChunk := fmt.Sprintf(`
-		MERGE (c:%s {id: '%s', memory_group: '%s'})
-		ON CREATE SET
-			c.document_id = '%s',
-			c.text = '%s',
-			c.keywords = '%s',
-			c.nouns = '%s',
-			c.nouns_verbs = '%s',
-			c.token_count = %d,
-			c.chunk_index = %d,
-			c.embedding = %s
-		ON MATCH SET
-			c.document_id = '%s',
-			c.text = '%s',
-			c.keywords = '%s',
-			c.nouns = '%s',
-			c.nouns_verbs = '%s',
-			c.token_count = %d,
-			c.chunk_index = %d,
-			c.embedding = %s
+		CREATE (c:%s {
+			id: '%s',
+			memory_group: '%s',
+			document_id: '%s',
+			text: '%s',
+			keywords: '%s',
+			nouns: '%s',
+			nouns_verbs: '%s',
+			token_count: %d,
+			chunk_index: %d,
+			embedding: %s
+		})
 	`,
 		types.TABLE_NAME_CHUNK,
 		escapeString(chunk.ID),
 		escapeString(chunk.MemoryGroup),
-		// ON CREATE
-		escapeString(chunk.DocumentID),
-		escapeString(chunk.Text),
-		escapeString(chunk.Keywords),
-		escapeString(chunk.Nouns),
-		escapeString(chunk.NounsVerbs),
-		chunk.TokenCount,
-		chunk.ChunkIndex,
-		embeddingStr,
-		// ON MATCH
 		escapeString(chunk.DocumentID),
 		escapeString(chunk.Text),
 		escapeString(chunk.Keywords),
@@ -769,6 +1251,24 @@ func (s *LadybugDBStorage) SaveChunk(ctx context.Context, chunk *storage.Chunk)
 		defer s.mu.Unlock()
 	}
 
+	// 削除前に、既存のチャンクに張られたリレーションの相手を控えておく
+	linkedIDs := make([][]string, len(CHUNK_RELATIONS))
+	for i, rel := range CHUNK_RELATIONS {
+		ids, err := queryIDs(conn, fmt.Sprintf(`
+			MATCH (c:%s {id: '%s', memory_group: '%s'})%s[:%s]%s(o:%s)
+			RETURN o.id
+		`, types.TABLE_NAME_CHUNK, escapeString(chunk.ID), escapeString(chunk.MemoryGroup),
+			rel.leftArrow(), rel.relType, rel.rightArrow(), rel.otherTable))
+		if err != nil {
+			return fmt.Errorf("Failed to get %s relations of chunk: %w", rel.relType, err)
+		}
+		linkedIDs[i] = ids
+	}
+	if result, err := conn.Query(queryDelete); err != nil {
+		return fmt.Errorf("Failed to replace chunk node: %w", err)
+	} else {
+		result.Close()
+	}
 	if result, err := conn.Query(queryChunk); err != nil {
 		return fmt.Errorf("Failed to save chunk node: %w", err)
 	} else {
@@ -790,44 +1290,178 @@ func (s *LadybugDBStorage) SaveChunk(ctx context.Context, chunk *storage.Chunk)
 	} else {
 		result.Close()
 	}
+	// 3. 削除前のリレーション (NEXT_CHUNK) を張り直す
+	for i, rel := range CHUNK_RELATIONS {
+		for _, otherID := range linkedIDs[i] {
+			query := fmt.Sprintf(`
+				MATCH (c:%s {id: '%s', memory_group: '%s'}), (o:%s {id: '%s'})
+				MERGE (c)%s[:%s {memory_group: '%s'}]%s(o)
+			`, types.TABLE_NAME_CHUNK, escapeString(chunk.ID), escapeString(chunk.MemoryGroup),
+				rel.otherTable, escapeString(otherID),
+				rel.leftArrow(), rel.relType, escapeString(chunk.MemoryGroup), rel.rightArrow())
+			if result, err := conn.Query(query); err != nil {
+				return fmt.Errorf("Failed to restore %s relation of chunk: %w", rel.relType, err)
+			} else {
+				result.Close()
+			}
+		}
+	}
 	return nil
 }
 
+// chunkRelation は、Chunk ノードに張られるリレーションの種類です。
+type chunkRelation struct {
+	relType    string
+	otherTable types.TableName
+	incoming   bool // true の場合は相手のノードから Chunk へのリレーション
+}
+
+// CHUNK_RELATIONS は、SaveChunk で既存のチャンクを作成し直す際に張り直す、Chunk ノードのリレーションの一覧です。
+// HAS_CHUNK は、チャンクが現在属するドキュメントから張り直すため含みません。
+// EdgeProvenance や DocumentChunk の GraphNode はチャンクIDをプロパティとして参照するため、作成し直しの影響を受けません。
+var CHUNK_RELATIONS = []chunkRelation{
+	{relType: "NEXT_CHUNK", otherTable: types.TABLE_NAME_CHUNK, incoming: true},
+	{relType: "NEXT_CHUNK", otherTable: types.TABLE_NAME_CHUNK, incoming: false},
+}
+
+// leftArrow と rightArrow は、(c:Chunk)<left>[:REL]<right>(o) の形でリレーションの向きを表す矢印を返します。
+func (r chunkRelation) leftArrow() string {
+	if r.incoming {
+		return "<-"
+	}
+	return "-"
+}
+
+func (r chunkRelation) rightArrow() string {
+	if r.incoming {
+		return "-"
+	}
+	return "->"
+}
+
+// queryIDs は、1列目にIDを返すクエリを実行し、IDのリストを返します。
+func queryIDs(conn *ladybug.Connection, query string) ([]string, error) {
+	result, err := conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	var ids []string
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		if v, _ := row.GetValue(0); v != nil {
+			ids = append(ids, getString(v))
+		}
+		row.Close()
+	}
+	return ids, nil
+}
+
 func (s *LadybugDBStorage) SaveEmbedding(ctx context.Context, tableName types.TableName, id string, text string, vector []float32, memoryGroup string) error {
 	if len(vector) == 0 {
 		return nil
 	}
 	vecStr := formatVectorForLadybugDB(vector)
-	// IDでChunkを検索し、embedding と text を更新
-	query := fmt.Sprintf(`
-		MERGE (c:%s {id: '%s'})
-		ON CREATE SET
-			c.memory_group = '%s',
-			c.embedding = %s,
-			c.text = '%s'
-		ON MATCH SET
-			c.memory_group = '%s',
-			c.embedding = %s,
-			c.text = '%s'
-	`, tableName, escapeString(id), escapeString(memoryGroup), vecStr, escapeString(text), escapeString(memoryGroup), vecStr, escapeString(text))
+	// IDでノードを検索し、embedding と text を更新
+	// ベクトルインデックスが張られた embedding カラムは SET で更新できないため、既存のノードを削除してから作成し直す
+	// （埋め込みテーブルにはリレーションテーブルがないため、削除で失われるリレーションはない。
+	//  リレーションが張られている場合は DELETE が失敗するため、黙って失われることもない）
+	// 削除はメモリーグループ内に限定する。他のメモリーグループに同じIDのノードがある場合は、主キーの重複で作成が失敗する
+	queries := []string{
+		fmt.Sprintf(`
+			MATCH (c:%s {id: '%s', memory_group: '%s'})
+			DELETE c
+		`, tableName, escapeString(id), escapeString(memoryGroup)),
+		fmt.Sprintf(`
+			CREATE (c:%s {id: '%s', memory_group: '%s', embedding: %s, text: '%s'})
+		`, tableName, escapeString(id), escapeString(memoryGroup), vecStr, escapeString(text)),
+	}
 	conn := s.getConn(ctx)
 	if conn == s.conn {
 		s.mu.Lock()
 		defer s.mu.Unlock()
 	}
 
-	if result, err := conn.Query(query); err != nil {
-		return fmt.Errorf("Failed to save embedding: %w", err)
-	} else {
-		result.Close()
+	for _, query := range queries {
+		if result, err := conn.Query(query); err != nil {
+			return fmt.Errorf("Failed to save embedding: %w", err)
+		} else {
+			result.Close()
+		}
 	}
 	return nil
 }
 
+// Query は、ベクトル類似度検索を実行します。
+// テーブルのベクトルインデックスが利用可能な場合は近似最近傍検索を行い、
+// インデックスが利用できない場合や、memory_group で絞り込んだ結果が topk 件に満たない場合は全件走査（厳密検索）にフォールバックします。
 func (s *LadybugDBStorage) Query(ctx context.Context, tableName types.TableName, vector []float32, topk int, memoryGroup string) ([]*storage.QueryResult, error) {
 	if len(vector) == 0 {
 		return nil, fmt.Errorf("Query vector is empty.")
 	}
+	if s.isVectorIndexReady(tableName) {
+		results, err := s.queryVectorIndex(ctx, tableName, vector, topk, memoryGroup)
+		if err != nil {
+			// インデックスが壊れている・拡張が読み込めない等の場合は、以降は全件走査で検索する
+			utils.LogWarn(s.Logger, "LadybugDB: Vector index query failed, falling back to exact search", zap.String("table", string(tableName)), zap.Error(err))
+			s.setVectorIndexReady(tableName, false)
+		} else if len(results) >= topk {
+			return results, nil
+		}
+	}
+	return s.queryExact(ctx, tableName, vector, topk, memoryGroup)
+}
+
+// queryVectorIndex は、ベクトルインデックス (HNSW) を使って近似最近傍検索を実行します。
+// インデックスは memory_group を区別しないため、topk × VECTOR_SEARCH_OVERSAMPLING 件を取得してから memory_group で絞り込みます。
+// スコアは全件走査と揃えるため、コサイン類似度（1 - コサイン距離）で返します。
+func (s *LadybugDBStorage) queryVectorIndex(ctx context.Context, tableName types.TableName, vector []float32, topk int, memoryGroup string) ([]*storage.QueryResult, error) {
+	vecStr := formatVectorForLadybugDB(vector)
+	efs := s.VectorSearchEfs
+	if efs <= 0 {
+		efs = appconfig.DEFAULT_VECTOR_SEARCH_EFS
+	}
+	// 構文: QUERY_VECTOR_INDEX('テーブル名', 'インデックス名', クエリベクトル, k, efs := 探索幅) RETURN node, distance
+	query := fmt.Sprintf(`
+		CALL QUERY_VECTOR_INDEX('%s', '%s', %s, %d, efs := %d)
+		WITH node AS c, distance
+		WHERE c.memory_group = '%s'
+		RETURN c.id, c.text, 1.0 - distance AS score
+		ORDER BY score DESC
+		LIMIT %d
+	`, tableName, vectorIndexName(tableName), vecStr, topk*appconfig.VECTOR_SEARCH_OVERSAMPLING, efs, escapeString(memoryGroup), topk)
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Vector index query failed: %w", err)
+	}
+	defer result.Close()
+	var results []*storage.QueryResult
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Vector index query next failed: %w", err)
+		}
+		res := &storage.QueryResult{}
+		if v, _ := row.GetValue(0); v != nil {
+			res.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			res.Text = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			res.Distance = getFloat64(v)
+		}
+		results = append(results, res)
+		row.Close()
+	}
+	return results, nil
+}
+
+// queryExact は、memory_group 内の全件についてコサイン類似度を計算する厳密なベクトル検索を実行します。
+func (s *LadybugDBStorage) queryExact(ctx context.Context, tableName types.TableName, vector []float32, topk int, memoryGroup string) ([]*storage.QueryResult, error) {
 	vecStr := formatVectorForLadybugDB(vector)
 	// array_cosine_similarity 関数を使って類似度計算
 	// LadybugDBのバージョンによっては cosine_similarity または array_cosine_similarity
@@ -938,26 +1572,78 @@ func (s *LadybugDBStorage) FullTextSearch(ctx context.Context, tableName types.T
 	return results, nil
 }
 
-func (s *LadybugDBStorage) GetEmbeddingByID(ctx context.Context, tableName types.TableName, id string, memoryGroup string) ([]float32, error) {
-	// Chunkテーブルから取得
+// QueryChunksInTimeRange は、元データの取り込み日時が指定期間内のチャンクに限定してベクトル類似度検索を実行します。
+// Data -> Document -> Chunk の関係を辿り、Data.created_at で期間を絞り込みます。
+func (s *LadybugDBStorage) QueryChunksInTimeRange(ctx context.Context, vector []float32, topk int, memoryGroup string, timeRange types.TimeRange) ([]*storage.QueryResult, error) {
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("Query vector is empty.")
+	}
+	vecStr := formatVectorForLadybugDB(vector)
+	var timeCond strings.Builder
+	if !timeRange.From.IsZero() {
+		timeCond.WriteString(fmt.Sprintf(" AND d.created_at >= timestamp('%s')", timeRange.From.Format(time.RFC3339)))
+	}
+	if !timeRange.To.IsZero() {
+		timeCond.WriteString(fmt.Sprintf(" AND d.created_at <= timestamp('%s')", timeRange.To.Format(time.RFC3339)))
+	}
 	query := fmt.Sprintf(`
-		MATCH (c:%s)
-		WHERE c.id = '%s' AND c.memory_group = '%s'
-		RETURN c.embedding
-	`, tableName, escapeString(id), escapeString(memoryGroup))
+		MATCH (d:%s {memory_group: '%s'})-[:HAS_DOCUMENT]->(doc:%s)-[:HAS_CHUNK]->(c:%s)
+		WHERE c.memory_group = '%s' AND c.embedding IS NOT NULL%s
+		RETURN c.id, c.text, array_cosine_similarity(c.embedding, %s) AS score, d.created_at
+		ORDER BY score DESC
+		LIMIT %d
+	`, types.TABLE_NAME_DATA, escapeString(memoryGroup), types.TABLE_NAME_DOCUMENT, types.TABLE_NAME_CHUNK,
+		escapeString(memoryGroup), timeCond.String(), vecStr, topk)
 	result, err := s.getConn(ctx).Query(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get embedding: %w", err)
+		return nil, fmt.Errorf("Query chunks in time range failed: %w", err)
 	}
 	defer result.Close()
-	if result.HasNext() {
+	var results []*storage.QueryResult
+	for result.HasNext() {
 		row, err := result.Next()
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("Query chunks in time range next failed: %w", err)
 		}
-		defer row.Close()
+		res := &storage.QueryResult{}
 		if v, _ := row.GetValue(0); v != nil {
-			return parseEmbedding(v), nil
+			res.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			res.Text = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			res.Distance = getFloat64(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			res.ObservedAt = parseTimestamp(v)
+		}
+		results = append(results, res)
+		row.Close()
+	}
+	return results, nil
+}
+
+func (s *LadybugDBStorage) GetEmbeddingByID(ctx context.Context, tableName types.TableName, id string, memoryGroup string) ([]float32, error) {
+	// Chunkテーブルから取得
+	query := fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.id = '%s' AND c.memory_group = '%s'
+		RETURN c.embedding
+	`, tableName, escapeString(id), escapeString(memoryGroup))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding: %w", err)
+	}
+	defer result.Close()
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		defer row.Close()
+		if v, _ := row.GetValue(0); v != nil {
+			return parseEmbedding(v), nil
 		}
 	}
 	return nil, nil // Not found
@@ -1012,6 +1698,152 @@ func (s *LadybugDBStorage) GetEmbeddingsByIDs(ctx context.Context, tableName typ
 	return embeddings, nil
 }
 
+func (s *LadybugDBStorage) DeleteEmbeddings(ctx context.Context, tableName types.TableName, ids []string, memoryGroup string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.memory_group = '%s' AND c.id IN %s
+		DELETE c
+	`, tableName, escapeString(memoryGroup), formatStringList(ids))
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	if result, err := conn.Query(query); err != nil {
+		return fmt.Errorf("Failed to delete embeddings: %w", err)
+	} else {
+		result.Close()
+	}
+	return nil
+}
+
+// GetEmbeddingTextsByIDs は、複数IDのテキストを一括取得します。
+func (s *LadybugDBStorage) GetEmbeddingTextsByIDs(ctx context.Context, tableName types.TableName, ids []string, memoryGroup string) (map[string]string, error) {
+	texts := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return texts, nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.memory_group = '%s' AND c.id IN %s
+		RETURN c.id, c.text
+	`, tableName, escapeString(memoryGroup), formatStringList(ids))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get embedding texts: %w", err)
+	}
+	defer result.Close()
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		idVal, _ := row.GetValue(0)
+		textVal, _ := row.GetValue(1)
+		if idVal != nil && textVal != nil {
+			texts[getString(idVal)] = getString(textVal)
+		}
+		row.Close()
+	}
+	return texts, nil
+}
+
+// GetCachedEmbeddings は、埋め込みキャッシュからキーに一致するベクトルを取得します。
+func (s *LadybugDBStorage) GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error) {
+	embeddings := make(map[string][]float32)
+	if len(keys) == 0 {
+		return embeddings, nil
+	}
+	query := fmt.Sprintf(`
+		MATCH (e:%s)
+		WHERE e.id IN %s
+		RETURN e.id, e.embedding
+	`, types.TABLE_NAME_EMBEDDING_CACHE, formatStringList(keys))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get cached embeddings: %w", err)
+	}
+	defer result.Close()
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		var id string
+		var vec []float32
+		if v, _ := row.GetValue(0); v != nil {
+			id = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			vec = parseEmbedding(v)
+		}
+		if id != "" && len(vec) > 0 {
+			embeddings[id] = vec
+		}
+		row.Close()
+	}
+	return embeddings, nil
+}
+
+// SaveCachedEmbeddings は、ベクトルを埋め込みキャッシュに保存します。
+// トランザクション外から呼び出された場合、他の書き込み（Absorb などのトランザクション）の完了を待たないよう、
+// ロックを取得できなければ保存を省略します。
+func (s *LadybugDBStorage) SaveCachedEmbeddings(ctx context.Context, entries map[string][]float32) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		if !s.mu.TryLock() {
+			return nil
+		}
+		defer s.mu.Unlock()
+	}
+	createdAt := time.Now().Format(time.RFC3339)
+	for key, vector := range entries {
+		if len(vector) == 0 {
+			continue
+		}
+		query := fmt.Sprintf(`
+			MERGE (e:%s {id: '%s'})
+			ON CREATE SET e.embedding = CAST(%s AS FLOAT[]), e.created_at = timestamp('%s')
+		`, types.TABLE_NAME_EMBEDDING_CACHE, escapeString(key), formatVectorForLadybugDB(vector), createdAt)
+		if result, err := conn.Query(query); err != nil {
+			return fmt.Errorf("Failed to save cached embedding: %w", err)
+		} else {
+			result.Close()
+		}
+	}
+	return nil
+}
+
+// DeleteCachedEmbeddings は、埋め込みキャッシュからキーに一致するエントリを削除します。
+func (s *LadybugDBStorage) DeleteCachedEmbeddings(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	query := fmt.Sprintf(`
+		MATCH (e:%s)
+		WHERE e.id IN %s
+		DELETE e
+	`, types.TABLE_NAME_EMBEDDING_CACHE, formatStringList(keys))
+	if result, err := conn.Query(query); err != nil {
+		return fmt.Errorf("Failed to delete cached embeddings: %w", err)
+	} else {
+		result.Close()
+	}
+	return nil
+}
+
 // =================================================================================
 // GraphStorage Interface Implementation
 // =================================================================================
@@ -1582,6 +2414,165 @@ func (s *LadybugDBStorage) GetNodesByType(ctx context.Context, nodeType string,
 	return nodes, nil
 }
 
+// GetNodesByIDs は、指定されたIDのノードを取得します。
+func (s *LadybugDBStorage) GetNodesByIDs(ctx context.Context, ids []string, memoryGroup string) ([]*storage.Node, error) {
+	if len(ids) == 0 {
+		return []*storage.Node{}, nil
+	}
+	var idListStr strings.Builder
+	idListStr.WriteString("[")
+	for i, id := range ids {
+		if i > 0 {
+			idListStr.WriteString(", ")
+		}
+		idListStr.WriteString(fmt.Sprintf("'%s'", escapeString(id)))
+	}
+	idListStr.WriteString("]")
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE n.memory_group = '%s' AND n.id IN %s
+		RETURN n.id, n.type, n.properties
+	`, types.TABLE_NAME_GRAPH_NODE, escapeString(memoryGroup), idListStr.String())
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("GetNodesByIDs query failed: %w", err)
+	}
+	defer result.Close()
+	nodes := []*storage.Node{}
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		n := &storage.Node{MemoryGroup: memoryGroup}
+		if v, _ := row.GetValue(0); v != nil {
+			n.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			n.Type = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			n.Properties = parseJSONProperties(getString(v))
+		}
+		nodes = append(nodes, n)
+		row.Close()
+	}
+	return nodes, nil
+}
+
+// GetNodesByPropertyText は、properties に texts のいずれかを含むノードを取得します。
+// クエリが長くなりすぎないよう、texts は一定数ずつに分けて検索します。
+func (s *LadybugDBStorage) GetNodesByPropertyText(ctx context.Context, texts []string, memoryGroup string) ([]*storage.Node, error) {
+	const batchSize = 50
+	nodes := []*storage.Node{}
+	seen := make(map[string]bool)
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+		conds := make([]string, 0, len(batch))
+		for _, text := range batch {
+			conds = append(conds, fmt.Sprintf("n.properties CONTAINS '%s'", escapeString(text)))
+		}
+		query := fmt.Sprintf(`
+			MATCH (n:%s)
+			WHERE n.memory_group = '%s' AND n.type <> '%s' AND (%s)
+			RETURN n.id, n.type, n.properties
+		`, types.TABLE_NAME_GRAPH_NODE, escapeString(memoryGroup), types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK, strings.Join(conds, " OR "))
+		result, err := s.getConn(ctx).Query(query)
+		if err != nil {
+			return nil, fmt.Errorf("GetNodesByPropertyText query failed: %w", err)
+		}
+		for result.HasNext() {
+			row, err := result.Next()
+			if err != nil {
+				result.Close()
+				return nil, err
+			}
+			n := &storage.Node{MemoryGroup: memoryGroup}
+			if v, _ := row.GetValue(0); v != nil {
+				n.ID = getString(v)
+			}
+			if v, _ := row.GetValue(1); v != nil {
+				n.Type = getString(v)
+			}
+			if v, _ := row.GetValue(2); v != nil {
+				n.Properties = parseJSONProperties(getString(v))
+			}
+			row.Close()
+			if !seen[n.ID] {
+				seen[n.ID] = true
+				nodes = append(nodes, n)
+			}
+		}
+		result.Close()
+	}
+	return nodes, nil
+}
+
+// SearchNodes は、名前または別名に text を含むノードを取得します。
+// properties の文字列に対する CONTAINS で候補を絞り込んだ後、名前と別名だけを対象に照合し直します。
+func (s *LadybugDBStorage) SearchNodes(ctx context.Context, text string, nodeType string, memoryGroup string) ([]*storage.Node, error) {
+	normalized := utils.NormalizeForGraph(text)
+	conditions := []string{
+		fmt.Sprintf("n.memory_group = '%s'", escapeString(memoryGroup)),
+		fmt.Sprintf("n.type <> '%s'", types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK),
+	}
+	if nodeType != "" {
+		conditions = append(conditions, fmt.Sprintf("n.type = '%s'", escapeString(nodeType)))
+	}
+	if normalized != "" {
+		conditions = append(conditions, fmt.Sprintf("(lower(n.id) CONTAINS '%s' OR lower(n.properties) CONTAINS '%s')", escapeString(normalized), escapeString(normalized)))
+	}
+	query := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE %s
+		RETURN n.id, n.type, n.properties
+		ORDER BY n.id
+	`, types.TABLE_NAME_GRAPH_NODE, strings.Join(conditions, " AND "))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("SearchNodes query failed: %w", err)
+	}
+	defer result.Close()
+	nodes := []*storage.Node{}
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		n := &storage.Node{MemoryGroup: memoryGroup}
+		if v, _ := row.GetValue(0); v != nil {
+			n.ID = utils.GetNameStrByGraphNodeID(getString(v)) // IDのメモリーグループを除去
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			n.Type = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			n.Properties = parseJSONProperties(getString(v))
+		}
+		row.Close()
+		if normalized != "" && !nodeNameOrAliasContains(n, normalized) {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// nodeNameOrAliasContains は、ノードの名前（ID）または別名のいずれかが、正規化済みの text を含むかどうかを返します。
+func nodeNameOrAliasContains(n *storage.Node, text string) bool {
+	if strings.Contains(utils.NormalizeForGraph(n.ID), text) {
+		return true
+	}
+	if aliases, ok := n.Properties["aliases"].([]any); ok { // エンティティ解決で記録された別名（resolution.ALIASES_PROPERTY）
+		for _, a := range aliases {
+			if alias, ok := a.(string); ok && strings.Contains(utils.NormalizeForGraph(alias), text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (s *LadybugDBStorage) GetNodesByEdge(ctx context.Context, targetID string, edgeType string, memoryGroup string) ([]*storage.Node, error) {
 	query := fmt.Sprintf(`
 		MATCH (a:%s {memory_group: '%s'})-[:%s {memory_group: '%s', type: '%s'}]->(b:%s {id: '%s', memory_group: '%s'})
@@ -1641,15 +2632,15 @@ func (s *LadybugDBStorage) UpdateEdgeWeight(ctx context.Context, sourceID, targe
 	return nil
 }
 
-func (s *LadybugDBStorage) UpdateEdgeMetrics(ctx context.Context, sourceID, targetID, memoryGroup string, weight, confidence float64, unix int64) error {
+func (s *LadybugDBStorage) UpdateEdgeMetrics(ctx context.Context, sourceID, edgeType, targetID, memoryGroup string, weight, confidence float64, unix int64) error {
 	// Reconstruct full IDs with memory group suffix
 	fullSourceID := utils.EnsureFullGraphNodeID(sourceID, memoryGroup)
 	fullTargetID := utils.EnsureFullGraphNodeID(targetID, memoryGroup)
 	query := fmt.Sprintf(`
-		MATCH (a:%s {id: '%s', memory_group: '%s'})-[r:%s {memory_group: '%s'}]->(b:%s {id: '%s', memory_group: '%s'})
+		MATCH (a:%s {id: '%s', memory_group: '%s'})-[r:%s {type: '%s', memory_group: '%s'}]->(b:%s {id: '%s', memory_group: '%s'})
 		SET r.weight = %f, r.confidence = %f, r.unix = %d
 	`, types.TABLE_NAME_GRAPH_NODE, escapeString(fullSourceID), escapeString(memoryGroup),
-		types.TABLE_NAME_GRAPH_EDGE, escapeString(memoryGroup),
+		types.TABLE_NAME_GRAPH_EDGE, escapeString(edgeType), escapeString(memoryGroup),
 		types.TABLE_NAME_GRAPH_NODE, escapeString(fullTargetID), escapeString(memoryGroup),
 		weight, confidence, unix)
 	conn := s.getConn(ctx)
@@ -1756,25 +2747,360 @@ func (s *LadybugDBStorage) GetEdgesByNode(ctx context.Context, nodeID string, me
 	return edges, nil
 }
 
-// GetMaxUnix は、指定されたメモリーグループ内のエッジの最大Unixタイムスタンプを取得します。
-func (s *LadybugDBStorage) GetMaxUnix(ctx context.Context, memoryGroup string) (int64, error) {
-	query := fmt.Sprintf(`
-		MATCH ()-[r:%s {memory_group: '%s'}]->()
-		RETURN max(r.unix) AS max_unix
-	`, types.TABLE_NAME_GRAPH_EDGE, escapeString(memoryGroup))
-	result, err := s.getConn(ctx).Query(query)
-	if err != nil {
-		return 0, fmt.Errorf("GetMaxUnix query failed: %w", err)
+// AddEdgeProvenances は、エッジの出典をEdgeProvenanceテーブルにUPSERTします。
+func (s *LadybugDBStorage) AddEdgeProvenances(ctx context.Context, provenances []*storage.EdgeProvenance) error {
+	if len(provenances) == 0 {
+		return nil
 	}
-	defer result.Close()
-	if result.HasNext() {
-		row, err := result.Next()
-		if err != nil {
-			return 0, err
-		}
-		defer row.Close()
-		if v, _ := row.GetValue(0); v != nil {
-			return getInt64(v), nil
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	for _, p := range provenances {
+		query := fmt.Sprintf(`
+			MERGE (p:%s {id: '%s'})
+			ON CREATE SET
+				p.memory_group = '%s',
+				p.source_id = '%s',
+				p.target_id = '%s',
+				p.type = '%s',
+				p.chunk_id = '%s',
+				p.document_id = '%s'
+			ON MATCH SET
+				p.memory_group = '%s',
+				p.source_id = '%s',
+				p.target_id = '%s',
+				p.type = '%s',
+				p.chunk_id = '%s',
+				p.document_id = '%s'
+		`,
+			types.TABLE_NAME_EDGE_PROVENANCE,
+			escapeString(p.ID),
+			// ON CREATE
+			escapeString(p.MemoryGroup),
+			escapeString(p.SourceID),
+			escapeString(p.TargetID),
+			escapeString(p.Type),
+			escapeString(p.ChunkID),
+			escapeString(p.DocumentID),
+			// ON MATCH
+			escapeString(p.MemoryGroup),
+			escapeString(p.SourceID),
+			escapeString(p.TargetID),
+			escapeString(p.Type),
+			escapeString(p.ChunkID),
+			escapeString(p.DocumentID),
+		)
+		if result, err := conn.Query(query); err != nil {
+			return fmt.Errorf("Failed to add edge provenance %s: %w", p.ID, err)
+		} else {
+			result.Close()
+		}
+	}
+	return nil
+}
+
+// GetEdgeProvenances は、EdgeProvenanceテーブルから指定エッジの出典を取得します。
+// ソース・ターゲットIDで候補を絞り込んだ後、（SourceID, Type, TargetID）の完全一致で選別します。
+func (s *LadybugDBStorage) GetEdgeProvenances(ctx context.Context, edges []*storage.Edge, memoryGroup string) ([]*storage.EdgeProvenance, error) {
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	edgeKeys := make(map[string]bool, len(edges))
+	sourceIDSet := make(map[string]bool)
+	targetIDSet := make(map[string]bool)
+	for _, e := range edges {
+		edgeKeys[e.SourceID+"|"+e.Type+"|"+e.TargetID] = true
+		sourceIDSet[e.SourceID] = true
+		targetIDSet[e.TargetID] = true
+	}
+	sourceIDs := make([]string, 0, len(sourceIDSet))
+	for id := range sourceIDSet {
+		sourceIDs = append(sourceIDs, id)
+	}
+	targetIDs := make([]string, 0, len(targetIDSet))
+	for id := range targetIDSet {
+		targetIDs = append(targetIDs, id)
+	}
+	query := fmt.Sprintf(`
+		MATCH (p:%s)
+		WHERE p.memory_group = '%s' AND p.source_id IN %s AND p.target_id IN %s
+		RETURN p.id, p.source_id, p.target_id, p.type, p.chunk_id, p.document_id
+	`, types.TABLE_NAME_EDGE_PROVENANCE, escapeString(memoryGroup), formatStringList(sourceIDs), formatStringList(targetIDs))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get edge provenances: %w", err)
+	}
+	defer result.Close()
+	var provenances []*storage.EdgeProvenance
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get next row: %w", err)
+		}
+		p := &storage.EdgeProvenance{MemoryGroup: memoryGroup}
+		if v, _ := row.GetValue(0); v != nil {
+			p.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			p.SourceID = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			p.TargetID = getString(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			p.Type = getString(v)
+		}
+		if v, _ := row.GetValue(4); v != nil {
+			p.ChunkID = getString(v)
+		}
+		if v, _ := row.GetValue(5); v != nil {
+			p.DocumentID = getString(v)
+		}
+		row.Close()
+		if edgeKeys[p.SourceID+"|"+p.Type+"|"+p.TargetID] {
+			provenances = append(provenances, p)
+		}
+	}
+	return provenances, nil
+}
+
+// RetractChunks は、チャンクに由来する出典を削除し、裏付けを失ったエッジとノードを整理します。
+// エッジごとに「取り消される出典数」と「残る出典数」を数え、残りが 0 なら削除、
+// 残りがあれば weight に 残り / (残り + 取り消し) を掛けて弱化します。
+// keepChunkIDs（新しい版のチャンク）の出典を持つエッジは、AddEdges の MERGE で weight が
+// 設定し直されているため弱化しません。
+func (s *LadybugDBStorage) RetractChunks(ctx context.Context, chunkIDs []string, keepChunkIDs []string, memoryGroup string) (*storage.RetractResult, error) {
+	res := &storage.RetractResult{}
+	if len(chunkIDs) == 0 {
+		return res, nil
+	}
+	if err := s.checkContext(ctx); err != nil {
+		return nil, err
+	}
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	exec := func(query string) error {
+		result, err := conn.Query(query)
+		if err != nil {
+			return err
+		}
+		result.Close()
+		return nil
+	}
+	chunkList := formatStringList(chunkIDs)
+	// ========================================
+	// 1. 取り消し対象の出典をエッジ単位で集計
+	// ========================================
+	type edgeKey struct {
+		sourceID, edgeType, targetID string
+	}
+	retracted := map[edgeKey]int64{}
+	query := fmt.Sprintf(`
+		MATCH (p:%s {memory_group: '%s'})
+		WHERE p.chunk_id IN %s
+		RETURN p.source_id, p.type, p.target_id, count(p)
+	`, types.TABLE_NAME_EDGE_PROVENANCE, escapeString(memoryGroup), chunkList)
+	result, err := conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("RetractChunks: Failed to collect provenances: %w", err)
+	}
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+		var key edgeKey
+		if v, _ := row.GetValue(0); v != nil {
+			key.sourceID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			key.edgeType = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			key.targetID = getString(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			retracted[key] = getInt64(v)
+		}
+		row.Close()
+	}
+	result.Close()
+	// 新しい版のチャンクが出典となっているエッジ（再度抽出されたエッジ）を収集
+	reasserted := map[edgeKey]bool{}
+	if len(keepChunkIDs) > 0 {
+		result, err := conn.Query(fmt.Sprintf(`
+			MATCH (p:%s {memory_group: '%s'})
+			WHERE p.chunk_id IN %s
+			RETURN DISTINCT p.source_id, p.type, p.target_id
+		`, types.TABLE_NAME_EDGE_PROVENANCE, escapeString(memoryGroup), formatStringList(keepChunkIDs)))
+		if err != nil {
+			return nil, fmt.Errorf("RetractChunks: Failed to collect reasserted edges: %w", err)
+		}
+		for result.HasNext() {
+			row, err := result.Next()
+			if err != nil {
+				result.Close()
+				return nil, err
+			}
+			var key edgeKey
+			if v, _ := row.GetValue(0); v != nil {
+				key.sourceID = getString(v)
+			}
+			if v, _ := row.GetValue(1); v != nil {
+				key.edgeType = getString(v)
+			}
+			if v, _ := row.GetValue(2); v != nil {
+				key.targetID = getString(v)
+			}
+			reasserted[key] = true
+			row.Close()
+		}
+		result.Close()
+	}
+	// ========================================
+	// 2. 出典を削除
+	// ========================================
+	if err := exec(fmt.Sprintf(`
+		MATCH (p:%s {memory_group: '%s'})
+		WHERE p.chunk_id IN %s
+		DELETE p
+	`, types.TABLE_NAME_EDGE_PROVENANCE, escapeString(memoryGroup), chunkList)); err != nil {
+		return nil, fmt.Errorf("RetractChunks: Failed to delete provenances: %w", err)
+	}
+	// ========================================
+	// 3. エッジの削除または弱化
+	// ========================================
+	orphanCandidates := map[string]bool{}
+	affected := map[string]bool{}
+	for key := range retracted {
+		if !affected[key.sourceID] {
+			affected[key.sourceID] = true
+			res.AffectedNodeIDs = append(res.AffectedNodeIDs, key.sourceID)
+		}
+		if !affected[key.targetID] {
+			affected[key.targetID] = true
+			res.AffectedNodeIDs = append(res.AffectedNodeIDs, key.targetID)
+		}
+	}
+	for key, removed := range retracted {
+		// 新しい版が再度抽出したエッジは、新しい版の weight をそのまま残す
+		if reasserted[key] {
+			continue
+		}
+		remaining := int64(0)
+		result, err := conn.Query(fmt.Sprintf(`
+			MATCH (p:%s {memory_group: '%s', source_id: '%s', type: '%s', target_id: '%s'})
+			RETURN count(p)
+		`, types.TABLE_NAME_EDGE_PROVENANCE, escapeString(memoryGroup), escapeString(key.sourceID), escapeString(key.edgeType), escapeString(key.targetID)))
+		if err != nil {
+			return nil, fmt.Errorf("RetractChunks: Failed to count remaining provenances: %w", err)
+		}
+		if result.HasNext() {
+			if row, err := result.Next(); err == nil {
+				v, _ := row.GetValue(0)
+				remaining = getInt64(v)
+				row.Close()
+			}
+		}
+		result.Close()
+		match := fmt.Sprintf(`
+			MATCH (a:%s {id: '%s', memory_group: '%s'})-[r:%s {type: '%s', memory_group: '%s'}]->(b:%s {id: '%s', memory_group: '%s'})
+		`, types.TABLE_NAME_GRAPH_NODE, escapeString(key.sourceID), escapeString(memoryGroup),
+			types.TABLE_NAME_GRAPH_EDGE, escapeString(key.edgeType), escapeString(memoryGroup),
+			types.TABLE_NAME_GRAPH_NODE, escapeString(key.targetID), escapeString(memoryGroup))
+		if remaining == 0 {
+			if err := exec(match + "DELETE r"); err != nil {
+				return nil, fmt.Errorf("RetractChunks: Failed to delete edge %s->%s: %w", key.sourceID, key.targetID, err)
+			}
+			orphanCandidates[key.sourceID] = true
+			orphanCandidates[key.targetID] = true
+			res.DeletedEdges++
+			continue
+		}
+		ratio := float64(remaining) / float64(remaining+removed)
+		if err := exec(match + fmt.Sprintf("SET r.weight = r.weight * %f", ratio)); err != nil {
+			return nil, fmt.Errorf("RetractChunks: Failed to weaken edge %s->%s: %w", key.sourceID, key.targetID, err)
+		}
+		res.WeakenedEdges++
+	}
+	// ========================================
+	// 4. チャンクノードと孤立ノードの削除
+	// ========================================
+	if err := exec(fmt.Sprintf(`
+		MATCH (n:%s {memory_group: '%s', type: '%s'})
+		WHERE n.id IN %s
+		DETACH DELETE n
+	`, types.TABLE_NAME_GRAPH_NODE, escapeString(memoryGroup), types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK, chunkList)); err != nil {
+		return nil, fmt.Errorf("RetractChunks: Failed to delete chunk nodes: %w", err)
+	}
+	if len(orphanCandidates) > 0 {
+		candidates := make([]string, 0, len(orphanCandidates))
+		for id := range orphanCandidates {
+			candidates = append(candidates, id)
+		}
+		candidateList := formatStringList(candidates)
+		result, err := conn.Query(fmt.Sprintf(`
+			MATCH (n:%s {memory_group: '%s'})
+			WHERE n.id IN %s
+			  AND NOT EXISTS { MATCH (n)-[]->() }
+			  AND NOT EXISTS { MATCH ()-[]->(n) }
+			RETURN n.id
+		`, types.TABLE_NAME_GRAPH_NODE, escapeString(memoryGroup), candidateList))
+		if err != nil {
+			return nil, fmt.Errorf("RetractChunks: Failed to find orphan nodes: %w", err)
+		}
+		for result.HasNext() {
+			row, err := result.Next()
+			if err != nil {
+				result.Close()
+				return nil, err
+			}
+			if v, _ := row.GetValue(0); v != nil {
+				res.OrphanNodeIDs = append(res.OrphanNodeIDs, getString(v))
+			}
+			row.Close()
+		}
+		result.Close()
+		if len(res.OrphanNodeIDs) > 0 {
+			if err := exec(fmt.Sprintf(`
+				MATCH (n:%s {memory_group: '%s'})
+				WHERE n.id IN %s
+				DETACH DELETE n
+			`, types.TABLE_NAME_GRAPH_NODE, escapeString(memoryGroup), formatStringList(res.OrphanNodeIDs))); err != nil {
+				return nil, fmt.Errorf("RetractChunks: Failed to delete orphan nodes: %w", err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// GetMaxUnix は、指定されたメモリーグループ内のエッジの最大Unixタイムスタンプを取得します。
+func (s *LadybugDBStorage) GetMaxUnix(ctx context.Context, memoryGroup string) (int64, error) {
+	query := fmt.Sprintf(`
+		MATCH ()-[r:%s {memory_group: '%s'}]->()
+		RETURN max(r.unix) AS max_unix
+	`, types.TABLE_NAME_GRAPH_EDGE, escapeString(memoryGroup))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("GetMaxUnix query failed: %w", err)
+	}
+	defer result.Close()
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return 0, err
+		}
+		defer row.Close()
+		if v, _ := row.GetValue(0); v != nil {
+			return getInt64(v), nil
 		}
 	}
 	return 0, nil // エッジがない場合は0を返す
@@ -1866,6 +3192,526 @@ func (s *LadybugDBStorage) UpsertMemoryGroup(ctx context.Context, config *storag
 	return nil
 }
 
+// GetPromptOverrides は、指定されたメモリーグループのプロンプトの上書きを取得します。
+func (s *LadybugDBStorage) GetPromptOverrides(ctx context.Context, memoryGroup string, activeOnly bool) ([]*storage.PromptOverride, error) {
+	where := ""
+	if activeOnly {
+		where = "AND p.is_active = true"
+	}
+	query := fmt.Sprintf(`
+		MATCH (p:%s)
+		WHERE p.memory_group = '%s' %s
+		RETURN p.memory_group, p.name, p.version, p.content, p.is_active, p.created_at
+		ORDER BY p.name, p.version
+	`, types.TABLE_NAME_PROMPT_OVERRIDE, escapeString(memoryGroup), where)
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("GetPromptOverrides query failed: %w", err)
+	}
+	defer result.Close()
+	overrides := []*storage.PromptOverride{}
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		o := &storage.PromptOverride{}
+		if v, _ := row.GetValue(0); v != nil {
+			o.MemoryGroup = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			o.Name = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			o.Version = getInt64(v)
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			o.Content = getString(v)
+		}
+		if v, _ := row.GetValue(4); v != nil {
+			o.IsActive, _ = v.(bool)
+		}
+		if v, _ := row.GetValue(5); v != nil {
+			o.CreatedAt = parseTimestamp(v)
+		}
+		row.Close()
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// SavePromptOverride は、プロンプトの上書きを新しいバージョンとして保存し、有効にします。
+// プロンプトの内容には任意の文字が含まれるため、パラメータとして渡します。
+func (s *LadybugDBStorage) SavePromptOverride(ctx context.Context, memoryGroup string, name string, content string) (*storage.PromptOverride, error) {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	// 1. 次のバージョンを決める
+	version := int64(1)
+	result, err := conn.Query(fmt.Sprintf(`
+		MATCH (p:%s)
+		WHERE p.memory_group = '%s' AND p.name = '%s'
+		RETURN max(p.version)
+	`, types.TABLE_NAME_PROMPT_OVERRIDE, escapeString(memoryGroup), escapeString(name)))
+	if err != nil {
+		return nil, fmt.Errorf("SavePromptOverride: Failed to get latest version: %w", err)
+	}
+	if result.HasNext() {
+		if row, err := result.Next(); err == nil {
+			if v, _ := row.GetValue(0); v != nil {
+				version = getInt64(v) + 1
+			}
+			row.Close()
+		}
+	}
+	result.Close()
+	// 2. 以前のバージョンを無効にする
+	if result, err := conn.Query(fmt.Sprintf(`
+		MATCH (p:%s)
+		WHERE p.memory_group = '%s' AND p.name = '%s' AND p.is_active = true
+		SET p.is_active = false
+	`, types.TABLE_NAME_PROMPT_OVERRIDE, escapeString(memoryGroup), escapeString(name))); err != nil {
+		return nil, fmt.Errorf("SavePromptOverride: Failed to deactivate previous version: %w", err)
+	} else {
+		result.Close()
+	}
+	// 3. 新しいバージョンを保存する
+	now := common.GetNow()
+	stmt, err := conn.Prepare(fmt.Sprintf(`
+		CREATE (p:%s {id: $id, memory_group: $memory_group, name: $name, version: $version, content: $content, is_active: true, created_at: timestamp($created_at)})
+	`, types.TABLE_NAME_PROMPT_OVERRIDE))
+	if err != nil {
+		return nil, fmt.Errorf("SavePromptOverride: Failed to prepare query: %w", err)
+	}
+	defer stmt.Close()
+	result, err = conn.Execute(stmt, map[string]any{
+		"id":           fmt.Sprintf("%s|%s|%d", memoryGroup, name, version),
+		"memory_group": memoryGroup,
+		"name":         name,
+		"version":      version,
+		"content":      content,
+		"created_at":   now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SavePromptOverride failed: %w", err)
+	}
+	result.Close()
+	return &storage.PromptOverride{
+		MemoryGroup: memoryGroup,
+		Name:        name,
+		Version:     version,
+		Content:     content,
+		IsActive:    true,
+		CreatedAt:   now,
+	}, nil
+}
+
+// DeactivatePromptOverride は、プロンプトの上書きを無効にし、デフォルトのプロンプトに戻します。
+func (s *LadybugDBStorage) DeactivatePromptOverride(ctx context.Context, memoryGroup string, name string) (bool, error) {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	result, err := conn.Query(fmt.Sprintf(`
+		MATCH (p:%s)
+		WHERE p.memory_group = '%s' AND p.name = '%s' AND p.is_active = true
+		SET p.is_active = false
+		RETURN count(p)
+	`, types.TABLE_NAME_PROMPT_OVERRIDE, escapeString(memoryGroup), escapeString(name)))
+	if err != nil {
+		return false, fmt.Errorf("DeactivatePromptOverride failed: %w", err)
+	}
+	defer result.Close()
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return false, err
+		}
+		defer row.Close()
+		if v, _ := row.GetValue(0); v != nil {
+			return getInt64(v) > 0, nil
+		}
+	}
+	return false, nil
+}
+
+// GetOntology は、指定されたメモリーグループのオントロジー定義（JSON）を取得します。
+func (s *LadybugDBStorage) GetOntology(ctx context.Context, memoryGroup string) (string, error) {
+	query := fmt.Sprintf(`
+		MATCH (o:%s {id: '%s'})
+		RETURN o.definition
+	`, types.TABLE_NAME_ONTOLOGY, escapeString(memoryGroup))
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return "", fmt.Errorf("GetOntology query failed: %w", err)
+	}
+	defer result.Close()
+	if result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return "", err
+		}
+		defer row.Close()
+		if v, _ := row.GetValue(0); v != nil {
+			return getString(v), nil
+		}
+	}
+	return "", nil
+}
+
+// SaveOntology は、メモリーグループのオントロジー定義（JSON）を保存します。
+// 定義には任意の文字が含まれるため、パラメータとして渡します。
+func (s *LadybugDBStorage) SaveOntology(ctx context.Context, memoryGroup string, definition string) error {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	stmt, err := conn.Prepare(fmt.Sprintf(`
+		MERGE (o:%s {id: $id})
+		SET o.definition = $definition, o.updated_at = timestamp($updated_at)
+	`, types.TABLE_NAME_ONTOLOGY))
+	if err != nil {
+		return fmt.Errorf("SaveOntology: Failed to prepare query: %w", err)
+	}
+	defer stmt.Close()
+	result, err := conn.Execute(stmt, map[string]any{
+		"id":         memoryGroup,
+		"definition": definition,
+		"updated_at": common.GetNow().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("SaveOntology failed: %w", err)
+	}
+	result.Close()
+	return nil
+}
+
+// DeleteOntology は、メモリーグループのオントロジー定義を削除します。
+func (s *LadybugDBStorage) DeleteOntology(ctx context.Context, memoryGroup string) (bool, error) {
+	definition, err := s.GetOntology(ctx, memoryGroup)
+	if err != nil {
+		return false, err
+	}
+	if definition == "" {
+		return false, nil
+	}
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	result, err := conn.Query(fmt.Sprintf(`
+		MATCH (o:%s {id: '%s'})
+		DELETE o
+	`, types.TABLE_NAME_ONTOLOGY, escapeString(memoryGroup)))
+	if err != nil {
+		return false, fmt.Errorf("DeleteOntology failed: %w", err)
+	}
+	result.Close()
+	return true, nil
+}
+
+// GetCommunities は、指定されたメモリーグループのコミュニティを取得します。
+func (s *LadybugDBStorage) GetCommunities(ctx context.Context, memoryGroup string, level int) ([]*storage.Community, error) {
+	where := ""
+	if level >= 0 {
+		where = fmt.Sprintf("AND c.level = %d", level)
+	}
+	query := fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.memory_group = '%s' %s
+		RETURN c.id, c.memory_group, c.level, c.parent_id, c.title, c.summary, c.member_ids, c.weight, c.signature, c.updated_at
+		ORDER BY c.level, c.weight DESC, c.id
+	`, types.TABLE_NAME_COMMUNITY, escapeString(memoryGroup), where)
+	result, err := s.getConn(ctx).Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommunities query failed: %w", err)
+	}
+	defer result.Close()
+	communities := []*storage.Community{}
+	for result.HasNext() {
+		row, err := result.Next()
+		if err != nil {
+			return nil, err
+		}
+		c := &storage.Community{}
+		if v, _ := row.GetValue(0); v != nil {
+			c.ID = getString(v)
+		}
+		if v, _ := row.GetValue(1); v != nil {
+			c.MemoryGroup = getString(v)
+		}
+		if v, _ := row.GetValue(2); v != nil {
+			c.Level = int(getInt64(v))
+		}
+		if v, _ := row.GetValue(3); v != nil {
+			c.ParentID = getString(v)
+		}
+		if v, _ := row.GetValue(4); v != nil {
+			c.Title = getString(v)
+		}
+		if v, _ := row.GetValue(5); v != nil {
+			c.Summary = getString(v)
+		}
+		if v, _ := row.GetValue(6); v != nil {
+			if err := json.Unmarshal([]byte(getString(v)), &c.MemberIDs); err != nil {
+				row.Close()
+				return nil, fmt.Errorf("GetCommunities: Failed to parse member_ids of %s: %w", c.ID, err)
+			}
+		}
+		if v, _ := row.GetValue(7); v != nil {
+			c.Weight = getFloat64(v)
+		}
+		if v, _ := row.GetValue(8); v != nil {
+			c.Signature = getString(v)
+		}
+		if v, _ := row.GetValue(9); v != nil {
+			c.UpdatedAt = parseTimestamp(v)
+		}
+		row.Close()
+		communities = append(communities, c)
+	}
+	return communities, nil
+}
+
+// ReplaceCommunities は、メモリーグループのコミュニティを全て削除し、指定されたコミュニティで置き換えます。
+// タイトルと要約には任意の文字が含まれるため、パラメータとして渡します。
+func (s *LadybugDBStorage) ReplaceCommunities(ctx context.Context, memoryGroup string, communities []*storage.Community) error {
+	conn := s.getConn(ctx)
+	if conn == s.conn {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	// 1. 既存のコミュニティを削除する
+	if result, err := conn.Query(fmt.Sprintf(`
+		MATCH (c:%s)
+		WHERE c.memory_group = '%s'
+		DELETE c
+	`, types.TABLE_NAME_COMMUNITY, escapeString(memoryGroup))); err != nil {
+		return fmt.Errorf("ReplaceCommunities: Failed to delete communities: %w", err)
+	} else {
+		result.Close()
+	}
+	if len(communities) == 0 {
+		return nil
+	}
+	// 2. 新しいコミュニティを保存する
+	stmt, err := conn.Prepare(fmt.Sprintf(`
+		CREATE (c:%s {id: $id, memory_group: $memory_group, level: $level, parent_id: $parent_id, title: $title, summary: $summary, member_ids: $member_ids, weight: $weight, signature: $signature, updated_at: timestamp($updated_at)})
+	`, types.TABLE_NAME_COMMUNITY))
+	if err != nil {
+		return fmt.Errorf("ReplaceCommunities: Failed to prepare query: %w", err)
+	}
+	defer stmt.Close()
+	for _, c := range communities {
+		memberIDs, err := json.Marshal(c.MemberIDs)
+		if err != nil {
+			return fmt.Errorf("ReplaceCommunities: Failed to marshal member_ids of %s: %w", c.ID, err)
+		}
+		result, err := conn.Execute(stmt, map[string]any{
+			"id":           c.ID,
+			"memory_group": memoryGroup,
+			"level":        int64(c.Level),
+			"parent_id":    c.ParentID,
+			"title":        c.Title,
+			"summary":      c.Summary,
+			"member_ids":   string(memberIDs),
+			"weight":       c.Weight,
+			"signature":    c.Signature,
+			"updated_at":   c.UpdatedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return fmt.Errorf("ReplaceCommunities: Failed to save community %s: %w", c.ID, err)
+		}
+		result.Close()
+	}
+	return nil
+}
+
+// FindPaths は、2つのエンティティ間の経路を、ホップ数の少ない順に最大 query.K 件取得します。
+// 起点と終点の双方から幅優先でエッジを収集し（起点側から ceil(MaxHops/2) 回、終点側から floor(MaxHops/2) 回）、
+// 収集したエッジの中から utils.KShortestPaths で経路を求めます。MaxHops 以内の経路を構成するエッジは、必ずどちらかの側から収集されます。
+// 探索するノード数の上限（PATH_MAX_EXPLORED_NODES）は起点側・終点側のそれぞれに適用するため、
+// 起点側が密なグラフで上限に達しても、終点側からの探索は妨げられません。
+// 上限に達した側はそれ以上探索を広げないため、その場合は MaxHops 以内の経路でも見つからないことがあります。
+// 引数:
+//   - ctx: コンテキスト
+//   - query: 経路検索の条件
+//   - memoryGroup: メモリーグループ
+//
+// 返り値:
+//   - []*storage.Path: 経路（各エッジの Thickness を設定済み）
+//   - error: エラー
+func (s *LadybugDBStorage) FindPaths(ctx context.Context, query storage.PathQuery, memoryGroup string) ([]*storage.Path, error) {
+	if err := s.checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if query.K <= 0 || query.MaxHops <= 0 || query.SourceID == query.TargetID {
+		return nil, nil
+	}
+	// Thickness 計算の準備
+	maxUnix, err := s.GetMaxUnix(ctx, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("FindPaths: Failed to get max unix: %w", err)
+	}
+	if maxUnix == 0 {
+		return nil, nil // エッジがない
+	}
+	halfLifeDays := appconfig.DEFAULT_HALF_LIFE_DAYS
+	if groupConfig, _ := s.GetMemoryGroupConfig(ctx, memoryGroup); groupConfig != nil && groupConfig.HalfLifeDays > 0 {
+		halfLifeDays = groupConfig.HalfLifeDays
+	}
+	lambda := utils.CalculateLambda(halfLifeDays)
+	edgeTypes := make(map[string]bool, len(query.EdgeTypes))
+	for _, t := range query.EdgeTypes {
+		edgeTypes[t] = true
+	}
+	// 起点側・終点側から幅優先でエッジを収集
+	collected := make(map[string]utils.ScoredTriple)
+	starts := [2]string{query.SourceID, query.TargetID}
+	expansions := [2]int{(query.MaxHops + 1) / 2, query.MaxHops / 2}
+	for side := range starts {
+		visited := map[string]bool{starts[side]: true}
+		exploredCount := 1 // この側で探索したノード数
+		frontier := []string{starts[side]}
+		for step := 0; step < expansions[side] && len(frontier) > 0; step++ {
+			if err := s.checkContext(ctx); err != nil {
+				return nil, err
+			}
+			triples, err := s.GetTriples(ctx, frontier, memoryGroup)
+			if err != nil {
+				return nil, fmt.Errorf("FindPaths: Failed to get triples: %w", err)
+			}
+			var next []string
+			for _, triple := range triples {
+				if len(edgeTypes) > 0 && !edgeTypes[triple.Edge.Type] {
+					continue
+				}
+				thickness := utils.CalculateThickness(triple.Edge.Weight, triple.Edge.Confidence, triple.Edge.Unix, maxUnix, lambda)
+				if thickness < query.ThicknessThreshold {
+					continue
+				}
+				key := triple.Edge.SourceID + "|" + triple.Edge.Type + "|" + triple.Edge.TargetID
+				if _, ok := collected[key]; !ok {
+					triple.Edge.Thickness = thickness
+					collected[key] = utils.ScoredTriple{Triple: triple, Thickness: thickness}
+				}
+				for _, id := range []string{triple.Source.ID, triple.Target.ID} {
+					if !visited[id] && exploredCount < appconfig.PATH_MAX_EXPLORED_NODES {
+						visited[id] = true
+						exploredCount++
+						next = append(next, id)
+					}
+				}
+			}
+			frontier = next
+		}
+	}
+	// 収集したエッジから経路を求める（キー順に並べて結果を決定的にする）
+	keys := make([]string, 0, len(collected))
+	for key := range collected {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	scored := make([]utils.ScoredTriple, 0, len(keys))
+	for _, key := range keys {
+		scored = append(scored, collected[key])
+	}
+	var paths []*storage.Path
+	for _, p := range utils.KShortestPaths(scored, query.SourceID, query.TargetID, query.K, query.MaxHops) {
+		path := &storage.Path{NodeIDs: p.NodeIDs, Hops: len(p.Triples), Thickness: p.Thickness}
+		for _, st := range p.Triples {
+			path.Triples = append(path.Triples, st.Triple)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ExecuteReadOnlyQuery は、検証・スコープ済みの読み取り専用 Cypher クエリを実行し、表形式で返します。
+// クエリは専用の接続で開始した読み取り専用トランザクション内で実行するため、
+// tools/cypher の検証をすり抜けた更新系のクエリもデータベースによって拒否されます。
+// トランザクション内（Query 等）から呼ばれた場合も専用の接続を使用するため、参照できるのはコミット済みのデータです。
+// コンテキストがキャンセルされた場合は、実行中のクエリを中断します。
+// 引数:
+//   - ctx: コンテキスト
+//   - query: 実行する Cypher クエリ（tools/cypher で検証・スコープ済みであること）
+//   - maxRows: 返却する最大行数（超過分は切り捨てられ Truncated が true になる）
+//   - timeoutMs: クエリのタイムアウト（ミリ秒、0 はタイムアウトなし）
+//
+// 返り値:
+//   - *storage.CypherResult: 実行結果
+//   - error: エラー
+func (s *LadybugDBStorage) ExecuteReadOnlyQuery(ctx context.Context, query string, maxRows int, timeoutMs uint64) (*storage.CypherResult, error) {
+	if err := s.checkContext(ctx); err != nil {
+		return nil, err
+	}
+	conn, err := ladybug.OpenConnection(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("ExecuteReadOnlyQuery: failed to open connection: %w", err)
+	}
+	defer conn.Close()
+	if result, err := conn.Query("BEGIN TRANSACTION READ ONLY"); err != nil {
+		return nil, fmt.Errorf("ExecuteReadOnlyQuery: failed to begin read-only transaction: %w", err)
+	} else {
+		result.Close()
+	}
+	defer func() {
+		if res, rerr := conn.Query("ROLLBACK"); rerr == nil {
+			res.Close()
+		}
+	}()
+	conn.SetTimeout(timeoutMs)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Interrupt()
+		case <-done:
+		}
+	}()
+	result, err := conn.Query(query)
+	if err != nil {
+		if ctxErr := s.checkContext(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("ExecuteReadOnlyQuery: query failed: %w", err)
+	}
+	defer result.Close()
+	cypherResult := &storage.CypherResult{
+		Columns: result.GetColumnNames(),
+		Rows:    [][]any{},
+	}
+	numColumns := uint64(len(cypherResult.Columns))
+	for result.HasNext() {
+		if len(cypherResult.Rows) >= maxRows {
+			cypherResult.Truncated = true
+			break
+		}
+		row, err := result.Next()
+		if err != nil {
+			return nil, fmt.Errorf("ExecuteReadOnlyQuery: failed to read row: %w", err)
+		}
+		values := make([]any, numColumns)
+		for i := uint64(0); i < numColumns; i++ {
+			v, err := row.GetValue(i)
+			if err != nil {
+				row.Close()
+				return nil, fmt.Errorf("ExecuteReadOnlyQuery: failed to read value: %w", err)
+			}
+			values[i] = toCypherResultValue(v)
+		}
+		row.Close()
+		cypherResult.Rows = append(cypherResult.Rows, values)
+	}
+	return cypherResult, nil
+}
+
 // ---------------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------------
@@ -1874,6 +3720,62 @@ func escapeString(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
 
+// formatStringList は、文字列スライスを Cypher のリストリテラル（['a', 'b']）に変換します。
+func formatStringList(values []string) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i, v := range values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("'%s'", escapeString(v)))
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// dataReturnColumns は、Data を取得するクエリの RETURN 句です（parseDataRow の列順と対応）。
+const dataReturnColumns = "d.id, d.memory_group, d.name, d.raw_data_location, d.original_data_location, d.extension, d.mime_type, d.content_hash, d.owner_id, d.created_at, d.source_id"
+
+// parseDataRow は、dataReturnColumns の順で取得した行を Data に変換します。
+func parseDataRow(row *ladybug.FlatTuple) *storage.Data {
+	data := &storage.Data{}
+	if v, _ := row.GetValue(0); v != nil {
+		data.ID = getString(v)
+	}
+	if v, _ := row.GetValue(1); v != nil {
+		data.MemoryGroup = getString(v)
+	}
+	if v, _ := row.GetValue(2); v != nil {
+		data.Name = getString(v)
+	}
+	if v, _ := row.GetValue(3); v != nil {
+		data.RawDataLocation = getString(v)
+	}
+	if v, _ := row.GetValue(4); v != nil {
+		data.OriginalDataLocation = getString(v)
+	}
+	if v, _ := row.GetValue(5); v != nil {
+		data.Extension = getString(v)
+	}
+	if v, _ := row.GetValue(6); v != nil {
+		data.MimeType = getString(v)
+	}
+	if v, _ := row.GetValue(7); v != nil {
+		data.ContentHash = getString(v)
+	}
+	if v, _ := row.GetValue(8); v != nil {
+		data.OwnerID = getString(v)
+	}
+	if v, _ := row.GetValue(9); v != nil {
+		data.CreatedAt = parseTimestamp(v)
+	}
+	if v, _ := row.GetValue(10); v != nil {
+		data.SourceID = getString(v)
+	}
+	return data
+}
+
 func formatVectorForLadybugDB(vector []float32) string {
 	var sb strings.Builder
 	sb.WriteString("[")
@@ -1943,3 +3845,60 @@ func parseJSONProperties(s string) map[string]interface{} {
 	json.Unmarshal([]byte(s), &m)
 	return m
 }
+
+// toCypherResultValue は、クエリ結果の値を JSON に変換可能な形に変換します。
+// ノード・リレーションはラベルとプロパティのマップになり、embedding プロパティは除外されます。
+func toCypherResultValue(v any) any {
+	switch val := v.(type) {
+	case ladybug.Node:
+		return graphElementToMap(val.Label, val.Properties)
+	case ladybug.Relationship:
+		return graphElementToMap(val.Label, val.Properties)
+	case ladybug.RecursiveRelationship:
+		nodes := make([]any, 0, len(val.Nodes))
+		for _, n := range val.Nodes {
+			nodes = append(nodes, graphElementToMap(n.Label, n.Properties))
+		}
+		rels := make([]any, 0, len(val.Relationships))
+		for _, r := range val.Relationships {
+			rels = append(rels, graphElementToMap(r.Label, r.Properties))
+		}
+		return map[string]any{"nodes": nodes, "rels": rels}
+	case ladybug.InternalID:
+		return fmt.Sprintf("%d:%d", val.TableID, val.Offset)
+	case []any:
+		list := make([]any, 0, len(val))
+		for _, item := range val {
+			list = append(list, toCypherResultValue(item))
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, item := range val {
+			m[k] = toCypherResultValue(item)
+		}
+		return m
+	case []ladybug.MapItem:
+		m := make(map[string]any, len(val))
+		for _, item := range val {
+			m[fmt.Sprint(item.Key)] = toCypherResultValue(item.Value)
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+// graphElementToMap は、ノード・リレーションのプロパティを _label 付きのマップに変換します。
+// 内部ID（_id, _src, _dst）と embedding は除外されます。
+func graphElementToMap(label string, properties map[string]any) map[string]any {
+	m := make(map[string]any, len(properties)+1)
+	m["_label"] = label
+	for k, v := range properties {
+		if k == "embedding" || k == "_id" || k == "_src" || k == "_dst" {
+			continue
+		}
+		m[k] = toCypherResultValue(v)
+	}
+	return m
+}
@@ -0,0 +1,325 @@
+package ladybugdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"go.uber.org/zap"
+)
+
+const testMemoryGroup = "g1"
+
+// newTestStorage は、メモリ上のデータベースにスキーマを作成したストレージを返します。
+func newTestStorage(t *testing.T) *LadybugDBStorage {
+	t.Helper()
+	s, err := NewLadybugDBStorage(":memory:", nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewLadybugDBStorage failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := s.EnsureSchema(context.Background(), types.EmbeddingModelConfig{Dimension: 4}); err != nil {
+		t.Fatalf("EnsureSchema failed: %v", err)
+	}
+	return s
+}
+
+// mustExec は、テストの前提となるデータを作成するクエリを実行します。
+func mustExec(t *testing.T, s *LadybugDBStorage, query string) {
+	t.Helper()
+	result, err := s.conn.Query(query)
+	if err != nil {
+		t.Fatalf("Query failed: %v\n%s", err, query)
+	}
+	result.Close()
+}
+
+// mustQueryIDs は、1列目の値を並べ替えて返します。
+func mustQueryIDs(t *testing.T, s *LadybugDBStorage, query string) []string {
+	t.Helper()
+	ids, err := queryIDs(s.conn, query)
+	if err != nil {
+		t.Fatalf("Query failed: %v\n%s", err, query)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// TestSaveChunkKeepsRelations は、既存のチャンクを保存し直しても、
+// ドキュメントとのリレーションと前後のチャンクとのリレーションが残ることを確認します。
+func TestSaveChunkKeepsRelations(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	if err := s.SaveDocument(ctx, &storage.Document{ID: "doc1", MemoryGroup: testMemoryGroup, DataID: "data1", Text: "c1 c2 c3"}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	for i, id := range []string{"c1", "c2", "c3"} {
+		chunk := &storage.Chunk{ID: id, MemoryGroup: testMemoryGroup, DocumentID: "doc1", Text: id, ChunkIndex: i, Embedding: []float32{1, 0, 0, 0}}
+		if err := s.SaveChunk(ctx, chunk); err != nil {
+			t.Fatalf("SaveChunk failed: %v", err)
+		}
+	}
+	mustExec(t, s, `MATCH (a:Chunk {id: 'c1'}), (b:Chunk {id: 'c2'}) CREATE (a)-[:NEXT_CHUNK {memory_group: 'g1'}]->(b)`)
+	mustExec(t, s, `MATCH (a:Chunk {id: 'c2'}), (b:Chunk {id: 'c3'}) CREATE (a)-[:NEXT_CHUNK {memory_group: 'g1'}]->(b)`)
+
+	// 埋め込みを更新するため、c2 を保存し直す
+	updated := &storage.Chunk{ID: "c2", MemoryGroup: testMemoryGroup, DocumentID: "doc1", Text: "c2 updated", ChunkIndex: 1, Embedding: []float32{0, 1, 0, 0}}
+	if err := s.SaveChunk(ctx, updated); err != nil {
+		t.Fatalf("SaveChunk failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "updated text",
+			query: `MATCH (c:Chunk {id: 'c2'}) RETURN c.text`,
+			want:  []string{"c2 updated"},
+		},
+		{
+			name:  "document",
+			query: `MATCH (d:Document)-[:HAS_CHUNK]->(c:Chunk {id: 'c2'}) RETURN d.id`,
+			want:  []string{"doc1"},
+		},
+		{
+			name:  "previous chunk",
+			query: `MATCH (p:Chunk)-[:NEXT_CHUNK]->(c:Chunk {id: 'c2'}) RETURN p.id`,
+			want:  []string{"c1"},
+		},
+		{
+			name:  "next chunk",
+			query: `MATCH (c:Chunk {id: 'c2'})-[:NEXT_CHUNK]->(n:Chunk) RETURN n.id`,
+			want:  []string{"c3"},
+		},
+		{
+			name:  "relations are not duplicated",
+			query: `MATCH (c:Chunk {id: 'c2'})-[r]-(o) RETURN o.id`,
+			want:  []string{"c1", "c3", "doc1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustQueryIDs(t, s, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("Unexpected result: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSaveEmbeddingScopedByMemoryGroup は、埋め込みの保存し直しが同じメモリーグループの行だけを置き換え、
+// 他のメモリーグループの同じIDの行を削除しないことを確認します。
+func TestSaveEmbeddingScopedByMemoryGroup(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	steps := []struct {
+		name        string
+		memoryGroup string
+		text        string
+		wantErr     bool
+		want        []string // 保存後の g1 の e1 の text
+	}{
+		{name: "create", memoryGroup: testMemoryGroup, text: "first", want: []string{"first"}},
+		{name: "replace in the same memory group", memoryGroup: testMemoryGroup, text: "second", want: []string{"second"}},
+		{name: "same id in another memory group", memoryGroup: "g2", text: "other", wantErr: true, want: []string{"second"}},
+	}
+	for _, step := range steps {
+		err := s.SaveEmbedding(ctx, types.TABLE_NAME_ENTITY, "e1", step.text, []float32{1, 0, 0, 0}, step.memoryGroup)
+		if (err != nil) != step.wantErr {
+			t.Fatalf("%s: SaveEmbedding error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		got := mustQueryIDs(t, s, `MATCH (e:Entity {id: 'e1', memory_group: 'g1'}) RETURN e.text`)
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: got %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+// addTestProvenanceGraph は、alice -> acme（c1 と c2 が出典）と bob -> acme（c1 だけが出典）のグラフを作成します。
+func addTestProvenanceGraph(t *testing.T, s *LadybugDBStorage) {
+	t.Helper()
+	ctx := context.Background()
+	nodes := []*storage.Node{
+		{ID: "alice", MemoryGroup: testMemoryGroup, Type: "Person", Properties: map[string]any{"name": "Alice"}},
+		{ID: "bob", MemoryGroup: testMemoryGroup, Type: "Person", Properties: map[string]any{"name": "Bob"}},
+		{ID: "acme", MemoryGroup: testMemoryGroup, Type: "Organization", Properties: map[string]any{"name": "Acme"}},
+	}
+	if err := s.AddNodes(ctx, nodes); err != nil {
+		t.Fatalf("AddNodes failed: %v", err)
+	}
+	edges := []*storage.Edge{
+		{SourceID: "alice", TargetID: "acme", MemoryGroup: testMemoryGroup, Type: "WORKS_AT", Weight: 0.8, Confidence: 1},
+		{SourceID: "bob", TargetID: "acme", MemoryGroup: testMemoryGroup, Type: "WORKS_AT", Weight: 0.8, Confidence: 1},
+	}
+	if err := s.AddEdges(ctx, edges); err != nil {
+		t.Fatalf("AddEdges failed: %v", err)
+	}
+	provenances := []*storage.EdgeProvenance{
+		{ID: "p1", MemoryGroup: testMemoryGroup, SourceID: "alice", TargetID: "acme", Type: "WORKS_AT", ChunkID: "c1", DocumentID: "doc1"},
+		{ID: "p2", MemoryGroup: testMemoryGroup, SourceID: "alice", TargetID: "acme", Type: "WORKS_AT", ChunkID: "c2", DocumentID: "doc2"},
+		{ID: "p3", MemoryGroup: testMemoryGroup, SourceID: "bob", TargetID: "acme", Type: "WORKS_AT", ChunkID: "c1", DocumentID: "doc1"},
+	}
+	if err := s.AddEdgeProvenances(ctx, provenances); err != nil {
+		t.Fatalf("AddEdgeProvenances failed: %v", err)
+	}
+}
+
+// TestRetractChunksKeepsSharedEntities は、チャンクを取り消したとき、他のチャンクでも裏付けられているエッジは
+// 弱化して残り、裏付けを失ったエッジとそれにより孤立したノードだけが削除されることを確認します。
+func TestRetractChunksKeepsSharedEntities(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	addTestProvenanceGraph(t, s)
+
+	res, err := s.RetractChunks(ctx, []string{"c1"}, nil, testMemoryGroup)
+	if err != nil {
+		t.Fatalf("RetractChunks failed: %v", err)
+	}
+	if res.DeletedEdges != 1 || res.WeakenedEdges != 1 {
+		t.Errorf("Expected 1 deleted and 1 weakened edge, got %d and %d", res.DeletedEdges, res.WeakenedEdges)
+	}
+	affected := slices.Sorted(slices.Values(res.AffectedNodeIDs))
+	if want := []string{"acme", "alice", "bob"}; !slices.Equal(affected, want) {
+		t.Errorf("AffectedNodeIDs = %v, want %v", affected, want)
+	}
+	if want := []string{"bob"}; !slices.Equal(res.OrphanNodeIDs, want) {
+		t.Errorf("OrphanNodeIDs = %v, want %v", res.OrphanNodeIDs, want)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "shared entities survive",
+			query: `MATCH (n:GraphNode {memory_group: 'g1'}) RETURN n.id`,
+			want:  []string{"acme", "alice"},
+		},
+		{
+			name:  "edge backed by another chunk is weakened",
+			query: `MATCH (:GraphNode {id: 'alice'})-[r:GraphEdge]->(:GraphNode {id: 'acme'}) RETURN round(r.weight * 100)`,
+			want:  []string{"40"},
+		},
+		{
+			name:  "provenances of the retracted chunk are deleted",
+			query: `MATCH (p:EdgeProvenance {memory_group: 'g1'}) RETURN p.chunk_id`,
+			want:  []string{"c2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustQueryIDs(t, s, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("Unexpected result: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetEdgeProvenances は、エッジの出典が（SourceID, Type, TargetID）の完全一致で取得されることを確認します。
+func TestGetEdgeProvenances(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	addTestProvenanceGraph(t, s)
+	tests := []struct {
+		name  string
+		edges []*storage.Edge
+		want  []string // 出典のチャンクID
+	}{
+		{
+			name:  "edge backed by two chunks",
+			edges: []*storage.Edge{{SourceID: "alice", TargetID: "acme", Type: "WORKS_AT"}},
+			want:  []string{"c1", "c2"},
+		},
+		{
+			name: "multiple edges",
+			edges: []*storage.Edge{
+				{SourceID: "alice", TargetID: "acme", Type: "WORKS_AT"},
+				{SourceID: "bob", TargetID: "acme", Type: "WORKS_AT"},
+			},
+			want: []string{"c1", "c1", "c2"},
+		},
+		{
+			name:  "different type",
+			edges: []*storage.Edge{{SourceID: "alice", TargetID: "acme", Type: "FOUNDED"}},
+		},
+		{
+			name: "source and target of different edges",
+			// ソースとターゲットはそれぞれ既存のエッジに含まれるが、組み合わせとしては存在しない
+			edges: []*storage.Edge{
+				{SourceID: "alice", TargetID: "bob", Type: "WORKS_AT"},
+				{SourceID: "bob", TargetID: "acme", Type: "KNOWS"},
+			},
+		},
+		{
+			name: "no edges",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provenances, err := s.GetEdgeProvenances(ctx, tt.edges, testMemoryGroup)
+			if err != nil {
+				t.Fatalf("GetEdgeProvenances failed: %v", err)
+			}
+			var got []string
+			for _, p := range provenances {
+				got = append(got, p.ChunkID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Unexpected chunk IDs: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetCitations は、チャンクからドキュメント・データを辿った出典が、指定したチャンクIDの順序で返されることを確認します。
+func TestGetCitations(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	if err := s.SaveData(ctx, &storage.Data{ID: "data1", MemoryGroup: testMemoryGroup, Name: "policy.txt", SourceID: "handbook"}); err != nil {
+		t.Fatalf("SaveData failed: %v", err)
+	}
+	if err := s.SaveDocument(ctx, &storage.Document{ID: "doc1", MemoryGroup: testMemoryGroup, DataID: "data1", Text: "c1 c2"}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+	for i, id := range []string{"c1", "c2"} {
+		chunk := &storage.Chunk{ID: id, MemoryGroup: testMemoryGroup, DocumentID: "doc1", Text: id, ChunkIndex: i, Embedding: []float32{1, 0, 0, 0}}
+		if err := s.SaveChunk(ctx, chunk); err != nil {
+			t.Fatalf("SaveChunk failed: %v", err)
+		}
+	}
+	tests := []struct {
+		name        string
+		chunkIDs    []string
+		memoryGroup string
+		want        []string // チャンクID:チャンク順序
+	}{
+		{name: "keeps the given order", chunkIDs: []string{"c2", "c1"}, memoryGroup: testMemoryGroup, want: []string{"c2:1", "c1:0"}},
+		{name: "ignores unknown chunks", chunkIDs: []string{"unknown", "c1"}, memoryGroup: testMemoryGroup, want: []string{"c1:0"}},
+		{name: "ignores duplicates", chunkIDs: []string{"c1", "c1"}, memoryGroup: testMemoryGroup, want: []string{"c1:0"}},
+		{name: "other memory group", chunkIDs: []string{"c1"}, memoryGroup: "g2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			citations, err := s.GetCitations(ctx, tt.chunkIDs, tt.memoryGroup)
+			if err != nil {
+				t.Fatalf("GetCitations failed: %v", err)
+			}
+			var got []string
+			for _, c := range citations {
+				got = append(got, fmt.Sprintf("%s:%d", c.ChunkID, c.ChunkIndex))
+				if c.DocumentID != "doc1" || c.DataID != "data1" || c.FileName != "policy.txt" || c.SourceID != "handbook" {
+					t.Errorf("Unexpected citation: %+v", c)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Unexpected citations: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package embedcache は、Cube ごとの埋め込みキャッシュを提供します。
+// キャッシュは Cube の LadybugDB（EmbeddingCache テーブル）に保存され、メモリーグループをまたいで共有されます。
+// キャッシュキーは (プロバイダー, モデル, 次元数, 正規化したテキストのハッシュ) から決まるため、
+// 埋め込みモデルを移行した場合は別のキーとなり、旧モデルのベクトルが使用されることはありません。
+package embedcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// Cache は、埋め込みキャッシュの保存先です（storage.VectorStorage が実装しています）。
+type Cache interface {
+	GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error)
+	SaveCachedEmbeddings(ctx context.Context, entries map[string][]float32) error
+}
+
+// CachedEmbedder は、埋め込みキャッシュを参照してから Embedder を呼び出す storage.Embedder です。
+// キャッシュにないテキストだけをプロバイダーに送信するため、返すトークン使用量は実際の呼び出し分のみです。
+// キャッシュのヒット数・ミス数は TokenUsage の EmbeddingCacheHits / EmbeddingCacheMisses（モデルごとの内訳を含む）で返します。
+type CachedEmbedder struct {
+	embedder storage.Embedder
+	cache    Cache
+	config   types.EmbeddingModelConfig
+	Logger   *zap.Logger
+}
+
+var _ storage.Embedder = (*CachedEmbedder)(nil)
+
+// NewCachedEmbedder は、新しい CachedEmbedder を作成します。
+// 引数:
+//   - embedder: キャッシュにないテキストのベクトル化に使用する Embedder
+//   - cache: 埋め込みキャッシュの保存先（Cube のストレージ）
+//   - config: 埋め込みモデル設定（キャッシュキーに使用）
+func NewCachedEmbedder(embedder storage.Embedder, cache Cache, config types.EmbeddingModelConfig, l *zap.Logger) *CachedEmbedder {
+	return &CachedEmbedder{
+		embedder: embedder,
+		cache:    cache,
+		config:   config,
+		Logger:   l,
+	}
+}
+
+// CacheKey は、埋め込みモデルとテキストからキャッシュキーを作成します。
+// テキストは utils.NormalizeForVector で正規化してからハッシュ化します。
+func CacheKey(config types.EmbeddingModelConfig, text string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%s", strings.ToLower(config.Provider), config.Model, config.Dimension, utils.NormalizeForVector(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EmbedQuery は、テキストをベクトル化します。キャッシュにある場合はプロバイダーを呼び出しません。
+func (e *CachedEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, types.TokenUsage, error) {
+	vectors, usage, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, usage, err
+	}
+	return vectors[0], usage, nil
+}
+
+// EmbedBatch は、複数のテキストをまとめてベクトル化します。
+// キャッシュにないテキストだけを（重複を除いて）まとめてプロバイダーに送信し、結果をキャッシュに保存します。
+// キャッシュの読み込み・保存に失敗した場合は、キャッシュを使用せずに続行します。
+func (e *CachedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error) {
+	var usage types.TokenUsage
+	if len(texts) == 0 {
+		return [][]float32{}, usage, nil
+	}
+	keys := make([]string, len(texts))
+	for i, text := range texts {
+		keys[i] = CacheKey(e.config, text)
+	}
+	cached, err := e.cache.GetCachedEmbeddings(ctx, uniqueKeys(keys))
+	if err != nil {
+		utils.LogWarn(e.Logger, "CachedEmbedder: Failed to read embedding cache", zap.Error(err))
+		cached = map[string][]float32{}
+	}
+	// キャッシュにないテキスト（重複を除く）を集める
+	var missTexts []string
+	var missKeys []string
+	missIndex := map[string]int{}
+	hits, misses := int64(0), int64(0)
+	for i, key := range keys {
+		if vector, ok := cached[key]; ok && len(vector) == int(e.config.Dimension) {
+			hits++
+			continue
+		}
+		misses++
+		if _, ok := missIndex[key]; !ok {
+			missIndex[key] = len(missTexts)
+			missTexts = append(missTexts, texts[i])
+			missKeys = append(missKeys, key)
+		}
+	}
+	var missVectors [][]float32
+	if len(missTexts) > 0 {
+		vectors, u, err := e.embedder.EmbedBatch(ctx, missTexts)
+		usage.Add(u)
+		if err != nil {
+			return nil, usage, err
+		}
+		missVectors = vectors
+		entries := make(map[string][]float32, len(missKeys))
+		for i, key := range missKeys {
+			entries[key] = vectors[i]
+		}
+		if err := e.cache.SaveCachedEmbeddings(ctx, entries); err != nil {
+			utils.LogWarn(e.Logger, "CachedEmbedder: Failed to save embedding cache", zap.Error(err))
+		}
+	}
+	results := make([][]float32, len(texts))
+	for i, key := range keys {
+		if j, ok := missIndex[key]; ok {
+			results[i] = missVectors[j]
+		} else {
+			results[i] = cached[key]
+		}
+	}
+	usage.Add(types.TokenUsage{
+		EmbeddingCacheHits:   hits,
+		EmbeddingCacheMisses: misses,
+		Details: map[string]types.TokenUsage{
+			e.config.Model: {EmbeddingCacheHits: hits, EmbeddingCacheMisses: misses},
+		},
+	})
+	return results, usage, nil
+}
+
+// uniqueKeys は、重複を除いたキーのリストを返します。
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, key)
+	}
+	return unique
+}
@@ -0,0 +1,232 @@
+package embedcache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+var testEmbeddingConfig = types.EmbeddingModelConfig{Provider: "openai", Model: "text-embedding-3-small", Dimension: 2}
+
+// mapCache は、メモリ上の埋め込みキャッシュです。
+type mapCache struct {
+	entries map[string][]float32
+	getErr  error
+	saveErr error
+}
+
+func (c *mapCache) GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error) {
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	found := map[string][]float32{}
+	for _, key := range keys {
+		if vector, ok := c.entries[key]; ok {
+			found[key] = vector
+		}
+	}
+	return found, nil
+}
+
+func (c *mapCache) SaveCachedEmbeddings(ctx context.Context, entries map[string][]float32) error {
+	if c.saveErr != nil {
+		return c.saveErr
+	}
+	for key, vector := range entries {
+		c.entries[key] = vector
+	}
+	return nil
+}
+
+// countingEmbedder は、プロバイダーに送信されたテキストを記録し、テキストの長さから2次元のベクトルを返す Embedder です。
+type countingEmbedder struct {
+	sent [][]string
+	err  error
+}
+
+func (e *countingEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, types.TokenUsage, error) {
+	vectors, usage, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, usage, err
+	}
+	return vectors[0], usage, nil
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error) {
+	e.sent = append(e.sent, texts)
+	if e.err != nil {
+		return nil, types.TokenUsage{}, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), 1}
+	}
+	return vectors, types.TokenUsage{InputTokens: int64(len(texts))}, nil
+}
+
+func TestCachedEmbedderEmbedBatch(t *testing.T) {
+	key := func(text string) string { return CacheKey(testEmbeddingConfig, text) }
+	tests := []struct {
+		name        string
+		cached      map[string][]float32
+		getErr      error
+		saveErr     error
+		embedErr    error
+		texts       []string
+		wantSent    []string // プロバイダーに送信されるテキスト（nil の場合は送信しない）
+		wantHits    int64
+		wantMisses  int64
+		wantVectors [][]float32
+		wantSaved   int // 実行後のキャッシュの件数
+		wantErr     bool
+	}{
+		{
+			name:        "all misses",
+			texts:       []string{"ab", "abc"},
+			wantSent:    []string{"ab", "abc"},
+			wantMisses:  2,
+			wantVectors: [][]float32{{2, 1}, {3, 1}},
+			wantSaved:   2,
+		},
+		{
+			name:        "cached texts are not sent",
+			cached:      map[string][]float32{key("ab"): {9, 9}},
+			texts:       []string{"ab", "abc"},
+			wantSent:    []string{"abc"},
+			wantHits:    1,
+			wantMisses:  1,
+			wantVectors: [][]float32{{9, 9}, {3, 1}},
+			wantSaved:   2,
+		},
+		{
+			name:        "all hits",
+			cached:      map[string][]float32{key("ab"): {9, 9}},
+			texts:       []string{"ab", "ab"},
+			wantHits:    2,
+			wantVectors: [][]float32{{9, 9}, {9, 9}},
+			wantSaved:   1,
+		},
+		{
+			name:        "duplicates after normalization are sent once",
+			texts:       []string{"Tokyo", "  Tokyo ", "Ｔｏｋｙｏ"},
+			wantSent:    []string{"Tokyo"},
+			wantMisses:  3,
+			wantVectors: [][]float32{{5, 1}, {5, 1}, {5, 1}},
+			wantSaved:   1,
+		},
+		{
+			name:        "cached vector of another dimension is ignored",
+			cached:      map[string][]float32{key("ab"): {9}},
+			texts:       []string{"ab"},
+			wantSent:    []string{"ab"},
+			wantMisses:  1,
+			wantVectors: [][]float32{{2, 1}},
+			wantSaved:   1,
+		},
+		{
+			name:        "cache read error",
+			cached:      map[string][]float32{key("ab"): {9, 9}},
+			getErr:      errors.New("read failed"),
+			texts:       []string{"ab"},
+			wantSent:    []string{"ab"},
+			wantMisses:  1,
+			wantVectors: [][]float32{{2, 1}},
+			wantSaved:   1,
+		},
+		{
+			name:        "cache save error",
+			saveErr:     errors.New("save failed"),
+			texts:       []string{"ab"},
+			wantSent:    []string{"ab"},
+			wantMisses:  1,
+			wantVectors: [][]float32{{2, 1}},
+		},
+		{
+			name:     "embedding error",
+			embedErr: errors.New("rate limited"),
+			texts:    []string{"ab"},
+			wantSent: []string{"ab"},
+			wantErr:  true,
+		},
+		{
+			name:        "no texts",
+			wantVectors: [][]float32{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &mapCache{entries: map[string][]float32{}, getErr: tt.getErr, saveErr: tt.saveErr}
+			for k, v := range tt.cached {
+				cache.entries[k] = v
+			}
+			embedder := &countingEmbedder{err: tt.embedErr}
+			e := NewCachedEmbedder(embedder, cache, testEmbeddingConfig, nil)
+			vectors, usage, err := e.EmbedBatch(context.Background(), tt.texts)
+			if tt.wantSent == nil && len(embedder.sent) != 0 || tt.wantSent != nil && (len(embedder.sent) != 1 || !slices.Equal(embedder.sent[0], tt.wantSent)) {
+				t.Errorf("sent = %q, want %q", embedder.sent, tt.wantSent)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EmbedBatch failed: %v", err)
+			}
+			if len(vectors) != len(tt.wantVectors) {
+				t.Fatalf("len(vectors) = %d, want %d", len(vectors), len(tt.wantVectors))
+			}
+			for i := range vectors {
+				if !slices.Equal(vectors[i], tt.wantVectors[i]) {
+					t.Errorf("vectors[%d] = %v, want %v", i, vectors[i], tt.wantVectors[i])
+				}
+			}
+			// トークン使用量は実際にプロバイダーに送信したテキストの分だけ
+			if usage.InputTokens != int64(len(tt.wantSent)) {
+				t.Errorf("input tokens = %d, want %d", usage.InputTokens, len(tt.wantSent))
+			}
+			if usage.EmbeddingCacheHits != tt.wantHits || usage.EmbeddingCacheMisses != tt.wantMisses {
+				t.Errorf("hits = %d, misses = %d, want %d, %d", usage.EmbeddingCacheHits, usage.EmbeddingCacheMisses, tt.wantHits, tt.wantMisses)
+			}
+			if len(tt.texts) > 0 {
+				detail := usage.Details[testEmbeddingConfig.Model]
+				if detail.EmbeddingCacheHits != tt.wantHits || detail.EmbeddingCacheMisses != tt.wantMisses {
+					t.Errorf("model detail = %+v, want hits %d, misses %d", detail, tt.wantHits, tt.wantMisses)
+				}
+			}
+			if len(cache.entries) != tt.wantSaved {
+				t.Errorf("cache entries = %d, want %d", len(cache.entries), tt.wantSaved)
+			}
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey(testEmbeddingConfig, "Acme Corporation")
+	tests := []struct {
+		name     string
+		config   types.EmbeddingModelConfig
+		text     string
+		wantSame bool
+	}{
+		{"same input", testEmbeddingConfig, "Acme Corporation", true},
+		{"provider is case insensitive", types.EmbeddingModelConfig{Provider: "OpenAI", Model: "text-embedding-3-small", Dimension: 2}, "Acme Corporation", true},
+		{"normalized text", testEmbeddingConfig, " Ａｃｍｅ  Corporation ", true},
+		{"connection settings are ignored", types.EmbeddingModelConfig{Provider: "openai", Model: "text-embedding-3-small", Dimension: 2, BaseURL: "http://localhost", ApiKey: "key"}, "Acme Corporation", true},
+		{"different text", testEmbeddingConfig, "acme corporation", false},
+		{"different provider", types.EmbeddingModelConfig{Provider: "azure", Model: "text-embedding-3-small", Dimension: 2}, "Acme Corporation", false},
+		{"different model", types.EmbeddingModelConfig{Provider: "openai", Model: "text-embedding-3-large", Dimension: 2}, "Acme Corporation", false},
+		{"different dimension", types.EmbeddingModelConfig{Provider: "openai", Model: "text-embedding-3-small", Dimension: 3}, "Acme Corporation", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := CacheKey(tt.config, tt.text) == base; same != tt.wantSame {
+				t.Errorf("same key = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
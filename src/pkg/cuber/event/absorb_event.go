@@ -17,10 +17,12 @@ const (
 	EVENT_ABSORB_KEYWORDS_START               EventName = "ABSORB_KEYWORDS_START"               // FTS用キーワード抽出処理が開始された時に発火する
 	EVENT_ABSORB_KEYWORDS_END                 EventName = "ABSORB_KEYWORDS_END"                 // FTS用キーワード抽出処理が完了した時に発火する
 	EVENT_ABSORB_CHUNKING_PROCESS_END         EventName = "ABSORB_CHUNKING_PROCESS_END"         // チャンク分割処理が完了した時に発火する
+	EVENT_ABSORB_CHECKPOINT_RESTORED          EventName = "ABSORB_CHECKPOINT_RESTORED"          // 以前に失敗した Absorb のチェックポイントからチャンクと抽出済みグラフが復元された時に発火する
 	EVENT_ABSORB_GRAPH_REQUEST_START          EventName = "ABSORB_GRAPH_REQUEST_START"          // 知識グラフ抽出のためのLLMリクエストが開始された時に発火する
 	EVENT_ABSORB_GRAPH_REQUEST_END            EventName = "ABSORB_GRAPH_REQUEST_END"            // 知識グラフ抽出のためのLLMリクエストが完了した時に発火する
 	EVENT_ABSORB_GRAPH_PARSE_START            EventName = "ABSORB_GRAPH_PARSE_START"            // LLMの応答からグラフ要素（ノード・エッジ）をパースする処理が開始された時に発火する
 	EVENT_ABSORB_GRAPH_PARSE_END              EventName = "ABSORB_GRAPH_PARSE_END"              // グラフ要素のパース処理が完了した時に発火する
+	EVENT_ABSORB_GRAPH_CHUNK_SKIPPED          EventName = "ABSORB_GRAPH_CHUNK_SKIPPED"          // 再試行してもグラフ抽出に失敗したチャンクが、許容範囲内としてスキップされた時に発火する
 	EVENT_ABSORB_GRAPH_INTERPRETED            EventName = "ABSORB_GRAPH_INTERPRETED"            // グラフの解析と解釈が完了した時に発火する
 	EVENT_ABSORB_STORAGE_CHUNK_START          EventName = "ABSORB_STORAGE_CHUNK_START"          // チャンクのベクトルストアへの保存処理が開始された時に発火する
 	EVENT_ABSORB_STORAGE_CHUNK_END            EventName = "ABSORB_STORAGE_CHUNK_END"            // チャンクのベクトルストアへの保存処理が完了した時に発火する
@@ -86,6 +88,13 @@ type AbsorbChunkingProcessEndPayload struct {
 	ChunksCount int
 }
 
+type AbsorbCheckpointRestoredPayload struct {
+	BasePayload
+	FileName       string
+	ChunksCount    int // 復元したチャンク数
+	ExtractedCount int // そのうちグラフの抽出が完了していたチャンク数
+}
+
 type AbsorbKeywordsStartPayload struct {
 	BasePayload
 	ChunkNum int // キーワード抽出対象のチャンク数
@@ -124,6 +133,14 @@ type AbsorbGraphParseEndPayload struct {
 	EdgesExtracted int
 }
 
+type AbsorbGraphChunkSkippedPayload struct {
+	BasePayload
+	ChunkID  string
+	ChunkNum int
+	Attempts int    // 試行回数
+	Error    string // 最後の試行のエラー
+}
+
 type AbsorbGraphInterpretedPayload struct {
 	BasePayload
 	InterpretedContent string
@@ -247,10 +264,12 @@ func RegisterAbsorbStreamer(eb *eventbus.EventBus, ch chan<- StreamEvent) {
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_KEYWORDS_START), func(p AbsorbKeywordsStartPayload) error { send(EVENT_ABSORB_KEYWORDS_START, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_KEYWORDS_END), func(p AbsorbKeywordsEndPayload) error { send(EVENT_ABSORB_KEYWORDS_END, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_CHUNKING_PROCESS_END), func(p AbsorbChunkingProcessEndPayload) error { send(EVENT_ABSORB_CHUNKING_PROCESS_END, p); return nil })
+	eventbus.Subscribe(eb, string(EVENT_ABSORB_CHECKPOINT_RESTORED), func(p AbsorbCheckpointRestoredPayload) error { send(EVENT_ABSORB_CHECKPOINT_RESTORED, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_REQUEST_START), func(p AbsorbGraphRequestStartPayload) error { send(EVENT_ABSORB_GRAPH_REQUEST_START, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_REQUEST_END), func(p AbsorbGraphRequestEndPayload) error { send(EVENT_ABSORB_GRAPH_REQUEST_END, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_PARSE_START), func(p AbsorbGraphParseStartPayload) error { send(EVENT_ABSORB_GRAPH_PARSE_START, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_PARSE_END), func(p AbsorbGraphParseEndPayload) error { send(EVENT_ABSORB_GRAPH_PARSE_END, p); return nil })
+	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_CHUNK_SKIPPED), func(p AbsorbGraphChunkSkippedPayload) error { send(EVENT_ABSORB_GRAPH_CHUNK_SKIPPED, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_GRAPH_INTERPRETED), func(p AbsorbGraphInterpretedPayload) error { send(EVENT_ABSORB_GRAPH_INTERPRETED, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_STORAGE_CHUNK_START), func(p AbsorbStorageChunkStartPayload) error { send(EVENT_ABSORB_STORAGE_CHUNK_START, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_ABSORB_STORAGE_CHUNK_END), func(p AbsorbStorageChunkEndPayload) error { send(EVENT_ABSORB_STORAGE_CHUNK_END, p); return nil })
@@ -3,6 +3,7 @@ package event
 import (
 	"fmt"
 	"math/rand/v2"
+	"strings"
 	"sync"
 )
 
@@ -75,6 +76,9 @@ func FormatEvent(e StreamEvent, isEn bool) (string, error) {
 	// チャンク分割処理が完了した時に発火する
 	case AbsorbChunkingProcessEndPayload:
 		return fmt.Sprintf(template, p.ChunksCount), nil
+	// 以前に失敗した Absorb のチェックポイントからチャンクと抽出済みグラフが復元された時に発火する
+	case AbsorbCheckpointRestoredPayload:
+		return fmt.Sprintf(template, TruncateString(p.FileName, 45), p.ChunksCount, p.ExtractedCount), nil
 	// 知識グラフ抽出のためのLLMリクエストが開始された時に発火する
 	case AbsorbGraphRequestStartPayload:
 		return fmt.Sprintf(template, p.ChunkNum), nil
@@ -87,6 +91,9 @@ func FormatEvent(e StreamEvent, isEn bool) (string, error) {
 	// グラフ要素のパース処理が完了した時に発火する
 	case AbsorbGraphParseEndPayload:
 		return fmt.Sprintf(template, p.ChunkNum), nil
+	// 再試行してもグラフ抽出に失敗したチャンクが、許容範囲内としてスキップされた時に発火する
+	case AbsorbGraphChunkSkippedPayload:
+		return fmt.Sprintf(template, p.ChunkNum, p.Attempts, TruncateString(p.Error, 100)), nil
 	// グラフの解析と解釈が完了した時に発火する
 	case AbsorbGraphInterpretedPayload:
 		return fmt.Sprintf(template, p.InterpretedContent), nil
@@ -192,6 +199,9 @@ func FormatEvent(e StreamEvent, isEn bool) (string, error) {
 	// 矛盾解決によりエッジが破棄された時に発火する
 	case InfoConflictDiscardedPayload:
 		return fmt.Sprintf(template, p.SourceID, p.RelationType, p.TargetID, p.Stage, p.Reason), nil
+	// オペレーションがキャンセルされ、変更がロールバックされた時に発火する
+	case InfoOperationCancelledPayload:
+		return fmt.Sprintf(template, p.OperationType, p.OperationID), nil
 	// LLMに渡すコンテキスト（検索結果の統合）の構築が開始された時に発火する
 	case QueryContextStartPayload:
 		return template, nil
@@ -204,6 +214,15 @@ func FormatEvent(e StreamEvent, isEn bool) (string, error) {
 	// 最終的な回答生成が完了した時に発火する
 	case QueryGenerationEndPayload:
 		return template, nil
+	// 推論ラウンドで回答案が不十分と判断され、追加の検索クエリが生成された時に発火する
+	case QueryReasoningFollowUpPayload:
+		return fmt.Sprintf(template, p.Round, TruncateString(p.Critique, 100), TruncateString(strings.ReplaceAll(p.FollowUpQueries, "\n", " / "), 120)), nil
+	// 推論ラウンドで回答案が十分と判断された時に発火する
+	case QueryReasoningSufficientPayload:
+		return fmt.Sprintf(template, p.Round), nil
+	// ハイブリッド検索で各ランキングの融合が完了し、回答に使うチャンクが確定した時に発火する
+	case QueryHybridFusionEndPayload:
+		return fmt.Sprintf(template, p.VectorCount, p.LexicalCount, p.GraphCount, p.FusedCount), nil
 	// クエリ処理中にエラーが発生した時に発火する
 	case QueryErrorPayload:
 		return fmt.Sprintf(template, p.ErrorMessage), nil
@@ -261,6 +280,18 @@ func FormatEvent(e StreamEvent, isEn bool) (string, error) {
 	// Memify処理中にエラーが発生した時に発火する
 	case MemifyErrorPayload:
 		return fmt.Sprintf(template, p.Error.Error()), nil
+	// ================================
+	// --- Migration Events ---
+	// ================================
+	// 埋め込みモデルの移行が開始された時に発火する
+	case MigrateEmbeddingsStartPayload:
+		return fmt.Sprintf(template, p.TotalCount, TruncateString(p.Model, 45), p.Dimension), nil
+	// 埋め込みモデルの移行で、1バッチの再ベクトル化が完了した時に発火する
+	case MigrateEmbeddingsProgressPayload:
+		return fmt.Sprintf(template, p.TableName, p.DoneCount, p.TotalCount), nil
+	// 埋め込みモデルの移行が完了した時に発火する
+	case MigrateEmbeddingsEndPayload:
+		return fmt.Sprintf(template, p.TotalCount), nil
 	// Fallback for events with no specific fields other than BasePayload or if missed
 	default:
 		return template, nil
@@ -638,6 +638,63 @@ var templateMap = map[EventName]EventTemplates{
 		},
 	},
 
+	EVENT_ABSORB_CHECKPOINT_RESTORED: {
+		En: [25]string{
+			"Resuming \"%[1]s\" from a previous attempt: restored %[2]d chunks, %[3]d of which already have their knowledge extracted.",
+			"Found a checkpoint for \"%[1]s\". Reusing %[2]d chunks and %[3]d completed extractions instead of starting over.",
+			"Picking up where the last attempt left off on \"%[1]s\": %[2]d chunks restored, %[3]d already analyzed.",
+			"Restored \"%[1]s\" from its checkpoint with %[2]d chunks. %[3]d chunks will not need to be analyzed again.",
+			"Resumed processing of \"%[1]s\": %[2]d chunks recovered from the checkpoint, %[3]d with finished extraction.",
+			"Good news: \"%[1]s\" was partially processed before. Restored %[2]d chunks, including %[3]d already extracted.",
+			"Continuing \"%[1]s\" from the saved checkpoint. %[2]d chunks are ready and %[3]d have their graph already.",
+			"Checkpoint loaded for \"%[1]s\": %[2]d chunks restored and %[3]d extractions reused.",
+			"Skipping repeated work on \"%[1]s\": %[2]d chunks restored from the checkpoint, %[3]d already extracted.",
+			"Recovered the previous progress on \"%[1]s\": %[2]d chunks, with knowledge already extracted from %[3]d.",
+			"Resuming \"%[1]s\" with %[2]d restored chunks. Extraction was already complete for %[3]d of them.",
+			"The earlier attempt on \"%[1]s\" was saved. Restored %[2]d chunks and %[3]d finished extractions.",
+			"Reusing saved progress for \"%[1]s\": %[2]d chunks restored, %[3]d of them fully analyzed.",
+			"Restarted \"%[1]s\" from the checkpoint. %[2]d chunks recovered; %[3]d need no further extraction.",
+			"Loaded \"%[1]s\" from the last checkpoint: %[2]d chunks in total, %[3]d already converted into knowledge.",
+			"Resuming from the checkpoint for \"%[1]s\". Restored %[2]d chunks and reused %[3]d completed analyses.",
+			"Previous work on \"%[1]s\" has been recovered: %[2]d chunks restored, %[3]d extractions carried over.",
+			"Continuing the interrupted processing of \"%[1]s\": %[2]d chunks restored, %[3]d already extracted.",
+			"Checkpoint found for \"%[1]s\". %[2]d chunks were restored and %[3]d will be reused as-is.",
+			"Restored %[2]d chunks of \"%[1]s\" from the checkpoint; %[3]d of them were already analyzed.",
+			"Resumed \"%[1]s\" without repeating finished work: %[2]d chunks restored, %[3]d extractions reused.",
+			"The checkpoint for \"%[1]s\" was applied: %[2]d chunks restored, %[3]d already processed.",
+			"Recovered %[2]d chunks of \"%[1]s\" from a previous run, with %[3]d extractions already done.",
+			"Resuming the absorption of \"%[1]s\": %[2]d chunks restored and %[3]d completed extractions reused.",
+			"Saved progress for \"%[1]s\" restored: %[2]d chunks, %[3]d of which are already extracted.",
+		},
+		Ja: [25]string{
+			"前回の試行から「%[1]s」を再開します。%[2]d件のチャンクを復元し、そのうち%[3]d件は抽出済みです。",
+			"「%[1]s」のチェックポイントが見つかりました。最初からやり直さず、%[2]d件のチャンクと%[3]d件の抽出結果を再利用します。",
+			"「%[1]s」の前回の続きから処理します。%[2]d件のチャンクを復元し、%[3]d件は解析済みです。",
+			"「%[1]s」をチェックポイントから復元しました（%[2]d件のチャンク）。%[3]d件は再解析が不要です。",
+			"「%[1]s」の処理を再開しました。チェックポイントから%[2]d件のチャンクを復元し、%[3]d件は抽出が完了しています。",
+			"「%[1]s」は以前に途中まで処理されていました。%[2]d件のチャンクを復元し、うち%[3]d件は抽出済みです。",
+			"保存されたチェックポイントから「%[1]s」を続行します。%[2]d件のチャンクが準備済みで、%[3]d件はグラフ抽出済みです。",
+			"「%[1]s」のチェックポイントを読み込みました。%[2]d件のチャンクを復元し、%[3]d件の抽出結果を再利用します。",
+			"「%[1]s」の重複作業を省きます。チェックポイントから%[2]d件のチャンクを復元し、%[3]d件は抽出済みです。",
+			"「%[1]s」の前回の進捗を復元しました。%[2]d件のチャンクのうち、%[3]d件は知識の抽出が完了しています。",
+			"復元した%[2]d件のチャンクで「%[1]s」を再開します。そのうち%[3]d件は抽出が完了済みです。",
+			"「%[1]s」の前回の試行は保存されていました。%[2]d件のチャンクと%[3]d件の抽出結果を復元しました。",
+			"「%[1]s」の保存済みの進捗を再利用します。%[2]d件のチャンクを復元し、うち%[3]d件は解析済みです。",
+			"チェックポイントから「%[1]s」を再開しました。%[2]d件のチャンクを復元し、%[3]d件は追加の抽出が不要です。",
+			"最後のチェックポイントから「%[1]s」を読み込みました。全%[2]d件のチャンクのうち、%[3]d件は知識化済みです。",
+			"「%[1]s」のチェックポイントから再開します。%[2]d件のチャンクを復元し、%[3]d件の解析結果を再利用します。",
+			"「%[1]s」の以前の作業を回収しました。%[2]d件のチャンクを復元し、%[3]d件の抽出結果を引き継ぎます。",
+			"中断された「%[1]s」の処理を続行します。%[2]d件のチャンクを復元し、%[3]d件は抽出済みです。",
+			"「%[1]s」のチェックポイントが見つかりました。%[2]d件のチャンクを復元し、%[3]d件はそのまま再利用します。",
+			"チェックポイントから「%[1]s」のチャンク%[2]d件を復元しました。うち%[3]d件は解析済みです。",
+			"完了済みの作業を繰り返さずに「%[1]s」を再開しました。%[2]d件のチャンクを復元し、%[3]d件の抽出結果を再利用します。",
+			"「%[1]s」のチェックポイントを適用しました。%[2]d件のチャンクを復元し、%[3]d件は処理済みです。",
+			"前回の実行から「%[1]s」のチャンク%[2]d件を復元しました。%[3]d件は抽出が完了しています。",
+			"「%[1]s」の取り込みを再開します。%[2]d件のチャンクを復元し、%[3]d件の抽出結果を再利用します。",
+			"「%[1]s」の保存済みの進捗を復元しました。%[2]d件のチャンクのうち、%[3]d件は抽出済みです。",
+		},
+	},
+
 	EVENT_ABSORB_GRAPH_REQUEST_START: {
 		En: [25]string{
 			"Thinking deeply about %d items to find connections and meaning.",
@@ -866,6 +923,63 @@ var templateMap = map[EventName]EventTemplates{
 		},
 	},
 
+	EVENT_ABSORB_GRAPH_CHUNK_SKIPPED: {
+		En: [25]string{
+			"Skipped chunk #%[1]d after %[2]d failed attempts. It stays searchable as text but adds no knowledge graph. Last error: %[3]s",
+			"Could not extract knowledge from chunk #%[1]d in %[2]d attempts, so it was skipped within the allowed tolerance. Last error: %[3]s",
+			"Chunk #%[1]d was left out of the knowledge graph after %[2]d tries. Last error: %[3]s",
+			"Moving on without chunk #%[1]d: extraction failed %[2]d times. Last error: %[3]s",
+			"Chunk #%[1]d failed %[2]d times and was skipped as allowed by the tolerance setting. Last error: %[3]s",
+			"Gave up on extracting chunk #%[1]d after %[2]d attempts; the rest of the document continues. Last error: %[3]s",
+			"Skipping chunk #%[1]d (%[2]d attempts failed). Its text is still stored for search. Last error: %[3]s",
+			"Extraction for chunk #%[1]d did not succeed in %[2]d attempts, so it was skipped. Last error: %[3]s",
+			"Chunk #%[1]d could not be analyzed after %[2]d tries and will not contribute to the graph. Last error: %[3]s",
+			"Tolerated a failure on chunk #%[1]d after %[2]d attempts and continued. Last error: %[3]s",
+			"Chunk #%[1]d was skipped after %[2]d unsuccessful extraction attempts. Last error: %[3]s",
+			"Left chunk #%[1]d out of the graph (%[2]d attempts). All other chunks are unaffected. Last error: %[3]s",
+			"Knowledge extraction for chunk #%[1]d failed %[2]d times; skipping it within tolerance. Last error: %[3]s",
+			"Continuing without the graph for chunk #%[1]d after %[2]d failed tries. Last error: %[3]s",
+			"Chunk #%[1]d was set aside after %[2]d attempts. It remains available as plain text. Last error: %[3]s",
+			"Skipped the knowledge graph for chunk #%[1]d: %[2]d attempts did not succeed. Last error: %[3]s",
+			"After %[2]d attempts, chunk #%[1]d still failed and was skipped. Last error: %[3]s",
+			"Chunk #%[1]d could not be converted into knowledge in %[2]d tries and was skipped. Last error: %[3]s",
+			"The failure on chunk #%[1]d is within the allowed tolerance, so it was skipped after %[2]d attempts. Last error: %[3]s",
+			"Omitted chunk #%[1]d from graph extraction after %[2]d failures. Last error: %[3]s",
+			"Chunk #%[1]d was skipped; extraction failed on all %[2]d attempts. Last error: %[3]s",
+			"Proceeding without chunk #%[1]d's graph after %[2]d attempts. Last error: %[3]s",
+			"Extraction retries for chunk #%[1]d were exhausted (%[2]d attempts), so it was skipped. Last error: %[3]s",
+			"Chunk #%[1]d did not yield a valid graph in %[2]d attempts and was skipped. Last error: %[3]s",
+			"Skipped chunk #%[1]d after exhausting %[2]d attempts; processing continues with the remaining chunks. Last error: %[3]s",
+		},
+		Ja: [25]string{
+			"%[2]d回試行しても失敗したため、チャンク#%[1]dをスキップしました。テキストとしては検索できますが、知識グラフには追加されません。最後のエラー：%[3]s",
+			"チャンク#%[1]dから%[2]d回の試行で知識を抽出できなかったため、許容範囲内としてスキップしました。最後のエラー：%[3]s",
+			"%[2]d回試行した結果、チャンク#%[1]dは知識グラフから除外しました。最後のエラー：%[3]s",
+			"チャンク#%[1]dは抽出に%[2]d回失敗したため、スキップして先に進みます。最後のエラー：%[3]s",
+			"チャンク#%[1]dは%[2]d回失敗したため、許容設定に従いスキップしました。最後のエラー：%[3]s",
+			"%[2]d回の試行後、チャンク#%[1]dの抽出を断念しました。文書の残りの処理は続行します。最後のエラー：%[3]s",
+			"チャンク#%[1]dをスキップします（%[2]d回失敗）。テキストは検索用に保存されています。最後のエラー：%[3]s",
+			"チャンク#%[1]dの抽出は%[2]d回の試行で成功しなかったため、スキップしました。最後のエラー：%[3]s",
+			"チャンク#%[1]dは%[2]d回試行しても解析できず、グラフには反映されません。最後のエラー：%[3]s",
+			"チャンク#%[1]dの失敗（%[2]d回試行）を許容し、処理を続行しました。最後のエラー：%[3]s",
+			"%[2]d回の抽出に失敗したため、チャンク#%[1]dをスキップしました。最後のエラー：%[3]s",
+			"チャンク#%[1]dをグラフから除外しました（%[2]d回試行）。他のチャンクには影響ありません。最後のエラー：%[3]s",
+			"チャンク#%[1]dの知識抽出に%[2]d回失敗したため、許容範囲内としてスキップします。最後のエラー：%[3]s",
+			"%[2]d回失敗したため、チャンク#%[1]dのグラフなしで続行します。最後のエラー：%[3]s",
+			"チャンク#%[1]dは%[2]d回の試行後に保留としました。テキストとしては引き続き利用できます。最後のエラー：%[3]s",
+			"チャンク#%[1]dの知識グラフをスキップしました。%[2]d回の試行が成功しませんでした。最後のエラー：%[3]s",
+			"%[2]d回試行してもチャンク#%[1]dは失敗したため、スキップしました。最後のエラー：%[3]s",
+			"チャンク#%[1]dは%[2]d回の試行で知識に変換できず、スキップしました。最後のエラー：%[3]s",
+			"チャンク#%[1]dの失敗は許容範囲内のため、%[2]d回の試行後にスキップしました。最後のエラー：%[3]s",
+			"%[2]d回失敗したため、チャンク#%[1]dをグラフ抽出の対象から外しました。最後のエラー：%[3]s",
+			"チャンク#%[1]dをスキップしました。%[2]d回の試行すべてで抽出に失敗しました。最後のエラー：%[3]s",
+			"%[2]d回試行した後、チャンク#%[1]dのグラフなしで処理を進めます。最後のエラー：%[3]s",
+			"チャンク#%[1]dの再試行（%[2]d回）を使い切ったため、スキップしました。最後のエラー：%[3]s",
+			"チャンク#%[1]dは%[2]d回の試行で有効なグラフが得られず、スキップしました。最後のエラー：%[3]s",
+			"%[2]d回の試行を使い切ったため、チャンク#%[1]dをスキップしました。残りのチャンクの処理を続けます。最後のエラー：%[3]s",
+		},
+	},
+
 	EVENT_ABSORB_GRAPH_INTERPRETED: {
 		En: [25]string{
 			"Clear understanding has been achieved: \n\n%s",
@@ -2807,6 +2921,63 @@ var templateMap = map[EventName]EventTemplates{
 		},
 	},
 
+	EVENT_INFO_OPERATION_CANCELLED: {
+		En: [25]string{
+			"The %[1]s operation (%[2]s) was cancelled. All changes made so far have been rolled back.",
+			"Cancellation received for the %[1]s operation (%[2]s). The knowledge space has been restored to its previous state.",
+			"Stopped the %[1]s operation (%[2]s) as requested. No changes were saved.",
+			"The %[1]s operation (%[2]s) has been cancelled and its pending changes were discarded.",
+			"Cancelled the running %[1]s operation (%[2]s). The cube remains exactly as it was before.",
+			"The %[1]s operation (%[2]s) was interrupted by a cancel request. Everything has been rolled back safely.",
+			"Acknowledged the cancel request. The %[1]s operation (%[2]s) ended without saving any changes.",
+			"Halted the %[1]s operation (%[2]s). The partial results were discarded to keep the knowledge consistent.",
+			"The %[1]s operation (%[2]s) has stopped. All in-progress changes were rolled back.",
+			"Cancellation complete for the %[1]s operation (%[2]s). The knowledge space was left untouched.",
+			"Ended the %[1]s operation (%[2]s) early as requested. No partial data was written.",
+			"The %[1]s operation (%[2]s) was cancelled before completion. Its changes have been undone.",
+			"Stopped processing the %[1]s operation (%[2]s). The cube was restored to its original state.",
+			"The cancel request was applied to the %[1]s operation (%[2]s). Nothing from this run was kept.",
+			"Aborted the %[1]s operation (%[2]s). All intermediate changes were rolled back cleanly.",
+			"The %[1]s operation (%[2]s) ended by cancellation. The knowledge remains unchanged.",
+			"Cancelled the %[1]s operation (%[2]s) and discarded everything it had prepared.",
+			"Per the cancel request, the %[1]s operation (%[2]s) was stopped and rolled back.",
+			"The %[1]s operation (%[2]s) was terminated. The knowledge space is exactly as it was before it started.",
+			"Wrapped up the cancelled %[1]s operation (%[2]s). No changes were committed.",
+			"The %[1]s operation (%[2]s) was called off. All pending writes have been rolled back.",
+			"Stopped the %[1]s operation (%[2]s) cleanly. The cube has not been modified.",
+			"The %[1]s operation (%[2]s) was cancelled midway. Its partial work was safely discarded.",
+			"Cancellation of the %[1]s operation (%[2]s) is complete. The previous state has been preserved.",
+			"The %[1]s operation (%[2]s) finished as cancelled. Everything has been rolled back.",
+		},
+		Ja: [25]string{
+			"%[1]s のオペレーション（%[2]s）はキャンセルされました。ここまでの変更はすべてロールバックされています。",
+			"%[1]s のオペレーション（%[2]s）のキャンセルを受け付けました。知識空間は元の状態に戻っています。",
+			"ご依頼どおり %[1]s のオペレーション（%[2]s）を停止しました。変更は保存されていません。",
+			"%[1]s のオペレーション（%[2]s）をキャンセルし、保留中の変更を破棄しました。",
+			"実行中の %[1]s のオペレーション（%[2]s）をキャンセルしました。Cubeは以前のままです。",
+			"キャンセルの要求により %[1]s のオペレーション（%[2]s）を中断しました。すべて安全にロールバックされています。",
+			"キャンセルの要求を受け付けました。%[1]s のオペレーション（%[2]s）は変更を保存せずに終了しました。",
+			"%[1]s のオペレーション（%[2]s）を停止しました。知識の整合性を保つため、途中の結果は破棄されています。",
+			"%[1]s のオペレーション（%[2]s）が停止しました。処理中の変更はすべてロールバックされています。",
+			"%[1]s のオペレーション（%[2]s）のキャンセルが完了しました。知識空間には手を加えていません。",
+			"ご依頼どおり %[1]s のオペレーション（%[2]s）を途中で終了しました。途中のデータは書き込まれていません。",
+			"%[1]s のオペレーション（%[2]s）は完了前にキャンセルされました。変更は取り消されています。",
+			"%[1]s のオペレーション（%[2]s）の処理を停止しました。Cubeは元の状態に戻っています。",
+			"%[1]s のオペレーション（%[2]s）にキャンセルを適用しました。今回の処理内容は残っていません。",
+			"%[1]s のオペレーション（%[2]s）を中止しました。途中の変更はすべて正常にロールバックされました。",
+			"%[1]s のオペレーション（%[2]s）はキャンセルにより終了しました。知識は変わっていません。",
+			"%[1]s のオペレーション（%[2]s）をキャンセルし、準備していた内容をすべて破棄しました。",
+			"キャンセルの要求に従い、%[1]s のオペレーション（%[2]s）を停止してロールバックしました。",
+			"%[1]s のオペレーション（%[2]s）を終了しました。知識空間は開始前とまったく同じ状態です。",
+			"キャンセルされた %[1]s のオペレーション（%[2]s）の後処理を終えました。変更はコミットされていません。",
+			"%[1]s のオペレーション（%[2]s）は取りやめになりました。保留中の書き込みはすべてロールバックされています。",
+			"%[1]s のオペレーション（%[2]s）を正常に停止しました。Cubeは変更されていません。",
+			"%[1]s のオペレーション（%[2]s）は途中でキャンセルされました。途中の処理内容は安全に破棄されています。",
+			"%[1]s のオペレーション（%[2]s）のキャンセルが完了しました。以前の状態が保たれています。",
+			"%[1]s のオペレーション（%[2]s）はキャンセルとして終了しました。すべてロールバックされています。",
+		},
+	},
+
 	EVENT_QUERY_CONTEXT_START: {
 		En: [25]string{
 			"Now organizing all the retrieved information into a coherent structure.",
@@ -3035,6 +3206,177 @@ var templateMap = map[EventName]EventTemplates{
 		},
 	},
 
+	EVENT_QUERY_REASONING_FOLLOW_UP: {
+		En: [25]string{
+			"Round %d: the draft answer is missing something (%s). Searching further: %s",
+			"Reasoning round %d found a gap (%s). Looking up: %s",
+			"Round %d review: %s. Running follow-up searches: %s",
+			"The draft from round %d is not enough yet (%s). Next queries: %s",
+			"Round %d critique: %s. Gathering more facts with: %s",
+			"Still missing pieces after round %d (%s). Searching for: %s",
+			"Round %d: more evidence is needed (%s). Follow-up queries: %s",
+			"Reviewed the round %d draft and found gaps (%s). Exploring: %s",
+			"Round %d check says the answer is incomplete (%s). Trying: %s",
+			"Round %d: not fully answered yet (%s). Digging deeper with: %s",
+			"After round %d, some links are still unclear (%s). Next up: %s",
+			"Round %d reflection: %s. Retrieving more with: %s",
+			"The round %d answer needs support (%s). Issuing follow-ups: %s",
+			"Round %d: following the trail further (%s). Queries: %s",
+			"Round %d identified missing information (%s). Searching: %s",
+			"Self-check in round %d spotted gaps (%s). Looking into: %s",
+			"Round %d: the reasoning chain is incomplete (%s). Next hops: %s",
+			"Round %d draft reviewed (%s). Collecting more context via: %s",
+			"More hops needed after round %d (%s). Follow-up searches: %s",
+			"Round %d: the answer could be stronger (%s). Investigating: %s",
+			"Critique of round %d: %s. Expanding the search with: %s",
+			"Round %d suggests additional lookups (%s). Running: %s",
+			"Round %d: evidence is thin in places (%s). Searching for: %s",
+			"Not there yet after round %d (%s). Next questions: %s",
+			"Round %d: refining the answer (%s). Additional queries: %s",
+		},
+		Ja: [25]string{
+			"ラウンド%d: 回答案に不足があります（%s）。追加で検索します: %s",
+			"推論ラウンド%dで不足を見つけました（%s）。調べる内容: %s",
+			"ラウンド%dの見直し: %s。追加検索を実行します: %s",
+			"ラウンド%dの回答案はまだ不十分です（%s）。次のクエリ: %s",
+			"ラウンド%dの批評: %s。さらに情報を集めます: %s",
+			"ラウンド%dの時点でまだ欠けている情報があります（%s）。検索内容: %s",
+			"ラウンド%d: 根拠が足りません（%s）。追加クエリ: %s",
+			"ラウンド%dの回答案を確認し、不足を見つけました（%s）。探索します: %s",
+			"ラウンド%dの確認で回答が不完全と判断されました（%s）。試すクエリ: %s",
+			"ラウンド%d: まだ答えきれていません（%s）。さらに掘り下げます: %s",
+			"ラウンド%dの後も不明なつながりがあります（%s）。次の検索: %s",
+			"ラウンド%dの振り返り: %s。追加で取得します: %s",
+			"ラウンド%dの回答には裏付けが必要です（%s）。追加検索: %s",
+			"ラウンド%d: 手がかりをさらに辿ります（%s）。クエリ: %s",
+			"ラウンド%dで不足している情報を特定しました（%s）。検索します: %s",
+			"ラウンド%dの自己点検で不足が見つかりました（%s）。調査内容: %s",
+			"ラウンド%d: 推論の連鎖がまだ途切れています（%s）。次の探索: %s",
+			"ラウンド%dの回答案を確認しました（%s）。追加のコンテキストを収集します: %s",
+			"ラウンド%dの後、さらに探索が必要です（%s）。追加検索: %s",
+			"ラウンド%d: 回答をより確かなものにします（%s）。調査内容: %s",
+			"ラウンド%dの批評: %s。検索範囲を広げます: %s",
+			"ラウンド%dで追加の参照が必要と判断しました（%s）。実行します: %s",
+			"ラウンド%d: 根拠が薄い部分があります（%s）。検索内容: %s",
+			"ラウンド%dではまだ結論に届きません（%s）。次の問い: %s",
+			"ラウンド%d: 回答を磨き上げます（%s）。追加クエリ: %s",
+		},
+	},
+
+	EVENT_QUERY_REASONING_SUFFICIENT: {
+		En: [25]string{
+			"Round %d: the draft answer is well supported.",
+			"Reasoning round %d confirmed the answer is complete.",
+			"Round %d review found no remaining gaps.",
+			"The draft from round %d covers the question fully.",
+			"Round %d: enough evidence has been gathered.",
+			"Self-check in round %d passed with no missing pieces.",
+			"Round %d: the reasoning chain is complete.",
+			"No further searches needed after round %d.",
+			"Round %d critique found the answer sufficient.",
+			"Round %d: all the needed facts are in place.",
+			"The answer was judged complete in round %d.",
+			"Round %d: the evidence supports the answer.",
+			"Reasoning finished at round %d with a sufficient answer.",
+			"Round %d: nothing important is missing.",
+			"The round %d draft answers the question.",
+			"Round %d: ready to finalize the answer.",
+			"Review in round %d is satisfied with the draft.",
+			"Round %d: the knowledge collected is enough.",
+			"Round %d confirmed every part of the question is addressed.",
+			"No gaps left after round %d.",
+			"Round %d: the answer holds together well.",
+			"Stopping the reasoning at round %d with a complete answer.",
+			"Round %d: the follow-up search is not necessary.",
+			"The answer passed the round %d check.",
+			"Round %d: reasoning is complete.",
+		},
+		Ja: [25]string{
+			"ラウンド%d: 回答案は十分な根拠に支えられています。",
+			"推論ラウンド%dで回答が完全であることを確認しました。",
+			"ラウンド%dの見直しで不足は見つかりませんでした。",
+			"ラウンド%dの回答案は質問を十分にカバーしています。",
+			"ラウンド%d: 必要な根拠が揃いました。",
+			"ラウンド%dの自己点検で欠けている情報はありませんでした。",
+			"ラウンド%d: 推論の連鎖がつながりました。",
+			"ラウンド%dの後、追加の検索は不要です。",
+			"ラウンド%dの批評で回答は十分と判断されました。",
+			"ラウンド%d: 必要な事実はすべて揃っています。",
+			"ラウンド%dで回答が完成していると判断されました。",
+			"ラウンド%d: 根拠が回答を裏付けています。",
+			"ラウンド%dで十分な回答が得られ、推論を終了しました。",
+			"ラウンド%d: 重要な情報の欠落はありません。",
+			"ラウンド%dの回答案で質問に答えられています。",
+			"ラウンド%d: 回答を確定する準備ができました。",
+			"ラウンド%dの見直しで回答案に問題はありませんでした。",
+			"ラウンド%d: 集めた知識で十分です。",
+			"ラウンド%dで質問のすべての要素に答えていることを確認しました。",
+			"ラウンド%dの時点で不足はありません。",
+			"ラウンド%d: 回答の筋道が通っています。",
+			"ラウンド%dで完全な回答が得られたため、推論を終えます。",
+			"ラウンド%d: 追加の検索は必要ありません。",
+			"回答はラウンド%dの確認を通過しました。",
+			"ラウンド%d: 推論が完了しました。",
+		},
+	},
+
+	EVENT_QUERY_HYBRID_FUSION_END: {
+		En: [25]string{
+			"Fused %d vector, %d lexical and %d graph hits into %d chunks.",
+			"Rank fusion combined %d vector, %d keyword and %d graph candidates into %d chunks.",
+			"Merged rankings: %d by meaning, %d by keywords, %d from the graph, %d selected.",
+			"Hybrid ranking done: %d vector, %d lexical, %d graph hits, %d chunks kept.",
+			"Blended %d semantic, %d keyword and %d graph results into the top %d chunks.",
+			"Combined three signals (%d vector, %d lexical, %d graph) into %d chunks.",
+			"Fusion complete: %d vector hits, %d keyword hits, %d graph hits, %d chunks chosen.",
+			"Fused %d vector, %d lexical and %d graph hits, then picked %d chunks.",
+			"Reciprocal rank fusion merged %d vector, %d lexical and %d graph hits into %d chunks.",
+			"Balanced %d meaning matches, %d word matches and %d graph links into %d chunks.",
+			"Ranking merged: %d vector, %d full-text, %d graph, %d final chunks.",
+			"Unified the vector (%d), keyword (%d) and graph (%d) rankings into %d chunks.",
+			"Cross-checked %d vector, %d lexical and %d graph candidates, keeping %d chunks.",
+			"Hybrid search fused %d vector, %d lexical and %d graph hits down to %d chunks.",
+			"Weighed %d vector, %d lexical and %d graph hits together and chose %d chunks.",
+			"Fused candidates: %d vector, %d lexical, %d graph. Using %d chunks.",
+			"Mixed %d semantic, %d lexical and %d graph hits into a single top-%d list.",
+			"The three rankings (%d vector, %d lexical, %d graph) were fused into %d chunks.",
+			"Rank fusion finished: %d vector, %d lexical and %d graph hits became %d chunks.",
+			"Combined evidence: %d vector, %d keyword, %d graph hits, %d chunks selected.",
+			"Merged %d vector, %d lexical and %d graph results; %d chunks made the cut.",
+			"Fusion of %d vector, %d lexical and %d graph hits produced %d chunks.",
+			"Hybrid ranking blended %d vector, %d lexical and %d graph hits into %d chunks.",
+			"Ranked together %d vector, %d lexical and %d graph hits, keeping the best %d.",
+			"Fused rankings ready: %d vector, %d lexical, %d graph, %d chunks.",
+		},
+		Ja: [25]string{
+			"ベクトル%d件・字句%d件・グラフ%d件のヒットを融合し、%d件のチャンクに絞りました。",
+			"順位融合で、ベクトル%d件・キーワード%d件・グラフ%d件の候補から%d件のチャンクを選びました。",
+			"ランキングを統合しました: 意味%d件、キーワード%d件、グラフ%d件、採用%d件。",
+			"ハイブリッドランキング完了: ベクトル%d件、字句%d件、グラフ%d件、採用チャンク%d件。",
+			"意味検索%d件・キーワード%d件・グラフ%d件の結果をブレンドし、上位%d件のチャンクにしました。",
+			"3つの手がかり（ベクトル%d件、字句%d件、グラフ%d件）を%d件のチャンクにまとめました。",
+			"融合完了: ベクトル%d件、キーワード%d件、グラフ%d件のヒットから%d件を選択。",
+			"ベクトル%d件・字句%d件・グラフ%d件を融合し、%d件のチャンクを選びました。",
+			"Reciprocal Rank Fusion で、ベクトル%d件・字句%d件・グラフ%d件を%d件のチャンクに統合しました。",
+			"意味の一致%d件・語の一致%d件・グラフのつながり%d件をバランスよく%d件のチャンクにまとめました。",
+			"ランキング統合: ベクトル%d件、全文検索%d件、グラフ%d件、最終チャンク%d件。",
+			"ベクトル（%d件）・キーワード（%d件）・グラフ（%d件）のランキングを%d件のチャンクに一本化しました。",
+			"ベクトル%d件・字句%d件・グラフ%d件の候補を突き合わせ、%d件のチャンクを残しました。",
+			"ハイブリッド検索で、ベクトル%d件・字句%d件・グラフ%d件を%d件のチャンクに絞り込みました。",
+			"ベクトル%d件・字句%d件・グラフ%d件のヒットを総合的に評価し、%d件のチャンクを選びました。",
+			"融合した候補: ベクトル%d件、字句%d件、グラフ%d件。%d件のチャンクを使用します。",
+			"意味%d件・字句%d件・グラフ%d件のヒットを1つの上位%d件リストにまとめました。",
+			"3つのランキング（ベクトル%d件、字句%d件、グラフ%d件）を%d件のチャンクに融合しました。",
+			"順位融合が完了しました（ベクトル%d件、字句%d件、グラフ%d件 → %d件のチャンク）。",
+			"根拠を統合しました: ベクトル%d件、キーワード%d件、グラフ%d件、採用%d件。",
+			"ベクトル%d件・字句%d件・グラフ%d件の結果を統合し、%d件のチャンクが残りました。",
+			"ベクトル%d件・字句%d件・グラフ%d件のヒットを融合し、%d件のチャンクを得ました。",
+			"ハイブリッドランキングで、ベクトル%d件・字句%d件・グラフ%d件を%d件のチャンクにまとめました。",
+			"ベクトル%d件・字句%d件・グラフ%d件をまとめて順位付けし、上位%d件を残しました。",
+			"融合ランキングの準備ができました: ベクトル%d件、字句%d件、グラフ%d件、チャンク%d件。",
+		},
+	},
+
 	EVENT_QUERY_ERROR: {
 		En: [25]string{
 			"An error occurred during search: %s",
@@ -4063,4 +4405,175 @@ var templateMap = map[EventName]EventTemplates{
 			"プロセス内でエラーが発生しました。早急な対応が必要と思われます: %s",
 		},
 	},
+	// ================================
+	// --- Migration Events ---
+	// ================================
+	EVENT_MIGRATE_EMBEDDINGS_START: {
+		En: [25]string{
+			"Starting to re-embed %[1]d items with the new embedding model %[2]s (%[3]d dimensions).",
+			"Moving the knowledge space to a new embedding model: %[2]s (%[3]d dimensions). %[1]d items will be re-embedded.",
+			"Preparing to re-express %[1]d items of knowledge with %[2]s (%[3]d dimensions).",
+			"Embedding migration has begun. %[1]d items will be re-embedded using %[2]s (%[3]d dimensions).",
+			"Switching the embedding model to %[2]s (%[3]d dimensions) and re-embedding %[1]d items.",
+			"Getting ready to rebuild the vectors of %[1]d items with %[2]s (%[3]d dimensions).",
+			"Starting the migration to %[2]s (%[3]d dimensions). There are %[1]d items to re-embed.",
+			"Beginning to translate %[1]d items of knowledge into the vector space of %[2]s (%[3]d dimensions).",
+			"Re-embedding of %[1]d items with %[2]s (%[3]d dimensions) is now under way.",
+			"The embedding model is being upgraded to %[2]s (%[3]d dimensions). %[1]d items are queued for re-embedding.",
+			"Kicking off the embedding migration: %[1]d items, new model %[2]s, %[3]d dimensions.",
+			"Now recreating the vector columns for %[2]s (%[3]d dimensions) and re-embedding %[1]d items.",
+			"Preparing a new vector space with %[2]s (%[3]d dimensions) for %[1]d items.",
+			"Started re-embedding %[1]d items so that the cube can use %[2]s (%[3]d dimensions).",
+			"Launching the migration of %[1]d embeddings to %[2]s (%[3]d dimensions).",
+			"Re-embedding all %[1]d items with %[2]s (%[3]d dimensions). Existing knowledge will be preserved.",
+			"The knowledge will now be re-embedded with %[2]s (%[3]d dimensions): %[1]d items in total.",
+			"Starting to refresh the vectors of %[1]d items using %[2]s (%[3]d dimensions).",
+			"Migration to the embedding model %[2]s (%[3]d dimensions) has started for %[1]d items.",
+			"Re-embedding %[1]d items with %[2]s (%[3]d dimensions). Searches keep using the current model until the migration completes.",
+			"Setting up the migration to %[2]s (%[3]d dimensions) and re-embedding %[1]d items one batch at a time.",
+			"Beginning the embedding migration. %[1]d items will be converted to %[2]s (%[3]d dimensions).",
+			"Starting to move %[1]d embeddings into the new %[3]d-dimensional space of %[2]s.",
+			"Re-embedding of the whole cube (%[1]d items) with %[2]s (%[3]d dimensions) has begun.",
+			"Embedding migration started: re-embedding %[1]d items with %[2]s (%[3]d dimensions) while keeping all knowledge intact.",
+		},
+		Ja: [25]string{
+			"新しい埋め込みモデル %[2]s（%[3]d 次元）で %[1]d 件の再ベクトル化を開始します。",
+			"知識空間を新しい埋め込みモデル %[2]s（%[3]d 次元）に移行します。%[1]d 件を再ベクトル化します。",
+			"%[1]d 件の知識を %[2]s（%[3]d 次元）で表現し直す準備をしています。",
+			"埋め込みモデルの移行を開始しました。%[2]s（%[3]d 次元）で %[1]d 件を再ベクトル化します。",
+			"埋め込みモデルを %[2]s（%[3]d 次元）に切り替え、%[1]d 件を再ベクトル化します。",
+			"%[2]s（%[3]d 次元）で %[1]d 件のベクトルを作り直す準備をしています。",
+			"%[2]s（%[3]d 次元）への移行を開始します。再ベクトル化の対象は %[1]d 件です。",
+			"%[1]d 件の知識を %[2]s（%[3]d 次元）のベクトル空間へ移し始めます。",
+			"%[2]s（%[3]d 次元）による %[1]d 件の再ベクトル化を進めています。",
+			"埋め込みモデルを %[2]s（%[3]d 次元）に更新しています。%[1]d 件が再ベクトル化の対象です。",
+			"埋め込みモデルの移行を開始：%[1]d 件、新しいモデルは %[2]s（%[3]d 次元）です。",
+			"%[2]s（%[3]d 次元）用のベクトルカラムを作成し、%[1]d 件を再ベクトル化します。",
+			"%[1]d 件のために %[2]s（%[3]d 次元）の新しいベクトル空間を準備しています。",
+			"Cube で %[2]s（%[3]d 次元）を使用できるよう、%[1]d 件の再ベクトル化を開始しました。",
+			"%[1]d 件の埋め込みを %[2]s（%[3]d 次元）へ移行し始めます。",
+			"%[2]s（%[3]d 次元）で全 %[1]d 件を再ベクトル化します。既存の知識はそのまま保持されます。",
+			"これから知識を %[2]s（%[3]d 次元）で再ベクトル化します。対象は合計 %[1]d 件です。",
+			"%[2]s（%[3]d 次元）を使って %[1]d 件のベクトルを更新し始めます。",
+			"埋め込みモデル %[2]s（%[3]d 次元）への移行を %[1]d 件について開始しました。",
+			"%[2]s（%[3]d 次元）で %[1]d 件を再ベクトル化します。移行が完了するまで、検索には現在のモデルが使われます。",
+			"%[2]s（%[3]d 次元）への移行を準備し、%[1]d 件をバッチごとに再ベクトル化します。",
+			"埋め込みモデルの移行を始めます。%[1]d 件を %[2]s（%[3]d 次元）に変換します。",
+			"%[1]d 件の埋め込みを %[2]s の %[3]d 次元の空間へ移し始めます。",
+			"Cube 全体（%[1]d 件）の %[2]s（%[3]d 次元）による再ベクトル化を開始しました。",
+			"埋め込みモデルの移行を開始しました：すべての知識を保ったまま、%[2]s（%[3]d 次元）で %[1]d 件を再ベクトル化します。",
+		},
+	},
+	EVENT_MIGRATE_EMBEDDINGS_PROGRESS: {
+		En: [25]string{
+			"Re-embedded %[2]d of %[3]d items (now processing %[1]s).",
+			"Progress: %[2]d / %[3]d items re-embedded. Working on %[1]s.",
+			"%[2]d of %[3]d items are now in the new vector space (%[1]s).",
+			"Another batch of %[1]s has been re-embedded: %[2]d / %[3]d done.",
+			"Migration is moving forward: %[2]d out of %[3]d items completed (%[1]s).",
+			"Finished a batch in %[1]s. %[2]d of %[3]d items have been re-embedded so far.",
+			"%[2]d / %[3]d items converted to the new embedding model (%[1]s).",
+			"Steady progress on %[1]s: %[2]d of %[3]d items re-embedded.",
+			"Re-embedding continues: %[2]d of %[3]d items completed, currently in %[1]s.",
+			"Updated the vectors of another batch in %[1]s (%[2]d / %[3]d).",
+			"%[2]d items out of %[3]d now use the new embedding model. Current table: %[1]s.",
+			"Processing %[1]s: %[2]d of %[3]d items have been re-embedded.",
+			"Completed another step of the migration in %[1]s: %[2]d / %[3]d.",
+			"The new vectors for %[1]s are being written: %[2]d of %[3]d items done.",
+			"Moving along nicely: %[2]d / %[3]d items re-embedded (%[1]s).",
+			"Batch complete in %[1]s. Total progress: %[2]d of %[3]d items.",
+			"So far %[2]d of %[3]d items have been re-embedded, working through %[1]s.",
+			"Another batch of %[1]s is now in the new vector space (%[2]d / %[3]d).",
+			"Re-embedded items: %[2]d / %[3]d. Currently handling %[1]s.",
+			"Migration progress for %[1]s: %[2]d of %[3]d items finished.",
+			"Keeping the momentum: %[2]d of %[3]d items converted (%[1]s).",
+			"%[1]s batch finished. %[2]d of %[3]d items now have new embeddings.",
+			"Re-embedding is %[2]d / %[3]d complete, with %[1]s in progress.",
+			"Wrote new embeddings for another batch of %[1]s: %[2]d out of %[3]d.",
+			"Making progress on the embedding migration: %[2]d / %[3]d items (%[1]s).",
+		},
+		Ja: [25]string{
+			"%[3]d 件中 %[2]d 件の再ベクトル化が完了しました（%[1]s を処理中）。",
+			"進捗：%[2]d / %[3]d 件を再ベクトル化しました。%[1]s を処理しています。",
+			"%[3]d 件中 %[2]d 件が新しいベクトル空間に移行しました（%[1]s）。",
+			"%[1]s の次のバッチを再ベクトル化しました：%[2]d / %[3]d 件完了。",
+			"移行は順調に進んでいます：%[3]d 件中 %[2]d 件が完了しました（%[1]s）。",
+			"%[1]s のバッチが完了しました。これまでに %[3]d 件中 %[2]d 件を再ベクトル化しました。",
+			"%[2]d / %[3]d 件を新しい埋め込みモデルに変換しました（%[1]s）。",
+			"%[1]s を着実に処理しています：%[3]d 件中 %[2]d 件を再ベクトル化しました。",
+			"再ベクトル化を継続中：%[3]d 件中 %[2]d 件が完了、現在 %[1]s を処理しています。",
+			"%[1]s の次のバッチのベクトルを更新しました（%[2]d / %[3]d）。",
+			"%[3]d 件中 %[2]d 件が新しい埋め込みモデルを使用するようになりました。処理中のテーブル：%[1]s。",
+			"%[1]s を処理中：%[3]d 件中 %[2]d 件を再ベクトル化しました。",
+			"%[1]s で移行の次のステップが完了しました：%[2]d / %[3]d。",
+			"%[1]s の新しいベクトルを書き込んでいます：%[3]d 件中 %[2]d 件が完了しました。",
+			"順調に進んでいます：%[2]d / %[3]d 件を再ベクトル化しました（%[1]s）。",
+			"%[1]s のバッチが完了しました。全体の進捗：%[3]d 件中 %[2]d 件。",
+			"これまでに %[3]d 件中 %[2]d 件を再ベクトル化しました。%[1]s を処理しています。",
+			"%[1]s の次のバッチが新しいベクトル空間に入りました（%[2]d / %[3]d）。",
+			"再ベクトル化済み：%[2]d / %[3]d 件。現在 %[1]s を処理しています。",
+			"%[1]s の移行の進捗：%[3]d 件中 %[2]d 件が完了しました。",
+			"この調子で進めています：%[3]d 件中 %[2]d 件を変換しました（%[1]s）。",
+			"%[1]s のバッチが完了しました。%[3]d 件中 %[2]d 件に新しい埋め込みが設定されました。",
+			"再ベクトル化は %[2]d / %[3]d 件まで完了し、%[1]s を処理中です。",
+			"%[1]s の次のバッチに新しい埋め込みを書き込みました：%[3]d 件中 %[2]d 件。",
+			"埋め込みモデルの移行が進んでいます：%[2]d / %[3]d 件（%[1]s）。",
+		},
+	},
+	EVENT_MIGRATE_EMBEDDINGS_END: {
+		En: [25]string{
+			"Embedding migration completed. All %d items now use the new embedding model.",
+			"Successfully re-embedded %d items. The cube is now running on the new embedding model.",
+			"The migration is finished: %d items were re-embedded and the vector indexes have been rebuilt.",
+			"All %d items have been moved to the new vector space. Migration complete!",
+			"Finished switching the embedding model. %d items were re-embedded without losing any knowledge.",
+			"Re-embedding of %d items is done. Searches now use the new embedding model.",
+			"The knowledge space has been fully migrated: %d items re-embedded.",
+			"Migration wrapped up successfully with %d items re-embedded.",
+			"All done! %d items now have embeddings from the new model.",
+			"Completed the embedding migration for %d items. Everything learned so far has been preserved.",
+			"The new embedding model is now active. %d items were re-embedded.",
+			"Embedding migration finished cleanly: %d items converted.",
+			"Successfully moved %d items to the new embedding model, including all Memify results.",
+			"The cube now speaks the language of the new embedding model: %d items re-embedded.",
+			"Re-embedding complete. %d items are ready to be searched with the new model.",
+			"Migration to the new embedding model has been completed for %d items.",
+			"Finished rebuilding the vectors of %d items. The new model is in use from now on.",
+			"All %d embeddings have been regenerated. The migration is complete.",
+			"The embedding migration is complete. %d items were updated and the cube settings were switched.",
+			"Done migrating: %d items now live in the new vector space.",
+			"Wrapped up the re-embedding of %d items. The knowledge is ready with the new model.",
+			"Embedding model switch completed successfully with %d items re-embedded.",
+			"%d items were re-embedded and the old vectors have been replaced. Migration complete.",
+			"The migration has finished. All %d items were re-embedded without rebuilding the cube.",
+			"Successfully completed the embedding migration. %d items are now searchable with the new model.",
+		},
+		Ja: [25]string{
+			"埋め込みモデルの移行が完了しました。全 %d 件が新しい埋め込みモデルを使用しています。",
+			"%d 件の再ベクトル化に成功しました。Cube は新しい埋め込みモデルで動作しています。",
+			"移行が完了しました：%d 件を再ベクトル化し、ベクトルインデックスを作り直しました。",
+			"全 %d 件が新しいベクトル空間に移行しました。移行完了です！",
+			"埋め込みモデルの切り替えが完了しました。知識を失うことなく %d 件を再ベクトル化しました。",
+			"%d 件の再ベクトル化が完了しました。検索には新しい埋め込みモデルが使われます。",
+			"知識空間の移行が完了しました：%d 件を再ベクトル化しました。",
+			"%d 件を再ベクトル化し、移行を無事に終えました。",
+			"すべて完了しました！%d 件に新しいモデルの埋め込みが設定されています。",
+			"%d 件の埋め込みモデルの移行が完了しました。これまでに学んだ内容はすべて保持されています。",
+			"新しい埋め込みモデルが有効になりました。%d 件を再ベクトル化しました。",
+			"埋め込みモデルの移行が正常に終了しました：%d 件を変換しました。",
+			"Memify の結果も含め、%d 件を新しい埋め込みモデルに移行しました。",
+			"Cube は新しい埋め込みモデルの言葉を話すようになりました：%d 件を再ベクトル化しました。",
+			"再ベクトル化が完了しました。%d 件を新しいモデルで検索できます。",
+			"%d 件について、新しい埋め込みモデルへの移行が完了しました。",
+			"%d 件のベクトルの作り直しが完了しました。これからは新しいモデルが使われます。",
+			"全 %d 件の埋め込みを作り直しました。移行は完了です。",
+			"埋め込みモデルの移行が完了しました。%d 件を更新し、Cube の設定を切り替えました。",
+			"移行完了：%d 件が新しいベクトル空間に移りました。",
+			"%d 件の再ベクトル化を終えました。新しいモデルで知識を使う準備ができています。",
+			"埋め込みモデルの切り替えが正常に完了し、%d 件を再ベクトル化しました。",
+			"%d 件を再ベクトル化し、古いベクトルを置き換えました。移行完了です。",
+			"移行が完了しました。Cube を作り直すことなく、全 %d 件を再ベクトル化しました。",
+			"埋め込みモデルの移行が正常に完了しました。%d 件を新しいモデルで検索できます。",
+		},
+	},
 }
@@ -12,6 +12,7 @@ const (
 	EVENT_INFO_CONFLICT_RESOLUTION_2_START EventName = "INFO_CONFLICT_RESOLUTION_2_START" // 矛盾解決（Stage 2）が開始された時に発火する
 	EVENT_INFO_CONFLICT_RESOLUTION_2_END   EventName = "INFO_CONFLICT_RESOLUTION_2_END"   // 矛盾解決（Stage 2）が完了した時に発火する
 	EVENT_INFO_CONFLICT_DISCARDED          EventName = "INFO_CONFLICT_DISCARDED"          // 矛盾解決によりエッジが破棄された時に発火する
+	EVENT_INFO_OPERATION_CANCELLED         EventName = "INFO_OPERATION_CANCELLED"         // オペレーションがキャンセルされ、変更がロールバックされた時に発火する
 )
 
 type InfoConflictResolution1StartPayload struct {
@@ -45,6 +46,12 @@ type InfoConflictDiscardedPayload struct {
 	Reason       string // 破棄された理由
 }
 
+type InfoOperationCancelledPayload struct {
+	BasePayload
+	OperationID   string
+	OperationType string // "absorb", "memify", "query"
+}
+
 // RegisterInfoStreamer subscribes to neutral informational events and forwards them to the provided channel.
 func RegisterInfoStreamer(eb *eventbus.EventBus, ch chan<- StreamEvent) {
 	send := func(name EventName, p any) {
@@ -70,6 +77,10 @@ func RegisterInfoStreamer(eb *eventbus.EventBus, ch chan<- StreamEvent) {
 		send(EVENT_INFO_CONFLICT_DISCARDED, p)
 		return nil
 	})
+	eventbus.Subscribe(eb, string(EVENT_INFO_OPERATION_CANCELLED), func(p InfoOperationCancelledPayload) error {
+		send(EVENT_INFO_OPERATION_CANCELLED, p)
+		return nil
+	})
 }
 
 var stage1ReasonCounter uint64
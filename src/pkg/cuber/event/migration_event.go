@@ -0,0 +1,51 @@
+package event
+
+import (
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+const (
+	EVENT_MIGRATE_EMBEDDINGS_START    EventName = "MIGRATE_EMBEDDINGS_START"    // 埋め込みモデルの移行が開始された時に発火する
+	EVENT_MIGRATE_EMBEDDINGS_PROGRESS EventName = "MIGRATE_EMBEDDINGS_PROGRESS" // 埋め込みモデルの移行で、1バッチの再ベクトル化が完了した時に発火する
+	EVENT_MIGRATE_EMBEDDINGS_END      EventName = "MIGRATE_EMBEDDINGS_END"      // 埋め込みモデルの移行が完了した時に発火する
+)
+
+type MigrateEmbeddingsStartPayload struct {
+	BasePayload
+	Model      string // 移行先の埋め込みモデル
+	Dimension  uint   // 移行先の次元数
+	TotalCount int    // 再ベクトル化する行数
+}
+
+type MigrateEmbeddingsProgressPayload struct {
+	BasePayload
+	TableName  string // 再ベクトル化しているテーブル
+	DoneCount  int    // 全テーブルで再ベクトル化が完了した行数
+	TotalCount int    // 再ベクトル化する行数
+}
+
+type MigrateEmbeddingsEndPayload struct {
+	BasePayload
+	TotalCount  int
+	TotalTokens types.TokenUsage
+}
+
+// RegisterMigrationStreamer subscribes to embedding migration events and forwards them to the provided channel.
+func RegisterMigrationStreamer(eb *eventbus.EventBus, ch chan<- StreamEvent) {
+	send := func(name EventName, p any) {
+		ch <- StreamEvent{EventName: name, Payload: p}
+	}
+	eventbus.Subscribe(eb, string(EVENT_MIGRATE_EMBEDDINGS_START), func(p MigrateEmbeddingsStartPayload) error {
+		send(EVENT_MIGRATE_EMBEDDINGS_START, p)
+		return nil
+	})
+	eventbus.Subscribe(eb, string(EVENT_MIGRATE_EMBEDDINGS_PROGRESS), func(p MigrateEmbeddingsProgressPayload) error {
+		send(EVENT_MIGRATE_EMBEDDINGS_PROGRESS, p)
+		return nil
+	})
+	eventbus.Subscribe(eb, string(EVENT_MIGRATE_EMBEDDINGS_END), func(p MigrateEmbeddingsEndPayload) error {
+		send(EVENT_MIGRATE_EMBEDDINGS_END, p)
+		return nil
+	})
+}
@@ -6,21 +6,24 @@ import (
 )
 
 const (
-	EVENT_QUERY_START               EventName = "QUERY_START"               // クエリ処理全体が開始された時に発火する
-	EVENT_QUERY_EMBEDDING_START     EventName = "QUERY_EMBEDDING_START"     // クエリテキストの埋め込みベクトル生成処理が開始された時に発火する
-	EVENT_QUERY_EMBEDDING_END       EventName = "QUERY_EMBEDDING_END"       // クエリテキストの埋め込みベクトル生成処理が完了した時に発火する
-	EVENT_QUERY_SEARCH_VECTOR_START EventName = "QUERY_SEARCH_VECTOR_START" // ベクトル検索（チャンク、サマリー、エンティティ検索）が開始された時に発火する
-	EVENT_QUERY_SEARCH_VECTOR_END   EventName = "QUERY_SEARCH_VECTOR_END"   // ベクトル検索が完了し、ヒットした件数が確定した時に発火する
-	EVENT_QUERY_FTS_START           EventName = "QUERY_FTS_START"           // 全文検索（FTS）によるエンティティ拡張が開始された時に発火する
-	EVENT_QUERY_FTS_END             EventName = "QUERY_FTS_END"             // 全文検索によるエンティティ拡張が完了し、拡張数が確定した時に発火する
-	EVENT_QUERY_SEARCH_GRAPH_START  EventName = "QUERY_SEARCH_GRAPH_START"  // 知識グラフの探索処理が開始された時に発火する
-	EVENT_QUERY_SEARCH_GRAPH_END    EventName = "QUERY_SEARCH_GRAPH_END"    // 知識グラフの探索処理が完了し、関連するトリプルが見つかった時に発火する
-	EVENT_QUERY_CONTEXT_START       EventName = "QUERY_CONTEXT_START"       // LLMに渡すコンテキスト（検索結果の統合）の構築が開始された時に発火する
-	EVENT_QUERY_CONTEXT_END         EventName = "QUERY_CONTEXT_END"         // LLMに渡すコンテキストの構築が完了した時に発火する
-	EVENT_QUERY_GENERATION_START    EventName = "QUERY_GENERATION_START"    // 最終的な回答生成のためのLLMリクエストが開始された時に発火する
-	EVENT_QUERY_GENERATION_END      EventName = "QUERY_GENERATION_END"      // 最終的な回答生成が完了した時に発火する
-	EVENT_QUERY_END                 EventName = "QUERY_END"                 // クエリ処理全体が正常に完了した時に発火する
-	EVENT_QUERY_ERROR               EventName = "QUERY_ERROR"               // クエリ処理中にエラーが発生した時に発火する
+	EVENT_QUERY_START                EventName = "QUERY_START"                // クエリ処理全体が開始された時に発火する
+	EVENT_QUERY_EMBEDDING_START      EventName = "QUERY_EMBEDDING_START"      // クエリテキストの埋め込みベクトル生成処理が開始された時に発火する
+	EVENT_QUERY_EMBEDDING_END        EventName = "QUERY_EMBEDDING_END"        // クエリテキストの埋め込みベクトル生成処理が完了した時に発火する
+	EVENT_QUERY_SEARCH_VECTOR_START  EventName = "QUERY_SEARCH_VECTOR_START"  // ベクトル検索（チャンク、サマリー、エンティティ検索）が開始された時に発火する
+	EVENT_QUERY_SEARCH_VECTOR_END    EventName = "QUERY_SEARCH_VECTOR_END"    // ベクトル検索が完了し、ヒットした件数が確定した時に発火する
+	EVENT_QUERY_FTS_START            EventName = "QUERY_FTS_START"            // 全文検索（FTS）によるエンティティ拡張が開始された時に発火する
+	EVENT_QUERY_FTS_END              EventName = "QUERY_FTS_END"              // 全文検索によるエンティティ拡張が完了し、拡張数が確定した時に発火する
+	EVENT_QUERY_SEARCH_GRAPH_START   EventName = "QUERY_SEARCH_GRAPH_START"   // 知識グラフの探索処理が開始された時に発火する
+	EVENT_QUERY_SEARCH_GRAPH_END     EventName = "QUERY_SEARCH_GRAPH_END"     // 知識グラフの探索処理が完了し、関連するトリプルが見つかった時に発火する
+	EVENT_QUERY_CONTEXT_START        EventName = "QUERY_CONTEXT_START"        // LLMに渡すコンテキスト（検索結果の統合）の構築が開始された時に発火する
+	EVENT_QUERY_CONTEXT_END          EventName = "QUERY_CONTEXT_END"          // LLMに渡すコンテキストの構築が完了した時に発火する
+	EVENT_QUERY_GENERATION_START     EventName = "QUERY_GENERATION_START"     // 最終的な回答生成のためのLLMリクエストが開始された時に発火する
+	EVENT_QUERY_GENERATION_END       EventName = "QUERY_GENERATION_END"       // 最終的な回答生成が完了した時に発火する
+	EVENT_QUERY_REASONING_FOLLOW_UP  EventName = "QUERY_REASONING_FOLLOW_UP"  // 推論ラウンドで回答案が不十分と判断され、追加の検索クエリが生成された時に発火する
+	EVENT_QUERY_REASONING_SUFFICIENT EventName = "QUERY_REASONING_SUFFICIENT" // 推論ラウンドで回答案が十分と判断された時に発火する
+	EVENT_QUERY_HYBRID_FUSION_END    EventName = "QUERY_HYBRID_FUSION_END"    // ハイブリッド検索で各ランキングの融合が完了し、回答に使うチャンクが確定した時に発火する
+	EVENT_QUERY_END                  EventName = "QUERY_END"                  // クエリ処理全体が正常に完了した時に発火する
+	EVENT_QUERY_ERROR                EventName = "QUERY_ERROR"                // クエリ処理中にエラーが発生した時に発火する
 )
 
 type QueryStartPayload struct {
@@ -105,6 +108,27 @@ type QueryGenerationEndPayload struct {
 	Response   string
 }
 
+type QueryReasoningFollowUpPayload struct {
+	BasePayload
+	Round           int    // 推論ラウンド（1始まり）
+	Critique        string // 回答案に対する批評（不足している情報）
+	FollowUpQueries string // 追加の検索クエリ（改行区切り）
+}
+
+type QueryReasoningSufficientPayload struct {
+	BasePayload
+	Round    int    // 推論ラウンド（1始まり）
+	Critique string // 回答案に対する批評
+}
+
+type QueryHybridFusionEndPayload struct {
+	BasePayload
+	VectorCount  int // ベクトル検索でヒットしたチャンク数
+	LexicalCount int // FTS（全レイヤー）でヒットしたチャンク数（重複を除く）
+	GraphCount   int // グラフのエッジの出典としてヒットしたチャンク数
+	FusedCount   int // 融合後に回答に使用するチャンク数
+}
+
 type QueryErrorPayload struct {
 	BasePayload
 	QueryType    string
@@ -129,6 +153,9 @@ func RegisterQueryStreamer(eb *eventbus.EventBus, ch chan<- StreamEvent) {
 	eventbus.Subscribe(eb, string(EVENT_QUERY_CONTEXT_END), func(p QueryContextEndPayload) error { send(EVENT_QUERY_CONTEXT_END, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_QUERY_GENERATION_START), func(p QueryGenerationStartPayload) error { send(EVENT_QUERY_GENERATION_START, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_QUERY_GENERATION_END), func(p QueryGenerationEndPayload) error { send(EVENT_QUERY_GENERATION_END, p); return nil })
+	eventbus.Subscribe(eb, string(EVENT_QUERY_REASONING_FOLLOW_UP), func(p QueryReasoningFollowUpPayload) error { send(EVENT_QUERY_REASONING_FOLLOW_UP, p); return nil })
+	eventbus.Subscribe(eb, string(EVENT_QUERY_REASONING_SUFFICIENT), func(p QueryReasoningSufficientPayload) error { send(EVENT_QUERY_REASONING_SUFFICIENT, p); return nil })
+	eventbus.Subscribe(eb, string(EVENT_QUERY_HYBRID_FUSION_END), func(p QueryHybridFusionEndPayload) error { send(EVENT_QUERY_HYBRID_FUSION_END, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_QUERY_END), func(p QueryEndPayload) error { send(EVENT_QUERY_END, p); return nil })
 	eventbus.Subscribe(eb, string(EVENT_QUERY_ERROR), func(p QueryErrorPayload) error { send(EVENT_QUERY_ERROR, p); return nil })
 }
@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVExtractor は、CSV/TSV を「列名: 値」形式の行テキストに変換する抽出器です。
+// 先頭行をヘッダーとして扱い、各レコードを1行の文として出力することで、
+// チャンク分割後もレコード単位で列名と値の対応が失われないようにします。
+type CSVExtractor struct{}
+
+var _ Extractor = (*CSVExtractor)(nil)
+
+func (e *CSVExtractor) Name() string { return "csv" }
+
+func (e *CSVExtractor) Extensions() []string { return []string{".csv", ".tsv"} }
+
+func (e *CSVExtractor) MimeTypes() []string {
+	return []string{"text/csv", "text/tab-separated-values", "application/csv"}
+}
+
+func (e *CSVExtractor) Extract(ctx context.Context, path string) (*Extracted, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// UTF-8 BOM を除去
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("CSV must be UTF-8 encoded")
+	}
+	r := csv.NewReader(bytes.NewReader(content))
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var header []string
+	var lines []string
+	rows := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse CSV: %w", err)
+		}
+		if header == nil {
+			header = record
+			continue
+		}
+		rows++
+		if line := formatRecord(header, record); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return &Extracted{
+		Sections: []Section{{Kind: SECTION_KIND_TABLE, Index: 1, Name: filepath.Base(path), Text: strings.Join(lines, "\n")}},
+		MetaData: map[string]any{"columns": header, "rows": rows},
+	}, nil
+}
+
+// formatRecord は、ヘッダーとレコードから「列名: 値; 列名: 値.」形式の1行を生成します。
+// 空の値は省略し、ヘッダーより列数が多い場合は「列N」を列名とします。
+// 行末に句点を付与するのは、ChunkingTask の文分割でレコードが1文として扱われるようにするためです。
+func formatRecord(header []string, record []string) string {
+	var parts []string
+	for i, v := range record {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		name := ""
+		if i < len(header) {
+			name = strings.TrimSpace(header[i])
+		}
+		if name == "" {
+			name = fmt.Sprintf("列%d", i+1)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", name, v))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "; ") + "."
+}
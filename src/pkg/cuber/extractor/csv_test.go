@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVExtractor(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantText string
+		wantRows int
+	}{
+		{
+			name:     "csv with bom and quoted fields",
+			file:     "members.csv",
+			content:  "\xef\xbb\xbfname,age,note\nTaro,30,\"likes, commas\"\nHanako,,\n,,\n",
+			wantText: "name: Taro; age: 30; note: likes, commas.\nname: Hanako.",
+			wantRows: 3,
+		},
+		{
+			name:     "tsv with extra columns",
+			file:     "members.tsv",
+			content:  "name\tage\nJiro\t40\textra\n",
+			wantText: "name: Jiro; age: 40; 列3: extra.",
+			wantRows: 1,
+		},
+		{
+			name:     "header only",
+			file:     "empty.csv",
+			content:  "a,b\n",
+			wantText: "",
+			wantRows: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex, err := (&CSVExtractor{}).Extract(context.Background(), writeFixture(t, tt.file, []byte(tt.content)))
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			want := []Section{{Kind: SECTION_KIND_TABLE, Index: 1, Name: tt.file, Text: tt.wantText}}
+			if !reflect.DeepEqual(ex.Sections, want) {
+				t.Errorf("Unexpected sections: %+v", ex.Sections)
+			}
+			if ex.MetaData["rows"] != tt.wantRows {
+				t.Errorf("Unexpected rows: %v", ex.MetaData["rows"])
+			}
+		})
+	}
+}
+
+func TestCSVExtractorRejectsNonUTF8(t *testing.T) {
+	// Shift_JIS の「名前」
+	_, err := (&CSVExtractor{}).Extract(context.Background(), writeFixture(t, "sjis.csv", []byte("\x96\xbc\x91\x4f,age\n")))
+	if err == nil || !strings.Contains(err.Error(), "UTF-8") {
+		t.Errorf("Expected UTF-8 error, got %v", err)
+	}
+}
+
+func TestExtractDispatch(t *testing.T) {
+	p := writeFixture(t, "fixture.bin", []byte("a,b\n1,2\n"))
+	ex, err := Extract(context.Background(), p, ".CSV", "")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if ex.Format != "csv" {
+		t.Errorf("Expected csv extractor by extension, got %q", ex.Format)
+	}
+	ex, err = Extract(context.Background(), p, "", "text/csv; charset=utf-8")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if ex.Format != "csv" {
+		t.Errorf("Expected csv extractor by MIME type, got %q", ex.Format)
+	}
+	ex, err = Extract(context.Background(), p, ".unknown", "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if ex.Format != "text" || !ex.Markup {
+		t.Errorf("Expected text fallback, got %q", ex.Format)
+	}
+	_, err = Extract(context.Background(), writeFixture(t, "broken.pdf", []byte("garbage")), ".pdf", "")
+	if err == nil || !strings.HasPrefix(err.Error(), "Extractor(pdf): ") {
+		t.Errorf("Expected wrapped pdf error, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOCXExtractor は、Word 文書（.docx）の本文を抽出する抽出器です。
+// Word が保存時に記録する改ページ位置（w:lastRenderedPageBreak）と明示的な改ページ（w:br w:type="page"）で
+// ページを区切り、ページ情報を持たない文書は本文全体を1セクションとして返します。
+type DOCXExtractor struct{}
+
+var _ Extractor = (*DOCXExtractor)(nil)
+
+func (e *DOCXExtractor) Name() string { return "docx" }
+
+func (e *DOCXExtractor) Extensions() []string { return []string{".docx", ".docm"} }
+
+func (e *DOCXExtractor) MimeTypes() []string {
+	return []string{
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.ms-word.document.macroenabled.12",
+	}
+}
+
+func (e *DOCXExtractor) Extract(ctx context.Context, p string) (*Extracted, error) {
+	zr, files, err := openZip(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	b, err := readZipEntry(files, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+	pages, err := parseDocxPages(b)
+	if err != nil {
+		return nil, err
+	}
+	ex := &Extracted{MetaData: map[string]any{}}
+	if title := readCoreTitle(files); title != "" {
+		ex.MetaData["title"] = title
+	}
+	kind := SECTION_KIND_PAGE
+	if len(pages) == 1 {
+		kind = SECTION_KIND_BODY
+	}
+	for i, text := range pages {
+		// 空ページは出力しないが、ページ番号は維持する
+		if text == "" {
+			continue
+		}
+		ex.Sections = append(ex.Sections, Section{Kind: kind, Index: i + 1, Text: text})
+	}
+	if kind == SECTION_KIND_PAGE {
+		ex.MetaData["page_count"] = len(pages)
+	}
+	return ex, nil
+}
+
+// parseDocxPages は、word/document.xml の本文をページごとのテキストに分割します。
+// 返り値は常に1要素以上で、空ページは空文字列として含まれます。
+func parseDocxPages(b []byte) ([]string, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(b)))
+	var pages []string
+	var sb strings.Builder
+	inText := false
+	flush := func() {
+		pages = append(pages, squeezeBlankLines(sb.String()))
+		sb.Reset()
+	}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse word/document.xml: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString(" ")
+			case "lastRenderedPageBreak":
+				flush()
+			case "br":
+				isPageBreak := false
+				for _, a := range t.Attr {
+					if a.Name.Local == "type" && a.Value == "page" {
+						isPageBreak = true
+					}
+				}
+				if isPageBreak {
+					flush()
+				} else {
+					sb.WriteString("\n")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p", "tc":
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	flush()
+	return pages, nil
+}
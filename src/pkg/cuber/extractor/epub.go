@@ -0,0 +1,121 @@
+package extractor
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// EPUBExtractor は、EPUB を spine（読み順）の項目ごとに章として抽出する抽出器です。
+type EPUBExtractor struct{}
+
+var _ Extractor = (*EPUBExtractor)(nil)
+
+func (e *EPUBExtractor) Name() string { return "epub" }
+
+func (e *EPUBExtractor) Extensions() []string { return []string{".epub"} }
+
+func (e *EPUBExtractor) MimeTypes() []string { return []string{"application/epub+zip"} }
+
+// xhtmlTextOptions は、EPUB の XHTML 本文からテキストを取り出す設定です。
+var xhtmlTextOptions = xmlTextOptions{
+	BreakElements: set("p", "div", "br", "li", "tr", "section", "article", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6", "dt", "dd", "figcaption"),
+	TabElements:   set("td", "th"),
+	SkipElements:  set("head", "script", "style", "nav"),
+	HTML:          true,
+}
+
+func (e *EPUBExtractor) Extract(ctx context.Context, p string) (*Extracted, error) {
+	zr, files, err := openZip(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	// 1. container.xml からパッケージ文書（OPF）の場所を取得
+	cb, err := readZipEntry(files, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(cb, &container); err != nil {
+		return nil, fmt.Errorf("Failed to parse container.xml: %w", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("No rootfile in container.xml")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	// 2. OPF から manifest と spine を取得
+	ob, err := readZipEntry(files, opfPath)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Title    []string `xml:"metadata>title"`
+		Creator  []string `xml:"metadata>creator"`
+		Language []string `xml:"metadata>language"`
+		Items    []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(ob, &pkg); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", opfPath, err)
+	}
+	hrefs := make(map[string]string, len(pkg.Items))
+	for _, it := range pkg.Items {
+		if it.MediaType != "application/xhtml+xml" && it.MediaType != "text/html" {
+			continue
+		}
+		href, err := url.PathUnescape(it.Href)
+		if err != nil {
+			href = it.Href
+		}
+		hrefs[it.ID] = path.Clean(path.Join(path.Dir(opfPath), href))
+	}
+	ex := &Extracted{MetaData: map[string]any{}}
+	if len(pkg.Title) > 0 {
+		ex.MetaData["title"] = strings.TrimSpace(pkg.Title[0])
+	}
+	if len(pkg.Creator) > 0 {
+		ex.MetaData["author"] = strings.TrimSpace(pkg.Creator[0])
+	}
+	if len(pkg.Language) > 0 {
+		ex.MetaData["language"] = strings.TrimSpace(pkg.Language[0])
+	}
+	// 3. spine 順に本文を抽出
+	for i, ref := range pkg.Spine {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		part, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		b, err := readZipEntry(files, part)
+		if err != nil {
+			return nil, err
+		}
+		text, err := collectXMLText(b, xhtmlTextOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse %s: %w", part, err)
+		}
+		if text == "" {
+			continue
+		}
+		name, _ := collectXMLText(b, xmlTextOptions{TextElements: set("title"), HTML: true})
+		ex.Sections = append(ex.Sections, Section{Kind: SECTION_KIND_CHAPTER, Index: i + 1, Name: strings.TrimSpace(name), Text: text})
+	}
+	ex.MetaData["chapter_count"] = len(pkg.Spine)
+	return ex, nil
+}
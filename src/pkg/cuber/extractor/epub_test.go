@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testContainerXML = `<?xml version="1.0"?><container xmlns="urn:oasis:names:tc:opendocument:xmlns:container">` +
+	`<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`
+
+func TestEPUBExtractor(t *testing.T) {
+	opf := `<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<metadata><dc:title> Fixture Book </dc:title><dc:creator>Author</dc:creator><dc:language>ja</dc:language></metadata>` +
+		`<manifest>` +
+		`<item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>` +
+		`<item id="c2" href="text/chapter2.xhtml" media-type="application/xhtml+xml"/>` +
+		`<item id="css" href="style.css" media-type="text/css"/>` +
+		`</manifest>` +
+		`<spine><itemref idref="c2"/><itemref idref="css"/><itemref idref="c1"/></spine></package>`
+	ch1 := `<html><head><title>One</title><style>p{}</style></head><body><p>First&nbsp;chapter</p><nav>toc</nav></body></html>`
+	ch2 := `<html><head><title>Two</title></head><body><h1>Heading</h1><p>Second<br>line</p></body></html>`
+	p := writeFixture(t, "fixture.epub", buildZip(t, map[string]string{
+		"META-INF/container.xml":     testContainerXML,
+		"OEBPS/content.opf":          opf,
+		"OEBPS/text/chapter 1.xhtml": ch1,
+		"OEBPS/text/chapter2.xhtml":  ch2,
+		"OEBPS/style.css":            "p{}",
+	}))
+	ex, err := (&EPUBExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := []Section{
+		{Kind: SECTION_KIND_CHAPTER, Index: 1, Name: "Two", Text: "Heading\nSecond\nline"},
+		{Kind: SECTION_KIND_CHAPTER, Index: 3, Name: "One", Text: "First\u00a0chapter"},
+	}
+	if !reflect.DeepEqual(ex.Sections, want) {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+	if ex.MetaData["title"] != "Fixture Book" || ex.MetaData["author"] != "Author" || ex.MetaData["language"] != "ja" {
+		t.Errorf("Unexpected metadata: %v", ex.MetaData)
+	}
+	if ex.MetaData["chapter_count"] != 3 {
+		t.Errorf("Unexpected chapter_count: %v", ex.MetaData["chapter_count"])
+	}
+}
+
+func TestEPUBExtractorMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		wantErr string
+	}{
+		{"missing container", map[string]string{"mimetype": "application/epub+zip"}, "Entry not found in container: META-INF/container.xml"},
+		{"broken container", map[string]string{"META-INF/container.xml": "<container><rootfiles>"}, "Failed to parse container.xml"},
+		{"no rootfile", map[string]string{"META-INF/container.xml": "<container><rootfiles/></container>"}, "No rootfile"},
+		{"missing opf", map[string]string{"META-INF/container.xml": testContainerXML}, "Entry not found in container: OEBPS/content.opf"},
+		{"missing chapter", map[string]string{
+			"META-INF/container.xml": testContainerXML,
+			"OEBPS/content.opf": `<package><manifest><item id="c1" href="missing.xhtml" media-type="application/xhtml+xml"/></manifest>` +
+				`<spine><itemref idref="c1"/></spine></package>`,
+		}, "Entry not found in container: OEBPS/missing.xhtml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&EPUBExtractor{}).Extract(context.Background(), writeFixture(t, "fixture.epub", buildZip(t, tt.entries)))
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
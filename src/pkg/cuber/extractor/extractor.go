@@ -0,0 +1,179 @@
+// Package extractor は、取り込みファイルからテキストを抽出するプラガブルな抽出層を提供します。
+// 抽出器は拡張子（storage.Data.Extension）および MIME タイプ（storage.Data.MimeType）をキーに登録され、
+// ChunkingTask はここで得たテキストとセクション（ページ/シート/スライド/章）情報を Document に保存します。
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// セクション種別
+const (
+	SECTION_KIND_TEXT    = "text"    // プレーンテキスト（分割なし）
+	SECTION_KIND_PAGE    = "page"    // PDF のページ
+	SECTION_KIND_SHEET   = "sheet"   // スプレッドシートのシート
+	SECTION_KIND_SLIDE   = "slide"   // プレゼンテーションのスライド
+	SECTION_KIND_CHAPTER = "chapter" // EPUB の章（spine の各項目）
+	SECTION_KIND_BODY    = "body"    // 文書本文（DOCX）
+	SECTION_KIND_TABLE   = "table"   // 表形式データ（CSV/TSV）
+)
+
+// Section は、抽出されたテキストの論理的な区切り（ページ・シート等）を表します。
+type Section struct {
+	Kind  string // セクション種別（SECTION_KIND_*）
+	Index int    // 1始まりの通し番号
+	Name  string // シート名・章タイトルなど（無い場合は空）
+	Text  string // セクションのテキスト
+}
+
+// Extracted は、1ファイル分の抽出結果です。
+type Extracted struct {
+	Format   string         // 抽出器の名前（"pdf", "docx" 等）
+	Sections []Section      // 出現順のセクション
+	Markup   bool           // true の場合、HTML/Markdown を含み得るため CommonNormalize が必要
+	MetaData map[string]any // 形式固有のメタデータ（タイトル等）。Document.MetaData にマージされる
+}
+
+// Extractor は、ファイル形式ごとのテキスト抽出器のインターフェースです。
+type Extractor interface {
+	// Name は抽出器の名前を返します（Document.MetaData の format に記録されます）。
+	Name() string
+	// Extensions は対応する拡張子（".pdf" のようにドット付き・小文字）を返します。
+	Extensions() []string
+	// MimeTypes は対応する MIME タイプを返します。先頭が代表値として使用されます。
+	MimeTypes() []string
+	// Extract はローカルファイルからテキストを抽出します。
+	Extract(ctx context.Context, path string) (*Extracted, error)
+}
+
+var (
+	registryMu  sync.RWMutex
+	byExtension = map[string]Extractor{}
+	byMimeType  = map[string]Extractor{}
+	fallback    Extractor
+)
+
+func init() {
+	Register(&TextExtractor{})
+	Register(&CSVExtractor{})
+	Register(&PDFExtractor{})
+	Register(&DOCXExtractor{})
+	Register(&XLSXExtractor{})
+	Register(&PPTXExtractor{})
+	Register(&EPUBExtractor{})
+	fallback = &TextExtractor{}
+}
+
+// Register は、抽出器を拡張子と MIME タイプに対して登録します。
+// 既に同じキーで登録されている抽出器は上書きされます。
+func Register(e Extractor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, ext := range e.Extensions() {
+		byExtension[strings.ToLower(ext)] = e
+	}
+	for _, mt := range e.MimeTypes() {
+		byMimeType[strings.ToLower(mt)] = e
+	}
+}
+
+// Find は、拡張子・MIME タイプの順に抽出器を検索します。
+// 引数:
+//   - extension: ドット付きの拡張子（大文字小文字は区別しない）
+//   - mimeType: MIME タイプ（パラメータ付きでも可）
+//
+// 返り値:
+//   - Extractor: 見つかった抽出器
+//   - bool: 見つかった場合 true
+func Find(extension string, mimeType string) (Extractor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if e, ok := byExtension[strings.ToLower(extension)]; ok {
+		return e, true
+	}
+	if mt, _, err := mime.ParseMediaType(mimeType); err == nil {
+		if e, ok := byMimeType[strings.ToLower(mt)]; ok {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// IsSupported は、ファイル名の拡張子に対応する抽出器が登録されているかを返します。
+// 拡張子の無いファイルはプレーンテキストとして扱うため true を返します。
+func IsSupported(fileName string) bool {
+	ext := filepath.Ext(fileName)
+	if ext == "" {
+		return true
+	}
+	_, ok := Find(ext, "")
+	return ok
+}
+
+// SupportedExtensions は、登録されている拡張子の一覧をソートして返します。
+func SupportedExtensions() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	exts := make([]string, 0, len(byExtension))
+	for ext := range byExtension {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// DetectMimeType は、ファイルの MIME タイプを判定します。
+// 登録済み抽出器の代表 MIME タイプ、標準ライブラリの拡張子テーブル、先頭 512 バイトのスニッフィングの順に判定します。
+func DetectMimeType(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if e, ok := Find(ext, ""); ok {
+		if mts := e.MimeTypes(); len(mts) > 0 {
+			return mts[0]
+		}
+	}
+	if mt := mime.TypeByExtension(ext); mt != "" {
+		return mt
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	return http.DetectContentType(head[:n])
+}
+
+// Extract は、拡張子・MIME タイプに応じた抽出器でファイルからテキストを抽出します。
+// 対応する抽出器が無い場合はプレーンテキストとして読み込みます。
+// 引数:
+//   - ctx: コンテキスト
+//   - path: ローカルファイルパス
+//   - extension: storage.Data.Extension
+//   - mimeType: storage.Data.MimeType
+//
+// 返り値:
+//   - *Extracted: 抽出結果
+//   - error: 抽出に失敗した場合
+func Extract(ctx context.Context, path string, extension string, mimeType string) (*Extracted, error) {
+	e, ok := Find(extension, mimeType)
+	if !ok {
+		e = fallback
+	}
+	ex, err := e.Extract(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("Extractor(%s): %w", e.Name(), err)
+	}
+	if ex.Format == "" {
+		ex.Format = e.Name()
+	}
+	return ex, nil
+}
@@ -0,0 +1,205 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxZipEntrySize は、zip 内の1エントリから読み込む最大バイト数です（zip bomb 対策）。
+const maxZipEntrySize = 256 << 20
+
+var trailingNumberRe = regexp.MustCompile(`(\d+)\.[A-Za-z]+$`)
+
+// openZip は、OOXML / EPUB などの zip コンテナを開きます。
+func openZip(p string) (*zip.ReadCloser, map[string]*zip.File, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to open zip container: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	return zr, files, nil
+}
+
+// readZipEntry は、zip 内のエントリを読み込みます。
+func readZipEntry(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("Entry not found in container: %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open entry %s: %w", name, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxZipEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read entry %s: %w", name, err)
+	}
+	if len(b) > maxZipEntrySize {
+		return nil, fmt.Errorf("Entry too large: %s", name)
+	}
+	return b, nil
+}
+
+// opcRelationship は、OPC パッケージの .rels に記載されるリレーションです。
+type opcRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+// readRelationships は、part（例: "xl/workbook.xml"）に対応する .rels を読み込み、ID→絶対パスのマップを返します。
+func readRelationships(files map[string]*zip.File, part string) (map[string]string, error) {
+	relsName := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	b, err := readZipEntry(files, relsName)
+	if err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Items []opcRelationship `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(b, &rels); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", relsName, err)
+	}
+	res := make(map[string]string, len(rels.Items))
+	for _, r := range rels.Items {
+		res[r.ID] = resolvePartPath(part, r.Target)
+	}
+	return res, nil
+}
+
+// resolvePartPath は、part からの相対ターゲットをパッケージ内の絶対パスに解決します。
+func resolvePartPath(part string, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Clean(path.Join(path.Dir(part), target))
+}
+
+// sortedPartsByNumber は、prefix に一致するエントリ（例: "ppt/slides/slide"）を末尾の番号順に返します。
+func sortedPartsByNumber(files map[string]*zip.File, prefix string, suffix string) []string {
+	var names []string
+	for name := range files {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) && !strings.Contains(strings.TrimPrefix(name, prefix), "/") {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return partNumber(names[i]) < partNumber(names[j])
+	})
+	return names
+}
+
+func partNumber(name string) int {
+	m := trailingNumberRe.FindStringSubmatch(name)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// xmlTextOptions は、collectXMLText の挙動を指定します。
+type xmlTextOptions struct {
+	TextElements  map[string]bool // 文字データを採用する要素のローカル名（空なら全要素）
+	BreakElements map[string]bool // 終了時に改行を挿入する要素のローカル名
+	TabElements   map[string]bool // 出現時にタブ（空白）を挿入する要素のローカル名
+	SkipElements  map[string]bool // 配下を丸ごと無視する要素のローカル名
+	HTML          bool            // true の場合、HTML のエンティティ・自動クローズを許容する
+}
+
+// collectXMLText は、XML からテキストを抽出します。
+// 段落など BreakElements の終端で改行し、連続する空行は1つにまとめます。
+func collectXMLText(b []byte, opt xmlTextOptions) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(string(b)))
+	if opt.HTML {
+		dec.Strict = false
+		dec.AutoClose = xml.HTMLAutoClose
+		dec.Entity = xml.HTMLEntity
+	}
+	var sb strings.Builder
+	var stack []string
+	skipDepth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("Failed to parse XML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			stack = append(stack, name)
+			if skipDepth > 0 || opt.SkipElements[name] {
+				skipDepth++
+				continue
+			}
+			if opt.TabElements[name] {
+				sb.WriteString(" ")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if opt.BreakElements[name] {
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if skipDepth > 0 || len(stack) == 0 {
+				continue
+			}
+			if len(opt.TextElements) > 0 && !opt.TextElements[stack[len(stack)-1]] {
+				continue
+			}
+			sb.Write(t)
+		}
+	}
+	return squeezeBlankLines(sb.String()), nil
+}
+
+var blankLinesRe = regexp.MustCompile(`\n[ \t\r]*(\n[ \t\r]*)+`)
+
+// squeezeBlankLines は、連続する空行を1つの空行にまとめ、前後の空白を除去します。
+func squeezeBlankLines(s string) string {
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(s, "\n\n"))
+}
+
+// readCoreTitle は、OOXML の docProps/core.xml から dc:title を取得します（無ければ空）。
+func readCoreTitle(files map[string]*zip.File) string {
+	b, err := readZipEntry(files, "docProps/core.xml")
+	if err != nil {
+		return ""
+	}
+	var core struct {
+		Title string `xml:"title"`
+	}
+	if err := xml.Unmarshal(b, &core); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(core.Title)
+}
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
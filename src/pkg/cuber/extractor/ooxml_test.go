@@ -0,0 +1,200 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildZip は、パス→内容のマップから zip コンテナを組み立てます。
+func buildZip(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const (
+	testCoreXML = `<?xml version="1.0"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Fixture Title</dc:title></cp:coreProperties>`
+	testRelNS   = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+)
+
+func TestDOCXExtractorPages(t *testing.T) {
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>First</w:t><w:tab/><w:t>page</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:br w:type="page"/><w:t>Second page</w:t></w:r></w:p>` +
+		`</w:body></w:document>`
+	p := writeFixture(t, "fixture.docx", buildZip(t, map[string]string{
+		"word/document.xml": doc,
+		"docProps/core.xml": testCoreXML,
+	}))
+	ex, err := (&DOCXExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := []Section{
+		{Kind: SECTION_KIND_PAGE, Index: 1, Text: "First page"},
+		{Kind: SECTION_KIND_PAGE, Index: 2, Text: "Second page"},
+	}
+	if !reflect.DeepEqual(ex.Sections, want) {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+	if ex.MetaData["title"] != "Fixture Title" || ex.MetaData["page_count"] != 2 {
+		t.Errorf("Unexpected metadata: %v", ex.MetaData)
+	}
+}
+
+func TestDOCXExtractorSingleBody(t *testing.T) {
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>Only body</w:t></w:r></w:p></w:body></w:document>`
+	p := writeFixture(t, "fixture.docx", buildZip(t, map[string]string{"word/document.xml": doc}))
+	ex, err := (&DOCXExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(ex.Sections) != 1 || ex.Sections[0].Kind != SECTION_KIND_BODY || ex.Sections[0].Text != "Only body" {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+	if _, ok := ex.MetaData["page_count"]; ok {
+		t.Errorf("page_count must not be set for a single body")
+	}
+}
+
+func TestXLSXExtractor(t *testing.T) {
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` + testRelNS + `><sheets>` +
+		`<sheet name="Members" sheetId="1" r:id="rId1"/>` +
+		`<sheet name="Secret" sheetId="2" state="hidden" r:id="rId2"/>` +
+		`</sheets></workbook>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/sheet2.xml"/>` +
+		`</Relationships>`
+	shared := `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<si><t>Name</t></si><si><t>Age</t></si><si><r><t>Ta</t></r><r><t>ro</t></r></si></sst>`
+	sheet1 := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>Active</t></is></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>30</v></c><c r="D2" t="b"><v>1</v></c></row>` +
+		`<row r="3"><c r="A3" t="s"><v>99</v></c><c r="C3"><v>extra</v></c></row>` +
+		`</sheetData></worksheet>`
+	sheet2 := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="A1"><v>hidden</v></c></row></sheetData></worksheet>`
+	p := writeFixture(t, "fixture.xlsx", buildZip(t, map[string]string{
+		"xl/workbook.xml":            workbook,
+		"xl/_rels/workbook.xml.rels": rels,
+		"xl/sharedStrings.xml":       shared,
+		"xl/worksheets/sheet1.xml":   sheet1,
+		"xl/worksheets/sheet2.xml":   sheet2,
+	}))
+	ex, err := (&XLSXExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := []Section{{
+		Kind:  SECTION_KIND_SHEET,
+		Index: 1,
+		Name:  "Members",
+		Text:  "Name: Taro; Age: 30; Active: TRUE.\n列3: extra.",
+	}}
+	if !reflect.DeepEqual(ex.Sections, want) {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+	if !reflect.DeepEqual(ex.MetaData["sheet_names"], []string{"Members", "Secret"}) {
+		t.Errorf("Unexpected sheet_names: %v", ex.MetaData["sheet_names"])
+	}
+}
+
+func TestPPTXExtractorSlideOrder(t *testing.T) {
+	pres := `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" ` + testRelNS + `>` +
+		`<p:sldIdLst><p:sldId id="256" r:id="rId2"/><p:sldId id="257" r:id="rId1"/></p:sldIdLst></p:presentation>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="slide" Target="slides/slide1.xml"/>` +
+		`<Relationship Id="rId2" Type="slide" Target="slides/slide2.xml"/>` +
+		`</Relationships>`
+	slide := func(text string) string {
+		return `<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">` +
+			`<p:cSld><p:spTree><p:sp><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+	}
+	p := writeFixture(t, "fixture.pptx", buildZip(t, map[string]string{
+		"ppt/presentation.xml":            pres,
+		"ppt/_rels/presentation.xml.rels": rels,
+		"ppt/slides/slide1.xml":           slide("Shown second"),
+		"ppt/slides/slide2.xml":           slide("Shown first"),
+	}))
+	ex, err := (&PPTXExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := []Section{
+		{Kind: SECTION_KIND_SLIDE, Index: 1, Text: "Shown first"},
+		{Kind: SECTION_KIND_SLIDE, Index: 2, Text: "Shown second"},
+	}
+	if !reflect.DeepEqual(ex.Sections, want) {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+}
+
+func TestPPTXExtractorFallbackOrder(t *testing.T) {
+	slide := func(text string) string {
+		return `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:sld>`
+	}
+	p := writeFixture(t, "fixture.pptx", buildZip(t, map[string]string{
+		"ppt/slides/slide10.xml": slide("Ten"),
+		"ppt/slides/slide2.xml":  slide("Two"),
+	}))
+	ex, err := (&PPTXExtractor{}).Extract(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(ex.Sections) != 2 || ex.Sections[0].Text != "Two" || ex.Sections[1].Text != "Ten" {
+		t.Errorf("Unexpected sections: %+v", ex.Sections)
+	}
+}
+
+func TestOOXMLExtractorsMalformed(t *testing.T) {
+	notZip := []byte("this is not a zip container")
+	tests := []struct {
+		name    string
+		e       Extractor
+		data    []byte
+		wantErr string
+	}{
+		{"docx not zip", &DOCXExtractor{}, notZip, "Failed to open zip container"},
+		{"pptx not zip", &PPTXExtractor{}, notZip, "Failed to open zip container"},
+		{"xlsx not zip", &XLSXExtractor{}, notZip, "Failed to open zip container"},
+		{"docx missing document", &DOCXExtractor{}, buildZip(t, map[string]string{"other.xml": "<a/>"}), "Entry not found"},
+		{"docx broken xml", &DOCXExtractor{}, buildZip(t, map[string]string{"word/document.xml": "<w:document><w:body>"}), "Failed to parse word/document.xml"},
+		{"xlsx missing rels", &XLSXExtractor{}, buildZip(t, map[string]string{"xl/workbook.xml": "<workbook/>"}), "Entry not found"},
+		{"xlsx broken sheet", &XLSXExtractor{}, buildZip(t, map[string]string{
+			"xl/workbook.xml":            `<workbook ` + testRelNS + `><sheets><sheet name="S" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row>`,
+		}), "Failed to parse sheet S"},
+		{"pptx broken slide", &PPTXExtractor{}, buildZip(t, map[string]string{"ppt/slides/slide1.xml": "<p:sld><a:t>"}), "Failed to parse slide 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.e.Extract(context.Background(), writeFixture(t, "fixture"+tt.e.Extensions()[0], tt.data))
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
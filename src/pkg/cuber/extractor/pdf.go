@@ -0,0 +1,575 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"context"
+	"encoding/ascii85"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PDFExtractor は、PDF からページごとのテキストを抽出する抽出器です。
+// 外部依存を持たない簡易実装で、以下に対応します。
+//   - 通常のオブジェクトおよびオブジェクトストリーム（/Type /ObjStm）
+//   - FlateDecode / ASCIIHexDecode / ASCII85Decode フィルタ
+//   - ToUnicode CMap によるテキスト復元（日本語の CID フォントを含む）と、単純フォントの WinAnsi 解釈
+//   - フォーム XObject 内のテキスト
+//
+// 暗号化 PDF、および画像のみ（スキャン）の PDF からはテキストを取り出せません。
+type PDFExtractor struct{}
+
+var _ Extractor = (*PDFExtractor)(nil)
+
+func (e *PDFExtractor) Name() string { return "pdf" }
+
+func (e *PDFExtractor) Extensions() []string { return []string{".pdf"} }
+
+func (e *PDFExtractor) MimeTypes() []string { return []string{"application/pdf"} }
+
+func (e *PDFExtractor) Extract(ctx context.Context, p string) (*Extracted, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("No pages found in PDF")
+	}
+	ex := &Extracted{MetaData: map[string]any{"page_count": len(pages)}}
+	if title := doc.infoString("Title"); title != "" {
+		ex.MetaData["title"] = title
+	}
+	for i, page := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		text := squeezeBlankLines(doc.pageText(page))
+		if text == "" {
+			continue
+		}
+		ex.Sections = append(ex.Sections, Section{Kind: SECTION_KIND_PAGE, Index: i + 1, Text: text})
+	}
+	if len(ex.Sections) == 0 {
+		return nil, fmt.Errorf("No extractable text in PDF (scanned or image-only document?)")
+	}
+	return ex, nil
+}
+
+// pdfDocument は、解析済みの PDF です。
+type pdfDocument struct {
+	objects  map[int]any
+	trailers []pdfDict
+	fonts    map[pdfRef]*pdfFont
+}
+
+var pdfObjHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// parsePDF は、ファイル全体を走査して間接オブジェクトを収集します。
+// 相互参照表は使用せず、後から現れた定義（増分更新）を優先します。
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF")) {
+		return nil, fmt.Errorf("Not a PDF file")
+	}
+	doc := &pdfDocument{objects: map[int]any{}, fonts: map[pdfRef]*pdfFont{}}
+	for _, m := range pdfObjHeaderRe.FindAllSubmatchIndex(data, -1) {
+		num := atoiBytes(data[m[2]:m[3]])
+		l := newPDFLexer(data)
+		l.pos = m[1]
+		obj, ok := l.next()
+		if !ok {
+			continue
+		}
+		if dict, isDict := obj.(pdfDict); isDict {
+			s, isStream, err := readPDFStream(data, l, dict)
+			if err != nil {
+				return nil, fmt.Errorf("Object %d: %w", num, err)
+			}
+			if isStream {
+				obj = s
+			}
+		}
+		doc.objects[num] = obj
+	}
+	// トレーラー（従来形式）と相互参照ストリームの辞書を収集
+	for _, idx := range allIndexes(data, []byte("trailer")) {
+		l := newPDFLexer(data)
+		l.pos = idx + len("trailer")
+		if t, ok := l.next(); ok {
+			if d, isDict := t.(pdfDict); isDict {
+				doc.trailers = append(doc.trailers, d)
+			}
+		}
+	}
+	var objStreams []*pdfStream
+	for _, obj := range doc.objects {
+		s, ok := obj.(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch s.Dict["Type"] {
+		case pdfName("XRef"):
+			doc.trailers = append(doc.trailers, s.Dict)
+		case pdfName("ObjStm"):
+			objStreams = append(objStreams, s)
+		}
+	}
+	for _, t := range doc.trailers {
+		if _, encrypted := t["Encrypt"]; encrypted {
+			return nil, fmt.Errorf("Encrypted PDF is not supported")
+		}
+	}
+	for _, s := range objStreams {
+		if err := doc.loadObjectStream(s); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// readPDFStream は、辞書の直後に stream キーワードが続く場合にストリーム本体を読み込みます。
+// /Length が負の値など、ストリームとして解釈できない場合はエラーを返します。
+func readPDFStream(data []byte, l *pdfLexer, dict pdfDict) (*pdfStream, bool, error) {
+	save := l.pos
+	l.skipSpace()
+	if !bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+		l.pos = save
+		return nil, false, nil
+	}
+	start := l.pos + len("stream")
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+	// 直接指定の /Length が妥当であれば採用し、そうでなければ endstream を探索する
+	if n, ok := dict["Length"].(float64); ok {
+		if n < 0 || math.IsNaN(n) {
+			return nil, false, fmt.Errorf("Invalid stream length: %v", n)
+		}
+		if n <= float64(len(data)-start) {
+			end := start + int(n)
+			if bytes.HasPrefix(bytes.TrimLeft(data[end:], "\r\n \t"), []byte("endstream")) {
+				return &pdfStream{Dict: dict, Raw: data[start:end]}, true, nil
+			}
+		}
+	}
+	idx := bytes.Index(data[start:], []byte("endstream"))
+	if idx < 0 {
+		return &pdfStream{Dict: dict, Raw: data[start:]}, true, nil
+	}
+	raw := bytes.TrimRight(data[start:start+idx], "\r\n")
+	return &pdfStream{Dict: dict, Raw: raw}, true, nil
+}
+
+// loadObjectStream は、オブジェクトストリーム内のオブジェクトを展開します。
+// 直接定義されたオブジェクトが既に存在する場合はそちらを優先します。
+// 復号できないストリームは無視し、ヘッダーのオフセットが範囲外の場合はエラーを返します。
+func (d *pdfDocument) loadObjectStream(s *pdfStream) error {
+	body, err := d.decodeStream(s)
+	if err != nil {
+		return nil
+	}
+	n := int(d.number(s.Dict["N"]))
+	first := int(d.number(s.Dict["First"]))
+	if n <= 0 || first <= 0 || first > len(body) {
+		return nil
+	}
+	header := newPDFLexer(body[:first])
+	for i := 0; i < n; i++ {
+		numTok, ok1 := header.next()
+		offTok, ok2 := header.next()
+		if !ok1 || !ok2 {
+			return nil
+		}
+		num, _ := numTok.(float64)
+		off, _ := offTok.(float64)
+		if off < 0 || math.IsNaN(off) || off >= float64(len(body)-first) {
+			return fmt.Errorf("Invalid object stream offset for object %d: %v", int(num), off)
+		}
+		if _, exists := d.objects[int(num)]; exists {
+			continue
+		}
+		l := newPDFLexer(body)
+		l.pos = first + int(off)
+		if obj, ok := l.next(); ok {
+			d.objects[int(num)] = obj
+		}
+	}
+	return nil
+}
+
+// resolve は、参照を実体に解決します（多段参照にも対応）。
+func (d *pdfDocument) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = d.objects[ref.Num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(v any) pdfDict {
+	switch t := d.resolve(v).(type) {
+	case pdfDict:
+		return t
+	case *pdfStream:
+		return t.Dict
+	}
+	return nil
+}
+
+func (d *pdfDocument) array(v any) pdfArray {
+	a, _ := d.resolve(v).(pdfArray)
+	return a
+}
+
+func (d *pdfDocument) number(v any) float64 {
+	n, _ := d.resolve(v).(float64)
+	return n
+}
+
+// infoString は、文書情報辞書（/Info）の文字列項目を返します。
+func (d *pdfDocument) infoString(key pdfName) string {
+	for i := len(d.trailers) - 1; i >= 0; i-- {
+		info := d.dict(d.trailers[i]["Info"])
+		if info == nil {
+			continue
+		}
+		if s, ok := d.resolve(info[key]).(pdfString); ok {
+			return strings.TrimSpace(decodePDFTextString(s))
+		}
+	}
+	return ""
+}
+
+// pages は、ページツリーを表示順に辿り、リソースを継承したページ辞書を返します。
+func (d *pdfDocument) pages() []pdfDict {
+	var root pdfDict
+	for i := len(d.trailers) - 1; i >= 0 && root == nil; i-- {
+		root = d.dict(d.trailers[i]["Root"])
+	}
+	if root == nil {
+		for _, obj := range d.objects {
+			if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+				root = dict
+				break
+			}
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	var res []pdfDict
+	visited := map[pdfRef]bool{}
+	var walk func(node any, inherited any, depth int)
+	walk = func(node any, inherited any, depth int) {
+		if depth > 64 {
+			return
+		}
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		n := d.dict(node)
+		if n == nil {
+			return
+		}
+		resources := inherited
+		if r, ok := n["Resources"]; ok {
+			resources = r
+		}
+		if kids, ok := n["Kids"]; ok {
+			for _, kid := range d.array(kids) {
+				walk(kid, resources, depth+1)
+			}
+			return
+		}
+		page := pdfDict{}
+		for k, v := range n {
+			page[k] = v
+		}
+		page["Resources"] = resources
+		res = append(res, page)
+	}
+	walk(root["Pages"], nil, 0)
+	return res
+}
+
+// decodeStream は、ストリームのフィルタを適用して本体を返します。
+func (d *pdfDocument) decodeStream(s *pdfStream) ([]byte, error) {
+	var filters []pdfName
+	switch f := d.resolve(s.Dict["Filter"]).(type) {
+	case pdfName:
+		filters = []pdfName{f}
+	case pdfArray:
+		for _, v := range f {
+			if n, ok := d.resolve(v).(pdfName); ok {
+				filters = append(filters, n)
+			}
+		}
+	}
+	out := s.Raw
+	for _, f := range filters {
+		var err error
+		switch f {
+		case "FlateDecode", "Fl":
+			out, err = inflate(out)
+		case "ASCIIHexDecode", "AHx":
+			l := newPDFLexer(append(append([]byte{}, out...), '>'))
+			out = []byte(l.readHexString())
+		case "ASCII85Decode", "A85":
+			src := bytes.TrimPrefix(bytes.TrimSpace(out), []byte("<~"))
+			if i := bytes.Index(src, []byte("~>")); i >= 0 {
+				src = src[:i]
+			}
+			buf := make([]byte, len(src)*4/5+4)
+			n, _, e := ascii85.Decode(buf, src, true)
+			out, err = buf[:n], e
+		default:
+			return nil, fmt.Errorf("Unsupported PDF filter: %s", f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// inflate は、zlib（失敗時は raw deflate）で展開します。途中で壊れている場合も展開できた分は返します。
+func inflate(b []byte) ([]byte, error) {
+	var r io.ReadCloser
+	zr, err := zlib.NewReader(bytes.NewReader(b))
+	if err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(b))
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if len(out) > 0 {
+		return out, nil
+	}
+	return out, err
+}
+
+// pageText は、ページのコンテンツストリームを解釈してテキストを取り出します。
+func (d *pdfDocument) pageText(page pdfDict) string {
+	var content []byte
+	switch c := d.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		if b, err := d.decodeStream(c); err == nil {
+			content = b
+		}
+	case pdfArray:
+		for _, part := range c {
+			if s, ok := d.resolve(part).(*pdfStream); ok {
+				if b, err := d.decodeStream(s); err == nil {
+					content = append(content, b...)
+					content = append(content, '\n')
+				}
+			}
+		}
+	}
+	w := &pdfTextWriter{}
+	d.runContent(content, d.dict(page["Resources"]), w, 0)
+	return w.String()
+}
+
+// pdfTextWriter は、テキストの出力先です。改行・空白の重複を抑制します。
+type pdfTextWriter struct {
+	sb strings.Builder
+}
+
+func (w *pdfTextWriter) write(s string) { w.sb.WriteString(s) }
+
+func (w *pdfTextWriter) last() byte {
+	s := w.sb.String()
+	if s == "" {
+		return '\n'
+	}
+	return s[len(s)-1]
+}
+
+func (w *pdfTextWriter) newline() {
+	if w.last() != '\n' {
+		w.sb.WriteByte('\n')
+	}
+}
+
+// space は、直前が ASCII の英数字の場合のみ空白を挿入します（日本語の文中に空白を入れないため）。
+func (w *pdfTextWriter) space() {
+	c := w.last()
+	if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == ',' || c == '.' || c == ':' || c == ';' {
+		w.sb.WriteByte(' ')
+	}
+}
+
+func (w *pdfTextWriter) String() string { return w.sb.String() }
+
+// runContent は、コンテンツストリームのテキスト関連オペレータを解釈します。
+func (d *pdfDocument) runContent(content []byte, resources pdfDict, w *pdfTextWriter, depth int) {
+	if depth > 8 || len(content) == 0 {
+		return
+	}
+	fonts := d.dict(resources["Font"])
+	xobjects := d.dict(resources["XObject"])
+	var font *pdfFont
+	var operands []any
+	fontSize := 1.0
+	lastY, hasY := 0.0, false
+	l := newPDFLexer(content)
+	for {
+		tok, ok := l.next()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = d.font(fonts[name])
+				}
+			}
+			if len(operands) >= 2 {
+				if size, ok := operands[1].(float64); ok && size != 0 {
+					fontSize = math.Abs(size)
+				}
+			}
+		case "Tj":
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					w.write(font.decode(s))
+				}
+			}
+		case "'", "\"":
+			w.newline()
+			if len(operands) >= 1 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					w.write(font.decode(s))
+				}
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, ok := operands[len(operands)-1].(pdfArray); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case pdfString:
+							w.write(font.decode(v))
+						case float64:
+							// 大きな負の字送り（1/1000 em 単位）は単語間の空白とみなす
+							if v < -150 {
+								w.space()
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := operands[len(operands)-2].(float64)
+				ty, _ := operands[len(operands)-1].(float64)
+				// 移動量はテキスト空間単位のため、Tf のフォントサイズと直接比較できる
+				if math.Abs(ty) > fontSize*0.5 {
+					w.newline()
+				} else if tx > 0 {
+					w.space()
+				}
+			}
+		case "T*":
+			w.newline()
+		case "Tm":
+			if len(operands) >= 6 {
+				scale, _ := operands[3].(float64)
+				y, _ := operands[5].(float64)
+				// 上付き・下付き程度の縦移動は同じ行とみなす（実効フォントサイズの半分を閾値とする）
+				threshold := math.Max(math.Abs(scale)*fontSize*0.5, 1)
+				if hasY && math.Abs(y-lastY) > threshold {
+					w.newline()
+				} else if hasY {
+					w.space()
+				}
+				lastY, hasY = y, true
+			}
+		case "ET":
+			w.space()
+		case "Do":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					if s, ok := d.resolve(xobjects[name]).(*pdfStream); ok && s.Dict["Subtype"] == pdfName("Form") {
+						if b, err := d.decodeStream(s); err == nil {
+							res := resources
+							if r := d.dict(s.Dict["Resources"]); r != nil {
+								res = r
+							}
+							d.runContent(b, res, w, depth+1)
+						}
+					}
+				}
+			}
+		case "BI":
+			// インライン画像: ID 以降のバイナリを EI まで読み飛ばす
+			idx := bytes.Index(content[l.pos:], []byte("ID"))
+			if idx < 0 {
+				return
+			}
+			l.pos += idx + 2
+			end := pdfInlineImageEnd(content, l.pos)
+			if end < 0 {
+				return
+			}
+			l.pos = end
+		}
+		operands = operands[:0]
+	}
+}
+
+// pdfInlineImageEnd は、インライン画像データの終端（EI の直後）の位置を返します。
+func pdfInlineImageEnd(content []byte, from int) int {
+	for i := from; i+2 <= len(content); i++ {
+		if content[i] == 'E' && content[i+1] == 'I' && i > 0 && isPDFWhitespace(content[i-1]) &&
+			(i+2 == len(content) || isPDFWhitespace(content[i+2])) {
+			return i + 2
+		}
+	}
+	return -1
+}
+
+func atoiBytes(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
+
+func allIndexes(data []byte, sep []byte) []int {
+	var res []int
+	for off := 0; ; {
+		i := bytes.Index(data[off:], sep)
+		if i < 0 {
+			return res
+		}
+		res = append(res, off+i)
+		off += i + len(sep)
+	}
+}
@@ -0,0 +1,246 @@
+package extractor
+
+import (
+	"unicode/utf16"
+)
+
+// pdfFont は、コンテンツストリーム内の文字列をテキストへ復元するためのフォント情報です。
+type pdfFont struct {
+	cmap      *pdfCMap // ToUnicode CMap（無い場合は nil）
+	composite bool     // Type0（CID）フォントの場合 true
+}
+
+// font は、フォント辞書から pdfFont を生成します（参照単位でキャッシュします）。
+func (d *pdfDocument) font(v any) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if f, ok := d.fonts[ref]; ok {
+			return f
+		}
+	}
+	f := &pdfFont{}
+	if fd := d.dict(v); fd != nil {
+		f.composite = fd["Subtype"] == pdfName("Type0")
+		if s, ok := d.resolve(fd["ToUnicode"]).(*pdfStream); ok {
+			if b, err := d.decodeStream(s); err == nil {
+				f.cmap = parseCMap(b)
+			}
+		}
+	}
+	if isRef {
+		d.fonts[ref] = f
+	}
+	return f
+}
+
+// decode は、文字列をフォントに従って Unicode テキストへ変換します。
+// ToUnicode を持たない CID フォントは復元できないため空文字列を返します。
+func (f *pdfFont) decode(s pdfString) string {
+	if f != nil && f.cmap != nil {
+		return f.cmap.decode(s, f.composite)
+	}
+	if f != nil && f.composite {
+		return ""
+	}
+	return decodeWinAnsi(s)
+}
+
+// pdfCMap は、ToUnicode CMap の対応表です。
+type pdfCMap struct {
+	codespace []cmapCodespace
+	chars     map[cmapCode]string
+	ranges    []cmapRange
+	width     int // codespace が無い場合のコード長
+}
+
+type cmapCode struct {
+	n    int
+	code uint32
+}
+
+type cmapCodespace struct {
+	n      int
+	lo, hi uint32
+}
+
+type cmapRange struct {
+	n      int
+	lo, hi uint32
+	base   []rune   // 連番マッピングの先頭値
+	values []string // 配列マッピングの場合の個別値
+}
+
+// parseCMap は、ToUnicode CMap ストリームを解析します。
+func parseCMap(b []byte) *pdfCMap {
+	cm := &pdfCMap{chars: map[cmapCode]string{}}
+	l := newPDFLexer(b)
+	var operands []any
+	for {
+		tok, ok := l.next()
+		if !ok {
+			break
+		}
+		op, isOp := tok.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 && len(lo) == len(hi) && len(lo) > 0 && len(lo) <= 4 {
+					cm.codespace = append(cm.codespace, cmapCodespace{n: len(lo), lo: beUint(lo), hi: beUint(hi)})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok := operands[i].(pdfString)
+				if !ok || len(src) == 0 || len(src) > 4 {
+					continue
+				}
+				if cm.width == 0 {
+					cm.width = len(src)
+				}
+				cm.chars[cmapCode{n: len(src), code: beUint(src)}] = cmapDestination(operands[i+1])
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				if cm.width == 0 {
+					cm.width = len(lo)
+				}
+				r := cmapRange{n: len(lo), lo: beUint(lo), hi: beUint(hi)}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					r.base = []rune(decodeUTF16BE(dst))
+				case pdfArray:
+					for _, v := range dst {
+						r.values = append(r.values, cmapDestination(v))
+					}
+				}
+				cm.ranges = append(cm.ranges, r)
+			}
+		}
+		operands = operands[:0]
+	}
+	return cm
+}
+
+// cmapDestination は、bfchar/bfrange の変換先（UTF-16BE 文字列または字形名）を文字列にします。
+func cmapDestination(v any) string {
+	switch t := v.(type) {
+	case pdfString:
+		return decodeUTF16BE(t)
+	case pdfName:
+		return string(t)
+	}
+	return ""
+}
+
+// decode は、バイト列をコード単位に分割して Unicode に変換します。
+func (cm *pdfCMap) decode(s pdfString, composite bool) string {
+	var out []rune
+	for i := 0; i < len(s); {
+		n := cm.codeLength(s[i:], composite)
+		if i+n > len(s) {
+			break
+		}
+		code := beUint(s[i : i+n])
+		i += n
+		if v, ok := cm.chars[cmapCode{n: n, code: code}]; ok {
+			out = append(out, []rune(v)...)
+			continue
+		}
+		for _, r := range cm.ranges {
+			if r.n != n || code < r.lo || code > r.hi {
+				continue
+			}
+			off := int(code - r.lo)
+			if r.values != nil {
+				if off < len(r.values) {
+					out = append(out, []rune(r.values[off])...)
+				}
+			} else if len(r.base) > 0 {
+				v := append([]rune{}, r.base...)
+				v[len(v)-1] += rune(off)
+				out = append(out, v...)
+			}
+			break
+		}
+	}
+	return string(out)
+}
+
+// codeLength は、codespacerange に従って次のコードのバイト長を決定します。
+func (cm *pdfCMap) codeLength(b []byte, composite bool) int {
+	for n := 1; n <= 4 && n <= len(b); n++ {
+		code := beUint(b[:n])
+		for _, cs := range cm.codespace {
+			if cs.n == n && code >= cs.lo && code <= cs.hi {
+				return n
+			}
+		}
+	}
+	if cm.width > 0 {
+		return cm.width
+	}
+	if composite {
+		return 2
+	}
+	return 1
+}
+
+func beUint(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func decodeUTF16BE(b []byte) string {
+	if len(b)%2 == 1 {
+		b = append(b, 0)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+// decodePDFTextString は、文書情報などのテキスト文字列（UTF-16BE BOM 付き、または PDFDocEncoding）を復元します。
+func decodePDFTextString(s pdfString) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return decodeUTF16BE(s[2:])
+	}
+	return decodeWinAnsi(s)
+}
+
+// winAnsiHigh は、WinAnsiEncoding（CP1252）の 0x80〜0x9F の対応表です。
+var winAnsiHigh = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// decodeWinAnsi は、単純フォントの文字列を WinAnsiEncoding とみなして復元します。
+func decodeWinAnsi(s pdfString) string {
+	out := make([]rune, 0, len(s))
+	for _, c := range s {
+		switch {
+		case c >= 0x80 && c <= 0x9F:
+			if r := winAnsiHigh[c-0x80]; r != 0 {
+				out = append(out, r)
+			}
+		case c >= 0x20 || c == '\n' || c == '\t':
+			out = append(out, rune(c))
+		}
+	}
+	return string(out)
+}
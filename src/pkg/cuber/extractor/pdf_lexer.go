@@ -0,0 +1,317 @@
+package extractor
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// PDF のオブジェクト表現
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  []byte
+	pdfArray   []any
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ Num, Gen int }
+	pdfStream  struct {
+		Dict pdfDict
+		Raw  []byte
+	}
+)
+
+// pdfLexer は、PDF のオブジェクト構文およびコンテンツストリームの字句解析器です。
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func newPDFLexer(data []byte) *pdfLexer {
+	return &pdfLexer{data: data}
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace は、空白とコメントを読み飛ばします。
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFWhitespace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// pdfDelim は、辞書・配列の終端（">>" / "]"）を表すトークンです。
+type pdfDelim string
+
+// next は、次のトークンを返します。終端に達した場合は ok=false を返します。
+// 数値は float64、配列・辞書は再帰的に解析した値を返し、参照（N G R）もここで組み立てます。
+func (l *pdfLexer) next() (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		return l.readName(), true
+	case c == '(':
+		l.pos++
+		return l.readLiteralString(), true
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return l.readDict(), true
+		}
+		l.pos++
+		return l.readHexString(), true
+	case c == '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfDelim(">>"), true
+		}
+		l.pos++
+		return l.next()
+	case c == '[':
+		l.pos++
+		return l.readArray(), true
+	case c == ']':
+		l.pos++
+		return pdfDelim("]"), true
+	case c == '{' || c == '}' || c == ')':
+		l.pos++
+		return l.next()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.readNumberOrRef(), true
+	default:
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		if l.pos == start {
+			l.pos++
+			return l.next()
+		}
+		kw := string(l.data[start:l.pos])
+		switch kw {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+		return pdfKeyword(kw), true
+	}
+}
+
+func (l *pdfLexer) readName() pdfName {
+	var buf bytes.Buffer
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFWhitespace(c) || isPDFDelimiter(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				buf.WriteByte(byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		buf.WriteByte(c)
+		l.pos++
+	}
+	return pdfName(buf.String())
+}
+
+func (l *pdfLexer) readLiteralString() pdfString {
+	var buf bytes.Buffer
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			buf.WriteByte(c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(buf.Bytes())
+			}
+			buf.WriteByte(c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return pdfString(buf.Bytes())
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case '\r':
+				// 行継続
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+				// 行継続
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf.WriteByte(byte(v))
+				} else {
+					buf.WriteByte(e)
+				}
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return pdfString(buf.Bytes())
+}
+
+func (l *pdfLexer) readHexString() pdfString {
+	var digits []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return pdfString(out)
+}
+
+func (l *pdfLexer) readArray() pdfArray {
+	var arr pdfArray
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return arr
+		}
+		if d, isDelim := tok.(pdfDelim); isDelim {
+			if d == "]" {
+				return arr
+			}
+			continue
+		}
+		arr = append(arr, tok)
+	}
+}
+
+func (l *pdfLexer) readDict() pdfDict {
+	dict := pdfDict{}
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return dict
+		}
+		if d, isDelim := tok.(pdfDelim); isDelim {
+			if d == ">>" {
+				return dict
+			}
+			continue
+		}
+		key, isName := tok.(pdfName)
+		if !isName {
+			continue
+		}
+		val, ok := l.next()
+		if !ok {
+			return dict
+		}
+		if d, isDelim := val.(pdfDelim); isDelim && d == ">>" {
+			return dict
+		}
+		dict[key] = val
+	}
+}
+
+func (l *pdfLexer) readRawNumber() (float64, bool, bool) {
+	start := l.pos
+	isInt := true
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if c == '.' {
+			isInt = false
+		} else if !(c >= '0' && c <= '9') && !(l.pos == start && (c == '+' || c == '-')) {
+			break
+		}
+		l.pos++
+	}
+	v, err := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+	if err != nil {
+		return 0, false, false
+	}
+	return v, isInt, true
+}
+
+// readNumberOrRef は、数値を読み込み、後続が「G R」であれば参照として返します。
+func (l *pdfLexer) readNumberOrRef() any {
+	v, isInt, ok := l.readRawNumber()
+	if !ok {
+		return float64(0)
+	}
+	if !isInt || v < 0 {
+		return v
+	}
+	save := l.pos
+	l.skipSpace()
+	if l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		gen, genIsInt, ok := l.readRawNumber()
+		if ok && genIsInt {
+			l.skipSpace()
+			if l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+				(l.pos+1 >= len(l.data) || isPDFWhitespace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+				l.pos++
+				return pdfRef{Num: int(v), Gen: int(gen)}
+			}
+		}
+	}
+	l.pos = save
+	return v
+}
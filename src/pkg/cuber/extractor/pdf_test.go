@@ -0,0 +1,273 @@
+package extractor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildPDF は、番号順のオブジェクト本体からテスト用の PDF を組み立てます。
+// パーサーは相互参照表を使用しないため、xref は出力しません。
+func buildPDF(trailer string, objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&b, "trailer\n%s\n%%%%EOF\n", trailer)
+	return b.Bytes()
+}
+
+// pdfStreamObject は、/Length 付きのストリームオブジェクトを返します。
+func pdfStreamObject(extra string, body []byte) string {
+	return fmt.Sprintf("<< /Length %d %s>>\nstream\n%s\nendstream", len(body), extra, body)
+}
+
+func deflateBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeFixture(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func extractPDF(t *testing.T, data []byte) (*Extracted, error) {
+	t.Helper()
+	return (&PDFExtractor{}).Extract(context.Background(), writeFixture(t, "fixture.pdf", data))
+}
+
+// simplePDF は、Helvetica で2行を描画する1ページの PDF です。
+func simplePDF() []byte {
+	content := []byte("BT /F1 12 Tf 72 720 Td (Hello World) Tj 0 -14 Td (Second line) Tj ET")
+	return buildPDF("<< /Root 1 0 R /Info 6 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 4 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		pdfStreamObject("", content),
+		"<< /Title (Fixture Title) >>",
+	)
+}
+
+func TestPDFExtractorSimple(t *testing.T) {
+	ex, err := extractPDF(t, simplePDF())
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(ex.Sections) != 1 {
+		t.Fatalf("Expected 1 section, got %d", len(ex.Sections))
+	}
+	s := ex.Sections[0]
+	if s.Kind != SECTION_KIND_PAGE || s.Index != 1 {
+		t.Errorf("Unexpected section: %+v", s)
+	}
+	if s.Text != "Hello World\nSecond line" {
+		t.Errorf("Unexpected text: %q", s.Text)
+	}
+	if ex.MetaData["title"] != "Fixture Title" {
+		t.Errorf("Unexpected title: %v", ex.MetaData["title"])
+	}
+	if ex.MetaData["page_count"] != 1 {
+		t.Errorf("Unexpected page_count: %v", ex.MetaData["page_count"])
+	}
+}
+
+func TestPDFExtractorFlateAndTJ(t *testing.T) {
+	content := deflateBytes(t, []byte("BT /F1 10 Tf [(Multi)-300(word)] TJ ET"))
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		pdfStreamObject("/Filter /FlateDecode ", content),
+	)
+	ex, err := extractPDF(t, data)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if got := ex.Sections[0].Text; got != "Multi word" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+}
+
+func TestPDFExtractorToUnicodeCIDFont(t *testing.T) {
+	cmap := []byte(strings.Join([]string{
+		"begincmap",
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange",
+		"2 beginbfchar <0001> <65E5> <0002> <672C> endbfchar",
+		"1 beginbfrange <0010> <0011> <8A9E> endbfrange",
+		"endcmap",
+	}, "\n"))
+	content := []byte("BT /F1 12 Tf <0001000200100011> Tj ET")
+	data := buildPDF("<< /Root 1 0 R >>",
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Dummy /ToUnicode 6 0 R >>",
+		pdfStreamObject("", content),
+		pdfStreamObject("", cmap),
+	)
+	ex, err := extractPDF(t, data)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if got := ex.Sections[0].Text; got != "日本語誟" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+}
+
+// objectStreamPDF は、カタログとページツリーをオブジェクトストリームに格納した PDF を返します。
+// offsets が nil の場合は正しいオフセットを使用します。
+func objectStreamPDF(t *testing.T, offsets []int) []byte {
+	t.Helper()
+	inner := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	}
+	var header, body bytes.Buffer
+	for i, obj := range inner {
+		off := body.Len()
+		if offsets != nil {
+			off = offsets[i]
+		}
+		fmt.Fprintf(&header, "%d %d ", i+1, off)
+		body.WriteString(obj + " ")
+	}
+	stm := append(header.Bytes(), body.Bytes()...)
+	content := []byte("BT /F1 12 Tf (From object stream) Tj ET")
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n")
+	fmt.Fprintf(&b, "3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>\nendobj\n")
+	fmt.Fprintf(&b, "4 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
+	fmt.Fprintf(&b, "5 0 obj\n%s\nendobj\n", pdfStreamObject("", content))
+	fmt.Fprintf(&b, "6 0 obj\n%s\nendobj\n", pdfStreamObject(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode ", header.Len()), deflateBytes(t, stm)))
+	b.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+func TestPDFExtractorObjectStream(t *testing.T) {
+	ex, err := extractPDF(t, objectStreamPDF(t, nil))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if got := ex.Sections[0].Text; got != "From object stream" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+}
+
+func TestPDFExtractorMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{
+			name:    "not a pdf",
+			data:    []byte("PK\x03\x04 not a pdf"),
+			wantErr: "Not a PDF file",
+		},
+		{
+			name: "negative stream length",
+			data: buildPDF("<< /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Length -100 >>\nstream\nBT (x) Tj ET\nendstream",
+			),
+			wantErr: "Invalid stream length",
+		},
+		{
+			name:    "negative object stream offset",
+			data:    objectStreamPDF(t, []int{0, -40}),
+			wantErr: "Invalid object stream offset",
+		},
+		{
+			name:    "object stream offset beyond body",
+			data:    objectStreamPDF(t, []int{0, 1 << 20}),
+			wantErr: "Invalid object stream offset",
+		},
+		{
+			name: "encrypted",
+			data: buildPDF("<< /Root 1 0 R /Encrypt 2 0 R >>",
+				"<< /Type /Catalog /Pages 3 0 R >>",
+				"<< /Filter /Standard >>",
+			),
+			wantErr: "Encrypted PDF is not supported",
+		},
+		{
+			name:    "no pages",
+			data:    buildPDF("<< /Root 1 0 R >>", "<< /Type /Catalog >>"),
+			wantErr: "No pages found",
+		},
+		{
+			name: "image only",
+			data: buildPDF("<< /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStreamObject("", []byte("q 100 0 0 100 0 0 cm /Im1 Do Q")),
+			),
+			wantErr: "No extractable text",
+		},
+		{
+			name: "unsupported filter",
+			data: buildPDF("<< /Root 1 0 R >>",
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+				pdfStreamObject("/Filter /JBIG2Decode ", []byte("garbage")),
+			),
+			wantErr: "No extractable text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := extractPDF(t, tt.data)
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+// TestParsePDFTruncated は、任意の位置で途切れた PDF でもパニックしないことを確認します。
+func TestParsePDFTruncated(t *testing.T) {
+	for _, data := range [][]byte{simplePDF(), objectStreamPDF(t, nil)} {
+		for i := 0; i <= len(data); i++ {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("Panic at truncation %d: %v", i, r)
+					}
+				}()
+				doc, err := parsePDF(data[:i])
+				if err != nil {
+					return
+				}
+				for _, page := range doc.pages() {
+					doc.pageText(page)
+				}
+			}()
+		}
+	}
+}
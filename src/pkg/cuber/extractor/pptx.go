@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+)
+
+// PPTXExtractor は、PowerPoint（.pptx）をスライドごとに抽出する抽出器です。
+// スライド順は ppt/presentation.xml の表示順に従い、読み取れない場合はファイル名の番号順とします。
+type PPTXExtractor struct{}
+
+var _ Extractor = (*PPTXExtractor)(nil)
+
+func (e *PPTXExtractor) Name() string { return "pptx" }
+
+func (e *PPTXExtractor) Extensions() []string { return []string{".pptx", ".pptm"} }
+
+func (e *PPTXExtractor) MimeTypes() []string {
+	return []string{
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/vnd.ms-powerpoint.presentation.macroenabled.12",
+	}
+}
+
+func (e *PPTXExtractor) Extract(ctx context.Context, p string) (*Extracted, error) {
+	zr, files, err := openZip(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	slides := presentationSlideOrder(files)
+	if len(slides) == 0 {
+		slides = sortedPartsByNumber(files, "ppt/slides/slide", ".xml")
+	}
+	ex := &Extracted{MetaData: map[string]any{"slide_count": len(slides)}}
+	if title := readCoreTitle(files); title != "" {
+		ex.MetaData["title"] = title
+	}
+	opt := xmlTextOptions{
+		TextElements:  set("t"),
+		BreakElements: set("p"),
+		TabElements:   set("tab", "br"),
+	}
+	for i, part := range slides {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b, err := readZipEntry(files, part)
+		if err != nil {
+			return nil, err
+		}
+		text, err := collectXMLText(b, opt)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse slide %d: %w", i+1, err)
+		}
+		if text == "" {
+			continue
+		}
+		ex.Sections = append(ex.Sections, Section{Kind: SECTION_KIND_SLIDE, Index: i + 1, Text: text})
+	}
+	return ex, nil
+}
+
+// presentationSlideOrder は、ppt/presentation.xml の sldIdLst からスライドのパートパスを表示順に返します。
+func presentationSlideOrder(files map[string]*zip.File) []string {
+	const part = "ppt/presentation.xml"
+	b, err := readZipEntry(files, part)
+	if err != nil {
+		return nil
+	}
+	var pres struct {
+		Slides []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := xml.Unmarshal(b, &pres); err != nil {
+		return nil
+	}
+	rels, err := readRelationships(files, part)
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, s := range pres.Slides {
+		if target, ok := rels[s.RelID]; ok {
+			if _, exists := files[target]; exists {
+				res = append(res, target)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package extractor
+
+import (
+	"context"
+	"os"
+)
+
+// TextExtractor は、プレーンテキスト・Markdown・HTML をそのまま読み込む抽出器です。
+// マークアップの除去は ChunkingTask 側の utils.CommonNormalize に委ねます。
+type TextExtractor struct{}
+
+var _ Extractor = (*TextExtractor)(nil)
+
+func (e *TextExtractor) Name() string { return "text" }
+
+func (e *TextExtractor) Extensions() []string {
+	return []string{".txt", ".text", ".md", ".markdown", ".html", ".htm", ".xhtml", ".log", ".json", ".xml", ".yaml", ".yml"}
+}
+
+func (e *TextExtractor) MimeTypes() []string {
+	return []string{"text/plain", "text/markdown", "text/html", "application/xhtml+xml", "application/json", "application/xml", "text/xml", "application/yaml"}
+}
+
+func (e *TextExtractor) Extract(ctx context.Context, path string) (*Extracted, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Extracted{
+		Sections: []Section{{Kind: SECTION_KIND_TEXT, Index: 1, Text: string(content)}},
+		Markup:   true,
+	}, nil
+}
@@ -0,0 +1,226 @@
+package extractor
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// XLSXExtractor は、Excel ブック（.xlsx）をシートごとに抽出する抽出器です。
+// 各シートの先頭の空でない行をヘッダーとし、CSV と同じ「列名: 値」形式の行テキストに変換します。
+// 日付セルはシリアル値のまま出力されます（スタイル情報は解釈しません）。
+type XLSXExtractor struct{}
+
+var _ Extractor = (*XLSXExtractor)(nil)
+
+func (e *XLSXExtractor) Name() string { return "xlsx" }
+
+func (e *XLSXExtractor) Extensions() []string { return []string{".xlsx", ".xlsm"} }
+
+func (e *XLSXExtractor) MimeTypes() []string {
+	return []string{
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.ms-excel.sheet.macroenabled.12",
+	}
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+type xlsxRow struct {
+	Cells []xlsxCell `xml:"c"`
+}
+
+func (e *XLSXExtractor) Extract(ctx context.Context, p string) (*Extracted, error) {
+	zr, files, err := openZip(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	const workbookPart = "xl/workbook.xml"
+	wb, err := readZipEntry(files, workbookPart)
+	if err != nil {
+		return nil, err
+	}
+	var workbook struct {
+		Sheets []struct {
+			Name  string `xml:"name,attr"`
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+			State string `xml:"state,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(wb, &workbook); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", workbookPart, err)
+	}
+	rels, err := readRelationships(files, workbookPart)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := readSharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+	ex := &Extracted{MetaData: map[string]any{}}
+	if title := readCoreTitle(files); title != "" {
+		ex.MetaData["title"] = title
+	}
+	var sheetNames []string
+	for i, sh := range workbook.Sheets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sheetNames = append(sheetNames, sh.Name)
+		if sh.State == "hidden" || sh.State == "veryHidden" {
+			continue
+		}
+		target, ok := rels[sh.RelID]
+		if !ok {
+			continue
+		}
+		b, err := readZipEntry(files, target)
+		if err != nil {
+			return nil, err
+		}
+		var sheet struct {
+			Rows []xlsxRow `xml:"sheetData>row"`
+		}
+		if err := xml.Unmarshal(b, &sheet); err != nil {
+			return nil, fmt.Errorf("Failed to parse sheet %s: %w", sh.Name, err)
+		}
+		text := formatSheet(sheet.Rows, shared)
+		if text == "" {
+			continue
+		}
+		ex.Sections = append(ex.Sections, Section{Kind: SECTION_KIND_SHEET, Index: i + 1, Name: sh.Name, Text: text})
+	}
+	ex.MetaData["sheet_names"] = sheetNames
+	return ex, nil
+}
+
+// readSharedStrings は、xl/sharedStrings.xml を読み込みます（存在しない場合は空）。
+func readSharedStrings(files map[string]*zip.File) ([]string, error) {
+	const part = "xl/sharedStrings.xml"
+	if _, ok := files[part]; !ok {
+		return nil, nil
+	}
+	b, err := readZipEntry(files, part)
+	if err != nil {
+		return nil, err
+	}
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.Unmarshal(b, &sst); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", part, err)
+	}
+	res := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		if len(si.Runs) > 0 {
+			var sb strings.Builder
+			for _, r := range si.Runs {
+				sb.WriteString(r.Text)
+			}
+			res[i] = sb.String()
+		} else {
+			res[i] = si.Text
+		}
+	}
+	return res, nil
+}
+
+// formatSheet は、シートの行を「列名: 値」形式の行テキストに変換します。
+func formatSheet(rows []xlsxRow, shared []string) string {
+	var header []string
+	var lines []string
+	for _, row := range rows {
+		record := make([]string, 0, len(row.Cells))
+		for i, c := range row.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			record[col] = cellValue(c, shared)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if header == nil {
+			header = record
+			continue
+		}
+		if line := formatRecord(header, record); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	// ヘッダー行しか無いシートはヘッダーをそのまま本文とする
+	if len(lines) == 0 && header != nil {
+		return strings.TrimSpace(strings.Join(header, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cellValue は、セルの型に応じて表示用の文字列を返します。
+func cellValue(c xlsxCell, shared []string) string {
+	switch c.Type {
+	case "s":
+		idx, err := strconv.Atoi(strings.TrimSpace(c.Value))
+		if err != nil || idx < 0 || idx >= len(shared) {
+			return ""
+		}
+		return shared[idx]
+	case "inlineStr":
+		if len(c.Inline.Runs) > 0 {
+			var sb strings.Builder
+			for _, r := range c.Inline.Runs {
+				sb.WriteString(r.Text)
+			}
+			return sb.String()
+		}
+		return c.Inline.Text
+	case "b":
+		if strings.TrimSpace(c.Value) == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return c.Value
+	}
+}
+
+// columnIndex は、セル参照（例: "AB12"）から0始まりの列番号を返します。解釈できない場合は -1。
+func columnIndex(ref string) int {
+	n := 0
+	letters := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			n = n*26 + int(r-'A'+1)
+			letters++
+			continue
+		}
+		break
+	}
+	if letters == 0 {
+		return -1
+	}
+	return n - 1
+}
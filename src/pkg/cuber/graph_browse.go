@@ -0,0 +1,318 @@
+package cuber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/tasks/resolution"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// ErrEntityNotFound は、指定されたエンティティがメモリーグループ内に存在しない場合に返されます。
+var ErrEntityNotFound = errors.New("entity not found")
+
+// EntityDetail は、1つのエンティティの詳細です。
+type EntityDetail struct {
+	Node    *storage.Node // ノード（IDはメモリーグループを含まない形式）
+	Aliases []string      // エンティティ解決で記録された別名
+	Degree  int           // 接続するエッジ数
+}
+
+// EntityNeighbor は、エンティティに隣接するノードと、それを結ぶエッジです。
+type EntityNeighbor struct {
+	Node      *storage.Node // 隣接ノード
+	Edge      *storage.Edge // エッジ（Thickness 設定済み。保存されている向きのまま）
+	Direction string        // "out": エンティティ -> 隣接ノード, "in": 隣接ノード -> エンティティ
+}
+
+// EntitySubgraph は、エンティティを中心としたサブグラフ（エゴネットワーク）です。
+type EntitySubgraph struct {
+	Nodes     []*storage.Node   // 含まれるノード（中心から近い順）
+	Triples   []*storage.Triple // 含まれるノード間のトリプル（Thickness 設定済み）
+	Truncated bool              // ノード数の上限により打ち切った場合は true
+}
+
+// SearchEntities は、名前または別名に text を含むエンティティを検索します。
+// 名前が text と一致するもの、text で始まるもの、text を含むもの、別名のみが一致するものの順に並びます。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - text: 検索文字列（空の場合は全てのエンティティ）
+//   - nodeType: エンティティのタイプ（空の場合は全てのタイプ）
+//   - offset: スキップする件数
+//   - limit: 返す最大件数
+//   - embeddingModelConfig: エンベディングモデル設定（ストレージのオープンに使用）
+//
+// 返り値:
+//   - []*storage.Node: エンティティ
+//   - int: 条件に一致したエンティティの総数
+//   - error: エラーが発生した場合
+func (s *CuberService) SearchEntities(ctx context.Context, cubeDbFilePath string, memoryGroup string, text string, nodeType string, offset int, limit int, embeddingModelConfig types.EmbeddingModelConfig) ([]*storage.Node, int, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SearchEntities: Failed to get storage: %w", err)
+	}
+	nodes, err := st.Graph.SearchNodes(ctx, text, nodeType, memoryGroup)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SearchEntities: %w", err)
+	}
+	normalized := utils.NormalizeForGraph(text)
+	if normalized != "" {
+		rank := func(n *storage.Node) int {
+			name := utils.NormalizeForGraph(n.ID)
+			switch {
+			case name == normalized:
+				return 0
+			case strings.HasPrefix(name, normalized):
+				return 1
+			case strings.Contains(name, normalized):
+				return 2
+			default:
+				return 3 // 別名のみが一致
+			}
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return rank(nodes[i]) < rank(nodes[j])
+		})
+	}
+	return paginate(nodes, offset, limit), len(nodes), nil
+}
+
+// GetEntity は、エンティティの詳細（プロパティ・別名・接続するエッジ数）を取得します。
+// エンティティが存在しない場合は ErrEntityNotFound を返します。
+func (s *CuberService) GetEntity(ctx context.Context, cubeDbFilePath string, memoryGroup string, entityID string, embeddingModelConfig types.EmbeddingModelConfig) (*EntityDetail, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntity: Failed to get storage: %w", err)
+	}
+	node, err := getEntityNode(ctx, st, memoryGroup, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntity: %w", err)
+	}
+	triples, err := st.Graph.GetTriples(ctx, []string{entityID}, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntity: Failed to get triples: %w", err)
+	}
+	degree := 0
+	for _, triple := range triples {
+		if !isDocumentChunkTriple(triple) {
+			degree++
+		}
+	}
+	return &EntityDetail{Node: node, Aliases: resolution.GetAliases(node), Degree: degree}, nil
+}
+
+// GetEntityNeighbors は、エンティティに隣接するノードを、接続するエッジの Thickness が大きい順に取得します。
+// エンティティが存在しない場合は ErrEntityNotFound を返します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - entityID: エンティティのID（メモリーグループを含まない形式）
+//   - offset: スキップする件数
+//   - limit: 返す最大件数
+//   - embeddingModelConfig: エンベディングモデル設定（ストレージのオープンに使用）
+//
+// 返り値:
+//   - []*EntityNeighbor: 隣接ノード（同じノードと複数のエッジで接続している場合は、エッジごとに1件）
+//   - int: 隣接ノードの総数
+//   - error: エラーが発生した場合
+func (s *CuberService) GetEntityNeighbors(ctx context.Context, cubeDbFilePath string, memoryGroup string, entityID string, offset int, limit int, embeddingModelConfig types.EmbeddingModelConfig) ([]*EntityNeighbor, int, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntityNeighbors: Failed to get storage: %w", err)
+	}
+	if _, err := getEntityNode(ctx, st, memoryGroup, entityID); err != nil {
+		return nil, 0, fmt.Errorf("GetEntityNeighbors: %w", err)
+	}
+	thickness, err := newThicknessFunc(ctx, st, memoryGroup)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntityNeighbors: %w", err)
+	}
+	triples, err := st.Graph.GetTriples(ctx, []string{entityID}, memoryGroup)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetEntityNeighbors: Failed to get triples: %w", err)
+	}
+	neighbors := []*EntityNeighbor{}
+	for _, triple := range triples {
+		if isDocumentChunkTriple(triple) {
+			continue
+		}
+		triple.Edge.Thickness = thickness(triple.Edge)
+		if triple.Source.ID == entityID {
+			neighbors = append(neighbors, &EntityNeighbor{Node: triple.Target, Edge: triple.Edge, Direction: "out"})
+		} else {
+			neighbors = append(neighbors, &EntityNeighbor{Node: triple.Source, Edge: triple.Edge, Direction: "in"})
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		if neighbors[i].Edge.Thickness != neighbors[j].Edge.Thickness {
+			return neighbors[i].Edge.Thickness > neighbors[j].Edge.Thickness
+		}
+		if neighbors[i].Node.ID != neighbors[j].Node.ID {
+			return neighbors[i].Node.ID < neighbors[j].Node.ID
+		}
+		return neighbors[i].Edge.Type < neighbors[j].Edge.Type
+	})
+	return paginate(neighbors, offset, limit), len(neighbors), nil
+}
+
+// GetEntitySubgraph は、エンティティを中心に depth ホップ以内のノードと、それらの間のトリプルを取得します。
+// ノードは中心から近い順に、同じ深さでは太いエッジで接続されたものから順に含め、maxNodes に達した時点で打ち切ります。
+// Thickness が minThickness 未満のエッジは辿らず、結果にも含めません。
+// エンティティが存在しない場合は ErrEntityNotFound を返します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - memoryGroup: メモリグループ名
+//   - entityID: 中心のエンティティのID（メモリーグループを含まない形式）
+//   - depth: 深さ（ホップ数）
+//   - maxNodes: 含めるノード数の上限（中心のエンティティを含む）
+//   - minThickness: 辿るエッジの Thickness の下限（0 の場合は全てのエッジ）
+//   - embeddingModelConfig: エンベディングモデル設定（ストレージのオープンに使用）
+//
+// 返り値:
+//   - *EntitySubgraph: サブグラフ
+//   - error: エラーが発生した場合
+func (s *CuberService) GetEntitySubgraph(ctx context.Context, cubeDbFilePath string, memoryGroup string, entityID string, depth int, maxNodes int, minThickness float64, embeddingModelConfig types.EmbeddingModelConfig) (*EntitySubgraph, error) {
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, embeddingModelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntitySubgraph: Failed to get storage: %w", err)
+	}
+	center, err := getEntityNode(ctx, st, memoryGroup, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntitySubgraph: %w", err)
+	}
+	thickness, err := newThicknessFunc(ctx, st, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntitySubgraph: %w", err)
+	}
+	// traversable は、Thickness を設定し、辿ることのできるトリプルかどうかを返す
+	traversable := func(triple *storage.Triple) bool {
+		if isDocumentChunkTriple(triple) {
+			return false
+		}
+		triple.Edge.Thickness = thickness(triple.Edge)
+		return triple.Edge.Thickness >= minThickness
+	}
+	subgraph := &EntitySubgraph{Nodes: []*storage.Node{center}, Triples: []*storage.Triple{}}
+	included := map[string]bool{center.ID: true}
+	frontier := []string{center.ID}
+	// 1. 幅優先でノードを追加
+	for d := 0; d < depth && len(frontier) > 0 && !subgraph.Truncated; d++ {
+		triples, err := st.Graph.GetTriples(ctx, frontier, memoryGroup)
+		if err != nil {
+			return nil, fmt.Errorf("GetEntitySubgraph: Failed to get triples: %w", err)
+		}
+		// 新しく見つかったノードごとに、接続するエッジの Thickness の最大値を求める
+		candidates := make(map[string]*storage.Node)
+		best := make(map[string]float64)
+		for _, triple := range triples {
+			if !traversable(triple) {
+				continue
+			}
+			for _, n := range []*storage.Node{triple.Source, triple.Target} {
+				if included[n.ID] {
+					continue
+				}
+				if _, ok := candidates[n.ID]; !ok || triple.Edge.Thickness > best[n.ID] {
+					candidates[n.ID] = n
+					best[n.ID] = triple.Edge.Thickness
+				}
+			}
+		}
+		ids := make([]string, 0, len(candidates))
+		for id := range candidates {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, func(a, b string) int {
+			if best[a] != best[b] {
+				if best[a] > best[b] {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+		frontier = nil
+		for _, id := range ids {
+			if len(subgraph.Nodes) >= maxNodes {
+				subgraph.Truncated = true
+				break
+			}
+			included[id] = true
+			subgraph.Nodes = append(subgraph.Nodes, candidates[id])
+			frontier = append(frontier, id)
+		}
+	}
+	// 2. 含まれるノード間のトリプルを収集
+	ids := make([]string, 0, len(subgraph.Nodes))
+	for _, n := range subgraph.Nodes {
+		ids = append(ids, n.ID)
+	}
+	triples, err := st.Graph.GetTriples(ctx, ids, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("GetEntitySubgraph: Failed to get triples: %w", err)
+	}
+	for _, triple := range triples {
+		if included[triple.Source.ID] && included[triple.Target.ID] && traversable(triple) {
+			subgraph.Triples = append(subgraph.Triples, triple)
+		}
+	}
+	return subgraph, nil
+}
+
+// getEntityNode は、エンティティのノードを取得します（IDはメモリーグループを含まない形式）。
+// 存在しない場合、または DocumentChunk ノードの場合は ErrEntityNotFound を返します。
+func getEntityNode(ctx context.Context, st *StorageSet, memoryGroup string, entityID string) (*storage.Node, error) {
+	nodes, err := st.Graph.GetNodesByIDs(ctx, []string{utils.MakeGraphNodeID(entityID, memoryGroup)}, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entity: %w", err)
+	}
+	if len(nodes) == 0 || nodes[0].Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) {
+		return nil, ErrEntityNotFound
+	}
+	node := nodes[0]
+	node.ID = utils.GetNameStrByGraphNodeID(node.ID)
+	return node, nil
+}
+
+// newThicknessFunc は、メモリーグループの半減期と最新のエッジの時刻に基づいて、エッジの Thickness を計算する関数を返します。
+func newThicknessFunc(ctx context.Context, st *StorageSet, memoryGroup string) (func(*storage.Edge) float64, error) {
+	maxUnix, err := st.Graph.GetMaxUnix(ctx, memoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get max unix: %w", err)
+	}
+	halfLifeDays := appconfig.DEFAULT_HALF_LIFE_DAYS
+	if groupConfig, _ := st.Graph.GetMemoryGroupConfig(ctx, memoryGroup); groupConfig != nil && groupConfig.HalfLifeDays > 0 {
+		halfLifeDays = groupConfig.HalfLifeDays
+	}
+	lambda := utils.CalculateLambda(halfLifeDays)
+	return func(e *storage.Edge) float64 {
+		return utils.CalculateThickness(e.Weight, e.Confidence, e.Unix, maxUnix, lambda)
+	}, nil
+}
+
+// isDocumentChunkTriple は、トリプルのどちらかの端が DocumentChunk ノードかどうかを返します。
+func isDocumentChunkTriple(triple *storage.Triple) bool {
+	return triple.Source.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) || triple.Target.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK)
+}
+
+// paginate は、offset 件をスキップした最大 limit 件を返します。
+func paginate[T any](items []T, offset int, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
@@ -0,0 +1,387 @@
+package cuber
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/tasks/resolution"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+const (
+	testBrowseCubePath    = "/cubes/browse-cube.db"
+	testBrowseMemoryGroup = "g1"
+	testBrowseMaxUnix     = int64(1_700_000_000_000)
+)
+
+// browseGraphStorage は、メモリ上のノードとトリプルを返すテスト用の GraphStorage です。
+// ノードIDはメモリーグループを含まない形式で保持し、GetNodesByIDs にはメモリーグループを含む形式で問い合わせる点を LadybugDB に合わせています。
+type browseGraphStorage struct {
+	storage.GraphStorage
+	nodes   []*storage.Node
+	triples []*storage.Triple
+	config  *storage.MemoryGroupConfig
+}
+
+func (s *browseGraphStorage) GetNodesByIDs(ctx context.Context, ids []string, memoryGroup string) ([]*storage.Node, error) {
+	var found []*storage.Node
+	for _, n := range s.nodes {
+		if slices.Contains(ids, utils.MakeGraphNodeID(n.ID, memoryGroup)) {
+			copied := *n
+			copied.ID = utils.MakeGraphNodeID(n.ID, memoryGroup)
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (s *browseGraphStorage) SearchNodes(ctx context.Context, text string, nodeType string, memoryGroup string) ([]*storage.Node, error) {
+	var found []*storage.Node
+	for _, n := range s.nodes {
+		if n.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) || nodeType != "" && n.Type != nodeType {
+			continue
+		}
+		names := append([]string{n.ID}, resolution.GetAliases(n)...)
+		if slices.ContainsFunc(names, func(name string) bool { return strings.Contains(strings.ToLower(name), strings.ToLower(text)) }) {
+			found = append(found, n)
+		}
+	}
+	return found, nil
+}
+
+func (s *browseGraphStorage) GetTriples(ctx context.Context, nodeIDs []string, memoryGroup string) ([]*storage.Triple, error) {
+	var found []*storage.Triple
+	for _, triple := range s.triples {
+		if slices.Contains(nodeIDs, triple.Source.ID) || slices.Contains(nodeIDs, triple.Target.ID) {
+			edge := *triple.Edge
+			found = append(found, &storage.Triple{Source: triple.Source, Edge: &edge, Target: triple.Target})
+		}
+	}
+	return found, nil
+}
+
+func (s *browseGraphStorage) GetMaxUnix(ctx context.Context, memoryGroup string) (int64, error) {
+	return testBrowseMaxUnix, nil
+}
+
+func (s *browseGraphStorage) GetMemoryGroupConfig(ctx context.Context, memoryGroup string) (*storage.MemoryGroupConfig, error) {
+	return s.config, nil
+}
+
+// newBrowseTestService は、次のグラフを持つ Cube を開いた状態の CuberService を返します。
+//
+//	alice -works_at(0.9)-> acme -located_in(0.8)-> tokyo
+//	alice -knows(0.5)-> bob, bob -mentors(0.5)-> alice, bob -lives_in(0.2)-> osaka
+//	chunk1(DocumentChunk) -mentions-> alice
+//
+// 括弧内は Thickness です（lives_in は半減期1日分だけ古い重み 0.4 のエッジ）。
+func newBrowseTestService() *CuberService {
+	node := func(id string, nodeType string, aliases ...string) *storage.Node {
+		n := &storage.Node{ID: id, MemoryGroup: testBrowseMemoryGroup, Type: nodeType, Properties: map[string]any{}}
+		if len(aliases) > 0 {
+			n.Properties[resolution.ALIASES_PROPERTY] = aliases
+		}
+		return n
+	}
+	alice := node("alice", "person", "Ally")
+	acme := node("acme", "organization")
+	tokyo := node("tokyo", "city")
+	bob := node("bob", "person", "Alistair")
+	osaka := node("osaka", "city")
+	chunk := node("chunk1", string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK))
+	triple := func(source *storage.Node, edgeType string, target *storage.Node, weight float64, unix int64) *storage.Triple {
+		return &storage.Triple{
+			Source: source,
+			Edge:   &storage.Edge{SourceID: source.ID, TargetID: target.ID, MemoryGroup: testBrowseMemoryGroup, Type: edgeType, Weight: weight, Confidence: 1, Unix: unix},
+			Target: target,
+		}
+	}
+	halfLifeAgo := testBrowseMaxUnix - int64(utils.DaysToMillis(1))
+	graph := &browseGraphStorage{
+		nodes: []*storage.Node{bob, node("malice corp", "organization"), alice, node("ali", "person"), acme, tokyo, osaka, chunk},
+		triples: []*storage.Triple{
+			triple(alice, "works_at", acme, 0.9, testBrowseMaxUnix),
+			triple(alice, "knows", bob, 0.5, testBrowseMaxUnix),
+			triple(bob, "mentors", alice, 0.5, testBrowseMaxUnix),
+			triple(acme, "located_in", tokyo, 0.8, testBrowseMaxUnix),
+			triple(bob, "lives_in", osaka, 0.4, halfLifeAgo),
+			triple(chunk, "mentions", alice, 1, testBrowseMaxUnix),
+		},
+		config: &storage.MemoryGroupConfig{ID: testBrowseMemoryGroup, HalfLifeDays: 1},
+	}
+	return &CuberService{StorageMap: map[string]*StorageSet{getUUIDFromDBFilePath(testBrowseCubePath): {Graph: graph}}}
+}
+
+// tripleKeys は、トリプルを「ソース-タイプ->ターゲット」の形式で、ソートして返します。
+func tripleKeys(triples []*storage.Triple) []string {
+	keys := make([]string, 0, len(triples))
+	for _, triple := range triples {
+		keys = append(keys, triple.Source.ID+"-"+triple.Edge.Type+"->"+triple.Target.ID)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestSearchEntities(t *testing.T) {
+	s := newBrowseTestService()
+	tests := []struct {
+		name      string
+		text      string
+		nodeType  string
+		offset    int
+		limit     int
+		want      []string
+		wantTotal int
+	}{
+		// 名前の一致、前方一致、部分一致、別名のみの一致の順
+		{name: "ranked by match", text: "Ali", limit: 10, want: []string{"ali", "alice", "malice corp", "bob"}, wantTotal: 4},
+		{name: "filtered by type", text: "ali", nodeType: "person", limit: 10, want: []string{"ali", "alice", "bob"}, wantTotal: 3},
+		{name: "paginated", text: "ali", offset: 1, limit: 2, want: []string{"alice", "malice corp"}, wantTotal: 4},
+		{name: "offset beyond total", text: "ali", offset: 4, limit: 2, want: []string{}, wantTotal: 4},
+		{name: "empty text keeps storage order", nodeType: "city", limit: 10, want: []string{"tokyo", "osaka"}, wantTotal: 2},
+		{name: "no match", text: "kyoto", limit: 10, want: []string{}, wantTotal: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, total, err := s.SearchEntities(context.Background(), testBrowseCubePath, testBrowseMemoryGroup, tt.text, tt.nodeType, tt.offset, tt.limit, types.EmbeddingModelConfig{})
+			if err != nil {
+				t.Fatalf("SearchEntities failed: %v", err)
+			}
+			ids := []string{}
+			for _, n := range nodes {
+				ids = append(ids, n.ID)
+			}
+			if !slices.Equal(ids, tt.want) || total != tt.wantTotal {
+				t.Errorf("SearchEntities() = %q (total %d), want %q (total %d)", ids, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestGetEntity(t *testing.T) {
+	s := newBrowseTestService()
+	tests := []struct {
+		name        string
+		entityID    string
+		wantAliases []string
+		wantDegree  int
+		wantErr     error
+	}{
+		// DocumentChunk との接続は数えない
+		{name: "entity", entityID: "alice", wantAliases: []string{"Ally"}, wantDegree: 3},
+		{name: "entity without aliases", entityID: "tokyo", wantDegree: 1},
+		{name: "unknown entity", entityID: "kyoto", wantErr: ErrEntityNotFound},
+		{name: "document chunk", entityID: "chunk1", wantErr: ErrEntityNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail, err := s.GetEntity(context.Background(), testBrowseCubePath, testBrowseMemoryGroup, tt.entityID, types.EmbeddingModelConfig{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEntity failed: %v", err)
+			}
+			// ID はメモリーグループを含まない形式で返る
+			if detail.Node.ID != tt.entityID {
+				t.Errorf("node ID = %q, want %q", detail.Node.ID, tt.entityID)
+			}
+			if !slices.Equal(detail.Aliases, tt.wantAliases) || detail.Degree != tt.wantDegree {
+				t.Errorf("aliases = %q, degree = %d, want %q, %d", detail.Aliases, detail.Degree, tt.wantAliases, tt.wantDegree)
+			}
+		})
+	}
+}
+
+func TestGetEntityNeighbors(t *testing.T) {
+	s := newBrowseTestService()
+	tests := []struct {
+		name          string
+		entityID      string
+		offset        int
+		limit         int
+		want          []string // 隣接ノード:エッジタイプ:向き
+		wantThickness []float64
+		wantTotal     int
+		wantErr       error
+	}{
+		{
+			name:          "ordered by thickness, node and edge type",
+			entityID:      "alice",
+			limit:         10,
+			want:          []string{"acme:works_at:out", "bob:knows:out", "bob:mentors:in"},
+			wantThickness: []float64{0.9, 0.5, 0.5},
+			wantTotal:     3,
+		},
+		{
+			name:          "thickness decays with the half life",
+			entityID:      "bob",
+			limit:         10,
+			want:          []string{"alice:knows:in", "alice:mentors:out", "osaka:lives_in:out"},
+			wantThickness: []float64{0.5, 0.5, 0.2},
+			wantTotal:     3,
+		},
+		{
+			name:          "paginated",
+			entityID:      "alice",
+			offset:        1,
+			limit:         1,
+			want:          []string{"bob:knows:out"},
+			wantThickness: []float64{0.5},
+			wantTotal:     3,
+		},
+		{name: "unknown entity", entityID: "kyoto", limit: 10, wantErr: ErrEntityNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			neighbors, total, err := s.GetEntityNeighbors(context.Background(), testBrowseCubePath, testBrowseMemoryGroup, tt.entityID, tt.offset, tt.limit, types.EmbeddingModelConfig{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEntityNeighbors failed: %v", err)
+			}
+			var got []string
+			var thickness []float64
+			for _, n := range neighbors {
+				got = append(got, n.Node.ID+":"+n.Edge.Type+":"+n.Direction)
+				thickness = append(thickness, n.Edge.Thickness)
+			}
+			if !slices.Equal(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("GetEntityNeighbors() = %q (total %d), want %q (total %d)", got, total, tt.want, tt.wantTotal)
+			}
+			if !slices.EqualFunc(thickness, tt.wantThickness, func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }) {
+				t.Errorf("thickness = %v, want %v", thickness, tt.wantThickness)
+			}
+		})
+	}
+}
+
+func TestGetEntitySubgraph(t *testing.T) {
+	s := newBrowseTestService()
+	tests := []struct {
+		name          string
+		entityID      string
+		depth         int
+		maxNodes      int
+		minThickness  float64
+		wantNodes     []string
+		wantTriples   []string
+		wantTruncated bool
+		wantErr       error
+	}{
+		{
+			name:        "depth 0",
+			entityID:    "alice",
+			maxNodes:    10,
+			wantNodes:   []string{"alice"},
+			wantTriples: []string{},
+		},
+		{
+			name:        "depth 1",
+			entityID:    "alice",
+			depth:       1,
+			maxNodes:    10,
+			wantNodes:   []string{"alice", "acme", "bob"},
+			wantTriples: []string{"alice-knows->bob", "alice-works_at->acme", "bob-mentors->alice"},
+		},
+		{
+			name:        "depth 2 adds nodes in thickness order",
+			entityID:    "alice",
+			depth:       2,
+			maxNodes:    10,
+			wantNodes:   []string{"alice", "acme", "bob", "tokyo", "osaka"},
+			wantTriples: []string{"acme-located_in->tokyo", "alice-knows->bob", "alice-works_at->acme", "bob-lives_in->osaka", "bob-mentors->alice"},
+		},
+		{
+			name:          "truncated by max nodes",
+			entityID:      "alice",
+			depth:         2,
+			maxNodes:      4,
+			wantNodes:     []string{"alice", "acme", "bob", "tokyo"},
+			wantTriples:   []string{"acme-located_in->tokyo", "alice-knows->bob", "alice-works_at->acme", "bob-mentors->alice"},
+			wantTruncated: true,
+		},
+		{
+			name:          "truncated at the first hop",
+			entityID:      "alice",
+			depth:         2,
+			maxNodes:      2,
+			wantNodes:     []string{"alice", "acme"},
+			wantTriples:   []string{"alice-works_at->acme"},
+			wantTruncated: true,
+		},
+		{
+			name:         "thin edges are not followed",
+			entityID:     "alice",
+			depth:        2,
+			maxNodes:     10,
+			minThickness: 0.6,
+			wantNodes:    []string{"alice", "acme", "tokyo"},
+			wantTriples:  []string{"acme-located_in->tokyo", "alice-works_at->acme"},
+		},
+		{name: "unknown entity", entityID: "kyoto", depth: 1, maxNodes: 10, wantErr: ErrEntityNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subgraph, err := s.GetEntitySubgraph(context.Background(), testBrowseCubePath, testBrowseMemoryGroup, tt.entityID, tt.depth, tt.maxNodes, tt.minThickness, types.EmbeddingModelConfig{})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEntitySubgraph failed: %v", err)
+			}
+			var nodes []string
+			for _, n := range subgraph.Nodes {
+				nodes = append(nodes, n.ID)
+			}
+			if !slices.Equal(nodes, tt.wantNodes) {
+				t.Errorf("nodes = %q, want %q", nodes, tt.wantNodes)
+			}
+			if got := tripleKeys(subgraph.Triples); !slices.Equal(got, tt.wantTriples) {
+				t.Errorf("triples = %q, want %q", got, tt.wantTriples)
+			}
+			if subgraph.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", subgraph.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name   string
+		offset int
+		limit  int
+		want   []int
+	}{
+		{"first page", 0, 2, []int{1, 2}},
+		{"middle page", 2, 2, []int{3, 4}},
+		{"last page is short", 4, 2, []int{5}},
+		{"offset at the end", 5, 2, []int{}},
+		{"offset beyond the end", 9, 2, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paginate(items, tt.offset, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("paginate(%d, %d) = %v, want %v", tt.offset, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package cuber
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/checkpoint"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// MigrateEmbeddings は、Cube の埋め込みモデルを移行します。
+// Chunk / Entity / Summary / Rule / Unknown / Capability の全行を新しいモデルで再ベクトル化し、
+// embedding カラムを新しい次元数のカラムに置き換えます。Memify の結果を含む既存の知識はそのまま保持されます。
+//
+// 処理の流れ:
+//  1. 埋め込みテーブルに新しい次元数のカラム（embedding_next）を追加
+//  2. 既存の行をバッチごとに再ベクトル化して embedding_next に保存（この間も Query / Absorb は旧モデルで利用できる）
+//  3. トランザクション内で、移行中に追加された行を再ベクトル化し、embedding を embedding_next に置き換えて onComplete を呼び出す
+//  4. ベクトルインデックスを作成し直す
+//
+// 失敗・キャンセルした場合は embedding_next を削除し、移行前の状態に戻します。
+// 旧モデルで作成された Absorb のチェックポイントは再利用できないため、移行の完了時に削除します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - cubeDbFilePath: CubeのDBファイルパス
+//   - currentConfig: 現在の埋め込みモデル設定
+//   - newConfig: 移行先の埋め込みモデル設定
+//   - onComplete: カラムを置き換えた後、コミットの直前に呼び出す関数（Cube の埋め込みモデル設定の更新など）。エラーを返すと移行はロールバックされる
+//   - dataCh: イベントの送信先（nil の場合は送信しない）
+//
+// 返り値:
+//   - int: 再ベクトル化した行数
+//   - types.TokenUsage: トークン使用量
+//   - error: エラーが発生した場合
+func (s *CuberService) MigrateEmbeddings(
+	ctx context.Context,
+	eb *eventbus.EventBus,
+	cubeDbFilePath string,
+	currentConfig types.EmbeddingModelConfig,
+	newConfig types.EmbeddingModelConfig,
+	onComplete func() error,
+	dataCh chan<- event.StreamEvent,
+) (int, types.TokenUsage, error) {
+	var usage types.TokenUsage
+	cubeUUID := getUUIDFromDBFilePath(cubeDbFilePath)
+
+	// Register Events
+	if dataCh != nil {
+		event.RegisterMigrationStreamer(eb, dataCh)
+		event.RegisterInfoStreamer(eb, dataCh)
+	}
+
+	st, err := s.GetOrOpenStorage(cubeDbFilePath, currentConfig)
+	if err != nil {
+		return 0, usage, fmt.Errorf("MigrateEmbeddings: Failed to open storage for cube %s: %w", cubeUUID, err)
+	}
+	embedder, err := s.createCachedEmbedder(ctx, st, newConfig)
+	if err != nil {
+		return 0, usage, fmt.Errorf("MigrateEmbeddings: Failed to create embedder: %w", err)
+	}
+	// ========================================
+	// 1. 移行先のカラムを追加
+	// ========================================
+	if err := st.Vector.PrepareEmbeddingMigration(ctx, newConfig.Dimension); err != nil {
+		return 0, usage, fmt.Errorf("MigrateEmbeddings: %w", err)
+	}
+	migration := &embeddingMigration{
+		eb:        eb,
+		embedder:  embedder,
+		dimension: int(newConfig.Dimension),
+	}
+	// 失敗した場合は移行先のカラムを削除して、移行前の状態に戻す
+	abort := func(err error) (int, types.TokenUsage, error) {
+		if aerr := st.Vector.AbortEmbeddingMigration(context.Background()); aerr != nil {
+			utils.LogWarn(s.Logger, "MigrateEmbeddings: Failed to abort migration", zap.String("cube", cubeUUID), zap.Error(aerr))
+		}
+		emitIfOperationCancelled(ctx, eb, types.ACTION_TYPE_MIGRATE_EMBEDDINGS, "")
+		return migration.done, migration.usage, fmt.Errorf("MigrateEmbeddings: %w", err)
+	}
+	if err := migration.countPending(ctx, st.Vector); err != nil {
+		return abort(err)
+	}
+	eventbus.Emit(eb, string(event.EVENT_MIGRATE_EMBEDDINGS_START), event.MigrateEmbeddingsStartPayload{
+		BasePayload: event.NewBasePayload(""),
+		Model:       newConfig.Model,
+		Dimension:   newConfig.Dimension,
+		TotalCount:  migration.total,
+	})
+	utils.LogInfo(s.Logger, "MigrateEmbeddings: Starting", zap.String("cube", cubeUUID), zap.String("model", newConfig.Model), zap.Uint("dimension", newConfig.Dimension), zap.Int("total", migration.total))
+	// ========================================
+	// 2. 既存の行を再ベクトル化（Cube は排他しない）
+	// ========================================
+	if err := migration.reembedAll(ctx, st.Vector); err != nil {
+		return abort(err)
+	}
+	// ========================================
+	// 3. 移行中に追加された行を再ベクトル化し、カラムを置き換え（Cube を排他）
+	// ========================================
+	err = st.Vector.Transaction(ctx, func(txCtx context.Context) error {
+		if err := migration.countPending(txCtx, st.Vector); err != nil {
+			return err
+		}
+		if err := migration.reembedAll(txCtx, st.Vector); err != nil {
+			return err
+		}
+		if err := st.Vector.CompleteEmbeddingMigration(txCtx); err != nil {
+			return err
+		}
+		if onComplete != nil {
+			return onComplete()
+		}
+		return nil
+	})
+	if err != nil {
+		return abort(err)
+	}
+	// ========================================
+	// 4. ベクトルインデックスの再作成・後片付け
+	// ========================================
+	// 失敗したテーブルは全件走査で検索されるため、移行自体は成功とする
+	if err := st.Vector.RebuildVectorIndexes(context.Background()); err != nil {
+		utils.LogWarn(s.Logger, "MigrateEmbeddings: Failed to rebuild vector indexes", zap.String("cube", cubeUUID), zap.Error(err))
+	}
+	if err := st.Vector.Checkpoint(); err != nil {
+		utils.LogWarn(s.Logger, "MigrateEmbeddings: Failed to checkpoint storage", zap.Error(err))
+	}
+	if err := os.RemoveAll(checkpoint.DirPath(cubeDbFilePath)); err != nil {
+		utils.LogWarn(s.Logger, "MigrateEmbeddings: Failed to delete absorb checkpoints", zap.String("cube", cubeUUID), zap.Error(err))
+	}
+	utils.LogInfo(s.Logger, "MigrateEmbeddings: Completed", zap.String("cube", cubeUUID), zap.Int("migrated", migration.done))
+	eventbus.EmitSync(eb, string(event.EVENT_MIGRATE_EMBEDDINGS_END), event.MigrateEmbeddingsEndPayload{
+		BasePayload: event.NewBasePayload(""),
+		TotalCount:  migration.done,
+		TotalTokens: migration.usage,
+	})
+	time.Sleep(150 * time.Millisecond) // Ensure event is processed before function return
+	return migration.done, migration.usage, nil
+}
+
+// embeddingMigration は、MigrateEmbeddings の再ベクトル化の進捗です。
+type embeddingMigration struct {
+	eb        *eventbus.EventBus
+	embedder  storage.Embedder
+	dimension int
+	total     int // 再ベクトル化の対象の行数（移行中に追加された行を含む）
+	done      int // 再ベクトル化した行数
+	usage     types.TokenUsage
+}
+
+// countPending は、再ベクトル化されていない行数を対象の行数に加えます。
+func (m *embeddingMigration) countPending(ctx context.Context, vs storage.VectorStorage) error {
+	pending := 0
+	for _, tableName := range types.EMBEDDING_TABLE_NAMES {
+		n, err := vs.CountPendingEmbeddings(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		pending += n
+	}
+	m.total = m.done + pending
+	return nil
+}
+
+// reembedAll は、全ての埋め込みテーブルの再ベクトル化されていない行を、バッチごとに再ベクトル化します。
+func (m *embeddingMigration) reembedAll(ctx context.Context, vs storage.VectorStorage) error {
+	for _, tableName := range types.EMBEDDING_TABLE_NAMES {
+		afterID := ""
+		for {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			sources, err := vs.GetPendingEmbeddings(ctx, tableName, afterID, appconfig.EMBEDDING_MIGRATION_BATCH_SIZE)
+			if err != nil {
+				return err
+			}
+			if len(sources) == 0 {
+				break
+			}
+			texts := make([]string, len(sources))
+			for i, src := range sources {
+				texts[i] = src.Text
+			}
+			vectors, u, err := m.embedder.EmbedBatch(ctx, texts)
+			m.usage.Add(u)
+			if err != nil {
+				return fmt.Errorf("Failed to embed %s: %w", tableName, err)
+			}
+			for i, src := range sources {
+				vector := vectors[i]
+				if len(vector) != m.dimension {
+					return fmt.Errorf("Dimension mismatch for %s '%s': expected %d, got %d", tableName, src.ID, m.dimension, len(vector))
+				}
+				if err := vs.SaveMigratedEmbedding(ctx, tableName, src.ID, vector); err != nil {
+					return err
+				}
+			}
+			afterID = sources[len(sources)-1].ID
+			m.done += len(sources)
+			eventbus.Emit(m.eb, string(event.EVENT_MIGRATE_EMBEDDINGS_PROGRESS), event.MigrateEmbeddingsProgressPayload{
+				BasePayload: event.NewBasePayload(""),
+				TableName:   string(tableName),
+				DoneCount:   m.done,
+				TotalCount:  max(m.total, m.done),
+			})
+		}
+	}
+	return nil
+}
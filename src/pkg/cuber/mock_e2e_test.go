@@ -0,0 +1,166 @@
+package cuber
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/providers"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"go.uber.org/zap"
+)
+
+const (
+	testMockChatModel   = "mock-chat-e2e"
+	testMockAnswer      = "Alice works at Acme Corporation."
+	testMockMemoryGroup = "e2e"
+)
+
+// registerTestMockFixtures は、Absorb / Query / Memify が呼び出すタスクの応答を、システムプロンプトのハッシュをキーに登録します。
+// ユーザープロンプト（チャンクの本文など）には依存しないため、チャンクの分割や並行実行の順序が変わっても同じ応答になります。
+func registerTestMockFixtures(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	byPrompt := func(name prompts.Name, content string) (string, *schema.Message) {
+		return providers.MockSystemPromptKey(prompts.Get(ctx, name)), &schema.Message{Role: schema.Assistant, Content: content}
+	}
+	responses := map[string]*schema.Message{}
+	for name, content := range map[prompts.Name]string{
+		prompts.NAME_GENERATE_GRAPH_EN: `{"nodes": [` +
+			`{"id": "Alice", "type": "Person", "properties": {"name": "Alice"}},` +
+			`{"id": "Acme Corporation", "type": "Organization", "properties": {"name": "Acme Corporation"}}` +
+			`], "edges": [` +
+			`{"source_id": "Alice", "target_id": "Acme Corporation", "type": "WORKS_AT", "properties": {}}` +
+			`]}`,
+		prompts.NAME_ANSWER_QUERY_WITH_HYBRID_RAG_EN: testMockAnswer,
+		prompts.NAME_RULE_EXTRACTION_EN:              `{"rules": [{"text": "Record where each person works."}]}`,
+	} {
+		key, msg := byPrompt(name, content)
+		responses[key] = msg
+	}
+	providers.RegisterMockChatFixtures(testMockChatModel, &providers.MockChatFixtures{
+		Responses: responses,
+		// 要約・コミュニティ要約など、上記以外のタスクは自由記述の応答で足りる
+		Default: &schema.Message{Role: schema.Assistant, Content: "Alice is an employee of Acme Corporation."},
+	})
+	t.Cleanup(func() { providers.RegisterMockChatFixtures(testMockChatModel, nil) })
+}
+
+// testCube は、テスト用の CuberService と、mock プロバイダーを使用する Cube です。
+type testCube struct {
+	s               *CuberService
+	dir             string
+	cubeDbFilePath  string
+	embeddingConfig types.EmbeddingModelConfig
+	chatConfig      types.ChatModelConfig
+}
+
+// newTestCube は、一時ディレクトリに CuberService と Cube を作成します。
+// 応答のフィクスチャは registerTestMockFixtures で登録しておく必要があります。
+func newTestCube(t *testing.T) *testCube {
+	t.Helper()
+	dir := t.TempDir()
+	logger := zap.NewNop()
+	s, err := NewCuberService(types.CuberConfig{
+		DBDirPath:  dir,
+		S3UseLocal: true,
+		S3LocalDir: filepath.Join(dir, "files"),
+		S3DLDir:    filepath.Join(dir, "down"),
+		Logger:     logger,
+	})
+	if err != nil {
+		t.Fatalf("NewCuberService failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	c := &testCube{
+		s:               s,
+		dir:             dir,
+		cubeDbFilePath:  filepath.Join(dir, "cube", "e2e.db"),
+		embeddingConfig: types.EmbeddingModelConfig{Provider: string(providers.ProviderMock), Model: "mock-embed-e2e", Dimension: 64},
+		chatConfig:      types.ChatModelConfig{Provider: string(providers.ProviderMock), Model: testMockChatModel},
+	}
+	if err := CreateCubeDB(c.cubeDbFilePath, c.embeddingConfig, logger); err != nil {
+		t.Fatalf("CreateCubeDB failed: %v", err)
+	}
+	return c
+}
+
+// writeFile は、一時ディレクトリにファイルを作成し、そのパスを返します。
+func (c *testCube) writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(c.dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// absorb は、ファイルを testMockMemoryGroup に取り込みます。
+func (c *testCube) absorb(t *testing.T, filePaths []string, sourceID string) {
+	t.Helper()
+	if _, err := c.s.Absorb(context.Background(), eventbus.New(), c.cubeDbFilePath, testMockMemoryGroup, filePaths, sourceID,
+		types.CognifyConfig{ChunkSize: 500, ChunkOverlap: 50}, c.embeddingConfig, c.chatConfig, nil, true); err != nil {
+		t.Fatalf("Absorb failed: %v", err)
+	}
+}
+
+// storage は、Cube のストレージを返します。
+func (c *testCube) storage(t *testing.T) *StorageSet {
+	t.Helper()
+	st, err := c.s.GetOrOpenStorage(c.cubeDbFilePath, c.embeddingConfig)
+	if err != nil {
+		t.Fatalf("GetOrOpenStorage failed: %v", err)
+	}
+	return st
+}
+
+// TestMockProviderEndToEnd は、mock プロバイダーで Absorb → Query → Memify を実行し、
+// グラフ抽出の JSON のパースからルールの保存までがネットワークに接続せずに完了することを確認します。
+func TestMockProviderEndToEnd(t *testing.T) {
+	registerTestMockFixtures(t)
+	c := newTestCube(t)
+	s, cubeDbFilePath, embeddingConfig, chatConfig := c.s, c.cubeDbFilePath, c.embeddingConfig, c.chatConfig
+	ctx := context.Background()
+
+	// Absorb
+	c.absorb(t, []string{c.writeFile(t, "doc.txt", "Alice works at Acme Corporation. She joined Acme Corporation in 2020 as an engineer.")}, "")
+	nodes, err := s.CypherQuery(ctx, cubeDbFilePath, testMockMemoryGroup, "MATCH (n:GraphNode) RETURN n.id", embeddingConfig)
+	if err != nil {
+		t.Fatalf("CypherQuery failed: %v", err)
+	}
+	if len(nodes.Rows) < 2 {
+		t.Errorf("Expected the extracted entities to be stored, got %d nodes", len(nodes.Rows))
+	}
+
+	// Query
+	answer, _, _, _, _, _, _, err := s.Query(ctx, eventbus.New(), cubeDbFilePath, testMockMemoryGroup, "Where does Alice work?",
+		types.QueryConfig{
+			QueryType:  types.QUERY_TYPE_ANSWER_BY_CHUNKS_AND_GRAPH_SUMMARY,
+			ChunkTopk:  3,
+			EntityTopk: 3,
+			FtsLayer:   types.FTS_LAYER_NOUNS_VERBS,
+			FtsTopk:    3,
+		}, embeddingConfig, chatConfig, nil, true)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if answer == nil || *answer != testMockAnswer {
+		t.Errorf("Unexpected answer: %v", answer)
+	}
+
+	// Memify
+	if _, err := s.Memify(ctx, eventbus.New(), cubeDbFilePath, testMockMemoryGroup, nil, embeddingConfig, chatConfig, nil, true); err != nil {
+		t.Fatalf("Memify failed: %v", err)
+	}
+	rules, err := s.CypherQuery(ctx, cubeDbFilePath, testMockMemoryGroup, "MATCH (n:GraphNode) WHERE n.type = 'Rule' RETURN n.id", embeddingConfig)
+	if err != nil {
+		t.Fatalf("CypherQuery failed: %v", err)
+	}
+	if len(rules.Rows) != 1 {
+		t.Errorf("Expected 1 rule from Memify, got %d", len(rules.Rows))
+	}
+}
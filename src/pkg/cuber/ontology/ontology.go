@@ -0,0 +1,343 @@
+// Package ontology は、メモリーグループごとのオントロジー（エンティティタイプ・関係タイプの定義）を提供します。
+//
+// オントロジーが定義されたメモリーグループでは、
+//   - グラフ抽出のプロンプトに、使用できるタイプの一覧が追加されます（PromptSection）
+//   - 抽出されたノード・エッジのタイプが同義語から正式名に正規化され、定義に反するものは除外されます（Apply）
+//   - Stage 1 の矛盾解決で、排他的な関係（cardinality=one）の判定に使用されます（IsExclusive）
+//
+// オントロジーが定義されていない場合（*Ontology が nil の場合）は、従来どおり LLM が自由にタイプを決め、
+// 排他的な関係は utils.ExclusiveRelationType で判定されます。
+package ontology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// Cardinality は、関係の多重度です。
+type Cardinality string
+
+const (
+	CARDINALITY_ONE  Cardinality = "one"  // ソースごとに有効な値は1つ（排他的な関係。新しい値が古い値を置き換える）
+	CARDINALITY_MANY Cardinality = "many" // ソースごとに複数の値を持てる
+)
+
+const (
+	MAX_ENTITY_TYPES   = 100 // 定義できるエンティティタイプの最大数
+	MAX_RELATION_TYPES = 200 // 定義できる関係タイプの最大数
+	MAX_SYNONYMS       = 20  // タイプごとに定義できる同義語の最大数
+)
+
+// EntityType は、エンティティ（ノード）のタイプの定義です。
+type EntityType struct {
+	Name        string   `json:"name"`                  // 正式名（例: "person"）
+	Description string   `json:"description,omitempty"` // 説明（抽出プロンプトに含まれる）
+	Synonyms    []string `json:"synonyms,omitempty"`    // 同義語（例: "people", "人物"）。抽出結果は正式名に置き換えられる
+}
+
+// RelationType は、関係（エッジ）のタイプの定義です。
+type RelationType struct {
+	Name        string      `json:"name"`                  // 正式名（例: "works_at"）
+	Description string      `json:"description,omitempty"` // 説明（抽出プロンプトに含まれる）
+	Domain      []string    `json:"domain,omitempty"`      // ソースに使用できるエンティティタイプ（空の場合は制限しない）
+	Range       []string    `json:"range,omitempty"`       // ターゲットに使用できるエンティティタイプ（空の場合は制限しない）
+	Cardinality Cardinality `json:"cardinality,omitempty"` // 多重度（省略時は many）
+	Synonyms    []string    `json:"synonyms,omitempty"`    // 同義語（例: "employed_by", "勤務先"）。抽出結果は正式名に置き換えられる
+}
+
+// Ontology は、メモリーグループのオントロジーです。
+// Parse で作成したものだけが使用できます（同義語の索引を構築するため）。
+type Ontology struct {
+	EntityTypes   []EntityType   `json:"entity_types"`
+	RelationTypes []RelationType `json:"relation_types"`
+	// Strict が true の場合は、定義にないタイプのノード・エッジを除外します。
+	// false の場合は、定義にないタイプもそのまま保存します（同義語の正規化と domain/range の検証のみ行う）。
+	Strict bool `json:"strict"`
+
+	entityIndex   map[string]string        // 正規化した名前・同義語 → エンティティタイプの正式名
+	relationIndex map[string]*RelationType // 正規化した名前・同義語 → 関係タイプ
+}
+
+// Parse は、JSON のオントロジー定義を検証し、Ontology を作成します。
+func Parse(definition string) (*Ontology, error) {
+	o := &Ontology{}
+	if err := json.Unmarshal([]byte(definition), o); err != nil {
+		return nil, fmt.Errorf("Failed to parse ontology: %w", err)
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate は、オントロジーを検証し、タイプ名・同義語を正規化して索引を構築します。
+// 正式名は utils.NormalizeForGraph で正規化されます（抽出されたタイプと同じ正規化）。
+func (o *Ontology) Validate() error {
+	if len(o.EntityTypes) == 0 && len(o.RelationTypes) == 0 {
+		return fmt.Errorf("Ontology must define at least one entity type or relation type")
+	}
+	if len(o.EntityTypes) > MAX_ENTITY_TYPES {
+		return fmt.Errorf("Up to %d entity types can be defined", MAX_ENTITY_TYPES)
+	}
+	if len(o.RelationTypes) > MAX_RELATION_TYPES {
+		return fmt.Errorf("Up to %d relation types can be defined", MAX_RELATION_TYPES)
+	}
+	o.entityIndex = map[string]string{}
+	names := map[string]bool{}
+	for i := range o.EntityTypes {
+		et := &o.EntityTypes[i]
+		et.Name = utils.NormalizeForGraph(et.Name)
+		if et.Name == "" {
+			return fmt.Errorf("Entity type name must not be empty (entity_types[%d])", i)
+		}
+		if names[et.Name] {
+			return fmt.Errorf("Entity type '%s' is defined more than once", et.Name)
+		}
+		names[et.Name] = true
+		if len(et.Synonyms) > MAX_SYNONYMS {
+			return fmt.Errorf("Up to %d synonyms can be defined for entity type '%s'", MAX_SYNONYMS, et.Name)
+		}
+		for _, key := range append([]string{et.Name}, et.Synonyms...) {
+			key = utils.NormalizeForGraph(key)
+			if key == "" {
+				continue
+			}
+			if existing, ok := o.entityIndex[key]; ok && existing != et.Name {
+				return fmt.Errorf("Entity type name or synonym '%s' is defined more than once ('%s' and '%s')", key, existing, et.Name)
+			}
+			o.entityIndex[key] = et.Name
+		}
+	}
+	o.relationIndex = map[string]*RelationType{}
+	for i := range o.RelationTypes {
+		rt := &o.RelationTypes[i]
+		rt.Name = utils.NormalizeForGraph(rt.Name)
+		if rt.Name == "" {
+			return fmt.Errorf("Relation type name must not be empty (relation_types[%d])", i)
+		}
+		switch rt.Cardinality {
+		case "":
+			rt.Cardinality = CARDINALITY_MANY
+		case CARDINALITY_ONE, CARDINALITY_MANY:
+		default:
+			return fmt.Errorf("Cardinality of relation type '%s' must be '%s' or '%s'", rt.Name, CARDINALITY_ONE, CARDINALITY_MANY)
+		}
+		if len(rt.Synonyms) > MAX_SYNONYMS {
+			return fmt.Errorf("Up to %d synonyms can be defined for relation type '%s'", MAX_SYNONYMS, rt.Name)
+		}
+		for _, refs := range []*[]string{&rt.Domain, &rt.Range} {
+			for j, t := range *refs {
+				name, ok := o.entityIndex[utils.NormalizeForGraph(t)]
+				if !ok {
+					return fmt.Errorf("Relation type '%s' refers to undefined entity type '%s'", rt.Name, t)
+				}
+				(*refs)[j] = name
+			}
+		}
+		for _, key := range append([]string{rt.Name}, rt.Synonyms...) {
+			key = utils.NormalizeForGraph(key)
+			if key == "" {
+				continue
+			}
+			if existing, ok := o.relationIndex[key]; ok {
+				if existing == rt {
+					continue
+				}
+				return fmt.Errorf("Relation type name or synonym '%s' is defined more than once ('%s' and '%s')", key, existing.Name, rt.Name)
+			}
+			o.relationIndex[key] = rt
+		}
+	}
+	return nil
+}
+
+// String は、オントロジーを JSON で返します（ストレージへの保存に使用）。
+func (o *Ontology) String() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+// IsExclusive は、関係タイプが排他的（ソースごとに有効な値が1つ）かどうかを返します。
+// オントロジーが定義されている場合は cardinality で判定し、定義されていない場合（nil）は utils.ExclusiveRelationType で判定します。
+func (o *Ontology) IsExclusive(relationType string) bool {
+	if o == nil {
+		return utils.ExclusiveRelationType[relationType]
+	}
+	rt := o.relationIndex[utils.NormalizeForGraph(relationType)]
+	return rt != nil && rt.Cardinality == CARDINALITY_ONE
+}
+
+// Report は、Apply でオントロジーを適用した結果です。
+type Report struct {
+	NormalizedNodes int // タイプを同義語から正式名に置き換えたノード数
+	NormalizedEdges int // タイプを同義語から正式名に置き換えたエッジ数
+	RejectedNodes   int // 定義にないタイプのため除外したノード数（Strict の場合のみ）
+	RejectedEdges   int // 定義にないタイプ、または domain/range に反するため除外したエッジ数
+}
+
+// Apply は、抽出されたノード・エッジにオントロジーを適用します。
+// ノード・エッジのタイプは utils.NormalizeForGraph で正規化済みである必要があります。
+//
+// 処理内容:
+//  1. ノードのタイプを正式名に置き換える（Strict の場合、定義にないタイプのノードを除外する）
+//  2. エッジのタイプを正式名に置き換える（Strict の場合、定義にないタイプのエッジを除外する）
+//  3. domain/range が定義された関係について、ソース・ターゲットのタイプが一致しないエッジを除外する
+//  4. 除外したノードに接続するエッジを除外する
+//
+// ソース・ターゲットのノードが抽出結果に含まれない場合、domain/range は検証しません。
+func (o *Ontology) Apply(nodes []*storage.Node, edges []*storage.Edge) ([]*storage.Node, []*storage.Edge, Report) {
+	var report Report
+	if o == nil {
+		return nodes, edges, report
+	}
+	nodeTypes := make(map[string]string, len(nodes)) // ノードID → タイプ（正式名）
+	rejectedNodes := map[string]bool{}
+	keptNodes := make([]*storage.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if name, ok := o.entityIndex[node.Type]; ok {
+			if name != node.Type {
+				node.Type = name
+				report.NormalizedNodes++
+			}
+		} else if o.Strict && len(o.EntityTypes) > 0 {
+			rejectedNodes[node.ID] = true
+			report.RejectedNodes++
+			continue
+		}
+		nodeTypes[node.ID] = node.Type
+		keptNodes = append(keptNodes, node)
+	}
+	keptEdges := make([]*storage.Edge, 0, len(edges))
+	for _, edge := range edges {
+		if rejectedNodes[edge.SourceID] || rejectedNodes[edge.TargetID] {
+			report.RejectedEdges++
+			continue
+		}
+		rt := o.relationIndex[edge.Type]
+		if rt == nil {
+			if o.Strict && len(o.RelationTypes) > 0 {
+				report.RejectedEdges++
+				continue
+			}
+			keptEdges = append(keptEdges, edge)
+			continue
+		}
+		if rt.Name != edge.Type {
+			edge.Type = rt.Name
+			report.NormalizedEdges++
+		}
+		if !allows(rt.Domain, nodeTypes, edge.SourceID) || !allows(rt.Range, nodeTypes, edge.TargetID) {
+			report.RejectedEdges++
+			continue
+		}
+		keptEdges = append(keptEdges, edge)
+	}
+	return keptNodes, keptEdges, report
+}
+
+// allows は、ノードのタイプが許可されたタイプに含まれるかどうかを返します。
+// 許可されたタイプが空の場合、またはノードのタイプが不明な場合は true を返します。
+func allows(allowed []string, nodeTypes map[string]string, nodeID string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	t, ok := nodeTypes[nodeID]
+	if !ok {
+		return true
+	}
+	for _, a := range allowed {
+		if a == t {
+			return true
+		}
+	}
+	return false
+}
+
+// PromptSection は、グラフ抽出のプロンプトに追加するオントロジーの説明を返します。
+// オントロジーが定義されていない場合（nil）は空文字を返します。
+func (o *Ontology) PromptSection(isEn bool) string {
+	if o == nil {
+		return ""
+	}
+	var sb strings.Builder
+	if isEn {
+		sb.WriteString("\n\n## Ontology\nUse ONLY the following types for node `type` and edge `type`. Use the exact names below (not the synonyms).\n")
+		if !o.Strict {
+			sb.WriteString("If no type fits, you may use a new type, but prefer the defined types.\n")
+		}
+	} else {
+		sb.WriteString("\n\n## オントロジー\nノードの `type` とエッジの `type` には、以下のタイプのみを使用してください。同義語ではなく、以下の名前をそのまま使用してください。\n")
+		if !o.Strict {
+			sb.WriteString("どのタイプにも当てはまらない場合は新しいタイプを使用できますが、定義されたタイプを優先してください。\n")
+		}
+	}
+	if len(o.EntityTypes) > 0 {
+		if isEn {
+			sb.WriteString("\n### Entity types\n")
+		} else {
+			sb.WriteString("\n### エンティティタイプ\n")
+		}
+		for _, et := range o.EntityTypes {
+			fmt.Fprintf(&sb, "- %s", et.Name)
+			if et.Description != "" {
+				fmt.Fprintf(&sb, ": %s", et.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(o.RelationTypes) > 0 {
+		if isEn {
+			sb.WriteString("\n### Relation types\n")
+		} else {
+			sb.WriteString("\n### 関係タイプ\n")
+		}
+		for _, rt := range o.RelationTypes {
+			fmt.Fprintf(&sb, "- %s", rt.Name)
+			if len(rt.Domain) > 0 || len(rt.Range) > 0 {
+				fmt.Fprintf(&sb, " (%s -> %s)", typesOrAny(rt.Domain), typesOrAny(rt.Range))
+			}
+			if rt.Cardinality == CARDINALITY_ONE {
+				if isEn {
+					sb.WriteString(" [one value per source]")
+				} else {
+					sb.WriteString(" [ソースごとに1つ]")
+				}
+			}
+			if rt.Description != "" {
+				fmt.Fprintf(&sb, ": %s", rt.Description)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func typesOrAny(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
+
+// ========================================
+// コンテキスト
+// ========================================
+
+type ontologyKey struct{}
+
+// WithOntology は、メモリーグループのオントロジーをコンテキストに設定します。
+// Absorb / Memify / Query の開始時に、対象のメモリーグループのオントロジーを読み込んで設定します。
+func WithOntology(ctx context.Context, o *Ontology) context.Context {
+	return context.WithValue(ctx, ontologyKey{}, o)
+}
+
+// FromContext は、コンテキストに設定されたオントロジーを返します。設定されていない場合は nil を返します。
+func FromContext(ctx context.Context) *Ontology {
+	o, _ := ctx.Value(ontologyKey{}).(*Ontology)
+	return o
+}
@@ -0,0 +1,243 @@
+package ontology
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+// testDefinition は、人物・組織と勤務先の関係を定義したオントロジーです。
+func testDefinition(strict bool) string {
+	def := `{
+		"entity_types": [
+			{"name": "Person", "description": "A human being", "synonyms": ["people", "人物"]},
+			{"name": "Organization", "synonyms": ["company", "会社"]}
+		],
+		"relation_types": [
+			{"name": "works_at", "domain": ["person"], "range": ["company"], "cardinality": "one", "synonyms": ["employed_by", "勤務先"]},
+			{"name": "knows", "description": "Personal acquaintance"}
+		],
+		"strict": false
+	}`
+	if strict {
+		def = strings.Replace(def, `"strict": false`, `"strict": true`, 1)
+	}
+	return def
+}
+
+func mustParse(t *testing.T, definition string) *Ontology {
+	t.Helper()
+	o, err := Parse(definition)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return o
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		wantErr    string
+	}{
+		{name: "valid", definition: testDefinition(true)},
+		{name: "relation types only", definition: `{"relation_types": [{"name": "knows"}]}`},
+		{name: "invalid json", definition: `{"entity_types": [`, wantErr: "Failed to parse ontology"},
+		{name: "empty", definition: `{}`, wantErr: "at least one"},
+		{name: "empty entity type name", definition: `{"entity_types": [{"name": " "}]}`, wantErr: "must not be empty"},
+		{name: "duplicate entity type", definition: `{"entity_types": [{"name": "Person"}, {"name": "person"}]}`, wantErr: "defined more than once"},
+		{name: "entity synonym used twice", definition: `{"entity_types": [{"name": "person", "synonyms": ["member"]}, {"name": "group", "synonyms": ["member"]}]}`, wantErr: "'member' is defined more than once"},
+		{name: "undefined domain", definition: `{"entity_types": [{"name": "person"}], "relation_types": [{"name": "works_at", "range": ["organization"]}]}`, wantErr: "undefined entity type 'organization'"},
+		{name: "invalid cardinality", definition: `{"relation_types": [{"name": "works_at", "cardinality": "single"}]}`, wantErr: "Cardinality"},
+		{name: "relation synonym used twice", definition: `{"relation_types": [{"name": "works_at", "synonyms": ["employed_by"]}, {"name": "employed_by"}]}`, wantErr: "'employed_by' is defined more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.definition)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseNormalizesNames(t *testing.T) {
+	o := mustParse(t, testDefinition(true))
+	if got := []string{o.EntityTypes[0].Name, o.EntityTypes[1].Name}; !slices.Equal(got, []string{"person", "organization"}) {
+		t.Errorf("entity type names = %v", got)
+	}
+	worksAt := o.RelationTypes[0]
+	// domain/range の同義語は正式名に置き換えられ、多重度の省略は many になる
+	if !slices.Equal(worksAt.Domain, []string{"person"}) || !slices.Equal(worksAt.Range, []string{"organization"}) {
+		t.Errorf("works_at domain = %v, range = %v", worksAt.Domain, worksAt.Range)
+	}
+	if o.RelationTypes[1].Cardinality != CARDINALITY_MANY {
+		t.Errorf("knows cardinality = %q, want %q", o.RelationTypes[1].Cardinality, CARDINALITY_MANY)
+	}
+	// 保存した JSON から同じオントロジーを復元できる
+	restored := mustParse(t, o.String())
+	if restored.String() != o.String() {
+		t.Errorf("restored ontology = %s, want %s", restored, o)
+	}
+}
+
+func TestApply(t *testing.T) {
+	newGraph := func() ([]*storage.Node, []*storage.Edge) {
+		nodes := []*storage.Node{
+			{ID: "alice", Type: "person"},
+			{ID: "acme", Type: "company"},
+			{ID: "mars", Type: "planet"},
+		}
+		edges := []*storage.Edge{
+			{SourceID: "alice", TargetID: "acme", Type: "employed_by"}, // 同義語
+			{SourceID: "acme", TargetID: "alice", Type: "works_at"},    // domain に反する
+			{SourceID: "alice", TargetID: "mars", Type: "knows"},       // 定義にないタイプのノードに接続
+			{SourceID: "alice", TargetID: "acme", Type: "likes"},       // 定義にない関係
+			{SourceID: "alice", TargetID: "bob", Type: "works_at"},     // ターゲットが抽出結果に含まれない
+		}
+		return nodes, edges
+	}
+	tests := []struct {
+		name       string
+		ontology   *Ontology
+		wantNodes  []string // ID:タイプ
+		wantEdges  []string // ソース-タイプ->ターゲット
+		wantReport Report
+	}{
+		{
+			name:       "no ontology",
+			wantNodes:  []string{"alice:person", "acme:company", "mars:planet"},
+			wantEdges:  []string{"alice-employed_by->acme", "acme-works_at->alice", "alice-knows->mars", "alice-likes->acme", "alice-works_at->bob"},
+			wantReport: Report{},
+		},
+		{
+			name:       "strict",
+			ontology:   mustParse(t, testDefinition(true)),
+			wantNodes:  []string{"alice:person", "acme:organization"},
+			wantEdges:  []string{"alice-works_at->acme", "alice-works_at->bob"},
+			wantReport: Report{NormalizedNodes: 1, NormalizedEdges: 1, RejectedNodes: 1, RejectedEdges: 3},
+		},
+		{
+			name:       "not strict",
+			ontology:   mustParse(t, testDefinition(false)),
+			wantNodes:  []string{"alice:person", "acme:organization", "mars:planet"},
+			wantEdges:  []string{"alice-works_at->acme", "alice-knows->mars", "alice-likes->acme", "alice-works_at->bob"},
+			wantReport: Report{NormalizedNodes: 1, NormalizedEdges: 1, RejectedEdges: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, edges := newGraph()
+			gotNodes, gotEdges, report := tt.ontology.Apply(nodes, edges)
+			var nodeKeys, edgeKeys []string
+			for _, n := range gotNodes {
+				nodeKeys = append(nodeKeys, n.ID+":"+n.Type)
+			}
+			for _, e := range gotEdges {
+				edgeKeys = append(edgeKeys, e.SourceID+"-"+e.Type+"->"+e.TargetID)
+			}
+			if !slices.Equal(nodeKeys, tt.wantNodes) {
+				t.Errorf("nodes = %v, want %v", nodeKeys, tt.wantNodes)
+			}
+			if !slices.Equal(edgeKeys, tt.wantEdges) {
+				t.Errorf("edges = %v, want %v", edgeKeys, tt.wantEdges)
+			}
+			if report != tt.wantReport {
+				t.Errorf("report = %+v, want %+v", report, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestIsExclusive(t *testing.T) {
+	o := mustParse(t, testDefinition(true))
+	tests := []struct {
+		name         string
+		ontology     *Ontology
+		relationType string
+		want         bool
+	}{
+		{"cardinality one", o, "works_at", true},
+		{"synonym of cardinality one", o, "勤務先", true},
+		{"cardinality many", o, "knows", false},
+		{"undefined relation", o, "lives_in", false},
+		{"no ontology uses the static list", nil, "works_at", true},
+		{"no ontology and not in the static list", nil, "knows", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ontology.IsExclusive(tt.relationType); got != tt.want {
+				t.Errorf("IsExclusive(%q) = %v, want %v", tt.relationType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptSection(t *testing.T) {
+	tests := []struct {
+		name        string
+		ontology    *Ontology
+		isEn        bool
+		wantContain []string
+		wantAbsent  []string
+	}{
+		{
+			name: "no ontology",
+		},
+		{
+			name:     "strict english",
+			ontology: mustParse(t, testDefinition(true)),
+			isEn:     true,
+			wantContain: []string{
+				"## Ontology",
+				"- person: A human being\n",
+				"- organization\n",
+				"- works_at (person -> organization) [one value per source]\n",
+				"- knows: Personal acquaintance\n",
+			},
+			wantAbsent: []string{"you may use a new type", "employed_by"},
+		},
+		{
+			name:        "not strict japanese",
+			ontology:    mustParse(t, testDefinition(false)),
+			wantContain: []string{"## オントロジー", "新しいタイプを使用できます", "### エンティティタイプ", "- works_at (person -> organization) [ソースごとに1つ]\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ontology.PromptSection(tt.isEn)
+			if tt.ontology == nil && got != "" {
+				t.Errorf("PromptSection() = %q, want empty", got)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("PromptSection() does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(got, absent) {
+					t.Errorf("PromptSection() contains %q:\n%s", absent, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	o := mustParse(t, testDefinition(true))
+	if got := FromContext(context.Background()); got != nil {
+		t.Errorf("FromContext() without ontology = %v, want nil", got)
+	}
+	if got := FromContext(WithOntology(context.Background(), o)); got != o {
+		t.Errorf("FromContext() = %v, want %v", got, o)
+	}
+}
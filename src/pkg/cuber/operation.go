@@ -0,0 +1,159 @@
+package cuber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// ErrOperationCancelled は、CancelOperation によりキャンセルされたオペレーションのコンテキストの Cause です。
+// 呼び出し元は context.Cause(ctx) でクライアントの切断などによるキャンセルと区別できます。
+var ErrOperationCancelled = errors.New("operation cancelled")
+
+// ErrOperationNotFound は、指定されたオペレーションが実行中でない場合に返されます。
+var ErrOperationNotFound = errors.New("operation not found")
+
+// ErrOperationAlreadyRunning は、同じオペレーションIDのオペレーションが既に実行中の場合に返されます。
+var ErrOperationAlreadyRunning = errors.New("operation already running")
+
+// Operation は、CuberService で実行中のオペレーション（Absorb / Memify / Query）です。
+type Operation struct {
+	ID          string           // オペレーションID
+	Type        types.ActionType // "absorb", "memify", "query"
+	CubeUUID    string           // 対象の Cube
+	MemoryGroup string           // 対象のメモリーグループ
+	Owner       string           // 呼び出し元が設定する所有者の識別子（一覧・キャンセルの権限確認に使用）
+	StartedAt   time.Time
+	Cancelled   bool // CancelOperation が呼ばれた場合は true
+	cancel      context.CancelCauseFunc
+}
+
+type operationIDKey struct{}
+
+// StartOperation は、オペレーションを operationID で登録し、CancelOperation でキャンセルできるコンテキストを返します。
+// Absorb / Memify / Query には、返されたコンテキストを渡します。
+// 呼び出し元はオペレーションの終了時に、必ず返された finish を呼び出してください（登録を解除し、コンテキストを解放します）。
+//
+// 引数:
+//   - ctx: 親のコンテキスト（リクエストのコンテキストなど）
+//   - operationID: オペレーションID（空の場合は UUID を生成）
+//   - opType: オペレーションの種類
+//   - cubeUUID: 対象の Cube の UUID
+//   - memoryGroup: メモリグループ名
+//   - owner: 所有者の識別子
+//
+// 返り値:
+//   - context.Context: オペレーションのコンテキスト
+//   - string: オペレーションID
+//   - func(): 終了時に呼び出す関数
+//   - error: 同じオペレーションIDが既に実行中の場合
+func (s *CuberService) StartOperation(ctx context.Context, operationID string, opType types.ActionType, cubeUUID string, memoryGroup string, owner string) (context.Context, string, func(), error) {
+	if operationID == "" {
+		operationID = uuid.New().String()
+	}
+	opCtx, cancel := context.WithCancelCause(ctx)
+	op := &Operation{
+		ID:          operationID,
+		Type:        opType,
+		CubeUUID:    cubeUUID,
+		MemoryGroup: memoryGroup,
+		Owner:       owner,
+		StartedAt:   time.Now(),
+		cancel:      cancel,
+	}
+	s.operationsMu.Lock()
+	if _, ok := s.operations[operationID]; ok {
+		s.operationsMu.Unlock()
+		cancel(nil)
+		return nil, "", nil, fmt.Errorf("StartOperation: Operation '%s' is already running: %w", operationID, ErrOperationAlreadyRunning)
+	}
+	s.operations[operationID] = op
+	s.operationsMu.Unlock()
+	finish := func() {
+		s.operationsMu.Lock()
+		if s.operations[operationID] == op {
+			delete(s.operations, operationID)
+		}
+		s.operationsMu.Unlock()
+		cancel(nil)
+	}
+	return context.WithValue(opCtx, operationIDKey{}, operationID), operationID, finish, nil
+}
+
+// GetOperation は、実行中のオペレーションを返します。実行中でない場合は false を返します。
+func (s *CuberService) GetOperation(operationID string) (Operation, bool) {
+	s.operationsMu.RLock()
+	defer s.operationsMu.RUnlock()
+	op, ok := s.operations[operationID]
+	if !ok {
+		return Operation{}, false
+	}
+	return *op, true
+}
+
+// ListOperations は、owner が所有する実行中のオペレーションを開始時刻の順に返します。
+func (s *CuberService) ListOperations(owner string) []Operation {
+	s.operationsMu.RLock()
+	ops := make([]Operation, 0, len(s.operations))
+	for _, op := range s.operations {
+		if op.Owner == owner {
+			ops = append(ops, *op)
+		}
+	}
+	s.operationsMu.RUnlock()
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].StartedAt.Before(ops[j].StartedAt)
+	})
+	return ops
+}
+
+// CancelOperation は、実行中のオペレーションをキャンセルします。
+// コンテキストは ErrOperationCancelled を Cause としてキャンセルされ、Absorb / Memify / Query は
+// 実行中のトランザクションをロールバックし、EVENT_INFO_OPERATION_CANCELLED を発火してから終了します。
+// このメソッドはオペレーションの終了を待ちません。
+//
+// 返り値:
+//   - Operation: キャンセルしたオペレーション
+//   - error: 実行中でない場合は ErrOperationNotFound
+func (s *CuberService) CancelOperation(operationID string) (Operation, error) {
+	s.operationsMu.Lock()
+	op, ok := s.operations[operationID]
+	if !ok {
+		s.operationsMu.Unlock()
+		return Operation{}, ErrOperationNotFound
+	}
+	op.Cancelled = true
+	cancelled := *op
+	s.operationsMu.Unlock()
+	op.cancel(ErrOperationCancelled)
+	utils.LogInfo(s.Logger, "CancelOperation: Cancelled operation", zap.String("id", operationID), zap.String("type", string(op.Type)), zap.String("cube", op.CubeUUID))
+	return cancelled, nil
+}
+
+// IsOperationCancelled は、ctx が CancelOperation によりキャンセルされたかどうかを返します。
+func IsOperationCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrOperationCancelled)
+}
+
+// emitIfOperationCancelled は、ctx が CancelOperation によりキャンセルされていた場合に EVENT_INFO_OPERATION_CANCELLED を発火します。
+// ストリームに確実に届けるため同期的に発火するので、Absorb / Memify / Query の終了前に呼び出します。
+func emitIfOperationCancelled(ctx context.Context, eb *eventbus.EventBus, opType types.ActionType, memoryGroup string) {
+	if !IsOperationCancelled(ctx) {
+		return
+	}
+	operationID, _ := ctx.Value(operationIDKey{}).(string)
+	eventbus.EmitSync(eb, string(event.EVENT_INFO_OPERATION_CANCELLED), event.InfoOperationCancelledPayload{
+		BasePayload:   event.NewBasePayload(memoryGroup),
+		OperationID:   operationID,
+		OperationType: string(opType),
+	})
+}
@@ -0,0 +1,200 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ========================================
+// プロンプトの上書き（メモリーグループごと）
+// ========================================
+//
+// prompts.go の定数はデフォルトのプロンプトです。Cube のメモリーグループごとに上書きを保存でき、
+// 各タスクは Get でコンテキストに設定された上書き（Resolver）を参照してプロンプトを取得します。
+// 上書きがない場合は、デフォルトのプロンプトを返します。
+
+// Name は、上書きできるプロンプトの名前です（定数名と同じ。イベントの PromptName にも使用される）。
+type Name string
+
+const (
+	// Absorb
+	NAME_GENERATE_GRAPH_EN     Name = "GENERATE_GRAPH_EN_PROMPT"
+	NAME_GENERATE_GRAPH_JA     Name = "GENERATE_GRAPH_JA_PROMPT"
+	NAME_SUMMARIZE_CONTENT_EN  Name = "SUMMARIZE_CONTENT_EN_PROMPT"
+	NAME_SUMMARIZE_CONTENT_JA  Name = "SUMMARIZE_CONTENT_JA_PROMPT"
+	NAME_ARBITRATE_CONFLICT_EN Name = "ARBITRATE_CONFLICT_SYSTEM_EN_PROMPT"
+	NAME_ARBITRATE_CONFLICT_JA Name = "ARBITRATE_CONFLICT_SYSTEM_JA_PROMPT"
+	NAME_ARBITRATE_CONFLICT    Name = "ARBITRATE_CONFLICT_USER_PROMPT"
+	// Memify
+	NAME_RULE_EXTRACTION_EN        Name = "RuleExtractionSystemPromptEN"
+	NAME_RULE_EXTRACTION_JA        Name = "RuleExtractionSystemPromptJA"
+	NAME_RULE_EXTRACTION_USER      Name = "RuleExtractionUserPromptTemplate"
+	NAME_QUESTION_GENERATION_EN    Name = "QuestionGenerationSystemPromptEN"
+	NAME_QUESTION_GENERATION_JA    Name = "QuestionGenerationSystemPromptJA"
+	NAME_ANSWER_SIMPLE_QUESTION    Name = "AnswerSimpleQuestionPrompt"
+	NAME_KNOWLEDGE_CRYSTALLIZATION Name = "KnowledgeCrystallizationSystemPrompt"
+	NAME_EDGE_EVALUATION           Name = "EdgeEvaluationSystemPrompt"
+	// Query
+	NAME_SUMMARIZE_GRAPH_ITSELF_EN                Name = "SUMMARIZE_GRAPH_ITSELF_EN_PROMPT"
+	NAME_SUMMARIZE_GRAPH_ITSELF_JA                Name = "SUMMARIZE_GRAPH_ITSELF_JA_PROMPT"
+	NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN Name = "SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN_PROMPT"
+	NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA Name = "SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA_PROMPT"
+	NAME_ANSWER_QUERY_WITH_HYBRID_RAG_EN          Name = "ANSWER_QUERY_WITH_HYBRID_RAG_EN_PROMPT"
+	NAME_ANSWER_QUERY_WITH_HYBRID_RAG_JA          Name = "ANSWER_QUERY_WITH_HYBRID_RAG_JA_PROMPT"
+	NAME_COT_CRITIQUE                             Name = "COT_CRITIQUE_PROMPT"
+	NAME_GENERATE_CYPHER                          Name = "GENERATE_CYPHER_PROMPT"
+	NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_EN       Name = "ANSWER_QUERY_WITH_CYPHER_RESULT_EN_PROMPT"
+	NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_JA       Name = "ANSWER_QUERY_WITH_CYPHER_RESULT_JA_PROMPT"
+	NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN    Name = "ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN_PROMPT"
+	NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA    Name = "ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA_PROMPT"
+	NAME_GLOBAL_QUERY_MAP                         Name = "GLOBAL_QUERY_MAP_PROMPT"
+	NAME_GLOBAL_QUERY_REDUCE_EN                   Name = "GLOBAL_QUERY_REDUCE_EN_PROMPT"
+	NAME_GLOBAL_QUERY_REDUCE_JA                   Name = "GLOBAL_QUERY_REDUCE_JA_PROMPT"
+	// Community
+	NAME_COMMUNITY_SUMMARY_EN Name = "COMMUNITY_SUMMARY_EN_PROMPT"
+	NAME_COMMUNITY_SUMMARY_JA Name = "COMMUNITY_SUMMARY_JA_PROMPT"
+	// Path
+	NAME_PATH_NARRATION_EN Name = "PATH_NARRATION_EN_PROMPT"
+	NAME_PATH_NARRATION_JA Name = "PATH_NARRATION_JA_PROMPT"
+)
+
+// defaults は、上書きできるプロンプトのデフォルトです。
+var defaults = map[Name]string{
+	NAME_GENERATE_GRAPH_EN:                        GENERATE_GRAPH_EN_PROMPT,
+	NAME_GENERATE_GRAPH_JA:                        GENERATE_GRAPH_JA_PROMPT,
+	NAME_SUMMARIZE_CONTENT_EN:                     SUMMARIZE_CONTENT_EN_PROMPT,
+	NAME_SUMMARIZE_CONTENT_JA:                     SUMMARIZE_CONTENT_JA_PROMPT,
+	NAME_ARBITRATE_CONFLICT_EN:                    ARBITRATE_CONFLICT_SYSTEM_EN_PROMPT,
+	NAME_ARBITRATE_CONFLICT_JA:                    ARBITRATE_CONFLICT_SYSTEM_JA_PROMPT,
+	NAME_ARBITRATE_CONFLICT:                       ARBITRATE_CONFLICT_USER_PROMPT,
+	NAME_RULE_EXTRACTION_EN:                       RuleExtractionSystemPromptEN,
+	NAME_RULE_EXTRACTION_JA:                       RuleExtractionSystemPromptJA,
+	NAME_RULE_EXTRACTION_USER:                     RuleExtractionUserPromptTemplate,
+	NAME_QUESTION_GENERATION_EN:                   QuestionGenerationSystemPromptEN,
+	NAME_QUESTION_GENERATION_JA:                   QuestionGenerationSystemPromptJA,
+	NAME_ANSWER_SIMPLE_QUESTION:                   AnswerSimpleQuestionPrompt,
+	NAME_KNOWLEDGE_CRYSTALLIZATION:                KnowledgeCrystallizationSystemPrompt,
+	NAME_EDGE_EVALUATION:                          EdgeEvaluationSystemPrompt,
+	NAME_SUMMARIZE_GRAPH_ITSELF_EN:                SUMMARIZE_GRAPH_ITSELF_EN_PROMPT,
+	NAME_SUMMARIZE_GRAPH_ITSELF_JA:                SUMMARIZE_GRAPH_ITSELF_JA_PROMPT,
+	NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN: SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN_PROMPT,
+	NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA: SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA_PROMPT,
+	NAME_ANSWER_QUERY_WITH_HYBRID_RAG_EN:          ANSWER_QUERY_WITH_HYBRID_RAG_EN_PROMPT,
+	NAME_ANSWER_QUERY_WITH_HYBRID_RAG_JA:          ANSWER_QUERY_WITH_HYBRID_RAG_JA_PROMPT,
+	NAME_COT_CRITIQUE:                             COT_CRITIQUE_PROMPT,
+	NAME_GENERATE_CYPHER:                          GENERATE_CYPHER_PROMPT,
+	NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_EN:       ANSWER_QUERY_WITH_CYPHER_RESULT_EN_PROMPT,
+	NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_JA:       ANSWER_QUERY_WITH_CYPHER_RESULT_JA_PROMPT,
+	NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN:    ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN_PROMPT,
+	NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA:    ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA_PROMPT,
+	NAME_GLOBAL_QUERY_MAP:                         GLOBAL_QUERY_MAP_PROMPT,
+	NAME_GLOBAL_QUERY_REDUCE_EN:                   GLOBAL_QUERY_REDUCE_EN_PROMPT,
+	NAME_GLOBAL_QUERY_REDUCE_JA:                   GLOBAL_QUERY_REDUCE_JA_PROMPT,
+	NAME_COMMUNITY_SUMMARY_EN:                     COMMUNITY_SUMMARY_EN_PROMPT,
+	NAME_COMMUNITY_SUMMARY_JA:                     COMMUNITY_SUMMARY_JA_PROMPT,
+	NAME_PATH_NARRATION_EN:                        PATH_NARRATION_EN_PROMPT,
+	NAME_PATH_NARRATION_JA:                        PATH_NARRATION_JA_PROMPT,
+}
+
+// Names は、上書きできるプロンプトの名前を名前順に返します。
+func Names() []Name {
+	names := make([]Name, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Default は、プロンプトのデフォルトを返します。上書きできないプロンプトの場合は false を返します。
+func Default(name Name) (string, bool) {
+	p, ok := defaults[name]
+	return p, ok
+}
+
+// verbRe は、fmt の書式指定子（%% を除く）に一致します。
+var verbRe = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]*)?[a-zA-Z%]`)
+
+// Placeholders は、プロンプトに含まれる fmt の書式指定子を出現順に返します。
+func Placeholders(prompt string) []string {
+	var verbs []string
+	for _, v := range verbRe.FindAllString(prompt, -1) {
+		if v != "%%" {
+			verbs = append(verbs, v)
+		}
+	}
+	return verbs
+}
+
+// ValidateOverride は、プロンプトの上書きを検証します。
+// デフォルトのプロンプトが fmt.Sprintf のテンプレートの場合（例: %s）、上書きにも同じ書式指定子が同じ順で含まれている必要があります。
+// それ以外の文字として % を使用する場合は %% と記述します。
+func ValidateOverride(name Name, content string) error {
+	def, ok := defaults[name]
+	if !ok {
+		return fmt.Errorf("Unknown prompt name: %s", name)
+	}
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("Prompt %s must not be empty", name)
+	}
+	want := Placeholders(def)
+	if len(want) == 0 {
+		return nil
+	}
+	got := Placeholders(content)
+	if !slices.Equal(want, got) {
+		return fmt.Errorf("Prompt %s must contain the placeholders %s in this order (found %s). Use %%%% for a literal %%", name, formatVerbs(want), formatVerbs(got))
+	}
+	return nil
+}
+
+func formatVerbs(verbs []string) string {
+	if len(verbs) == 0 {
+		return "none"
+	}
+	return strings.Join(verbs, ", ")
+}
+
+// ========================================
+// Resolver
+// ========================================
+
+// Resolver は、メモリーグループのプロンプトの上書きを保持し、プロンプトを解決します。
+type Resolver struct {
+	overrides map[Name]string
+}
+
+// NewResolver は、新しい Resolver を作成します。
+// 引数:
+//   - overrides: プロンプトの名前と上書きの内容（有効なバージョンのみ）
+func NewResolver(overrides map[Name]string) *Resolver {
+	return &Resolver{overrides: overrides}
+}
+
+// Get は、上書きがあれば上書きを、なければデフォルトのプロンプトを返します。
+func (r *Resolver) Get(name Name) string {
+	if r != nil {
+		if p, ok := r.overrides[name]; ok && p != "" {
+			return p
+		}
+	}
+	return defaults[name]
+}
+
+type resolverKey struct{}
+
+// WithResolver は、プロンプトの Resolver をコンテキストに設定します。
+// Absorb / Memify / Query の開始時に、対象のメモリーグループの上書きを読み込んで設定します。
+func WithResolver(ctx context.Context, r *Resolver) context.Context {
+	return context.WithValue(ctx, resolverKey{}, r)
+}
+
+// Get は、コンテキストに設定された Resolver でプロンプトを解決します。
+// Resolver が設定されていない場合は、デフォルトのプロンプトを返します。
+func Get(ctx context.Context, name Name) string {
+	r, _ := ctx.Value(resolverKey{}).(*Resolver)
+	return r.Get(name)
+}
@@ -0,0 +1,102 @@
+package prompts
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   []string
+	}{
+		{"no placeholders", nil},
+		{"Question: %s\nAnswer: %s", []string{"%s", "%s"}},
+		{"up to %d queries, score %.2f, %-5v", []string{"%d", "%.2f", "%-5v"}},
+		{"100%% sure about %s", []string{"%s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prompt, func(t *testing.T) {
+			if got := Placeholders(tt.prompt); !slices.Equal(got, tt.want) {
+				t.Errorf("Placeholders(%q) = %q, want %q", tt.prompt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOverride(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  Name
+		content string
+		wantErr string
+	}{
+		{name: "prompt without placeholders", prompt: NAME_GENERATE_GRAPH_EN, content: "Extract legal entities and obligations."},
+		{name: "prompt without placeholders may contain percent", prompt: NAME_GENERATE_GRAPH_EN, content: "Keep 100% of the statute numbers."},
+		{name: "same placeholders", prompt: NAME_RULE_EXTRACTION_USER, content: "Existing rules:\n%s\n\nText:\n%s"},
+		{name: "literal percent", prompt: NAME_COT_CRITIQUE, content: "Return at most %d queries. Be 100%% strict."},
+		{name: "unknown name", prompt: Name("UNKNOWN_PROMPT"), content: "x", wantErr: "Unknown prompt name"},
+		{name: "empty", prompt: NAME_GENERATE_GRAPH_EN, content: " \n", wantErr: "must not be empty"},
+		{name: "missing placeholder", prompt: NAME_RULE_EXTRACTION_USER, content: "Text:\n%s", wantErr: "found %s"},
+		{name: "different verb", prompt: NAME_COT_CRITIQUE, content: "Return at most %s queries.", wantErr: "placeholders %d"},
+		{name: "unescaped percent", prompt: NAME_COT_CRITIQUE, content: "Return at most %d queries. Be 100% strict.", wantErr: "found %d, % s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOverride(tt.prompt, tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateOverride failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	override := "Extract contracting parties and their obligations."
+	tests := []struct {
+		name     string
+		resolver *Resolver // nil の場合はコンテキストに設定しない
+		prompt   Name
+		want     string
+	}{
+		{name: "no resolver", prompt: NAME_GENERATE_GRAPH_EN, want: GENERATE_GRAPH_EN_PROMPT},
+		{name: "overridden", resolver: NewResolver(map[Name]string{NAME_GENERATE_GRAPH_EN: override}), prompt: NAME_GENERATE_GRAPH_EN, want: override},
+		{name: "other prompt is not overridden", resolver: NewResolver(map[Name]string{NAME_GENERATE_GRAPH_EN: override}), prompt: NAME_GENERATE_GRAPH_JA, want: GENERATE_GRAPH_JA_PROMPT},
+		{name: "empty override", resolver: NewResolver(map[Name]string{NAME_GENERATE_GRAPH_EN: ""}), prompt: NAME_GENERATE_GRAPH_EN, want: GENERATE_GRAPH_EN_PROMPT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.resolver != nil {
+				ctx = WithResolver(ctx, tt.resolver)
+			}
+			if got := Get(ctx, tt.prompt); got != tt.want {
+				t.Errorf("Get(%s) = %.40q..., want %.40q...", tt.prompt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamesHaveDefaults(t *testing.T) {
+	names := Names()
+	if !slices.IsSorted(names) {
+		t.Errorf("Names() is not sorted")
+	}
+	for _, name := range names {
+		if p, ok := Default(name); !ok || strings.TrimSpace(p) == "" {
+			t.Errorf("Prompt %s has no default", name)
+		}
+		// デフォルトのプロンプト自体が検証を通ること
+		if p, _ := Default(name); ValidateOverride(name, p) != nil {
+			t.Errorf("Default of %s does not pass validation", name)
+		}
+	}
+}
@@ -1143,3 +1143,306 @@ Respond ONLY in valid JSON format:
 
 // ARBITRATE_CONFLICT_USER_PROMPT は、矛盾情報をLLMに渡すためのユーザープロンプトです。
 const ARBITRATE_CONFLICT_USER_PROMPT = "Analyze the following conflicting edges. First internally identify which edges should be KEPT, then output ONLY the edges that should be DISCARDED:\n\n## Conflicting Edges\n```json\n%s\n```"
+
+// ========================================
+// Text-to-Cypher Prompts (QUERY_TYPE_NATURAL_LANGUAGE)
+// ========================================
+
+// GENERATE_CYPHER_PROMPT は、質問を Cube のグラフに対する読み取り専用の Cypher クエリに変換するためのプロンプトです。
+// スキーマは LadybugDBStorage.EnsureSchema で作成されるテーブル定義に対応しています（embedding カラムは除外）。
+// memory_group の条件は実行時に自動で付与されるため、LLM には指定させません。
+const GENERATE_CYPHER_PROMPT = `You are an expert in the Cypher query language (Kuzu dialect). Your task is to translate a user's question into a single READ-ONLY Cypher query over the graph database described below.
+
+## Graph Schema
+Node tables:
+- GraphNode(id STRING, type STRING, properties STRING)
+  Knowledge graph entities. ` + "`id`" + ` is the normalized entity name followed by the suffix "<::>" and a partition name (e.g. "tanaka taro<::>sales"). ` + "`type`" + ` is the normalized entity type (e.g. "person", "organization"). ` + "`properties`" + ` is a JSON string.
+- Data(id STRING, name STRING, extension STRING, mime_type STRING, content_hash STRING, source_id STRING, created_at TIMESTAMP)
+  Ingested source files.
+- Document(id STRING, data_id STRING, text STRING, metadata STRING)
+- Chunk(id STRING, document_id STRING, text STRING, token_count INT64, chunk_index INT64)
+- Summary(id STRING, text STRING)
+- Rule(id STRING, text STRING)
+- Unknown(id STRING, text STRING)
+- Capability(id STRING, text STRING)
+- EdgeProvenance(id STRING, source_id STRING, target_id STRING, type STRING, chunk_id STRING, document_id STRING)
+  Records which chunk each knowledge graph edge was extracted from.
+
+Relationship tables:
+- GraphEdge(FROM GraphNode TO GraphNode, type STRING, properties STRING, weight DOUBLE, confidence DOUBLE, unix INT64)
+  Knowledge graph relationships. ` + "`type`" + ` is the normalized relation name (e.g. "works_for", "located_in"). ` + "`unix`" + ` is the time the fact was recorded (milliseconds).
+- HAS_DOCUMENT(FROM Data TO Document)
+- HAS_CHUNK(FROM Document TO Chunk)
+
+## Rules
+- Output ONLY the Cypher query. No explanations, no markdown code fences.
+- The query MUST be read-only: use only MATCH, OPTIONAL MATCH, WHERE, WITH, UNWIND, RETURN, ORDER BY, SKIP, LIMIT.
+- NEVER use CREATE, MERGE, SET, DELETE, REMOVE, DROP, ALTER, COPY, LOAD or CALL. Write exactly one statement.
+- Always give every node pattern in MATCH a label (e.g. (n:GraphNode)).
+- NEVER use variable-length relationships (e.g. [:GraphEdge*1..3]); write each hop as a separate pattern instead.
+- NEVER filter on memory_group; partition scoping is applied automatically.
+- Names and types are normalized to lower case. Match entity names with lower-case string functions, e.g. WHERE n.id STARTS WITH 'tanaka' or WHERE n.id CONTAINS 'tanaka'. Do not compare ` + "`id`" + ` with "=" because of the partition suffix.
+- Relation types may be phrased differently from the question; prefer CONTAINS on e.type or return e.type so the answer can be interpreted.
+- Use aggregate functions (count, collect, sum, avg, min, max) for counting or statistical questions, and count(DISTINCT ...) to avoid duplicates.
+- Add LIMIT 100 unless the question requires an aggregate.
+- Return human-readable columns with aliases (e.g. RETURN b.id AS organization).
+
+## Retry
+If you are given a previous query and the error it caused, fix the query so that the error does not occur again.`
+
+// ANSWER_QUERY_WITH_CYPHER_RESULT_EN_PROMPT は、Cypher クエリの実行結果を用いて質問に回答するためのプロンプトです（英語出力）。
+const ANSWER_QUERY_WITH_CYPHER_RESULT_EN_PROMPT = `You are an AI assistant that answers user questions using the result of a database query.
+
+CONTEXT:
+You will receive the user's question, the Cypher query that was executed against a knowledge graph database to answer it, and the query result as JSON (column names and rows).
+
+IMPORTANT INSTRUCTIONS:
+- Base your answer strictly on the query result; do not add external knowledge
+- Use the Cypher query only to understand what the result represents
+- State counts and figures exactly as they appear in the result
+- If the result is empty or does not answer the question, say clearly that the information was not found
+- If the result was truncated, mention that the list may be incomplete
+- Do not mention Cypher, queries, or databases in the answer
+- Your final OUTPUT MUST BE IN ENGLISH
+- Write in natural, professional English`
+
+// ANSWER_QUERY_WITH_CYPHER_RESULT_JA_PROMPT は、Cypher クエリの実行結果を用いて質問に回答するためのプロンプトです（日本語出力）。
+const ANSWER_QUERY_WITH_CYPHER_RESULT_JA_PROMPT = `You are an AI assistant that answers user questions using the result of a database query.
+
+CONTEXT:
+You will receive the user's question, the Cypher query that was executed against a knowledge graph database to answer it, and the query result as JSON (column names and rows).
+
+IMPORTANT INSTRUCTIONS:
+- Base your answer strictly on the query result; do not add external knowledge
+- Use the Cypher query only to understand what the result represents
+- State counts and figures exactly as they appear in the result
+- If the result is empty or does not answer the question, say clearly that the information was not found
+- If the result was truncated, mention that the list may be incomplete
+- Do not mention Cypher, queries, or databases in the answer
+- Your final OUTPUT MUST BE IN JAPANESE
+- Write in natural, professional Japanese`
+
+// ========================================
+// Chain-of-Thought Retrieval Prompts (QUERY_TYPE_GRAPH_COMPLETION_COT)
+// ========================================
+
+// COT_CRITIQUE_PROMPT は、回答案を批評し、不足している情報を補うための追加検索クエリを生成するプロンプトです。
+// 出力は JSON で、sufficient が true の場合は追加検索を行わずに推論を終了します。
+const COT_CRITIQUE_PROMPT = `You are a critical reviewer in a multi-hop question answering system. The system answers a user's question using facts retrieved from a knowledge base, and you decide whether more retrieval is needed.
+
+CONTEXT:
+You will receive the user's question, the current draft answer, and the search queries that have already been executed.
+
+YOUR TASK:
+1. Check whether the draft answer fully and correctly answers every part of the question.
+2. Identify missing facts, unresolved intermediate entities (e.g. "the company X works for", "the city where Y is located"), and claims that are not supported.
+3. If something is missing, write short, self-contained search queries that would retrieve exactly the missing facts. Use concrete entity names found in the draft answer instead of pronouns.
+
+OUTPUT FORMAT:
+Output ONLY a JSON object with the following fields, without markdown code fences:
+{
+  "sufficient": true or false,
+  "critique": "one or two sentences describing what is missing, or why the answer is sufficient",
+  "follow_up_queries": ["query 1", "query 2"]
+}
+
+IMPORTANT INSTRUCTIONS:
+- Set "sufficient" to true and "follow_up_queries" to an empty array if the draft answer is complete
+- Write at most %d follow-up queries
+- Do not repeat queries that have already been executed
+- Write the critique and the queries in the same language as the user's question`
+
+// ========================================
+// Temporal Query Prompts (QUERY_TYPE_TEMPORAL)
+// ========================================
+
+// ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN_PROMPT は、観測日時付きの検索結果から、期間を踏まえて質問に回答するためのプロンプトです（英語出力）。
+const ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN_PROMPT = `You are an AI assistant that answers time-related questions about a knowledge base.
+
+CONTEXT:
+You will receive the following information:
+1. Time Window: the period the user is asking about. Only information observed within this period has been retrieved.
+2. Document Excerpts: text chunks, each labeled with the date its source document was ingested.
+3. Knowledge Graph Timeline: facts (subject - relation - object), each labeled with the date it was last observed, in chronological order.
+4. Superseded Relations: relations that can hold only one value at a time (e.g. works_at, lives_in, current_version) for which several values were observed. They are listed from oldest to newest, and the newest value is the one that replaced the others.
+
+YOUR TASK:
+Answer the user's question from the viewpoint of the time window. When the question asks what was true at some point, use the latest information observed up to that point. When it asks what changed, describe how the facts evolved in chronological order, including which values were replaced and when.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- Mention dates when they help the user understand when something was true or changed
+- Observation dates are when the information was recorded, not necessarily when the event happened; do not claim exact event dates unless the text states them
+- If no information was observed in the time window, say so clearly
+- Your final OUTPUT MUST BE IN ENGLISH
+- Write in natural, professional English
+- Do not mention the sources by name (e.g., "according to the knowledge graph...")
+- Focus only on information provided; do not add external knowledge`
+
+// ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA_PROMPT は、観測日時付きの検索結果から、期間を踏まえて質問に回答するためのプロンプトです（日本語出力）。
+const ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA_PROMPT = `You are an AI assistant that answers time-related questions about a knowledge base.
+
+CONTEXT:
+You will receive the following information:
+1. Time Window: the period the user is asking about. Only information observed within this period has been retrieved.
+2. Document Excerpts: text chunks, each labeled with the date its source document was ingested.
+3. Knowledge Graph Timeline: facts (subject - relation - object), each labeled with the date it was last observed, in chronological order.
+4. Superseded Relations: relations that can hold only one value at a time (e.g. works_at, lives_in, current_version) for which several values were observed. They are listed from oldest to newest, and the newest value is the one that replaced the others.
+
+YOUR TASK:
+Answer the user's question from the viewpoint of the time window. When the question asks what was true at some point, use the latest information observed up to that point. When it asks what changed, describe how the facts evolved in chronological order, including which values were replaced and when.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- Mention dates when they help the user understand when something was true or changed
+- Observation dates are when the information was recorded, not necessarily when the event happened; do not claim exact event dates unless the text states them
+- If no information was observed in the time window, say so clearly
+- Your final OUTPUT MUST BE IN JAPANESE
+- Write in natural, professional Japanese
+- Do not mention the sources by name (e.g., "according to the knowledge graph...")
+- Focus only on information provided; do not add external knowledge`
+
+// ========================================
+// Community Prompts (QUERY_TYPE_GLOBAL)
+// ========================================
+
+// COMMUNITY_SUMMARY_EN_PROMPT は、知識グラフのコミュニティ（密につながったエンティティの集まり）のタイトルと要約を生成するプロンプトです（英語出力）。
+// 出力は JSON で、グローバル検索の map で各コミュニティの情報源として使用されます。
+const COMMUNITY_SUMMARY_EN_PROMPT = `You are a knowledge graph analyst. Your task is to write a report about one community of a knowledge graph: a group of entities that are densely connected to each other.
+
+CONTEXT:
+You will receive the entities of the community, the most important facts (subject - relation - object) among them, and, for large communities, the reports of the smaller sub-communities it contains.
+
+YOUR TASK:
+1. Identify the main theme that ties the entities together.
+2. Write a short title that names the theme.
+3. Write a summary that describes the key entities, how they are related, and the most important facts, so that a reader can understand what this part of the knowledge is about without seeing the graph.
+
+OUTPUT FORMAT:
+Output ONLY a JSON object with the following fields, without markdown code fences:
+{
+  "title": "a short title (at most 10 words)",
+  "summary": "a summary of one or two paragraphs"
+}
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- The title and the summary MUST BE IN ENGLISH
+- Mention concrete entity names instead of vague descriptions
+- Focus only on information provided; do not add external knowledge`
+
+// COMMUNITY_SUMMARY_JA_PROMPT は、知識グラフのコミュニティ（密につながったエンティティの集まり）のタイトルと要約を生成するプロンプトです（日本語出力）。
+// 出力は JSON で、グローバル検索の map で各コミュニティの情報源として使用されます。
+const COMMUNITY_SUMMARY_JA_PROMPT = `You are a knowledge graph analyst. Your task is to write a report about one community of a knowledge graph: a group of entities that are densely connected to each other.
+
+CONTEXT:
+You will receive the entities of the community, the most important facts (subject - relation - object) among them, and, for large communities, the reports of the smaller sub-communities it contains.
+
+YOUR TASK:
+1. Identify the main theme that ties the entities together.
+2. Write a short title that names the theme.
+3. Write a summary that describes the key entities, how they are related, and the most important facts, so that a reader can understand what this part of the knowledge is about without seeing the graph.
+
+OUTPUT FORMAT:
+Output ONLY a JSON object with the following fields, without markdown code fences:
+{
+  "title": "a short title (at most 30 characters)",
+  "summary": "a summary of one or two paragraphs"
+}
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- The title and the summary MUST BE IN JAPANESE
+- Write in natural, professional Japanese
+- Mention concrete entity names instead of vague descriptions
+- Focus only on information provided; do not add external knowledge`
+
+// GLOBAL_QUERY_MAP_PROMPT は、グローバル検索の map で、コミュニティの要約の一部から質問に関連する要点を抽出するプロンプトです。
+// 出力は JSON で、要点ごとに質問への重要度（0〜100）を付けます。
+const GLOBAL_QUERY_MAP_PROMPT = `You are an analyst who answers broad questions about a large knowledge base. The knowledge base has been divided into communities of related entities, and each community has a report.
+
+CONTEXT:
+You will receive the user's question and a subset of the community reports.
+
+YOUR TASK:
+Extract the key points from these reports that help answer the question. Each key point should be a self-contained statement that combines information across reports where appropriate, and should be given an importance score from 0 to 100 that reflects how useful it is for answering the question.
+
+OUTPUT FORMAT:
+Output ONLY a JSON object with the following fields, without markdown code fences:
+{
+  "points": [
+    {"description": "a key point", "score": 80}
+  ]
+}
+
+IMPORTANT INSTRUCTIONS:
+- Think and write in English to maintain logical precision
+- Return an empty "points" array if the reports contain nothing relevant to the question
+- Do not make up information that is not in the reports
+- Write at most 10 key points`
+
+// GLOBAL_QUERY_REDUCE_EN_PROMPT は、グローバル検索の reduce で、map で抽出した要点から最終的な回答を生成するプロンプトです（英語出力）。
+const GLOBAL_QUERY_REDUCE_EN_PROMPT = `You are an AI assistant that answers broad questions about a large knowledge base, such as its main themes or overall trends.
+
+CONTEXT:
+You will receive the user's question and key points that analysts extracted from reports about different parts of the knowledge base. Each key point has an importance score from 0 to 100; higher scores are more important.
+
+YOUR TASK:
+Synthesize the key points into a comprehensive answer. Group related points into themes, prioritize high-scoring points, and describe the overall picture rather than listing the points one by one.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- If the key points do not contain enough information to answer the question, say so clearly
+- Your final OUTPUT MUST BE IN ENGLISH
+- Write in natural, professional English
+- Do not mention the key points, scores or reports (e.g., "according to the reports...")
+- Focus only on information provided; do not add external knowledge`
+
+// GLOBAL_QUERY_REDUCE_JA_PROMPT は、グローバル検索の reduce で、map で抽出した要点から最終的な回答を生成するプロンプトです（日本語出力）。
+const GLOBAL_QUERY_REDUCE_JA_PROMPT = `You are an AI assistant that answers broad questions about a large knowledge base, such as its main themes or overall trends.
+
+CONTEXT:
+You will receive the user's question and key points that analysts extracted from reports about different parts of the knowledge base. Each key point has an importance score from 0 to 100; higher scores are more important.
+
+YOUR TASK:
+Synthesize the key points into a comprehensive answer. Group related points into themes, prioritize high-scoring points, and describe the overall picture rather than listing the points one by one.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- If the key points do not contain enough information to answer the question, say so clearly
+- Your final OUTPUT MUST BE IN JAPANESE
+- Write in natural, professional Japanese
+- Do not mention the key points, scores or reports (e.g., "according to the reports...")
+- Focus only on information provided; do not add external knowledge`
+
+// PATH_NARRATION_EN_PROMPT は、2つのエンティティ間の経路（知識グラフ上のつながり）を説明する文章を生成するプロンプトです（英語出力）。
+const PATH_NARRATION_EN_PROMPT = `You are an AI assistant that explains how two entities in a knowledge graph are connected.
+
+CONTEXT:
+You will receive the names of two entities and one or more paths between them. Each path is a chain of relationships from the first entity to the second, described in natural language. Relationships may be followed in either direction. Paths are ordered from the shortest and strongest to the weakest.
+
+YOUR TASK:
+Explain how the first entity is connected to the second. Start with the most direct connection, then briefly mention other notable connections. Describe each connection as a story that follows the chain of relationships step by step.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- Your final OUTPUT MUST BE IN ENGLISH
+- Write in natural, professional English
+- Do not mention paths, hops, graphs or relationship types (e.g., "path 1 shows...")
+- Focus only on information provided; do not add external knowledge`
+
+// PATH_NARRATION_JA_PROMPT は、2つのエンティティ間の経路（知識グラフ上のつながり）を説明する文章を生成するプロンプトです（日本語出力）。
+const PATH_NARRATION_JA_PROMPT = `You are an AI assistant that explains how two entities in a knowledge graph are connected.
+
+CONTEXT:
+You will receive the names of two entities and one or more paths between them. Each path is a chain of relationships from the first entity to the second, described in natural language. Relationships may be followed in either direction. Paths are ordered from the shortest and strongest to the weakest.
+
+YOUR TASK:
+Explain how the first entity is connected to the second. Start with the most direct connection, then briefly mention other notable connections. Describe each connection as a story that follows the chain of relationships step by step.
+
+IMPORTANT INSTRUCTIONS:
+- Think and analyze in English to maintain logical precision
+- Your final OUTPUT MUST BE IN JAPANESE
+- Write in natural, professional Japanese
+- Do not mention paths, hops, graphs or relationship types (e.g., "path 1 shows...")
+- Focus only on information provided; do not add external knowledge`
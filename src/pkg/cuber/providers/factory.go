@@ -53,6 +53,7 @@ type ProviderConfig struct {
 	ModelName   string
 	MaxTokens   int      // 生成する最大トークン数 (0の場合はデフォルト値またはプロバイダーのデフォルトが使用される)
 	Temperature *float64 // Temperature (nilの場合はデフォルト値)
+	Dimension   uint     // 埋め込みの次元数（mock プロバイダーで使用）
 }
 
 // NewChatModel は指定された設定に基づいて Eino ChatModel を生成します。
@@ -227,6 +228,15 @@ func NewChatModel(ctx context.Context, cfg ProviderConfig) (model.ToolCallingCha
 			return nil, fmt.Errorf("Failed to create qwen chat model: %w", err)
 		}
 		return chatModel, nil
+	// ========================================================================
+	// 3. Mock (Offline, for tests)
+	// ========================================================================
+	case ProviderMock:
+		chatModel, err := newMockChatModel(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create mock chat model: %w", err)
+		}
+		return chatModel, nil
 	default:
 		return nil, fmt.Errorf("Unsupported chat provider type: %s", cfg.Type)
 	}
@@ -264,7 +274,16 @@ func NewEmbedder(ctx context.Context, cfg ProviderConfig) (embedding.Embedder, e
 		}
 		return emb, nil
 	// ========================================================================
-	// 3. Unsupported
+	// 3. Mock (Offline, for tests)
+	// ========================================================================
+	case ProviderMock:
+		emb, err := newMockEmbedder(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create mock embedder: %w", err)
+		}
+		return emb, nil
+	// ========================================================================
+	// 4. Unsupported
 	// ========================================================================
 	case ProviderAnthropic:
 		return nil, fmt.Errorf("Anthropic does not support embeddings via this factory")
@@ -273,6 +292,33 @@ func NewEmbedder(ctx context.Context, cfg ProviderConfig) (embedding.Embedder, e
 	}
 }
 
+// EmbeddingBatchLimits は、1回の埋め込みリクエストで送信できる入力の上限です。
+type EmbeddingBatchLimits struct {
+	MaxBatchSize   int // 1リクエストあたりの最大入力数
+	MaxBatchTokens int // 1リクエストあたりの最大トークン数（0の場合は入力数のみで制限する）
+}
+
+// GetEmbeddingBatchLimits は、プロバイダーの埋め込みリクエストの上限を返します。
+// 公開されている上限より少し小さい値にしています。不明なプロバイダーには控えめな値を返します。
+func GetEmbeddingBatchLimits(pType ProviderType) EmbeddingBatchLimits {
+	switch ProviderType(strings.ToLower(string(pType))) {
+	case ProviderOpenAI:
+		return EmbeddingBatchLimits{MaxBatchSize: 2048, MaxBatchTokens: 300000}
+	case ProviderGemini:
+		return EmbeddingBatchLimits{MaxBatchSize: 100, MaxBatchTokens: 0}
+	case ProviderMistral:
+		return EmbeddingBatchLimits{MaxBatchSize: 128, MaxBatchTokens: 16000}
+	case ProviderQwen:
+		return EmbeddingBatchLimits{MaxBatchSize: 10, MaxBatchTokens: 0}
+	case ProviderOllama:
+		return EmbeddingBatchLimits{MaxBatchSize: 256, MaxBatchTokens: 0}
+	case ProviderLlamaCpp:
+		return EmbeddingBatchLimits{MaxBatchSize: 16, MaxBatchTokens: 0}
+	default:
+		return EmbeddingBatchLimits{MaxBatchSize: 64, MaxBatchTokens: 0}
+	}
+}
+
 // IsValidProviderType checks if the given provider type is supported.
 func IsValidProviderType(pType ProviderType) bool {
 	switch pType {
@@ -0,0 +1,468 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// mock プロバイダーは、ネットワークに接続せずに決定的な結果を返すテスト用のプロバイダーです。
+// CI などの API キーがない環境で、CuberService の Absorb / Query / Memify をグラフ抽出の JSON のパースを含めて実行するために使用します。
+//
+//   - 埋め込み: テキストのトークンをハッシュ化して ProviderConfig.Dimension 次元のベクトルを作成します（同じテキストは常に同じベクトル、似たテキストは似たベクトルになります）。
+//   - チャット: RegisterMockChatFixtures でモデル名に登録した応答を、プロンプトのハッシュ（MockPromptKey / MockSystemPromptKey）をキーに返します。
+//     応答は呼び出し順に依存しないため、並行して呼び出しても結果は変わりません。
+//
+// mock プロバイダーは IsValidProviderType では有効としないため、REST API からチャットモデル・Cube に設定することはできません。
+
+// ProviderMock は、テスト用の mock プロバイダーです。
+const ProviderMock ProviderType = "mock"
+
+// MockChatFixtures は、mock プロバイダーのチャットモデルが返す応答です。
+// 応答は次の順に探します。
+//  1. Responses に入力全体のハッシュ（MockPromptKey）が一致する応答
+//  2. Upstream が設定されている場合は、Upstream のモデルを呼び出した応答（Responses に記録し、Save で保存できる）
+//  3. Responses にシステムプロンプトのハッシュ（MockSystemPromptKey）が一致する応答
+//  4. Default
+//
+// いずれもない場合は、プロンプトのハッシュを含むエラーを返します。
+// 3 は、チャンクごとにユーザープロンプトが変わるグラフ抽出などを、タスクの種類（システムプロンプト）ごとに1つの応答で賄うために使用します。
+type MockChatFixtures struct {
+	Responses map[string]*schema.Message // キー: MockPromptKey または MockSystemPromptKey
+	Default   *schema.Message            // 一致する応答がない場合に返す応答
+	Upstream  *ProviderConfig            // 一致する応答がない場合に呼び出して記録する実際のモデル（記録用。nil の場合は呼び出さない）
+
+	mu sync.Mutex
+}
+
+const mockDefaultFileName = "default.json"
+
+var (
+	mockChatFixtures   = map[string]*MockChatFixtures{}
+	mockChatFixturesMu sync.RWMutex
+)
+
+// RegisterMockChatFixtures は、mock プロバイダーのモデル名に応答を登録します。
+// 同じモデル名に登録済みの応答は置き換えます。fixtures に nil を指定すると登録を解除します。
+func RegisterMockChatFixtures(modelName string, fixtures *MockChatFixtures) {
+	mockChatFixturesMu.Lock()
+	defer mockChatFixturesMu.Unlock()
+	if fixtures == nil {
+		delete(mockChatFixtures, modelName)
+		return
+	}
+	mockChatFixtures[modelName] = fixtures
+}
+
+func getMockChatFixtures(modelName string) *MockChatFixtures {
+	mockChatFixturesMu.RLock()
+	defer mockChatFixturesMu.RUnlock()
+	return mockChatFixtures[modelName]
+}
+
+// LoadMockChatFixtures は、ディレクトリから応答を読み込みます。
+// <キー>.json を Responses、default.json を Default として読み込みます。
+// 各ファイルは schema.Message の JSON です。
+func LoadMockChatFixtures(dir string) (*MockChatFixtures, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("LoadMockChatFixtures: Failed to read %s: %w", dir, err)
+	}
+	fixtures := &MockChatFixtures{Responses: map[string]*schema.Message{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("LoadMockChatFixtures: Failed to read %s: %w", name, err)
+		}
+		switch name {
+		case mockDefaultFileName:
+			err = json.Unmarshal(data, &fixtures.Default)
+		default:
+			var msg schema.Message
+			err = json.Unmarshal(data, &msg)
+			fixtures.Responses[strings.TrimSuffix(name, ".json")] = &msg
+		}
+		if err != nil {
+			return nil, fmt.Errorf("LoadMockChatFixtures: Failed to parse %s: %w", name, err)
+		}
+	}
+	return fixtures, nil
+}
+
+// Save は、応答をディレクトリに保存します（LoadMockChatFixtures で読み込める形式）。
+// Upstream を設定して記録した応答をフィクスチャとして保存するために使用します。
+func (f *MockChatFixtures) Save(dir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("MockChatFixtures: Failed to create %s: %w", dir, err)
+	}
+	write := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+	}
+	for key, msg := range f.Responses {
+		if err := write(key+".json", msg); err != nil {
+			return fmt.Errorf("MockChatFixtures: Failed to save %s: %w", key, err)
+		}
+	}
+	if f.Default != nil {
+		if err := write(mockDefaultFileName, f.Default); err != nil {
+			return fmt.Errorf("MockChatFixtures: Failed to save default: %w", err)
+		}
+	}
+	return nil
+}
+
+// lookup は、一致する応答を返します。一致する応答がない場合は nil を返します。
+func (f *MockChatFixtures) lookup(key string) *schema.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Responses[key]
+}
+
+// record は、Upstream の応答を記録します。
+func (f *MockChatFixtures) record(key string, msg *schema.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Responses == nil {
+		f.Responses = map[string]*schema.Message{}
+	}
+	f.Responses[key] = msg
+}
+
+// fallback は、システムプロンプトが一致する応答（ない場合は Default）を返します。
+func (f *MockChatFixtures) fallback(systemKey string) *schema.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if msg := f.Responses[systemKey]; msg != nil {
+		return msg
+	}
+	return f.Default
+}
+
+// MockPromptKey は、チャットモデルへの入力（メッセージとツール）から応答を探すキーを作成します。
+func MockPromptKey(input []*schema.Message, tools []*schema.ToolInfo) string {
+	h := sha256.New()
+	for _, msg := range input {
+		if msg == nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", msg.Role, msg.Content)
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(h, "%s\x00%s\x00", tc.Function.Name, tc.Function.Arguments)
+		}
+	}
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(h, "tools\x00%s", strings.Join(names, "\x00"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MockSystemPromptKey は、システムプロンプトから応答を探すキーを作成します。
+// MockPromptKey と衝突しないように、異なる接頭辞でハッシュ化します。
+func MockSystemPromptKey(systemPrompt string) string {
+	sum := sha256.Sum256([]byte("system-prompt\x00" + systemPrompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// mockSystemPrompt は、入力の最初のシステムメッセージの内容を返します。
+func mockSystemPrompt(input []*schema.Message) string {
+	for _, msg := range input {
+		if msg != nil && msg.Role == schema.System {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// mockTokenCount は、テキストのトークン数を決定的に概算します（4文字で1トークン）。
+func mockTokenCount(texts ...string) int {
+	n := 0
+	for _, t := range texts {
+		n += utf8.RuneCountInString(t)
+	}
+	return (n + 3) / 4
+}
+
+// ========================================
+// Mock Chat Model
+// ========================================
+
+// MockChatModel は、登録された応答を返す mock プロバイダーのチャットモデルです。
+type MockChatModel struct {
+	modelName string
+	tools     []*schema.ToolInfo
+	upstream  model.ToolCallingChatModel
+}
+
+var _ model.ToolCallingChatModel = (*MockChatModel)(nil)
+
+func newMockChatModel(ctx context.Context, cfg ProviderConfig) (*MockChatModel, error) {
+	m := &MockChatModel{modelName: cfg.ModelName}
+	if f := getMockChatFixtures(cfg.ModelName); f != nil && f.Upstream != nil {
+		if ProviderType(strings.ToLower(string(f.Upstream.Type))) == ProviderMock {
+			return nil, errors.New("Mock chat model cannot use the mock provider as upstream")
+		}
+		upstream, err := NewChatModel(ctx, *f.Upstream)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create upstream chat model for mock: %w", err)
+		}
+		m.upstream = upstream
+	}
+	return m, nil
+}
+
+// GetType は、コンポーネントの種類を返します。
+func (m *MockChatModel) GetType() string {
+	return "Mock"
+}
+
+// IsCallbacksEnabled は、コールバックを自身で呼び出すことを示します。
+func (m *MockChatModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// Generate は、登録された応答を返します。
+func (m *MockChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (msg *schema.Message, err error) {
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input, Tools: m.tools})
+	defer func() {
+		if err != nil {
+			callbacks.OnError(ctx, err)
+		}
+	}()
+	msg, err = m.respond(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	callbacks.OnEnd(ctx, &model.CallbackOutput{Message: msg, TokenUsage: m.tokenUsage(input, msg)})
+	return msg, nil
+}
+
+// Stream は、登録された応答を1つのチャンクとして返します。
+func (m *MockChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Messages: input, Tools: m.tools})
+	msg, err := m.respond(ctx, input, opts...)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+	out := schema.StreamReaderFromArray([]*model.CallbackOutput{{Message: msg, TokenUsage: m.tokenUsage(input, msg)}})
+	_, out = callbacks.OnEndWithStreamOutput(ctx, out)
+	return schema.StreamReaderWithConvert(out, func(o *model.CallbackOutput) (*schema.Message, error) {
+		return o.Message, nil
+	}), nil
+}
+
+// WithTools は、ツールを設定した新しい MockChatModel を返します。ツールは応答を探すキーに含まれます。
+func (m *MockChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	withTools := &MockChatModel{modelName: m.modelName, tools: tools}
+	if m.upstream != nil {
+		upstream, err := m.upstream.WithTools(tools)
+		if err != nil {
+			return nil, err
+		}
+		withTools.upstream = upstream
+	}
+	return withTools, nil
+}
+
+// respond は、入力に対応する応答を探します。
+func (m *MockChatModel) respond(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	key := MockPromptKey(input, m.tools)
+	f := getMockChatFixtures(m.modelName)
+	if f == nil {
+		return nil, fmt.Errorf("No mock chat fixtures are registered for model '%s' (prompt key: %s)", m.modelName, key)
+	}
+	if msg := f.lookup(key); msg != nil {
+		return copyMockMessage(msg), nil
+	}
+	if m.upstream != nil {
+		// 記録時も概算したトークン使用量だけを集計するため、Upstream のコールバックは呼び出さない
+		upstreamCtx := callbacks.InitCallbacks(ctx, &callbacks.RunInfo{Name: "MockUpstream", Type: m.GetType(), Component: components.ComponentOfChatModel})
+		msg, err := m.upstream.Generate(upstreamCtx, input, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Upstream chat model failed to record prompt %s: %w", key, err)
+		}
+		f.record(key, msg)
+		return copyMockMessage(msg), nil
+	}
+	if msg := f.fallback(MockSystemPromptKey(mockSystemPrompt(input))); msg != nil {
+		return copyMockMessage(msg), nil
+	}
+	return nil, fmt.Errorf("No mock chat fixture for model '%s' matches prompt key %s", m.modelName, key)
+}
+
+// tokenUsage は、入力と応答のトークン数を概算します。
+func (m *MockChatModel) tokenUsage(input []*schema.Message, msg *schema.Message) *model.TokenUsage {
+	prompt := 0
+	for _, in := range input {
+		if in != nil {
+			prompt += mockTokenCount(in.Content)
+		}
+	}
+	completion := mockTokenCount(msg.Content)
+	return &model.TokenUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+// copyMockMessage は、呼び出し元が変更しても登録された応答に影響しないように、メッセージを複製します。
+func copyMockMessage(msg *schema.Message) *schema.Message {
+	c := *msg
+	c.ToolCalls = append([]schema.ToolCall(nil), msg.ToolCalls...)
+	if c.Role == "" {
+		c.Role = schema.Assistant
+	}
+	return &c
+}
+
+// ========================================
+// Mock Embedder
+// ========================================
+
+// MockEmbedder は、テキストのトークンをハッシュ化して決定的なベクトルを作成する mock プロバイダーの Embedder です。
+// 単語（日本語などスペースで区切らない文字は2文字ずつ）をハッシュ化して次元に割り当てるため、
+// 共通する語が多いテキストほどコサイン類似度が高くなります。作成するベクトルは L2 正規化されています。
+type MockEmbedder struct {
+	modelName string
+	dimension int
+}
+
+var _ embedding.Embedder = (*MockEmbedder)(nil)
+
+func newMockEmbedder(cfg ProviderConfig) (*MockEmbedder, error) {
+	if cfg.Dimension == 0 {
+		return nil, errors.New("Mock embedder requires a dimension")
+	}
+	return &MockEmbedder{modelName: cfg.ModelName, dimension: int(cfg.Dimension)}, nil
+}
+
+// GetType は、コンポーネントの種類を返します。
+func (e *MockEmbedder) GetType() string {
+	return "Mock"
+}
+
+// IsCallbacksEnabled は、コールバックを自身で呼び出すことを示します。
+func (e *MockEmbedder) IsCallbacksEnabled() bool {
+	return true
+}
+
+// EmbedStrings は、テキストをベクトル化します。
+func (e *MockEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	ctx = callbacks.OnStart(ctx, &embedding.CallbackInput{Texts: texts, Config: &embedding.Config{Model: e.modelName}})
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	tokens := mockTokenCount(texts...)
+	callbacks.OnEnd(ctx, &embedding.CallbackOutput{
+		Embeddings: vectors,
+		Config:     &embedding.Config{Model: e.modelName},
+		TokenUsage: &embedding.TokenUsage{PromptTokens: tokens, TotalTokens: tokens},
+	})
+	return vectors, nil
+}
+
+// embed は、1つのテキストをベクトル化します。
+func (e *MockEmbedder) embed(text string) []float64 {
+	vector := make([]float64, e.dimension)
+	features := mockFeatures(text)
+	if len(features) == 0 {
+		// 特徴がない（空白のみなど）テキストも、決定的な非ゼロベクトルにする
+		features = []string{"\x00" + text}
+	}
+	for _, feature := range features {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		idx := int(sum % uint64(e.dimension))
+		if sum&(1<<63) != 0 {
+			vector[idx] -= 1
+		} else {
+			vector[idx] += 1
+		}
+	}
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm == 0 {
+		// 特徴が打ち消し合った場合は、テキストのハッシュから1次元を選ぶ
+		sum := sha256.Sum256([]byte(text))
+		vector[int(binary.BigEndian.Uint64(sum[:8])%uint64(e.dimension))] = 1
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// mockFeatures は、テキストを小文字の単語に分割します。スペースで区切らない文字（日本語など）は2文字ずつに分割します。
+func mockFeatures(text string) []string {
+	var features []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			features = append(features, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			features = append(features, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				features = append(features, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return features
+}
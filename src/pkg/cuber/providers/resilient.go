@@ -0,0 +1,343 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen は、プロバイダーのサーキットブレーカーが開いているため呼び出さなかったことを表します。
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ========================================
+// Retry Policy
+// ========================================
+
+// RetryPolicy は、プロバイダーの呼び出しの再試行の方針です。
+type RetryPolicy struct {
+	MaxRetries     int           // 1回の呼び出しで、同じモデルを再試行する回数
+	InitialBackoff time.Duration // 最初の再試行までの待機時間（再試行のたびに2倍になる）
+	MaxBackoff     time.Duration // 待機時間の上限
+	RetryBudget    int           // ラッパー全体（1回のオペレーション）で再試行できる回数の上限（0以下の場合は無制限）
+}
+
+// backoff は、attempt 回目（0始まり）の再試行までの待機時間を返します。
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 0; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// retryBudget は、ラッパー全体で共有する再試行の残り回数です。
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining int
+	unlimited bool
+}
+
+func newRetryBudget(budget int) *retryBudget {
+	return &retryBudget{remaining: budget, unlimited: budget <= 0}
+}
+
+// take は、再試行を1回消費します。残りがない場合は false を返します。
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.unlimited {
+		return true
+	}
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// retryableStatusRe は、エラーメッセージに含まれる再試行の対象の HTTP ステータスコード（429、5xx）に一致します。
+// "512 tokens" のような数値に一致しないよう、"status code: 503"、"HTTP 502"、"Error 429,"（Gemini）のように
+// ステータスコードであることを示す語の直後にある場合のみ対象とします。
+var retryableStatusRe = regexp.MustCompile(`(^|[^a-z])((status( code)?|http(/[0-9.]+)?|code)[ :=]+|error )(429|5[0-9][0-9])([^0-9]|$)`)
+
+// IsRetryableError は、再試行（またはフォールバック）で解決する可能性があるエラーかどうかを判定します。
+// レート制限（429）、サーバーエラー（5xx）、タイムアウト、接続エラーを再試行の対象とします。
+// 各プロバイダーの SDK はステータスコードをエラーメッセージに含めるため、メッセージから判定します。
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	if retryableStatusRe.MatchString(msg) {
+		return true
+	}
+	for _, s := range []string{
+		"too many requests", "rate limit", "rate_limit", "resource_exhausted",
+		"internal server error", "bad gateway", "service unavailable", "gateway timeout", "overloaded",
+		"timeout", "timed out", "connection reset", "connection refused", "unexpected eof", "broken pipe",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// Circuit Breaker
+// ========================================
+
+// CircuitBreaker は、プロバイダー（エンドポイント）ごとのサーキットブレーカーです。
+// 連続して失敗した場合に一定時間呼び出しを止め、フォールバック先のモデルへすぐに切り替えられるようにします。
+// 一定時間が経過すると1回だけ試しに呼び出し（半開状態）、成功すれば閉じ、失敗すれば再び開きます。
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+	threshold int
+	cooldown  time.Duration
+}
+
+var (
+	circuitBreakers   = map[string]*CircuitBreaker{}
+	circuitBreakersMu sync.Mutex
+)
+
+// GetCircuitBreaker は、プロバイダーとベースURLに対応するサーキットブレーカーを返します。
+// サーキットブレーカーはプロセス内で共有され、全ての Cube・オペレーションで同じ状態を参照します。
+func GetCircuitBreaker(pType ProviderType, baseURL string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	key := strings.ToLower(string(pType)) + "|" + baseURL
+	circuitBreakersMu.Lock()
+	defer circuitBreakersMu.Unlock()
+	if b, ok := circuitBreakers[key]; ok {
+		return b
+	}
+	b := &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+	circuitBreakers[key] = b
+	return b
+}
+
+// Allow は、呼び出してよいかを返します。
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	// 開いている間は呼び出さない。経過後は1回だけ試す
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// RecordSuccess は、呼び出しの成功を記録し、サーキットブレーカーを閉じます。
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+// RecordFailure は、呼び出しの失敗を記録します。連続失敗回数が閾値に達した場合はサーキットブレーカーを開きます。
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// abandonProbe は、半開状態の試しの呼び出しが結果を得ずに終わった（キャンセルされた）場合に、次の呼び出しで再び試せるようにします。
+func (b *CircuitBreaker) abandonProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// callWithRetry は、サーキットブレーカーと再試行の方針に従って fn を呼び出します。
+// 再試行の対象外のエラーはそのまま返します。再試行しても失敗した場合は最後のエラーを返します。
+func callWithRetry[T any](ctx context.Context, policy RetryPolicy, budget *retryBudget, breaker *CircuitBreaker, l *zap.Logger, label string, fn func() (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; ; attempt++ {
+		if !breaker.Allow() {
+			return zero, fmt.Errorf("%s: %w", label, ErrCircuitOpen)
+		}
+		result, err := fn()
+		if err == nil {
+			breaker.RecordSuccess()
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			breaker.abandonProbe()
+			return zero, err
+		}
+		if !IsRetryableError(err) {
+			// プロバイダーは応答している（リクエスト自体の誤りなど）ため、障害としては数えない
+			breaker.RecordSuccess()
+			return zero, err
+		}
+		breaker.RecordFailure()
+		if attempt >= policy.MaxRetries || !budget.take() {
+			return zero, err
+		}
+		wait := policy.backoff(attempt)
+		utils.LogWarn(l, "Provider: Retrying after error", zap.String("model", label), zap.Int("attempt", attempt+1), zap.Duration("backoff", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return zero, context.Cause(ctx)
+		case <-time.After(wait):
+		}
+	}
+}
+
+// ========================================
+// Resilient Chat Model
+// ========================================
+
+// ChatModelCandidate は、ResilientChatModel が使用するチャットモデルの1つです。
+type ChatModelCandidate struct {
+	ModelName string                     // モデル名（トークン使用量の集計に使用）
+	Model     model.ToolCallingChatModel // チャットモデル
+	Breaker   *CircuitBreaker            // プロバイダーのサーキットブレーカー
+}
+
+// ResilientChatModel は、再試行・サーキットブレーカー・フォールバックを備えた model.ToolCallingChatModel です。
+// 先頭のモデルから順に呼び出し、再試行しても失敗した場合（またはサーキットブレーカーが開いている場合）は次のモデルを使用します。
+// 実際に応答したモデルの名前をコンテキストに設定して呼び出すため、トークン使用量はそのモデル名で集計されます（utils.WithAnsweringModel）。
+type ResilientChatModel struct {
+	candidates []ChatModelCandidate
+	policy     RetryPolicy
+	budget     *retryBudget
+	Logger     *zap.Logger
+}
+
+var _ model.ToolCallingChatModel = (*ResilientChatModel)(nil)
+
+// NewResilientChatModel は、新しい ResilientChatModel を作成します。
+// 引数:
+//   - candidates: 使用するチャットモデル（先頭がプライマリ、以降がフォールバック先の順）
+//   - policy: 再試行の方針（RetryBudget はこのインスタンス全体で共有）
+func NewResilientChatModel(candidates []ChatModelCandidate, policy RetryPolicy, l *zap.Logger) *ResilientChatModel {
+	return &ResilientChatModel{
+		candidates: candidates,
+		policy:     policy,
+		budget:     newRetryBudget(policy.RetryBudget),
+		Logger:     l,
+	}
+}
+
+// Generate は、応答を生成します。
+func (m *ResilientChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	return withFallback(m, ctx, func(c ChatModelCandidate, cctx context.Context) (*schema.Message, error) {
+		return c.Model.Generate(cctx, input, opts...)
+	})
+}
+
+// Stream は、応答をストリームで生成します。
+// 再試行・フォールバックはストリームの開始までが対象で、開始後のエラーはそのまま返します。
+func (m *ResilientChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return withFallback(m, ctx, func(c ChatModelCandidate, cctx context.Context) (*schema.StreamReader[*schema.Message], error) {
+		return c.Model.Stream(cctx, input, opts...)
+	})
+}
+
+// WithTools は、全てのモデルにツールを設定した新しい ResilientChatModel を返します。
+// 再試行の残り回数は元のインスタンスと共有します。
+func (m *ResilientChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	candidates := make([]ChatModelCandidate, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		withTools, err := c.Model.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to bind tools to %s: %w", c.ModelName, err)
+		}
+		candidates = append(candidates, ChatModelCandidate{ModelName: c.ModelName, Model: withTools, Breaker: c.Breaker})
+	}
+	return &ResilientChatModel{candidates: candidates, policy: m.policy, budget: m.budget, Logger: m.Logger}, nil
+}
+
+// withFallback は、モデルを順に呼び出し、最初に成功したモデルの結果を返します。
+// 再試行の対象外のエラー（リクエスト自体の誤りなど）はフォールバックせずにそのまま返します。
+func withFallback[T any](m *ResilientChatModel, ctx context.Context, call func(c ChatModelCandidate, cctx context.Context) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	for i, c := range m.candidates {
+		cctx := utils.WithAnsweringModel(ctx, c.ModelName)
+		result, err := callWithRetry(ctx, m.policy, m.budget, c.Breaker, m.Logger, c.ModelName, func() (T, error) {
+			return call(c, cctx)
+		})
+		if err == nil {
+			if i > 0 {
+				utils.LogInfo(m.Logger, "Provider: Answered by fallback model", zap.String("model", c.ModelName), zap.String("primary", m.candidates[0].ModelName))
+			}
+			return result, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !(IsRetryableError(err) || errors.Is(err, ErrCircuitOpen)) {
+			return zero, err
+		}
+		if i+1 < len(m.candidates) {
+			utils.LogWarn(m.Logger, "Provider: Falling back to next model", zap.String("failed", c.ModelName), zap.String("next", m.candidates[i+1].ModelName), zap.Error(err))
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no chat model is configured")
+	}
+	return zero, fmt.Errorf("All chat models failed: %w", lastErr)
+}
+
+// ========================================
+// Resilient Embedder
+// ========================================
+
+// ResilientEmbedder は、再試行とサーキットブレーカーを備えた embedding.Embedder です。
+// 埋め込みモデルは Cube のベクトル空間を決めるため、別のモデルへのフォールバックは行いません。
+type ResilientEmbedder struct {
+	embedder  embedding.Embedder
+	modelName string
+	breaker   *CircuitBreaker
+	policy    RetryPolicy
+	budget    *retryBudget
+	Logger    *zap.Logger
+}
+
+var _ embedding.Embedder = (*ResilientEmbedder)(nil)
+
+// NewResilientEmbedder は、新しい ResilientEmbedder を作成します。
+func NewResilientEmbedder(emb embedding.Embedder, modelName string, breaker *CircuitBreaker, policy RetryPolicy, l *zap.Logger) *ResilientEmbedder {
+	return &ResilientEmbedder{
+		embedder:  emb,
+		modelName: modelName,
+		breaker:   breaker,
+		policy:    policy,
+		budget:    newRetryBudget(policy.RetryBudget),
+		Logger:    l,
+	}
+}
+
+// EmbedStrings は、テキストをベクトル化します。
+func (e *ResilientEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	return callWithRetry(ctx, e.policy, e.budget, e.breaker, e.Logger, e.modelName, func() ([][]float64, error) {
+		return e.embedder.EmbedStrings(ctx, texts, opts...)
+	})
+}
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{fmt.Errorf("call failed: %w", context.DeadlineExceeded), true},
+		{errors.New("error, status code: 429, status: 429 Too Many Requests, message: Rate limit reached"), true},
+		{errors.New("error, status code: 503, message: upstream error"), true},
+		{errors.New("unexpected status code=502 from server"), true},
+		{errors.New("HTTP 500: something went wrong"), true},
+		{errors.New("Error 503, Message: The model is busy, Status: UNAVAILABLE"), true},
+		{errors.New(`POST "https://api.anthropic.com/v1/messages": 529 Overloaded`), true},
+		{errors.New("connection reset by peer"), true},
+		{errors.New("error, status code: 400, message: This model's maximum context length is 512 tokens"), false},
+		{errors.New("input has 512 tokens, which exceeds the limit of 500"), false},
+		{errors.New("error, status code: 401, message: invalid api key"), false},
+		{errors.New("status code: 5000 is not a valid status"), false},
+	}
+	for _, tt := range tests {
+		name := "nil"
+		if tt.err != nil {
+			name = tt.err.Error()
+		}
+		t.Run(name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
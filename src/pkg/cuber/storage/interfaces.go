@@ -21,6 +21,7 @@ type Data struct {
 	Extension            string    `json:"extension"`              // ファイル拡張子
 	MimeType             string    `json:"mime_type"`              // MIMEタイプ
 	ContentHash          string    `json:"content_hash"`           // ファイルのコンテンツハッシュ（SHA-256等）
+	SourceID             string    `json:"source_id"`              // 呼び出し元が指定する文書の同一性キー（同じ source_id の新しい版が古い版を置き換える）
 	OwnerID              string    `json:"owner_id"`               // 所有者ID
 	CreatedAt            time.Time `json:"created_at"`             // 作成日時
 }
@@ -52,11 +53,18 @@ type Chunk struct {
 
 // QueryResult は、ベクトル検索の結果を表します。
 type QueryResult struct {
-	ID         string  // 検索結果のID
-	Text       string  // 検索結果のテキスト
-	Distance   float64 // クエリとの類似度（コサイン類似度、-1〜1）
-	Nouns      string  // FTS拡張用: チャンクから取り出した名詞キーワード
-	NounsVerbs string  // FTS拡張用: チャンクから取り出した名詞+動詞キーワード
+	ID         string    // 検索結果のID
+	Text       string    // 検索結果のテキスト
+	Distance   float64   // クエリとの類似度（コサイン類似度、-1〜1）
+	Nouns      string    // FTS拡張用: チャンクから取り出した名詞キーワード
+	NounsVerbs string    // FTS拡張用: チャンクから取り出した名詞+動詞キーワード
+	ObservedAt time.Time // 時系列検索用: チャンクの元データを取り込んだ日時（QueryChunksInTimeRange のみ設定）
+}
+
+// EmbeddingSource は、埋め込みモデルの移行で再ベクトル化する行です。
+type EmbeddingSource struct {
+	ID   string // 行のID
+	Text string // ベクトル化するテキスト
 }
 
 // VectorStorage は、ベクトルストレージの操作を定義するインターフェースです。
@@ -75,11 +83,36 @@ type VectorStorage interface {
 
 	// GetDataByID は、IDとメモリーグループでデータを取得します。
 	// memory_groupによる厳格なフィルタリングを行います。
+	// 存在しない場合は nil を返します。
 	GetDataByID(ctx context.Context, id string, memoryGroup string) (*Data, error)
 
 	// GetDataList は、指定されたメモリーグループに属するすべてのデータを取得します。
 	GetDataList(ctx context.Context, memoryGroup string) ([]*Data, error)
 
+	// GetDataBySourceID は、指定された source_id を持つデータを取得します。
+	// memory_groupによる厳格なフィルタリングを行います。
+	GetDataBySourceID(ctx context.Context, sourceID string, memoryGroup string) ([]*Data, error)
+
+	// SetDataSourceID は、データの source_id を更新します。
+	// 重複としてスキップされたデータを、再度取り込まれた文書の版として引き継ぐために使用します。
+	SetDataSourceID(ctx context.Context, dataID string, sourceID string, memoryGroup string) error
+
+	// GetChunkIDsByDataID は、指定されたデータから生成された全チャンクのIDを取得します。
+	// Data -> Document -> Chunk の関係を辿ります。
+	GetChunkIDsByDataID(ctx context.Context, dataID string, memoryGroup string) ([]string, error)
+
+	// GetCitations は、チャンクIDから出典情報（ファイル名・チャンク順序など）を取得します。
+	// 存在しないチャンクIDは無視されます。結果の順序は chunkIDs の順序に従います。
+	GetCitations(ctx context.Context, chunkIDs []string, memoryGroup string) ([]*Citation, error)
+
+	// GetChunkTextsByIDs は、チャンクIDからチャンクの本文を取得します。
+	// 存在しないチャンクIDは結果に含まれません。
+	GetChunkTextsByIDs(ctx context.Context, chunkIDs []string, memoryGroup string) (map[string]string, error)
+
+	// DeleteData は、データとそのドキュメント・チャンクを削除します。
+	// チャンクに紐づく要約やグラフ（エッジの出典）は呼び出し側で先に処理する必要があります。
+	DeleteData(ctx context.Context, dataID string, memoryGroup string) error
+
 	// ========================================
 	// ベクトル操作
 	// ========================================
@@ -95,12 +128,53 @@ type VectorStorage interface {
 	SaveEmbedding(ctx context.Context, tableName types.TableName, id string, text string, vector []float32, memoryGroup string) error
 
 	// Query は、ベクトル類似度検索を実行します。
+	// ベクトルインデックスが利用可能な場合は近似最近傍検索を行い、利用できない場合は全件走査で厳密に検索します。
 	// tableName: 検索対象のテーブル
 	// vector: クエリベクトル
 	// k: 返す結果の最大数
 	// memoryGroup: メモリーグループ（パーティション分離用）
 	Query(ctx context.Context, tableName types.TableName, vector []float32, topk int, memoryGroup string) ([]*QueryResult, error)
 
+	// RebuildVectorIndexes は、埋め込みテーブルのベクトルインデックスを削除して作成し直します。
+	// インポートなどでデータベースファイルを直接置き換えた後に使用します。
+	RebuildVectorIndexes(ctx context.Context) error
+
+	// PrepareEmbeddingMigration は、埋め込みモデルの移行のため、埋め込みテーブルに移行先の次元数のカラムを追加します。
+	// 前回中断した移行のカラムが残っている場合は、作成し直します。
+	// dimension: 移行先の埋め込みモデルの次元数
+	PrepareEmbeddingMigration(ctx context.Context, dimension uint) error
+
+	// GetPendingEmbeddings は、移行先のカラムが未設定の行を ID 順に最大 limit 件返します。
+	// afterID より大きい ID の行のみを返します（空の場合は先頭から）。
+	GetPendingEmbeddings(ctx context.Context, tableName types.TableName, afterID string, limit int) ([]*EmbeddingSource, error)
+
+	// CountPendingEmbeddings は、移行先のカラムが未設定の行数を返します。
+	CountPendingEmbeddings(ctx context.Context, tableName types.TableName) (int, error)
+
+	// SaveMigratedEmbedding は、移行先のモデルで作成したベクトルを移行先のカラムに保存します。
+	SaveMigratedEmbedding(ctx context.Context, tableName types.TableName, id string, vector []float32) error
+
+	// CompleteEmbeddingMigration は、旧モデルの embedding カラムを削除し、移行先のカラムを embedding に置き換えます。
+	// ベクトルインデックスも削除するため、完了後に RebuildVectorIndexes を呼び出してください。
+	// トランザクション内で呼び出します。
+	CompleteEmbeddingMigration(ctx context.Context) error
+
+	// AbortEmbeddingMigration は、移行先のカラムを削除し、移行前の状態に戻します。
+	AbortEmbeddingMigration(ctx context.Context) error
+
+	// GetCachedEmbeddings は、埋め込みキャッシュからキーに一致するベクトルを取得します。
+	// キャッシュにないキーは結果に含まれません。
+	// keys: キャッシュキー（埋め込みモデルとテキストから決まる。embedcache.CacheKey を参照）
+	GetCachedEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error)
+
+	// SaveCachedEmbeddings は、ベクトルを埋め込みキャッシュに保存します。既に存在するキーは更新しません。
+	// キャッシュの保存はベストエフォートで、他の書き込みトランザクションの実行中は保存を省略します。
+	SaveCachedEmbeddings(ctx context.Context, entries map[string][]float32) error
+
+	// DeleteCachedEmbeddings は、埋め込みキャッシュからキーに一致するエントリを削除します。
+	// 存在しないキーは無視されます。文書の削除時に、その文書のテキストのベクトルを消去するために使用します。
+	DeleteCachedEmbeddings(ctx context.Context, keys []string) error
+
 	// FullTextSearch は、全文検索を実行します。
 	// 検索クエリを形態素解析し、指定されたレイヤーのインデックスを使用して検索します。
 	// tableName: 検索対象のテーブル（通常は Chunk）
@@ -111,6 +185,11 @@ type VectorStorage interface {
 	// layer: 検索に使用するFTSレイヤー（nouns, nouns_verbs, all）
 	FullTextSearch(ctx context.Context, tableName types.TableName, query string, topk int, memoryGroup string, isEn bool, layer types.FtsLayer) ([]*QueryResult, error)
 
+	// QueryChunksInTimeRange は、元データの取り込み日時が指定期間内のチャンクに限定してベクトル類似度検索を実行します。
+	// Data -> Document -> Chunk の関係を辿り、結果の ObservedAt に元データの取り込み日時を設定します。
+	// timeRange の From / To がゼロ値の場合、その側の期間は無制限です。
+	QueryChunksInTimeRange(ctx context.Context, vector []float32, topk int, memoryGroup string, timeRange types.TimeRange) ([]*QueryResult, error)
+
 	// ========================================
 	// Embedding取得操作 (Phase-09追加)
 	// ========================================
@@ -148,6 +227,23 @@ type VectorStorage interface {
 	//   - error: エラーが発生した場合
 	GetEmbeddingsByIDs(ctx context.Context, tableName types.TableName, ids []string, memoryGroup string) (map[string][]float32, error)
 
+	// GetEmbeddingTextsByIDs は、複数IDのテキスト（埋め込みの元になったテキスト）を一括取得します。
+	// 存在しないIDは結果に含まれません。
+	GetEmbeddingTextsByIDs(ctx context.Context, tableName types.TableName, ids []string, memoryGroup string) (map[string]string, error)
+
+	// DeleteEmbeddings は、複数IDのEmbedding（およびテキスト）を一括削除します。
+	// 存在しないIDは無視されます。
+	//
+	// 引数:
+	//   - ctx: コンテキスト
+	//   - tableName: テーブル名（例: "Summary", "Entity"）
+	//   - ids: 削除するIDのスライス
+	//   - memoryGroup: メモリーグループ
+	//
+	// 返り値:
+	//   - error: エラーが発生した場合
+	DeleteEmbeddings(ctx context.Context, tableName types.TableName, ids []string, memoryGroup string) error
+
 	// Transaction は与えられた関数をトランザクション内で実行します。
 	Transaction(ctx context.Context, fn func(txCtx context.Context) error) error
 
@@ -173,6 +269,17 @@ type Embedder interface {
 	//   - types.TokenUsage: トークン使用量
 	//   - error: エラーが発生した場合
 	EmbedQuery(ctx context.Context, text string) ([]float32, types.TokenUsage, error)
+
+	// EmbedBatch は、複数のテキストをまとめてベクトル表現に変換します。
+	// プロバイダーの上限（1リクエストあたりの入力数・トークン数）に合わせてリクエストを分割します。
+	// 引数:
+	//   - ctx: コンテキスト
+	//   - texts: ベクトル化するテキストのリスト
+	// 返り値:
+	//   - [][]float32: ベクトル表現のリスト（texts と同じ順序）
+	//   - types.TokenUsage: トークン使用量
+	//   - error: いずれかのリクエストが失敗した場合
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error)
 }
 
 // Node は、知識グラフのノード（エンティティ）を表します。
@@ -196,6 +303,50 @@ type Edge struct {
 	Confidence  float64        `json:"confidence"`   // 信頼度（0.0〜1.0）
 	Unix        int64          `json:"unix"`         // 観測・更新時のUnixタイムスタンプ（ミリ秒）
 	Thickness   float64        `json:"thickness"`    // 計算された Thickness 値（クエリ時に動的算出、Weight × Confidence × 時間減衰）
+	ChunkID     string         `json:"-"`            // 抽出元チャンクのID（グラフ抽出時のみ設定され、出典の記録に使用）
+	// 出典となったチャンクのID群（クエリ時に EdgeProvenance から設定される。出典未記録のエッジでは空）
+	SourceChunkIDs []string `json:"source_chunk_ids,omitempty"`
+}
+
+// EdgeProvenance は、エッジがどのチャンクから抽出されたか（出典）を表します。
+// 1本のエッジが複数のチャンクで裏付けられている場合、チャンクごとに1件ずつ記録されます。
+// このデータは、LadybugDBのEdgeProvenanceテーブルに保存されます。
+type EdgeProvenance struct {
+	ID          string `json:"id"`           // 出典の一意識別子（エッジとチャンクから決定論的に生成）
+	MemoryGroup string `json:"memory_group"` // メモリーグループ（パーティション分離用）
+	SourceID    string `json:"source_id"`    // エッジのソースノードID（メモリーグループ連結済み）
+	TargetID    string `json:"target_id"`    // エッジのターゲットノードID（メモリーグループ連結済み）
+	Type        string `json:"type"`         // エッジのタイプ
+	ChunkID     string `json:"chunk_id"`     // 抽出元チャンクのID
+	DocumentID  string `json:"document_id"`  // 抽出元ドキュメントのID
+}
+
+// Citation は、クエリ結果の根拠となったチャンクの出典情報を表します。
+// チャンク → ドキュメント → データ（元ファイル）を辿って構築されます。
+type Citation struct {
+	ChunkID    string `json:"chunk_id"`    // チャンクのID
+	ChunkIndex int    `json:"chunk_index"` // ドキュメント内でのチャンクの順序（0始まり）
+	DocumentID string `json:"document_id"` // ドキュメントのID
+	DataID     string `json:"data_id"`     // データ（元ファイル）のID
+	FileName   string `json:"file_name"`   // 元ファイル名
+	SourceID   string `json:"source_id"`   // Absorb 時に指定された論理的な文書ID（未指定なら空）
+}
+
+// RetractResult は、チャンクの取り消し（RetractChunks）の結果を表します。
+type RetractResult struct {
+	DeletedEdges  int      // 裏付けを全て失ったため削除されたエッジ数
+	WeakenedEdges int      // 他の出典が残っているため重みを下げて残したエッジ数
+	OrphanNodeIDs []string // エッジ削除により孤立したため削除されたノードID（Entityのembedding削除に使用）
+	// 出典を取り消したエッジの端点のノードID（OrphanNodeIDs を含む。コミュニティの要約の無効化に使用）
+	AffectedNodeIDs []string
+}
+
+// CypherResult は、読み取り専用 Cypher クエリの実行結果を表形式で表します。
+// ノード・リレーションの値はプロパティのマップに変換され、embedding は除外されます。
+type CypherResult struct {
+	Columns   []string `json:"columns"`   // 列名
+	Rows      [][]any  `json:"rows"`      // 行（各行は Columns と同じ順序の値）
+	Truncated bool     `json:"truncated"` // 行数の上限により結果が打ち切られたかどうか
 }
 
 // Triple は、ノード-エッジ-ノードの3つ組を表します。
@@ -281,6 +432,19 @@ type GraphStorage interface {
 	// 指定されたタイプのノードを取得
 	GetNodesByType(ctx context.Context, nodeType string, memoryGroup string) ([]*Node, error)
 
+	// GetNodesByIDs は、指定されたIDのノードを取得します。存在しないIDは結果に含まれません。
+	GetNodesByIDs(ctx context.Context, ids []string, memoryGroup string) ([]*Node, error)
+
+	// GetNodesByPropertyText は、properties（JSON 文字列）に texts のいずれかを含むノードを取得します。DocumentChunk ノードは含みません。
+	// 文書の取り消し時に、別名の出典としてチャンクIDを記録したノードを探すために使用します。
+	GetNodesByPropertyText(ctx context.Context, texts []string, memoryGroup string) ([]*Node, error)
+
+	// SearchNodes は、名前（IDからメモリーグループを除いたもの）または別名（properties の aliases）に text を含むノードを取得します。
+	// text は utils.NormalizeForGraph で正規化して照合します。text が空の場合は全てのノードを返します。
+	// nodeType が空でない場合は、そのタイプのノードに限定します。DocumentChunk ノードは含みません。
+	// 返すノードのIDは、メモリーグループを含まない形式です（ID順）。
+	SearchNodes(ctx context.Context, text string, nodeType string, memoryGroup string) ([]*Node, error)
+
 	// 指定されたエッジタイプでターゲットに接続されたノードを取得
 	GetNodesByEdge(ctx context.Context, targetID string, edgeType string, memoryGroup string) ([]*Node, error)
 
@@ -288,7 +452,8 @@ type GraphStorage interface {
 	UpdateEdgeWeight(ctx context.Context, sourceID, targetID, memoryGroup string, weight float64) error
 
 	// エッジの重みと信頼度とタイムスタンプを更新
-	UpdateEdgeMetrics(ctx context.Context, sourceID, targetID, memoryGroup string, weight, confidence float64, unix int64) error
+	// 注意: sourceID, edgeType, targetID の組み合わせで特定のエッジのみを更新します。
+	UpdateEdgeMetrics(ctx context.Context, sourceID, edgeType, targetID, memoryGroup string, weight, confidence float64, unix int64) error
 
 	// エッジを削除
 	// 注意: sourceID, edgeType, targetID の組み合わせで特定のエッジのみを削除します。
@@ -300,6 +465,42 @@ type GraphStorage interface {
 	// 指定されたノードに接続されたエッジを取得
 	GetEdgesByNode(ctx context.Context, nodeID string, memoryGroup string) ([]*Edge, error)
 
+	// ========================================
+	// 出典管理API
+	// ========================================
+
+	// AddEdgeProvenances は、エッジの出典（抽出元チャンク）を記録します。
+	// 同じIDの出典が既に存在する場合は上書きされます。
+	AddEdgeProvenances(ctx context.Context, provenances []*EdgeProvenance) error
+
+	// GetEdgeProvenances は、指定されたエッジ群の出典を取得します。
+	// エッジは（SourceID, Type, TargetID）で照合されます。出典が記録されていないエッジは結果に含まれません。
+	GetEdgeProvenances(ctx context.Context, edges []*Edge, memoryGroup string) ([]*EdgeProvenance, error)
+
+	// RetractChunks は、指定されたチャンク群を知識グラフから取り消します。
+	// この関数は、文書の新しい版による置き換えや削除の際に使用します。
+	//
+	// 引数:
+	//   - ctx: コンテキスト
+	//   - chunkIDs: 取り消すチャンクIDのスライス
+	//   - keepChunkIDs: 置き換え後の新しい版のチャンクIDのスライス（削除のみの場合は nil）
+	//   - memoryGroup: メモリーグループ
+	//
+	// 返り値:
+	//   - *RetractResult: 削除・弱化したエッジ数と削除した孤立ノード
+	//   - error: エラーが発生した場合
+	//
+	// 処理内容:
+	//   1. チャンクに由来する出典を削除
+	//   2. 出典が残らなかったエッジを削除
+	//   3. 他のチャンクの出典が残るエッジは、失った出典の割合だけ重みを下げて残す
+	//      （keepChunkIDs の出典を持つエッジは新しい版が再度抽出したものなので、重みを変更しない）
+	//   4. チャンクのグラフノード（DocumentChunk）と、エッジ削除で孤立したノードを削除
+	//
+	// 注意:
+	//   - 出典が記録されていないエッジ（出典管理の導入前に作成されたもの、Memify 等で生成されたもの）は対象外です
+	RetractChunks(ctx context.Context, chunkIDs []string, keepChunkIDs []string, memoryGroup string) (*RetractResult, error)
+
 	// ========================================
 	// 効率化API (Phase-09追加)
 	// ========================================
@@ -370,6 +571,46 @@ type GraphStorage interface {
 	// UpsertMemoryGroup は、メモリーグループの設定を作成または更新します。
 	// Absorbリクエスト時に動的にグループ設定を初期化・調整するために使用されます。
 	UpsertMemoryGroup(ctx context.Context, config *MemoryGroupConfig) error
+
+	// GetPromptOverrides は、指定されたメモリーグループのプロンプトの上書きを取得します。
+	// activeOnly が true の場合は有効なバージョンのみ、false の場合は全てのバージョンを（名前・バージョン順に）返します。
+	GetPromptOverrides(ctx context.Context, memoryGroup string, activeOnly bool) ([]*PromptOverride, error)
+
+	// SavePromptOverride は、プロンプトの上書きを新しいバージョンとして保存し、有効にします。
+	// 以前のバージョンは履歴として残ります（無効になります）。
+	SavePromptOverride(ctx context.Context, memoryGroup string, name string, content string) (*PromptOverride, error)
+
+	// DeactivatePromptOverride は、プロンプトの上書きを無効にし、デフォルトのプロンプトに戻します。
+	// 有効な上書きがなかった場合は false を返します。
+	DeactivatePromptOverride(ctx context.Context, memoryGroup string, name string) (bool, error)
+
+	// GetOntology は、指定されたメモリーグループのオントロジー定義（JSON）を取得します。
+	// 定義されていない場合は空文字を返します。
+	GetOntology(ctx context.Context, memoryGroup string) (string, error)
+
+	// SaveOntology は、メモリーグループのオントロジー定義（JSON）を保存します（既存の定義は置き換えられます）。
+	SaveOntology(ctx context.Context, memoryGroup string, definition string) error
+
+	// DeleteOntology は、メモリーグループのオントロジー定義を削除します。
+	// 定義されていなかった場合は false を返します。
+	DeleteOntology(ctx context.Context, memoryGroup string) (bool, error)
+
+	// GetCommunities は、指定されたメモリーグループのコミュニティを取得します。
+	// level が負の場合は全ての階層を返します。階層の浅い順、同じ階層内では Weight の大きい順に並びます。
+	GetCommunities(ctx context.Context, memoryGroup string, level int) ([]*Community, error)
+
+	// ReplaceCommunities は、メモリーグループのコミュニティを全て削除し、指定されたコミュニティで置き換えます。
+	ReplaceCommunities(ctx context.Context, memoryGroup string, communities []*Community) error
+
+	// FindPaths は、2つのエンティティ間の経路を、ホップ数の少ない順に最大 query.K 件取得します。
+	// ホップ数が同じ経路は、経路上のエッジの Thickness が大きい順に並びます。
+	// エッジの向きは無視して辿ります。経路がない場合は空のスライスを返します。
+	FindPaths(ctx context.Context, query PathQuery, memoryGroup string) ([]*Path, error)
+
+	// ExecuteReadOnlyQuery は、検証・スコープ済みの読み取り専用 Cypher クエリを実行し、表形式で返します。
+	// 結果は最大 maxRows 行で打ち切られ、timeoutMs ミリ秒を超えるとクエリは中断されます。
+	// クエリの検証とメモリーグループによるスコープは呼び出し側（tools/cypher）の責務です。
+	ExecuteReadOnlyQuery(ctx context.Context, query string, maxRows int, timeoutMs uint64) (*CypherResult, error)
 }
 
 // MemoryGroupConfig は、メモリーグループごとの代謝パラメータを保持します。
@@ -382,6 +623,51 @@ type MemoryGroupConfig struct {
 	MdlKNeighbors              int     `json:"mdl_k_neighbors"`               // MDL判定時の近傍ノード数（デフォルト: 5）
 }
 
+// PromptOverride は、メモリーグループごとのプロンプトの上書きの1バージョンです。
+// 同じメモリーグループ・プロンプトの上書きは、保存するたびに新しいバージョンとして記録されます。
+type PromptOverride struct {
+	MemoryGroup string    `json:"memory_group"` // メモリーグループ名
+	Name        string    `json:"name"`         // プロンプトの名前（prompts.Name）
+	Version     int64     `json:"version"`      // バージョン（1から始まり、保存するたびに増える）
+	Content     string    `json:"content"`      // プロンプトの内容
+	IsActive    bool      `json:"is_active"`    // 有効なバージョンかどうか（プロンプトごとに最大1つ）
+	CreatedAt   time.Time `json:"created_at"`   // 保存日時
+}
+
+// Community は、知識グラフのコミュニティ（密につながったノードの集まり）と、その要約です。
+// コミュニティは階層を持ち、Level 0 が最も大きな（粗い）コミュニティです。
+type Community struct {
+	ID          string    `json:"id"`           // コミュニティの一意識別子
+	MemoryGroup string    `json:"memory_group"` // メモリーグループ名
+	Level       int       `json:"level"`        // 階層レベル（0 が最上位。数字が大きいほど細かい）
+	ParentID    string    `json:"parent_id"`    // 1つ上の階層のコミュニティID（最上位、または親が保存されていない場合は空）
+	Title       string    `json:"title"`        // LLM が付けたタイトル（未要約の場合は空）
+	Summary     string    `json:"summary"`      // LLM が書いた要約（未要約の場合は空）
+	MemberIDs   []string  `json:"member_ids"`   // 所属するノードID
+	Weight      float64   `json:"weight"`       // コミュニティ内のエッジの Thickness の合計（重要度の目安）
+	Signature   string    `json:"signature"`    // 所属ノードと内部のエッジから決まる署名（要約の再利用の判定に使用）
+	UpdatedAt   time.Time `json:"updated_at"`   // 更新日時
+}
+
+// PathQuery は、2つのエンティティ間の経路検索の条件です。
+type PathQuery struct {
+	SourceID           string   // 起点のノードID（メモリーグループを含まない形式）
+	TargetID           string   // 終点のノードID（メモリーグループを含まない形式）
+	K                  int      // 返す経路の最大数
+	MaxHops            int      // 経路のホップ数（エッジ数）の上限
+	ThicknessThreshold float64  // Thickness がこの値未満のエッジは辿らない
+	EdgeTypes          []string // 辿るエッジのタイプ（空の場合は全てのタイプ）
+}
+
+// Path は、2つのエンティティ間の経路です。
+// エッジの向きは無視して辿るため、各トリプルは保存されている向きのまま返されます。
+type Path struct {
+	NodeIDs   []string  `json:"node_ids"`  // 起点から終点までのノードID
+	Triples   []*Triple `json:"triples"`   // 経路を構成するトリプル（起点側から順に並ぶ）
+	Hops      int       `json:"hops"`      // ホップ数（エッジ数）
+	Thickness float64   `json:"thickness"` // 経路上のエッジの Thickness の最小値（経路の強さの目安）
+}
+
 // GraphData は、ノードとエッジのテーブルを表します。
 // グラフ抽出タスクの出力として使用されます。
 type GraphData struct {
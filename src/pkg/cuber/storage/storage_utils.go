@@ -1,6 +1,11 @@
 package storage
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
 
 // ConvertNodesAndEdgesToTriples は、ノードとエッジからトリプルを作成します。
 // この関数は、ladybugdb_storage.go の GetTriples で取得される triples と
@@ -77,3 +82,43 @@ func ConvertNodesAndEdgesToTriples(nodes *[]*Node, edges *[]*Edge) (triples *[]*
 	}
 	return &result, nil
 }
+
+// EmbedBatchTolerant は、テキストをまとめてベクトル化します。
+// まとめてのベクトル化に失敗した場合は1件ずつベクトル化し直し、失敗したテキストだけを errs に記録します。
+// 一部のテキストの失敗を許容する処理（エンティティ名や要約の埋め込みなど）で使用します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - embedder: Embedder
+//   - texts: ベクトル化するテキストのリスト
+//
+// 返り値:
+//   - vectors: ベクトル表現のリスト（texts と同じ順序。失敗したテキストは nil）
+//   - errs: テキストごとのエラー（texts と同じ順序。成功したテキストは nil）
+//   - usage: トークン使用量
+func EmbedBatchTolerant(ctx context.Context, embedder Embedder, texts []string) (vectors [][]float32, errs []error, usage types.TokenUsage) {
+	errs = make([]error, len(texts))
+	if len(texts) == 0 {
+		return nil, errs, usage
+	}
+	vectors, u, err := embedder.EmbedBatch(ctx, texts)
+	usage.Add(u)
+	if err == nil {
+		return vectors, errs, usage
+	}
+	vectors = make([][]float32, len(texts))
+	for i, text := range texts {
+		if ctx.Err() != nil {
+			errs[i] = context.Cause(ctx)
+			continue
+		}
+		vector, u, err := embedder.EmbedQuery(ctx, text)
+		usage.Add(u)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		vectors[i] = vector
+	}
+	return vectors, errs, usage
+}
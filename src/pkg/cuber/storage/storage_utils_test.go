@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+// failingEmbedder は、failing に含まれるテキストのベクトル化に失敗するテスト用の Embedder です。
+// まとめてのベクトル化は、failing のテキストを1件でも含むと失敗します。
+type failingEmbedder struct {
+	failing    map[string]bool
+	batchCalls int
+	queryCalls int
+}
+
+func (e *failingEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, types.TokenUsage, error) {
+	e.queryCalls++
+	if e.failing[text] {
+		return nil, types.TokenUsage{InputTokens: 1}, errors.New("embedding failed: " + text)
+	}
+	return []float32{float32(len(text))}, types.TokenUsage{InputTokens: 1}, nil
+}
+
+func (e *failingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error) {
+	e.batchCalls++
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		if e.failing[text] {
+			return nil, types.TokenUsage{InputTokens: int64(len(texts))}, errors.New("batch embedding failed")
+		}
+		vectors = append(vectors, []float32{float32(len(text))})
+	}
+	return vectors, types.TokenUsage{InputTokens: int64(len(texts))}, nil
+}
+
+func TestEmbedBatchTolerant(t *testing.T) {
+	tests := []struct {
+		name           string
+		texts          []string
+		failing        []string
+		cancelled      bool
+		wantVectors    []bool // テキストごとにベクトルが得られるかどうか
+		wantQueryCalls int
+		wantTokens     int64
+	}{
+		{
+			name: "no texts",
+		},
+		{
+			name:        "batch succeeds",
+			texts:       []string{"a", "bb", "ccc"},
+			wantVectors: []bool{true, true, true},
+			wantTokens:  3,
+		},
+		{
+			name:           "failed text is isolated",
+			texts:          []string{"a", "bb", "ccc"},
+			failing:        []string{"bb"},
+			wantVectors:    []bool{true, false, true},
+			wantQueryCalls: 3,
+			wantTokens:     6,
+		},
+		{
+			name:        "cancelled context",
+			texts:       []string{"a", "bb"},
+			failing:     []string{"a"},
+			cancelled:   true,
+			wantVectors: []bool{false, false},
+			wantTokens:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedder := &failingEmbedder{failing: map[string]bool{}}
+			for _, text := range tt.failing {
+				embedder.failing[text] = true
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			vectors, errs, usage := EmbedBatchTolerant(ctx, embedder, tt.texts)
+			if len(errs) != len(tt.texts) {
+				t.Fatalf("len(errs) = %d, want %d", len(errs), len(tt.texts))
+			}
+			for i, want := range tt.wantVectors {
+				if got := vectors[i] != nil; got != want || (errs[i] == nil) != want {
+					t.Errorf("text %q: vector = %v, err = %v, want vector %v", tt.texts[i], vectors[i], errs[i], want)
+				}
+				if want && vectors[i][0] != float32(len(tt.texts[i])) {
+					t.Errorf("text %q: vector = %v belongs to another text", tt.texts[i], vectors[i])
+				}
+			}
+			if tt.cancelled && !errors.Is(errs[0], context.Canceled) {
+				t.Errorf("err = %v, want context.Canceled", errs[0])
+			}
+			if embedder.queryCalls != tt.wantQueryCalls {
+				t.Errorf("EmbedQuery calls = %d, want %d", embedder.queryCalls, tt.wantQueryCalls)
+			}
+			if usage.InputTokens != tt.wantTokens {
+				t.Errorf("input tokens = %d, want %d", usage.InputTokens, tt.wantTokens)
+			}
+		})
+	}
+}
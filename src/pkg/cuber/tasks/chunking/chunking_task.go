@@ -5,7 +5,6 @@ package chunking
 import (
 	"context"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 	"unicode/utf8"
@@ -13,6 +12,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/pkg/cuber/checkpoint"
+	"github.com/t-kawata/mycute/pkg/cuber/extractor"
 	"github.com/t-kawata/mycute/pkg/cuber/pipeline"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
@@ -40,12 +41,14 @@ type ChunkingTask struct {
 	s3Client      *s3client.S3Client    // S3クライアント
 	Logger        *zap.Logger
 	EventBus      *eventbus.EventBus
-	IsEn          bool // true=英語、false=日本語（FTSキーワード抽出用）
+	IsEn          bool              // true=英語、false=日本語（FTSキーワード抽出用）
+	Checkpoints   *checkpoint.Store // チャンク化の結果の保存先（nil の場合は保存・復元しない）
 }
 
 // NewChunkingTask は、新しいChunkingTaskを作成します。
 // kagome: 日本語形態素解析器（CuberServiceのシングルトンを共有）
-func NewChunkingTask(chunkSize, chunkOverlap int, vectorStorage storage.VectorStorage, embedder storage.Embedder, kagome *tokenizer.Tokenizer, s3Client *s3client.S3Client, l *zap.Logger, eb *eventbus.EventBus, isEn bool) *ChunkingTask {
+// checkpoints: 前回失敗した Absorb のチャンクを再利用するためのチェックポイント（nil 可）
+func NewChunkingTask(chunkSize, chunkOverlap int, vectorStorage storage.VectorStorage, embedder storage.Embedder, kagome *tokenizer.Tokenizer, s3Client *s3client.S3Client, l *zap.Logger, eb *eventbus.EventBus, isEn bool, checkpoints *checkpoint.Store) *ChunkingTask {
 	return &ChunkingTask{
 		ChunkSize:     chunkSize,
 		ChunkOverlap:  chunkOverlap,
@@ -56,6 +59,7 @@ func NewChunkingTask(chunkSize, chunkOverlap int, vectorStorage storage.VectorSt
 		Logger:        l,
 		EventBus:      eb,
 		IsEn:          isEn,
+		Checkpoints:   checkpoints,
 	}
 }
 
@@ -80,6 +84,24 @@ func (t *ChunkingTask) Run(ctx context.Context, input any) (any, types.TokenUsag
 		default:
 		}
 
+		// ========================================
+		// 前回失敗した Absorb のチェックポイントがあれば、ファイルの読み込みとチャンク化（embedding 生成）を省略
+		// ========================================
+		if doc, chunks, ok := t.Checkpoints.LoadChunks(data.ID); ok {
+			if err := t.VectorStorage.SaveDocument(ctx, doc); err != nil {
+				return nil, totalUsage, fmt.Errorf("Chunking: Failed to save document for %s: %w", data.Name, err)
+			}
+			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_CHECKPOINT_RESTORED), event.AbsorbCheckpointRestoredPayload{
+				BasePayload:    event.NewBasePayload(data.MemoryGroup),
+				FileName:       data.Name,
+				ChunksCount:    len(chunks),
+				ExtractedCount: t.Checkpoints.CountGraphs(data.ID),
+			})
+			allChunks = append(allChunks, chunks...)
+			utils.LogInfo(t.Logger, "ChunkingTask: Restored chunks from checkpoint", zap.String("name", data.Name), zap.Int("chunks", len(chunks)))
+			continue
+		}
+
 		// Emit Chunking Read Start
 		eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_CHUNKING_READ_START), event.AbsorbChunkingReadStartPayload{
 			BasePayload: event.NewBasePayload(data.MemoryGroup),
@@ -91,10 +113,10 @@ func (t *ChunkingTask) Run(ctx context.Context, input any) (any, types.TokenUsag
 		if err != nil {
 			return nil, totalUsage, fmt.Errorf("Chunking: Failed to download file %s: %w", data.RawDataLocation, err)
 		}
-		// 取得したローカルパスから読み込み
-		content, err := os.ReadFile(*localPath)
+		// 取得したローカルパスから、拡張子・MIMEタイプに応じた抽出器でテキストを抽出
+		extracted, err := extractor.Extract(ctx, *localPath, data.Extension, data.MimeType)
 		if err != nil {
-			return nil, totalUsage, fmt.Errorf("Chunking: Failed to read file %s: %w", *localPath, err)
+			return nil, totalUsage, fmt.Errorf("Chunking: Failed to extract text from %s: %w", data.Name, err)
 		}
 
 		// Emit Chunking Read End
@@ -103,24 +125,32 @@ func (t *ChunkingTask) Run(ctx context.Context, input any) (any, types.TokenUsag
 			FileName:    data.Name,
 		})
 
-		text := string(content)
-
 		// ========================================
 		// テキスト正規化 (SaveDocument の前に実行)
 		// ========================================
-		// 1. 共通正規化 (HTML除去、Boilerplate削除)
-		cleaned := utils.CommonNormalize(text)
-		// 2. Vector用正規化
-		text = utils.NormalizeForVector(cleaned)
+		// セクション（ページ/シート等）ごとに正規化し、正規化後のテキスト上の位置をメタデータに記録する
+		text, sections := normalizeSections(extracted)
 
 		// ドキュメントを作成 (正規化済みテキストを使用)
 		docID := uuid.New().String()
+		metaData := map[string]any{
+			"source":    data.Name,
+			"format":    extracted.Format,
+			"extension": data.Extension,
+			"mime_type": data.MimeType,
+		}
+		for k, v := range extracted.MetaData {
+			metaData[k] = v
+		}
+		if len(sections) > 0 {
+			metaData["sections"] = sections
+		}
 		doc := &storage.Document{
 			ID:          docID,
 			MemoryGroup: data.MemoryGroup, // パーティション
 			DataID:      data.ID,
 			Text:        text,
-			MetaData:    map[string]any{"source": data.Name},
+			MetaData:    metaData,
 		}
 
 		// Emit Chunking Save Start
@@ -144,11 +174,15 @@ func (t *ChunkingTask) Run(ctx context.Context, input any) (any, types.TokenUsag
 		})
 
 		// テキストをチャンク化
-		chunks, chunkUsage, err := t.chunkText(text, docID, data.MemoryGroup)
+		chunks, chunkUsage, err := t.chunkText(ctx, text, docID, data.MemoryGroup)
 		totalUsage.Add(chunkUsage)
 		if err != nil {
 			return nil, totalUsage, fmt.Errorf("Chunking: Failed to chunk text for %s: %w", data.Name, err)
 		}
+		// チェックポイントの保存に失敗しても Absorb は続行する（再実行時にチャンク化からやり直すだけ）
+		if err := t.Checkpoints.SaveChunks(data.ID, doc, chunks); err != nil {
+			utils.LogWarn(t.Logger, "ChunkingTask: Failed to save checkpoint", zap.String("name", data.Name), zap.Error(err))
+		}
 
 		// Emit Chunking Process End
 		eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_CHUNKING_PROCESS_END), event.AbsorbChunkingProcessEndPayload{
@@ -167,8 +201,8 @@ func (t *ChunkingTask) Run(ctx context.Context, input any) (any, types.TokenUsag
 // 1. 文単位に分割（splitSentences）
 // 2. 文字数をカウントしながら、文単位でチャンクを構築
 // 3. オーバーラップを考慮して前のチャンクの末尾の文を次のチャンクの先頭に含める
-// 4. 各チャンクのembeddingを生成
-func (t *ChunkingTask) chunkText(text string, documentID string, memoryGroup string) ([]*storage.Chunk, types.TokenUsage, error) {
+// 4. 全チャンクのembeddingをまとめて生成（EmbedBatch）
+func (t *ChunkingTask) chunkText(ctx context.Context, text string, documentID string, memoryGroup string) ([]*storage.Chunk, types.TokenUsage, error) {
 	var usage types.TokenUsage
 	// 文単位に分割（文の途中で切れることを防ぐ）
 	sentences := splitSentences(text)
@@ -185,11 +219,8 @@ func (t *ChunkingTask) chunkText(text string, documentID string, memoryGroup str
 		sentenceChars := utf8.RuneCountInString(sentence)
 		// 現在のチャンクにこの文を追加するとサイズを超える場合
 		if currentChars+sentenceChars > t.ChunkSize && len(currentChunk) > 0 {
-			// 現在のチャンクを確定（embeddingを生成してchunksに追加）
-			if err := t.finalizeChunk(&currentChunk, &currentChars, &previousChunkSentences,
-				&chunks, &usage, memoryGroup, documentID); err != nil {
-				return nil, usage, err
-			}
+			// 現在のチャンクを確定（chunksに追加）
+			t.finalizeChunk(&currentChunk, &currentChars, &previousChunkSentences, &chunks, memoryGroup, documentID)
 			// オーバーラップ分（前のチャンクの末尾の文）を新しいチャンクの先頭に追加
 			t.addOverlap(&currentChunk, &currentChars, previousChunkSentences)
 		}
@@ -200,33 +231,37 @@ func (t *ChunkingTask) chunkText(text string, documentID string, memoryGroup str
 	}
 	// 最後のチャンクを確定
 	if len(currentChunk) > 0 {
-		if err := t.finalizeChunk(&currentChunk, &currentChars, &previousChunkSentences,
-			&chunks, &usage, memoryGroup, documentID); err != nil {
-			return nil, usage, err
-		}
+		t.finalizeChunk(&currentChunk, &currentChars, &previousChunkSentences, &chunks, memoryGroup, documentID)
+	}
+	// 全チャンクのembeddingをまとめて生成
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	embeddings, u, err := t.Embedder.EmbedBatch(ctx, texts)
+	usage.Add(u)
+	if err != nil {
+		return nil, usage, fmt.Errorf("Chunking: Failed to generate embeddings: %w", err)
+	}
+	for i, chunk := range chunks {
+		chunk.Embedding = embeddings[i]
 	}
 	return chunks, usage, nil
 }
 
-// finalizeChunk は現在のチャンクを確定してembeddingを生成し、chunksリストに追加します
+// finalizeChunk は現在のチャンクを確定し、chunksリストに追加します
+// embeddingは chunkText で全チャンクまとめて生成します
 func (t *ChunkingTask) finalizeChunk(
 	currentChunk *[]string,
 	currentChars *int,
 	previousChunkSentences *[]string,
 	chunks *[]*storage.Chunk,
-	usage *types.TokenUsage,
 	memoryGroup string,
 	documentID string,
-) error {
+) {
 	// チャンクのテキストを結合
 	chunkText := strings.Join(*currentChunk, "")
 	utils.LogDebug(t.Logger, "ChunkingTask: Finalizing chunk", zap.Int("index", len(*chunks)), zap.Int("chars", *currentChars))
-	// embeddingを生成
-	embedding, u, err := t.Embedder.EmbedQuery(context.Background(), chunkText)
-	usage.Add(u)
-	if err != nil {
-		return fmt.Errorf("Chunking: Failed to generate embedding: %w", err)
-	}
 
 	// Emit Absorb Keywords Start
 	eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_KEYWORDS_START), event.AbsorbKeywordsStartPayload{
@@ -255,7 +290,6 @@ func (t *ChunkingTask) finalizeChunk(
 		Nouns:       kwRes.Nouns,
 		NounsVerbs:  kwRes.NounsVerbs,
 		ChunkIndex:  len(*chunks),
-		Embedding:   embedding,
 	})
 	// 次のオーバーラップ用に現在のチャンクの文を保存
 	*previousChunkSentences = make([]string, len(*currentChunk))
@@ -263,7 +297,6 @@ func (t *ChunkingTask) finalizeChunk(
 	// 現在のチャンクをリセット
 	*currentChunk = []string{}
 	*currentChars = 0
-	return nil
 }
 
 // addOverlap は前のチャンクから ChunkOverlap 分の文字数になるまで、
@@ -326,3 +359,69 @@ func splitSentences(text string) []string {
 	}
 	return sentences
 }
+
+// normalizeSections は、抽出結果の各セクションを正規化して1つのドキュメントテキストに結合します。
+// 返り値のセクション情報には、正規化後のテキスト上の位置（ルーン単位の start/end）を含めます。
+// マークアップを含み得るテキスト（Markup=true）のみ CommonNormalize（HTML/Markdown除去、Boilerplate削除）を適用します。
+func normalizeSections(extracted *extractor.Extracted) (string, []map[string]any) {
+	var sb strings.Builder
+	var sections []map[string]any
+	offset := 0
+	for _, sec := range extracted.Sections {
+		var normalized string
+		if extracted.Markup {
+			// 1. 共通正規化 (HTML除去、Boilerplate削除)
+			// 2. Vector用正規化
+			normalized = utils.NormalizeForVector(utils.CommonNormalize(sec.Text))
+		} else {
+			// 抽出済みのプレーンテキストは、改行で単語が結合されないよう行を連結してから正規化
+			normalized = utils.NormalizeForVector(joinLines(sec.Text))
+		}
+		if normalized == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+			offset++
+		}
+		sb.WriteString(normalized)
+		length := utf8.RuneCountInString(normalized)
+		// 単一のテキストセクションは位置情報を持たせる意味が無いため記録しない
+		if sec.Kind != extractor.SECTION_KIND_TEXT {
+			section := map[string]any{
+				"kind":  sec.Kind,
+				"index": sec.Index,
+				"start": offset,
+				"end":   offset + length,
+			}
+			if sec.Name != "" {
+				section["name"] = sec.Name
+			}
+			sections = append(sections, section)
+		}
+		offset += length
+	}
+	return sb.String(), sections
+}
+
+// joinLines は、改行で区切られた行を連結します。
+// 前後が英数字の場合のみ空白を挟み、日本語の行折り返しには空白を入れません。
+func joinLines(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(sb.String())
+			next, _ := utf8.DecodeRuneInString(line)
+			if prev < utf8.RuneSelf || next < utf8.RuneSelf {
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
@@ -0,0 +1,363 @@
+// Package community は、知識グラフのコミュニティ検出と要約を行うタスクを提供します。
+// 検出したコミュニティの要約は、グローバル検索 (QUERY_TYPE_GLOBAL) で
+// 「取り込んだ知識全体の主なテーマは何か」のような、知識全体にわたる質問に回答するために使用されます。
+package community
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"go.uber.org/zap"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// CommunityTask は、コミュニティの更新タスクを表します。
+// Absorb / Memify の完了後に実行され、以下の処理を行います：
+//  1. GraphEdge を Thickness で重み付けしたグラフを読み込む
+//  2. Louvain 法で階層的なコミュニティを検出する
+//  3. 各コミュニティのタイトルと要約を LLM で生成する（所属ノードと内部のエッジが変わっていなければ以前の要約を再利用する）
+//  4. メモリーグループのコミュニティを置き換える
+type CommunityTask struct {
+	GraphStorage storage.GraphStorage
+	LLM          model.ToolCallingChatModel
+	ModelName    string
+	MemoryGroup  string
+	IsEn         bool
+	Logger       *zap.Logger
+}
+
+// NewCommunityTask は、新しい CommunityTask を作成します。
+func NewCommunityTask(
+	graphStorage storage.GraphStorage,
+	llm model.ToolCallingChatModel,
+	modelName string,
+	memoryGroup string,
+	isEn bool,
+	logger *zap.Logger,
+) *CommunityTask {
+	return &CommunityTask{
+		GraphStorage: graphStorage,
+		LLM:          llm,
+		ModelName:    modelName,
+		MemoryGroup:  memoryGroup,
+		IsEn:         isEn,
+		Logger:       logger,
+	}
+}
+
+// weightedTriple は、Thickness を計算済みのトリプルです。
+type weightedTriple struct {
+	triple    *storage.Triple
+	thickness float64
+}
+
+// draft は、要約前のコミュニティです。
+type draft struct {
+	community *storage.Community
+	triples   []*weightedTriple // コミュニティ内のエッジ（太い順）
+	children  []*draft          // 1つ下の階層のコミュニティ
+}
+
+// communitySummary は、COMMUNITY_SUMMARY_*_PROMPT の出力です。
+type communitySummary struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// Run は、コミュニティを更新します。
+// 返り値:
+//   - communityCount: 保存したコミュニティ数
+//   - summarizedCount: 新たに LLM で要約したコミュニティ数
+//   - usage: トークン使用量
+//   - error: エラー
+func (t *CommunityTask) Run(ctx context.Context) (communityCount int, summarizedCount int, usage types.TokenUsage, err error) {
+	utils.LogInfo(t.Logger, "CommunityTask: Starting community refresh", zap.String("memory_group", t.MemoryGroup))
+	// ========================================
+	// 1. 重み付きグラフの読み込み
+	// ========================================
+	triples, err := t.loadTriples(ctx)
+	if err != nil {
+		return 0, 0, usage, err
+	}
+	edges := make([]utils.WeightedEdge, 0, len(triples))
+	for _, wt := range triples {
+		edges = append(edges, utils.WeightedEdge{
+			SourceID: wt.triple.Edge.SourceID,
+			TargetID: wt.triple.Edge.TargetID,
+			Weight:   wt.thickness,
+		})
+	}
+	// ========================================
+	// 2. コミュニティの検出
+	// ========================================
+	levels := utils.DetectCommunities(edges, appconfig.COMMUNITY_RESOLUTION, appconfig.COMMUNITY_MAX_LEVELS)
+	drafts := t.buildDrafts(levels, triples)
+	// ========================================
+	// 3. 要約の生成（細かい階層から順に）
+	// ========================================
+	existing, err := t.GraphStorage.GetCommunities(ctx, t.MemoryGroup, -1)
+	if err != nil {
+		return 0, 0, usage, fmt.Errorf("CommunityTask: Failed to get existing communities: %w", err)
+	}
+	previous := make(map[string]*storage.Community, len(existing))
+	for _, c := range existing {
+		if c.Summary != "" {
+			previous[c.Signature] = c
+		}
+	}
+	var communities []*storage.Community
+	for _, levelDrafts := range drafts {
+		for _, d := range levelDrafts {
+			if prev, ok := previous[d.community.Signature]; ok {
+				d.community.Title = prev.Title
+				d.community.Summary = prev.Summary
+			} else if summarizedCount < appconfig.COMMUNITY_MAX_SUMMARIES {
+				if ctx.Err() != nil {
+					return 0, summarizedCount, usage, context.Cause(ctx)
+				}
+				summary, u, err := t.summarize(ctx, d)
+				usage.Add(u)
+				if err != nil {
+					// 要約できなかったコミュニティは、要約なしで保存して次回の更新で再試行する
+					utils.LogWarn(t.Logger, "CommunityTask: Failed to summarize community", zap.String("id", d.community.ID), zap.Error(err))
+				} else {
+					d.community.Title = summary.Title
+					d.community.Summary = summary.Summary
+					summarizedCount++
+				}
+			}
+			communities = append(communities, d.community)
+		}
+	}
+	// ========================================
+	// 4. コミュニティの置き換え
+	// ========================================
+	if err := t.GraphStorage.ReplaceCommunities(ctx, t.MemoryGroup, communities); err != nil {
+		return 0, summarizedCount, usage, fmt.Errorf("CommunityTask: Failed to save communities: %w", err)
+	}
+	utils.LogInfo(t.Logger, "CommunityTask: Community refresh completed",
+		zap.Int("levels", len(levels)),
+		zap.Int("communities", len(communities)),
+		zap.Int("summarized", summarizedCount))
+	return len(communities), summarizedCount, usage, nil
+}
+
+// loadTriples は、メモリーグループの全てのトリプルを読み込み、Thickness を計算します。
+// Thickness が 0 以下のエッジと、チャンクのノードにつながるエッジは除外します。
+func (t *CommunityTask) loadTriples(ctx context.Context) ([]*weightedTriple, error) {
+	halfLifeDays := appconfig.DEFAULT_HALF_LIFE_DAYS
+	memoryGroupConfig, err := t.GraphStorage.GetMemoryGroupConfig(ctx, t.MemoryGroup)
+	if err != nil {
+		utils.LogWarn(t.Logger, "CommunityTask: Failed to get MemoryGroupConfig, using defaults", zap.Error(err))
+	} else if memoryGroupConfig != nil && memoryGroupConfig.HalfLifeDays > 0 {
+		halfLifeDays = memoryGroupConfig.HalfLifeDays
+	}
+	maxUnix, err := t.GraphStorage.GetMaxUnix(ctx, t.MemoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("CommunityTask: Failed to get max unix: %w", err)
+	}
+	if maxUnix == 0 {
+		return nil, nil // エッジがない
+	}
+	lambda := utils.CalculateLambda(halfLifeDays)
+	var weighted []*weightedTriple
+	pageSize := appconfig.METABOLISM_PAGE_SIZE
+	for offset := 0; ; offset += pageSize {
+		nodeIDs, err := t.GraphStorage.GetSourceNodeIDs(ctx, t.MemoryGroup, offset, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("CommunityTask: Failed to get source node IDs: %w", err)
+		}
+		if len(nodeIDs) == 0 {
+			break
+		}
+		triples, err := t.GraphStorage.GetTriplesBySourceIDs(ctx, nodeIDs, t.MemoryGroup)
+		if err != nil {
+			return nil, fmt.Errorf("CommunityTask: Failed to get triples: %w", err)
+		}
+		for _, triple := range triples {
+			if triple.Source == nil || triple.Target == nil || triple.Edge == nil {
+				continue
+			}
+			if triple.Source.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) || triple.Target.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) {
+				continue
+			}
+			thickness := utils.CalculateThickness(triple.Edge.Weight, triple.Edge.Confidence, triple.Edge.Unix, maxUnix, lambda)
+			if thickness <= 0 {
+				continue
+			}
+			weighted = append(weighted, &weightedTriple{triple: triple, thickness: thickness})
+		}
+	}
+	return weighted, nil
+}
+
+// buildDrafts は、検出したコミュニティから保存対象のコミュニティを作成します。
+// 返り値は Louvain の階層順（最も細かい階層が先頭）で、各階層内は Weight の大きい順です。
+// 保存する階層レベルは逆順で、最も粗い階層が Level 0 になります。
+func (t *CommunityTask) buildDrafts(levels []map[string]int, triples []*weightedTriple) [][]*draft {
+	now := common.GetNow()
+	drafts := make([][]*draft, len(levels))
+	byNumber := make([]map[int]*draft, len(levels))
+	for li, assignment := range levels {
+		level := len(levels) - 1 - li
+		members := make(map[int][]string)
+		for id, number := range assignment {
+			members[number] = append(members[number], id)
+		}
+		byNumber[li] = make(map[int]*draft)
+		for number, ids := range members {
+			if len(ids) < appconfig.COMMUNITY_MIN_SIZE {
+				continue
+			}
+			sort.Strings(ids)
+			byNumber[li][number] = &draft{
+				community: &storage.Community{
+					MemoryGroup: t.MemoryGroup,
+					Level:       level,
+					MemberIDs:   ids,
+					UpdatedAt:   now,
+				},
+			}
+		}
+		// コミュニティ内のエッジを集める
+		for _, wt := range triples {
+			source, target := assignment[wt.triple.Edge.SourceID], assignment[wt.triple.Edge.TargetID]
+			if source != target {
+				continue
+			}
+			if d, ok := byNumber[li][source]; ok {
+				d.triples = append(d.triples, wt)
+				d.community.Weight += wt.thickness
+			}
+		}
+		for _, d := range byNumber[li] {
+			sort.SliceStable(d.triples, func(i, j int) bool {
+				return d.triples[i].thickness > d.triples[j].thickness
+			})
+			d.community.Signature = t.signature(d)
+			d.community.ID = fmt.Sprintf("%s|%d|%s", t.MemoryGroup, level, d.community.Signature[:16])
+			drafts[li] = append(drafts[li], d)
+		}
+		sort.Slice(drafts[li], func(i, j int) bool {
+			if drafts[li][i].community.Weight != drafts[li][j].community.Weight {
+				return drafts[li][i].community.Weight > drafts[li][j].community.Weight
+			}
+			return drafts[li][i].community.ID < drafts[li][j].community.ID
+		})
+	}
+	// 親子関係を設定する（親は1つ粗い階層で、所属ノードを含むコミュニティ）
+	for li := 0; li+1 < len(levels); li++ {
+		for _, d := range drafts[li] {
+			parent, ok := byNumber[li+1][levels[li+1][d.community.MemberIDs[0]]]
+			if !ok {
+				continue
+			}
+			d.community.ParentID = parent.community.ID
+			parent.children = append(parent.children, d)
+		}
+	}
+	return drafts
+}
+
+// signature は、コミュニティの所属ノードと内部のエッジ、出力言語から署名を計算します。
+// 署名が同じコミュニティは、以前の要約を再利用できます。
+func (t *CommunityTask) signature(d *draft) string {
+	keys := make([]string, 0, len(d.triples))
+	for _, wt := range d.triples {
+		keys = append(keys, wt.triple.Edge.SourceID+"|"+wt.triple.Edge.Type+"|"+wt.triple.Edge.TargetID)
+	}
+	sort.Strings(keys)
+	lang := "ja"
+	if t.IsEn {
+		lang = "en"
+	}
+	h := sha256.New()
+	h.Write([]byte(lang + "\n"))
+	h.Write([]byte(strings.Join(d.community.MemberIDs, "\n") + "\n\n"))
+	h.Write([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// summarize は、コミュニティのタイトルと要約を LLM で生成します。
+// コミュニティ内の太いエッジ（最大 COMMUNITY_SUMMARY_MAX_TRIPLES 件）と、要約済みの子コミュニティの要約を入力とします。
+// LLM の出力を JSON として解析できない場合は、出力全体を要約として使用します。
+func (t *CommunityTask) summarize(ctx context.Context, d *draft) (summary communitySummary, usage types.TokenUsage, err error) {
+	triples := d.triples
+	if len(triples) > appconfig.COMMUNITY_SUMMARY_MAX_TRIPLES {
+		triples = triples[:appconfig.COMMUNITY_SUMMARY_MAX_TRIPLES]
+	}
+	var entities []string
+	seen := make(map[string]bool)
+	var facts strings.Builder
+	for _, wt := range triples {
+		for _, node := range []*storage.Node{wt.triple.Source, wt.triple.Target} {
+			if !seen[node.ID] {
+				seen[node.ID] = true
+				entities = append(entities, describeNode(node))
+			}
+		}
+		facts.WriteString(fmt.Sprintf("- %s -[%s]-> %s\n", nodeName(wt.triple.Source), wt.triple.Edge.Type, nodeName(wt.triple.Target)))
+	}
+	var userPrompt strings.Builder
+	userPrompt.WriteString(fmt.Sprintf("Entities (%d in total, showing those in the facts below):\n- %s\n\n", len(d.community.MemberIDs), strings.Join(entities, "\n- ")))
+	userPrompt.WriteString("Facts:\n" + facts.String())
+	var subReports []string
+	for _, child := range d.children {
+		if child.community.Summary != "" {
+			subReports = append(subReports, fmt.Sprintf("## %s\n%s", child.community.Title, child.community.Summary))
+		}
+	}
+	if len(subReports) > 0 {
+		userPrompt.WriteString("\nSub-community Reports:\n" + strings.Join(subReports, "\n\n") + "\n")
+	}
+	systemPrompt := prompts.Get(ctx, prompts.NAME_COMMUNITY_SUMMARY_JA)
+	if t.IsEn {
+		systemPrompt = prompts.Get(ctx, prompts.NAME_COMMUNITY_SUMMARY_EN)
+	}
+	content, usage, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt.String())
+	if err != nil {
+		return summary, usage, err
+	}
+	jsonStr := content
+	jsonStart := strings.Index(jsonStr, "{")
+	jsonEnd := strings.LastIndex(jsonStr, "}")
+	if jsonStart >= 0 && jsonEnd > jsonStart {
+		jsonStr = jsonStr[jsonStart : jsonEnd+1]
+	}
+	if errr := json.Unmarshal([]byte(jsonStr), &summary); errr != nil || summary.Summary == "" {
+		utils.LogWarn(t.Logger, "CommunityTask: Failed to parse community summary, using raw response", zap.String("id", d.community.ID))
+		summary = communitySummary{Summary: strings.TrimSpace(content)}
+	}
+	if summary.Summary == "" {
+		return summary, usage, fmt.Errorf("CommunityTask: Empty summary for community %s", d.community.ID)
+	}
+	return summary, usage, nil
+}
+
+// nodeName は、ノードの表示名を返します。
+func nodeName(node *storage.Node) string {
+	if name, ok := node.Properties["name"].(string); ok && name != "" {
+		return name
+	}
+	return utils.GetNameStrByGraphNodeID(node.ID)
+}
+
+// describeNode は、ノードの表示名とタイプを返します。
+func describeNode(node *storage.Node) string {
+	if node.Type == "" {
+		return nodeName(node)
+	}
+	return fmt.Sprintf("%s (%s)", nodeName(node), node.Type)
+}
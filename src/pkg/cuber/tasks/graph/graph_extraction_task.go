@@ -6,13 +6,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/pkg/cuber/checkpoint"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
 	"github.com/t-kawata/mycute/pkg/cuber/pipeline"
 	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
@@ -29,26 +33,55 @@ import (
 // GraphExtractionTask は、グラフ抽出タスクを表します。
 // LLMを使用してテキストからエンティティ（ノード）と関係（エッジ）を抽出します。
 type GraphExtractionTask struct {
-	LLM         model.ToolCallingChatModel // テキスト生成LLM (Eino)
-	ModelName   string                     // モデル名
-	MemoryGroup string                     // メモリグループ
-	Logger      *zap.Logger
-	EventBus    *eventbus.EventBus
-	IsEn        bool
+	LLM                 model.ToolCallingChatModel // テキスト生成LLM (Eino)
+	ModelName           string                     // モデル名
+	MemoryGroup         string                     // メモリグループ
+	Logger              *zap.Logger
+	EventBus            *eventbus.EventBus
+	IsEn                bool
+	Checkpoints         *checkpoint.Store // チャンクごとの抽出結果の保存先（nil の場合は保存・復元しない）
+	MaxRetries          int               // 抽出に失敗したチャンクを再試行する回数
+	RetryBackoff        time.Duration     // 最初の再試行までの待機時間（再試行のたびに2倍）
+	MaxFailedChunkRatio float64           // 再試行しても失敗したチャンクをスキップして続行できる割合（0〜1）
 }
 
 // NewGraphExtractionTask は、新しいGraphExtractionTaskを作成します。
-func NewGraphExtractionTask(llm model.ToolCallingChatModel, modelName string, memoryGroup string, l *zap.Logger, eb *eventbus.EventBus, isEn bool) *GraphExtractionTask {
+// checkpoints: 前回失敗した Absorb で抽出済みのチャンクを再利用するためのチェックポイント（nil 可）
+// maxRetries: 抽出に失敗したチャンクを再試行する回数
+// retryBackoff: 最初の再試行までの待機時間（再試行のたびに2倍）
+// maxFailedChunkRatio: 再試行しても失敗したチャンクをスキップして続行できる割合（0の場合は1チャンクでも失敗したらエラー）
+func NewGraphExtractionTask(llm model.ToolCallingChatModel, modelName string, memoryGroup string, l *zap.Logger, eb *eventbus.EventBus, isEn bool, checkpoints *checkpoint.Store, maxRetries int, retryBackoff time.Duration, maxFailedChunkRatio float64) *GraphExtractionTask {
 	if modelName == "" {
 		modelName = "gpt-4o-mini" // Default fallback
 	}
-	return &GraphExtractionTask{LLM: llm, ModelName: modelName, MemoryGroup: memoryGroup, Logger: l, EventBus: eb, IsEn: isEn}
+	return &GraphExtractionTask{
+		LLM:                 llm,
+		ModelName:           modelName,
+		MemoryGroup:         memoryGroup,
+		Logger:              l,
+		EventBus:            eb,
+		IsEn:                isEn,
+		Checkpoints:         checkpoints,
+		MaxRetries:          max(maxRetries, 0),
+		RetryBackoff:        retryBackoff,
+		MaxFailedChunkRatio: min(max(maxFailedChunkRatio, 0), 1),
+	}
 }
 
 var _ pipeline.Task = (*GraphExtractionTask)(nil)
 
+// skippedChunk は、再試行してもグラフ抽出に失敗したチャンクです。
+type skippedChunk struct {
+	chunk    *storage.Chunk
+	chunkNum int
+	attempts int
+	err      error
+}
+
 // Run は、グラフ抽出タスクを実行します。
 // 各チャンクに対して並行してLLMを呼び出し、グラフデータを抽出します。
+// 失敗したチャンクは MaxRetries 回まで再試行し、それでも失敗したチャンクが MaxFailedChunkRatio 以下であればスキップして続行します。
+// 抽出に成功したチャンクの結果はチェックポイントに保存されるため、エラーで終了しても再実行時には失敗したチャンクだけが抽出されます。
 func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.TokenUsage, error) {
 	var totalUsage types.TokenUsage
 	chunks, ok := input.([]*storage.Chunk)
@@ -58,13 +91,16 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 	var (
 		allNodes []*storage.Node
 		allEdges []*storage.Edge
+		skipped  []skippedChunk
+		restored int
 		mu       sync.Mutex // ノードとエッジのリストへの並行アクセスを保護
 	)
 	// errgroup: 並行処理とエラーハンドリング
+	// チャンクの失敗では他のチャンクを止めない（抽出できたチャンクをチェックポイントに残すため）ので、エラーを返すのはキャンセル時のみ
 	g, ctx := errgroup.WithContext(ctx)
 	// 並行数を制限（レート制限を避けるため）
 	g.SetLimit(5)
-	utils.LogInfo(t.Logger, "GraphExtractionTask: Starting", zap.Int("chunks", len(chunks)), zap.String("model", t.ModelName))
+	utils.LogInfo(t.Logger, "GraphExtractionTask: Starting", zap.Int("chunks", len(chunks)), zap.String("model", t.ModelName), zap.Int("max_retries", t.MaxRetries))
 	for i, chunk := range chunks {
 		// ========================================
 		// 0. キャンセルチェック
@@ -76,60 +112,58 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 		}
 
 		chunk := chunk // ループ変数をキャプチャ
+		chunkNum := i + 1
 		g.Go(func() error {
 			// ========================================
-			// 1. プロンプトを作ってLLMを呼び出し (Eino)
+			// 1. 前回の Absorb で抽出済みであれば再利用
 			// ========================================
-			prompt := fmt.Sprintf("Extract a knowledge graph from the following text:\n\n%s", chunk.Text)
-			utils.LogDebug(t.Logger, "GraphExtractionTask: Sending request to LLM", zap.String("chunk_id", chunk.ID), zap.Int("prompt_len", len(prompt)))
-
-			// Emit Graph Request Start
-			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_REQUEST_START), event.AbsorbGraphRequestStartPayload{
-				BasePayload: event.NewBasePayload(t.MemoryGroup),
-				ChunkID:     chunk.ID,
-				ChunkNum:    i + 1,
-			})
-
-			// Select prompt based on language mode
-			var promptTemplate string
-			if t.IsEn {
-				promptTemplate = prompts.GENERATE_GRAPH_EN_PROMPT
+			graphData, ok := t.Checkpoints.LoadGraph(chunk)
+			if ok {
+				mu.Lock()
+				restored++
+				mu.Unlock()
 			} else {
-				promptTemplate = prompts.GENERATE_GRAPH_JA_PROMPT
+				// ========================================
+				// 2. LLMで抽出（失敗した場合はバックオフして再試行）
+				// ========================================
+				var (
+					attempts int
+					err      error
+				)
+				backoff := t.RetryBackoff
+				for attempts = 1; ; attempts++ {
+					var usage types.TokenUsage
+					graphData, usage, err = t.extractGraph(ctx, chunk, chunkNum)
+					mu.Lock()
+					totalUsage.Add(usage)
+					mu.Unlock()
+					if err == nil || ctx.Err() != nil || attempts > t.MaxRetries {
+						break
+					}
+					utils.LogWarn(t.Logger, "GraphExtractionTask: Retrying chunk", zap.String("chunk_id", chunk.ID), zap.Int("attempt", attempts), zap.Duration("backoff", backoff), zap.Error(err))
+					select {
+					case <-ctx.Done():
+					case <-time.After(backoff):
+					}
+					backoff *= 2
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err != nil {
+					mu.Lock()
+					skipped = append(skipped, skippedChunk{chunk: chunk, chunkNum: chunkNum, attempts: attempts, err: err})
+					mu.Unlock()
+					return nil
+				}
+				// チェックポイントの保存に失敗しても Absorb は続行する（再実行時に抽出し直すだけ）
+				if err := t.Checkpoints.SaveGraph(chunk, graphData); err != nil {
+					utils.LogWarn(t.Logger, "GraphExtractionTask: Failed to save checkpoint", zap.String("chunk_id", chunk.ID), zap.Error(err))
+				}
 			}
-			content, chunkUsage, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, promptTemplate, prompt)
-
-			// Emit Graph Request End
-			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_REQUEST_END), event.AbsorbGraphRequestEndPayload{
-				BasePayload: event.NewBasePayload(t.MemoryGroup),
-				ChunkID:     chunk.ID,
-				ChunkNum:    i + 1,
-			})
-
-			if err != nil {
-				utils.LogWarn(t.Logger, "GraphExtractionTask: LLM call failed", zap.Error(err))
-				return fmt.Errorf("LLM call failed: %w", err)
-			}
-			if content == "" {
-				return fmt.Errorf("no response from LLM")
-			}
-			utils.LogDebug(t.Logger, "GraphExtractionTask: Received response from LLM", zap.String("chunk_id", chunk.ID), zap.Int("response_len", len(content)))
-			// ========================================
-			// 2. JSONをパース
-			// ========================================
-			// Emit Graph Parse Start (implicit in logic but good to track)
-			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_PARSE_START), event.AbsorbGraphParseStartPayload{
-				BasePayload: event.NewBasePayload(t.MemoryGroup),
-				ChunkID:     chunk.ID,
-				ChunkNum:    i + 1,
-			})
-
-			content = cleanJSON(content) // JSONオブジェクト部分だけ取り出す
-			var graphData storage.GraphData
-			if err := json.Unmarshal([]byte(content), &graphData); err != nil {
-				// パースエラーの場合は失敗
-				utils.LogWarn(t.Logger, "GraphExtractionTask: JSON parse failed", zap.String("content", content), zap.Error(err))
-				return fmt.Errorf("Failed to parse Graph Data JSON: %w\nContent: %s", err, content)
+			// 抽出元チャンクを記録（StorageTask で出典として保存される）
+			for _, edge := range graphData.Edges {
+				edge.ChunkID = chunk.ID
 			}
 			// ========================================
 			// 3. 結果を集約
@@ -137,18 +171,8 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 			mu.Lock()
 			allNodes = append(allNodes, graphData.Nodes...)
 			allEdges = append(allEdges, graphData.Edges...)
-			totalUsage.Add(chunkUsage)
 			mu.Unlock()
 			utils.LogDebug(t.Logger, "GraphExtractionTask: Extracted graph from chunk", zap.Int("nodes", len(graphData.Nodes)), zap.Int("edges", len(graphData.Edges)))
-
-			// Emit Graph Parse End
-			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_PARSE_END), event.AbsorbGraphParseEndPayload{
-				BasePayload:    event.NewBasePayload(t.MemoryGroup),
-				ChunkID:        chunk.ID,
-				ChunkNum:       i + 1,
-				NodesExtracted: len(graphData.Nodes),
-				EdgesExtracted: len(graphData.Edges),
-			})
 			return nil
 		})
 	}
@@ -156,6 +180,32 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 	if err := g.Wait(); err != nil {
 		return nil, totalUsage, err
 	}
+	if restored > 0 {
+		utils.LogInfo(t.Logger, "GraphExtractionTask: Restored graphs from checkpoint", zap.Int("restored", restored), zap.Int("chunks", len(chunks)))
+	}
+	// ========================================
+	// 4. 失敗したチャンクの許容判定
+	// ========================================
+	if len(skipped) > 0 {
+		sort.Slice(skipped, func(i, j int) bool { return skipped[i].chunkNum < skipped[j].chunkNum })
+		allowed := int(float64(len(chunks)) * t.MaxFailedChunkRatio)
+		if len(skipped) > allowed {
+			return nil, totalUsage, fmt.Errorf(
+				"Graph Extraction: Failed to extract graph from %d of %d chunks (allowed: %d). Extracted chunks are checkpointed and will be reused on retry. First error (chunk %d): %w",
+				len(skipped), len(chunks), allowed, skipped[0].chunkNum, skipped[0].err,
+			)
+		}
+		for _, s := range skipped {
+			utils.LogWarn(t.Logger, "GraphExtractionTask: Skipped chunk", zap.String("chunk_id", s.chunk.ID), zap.Int("attempts", s.attempts), zap.Error(s.err))
+			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_CHUNK_SKIPPED), event.AbsorbGraphChunkSkippedPayload{
+				BasePayload: event.NewBasePayload(t.MemoryGroup),
+				ChunkID:     s.chunk.ID,
+				ChunkNum:    s.chunkNum,
+				Attempts:    s.attempts,
+				Error:       s.err.Error(),
+			})
+		}
+	}
 	// 知識グラフのノードとエッジを説明文に変換
 	triples, err := storage.ConvertNodesAndEdgesToTriples(&allNodes, &allEdges)
 	if err != nil {
@@ -219,6 +269,18 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 		allEdges[i].Confidence = 1.0
 		allEdges[i].Unix = *common.GetNowUnixMilli() // 現在時刻（ミリ秒）を注入
 	}
+	// ========================================
+	// オントロジーの適用（タイプの正規化・定義に反するノード/エッジの除外）
+	// ========================================
+	if onto := ontology.FromContext(ctx); onto != nil {
+		var report ontology.Report
+		allNodes, allEdges, report = onto.Apply(allNodes, allEdges)
+		utils.LogInfo(t.Logger, "GraphExtractionTask: Applied ontology",
+			zap.Int("normalized_nodes", report.NormalizedNodes),
+			zap.Int("normalized_edges", report.NormalizedEdges),
+			zap.Int("rejected_nodes", report.RejectedNodes),
+			zap.Int("rejected_edges", report.RejectedEdges))
+	}
 	return &storage.CognifyOutput{
 		Chunks: chunks,
 		GraphData: &storage.GraphData{
@@ -228,6 +290,81 @@ func (t *GraphExtractionTask) Run(ctx context.Context, input any) (any, types.To
 	}, totalUsage, nil
 }
 
+// extractGraph は、1つのチャンクからLLMでグラフを抽出します（正規化前）。
+//
+// 返り値:
+//   - *storage.GraphData: 抽出したグラフ
+//   - types.TokenUsage: LLMのトークン使用量（失敗した場合も含む）
+//   - error: LLMの呼び出し、またはJSONのパースに失敗した場合
+func (t *GraphExtractionTask) extractGraph(ctx context.Context, chunk *storage.Chunk, chunkNum int) (*storage.GraphData, types.TokenUsage, error) {
+	// ========================================
+	// 1. プロンプトを作ってLLMを呼び出し (Eino)
+	// ========================================
+	prompt := fmt.Sprintf("Extract a knowledge graph from the following text:\n\n%s", chunk.Text)
+	utils.LogDebug(t.Logger, "GraphExtractionTask: Sending request to LLM", zap.String("chunk_id", chunk.ID), zap.Int("prompt_len", len(prompt)))
+
+	// Emit Graph Request Start
+	eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_REQUEST_START), event.AbsorbGraphRequestStartPayload{
+		BasePayload: event.NewBasePayload(t.MemoryGroup),
+		ChunkID:     chunk.ID,
+		ChunkNum:    chunkNum,
+	})
+
+	// Select prompt based on language mode
+	var promptTemplate string
+	if t.IsEn {
+		promptTemplate = prompts.Get(ctx, prompts.NAME_GENERATE_GRAPH_EN)
+	} else {
+		promptTemplate = prompts.Get(ctx, prompts.NAME_GENERATE_GRAPH_JA)
+	}
+	// オントロジーが定義されている場合は、使用できるタイプをプロンプトに追加
+	promptTemplate += ontology.FromContext(ctx).PromptSection(t.IsEn)
+	content, usage, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, promptTemplate, prompt)
+
+	// Emit Graph Request End
+	eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_REQUEST_END), event.AbsorbGraphRequestEndPayload{
+		BasePayload: event.NewBasePayload(t.MemoryGroup),
+		ChunkID:     chunk.ID,
+		ChunkNum:    chunkNum,
+	})
+
+	if err != nil {
+		utils.LogWarn(t.Logger, "GraphExtractionTask: LLM call failed", zap.Error(err))
+		return nil, usage, fmt.Errorf("LLM call failed: %w", err)
+	}
+	if content == "" {
+		return nil, usage, fmt.Errorf("no response from LLM")
+	}
+	utils.LogDebug(t.Logger, "GraphExtractionTask: Received response from LLM", zap.String("chunk_id", chunk.ID), zap.Int("response_len", len(content)))
+	// ========================================
+	// 2. JSONをパース
+	// ========================================
+	// Emit Graph Parse Start (implicit in logic but good to track)
+	eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_PARSE_START), event.AbsorbGraphParseStartPayload{
+		BasePayload: event.NewBasePayload(t.MemoryGroup),
+		ChunkID:     chunk.ID,
+		ChunkNum:    chunkNum,
+	})
+
+	content = cleanJSON(content) // JSONオブジェクト部分だけ取り出す
+	var graphData storage.GraphData
+	if err := json.Unmarshal([]byte(content), &graphData); err != nil {
+		// パースエラーの場合は失敗
+		utils.LogWarn(t.Logger, "GraphExtractionTask: JSON parse failed", zap.String("content", content), zap.Error(err))
+		return nil, usage, fmt.Errorf("Failed to parse Graph Data JSON: %w\nContent: %s", err, content)
+	}
+
+	// Emit Graph Parse End
+	eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_GRAPH_PARSE_END), event.AbsorbGraphParseEndPayload{
+		BasePayload:    event.NewBasePayload(t.MemoryGroup),
+		ChunkID:        chunk.ID,
+		ChunkNum:       chunkNum,
+		NodesExtracted: len(graphData.Nodes),
+		EdgesExtracted: len(graphData.Edges),
+	})
+	return &graphData, usage, nil
+}
+
 // cleanJSON は、LLMの出力から最初の{から最後の}までのJSON部分を抽出します。
 // オブジェクト型のJSON部分だけを確実に取り出します。
 func cleanJSON(content string) string {
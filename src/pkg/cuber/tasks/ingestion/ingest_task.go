@@ -10,10 +10,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/t-kawata/mycute/pkg/cuber/extractor"
 	"github.com/t-kawata/mycute/pkg/cuber/pipeline"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
@@ -31,6 +33,7 @@ import (
 type IngestTask struct {
 	vectorStorage storage.VectorStorage // ベクトルストレージ（LadybugDB）
 	memoryGroup   string                // メモリーグループ（パーティション識別子）
+	sourceID      string                // 呼び出し側が付与する論理的な文書ID（空なら未指定）
 	s3Client      *s3client.S3Client    // S3クライアント
 	Logger        *zap.Logger
 	EventBus      *eventbus.EventBus
@@ -40,14 +43,16 @@ type IngestTask struct {
 // 引数:
 //   - vectorStorage: ベクトルストレージ
 //   - memoryGroup: メモリーグループ（"user-dataset"形式）
+//   - sourceID: 論理的な文書ID（同じIDで再度取り込むと旧版が置き換えられる。空なら未指定）
 //   - s3Client: S3クライアント
 //
 // 返り値:
 //   - *IngestTask: 新しいIngestTaskインスタンス
-func NewIngestTask(vectorStorage storage.VectorStorage, memoryGroup string, s3Client *s3client.S3Client, l *zap.Logger, eb *eventbus.EventBus) *IngestTask {
+func NewIngestTask(vectorStorage storage.VectorStorage, memoryGroup string, sourceID string, s3Client *s3client.S3Client, l *zap.Logger, eb *eventbus.EventBus) *IngestTask {
 	return &IngestTask{
 		vectorStorage: vectorStorage,
 		memoryGroup:   memoryGroup,
+		sourceID:      sourceID,
 		s3Client:      s3Client,
 		Logger:        l,
 		EventBus:      eb,
@@ -179,8 +184,10 @@ func (t *IngestTask) Run(ctx context.Context, input any) (any, types.TokenUsage,
 			ID:              dataID,
 			MemoryGroup:     t.memoryGroup, // パーティションID
 			Name:            filepath.Base(path),
-			Extension:       filepath.Ext(path),
+			Extension:       strings.ToLower(filepath.Ext(path)),
+			MimeType:        extractor.DetectMimeType(path), // 抽出器の選択に使用
 			ContentHash:     hash,
+			SourceID:        t.sourceID,
 			RawDataLocation: *storageKey, // 保存された場所のキーを記録
 			CreatedAt:       time.Now(),
 		}
@@ -136,14 +136,14 @@ func (t *RuleExtractionTask) ProcessBatch(ctx context.Context, texts []string) (
 	// ========================================
 	// 3. LLMでルールを抽出 (Eino)
 	// ========================================
-	userPrompt := fmt.Sprintf(prompts.RuleExtractionUserPromptTemplate, combinedText, existingRules)
+	userPrompt := fmt.Sprintf(prompts.Get(ctx, prompts.NAME_RULE_EXTRACTION_USER), combinedText, existingRules)
 
 	// Select prompt based on language mode
 	var systemPrompt string
 	if t.IsEn {
-		systemPrompt = prompts.RuleExtractionSystemPromptEN
+		systemPrompt = prompts.Get(ctx, prompts.NAME_RULE_EXTRACTION_EN)
 	} else {
-		systemPrompt = prompts.RuleExtractionSystemPromptJA
+		systemPrompt = prompts.Get(ctx, prompts.NAME_RULE_EXTRACTION_JA)
 	}
 	responseText, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
 	totalUsage.Add(u)
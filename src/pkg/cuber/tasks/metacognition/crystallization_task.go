@@ -375,7 +375,7 @@ func (t *CrystallizationTask) mergTexts(ctx context.Context, texts []string) (st
 	prompt := fmt.Sprintf("以下の複数の知識を1つの包括的な記述に統合してください:\n\n%s",
 		joinWithNumbers(texts))
 
-	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.KnowledgeCrystallizationSystemPrompt, prompt)
+	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_KNOWLEDGE_CRYSTALLIZATION), prompt)
 	usage.Add(u)
 	if err != nil {
 		return "", usage, err
@@ -0,0 +1,132 @@
+package metacognition
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/t-kawata/mycute/lib/common"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// FeedbackResult は、フィードバックの適用結果です。
+type FeedbackResult struct {
+	Strengthened int // 強化されたエッジ数
+	Weakened     int // 弱化されたエッジ数（削除されたものは含まない）
+	Pruned       int // 生存スコアが淘汰閾値を下回り削除されたエッジ数
+	Missing      int // 既に存在しなかったエッジ数（回答後に削除・置換されたもの）
+}
+
+// FeedbackTask は、クエリの回答に対するユーザーのフィードバックを、
+// 回答の根拠として使用されたエッジの代謝（Weight / Confidence）に反映するタスクです。
+// 代謝モデルは GraphRefinementTask と同じ（Alpha で強化、Delta で減衰、S = W × C で淘汰）です。
+type FeedbackTask struct {
+	GraphStorage storage.GraphStorage
+	MemoryGroup  string
+	Config       MetabolismConfig
+	Logger       *zap.Logger
+}
+
+// NewFeedbackTask は、新しいFeedbackTaskを作成します。
+func NewFeedbackTask(
+	graphStorage storage.GraphStorage,
+	memoryGroup string,
+	alpha, delta, pruneThreshold float64,
+	l *zap.Logger,
+) *FeedbackTask {
+	return &FeedbackTask{
+		GraphStorage: graphStorage,
+		MemoryGroup:  memoryGroup,
+		Config: MetabolismConfig{
+			Alpha:          alpha,
+			Delta:          delta,
+			PruneThreshold: pruneThreshold,
+		},
+		Logger: l,
+	}
+}
+
+// ApplyFeedback は、指定されたエッジにフィードバックを適用します。
+//
+// 処理の流れ:
+//   - positive = true の場合: Confidence を Alpha、Weight を Alpha × 0.5 だけ増加させ、タイムスタンプを現在時刻に更新する
+//     （ユーザーに支持されたことを、新たな観測として扱う）
+//   - positive = false の場合: Confidence を Delta だけ減少させる（タイムスタンプは更新しない）
+//     生存スコア S = W × C が PruneThreshold を下回った場合はエッジを削除する
+//
+// エッジは SourceID, Type, TargetID の組み合わせで特定します。
+// 回答後に削除・置換されて存在しないエッジは、Missing として数えて無視します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - edges: フィードバックの対象となるエッジ（SourceID, Type, TargetID のみ使用）
+//   - positive: true の場合は強化、false の場合は弱化
+//
+// 返り値:
+//   - FeedbackResult: 適用結果
+//   - error: エラーが発生した場合
+func (t *FeedbackTask) ApplyFeedback(ctx context.Context, edges []*storage.Edge, positive bool) (FeedbackResult, error) {
+	var result FeedbackResult
+	nowUnix := *common.GetNowUnixMilli()
+	seen := make(map[string]bool, len(edges))
+	for _, edge := range edges {
+		key := edge.SourceID + "|" + edge.Type + "|" + edge.TargetID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		currentEdge, err := t.findEdge(ctx, edge.SourceID, edge.Type, edge.TargetID)
+		if err != nil {
+			return result, err
+		}
+		if currentEdge == nil {
+			result.Missing++
+			continue
+		}
+		newWeight := currentEdge.Weight
+		newConfidence := currentEdge.Confidence
+		unix := currentEdge.Unix
+		if positive {
+			newConfidence = min(1.0, currentEdge.Confidence+t.Config.Alpha)
+			newWeight = min(1.0, currentEdge.Weight+t.Config.Alpha*0.5)
+			unix = nowUnix
+		} else {
+			newConfidence = max(0.0, currentEdge.Confidence-t.Config.Delta)
+			// 生存スコアが淘汰閾値を下回った場合は削除
+			if survivalScore := newWeight * newConfidence; survivalScore < t.Config.PruneThreshold {
+				if err := t.GraphStorage.DeleteEdge(ctx, edge.SourceID, edge.Type, edge.TargetID, t.MemoryGroup); err != nil {
+					return result, fmt.Errorf("FeedbackTask: Failed to prune edge: %w", err)
+				}
+				utils.LogDebug(t.Logger, "FeedbackTask: Pruned edge", zap.String("from", edge.SourceID), zap.String("type", edge.Type), zap.String("to", edge.TargetID), zap.Float64("score", survivalScore))
+				result.Pruned++
+				continue
+			}
+		}
+		if err := t.GraphStorage.UpdateEdgeMetrics(ctx, edge.SourceID, edge.Type, edge.TargetID, t.MemoryGroup, newWeight, newConfidence, unix); err != nil {
+			return result, fmt.Errorf("FeedbackTask: Failed to update edge metrics: %w", err)
+		}
+		utils.LogDebug(t.Logger, "FeedbackTask: Updated edge", zap.String("from", edge.SourceID), zap.String("type", edge.Type), zap.String("to", edge.TargetID), zap.Bool("positive", positive), zap.Float64("old_conf", currentEdge.Confidence), zap.Float64("new_conf", newConfidence))
+		if positive {
+			result.Strengthened++
+		} else {
+			result.Weakened++
+		}
+	}
+	return result, nil
+}
+
+// findEdge は、SourceID, Type, TargetID が一致する現在のエッジを取得します。存在しない場合は nil を返します。
+func (t *FeedbackTask) findEdge(ctx context.Context, sourceID, edgeType, targetID string) (*storage.Edge, error) {
+	edges, err := t.GraphStorage.GetEdgesByNode(ctx, sourceID, t.MemoryGroup)
+	if err != nil {
+		return nil, fmt.Errorf("FeedbackTask: Failed to get edges: %w", err)
+	}
+	fullTargetID := utils.EnsureFullGraphNodeID(targetID, t.MemoryGroup)
+	for _, e := range edges {
+		if e.Type == edgeType && utils.EnsureFullGraphNodeID(e.TargetID, t.MemoryGroup) == fullTargetID {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,144 @@
+package metacognition
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/consts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+const testMemoryGroup = "g1"
+
+// feedbackGraphStorage は、FeedbackTask が使用するメソッドだけを実装したテスト用のグラフストレージです。
+type feedbackGraphStorage struct {
+	storage.GraphStorage
+	edges   []*storage.Edge
+	updated map[string]storage.Edge // キー: SourceID|Type|TargetID
+	deleted []string
+}
+
+func (s *feedbackGraphStorage) GetEdgesByNode(ctx context.Context, nodeID string, memoryGroup string) ([]*storage.Edge, error) {
+	var edges []*storage.Edge
+	for _, e := range s.edges {
+		if e.SourceID == nodeID || e.TargetID == nodeID {
+			copied := *e
+			edges = append(edges, &copied)
+		}
+	}
+	return edges, nil
+}
+
+func (s *feedbackGraphStorage) UpdateEdgeMetrics(ctx context.Context, sourceID, edgeType, targetID, memoryGroup string, weight, confidence float64, unix int64) error {
+	s.updated[sourceID+"|"+edgeType+"|"+targetID] = storage.Edge{Weight: weight, Confidence: confidence, Unix: unix}
+	return nil
+}
+
+func (s *feedbackGraphStorage) DeleteEdge(ctx context.Context, sourceID, edgeType, targetID, memoryGroup string) error {
+	s.deleted = append(s.deleted, sourceID+"|"+edgeType+"|"+targetID)
+	return nil
+}
+
+func TestApplyFeedback(t *testing.T) {
+	const observedAt = int64(1700000000000)
+	acme := "acme" + consts.ID_MEMORY_GROUP_SEPARATOR + testMemoryGroup
+	worksAt := func(weight, confidence float64) []*storage.Edge {
+		return []*storage.Edge{{SourceID: "alice", TargetID: acme, Type: "WORKS_AT", Weight: weight, Confidence: confidence, Unix: observedAt}}
+	}
+	target := []*storage.Edge{{SourceID: "alice", TargetID: "acme", Type: "WORKS_AT"}}
+	tests := []struct {
+		name           string
+		stored         []*storage.Edge
+		feedback       []*storage.Edge
+		positive       bool
+		want           FeedbackResult
+		wantWeight     float64
+		wantConfidence float64
+		wantRenewed    bool // タイムスタンプが現在時刻に更新されるかどうか
+	}{
+		{
+			name:           "positive feedback strengthens the edge",
+			stored:         worksAt(0.5, 0.5),
+			feedback:       target,
+			positive:       true,
+			want:           FeedbackResult{Strengthened: 1},
+			wantWeight:     0.6,
+			wantConfidence: 0.7,
+			wantRenewed:    true,
+		},
+		{
+			name:           "positive feedback is capped at 1",
+			stored:         worksAt(0.95, 0.9),
+			feedback:       target,
+			positive:       true,
+			want:           FeedbackResult{Strengthened: 1},
+			wantWeight:     1.0,
+			wantConfidence: 1.0,
+			wantRenewed:    true,
+		},
+		{
+			name:           "negative feedback weakens the edge",
+			stored:         worksAt(0.5, 0.5),
+			feedback:       target,
+			want:           FeedbackResult{Weakened: 1},
+			wantWeight:     0.5,
+			wantConfidence: 0.4,
+		},
+		{
+			name:     "negative feedback prunes the edge",
+			stored:   worksAt(0.2, 0.3),
+			feedback: target,
+			want:     FeedbackResult{Pruned: 1},
+		},
+		{
+			name:     "missing edge",
+			stored:   worksAt(0.5, 0.5),
+			feedback: []*storage.Edge{{SourceID: "alice", TargetID: "acme", Type: "FOUNDED"}},
+			positive: true,
+			want:     FeedbackResult{Missing: 1},
+		},
+		{
+			name:           "duplicate edges are applied once",
+			stored:         worksAt(0.5, 0.5),
+			feedback:       append(target, target...),
+			positive:       true,
+			want:           FeedbackResult{Strengthened: 1},
+			wantWeight:     0.6,
+			wantConfidence: 0.7,
+			wantRenewed:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := &feedbackGraphStorage{edges: tt.stored, updated: map[string]storage.Edge{}}
+			task := NewFeedbackTask(graph, testMemoryGroup, 0.2, 0.1, 0.1, nil)
+			got, err := task.ApplyFeedback(context.Background(), tt.feedback, tt.positive)
+			if err != nil {
+				t.Fatalf("ApplyFeedback failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+			if len(graph.deleted) != tt.want.Pruned {
+				t.Errorf("deleted = %v, want %d edges", graph.deleted, tt.want.Pruned)
+			}
+			if tt.want.Strengthened+tt.want.Weakened == 0 {
+				if len(graph.updated) != 0 {
+					t.Errorf("Unexpected updates: %v", graph.updated)
+				}
+				return
+			}
+			updated, ok := graph.updated["alice|WORKS_AT|acme"]
+			if !ok || len(graph.updated) != 1 {
+				t.Fatalf("Unexpected updates: %v", graph.updated)
+			}
+			if math.Abs(updated.Weight-tt.wantWeight) > 1e-9 || math.Abs(updated.Confidence-tt.wantConfidence) > 1e-9 {
+				t.Errorf("weight = %v, confidence = %v, want %v, %v", updated.Weight, updated.Confidence, tt.wantWeight, tt.wantConfidence)
+			}
+			if renewed := updated.Unix != observedAt; renewed != tt.wantRenewed {
+				t.Errorf("unix = %d, renewed = %v, want %v", updated.Unix, renewed, tt.wantRenewed)
+			}
+		})
+	}
+}
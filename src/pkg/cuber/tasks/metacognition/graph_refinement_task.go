@@ -191,7 +191,7 @@ func (t *GraphRefinementTask) applyMetabolism(ctx context.Context, eval EdgeEval
 
 	// エッジのメトリクスを更新 (現在時刻でタイムスタンプを更新)
 	nowUnix := *common.GetNowUnixMilli()
-	return t.GraphStorage.UpdateEdgeMetrics(ctx, eval.SourceID, eval.TargetID, t.MemoryGroup, newWeight, newConfidence, nowUnix)
+	return t.GraphStorage.UpdateEdgeMetrics(ctx, eval.SourceID, currentEdge.Type, eval.TargetID, t.MemoryGroup, newWeight, newConfidence, nowUnix)
 
 }
 
@@ -232,7 +232,7 @@ Respond with JSON in this format:
 
 IMPORTANT: Use the edge_index number (0, 1, 2...) shown in brackets to identify each edge.`, rulesText, edgeTexts)
 
-	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.EdgeEvaluationSystemPrompt, prompt)
+	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_EDGE_EVALUATION), prompt)
 	usage.Add(u)
 	if err != nil {
 		return nil, usage, err
@@ -11,6 +11,7 @@ import (
 	"github.com/t-kawata/mycute/lib/common"
 	"github.com/t-kawata/mycute/lib/eventbus"
 	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 	"github.com/t-kawata/mycute/pkg/cuber/utils"
@@ -403,7 +404,7 @@ func (t *MetabolismTask) refineConflicts(ctx context.Context) (int, types.TokenU
 			BeforeTriplesCount: stage1BeforeTriplesCount,
 		})
 
-		resolved, discarded1, remainingConflicts := utils.Stage1ConflictResolution(scoredTriples, t.Logger, t.IsEn)
+		resolved, discarded1, remainingConflicts := utils.Stage1ConflictResolution(scoredTriples, ontology.FromContext(ctx).IsExclusive, t.Logger, t.IsEn)
 		scoredTriples = resolved
 
 		// Emit conflict discarded events for Stage 1
@@ -159,9 +159,9 @@ func (t *SelfReflectionTask) generateQuestions(ctx context.Context, rules []stri
 	// Select prompt based on language mode
 	var systemPrompt string
 	if t.IsEn {
-		systemPrompt = prompts.QuestionGenerationSystemPromptEN
+		systemPrompt = prompts.Get(ctx, prompts.NAME_QUESTION_GENERATION_EN)
 	} else {
-		systemPrompt = prompts.QuestionGenerationSystemPromptJA
+		systemPrompt = prompts.Get(ctx, prompts.NAME_QUESTION_GENERATION_JA)
 	}
 	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, combinedRules)
 	usage.Add(u)
@@ -261,7 +261,7 @@ func (t *SelfReflectionTask) TryAnswer(ctx context.Context, question string, unk
 	}
 
 	prompt := fmt.Sprintf("Question: %s\n\nContext:\n%s", question, ctxStr.String())
-	answer, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.AnswerSimpleQuestionPrompt, prompt)
+	answer, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_ANSWER_SIMPLE_QUESTION), prompt)
 
 	// Emit Unknown Item Solve End (Preliminary, will refine insight)
 	// Actually logic below checks uncertainty. We emit Solve End here or after check?
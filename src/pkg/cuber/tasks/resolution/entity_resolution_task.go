@@ -0,0 +1,636 @@
+// Package resolution は、抽出されたエンティティを既存のエンティティと照合して統合するタスクを提供します。
+// 表記ゆれ（全角/半角、ひらがな/カタカナ等）や言い換えによって同じ実体が別ノードとして
+// 保存されることを防ぎ、正規ノードに別名（aliases）として集約します。
+package resolution
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"unicode"
+
+	"go.uber.org/zap"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/pkg/cuber/pipeline"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// ALIASES_PROPERTY は、正規ノードに別名のリストを保存するプロパティ名です。
+const ALIASES_PROPERTY = "aliases"
+
+// ALIAS_SOURCES_PROPERTY は、別名ごとに、その別名が現れたチャンクIDのリストを保存するプロパティ名です。
+// 文書の削除・置き換え時に、その文書だけに由来する別名を取り除くために使用します（RetractAliases）。
+const ALIAS_SOURCES_PROPERTY = "alias_sources"
+
+// EntityResolutionTask は、エンティティ解決タスクを表します。
+// グラフ抽出で得られたエンティティを、既存の Entity の埋め込みと正規化した名前で照合し、
+// 同一とみなしたエンティティを正規ノードに統合して、エッジの参照先を書き換えます。
+type EntityResolutionTask struct {
+	VectorStorage storage.VectorStorage // ベクトルストレージ（LadybugDB）
+	GraphStorage  storage.GraphStorage  // グラフストレージ（LadybugDB）
+	Embedder      storage.Embedder      // Embedder
+	memoryGroup   string                // メモリーグループ
+	Logger        *zap.Logger
+}
+
+// NewEntityResolutionTask は、新しいEntityResolutionTaskを作成します。
+func NewEntityResolutionTask(vectorStorage storage.VectorStorage, graphStorage storage.GraphStorage, embedder storage.Embedder, memoryGroup string, l *zap.Logger) *EntityResolutionTask {
+	return &EntityResolutionTask{
+		VectorStorage: vectorStorage,
+		GraphStorage:  graphStorage,
+		Embedder:      embedder,
+		memoryGroup:   memoryGroup,
+		Logger:        l,
+	}
+}
+
+var _ pipeline.Task = (*EntityResolutionTask)(nil)
+
+// entity は、解決対象のエンティティ（同じIDを持つノードの集まり）を表します。
+type entity struct {
+	id         string          // ノードID
+	node       *storage.Node   // 最初に出現したノード
+	nodes      []*storage.Node // 同じIDを持つすべてのノード（出現順）
+	name       string          // 表層名
+	key        string          // 照合用キー（NormalizeForEntityMatch）
+	vector     []float32       // 名前の埋め込み（生成に失敗した場合は nil）
+	degree     int             // バッチ内のエッジ数
+	chunkIDs   []string        // バッチ内でこのエンティティのエッジが抽出されたチャンクのID
+	candidates []candidate     // 既存エンティティの候補
+}
+
+// candidate は、ベクトル検索で見つかった既存エンティティの候補を表します。
+type candidate struct {
+	id         string
+	similarity float64
+}
+
+// entityGroup は、同一とみなしたエンティティのグループを表します。
+// グループの全員が正規エンティティ（existing、なければ leader）と照合条件を満たします。
+// 推移的に（A と B、B と C が似ているから A と C も）統合することはありません。
+type entityGroup struct {
+	leader   *entity       // グループを作成したエンティティ（バッチ内で最も多くのエッジを持つ）
+	existing *storage.Node // 正規ノードとする既存のエンティティ（ない場合は nil で、leader が正規ノードになる）
+	members  []*entity     // 所属するエンティティ（leader を含む）
+}
+
+// Run は、エンティティ解決タスクを実行します。
+// この関数は以下の処理を行います：
+//  1. エンティティ名の埋め込みを生成
+//  2. ベクトルインデックスで既存のエンティティの候補を検索
+//  3. 同一とみなせるエンティティをグループ化し、正規ノードを決定
+//  4. ノードを正規ノードに統合し、別名を記録
+//  5. エッジの参照先を正規ノードに書き換え
+func (t *EntityResolutionTask) Run(ctx context.Context, input any) (any, types.TokenUsage, error) {
+	var totalUsage types.TokenUsage
+	output, ok := input.(*storage.CognifyOutput)
+	if !ok {
+		return nil, totalUsage, fmt.Errorf("EntityResolution: Expected *storage.CognifyOutput input, got %T", input)
+	}
+	if output.GraphData == nil || len(output.GraphData.Nodes) == 0 {
+		return output, totalUsage, nil
+	}
+	// エンティティを ID ごとにまとめる
+	var entities []*entity
+	byID := make(map[string]*entity)
+	for _, node := range output.GraphData.Nodes {
+		if node.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) {
+			continue
+		}
+		if e, ok := byID[node.ID]; ok {
+			e.nodes = append(e.nodes, node)
+			continue
+		}
+		name := nodeName(node)
+		if name == "" {
+			continue
+		}
+		e := &entity{
+			id:    node.ID,
+			node:  node,
+			nodes: []*storage.Node{node},
+			name:  name,
+			key:   utils.NormalizeForEntityMatch(name),
+		}
+		entities = append(entities, e)
+		byID[node.ID] = e
+	}
+	if len(entities) == 0 {
+		return output, totalUsage, nil
+	}
+	for _, edge := range output.GraphData.Edges {
+		if e, ok := byID[edge.SourceID]; ok {
+			e.degree++
+			e.chunkIDs = appendUnique(e.chunkIDs, edge.ChunkID)
+		}
+		if e, ok := byID[edge.TargetID]; ok {
+			e.degree++
+			e.chunkIDs = appendUnique(e.chunkIDs, edge.ChunkID)
+		}
+	}
+	// ========================================
+	// 1. エンティティ名の埋め込みを生成
+	// ========================================
+	// StorageTask と同じ正規化を行うため、埋め込みキャッシュが有効な場合は保存時の再計算が不要になります
+	texts := make([]string, len(entities))
+	for i, e := range entities {
+		texts[i] = utils.NormalizeForVector(e.name)
+	}
+	vectors, embErrs, u := storage.EmbedBatchTolerant(ctx, t.Embedder, texts)
+	totalUsage.Add(u)
+	for i, e := range entities {
+		if embErrs[i] != nil {
+			// 埋め込みに失敗したエンティティは、名前の一致のみで照合する
+			utils.LogWarn(t.Logger, "EntityResolutionTask: Failed to embed entity name", zap.String("name", e.name), zap.Error(embErrs[i]))
+			continue
+		}
+		e.vector = vectors[i]
+	}
+	// ========================================
+	// 2. 既存のエンティティを検索（ベクトルインデックス）
+	// ========================================
+	candidateIDs := make([]string, 0, len(entities))
+	seenIDs := make(map[string]bool)
+	for _, e := range entities {
+		// 同じIDのノードが既に存在する場合も照合対象とする
+		if !seenIDs[e.id] {
+			seenIDs[e.id] = true
+			candidateIDs = append(candidateIDs, e.id)
+		}
+		if e.vector == nil {
+			continue
+		}
+		results, err := t.VectorStorage.Query(ctx, types.TABLE_NAME_ENTITY, e.vector, appconfig.ENTITY_RESOLUTION_TOP_K, t.memoryGroup)
+		if err != nil {
+			return nil, totalUsage, fmt.Errorf("EntityResolution: Failed to query entities for %s: %w", e.id, err)
+		}
+		for _, r := range results {
+			e.candidates = append(e.candidates, candidate{id: r.ID, similarity: r.Distance})
+			if !seenIDs[r.ID] {
+				seenIDs[r.ID] = true
+				candidateIDs = append(candidateIDs, r.ID)
+			}
+		}
+	}
+	existingNodes, err := t.GraphStorage.GetNodesByIDs(ctx, candidateIDs, t.memoryGroup)
+	if err != nil {
+		return nil, totalUsage, fmt.Errorf("EntityResolution: Failed to get existing entities: %w", err)
+	}
+	existing := make(map[string]*storage.Node, len(existingNodes))
+	for _, node := range existingNodes {
+		if node.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) {
+			continue
+		}
+		existing[node.ID] = node
+	}
+	// ========================================
+	// 3. グループ化して正規ノードを決定
+	// ========================================
+	canonicalNodes := make(map[string]*storage.Node) // 元のノードID -> 正規ノード
+	renamed := make(map[string]string)               // 元のノードID -> 正規ノードID
+	matchedExisting := 0
+	for _, g := range groupEntities(entities, existing) {
+		canonical := t.resolveGroup(g)
+		if g.existing != nil {
+			matchedExisting++
+		}
+		for _, e := range g.members {
+			canonicalNodes[e.id] = canonical
+			if e.id != canonical.ID {
+				renamed[e.id] = canonical.ID
+			}
+		}
+	}
+	// ========================================
+	// 4. ノードを正規ノードに統合
+	// ========================================
+	var nodes []*storage.Node
+	emitted := make(map[string]bool)
+	for _, node := range output.GraphData.Nodes {
+		canonical, ok := canonicalNodes[node.ID]
+		if !ok {
+			nodes = append(nodes, node)
+			continue
+		}
+		if emitted[canonical.ID] {
+			continue
+		}
+		emitted[canonical.ID] = true
+		nodes = append(nodes, canonical)
+	}
+	output.GraphData.Nodes = nodes
+	// ========================================
+	// 5. エッジの参照先を書き換え
+	// ========================================
+	var edges []*storage.Edge
+	droppedEdges := 0
+	for _, edge := range output.GraphData.Edges {
+		sourceID, sourceRenamed := renamed[edge.SourceID]
+		targetID, targetRenamed := renamed[edge.TargetID]
+		if !sourceRenamed {
+			sourceID = edge.SourceID
+		}
+		if !targetRenamed {
+			targetID = edge.TargetID
+		}
+		// 統合によって生じた自己ループは破棄する
+		if (sourceRenamed || targetRenamed) && sourceID == targetID {
+			droppedEdges++
+			continue
+		}
+		edge.SourceID = sourceID
+		edge.TargetID = targetID
+		edges = append(edges, edge)
+	}
+	output.GraphData.Edges = edges
+	utils.LogInfo(t.Logger, "EntityResolutionTask: Resolved entities",
+		zap.Int("entities", len(entities)),
+		zap.Int("merged", len(renamed)),
+		zap.Int("matched_existing", matchedExisting),
+		zap.Int("dropped_self_loops", droppedEdges))
+	return output, totalUsage, nil
+}
+
+// groupEntities は、同一とみなせるエンティティをグループ化します。
+// エッジの多いエンティティから順に、照合条件を満たす既存のグループのうち最も類似したものに加え、
+// なければ新しいグループを作成します。照合するのはグループの正規エンティティだけです（連鎖的な統合を防ぐ）。
+//
+// 全組み合わせを比較しないよう、比較するグループを次の2つに限定します。
+//   - ベクトルインデックスで見つかった既存のエンティティ（candidates）を正規ノードとするグループ
+//   - ブロッキングキー（照合用キー・名前の単語）を共有するグループ
+//
+// 単語を共有しない言い換え（"NYC" と "New York City" など）は、同じバッチ内では統合されず、
+// 保存後に次のバッチからベクトルインデックスで照合されます。
+func groupEntities(entities []*entity, existing map[string]*storage.Node) []*entityGroup {
+	order := slices.Clone(entities)
+	slices.SortStableFunc(order, func(a, b *entity) int { return b.degree - a.degree })
+	var groups []*entityGroup
+	byExisting := make(map[string]*entityGroup) // 既存のエンティティのID -> それを正規ノードとするグループ
+	byBlock := make(map[string][]*entityGroup)  // ブロッキングキー -> グループ
+	for _, e := range order {
+		var best *entityGroup
+		bestScore := math.Inf(-1)
+		consider := func(g *entityGroup) {
+			if score, ok := g.similarity(e); ok && score > bestScore {
+				best, bestScore = g, score
+			}
+		}
+		if g, ok := byExisting[e.id]; ok {
+			consider(g)
+		}
+		for _, c := range e.candidates {
+			if g, ok := byExisting[c.id]; ok {
+				consider(g)
+			}
+		}
+		keys := blockingKeys(e)
+		for _, key := range keys {
+			for _, g := range byBlock[key] {
+				consider(g)
+			}
+		}
+		if best != nil {
+			best.members = append(best.members, e)
+			continue
+		}
+		g := &entityGroup{leader: e, existing: bestExisting(e, existing), members: []*entity{e}}
+		if g.existing != nil {
+			byExisting[g.existing.ID] = g
+		}
+		for _, key := range keys {
+			// ありふれた語のキーには、比較の対象が増えすぎないようにそれ以上グループを登録しない（照合用キーの完全一致は除く）
+			if key[0] == '~' && len(byBlock[key]) >= appconfig.ENTITY_RESOLUTION_MAX_BLOCK_SIZE {
+				continue
+			}
+			byBlock[key] = append(byBlock[key], g)
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// similarity は、エンティティがグループの正規エンティティと照合条件を満たす場合に、その類似度を返します。
+func (g *entityGroup) similarity(e *entity) (float64, bool) {
+	if g.existing != nil {
+		return similarityToExisting(e, g.existing)
+	}
+	a, b := e, g.leader
+	if !isTypeCompatible(a.node.Type, b.node.Type) {
+		return 0, false
+	}
+	if a.key != "" && a.key == b.key {
+		return 1, true
+	}
+	if sim := cosineSimilarity(a.vector, b.vector); sim >= appconfig.ENTITY_RESOLUTION_SIMILARITY_THRESHOLD {
+		return sim, true
+	}
+	return 0, false
+}
+
+// similarityToExisting は、エンティティが既存のエンティティと照合条件を満たす場合に、その類似度を返します。
+// 同じIDであれば最優先（2）、名前か別名の照合用キーが一致すれば 1、
+// それ以外はベクトル検索の候補で類似度が閾値以上の場合にその類似度とします。
+func similarityToExisting(e *entity, node *storage.Node) (float64, bool) {
+	if e.id == node.ID {
+		return 2, true
+	}
+	if !isTypeCompatible(e.node.Type, node.Type) {
+		return 0, false
+	}
+	if e.key != "" && matchesKey(e.key, node) {
+		return 1, true
+	}
+	for _, c := range e.candidates {
+		if c.id == node.ID && c.similarity >= appconfig.ENTITY_RESOLUTION_SIMILARITY_THRESHOLD {
+			return c.similarity, true
+		}
+	}
+	return 0, false
+}
+
+// bestExisting は、エンティティと照合条件を満たす既存のエンティティのうち、最も類似したものを返します（ない場合は nil）。
+func bestExisting(e *entity, existing map[string]*storage.Node) *storage.Node {
+	var best *storage.Node
+	bestScore := math.Inf(-1)
+	ids := []string{e.id}
+	for _, c := range e.candidates {
+		ids = append(ids, c.id)
+	}
+	for _, id := range ids {
+		node, ok := existing[id]
+		if !ok {
+			continue
+		}
+		if score, ok := similarityToExisting(e, node); ok && score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// blockingKeys は、比較するグループを絞り込むためのブロッキングキーを返します。
+// 照合用キーそのもの（"=" で始まる）と、名前の単語（"~" で始まる。日本語などスペースで区切らない文字は2文字ずつ）をキーとします。
+func blockingKeys(e *entity) []string {
+	var keys []string
+	if e.key != "" {
+		keys = append(keys, "="+e.key)
+	}
+	var word, cjk []rune
+	flushWord := func() {
+		if len(word) > 1 {
+			keys = appendUnique(keys, "~"+string(word))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			keys = appendUnique(keys, "~"+string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			keys = appendUnique(keys, "~"+string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range utils.NormalizeForSearch(e.name) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー':
+			flushWord()
+			if r >= 'ぁ' && r <= 'ゖ' {
+				// 照合用キーと同じく、ひらがなをカタカナに揃える
+				r += 'ァ' - 'ぁ'
+			}
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return keys
+}
+
+// resolveGroup は、同一とみなしたエンティティのグループから正規ノードを生成します。
+// 照合条件を満たす既存のエンティティがあればそれを、なければグループの leader を正規ノードとします。
+// 正規ノードの既存のプロパティは保持し、存在しないプロパティのみを他のノードから補完します。
+func (t *EntityResolutionTask) resolveGroup(g *entityGroup) *storage.Node {
+	base := g.leader.node
+	if g.existing != nil {
+		base = g.existing
+	}
+	members := g.members
+	canonical := &storage.Node{
+		ID:          base.ID,
+		MemoryGroup: t.memoryGroup,
+		Type:        base.Type,
+		Properties:  make(map[string]any, len(base.Properties)),
+	}
+	for k, v := range base.Properties {
+		canonical.Properties[k] = v
+	}
+	canonicalName := nodeName(canonical)
+	aliases := GetAliases(canonical)
+	sources := GetAliasSources(canonical)
+	for _, e := range members {
+		if canonical.Type == "" {
+			canonical.Type = e.node.Type
+		}
+		for _, node := range e.nodes {
+			for k, v := range node.Properties {
+				if _, ok := canonical.Properties[k]; !ok {
+					canonical.Properties[k] = v
+				}
+			}
+		}
+		if e.name != canonicalName {
+			aliases = appendAlias(aliases, e.name)
+			sources[e.name] = appendUnique(sources[e.name], e.chunkIDs...)
+		}
+		for _, alias := range GetAliases(e.node) {
+			if alias != canonicalName {
+				aliases = appendAlias(aliases, alias)
+				sources[alias] = appendUnique(sources[alias], e.chunkIDs...)
+			}
+		}
+	}
+	if len(aliases) > 0 {
+		canonical.Properties[ALIASES_PROPERTY] = aliases
+	}
+	setAliasSources(canonical, aliases, sources)
+	return canonical
+}
+
+// nodeName は、ノードの表層名を返します。
+// "name" プロパティがない場合は、IDからメモリーグループを除去したものを使用します。
+func nodeName(node *storage.Node) string {
+	if name, ok := node.Properties["name"].(string); ok && name != "" {
+		return name
+	}
+	return utils.GetNameStrByGraphNodeID(node.ID)
+}
+
+// GetAliases は、ノードに記録された別名のリストを返します（記録されていない場合は nil）。
+// DBから読み込んだプロパティ（[]any）と、抽出直後のプロパティ（[]string）の両方に対応します。
+func GetAliases(node *storage.Node) []string {
+	var aliases []string
+	switch v := node.Properties[ALIASES_PROPERTY].(type) {
+	case []string:
+		aliases = append(aliases, v...)
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s != "" {
+				aliases = append(aliases, s)
+			}
+		}
+	}
+	return aliases
+}
+
+// GetAliasSources は、ノードに記録された別名ごとの出典チャンクIDを返します（記録されていない場合は空のマップ）。
+// DBから読み込んだプロパティ（map[string]any）と、解決直後のプロパティ（map[string][]string）の両方に対応します。
+func GetAliasSources(node *storage.Node) map[string][]string {
+	sources := make(map[string][]string)
+	switch v := node.Properties[ALIAS_SOURCES_PROPERTY].(type) {
+	case map[string][]string:
+		for alias, ids := range v {
+			sources[alias] = append([]string(nil), ids...)
+		}
+	case map[string]any:
+		for alias, raw := range v {
+			ids, _ := raw.([]any)
+			for _, id := range ids {
+				if s, ok := id.(string); ok && s != "" {
+					sources[alias] = append(sources[alias], s)
+				}
+			}
+		}
+	}
+	return sources
+}
+
+// setAliasSources は、aliases に含まれる別名の出典だけをノードに保存します（出典が1つもなければプロパティを削除します）。
+func setAliasSources(node *storage.Node, aliases []string, sources map[string][]string) {
+	kept := make(map[string][]string, len(aliases))
+	for _, alias := range aliases {
+		if ids := sources[alias]; len(ids) > 0 {
+			kept[alias] = ids
+		}
+	}
+	if len(kept) == 0 {
+		delete(node.Properties, ALIAS_SOURCES_PROPERTY)
+		return
+	}
+	node.Properties[ALIAS_SOURCES_PROPERTY] = kept
+}
+
+// RetractAliases は、取り消されるチャンクだけに由来する別名をノードから取り除きます。
+// 別名の出典から chunkIDs を除き、出典が残らなかった別名を削除します。
+// 出典が記録されていない別名（出典の記録を導入する前に統合されたもの）は残します。
+// ノードを変更した場合は true を返します。
+func RetractAliases(node *storage.Node, chunkIDs map[string]bool) bool {
+	sources := GetAliasSources(node)
+	removed := make(map[string]bool)
+	changed := false
+	for alias, ids := range sources {
+		var kept []string
+		for _, id := range ids {
+			if !chunkIDs[id] {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == len(ids) {
+			continue
+		}
+		changed = true
+		if len(kept) == 0 {
+			removed[alias] = true
+			delete(sources, alias)
+		} else {
+			sources[alias] = kept
+		}
+	}
+	if !changed {
+		return false
+	}
+	var aliases []string
+	for _, alias := range GetAliases(node) {
+		if !removed[alias] {
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) > 0 {
+		node.Properties[ALIASES_PROPERTY] = aliases
+	} else {
+		delete(node.Properties, ALIASES_PROPERTY)
+	}
+	setAliasSources(node, aliases, sources)
+	return true
+}
+
+// appendUnique は、空文字と重複を除いて値を追加します。
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		if item != "" && !slices.Contains(values, item) {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// appendAlias は、重複を除いて別名を追加します。
+// 別名の数は config.ENTITY_RESOLUTION_MAX_ALIASES を上限とします。
+func appendAlias(aliases []string, alias string) []string {
+	if alias == "" || len(aliases) >= appconfig.ENTITY_RESOLUTION_MAX_ALIASES {
+		return aliases
+	}
+	for _, a := range aliases {
+		if a == alias {
+			return aliases
+		}
+	}
+	return append(aliases, alias)
+}
+
+// matchesKey は、照合用キーがノードの名前または別名のいずれかと一致するかを判定します。
+func matchesKey(key string, node *storage.Node) bool {
+	if utils.NormalizeForEntityMatch(nodeName(node)) == key {
+		return true
+	}
+	for _, alias := range GetAliases(node) {
+		if utils.NormalizeForEntityMatch(alias) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isTypeCompatible は、2つのノードタイプが同一エンティティとして統合可能かを判定します。
+// タイプが一致するか、いずれかのタイプが未設定の場合に統合可能とみなします。
+func isTypeCompatible(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// cosineSimilarity は、2つのベクトルのコサイン類似度を返します。
+// いずれかのベクトルが空、または次元が異なる場合は 0 を返します。
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,122 @@
+package resolution
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// testEntity は、名前・埋め込み・エッジ数からテスト用のエンティティを作成します。
+func testEntity(id, name string, degree int, vector []float32) *entity {
+	node := &storage.Node{ID: id, Type: "Organization", Properties: map[string]any{"name": name}}
+	return &entity{
+		id:     id,
+		node:   node,
+		nodes:  []*storage.Node{node},
+		name:   name,
+		key:    utils.NormalizeForEntityMatch(name),
+		vector: vector,
+		degree: degree,
+	}
+}
+
+// unitVector は、x軸から deg 度回転した2次元の単位ベクトルを返します。
+func unitVector(deg float64) []float32 {
+	rad := deg * math.Pi / 180
+	return []float32{float32(math.Cos(rad)), float32(math.Sin(rad))}
+}
+
+// groupIDs は、グループごとのメンバーのIDを返します。
+func groupIDs(groups []*entityGroup) [][]string {
+	var ids [][]string
+	for _, g := range groups {
+		var members []string
+		for _, e := range g.members {
+			members = append(members, e.id)
+		}
+		ids = append(ids, members)
+	}
+	return ids
+}
+
+func TestGroupEntitiesDoesNotChain(t *testing.T) {
+	// cos(a, b) = cos(b, c) ≒ 0.94、cos(a, c) ≒ 0.77
+	a := testEntity("a", "Acme Corp", 3, unitVector(0))
+	b := testEntity("b", "Acme Corporation", 2, unitVector(20))
+	c := testEntity("c", "Acme Co", 1, unitVector(40))
+	got := groupIDs(groupEntities([]*entity{c, b, a}, nil))
+	want := [][]string{{"a", "b"}, {"c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected groups: %v, want %v", got, want)
+	}
+}
+
+func TestGroupEntitiesJoinsCanonical(t *testing.T) {
+	// b が最も多くのエッジを持つため、a と c はどちらも正規エンティティの b と照合される
+	a := testEntity("a", "Acme Corp", 1, unitVector(0))
+	b := testEntity("b", "Acme Corporation", 3, unitVector(20))
+	c := testEntity("c", "Acme Co", 2, unitVector(40))
+	got := groupIDs(groupEntities([]*entity{a, b, c}, nil))
+	want := [][]string{{"b", "c", "a"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected groups: %v, want %v", got, want)
+	}
+}
+
+func TestGroupEntitiesBlocking(t *testing.T) {
+	// 埋め込みが同じでも、ブロッキングキーを共有しないエンティティとは比較しない
+	nyc := testEntity("nyc", "NYC", 2, unitVector(0))
+	ny := testEntity("ny", "New York City", 1, unitVector(0))
+	// 照合用キーが一致すれば、埋め込みがなくても統合する
+	kana := testEntity("kana", "とよた", 1, nil)
+	kata := testEntity("kata", "トヨタ", 1, nil)
+	got := groupIDs(groupEntities([]*entity{nyc, ny, kana, kata}, nil))
+	want := [][]string{{"nyc"}, {"ny"}, {"kana", "kata"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected groups: %v, want %v", got, want)
+	}
+}
+
+func TestGroupEntitiesExisting(t *testing.T) {
+	tokyo := &storage.Node{ID: "tokyo", Type: "Organization", Properties: map[string]any{"name": "Tokyo Univ", "aliases": []any{"Todai"}}}
+	existing := map[string]*storage.Node{tokyo.ID: tokyo}
+	// ベクトル検索の候補として見つかった既存のエンティティが正規ノードになる
+	a := testEntity("a", "University of Tokyo", 2, unitVector(0))
+	a.candidates = []candidate{{id: "tokyo", similarity: 0.95}}
+	// 正規ノードの別名と照合用キーが一致するため、同じグループに加わる
+	b := testEntity("b", "TODAI", 1, nil)
+	b.candidates = []candidate{{id: "tokyo", similarity: 0.5}}
+	// 既存のエンティティとの類似度が閾値未満のため、a と似ていても加わらない
+	c := testEntity("c", "University of Kyoto", 1, unitVector(10))
+	c.candidates = []candidate{{id: "tokyo", similarity: 0.8}}
+	groups := groupEntities([]*entity{a, b, c}, existing)
+	got := groupIDs(groups)
+	want := [][]string{{"a", "b"}, {"c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unexpected groups: %v, want %v", got, want)
+	}
+	if groups[0].existing != tokyo || groups[1].existing != nil {
+		t.Errorf("Unexpected canonical entities: %v, %v", groups[0].existing, groups[1].existing)
+	}
+}
+
+func TestBlockingKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"New York City", []string{"=newyorkcity", "~new", "~york", "~city"}},
+		{"トヨタ自動車", []string{"=トヨタ自動車", "~トヨ", "~ヨタ", "~タ自", "~自動", "~動車"}},
+		{"とよた", []string{"=トヨタ", "~トヨ", "~ヨタ"}},
+		{"A 東", []string{"=a東", "~東"}},
+	}
+	for _, tt := range tests {
+		got := blockingKeys(testEntity("x", tt.name, 0, nil))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("blockingKeys(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/t-kawata/mycute/lib/eventbus"
@@ -56,18 +58,28 @@ func (t *StorageTask) Run(ctx context.Context, input any) (any, types.TokenUsage
 	// ========================================
 	// 1. チャンク（ベクトル）を保存
 	// ========================================
-	for i, chunk := range output.Chunks {
-		// embeddingが空の場合は再生成
-		// 堅牢性のため、保存タスクで再生成を試みます
+	// embeddingが空のチャンクはまとめて再生成
+	// 堅牢性のため、保存タスクで再生成を試みます
+	var missingChunks []*storage.Chunk
+	var missingTexts []string
+	for _, chunk := range output.Chunks {
 		if len(chunk.Embedding) == 0 {
 			utils.LogWarn(t.Logger, "StorageTask: Embedding missing for chunk, regenerating", zap.String("id", chunk.ID))
-			embedding, u, err := t.Embedder.EmbedQuery(ctx, chunk.Text)
-			totalUsage.Add(u)
-			if err != nil {
-				return nil, totalUsage, fmt.Errorf("Storage: Failed to regenerate embedding for chunk %s: %w", chunk.ID, err)
-			}
-			chunk.Embedding = embedding
+			missingChunks = append(missingChunks, chunk)
+			missingTexts = append(missingTexts, chunk.Text)
+		}
+	}
+	if len(missingChunks) > 0 {
+		embeddings, u, err := t.Embedder.EmbedBatch(ctx, missingTexts)
+		totalUsage.Add(u)
+		if err != nil {
+			return nil, totalUsage, fmt.Errorf("Storage: Failed to regenerate embeddings for %d chunks: %w", len(missingChunks), err)
 		}
+		for i, chunk := range missingChunks {
+			chunk.Embedding = embeddings[i]
+		}
+	}
+	for i, chunk := range output.Chunks {
 		// Emit Chunk Save Start
 		eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_STORAGE_CHUNK_START), event.AbsorbStorageChunkStartPayload{
 			BasePayload: event.NewBasePayload(t.memoryGroup),
@@ -148,6 +160,14 @@ func (t *StorageTask) Run(ctx context.Context, input any) (any, types.TokenUsage
 		})
 
 		utils.LogDebug(t.Logger, "StorageTask: Added edges batch", zap.Int("count", len(output.GraphData.Edges)))
+
+		// エッジの出典（抽出元チャンク）を保存
+		// 文書の置き換え・削除時に、そのチャンクだけに裏付けられたエッジを特定するために使用されます
+		provenances := buildEdgeProvenances(output)
+		if err := t.GraphStorage.AddEdgeProvenances(ctx, provenances); err != nil {
+			return nil, totalUsage, fmt.Errorf("Storage: Failed to add edge provenances: %w", err)
+		}
+		utils.LogDebug(t.Logger, "StorageTask: Added edge provenances", zap.Int("count", len(provenances)))
 		utils.LogInfo(t.Logger, "StorageTask: Saved graph data", zap.Int("nodes", len(output.GraphData.Nodes)), zap.Int("edges", len(output.GraphData.Edges)))
 		// ========================================
 		// 3. ノードのインデックス化（エンティティ名のembedding）
@@ -161,7 +181,10 @@ func (t *StorageTask) Run(ctx context.Context, input any) (any, types.TokenUsage
 		})
 
 		utils.LogDebug(t.Logger, "StorageTask: Indexing nodes (entity embeddings)", zap.Int("nodes", len(output.GraphData.Nodes)))
+		// 埋め込み対象のエンティティ名を集めてから、まとめて埋め込みを生成
 		doneEntities := []string{}
+		var entityNodes []*storage.Node
+		var entityNames []string
 		for _, node := range output.GraphData.Nodes {
 			// SPECIAL_NODE_TYPE_DOCUMENT_CHUNK は、エンティティではないのでスキップ
 			if node.Type == string(types.SPECIAL_NODE_TYPE_DOCUMENT_CHUNK) {
@@ -185,15 +208,20 @@ func (t *StorageTask) Run(ctx context.Context, input any) (any, types.TokenUsage
 			if name == "" {
 				continue
 			}
-			// エンティティ名をVector用に正規化してから埋め込み生成
-			name = utils.NormalizeForVector(name)
-			// エンティティ名のembeddingを生成
-			embedding, u, err := t.Embedder.EmbedQuery(ctx, name)
-			totalUsage.Add(u)
-			if err != nil {
-				utils.LogWarn(t.Logger, "StorageTask: Failed to embed node", zap.String("name", name), zap.Error(err))
+			// エンティティ名をVector用に正規化してから埋め込み対象に追加
+			entityNodes = append(entityNodes, node)
+			entityNames = append(entityNames, utils.NormalizeForVector(name))
+		}
+		// エンティティ名のembeddingを生成（失敗したエンティティのみスキップ）
+		embeddings, embErrs, u := storage.EmbedBatchTolerant(ctx, t.Embedder, entityNames)
+		totalUsage.Add(u)
+		for i, node := range entityNodes {
+			name := entityNames[i]
+			if embErrs[i] != nil {
+				utils.LogWarn(t.Logger, "StorageTask: Failed to embed node", zap.String("name", name), zap.Error(embErrs[i]))
 				continue
 			}
+			embedding := embeddings[i]
 			// Emit Node Embedding Start
 			eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_STORAGE_NODE_EMBEDDING_START), event.AbsorbStorageNodeEmbeddingStartPayload{
 				BasePayload: event.NewBasePayload(t.memoryGroup),
@@ -221,3 +249,30 @@ func (t *StorageTask) Run(ctx context.Context, input any) (any, types.TokenUsage
 	}
 	return output, totalUsage, nil
 }
+
+// buildEdgeProvenances は、抽出元チャンクを持つエッジから出典のリストを生成します。
+// 出典IDはエッジのキー（source, type, target）とチャンクIDから決定論的に生成されるため、
+// 同じチャンクから同じエッジが複数回抽出されても出典は1件になります。
+func buildEdgeProvenances(output *storage.CognifyOutput) []*storage.EdgeProvenance {
+	documentIDs := make(map[string]string, len(output.Chunks))
+	for _, chunk := range output.Chunks {
+		documentIDs[chunk.ID] = chunk.DocumentID
+	}
+	var provenances []*storage.EdgeProvenance
+	for _, edge := range output.GraphData.Edges {
+		if edge.ChunkID == "" {
+			continue
+		}
+		key := strings.Join([]string{edge.SourceID, edge.Type, edge.TargetID, edge.ChunkID}, "|")
+		provenances = append(provenances, &storage.EdgeProvenance{
+			ID:          uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String(),
+			MemoryGroup: edge.MemoryGroup,
+			SourceID:    edge.SourceID,
+			TargetID:    edge.TargetID,
+			Type:        edge.Type,
+			ChunkID:     edge.ChunkID,
+			DocumentID:  documentIDs[edge.ChunkID],
+		})
+	}
+	return provenances
+}
@@ -56,7 +56,7 @@ var _ pipeline.Task = (*SummarizationTask)(nil)
 // Run は、要約生成タスクを実行します。
 // この関数は以下の処理を行います：
 //  1. 各チャンクに対してLLMで要約を生成
-//  2. 要約のembeddingを生成
+//  2. 全要約のembeddingをまとめて生成
 //  3. LadybugDBに保存
 func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.TokenUsage, error) {
 	var totalUsage types.TokenUsage
@@ -67,6 +67,13 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 	utils.LogInfo(t.Logger, "SummarizationTask: Starting", zap.Int("chunks", len(output.Chunks)))
 
 	summariesCreated := 0
+	// 要約を生成したチャンク（embeddingはまとめて生成する）
+	type chunkSummary struct {
+		chunk    *storage.Chunk
+		chunkNum int
+		text     string
+	}
+	var summaries []chunkSummary
 
 	for i, chunk := range output.Chunks {
 		// ========================================
@@ -85,9 +92,9 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 		// Select prompt based on language mode
 		var promptTemplate string
 		if t.IsEn {
-			promptTemplate = prompts.SUMMARIZE_CONTENT_EN_PROMPT
+			promptTemplate = prompts.Get(ctx, prompts.NAME_SUMMARIZE_CONTENT_EN)
 		} else {
-			promptTemplate = prompts.SUMMARIZE_CONTENT_JA_PROMPT
+			promptTemplate = prompts.Get(ctx, prompts.NAME_SUMMARIZE_CONTENT_JA)
 		}
 		summaryText, chunkUsage, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, promptTemplate, prompt)
 
@@ -116,21 +123,30 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 		// 要約テキストをVector用に正規化
 		summaryText = utils.NormalizeForVector(summaryText)
 		utils.LogDebug(t.Logger, "SummarizationTask: Generated summary", zap.String("chunk_id", chunk.ID), zap.String("summary_preview", truncate(summaryText, 50)))
-		// ========================================
-		// 2. 要約のembeddingを生成
-		// ========================================
-		embedding, u, err := t.Embedder.EmbedQuery(ctx, summaryText)
-		totalUsage.Add(u)
-		if err != nil {
-			utils.LogWarn(t.Logger, "SummarizationTask: Failed to embed summary", zap.String("chunk_id", chunk.ID), zap.Error(err))
+		summaries = append(summaries, chunkSummary{chunk: chunk, chunkNum: i + 1, text: summaryText})
+	}
+	// ========================================
+	// 2. 要約のembeddingをまとめて生成（失敗した要約のみスキップ）
+	// ========================================
+	summaryTexts := make([]string, len(summaries))
+	for i, summary := range summaries {
+		summaryTexts[i] = summary.text
+	}
+	embeddings, embErrs, u := storage.EmbedBatchTolerant(ctx, t.Embedder, summaryTexts)
+	totalUsage.Add(u)
+	for i, summary := range summaries {
+		chunk := summary.chunk
+		summaryText := summary.text
+		if embErrs[i] != nil {
+			utils.LogWarn(t.Logger, "SummarizationTask: Failed to embed summary", zap.String("chunk_id", chunk.ID), zap.Error(embErrs[i]))
 			continue
 		}
+		embedding := embeddings[i]
 		// ========================================
 		// 4. 要約をLadybugDBに保存
 		// ========================================
 		// 決定論的なIDを生成（チャンクIDベース）
-		namespace := uuid.MustParse("00000000-0000-0000-0000-000000000000")
-		summaryID := uuid.NewSHA1(namespace, []byte(chunk.ID+"TextSummary")).String()
+		summaryID := GenerateSummaryID(chunk.ID)
 		// ========================================
 		// 5. embeddingを保存
 		// ========================================
@@ -142,7 +158,7 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 		eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_SUMMARIZATION_SAVE_START), event.AbsorbSummarizationSaveStartPayload{
 			BasePayload: event.NewBasePayload(t.memoryGroup),
 			ChunkID:     chunk.ID,
-			ChunkNum:    i + 1,
+			ChunkNum:    summary.chunkNum,
 		})
 
 		// ... actually saved above. Let's readjust logic or emit start/end around save.
@@ -152,7 +168,7 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 		eventbus.Emit(t.EventBus, string(event.EVENT_ABSORB_SUMMARIZATION_SAVE_END), event.AbsorbSummarizationSaveEndPayload{
 			BasePayload: event.NewBasePayload(t.memoryGroup),
 			ChunkID:     chunk.ID,
-			ChunkNum:    i + 1,
+			ChunkNum:    summary.chunkNum,
 		})
 
 		utils.LogDebug(t.Logger, "SummarizationTask: Saved summary", zap.String("id", summaryID))
@@ -169,6 +185,13 @@ func (t *SummarizationTask) Run(ctx context.Context, input any) (any, types.Toke
 	return output, totalUsage, nil // 次のタスクのためにそのまま渡す
 }
 
+// GenerateSummaryID は、チャンクIDから要約の決定論的なIDを生成します。
+// 文書の置き換え・削除時に、チャンクに対応する要約を特定するためにも使用されます。
+func GenerateSummaryID(chunkID string) string {
+	namespace := uuid.MustParse("00000000-0000-0000-0000-000000000000")
+	return uuid.NewSHA1(namespace, []byte(chunkID+"TextSummary")).String()
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,510 @@
+// Package cypher は、利用者が記述した Cypher クエリを Cube に対して安全に実行するための
+// 検証と書き換えを提供します。
+// 更新系の句を拒否し、MATCH 句のパターンに memory_group の条件を注入することで、
+// 読み取り専用かつ指定メモリーグループに限定されたクエリに変換します。
+package cypher
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+// forbiddenKeywords は、読み取り専用クエリで使用を禁止するキーワードです。
+// 更新系の句、DDL（COMMENT ON TABLE を含む）、外部ファイル・拡張機能へのアクセス、トランザクション制御を含みます。
+// 実行時には読み取り専用トランザクションでも保護されるため、ここでの検証は早期にわかりやすいエラーを返すためのものです。
+var forbiddenKeywords = map[string]bool{
+	"CREATE":     true,
+	"MERGE":      true,
+	"SET":        true,
+	"DELETE":     true,
+	"DETACH":     true,
+	"REMOVE":     true,
+	"DROP":       true,
+	"ALTER":      true,
+	"COMMENT":    true,
+	"FOREACH":    true,
+	"COPY":       true,
+	"LOAD":       true,
+	"INSTALL":    true,
+	"UNINSTALL":  true,
+	"ATTACH":     true,
+	"USE":        true,
+	"EXPORT":     true,
+	"IMPORT":     true,
+	"CALL":       true,
+	"BEGIN":      true,
+	"COMMIT":     true,
+	"ROLLBACK":   true,
+	"CHECKPOINT": true,
+}
+
+// clauseKeywords は、MATCH 句のパターン部分の終端となる句のキーワードです。
+var clauseKeywords = map[string]bool{
+	"MATCH":    true,
+	"OPTIONAL": true,
+	"WHERE":    true,
+	"RETURN":   true,
+	"WITH":     true,
+	"UNWIND":   true,
+	"ORDER":    true,
+	"SKIP":     true,
+	"LIMIT":    true,
+	"UNION":    true,
+}
+
+// nodeTables は、クエリで参照できるノードテーブルです。
+// いずれも memory_group カラムを持つため、スコープ条件を注入できます。
+var nodeTables = []types.TableName{
+	types.TABLE_NAME_DATA,
+	types.TABLE_NAME_DOCUMENT,
+	types.TABLE_NAME_CHUNK,
+	types.TABLE_NAME_GRAPH_NODE,
+	types.TABLE_NAME_ENTITY,
+	types.TABLE_NAME_SUMMARY,
+	types.TABLE_NAME_RULE,
+	types.TABLE_NAME_UNKNOWN,
+	types.TABLE_NAME_CAPABILITY,
+	types.TABLE_NAME_EDGE_PROVENANCE,
+}
+
+// relTables は、クエリで参照できるリレーションテーブルです。
+var relTables = []types.TableName{
+	types.TABLE_NAME_GRAPH_EDGE,
+	"HAS_DOCUMENT",
+	"HAS_CHUNK",
+	"NEXT_CHUNK",
+}
+
+// memoryGroupKey は、スコープ条件に使用するプロパティ名です。
+const memoryGroupKey = "memory_group"
+
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // 識別子・キーワード・数値
+	tokenString                  // 文字列リテラル
+	tokenQuoted                  // バッククォートで囲まれた識別子
+	tokenSymbol                  // 記号（1文字）
+	tokenSpace                   // 空白・コメント
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// ScopeReadOnlyQuery は、Cypher クエリを検証し、指定メモリーグループに限定したクエリに書き換えます。
+// 以下のいずれかに該当するクエリはエラーとなります。
+//   - 複数のステートメントを含む
+//   - 更新系の句や管理系のコマンドを含む
+//   - 許可されていないテーブルを参照する
+//   - パターンで memory_group を直接指定している
+//   - 可変長リレーション（例: [:GraphEdge*1..3]）を含む
+//   - MATCH 句の外にリレーションのパターンを含む（WHERE 句のパターン述語、パターン内包表記など）
+//
+// ラベルのないノードパターンにも条件を注入するため、memory_group を持たないテーブルは結果に含まれません。
+// 可変長リレーションは経由するリレーションと中間ノードに条件を注入できず、
+// 他のメモリーグループを経由した経路が返り得るため拒否します。
+// MATCH 句の外のパターンには条件を注入できないため、同様に拒否します。
+// EXISTS { MATCH ... } や COUNT { MATCH ... } のサブクエリは、内側の MATCH 句に条件が注入されます。
+// 引数:
+//   - query: 利用者が記述した Cypher クエリ
+//   - memoryGroup: スコープするメモリーグループ
+//
+// 返り値:
+//   - string: 書き換え後のクエリ
+//   - error: 検証エラー
+func ScopeReadOnlyQuery(query string, memoryGroup string) (string, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return "", err
+	}
+	tokens = trimTrailingSemicolon(tokens)
+	if len(significantIndexes(tokens, 0, len(tokens))) == 0 {
+		return "", fmt.Errorf("Cypher: query is empty")
+	}
+	if err := validateReadOnly(tokens); err != nil {
+		return "", err
+	}
+	insertions := map[int]string{}
+	scope := fmt.Sprintf("%s: '%s'", memoryGroupKey, escapeString(memoryGroup))
+	inPattern := make([]bool, len(tokens))
+	for i, t := range tokens {
+		if t.kind == tokenWord && strings.EqualFold(t.text, "MATCH") {
+			end, err := scopeMatchClause(tokens, i+1, scope, insertions)
+			if err != nil {
+				return "", err
+			}
+			for j := i + 1; j < end; j++ {
+				inPattern[j] = true
+			}
+		}
+	}
+	if err := rejectPatternsOutsideMatch(tokens, inPattern); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for i, t := range tokens {
+		if s, ok := insertions[i]; ok {
+			sb.WriteString(s)
+		}
+		sb.WriteString(t.text)
+	}
+	if s, ok := insertions[len(tokens)]; ok {
+		sb.WriteString(s)
+	}
+	return sb.String(), nil
+}
+
+// validateReadOnly は、複数ステートメントと禁止キーワードを検出します。
+// プロパティアクセス（例: n.set）はキーワードとして扱いません。
+func validateReadOnly(tokens []token) error {
+	prev := -1
+	for i, t := range tokens {
+		switch t.kind {
+		case tokenSymbol:
+			if t.text == ";" {
+				return fmt.Errorf("Cypher: multiple statements are not allowed")
+			}
+		case tokenWord:
+			upper := strings.ToUpper(t.text)
+			isProperty := prev >= 0 && tokens[prev].kind == tokenSymbol && tokens[prev].text == "."
+			if forbiddenKeywords[upper] && !isProperty {
+				return fmt.Errorf("Cypher: %s is not allowed in read-only queries", upper)
+			}
+		}
+		if t.kind != tokenSpace {
+			prev = i
+		}
+	}
+	return nil
+}
+
+// scopeMatchClause は、MATCH 句のパターン部分を走査し、ノード・リレーションパターンに
+// memory_group の条件を注入します。
+// パターン部分は、同じ括弧の深さで次の句のキーワードが現れるか、
+// 外側の括弧が閉じられた（サブクエリの終端）時点で終わります。
+// 返り値はパターン部分の終端（次の句のキーワードまたは閉じ括弧）の位置です。
+func scopeMatchClause(tokens []token, start int, scope string, insertions map[int]string) (int, error) {
+	for i := start; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.kind == tokenWord && clauseKeywords[strings.ToUpper(t.text)]:
+			return i, nil
+		case t.kind == tokenSymbol && (t.text == "}" || t.text == ")" || t.text == "]"):
+			return i, nil
+		case t.kind == tokenSymbol && t.text == "(":
+			end, err := matchingIndex(tokens, i)
+			if err != nil {
+				return -1, err
+			}
+			if err := scopeNodePattern(tokens, i, end, scope, insertions); err != nil {
+				return -1, err
+			}
+			i = end
+		case t.kind == tokenSymbol && t.text == "[":
+			end, err := matchingIndex(tokens, i)
+			if err != nil {
+				return -1, err
+			}
+			if err := scopeRelPattern(tokens, i, end, scope, insertions); err != nil {
+				return -1, err
+			}
+			i = end
+		case t.kind == tokenSymbol && t.text == "{":
+			return -1, fmt.Errorf("Cypher: unexpected '{' in MATCH pattern")
+		}
+	}
+	return len(tokens), nil
+}
+
+// rejectPatternsOutsideMatch は、MATCH 句のパターン部分の外にあるリレーションのパターンを検出します。
+// ノードパターンの閉じ括弧の直後に "--"、"-["、"<-" が続く箇所をリレーションのパターンとみなすため、
+// 式の中の "(a)--1" のような記述も拒否されます。
+func rejectPatternsOutsideMatch(tokens []token, inPattern []bool) error {
+	idx := significantIndexes(tokens, 0, len(tokens))
+	for k := 0; k+2 < len(idx); k++ {
+		if inPattern[idx[k]] || !isSymbol(tokens[idx[k]], ")") {
+			continue
+		}
+		first, second := tokens[idx[k+1]], tokens[idx[k+2]]
+		if (isSymbol(first, "-") && (isSymbol(second, "-") || isSymbol(second, "["))) ||
+			(isSymbol(first, "<") && isSymbol(second, "-")) {
+			return fmt.Errorf("Cypher: patterns are only allowed in MATCH clauses")
+		}
+	}
+	return nil
+}
+
+// isSymbol は、トークンが指定した記号かを判定します。
+func isSymbol(t token, text string) bool {
+	return t.kind == tokenSymbol && t.text == text
+}
+
+// scopeNodePattern は、ノードパターン (open, close) を検証し、スコープ条件を注入します。
+func scopeNodePattern(tokens []token, open int, close int, scope string, insertions map[int]string) error {
+	labels, mapOpen, err := parsePattern(tokens, open, close)
+	if err != nil {
+		return err
+	}
+	for _, l := range labels {
+		if !containsTable(nodeTables, l) {
+			return fmt.Errorf("Cypher: node table %s is not allowed", l)
+		}
+	}
+	return injectScope(tokens, close, mapOpen, scope, insertions)
+}
+
+// scopeRelPattern は、リレーションパターン (open, close) を検証し、スコープ条件を注入します。
+// 可変長リレーションはエラーとなります。
+func scopeRelPattern(tokens []token, open int, close int, scope string, insertions map[int]string) error {
+	labels, mapOpen, err := parsePattern(tokens, open, close)
+	if err != nil {
+		return err
+	}
+	for _, l := range labels {
+		if !containsTable(relTables, l) {
+			return fmt.Errorf("Cypher: rel table %s is not allowed", l)
+		}
+	}
+	for _, j := range significantIndexes(tokens, open+1, close) {
+		if tokens[j].kind == tokenSymbol && tokens[j].text == "*" {
+			return fmt.Errorf("Cypher: variable-length relationships are not allowed")
+		}
+	}
+	return injectScope(tokens, close, mapOpen, scope, insertions)
+}
+
+// parsePattern は、パターンの括弧内（深さ0）からラベルとプロパティマップの開始位置を取り出します。
+// プロパティマップで memory_group を指定している場合はエラーを返します。
+func parsePattern(tokens []token, open int, close int) ([]string, int, error) {
+	labels := []string{}
+	mapOpen := -1
+	idx := significantIndexes(tokens, open+1, close)
+	for k := 0; k < len(idx); k++ {
+		t := tokens[idx[k]]
+		if t.kind != tokenSymbol {
+			continue
+		}
+		switch t.text {
+		case ":", "|":
+			if k+1 < len(idx) {
+				next := tokens[idx[k+1]]
+				if next.kind == tokenWord || next.kind == tokenQuoted {
+					labels = append(labels, strings.Trim(next.text, "`"))
+					k++
+				}
+			}
+		case "{":
+			end, err := matchingIndex(tokens, idx[k])
+			if err != nil {
+				return nil, -1, err
+			}
+			if err := rejectScopeKey(tokens, idx[k], end); err != nil {
+				return nil, -1, err
+			}
+			if mapOpen < 0 {
+				mapOpen = idx[k]
+			}
+			k = skipTo(idx, end)
+		case "(", "[":
+			end, err := matchingIndex(tokens, idx[k])
+			if err != nil {
+				return nil, -1, err
+			}
+			k = skipTo(idx, end)
+		}
+	}
+	return labels, mapOpen, nil
+}
+
+// rejectScopeKey は、プロパティマップ (open, close) の直下に memory_group キーがあればエラーを返します。
+func rejectScopeKey(tokens []token, open int, close int) error {
+	idx := significantIndexes(tokens, open+1, close)
+	depth := 0
+	for k, j := range idx {
+		t := tokens[j]
+		if t.kind == tokenSymbol {
+			switch t.text {
+			case "{", "(", "[":
+				depth++
+			case "}", ")", "]":
+				depth--
+			}
+			continue
+		}
+		if depth != 0 || k+1 >= len(idx) {
+			continue
+		}
+		next := tokens[idx[k+1]]
+		if next.kind == tokenSymbol && next.text == ":" && strings.EqualFold(strings.Trim(t.text, "`"), memoryGroupKey) {
+			return fmt.Errorf("Cypher: %s must not be specified in patterns; it is applied automatically", memoryGroupKey)
+		}
+	}
+	return nil
+}
+
+// injectScope は、パターンにスコープ条件を注入します。
+// プロパティマップがあればその先頭に、なければ閉じ括弧の直前に新しいマップとして追加します。
+func injectScope(tokens []token, close int, mapOpen int, scope string, insertions map[int]string) error {
+	if mapOpen < 0 {
+		insertions[close] = " {" + scope + "}"
+		return nil
+	}
+	idx := significantIndexes(tokens, mapOpen+1, len(tokens))
+	if len(idx) > 0 && tokens[idx[0]].kind == tokenSymbol && tokens[idx[0]].text == "}" {
+		insertions[mapOpen+1] = scope
+	} else {
+		insertions[mapOpen+1] = scope + ", "
+	}
+	return nil
+}
+
+// matchingIndex は、open の位置にある括弧に対応する閉じ括弧の位置を返します。
+func matchingIndex(tokens []token, open int) (int, error) {
+	pairs := map[string]string{"(": ")", "[": "]", "{": "}"}
+	stack := []string{}
+	for i := open; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.kind != tokenSymbol {
+			continue
+		}
+		if closer, ok := pairs[t.text]; ok {
+			stack = append(stack, closer)
+			continue
+		}
+		if t.text == ")" || t.text == "]" || t.text == "}" {
+			if len(stack) == 0 || stack[len(stack)-1] != t.text {
+				return -1, fmt.Errorf("Cypher: unbalanced '%s'", t.text)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, fmt.Errorf("Cypher: unclosed '%s'", tokens[open].text)
+}
+
+// significantIndexes は、[from, to) の範囲にある空白・コメント以外のトークンの位置を返します。
+func significantIndexes(tokens []token, from int, to int) []int {
+	idx := []int{}
+	for i := from; i < to && i < len(tokens); i++ {
+		if tokens[i].kind != tokenSpace {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// skipTo は、idx の中で位置 pos を指す要素の添字を返します。
+func skipTo(idx []int, pos int) int {
+	for k, j := range idx {
+		if j == pos {
+			return k
+		}
+	}
+	return len(idx)
+}
+
+// trimTrailingSemicolon は、末尾のセミコロン（と後続の空白）を取り除きます。
+func trimTrailingSemicolon(tokens []token) []token {
+	idx := significantIndexes(tokens, 0, len(tokens))
+	if len(idx) == 0 {
+		return tokens
+	}
+	last := idx[len(idx)-1]
+	if tokens[last].kind == tokenSymbol && tokens[last].text == ";" {
+		return tokens[:last]
+	}
+	return tokens
+}
+
+// containsTable は、テーブル名が許可リストに含まれるかを大文字小文字を区別せずに判定します。
+func containsTable(tables []types.TableName, name string) bool {
+	for _, t := range tables {
+		if strings.EqualFold(string(t), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeString は、Cypher の文字列リテラル用にシングルクォートとバックスラッシュをエスケープします。
+func escapeString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "'", `\'`)
+}
+
+// tokenize は、Cypher クエリをトークン列に分解します。
+// 文字列リテラル・バッククォート識別子・コメントを1つのトークンとして扱うため、
+// それらの内部に含まれるキーワードや記号は検証の対象になりません。
+func tokenize(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := []token{}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			start := i
+			for i < len(runes) && unicode.IsSpace(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenSpace, text: string(runes[start:i])})
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			start := i
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenSpace, text: string(runes[start:i])})
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := i
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("Cypher: unterminated comment")
+			}
+			i += 2
+			tokens = append(tokens, token{kind: tokenSpace, text: string(runes[start:i])})
+		case r == '\'' || r == '"' || r == '`':
+			start := i
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && r != '`' {
+					i += 2
+					continue
+				}
+				if runes[i] == r {
+					i++
+					closed = true
+					break
+				}
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("Cypher: unterminated literal")
+			}
+			kind := tokenString
+			if r == '`' {
+				kind = tokenQuoted
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[start:i])})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i])})
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(r)})
+			i++
+		}
+	}
+	return tokens, nil
+}
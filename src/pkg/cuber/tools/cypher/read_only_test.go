@@ -0,0 +1,126 @@
+package cypher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScopeReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "labelled node",
+			query: "MATCH (n:GraphNode) RETURN n.id",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1'}) RETURN n.id",
+		},
+		{
+			name:  "existing property map",
+			query: "MATCH (n:GraphNode {type: 'person'}) RETURN n.id;",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1', type: 'person'}) RETURN n.id",
+		},
+		{
+			name:  "relationship and unlabelled node",
+			query: "MATCH (a:GraphNode)-[e:GraphEdge]->(b) RETURN a.id, e.type, b.id",
+			want:  "MATCH (a:GraphNode {memory_group: 'g1'})-[e:GraphEdge {memory_group: 'g1'}]->(b {memory_group: 'g1'}) RETURN a.id, e.type, b.id",
+		},
+		{
+			name:  "optional match and where",
+			query: "MATCH (d:Data) OPTIONAL MATCH (d)-[:HAS_DOCUMENT]->(doc:Document) WHERE d.name CONTAINS 'x' RETURN d.name, count(doc)",
+			want:  "MATCH (d:Data {memory_group: 'g1'}) OPTIONAL MATCH (d {memory_group: 'g1'})-[:HAS_DOCUMENT {memory_group: 'g1'}]->(doc:Document {memory_group: 'g1'}) WHERE d.name CONTAINS 'x' RETURN d.name, count(doc)",
+		},
+		{
+			name:  "keywords as properties and in literals",
+			query: "MATCH (n:GraphNode) WHERE n.properties CONTAINS 'DELETE' RETURN n.set, n.comment",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1'}) WHERE n.properties CONTAINS 'DELETE' RETURN n.set, n.comment",
+		},
+		{
+			name:  "exists subquery",
+			query: "MATCH (n:GraphNode) WHERE EXISTS { MATCH (n)-[:GraphEdge]->(m:GraphNode) } RETURN n.id",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1'}) WHERE EXISTS { MATCH (n {memory_group: 'g1'})-[:GraphEdge {memory_group: 'g1'}]->(m:GraphNode {memory_group: 'g1'}) } RETURN n.id",
+		},
+		{
+			name:  "count subquery with where",
+			query: "MATCH (n:GraphNode) RETURN n.id, COUNT { MATCH (n)<-[e:GraphEdge]-(m) WHERE e.weight > 0.5 }",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1'}) RETURN n.id, COUNT { MATCH (n {memory_group: 'g1'})<-[e:GraphEdge {memory_group: 'g1'}]-(m {memory_group: 'g1'}) WHERE e.weight > 0.5 }",
+		},
+		{
+			name:  "list comprehension and arithmetic",
+			query: "MATCH (n:GraphNode) RETURN [x IN range(1, 3) WHERE x > (n.weight) - 1 | x * 2]",
+			want:  "MATCH (n:GraphNode {memory_group: 'g1'}) RETURN [x IN range(1, 3) WHERE x > (n.weight) - 1 | x * 2]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScopeReadOnlyQuery(tt.query, "g1")
+			if err != nil {
+				t.Fatalf("ScopeReadOnlyQuery failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unexpected query:\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeReadOnlyQueryEscapesMemoryGroup(t *testing.T) {
+	got, err := ScopeReadOnlyQuery("MATCH (n:Chunk) RETURN n.id", `o'brien\`)
+	if err != nil {
+		t.Fatalf("ScopeReadOnlyQuery failed: %v", err)
+	}
+	if want := `MATCH (n:Chunk {memory_group: 'o\'brien\\'}) RETURN n.id`; got != want {
+		t.Errorf("Unexpected query: %s", got)
+	}
+}
+
+func TestScopeReadOnlyQueryRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"empty", " ; ", "query is empty"},
+		{"create", "CREATE (n:GraphNode {id: 'x'})", "CREATE is not allowed"},
+		{"merge", "MERGE (n:GraphNode {id: 'x'}) RETURN n", "MERGE is not allowed"},
+		{"set", "MATCH (n:GraphNode) SET n.type = 'x'", "SET is not allowed"},
+		{"detach delete", "MATCH (n:GraphNode) DETACH DELETE n", "DETACH is not allowed"},
+		{"lower case delete", "match (n:GraphNode) delete n", "DELETE is not allowed"},
+		{"drop table", "DROP TABLE GraphNode", "DROP is not allowed"},
+		{"comment on table", "COMMENT ON TABLE GraphNode IS 'x'", "COMMENT is not allowed"},
+		{"copy", "COPY GraphNode FROM 'nodes.csv'", "COPY is not allowed"},
+		{"load from", "LOAD FROM 'nodes.csv' RETURN *", "LOAD is not allowed"},
+		{"call", "CALL show_tables() RETURN *", "CALL is not allowed"},
+		{"transaction control", "BEGIN TRANSACTION", "BEGIN is not allowed"},
+		{"multiple statements", "MATCH (n:GraphNode) RETURN n; MATCH (m:Chunk) RETURN m", "multiple statements"},
+		{"write hidden after comment", "MATCH (n:GraphNode) /* read */ DELETE n", "DELETE is not allowed"},
+		{"variable-length", "MATCH (a:GraphNode)-[:GraphEdge*1..3]->(b:GraphNode) RETURN b.id", "variable-length relationships are not allowed"},
+		{"unlabelled variable-length", "MATCH (a:GraphNode)-[*]->(b:GraphNode) RETURN b.id", "variable-length relationships are not allowed"},
+		{"shortest path", "MATCH (a:GraphNode)-[e* SHORTEST 1..5]->(b:GraphNode) RETURN length(e)", "variable-length relationships are not allowed"},
+		{"node table", "MATCH (n:PromptOverride) RETURN n", "node table PromptOverride is not allowed"},
+		{"rel table", "MATCH (a:GraphNode)-[:UNKNOWN_REL]->(b:GraphNode) RETURN a", "rel table UNKNOWN_REL is not allowed"},
+		{"memory_group in pattern", "MATCH (n:GraphNode {memory_group: 'other'}) RETURN n", "memory_group must not be specified"},
+		{"where pattern predicate", "MATCH (n:GraphNode) WHERE (n)-[:GraphEdge]->(:GraphNode {id: 'x'}) RETURN n", "patterns are only allowed in MATCH clauses"},
+		{"where undirected pattern", "MATCH (n:GraphNode) WHERE NOT (n)--() RETURN n", "patterns are only allowed in MATCH clauses"},
+		{"where incoming pattern", "MATCH (n:GraphNode) WHERE (n)<-[:GraphEdge]-() RETURN n", "patterns are only allowed in MATCH clauses"},
+		{"exists without match", "MATCH (n:GraphNode) WHERE EXISTS { (n)-[:GraphEdge]->(m) } RETURN n", "patterns are only allowed in MATCH clauses"},
+		{"where in exists subquery", "MATCH (n:GraphNode) WHERE EXISTS { MATCH (n)-[]->(m) WHERE (m)-->() } RETURN n", "patterns are only allowed in MATCH clauses"},
+		{"pattern comprehension", "MATCH (n:GraphNode) RETURN [(n)-[:GraphEdge]->(m) | m.id]", "patterns are only allowed in MATCH clauses"},
+		{"pattern in list comprehension", "MATCH (n:GraphNode) RETURN [x IN [1] WHERE (n)-->() | x]", "patterns are only allowed in MATCH clauses"},
+		{"pattern in with", "MATCH (n:GraphNode) WITH n, (n)-[:GraphEdge]->() AS p RETURN p", "patterns are only allowed in MATCH clauses"},
+		{"unbalanced", "MATCH (n:GraphNode RETURN n", "unclosed '('"},
+		{"unterminated literal", "MATCH (n:GraphNode) WHERE n.id = 'x RETURN n", "unterminated literal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScopeReadOnlyQuery(tt.query, "g1")
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got query %q", tt.wantErr, got)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package query
 import (
 	"context"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/components/embedding"
+	"github.com/t-kawata/mycute/pkg/cuber/providers"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
 	"github.com/t-kawata/mycute/pkg/cuber/utils"
 )
@@ -14,19 +16,70 @@ import (
 type EinoEmbedderAdapter struct {
 	embedder  embedding.Embedder
 	modelName string
+	limits    providers.EmbeddingBatchLimits
 }
 
 // NewEinoEmbedderAdapter は新しい Eino ベースの Embedder アダプターを作成します。
-func NewEinoEmbedderAdapter(emb embedding.Embedder, modelName string) *EinoEmbedderAdapter {
+// limits は EmbedBatch で1回のリクエストにまとめる入力の上限です（providers.GetEmbeddingBatchLimits）。
+func NewEinoEmbedderAdapter(emb embedding.Embedder, modelName string, limits providers.EmbeddingBatchLimits) *EinoEmbedderAdapter {
+	if limits.MaxBatchSize <= 0 {
+		limits.MaxBatchSize = 1
+	}
 	return &EinoEmbedderAdapter{
 		embedder:  emb,
 		modelName: modelName,
+		limits:    limits,
 	}
 }
 
 // EmbedQuery はテキストをベクトル化し、トークン使用量を返します。
 // storage.Embedder インターフェースを満たします。
 func (a *EinoEmbedderAdapter) EmbedQuery(ctx context.Context, text string) ([]float32, types.TokenUsage, error) {
+	vectors, usage, err := a.embedStrings(ctx, []string{text})
+	if err != nil {
+		return nil, usage, err
+	}
+	return vectors[0], usage, nil
+}
+
+// EmbedBatch は複数のテキストをまとめてベクトル化し、トークン使用量を返します。
+// プロバイダーの上限（入力数・トークン数）を超えないようにリクエストを分割します。
+// storage.Embedder インターフェースを満たします。
+func (a *EinoEmbedderAdapter) EmbedBatch(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error) {
+	var totalUsage types.TokenUsage
+	results := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); {
+		end := a.batchEnd(texts, start)
+		vectors, usage, err := a.embedStrings(ctx, texts[start:end])
+		totalUsage.Add(usage)
+		if err != nil {
+			return nil, totalUsage, fmt.Errorf("eino embed batch error (inputs %d-%d of %d): %w", start+1, end, len(texts), err)
+		}
+		results = append(results, vectors...)
+		start = end
+	}
+	return results, totalUsage, nil
+}
+
+// batchEnd は、texts[start:] のうち1回のリクエストにまとめる範囲の終端を返します。
+// トークン数は文字数で見積もります（多めに見積もることで上限を超えないようにする）。
+// 1件で上限を超えるテキストは、そのテキストだけでリクエストします。
+func (a *EinoEmbedderAdapter) batchEnd(texts []string, start int) int {
+	end := start
+	tokens := 0
+	for end < len(texts) && end-start < a.limits.MaxBatchSize {
+		t := utf8.RuneCountInString(texts[end])
+		if a.limits.MaxBatchTokens > 0 && end > start && tokens+t > a.limits.MaxBatchTokens {
+			break
+		}
+		tokens += t
+		end++
+	}
+	return end
+}
+
+// embedStrings は、1回のリクエストでテキストをベクトル化します。
+func (a *EinoEmbedderAdapter) embedStrings(ctx context.Context, texts []string) ([][]float32, types.TokenUsage, error) {
 	// トークン集計器の作成
 	agg := utils.NewTokenUsageAggregator(a.modelName)
 
@@ -38,21 +91,28 @@ func (a *EinoEmbedderAdapter) EmbedQuery(ctx context.Context, text string) ([]fl
 	ctx = callbacks.InitCallbacks(ctx, runInfo, agg.Handler())
 
 	// Embeddings の実行
-	vectors, err := a.embedder.EmbedStrings(ctx, []string{text})
+	vectors, err := a.embedder.EmbedStrings(ctx, texts)
 	if err != nil {
 		return nil, agg.TotalUsage, fmt.Errorf("eino embed error: %w", err)
 	}
 
-	if len(vectors) == 0 {
-		return nil, agg.TotalUsage, fmt.Errorf("no embeddings returned")
+	if len(vectors) != len(texts) {
+		if len(vectors) == 0 {
+			return nil, agg.TotalUsage, fmt.Errorf("no embeddings returned")
+		}
+		return nil, agg.TotalUsage, fmt.Errorf("embeddings count mismatch: expected %d, got %d", len(texts), len(vectors))
 	}
 
 	// 型変換 ([]float64 -> []float32)
 	// Einoの仕様上、通常はfloat64で返却されます
-	resultVector := make([]float32, len(vectors[0]))
-	for i, v := range vectors[0] {
-		resultVector[i] = float32(v)
+	results := make([][]float32, len(vectors))
+	for i, vector := range vectors {
+		resultVector := make([]float32, len(vector))
+		for j, v := range vector {
+			resultVector[j] = float32(v)
+		}
+		results[i] = resultVector
 	}
 
-	return resultVector, agg.TotalUsage, nil
+	return results, agg.TotalUsage, nil
 }
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/t-kawata/mycute/pkg/cuber/providers"
+)
+
+// recordingEinoEmbedder は、リクエストごとの入力数を記録し、テキストの文字数を1次元のベクトルとして返すテスト用の Eino Embedder です。
+type recordingEinoEmbedder struct {
+	batchSizes []int
+	dropLast   bool // 応答のベクトルを1件少なくする
+}
+
+func (e *recordingEinoEmbedder) EmbedStrings(ctx context.Context, texts []string, opts ...embedding.Option) ([][]float64, error) {
+	e.batchSizes = append(e.batchSizes, len(texts))
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, []float64{float64(len(text))})
+	}
+	if e.dropLast && len(vectors) > 0 {
+		vectors = vectors[:len(vectors)-1]
+	}
+	return vectors, nil
+}
+
+func TestEinoEmbedderAdapterEmbedBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		limits         providers.EmbeddingBatchLimits
+		texts          []string
+		dropLast       bool
+		wantBatchSizes []int
+		wantErr        bool
+	}{
+		{
+			name:   "no texts",
+			limits: providers.EmbeddingBatchLimits{MaxBatchSize: 2},
+		},
+		{
+			name:           "split by batch size",
+			limits:         providers.EmbeddingBatchLimits{MaxBatchSize: 2},
+			texts:          []string{"a", "b", "c", "d", "e"},
+			wantBatchSizes: []int{2, 2, 1},
+		},
+		{
+			name:           "split by tokens",
+			limits:         providers.EmbeddingBatchLimits{MaxBatchSize: 10, MaxBatchTokens: 5},
+			texts:          []string{"aaa", "bb", "c", "dddd"},
+			wantBatchSizes: []int{2, 2},
+		},
+		{
+			name:           "text over the token limit is sent alone",
+			limits:         providers.EmbeddingBatchLimits{MaxBatchSize: 10, MaxBatchTokens: 3},
+			texts:          []string{"aaaaa", "b", "c"},
+			wantBatchSizes: []int{1, 2},
+		},
+		{
+			name:           "tokens are counted in characters",
+			limits:         providers.EmbeddingBatchLimits{MaxBatchSize: 10, MaxBatchTokens: 4},
+			texts:          []string{"東京都", "大阪"},
+			wantBatchSizes: []int{1, 1},
+		},
+		{
+			name:           "invalid batch size",
+			texts:          []string{"a", "b"},
+			wantBatchSizes: []int{1, 1},
+		},
+		{
+			name:           "embeddings count mismatch",
+			limits:         providers.EmbeddingBatchLimits{MaxBatchSize: 10},
+			texts:          []string{"a", "b"},
+			dropLast:       true,
+			wantBatchSizes: []int{2},
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emb := &recordingEinoEmbedder{dropLast: tt.dropLast}
+			adapter := NewEinoEmbedderAdapter(emb, "test-embedding-model", tt.limits)
+			vectors, _, err := adapter.EmbedBatch(context.Background(), tt.texts)
+			if !slices.Equal(emb.batchSizes, tt.wantBatchSizes) {
+				t.Errorf("batch sizes = %v, want %v", emb.batchSizes, tt.wantBatchSizes)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %d vectors", len(vectors))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EmbedBatch failed: %v", err)
+			}
+			if len(vectors) != len(tt.texts) {
+				t.Fatalf("len(vectors) = %d, want %d", len(vectors), len(tt.texts))
+			}
+			for i, text := range tt.texts {
+				if vectors[i][0] != float32(len(text)) {
+					t.Errorf("vector of %q = %v, want [%d]", text, vectors[i], len(text))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// globalPoint は、グローバル検索の map で抽出された要点です。
+type globalPoint struct {
+	Description string `json:"description"`
+	Score       int    `json:"score"`
+}
+
+// globalMapResult は、GLOBAL_QUERY_MAP_PROMPT の出力です。
+type globalMapResult struct {
+	Points []globalPoint `json:"points"`
+}
+
+// getGlobalAnswer は、コミュニティの要約を map-reduce して、知識全体にわたる質問に回答します (QUERY_TYPE_GLOBAL)。
+// 「取り込んだ知識の主なテーマは何か」のように、特定のエンティティの近傍だけでは答えられない質問に使用します。
+//
+// 処理の流れ:
+//  1. 指定された階層のコミュニティの要約を取得する（階層が存在しない場合は最も細かい階層）
+//  2. 要約を Weight の大きい順に GLOBAL_QUERY_MAP_BATCH_CHARS 文字ずつに分け、各バッチから質問に関連する要点をスコア付きで抽出する (map)
+//  3. スコアの高い順に最大 GLOBAL_QUERY_MAX_POINTS 件の要点から回答を生成する (reduce)
+//
+// コミュニティの要約は Absorb / Memify の完了時に更新されます。要約がない場合は空の回答を返します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - query: 質問
+//   - config: クエリ設定（CommunityLevel で階層を指定）
+//
+// 返り値:
+//   - answer: 回答
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) getGlobalAnswer(ctx context.Context, query string, config types.QueryConfig) (answer *string, usage types.TokenUsage, err error) {
+	// 1. コミュニティの要約を取得
+	communities, err := t.GraphStorage.GetCommunities(ctx, t.memoryGroup, -1)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to get communities: %w", err)
+		return
+	}
+	maxLevel := -1
+	for _, c := range communities {
+		if c.Summary != "" && c.Level > maxLevel {
+			maxLevel = c.Level
+		}
+	}
+	level := min(config.CommunityLevel, maxLevel)
+	var selected []*storage.Community
+	for _, c := range communities {
+		if c.Level == level && c.Summary != "" {
+			selected = append(selected, c)
+		}
+	}
+	if len(selected) == 0 {
+		tmp := ""
+		answer = &tmp
+		return
+	}
+	utils.LogDebug(t.Logger, "GraphCompletionTool: Global search", zap.Int("level", level), zap.Int("communities", len(selected)))
+
+	// 2. map: バッチごとに要点を抽出
+	var batches []string
+	var batch strings.Builder
+	for _, c := range selected {
+		report := fmt.Sprintf("## %s\n%s\n\n", c.Title, c.Summary)
+		if batch.Len() > 0 && batch.Len()+len(report) > appconfig.GLOBAL_QUERY_MAP_BATCH_CHARS {
+			batches = append(batches, batch.String())
+			batch.Reset()
+		}
+		batch.WriteString(report)
+	}
+	if batch.Len() > 0 {
+		batches = append(batches, batch.String())
+	}
+	var points []globalPoint
+	for _, reports := range batches {
+		if ctx.Err() != nil {
+			err = context.Cause(ctx)
+			return
+		}
+		batchPoints, u, errr := t.mapGlobalReports(ctx, query, reports)
+		usage.Add(u)
+		if errr != nil {
+			err = errr
+			return
+		}
+		points = append(points, batchPoints...)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Score > points[j].Score
+	})
+	if len(points) > appconfig.GLOBAL_QUERY_MAX_POINTS {
+		points = points[:appconfig.GLOBAL_QUERY_MAX_POINTS]
+	}
+
+	// 3. reduce: 要点から回答を生成
+	var pointsText strings.Builder
+	for _, p := range points {
+		pointsText.WriteString(fmt.Sprintf("- [score: %d] %s\n", p.Score, p.Description))
+	}
+	if len(points) == 0 {
+		pointsText.WriteString("(no relevant key points were found)\n")
+	}
+	userPrompt := fmt.Sprintf("User Question: %s\n\nKey Points:\n%s", query, pointsText.String())
+	promptName := string(prompts.NAME_GLOBAL_QUERY_REDUCE_JA)
+	systemPrompt := prompts.Get(ctx, prompts.NAME_GLOBAL_QUERY_REDUCE_JA)
+	if config.IsEn {
+		promptName = string(prompts.NAME_GLOBAL_QUERY_REDUCE_EN)
+		systemPrompt = prompts.Get(ctx, prompts.NAME_GLOBAL_QUERY_REDUCE_EN)
+	}
+
+	// Emit Generation Start (Final Answer)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  promptName,
+	})
+
+	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  u,
+		Response:    answerContent,
+	})
+
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to generate final answer: %w", err)
+		return
+	}
+	if answerContent == "" {
+		err = errors.New("GraphCompletionTool: No final answer generated.")
+		return
+	}
+	answer = &answerContent
+	return
+}
+
+// mapGlobalReports は、コミュニティの要約のバッチから、質問に関連する要点をスコア付きで抽出します。
+// LLM の出力を解析できない場合は、そのバッチから要点が得られなかったものとして扱います。
+func (t *GraphCompletionTool) mapGlobalReports(ctx context.Context, query string, reports string) (points []globalPoint, usage types.TokenUsage, err error) {
+	systemPrompt := prompts.Get(ctx, prompts.NAME_GLOBAL_QUERY_MAP)
+	userPrompt := fmt.Sprintf("User Question: %s\n\nCommunity Reports:\n%s", query, reports)
+
+	// Emit Generation Start (Map)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  string(prompts.NAME_GLOBAL_QUERY_MAP),
+	})
+
+	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  u,
+		Response:    content,
+	})
+
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to extract key points: %w", err)
+		return
+	}
+	jsonStart := strings.Index(content, "{")
+	jsonEnd := strings.LastIndex(content, "}")
+	if jsonStart >= 0 && jsonEnd > jsonStart {
+		content = content[jsonStart : jsonEnd+1]
+	}
+	var result globalMapResult
+	if errr := json.Unmarshal([]byte(content), &result); errr != nil {
+		utils.LogWarn(t.Logger, "GraphCompletionTool: Failed to parse key points, skipping batch", zap.Error(errr), zap.String("response", content))
+		return
+	}
+	for _, p := range result.Points {
+		p.Description = strings.TrimSpace(p.Description)
+		if p.Description == "" || p.Score <= 0 {
+			continue
+		}
+		points = append(points, p)
+	}
+	return
+}
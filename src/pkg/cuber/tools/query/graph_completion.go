@@ -17,6 +17,7 @@ import (
 	appconfig "github.com/t-kawata/mycute/config"
 	"github.com/t-kawata/mycute/lib/eventbus"
 	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
 	"github.com/t-kawata/mycute/pkg/cuber/prompts"
 	"github.com/t-kawata/mycute/pkg/cuber/storage"
 	"github.com/t-kawata/mycute/pkg/cuber/types"
@@ -39,6 +40,9 @@ type GraphCompletionTool struct {
 	ModelName     string                     // 使用するモデル名（トークン集計用）
 	Logger        *zap.Logger                // ロガー
 	EventBus      *eventbus.EventBus
+	// 出典として収集したチャンクID（Query 1回ごとにリセットされる。検索で得られた順序を保持）
+	citationChunkIDs []string
+	citationSeen     map[string]bool
 }
 
 // NewGraphCompletionTool は、新しいGraphCompletionToolを作成します。
@@ -77,11 +81,16 @@ func NewGraphCompletionTool(vectorStorage storage.VectorStorage, graphStorage st
 //
 // 返り値:
 //   - string: 検索結果（回答）
-func (t *GraphCompletionTool) Query(ctx context.Context, query string, config types.QueryConfig) (answer *string, chunks *string, summaries *string, graph *[]*storage.Triple, embedding *[]float32, usage types.TokenUsage, err error) {
+//   - citations: 回答の根拠となったチャンクの出典（ベクトル検索で得たチャンクと、グラフのエッジの抽出元チャンク）
+//
+// 注意: 出典の収集にツール内部の状態を使用するため、1つのインスタンスで Query を並行実行しないでください。
+func (t *GraphCompletionTool) Query(ctx context.Context, query string, config types.QueryConfig) (answer *string, chunks *string, summaries *string, graph *[]*storage.Triple, citations *[]*storage.Citation, embedding *[]float32, usage types.TokenUsage, err error) {
 	if !types.IsValidQueryType(uint8(config.QueryType)) {
 		err = fmt.Errorf("GraphCompletionTool: Unknown query type: %d", config.QueryType)
 		return
 	}
+	t.citationChunkIDs = nil
+	t.citationSeen = make(map[string]bool)
 
 	// 検索クエリを正規化（FTS・ベクトル検索の整合性確保）
 	query = utils.NormalizeForSearch(query)
@@ -94,6 +103,9 @@ func (t *GraphCompletionTool) Query(ctx context.Context, query string, config ty
 	})
 
 	defer func() {
+		if err == nil {
+			citations = t.resolveCitations(ctx)
+		}
 		if err != nil {
 			// Emit Query Error
 			eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_ERROR), event.QueryErrorPayload{
@@ -211,6 +223,37 @@ func (t *GraphCompletionTool) Query(ctx context.Context, query string, config ty
 			embedding, answer, usage, err = t.getGraphCompletionJA(ctx, config.ChunkTopk, config.EntityTopk, query, nil, config)
 		}
 		return
+	case types.QUERY_TYPE_NATURAL_LANGUAGE:
+		answer, usage, err = t.getNaturalLanguageAnswer(ctx, query, config.IsEn)
+		return
+	case types.QUERY_TYPE_GRAPH_COMPLETION_COT:
+		if config.ChunkTopk == 0 || config.EntityTopk == 0 {
+			err = fmt.Errorf("GraphCompletionTool: ChunkTopk and EntityTopk must be greater than 0")
+			return
+		}
+		rounds := config.CotRounds
+		if rounds == 0 {
+			rounds = appconfig.DEFAULT_COT_ROUNDS
+		}
+		embedding, answer, graph, usage, err = t.getGraphCompletionCoT(ctx, config.ChunkTopk, config.EntityTopk, rounds, query, config)
+		return
+	case types.QUERY_TYPE_TEMPORAL:
+		if config.ChunkTopk == 0 || config.EntityTopk == 0 {
+			err = fmt.Errorf("GraphCompletionTool: ChunkTopk and EntityTopk must be greater than 0")
+			return
+		}
+		embedding, answer, graph, usage, err = t.getTemporalAnswer(ctx, config.ChunkTopk, config.EntityTopk, query, config)
+		return
+	case types.QUERY_TYPE_HYBRID:
+		if config.ChunkTopk == 0 || config.EntityTopk == 0 {
+			err = fmt.Errorf("GraphCompletionTool: ChunkTopk and EntityTopk must be greater than 0")
+			return
+		}
+		embedding, answer, chunks, graph, usage, err = t.getHybridAnswer(ctx, config.ChunkTopk, config.EntityTopk, query, config)
+		return
+	case types.QUERY_TYPE_GLOBAL:
+		answer, usage, err = t.getGlobalAnswer(ctx, query, config)
+		return
 	default:
 		err = fmt.Errorf("GraphCompletionTool: Unknown query type: %d", config.QueryType)
 		return
@@ -393,6 +436,17 @@ func (t *GraphCompletionTool) getGraph(ctx context.Context, entityTopk int, quer
 		err = fmt.Errorf("GraphCompletionTool: Graph traversal failed: %w", err)
 		return
 	}
+	// 時系列検索では、観測時刻（エッジの unix）が対象期間内のエッジのみを使用する
+	isTemporal := config.QueryType == types.QUERY_TYPE_TEMPORAL
+	if isTemporal {
+		inRange := make([]*storage.Triple, 0, len(triples))
+		for _, triple := range triples {
+			if config.TimeRange.ContainsUnixMilli(triple.Edge.Unix) {
+				inRange = append(inRange, triple)
+			}
+		}
+		triples = inRange
+	}
 
 	// ========================================
 	// 3. Thickness スコアリングとフィルタリング
@@ -408,6 +462,10 @@ func (t *GraphCompletionTool) getGraph(ctx context.Context, entityTopk int, quer
 		if getMaxErr != nil {
 			utils.LogWarn(t.Logger, "Failed to get MaxUnix, skipping thickness filtering", zap.Error(getMaxErr))
 		} else if maxUnix > 0 {
+			// 時系列検索では、期間の終端を基準に減衰させる（過去の期間の知識が古さで足切りされないようにする）
+			if isTemporal && !config.TimeRange.To.IsZero() && config.TimeRange.To.UnixMilli() < maxUnix {
+				maxUnix = config.TimeRange.To.UnixMilli()
+			}
 			// 3-2. MemoryGroupConfig を取得してλを計算
 			groupConfig, _ := t.GraphStorage.GetMemoryGroupConfig(ctx, t.memoryGroup)
 			halfLifeDays := appconfig.DEFAULT_HALF_LIFE_DAYS // デフォルトは settings.go から取得
@@ -444,7 +502,7 @@ func (t *GraphCompletionTool) getGraph(ctx context.Context, entityTopk int, quer
 				})
 
 				// Stage 1: 決定論的解決
-				resolved, stage1Discarded, remainingConflicts := utils.Stage1ConflictResolution(scoredTriples, t.Logger, config.IsEn)
+				resolved, stage1Discarded, remainingConflicts := utils.Stage1ConflictResolution(scoredTriples, ontology.FromContext(ctx).IsExclusive, t.Logger, config.IsEn)
 				scoredTriples = resolved
 				discardedEdges = append(discardedEdges, stage1Discarded...)
 
@@ -557,6 +615,7 @@ func (t *GraphCompletionTool) getGraph(ctx context.Context, entityTopk int, quer
 		GraphNodeIDCandidates: strings.Join(graphNodeIDCandidatesForDisplay, ", "),
 		TriplesCount:          len(triples),
 	})
+	t.attachEdgeProvenances(ctx, triples)
 	graph = &triples
 	embedding = &embeddingVectors
 	return
@@ -577,6 +636,31 @@ func (t *GraphCompletionTool) getGraph(ctx context.Context, entityTopk int, quer
 //   - string: Chunkのリスト
 //   - error: エラーが発生した場合
 func (t *GraphCompletionTool) getChunks(ctx context.Context, chunkTopk int, query string, embeddingVecs *[]float32) (embedding *[]float32, chunks *string, usage types.TokenUsage, err error) {
+	embeddingVectors, results, usage, err := t.searchChunks(ctx, chunkTopk, query, embeddingVecs, nil)
+	if err != nil {
+		return
+	}
+	// 結果が見つからない場合
+	if len(results) == 0 {
+		tmp := ""
+		chunks = &tmp
+		return
+	}
+	// 要約のリストを構築
+	var sb strings.Builder
+	for _, result := range results {
+		sb.WriteString("- " + result.Text + "\n\n")
+	}
+	tmp := strings.TrimSpace(sb.String())
+	chunks = &tmp
+	embedding = embeddingVectors
+	return
+}
+
+// searchChunks は、クエリに類似するChunkをベクトル検索し、検索結果をそのまま返します。
+// timeRange を指定した場合は、元データの取り込み日時が期間内のチャンクに限定します（結果の ObservedAt も設定されます）。
+// 検索で得られたチャンクは出典として記録されます。
+func (t *GraphCompletionTool) searchChunks(ctx context.Context, chunkTopk int, query string, embeddingVecs *[]float32, timeRange *types.TimeRange) (embedding *[]float32, results []*storage.QueryResult, usage types.TokenUsage, err error) {
 	// クエリをベクトル化
 	var embeddingVectors []float32
 	if embeddingVecs != nil && len(*embeddingVecs) > 0 {
@@ -590,6 +674,20 @@ func (t *GraphCompletionTool) getChunks(ctx context.Context, chunkTopk int, quer
 		}
 		embeddingVectors = tmpEmbeddingVectors
 	}
+	results, err = t.queryChunkVectors(ctx, chunkTopk, embeddingVectors, timeRange)
+	if err != nil {
+		return
+	}
+	for _, result := range results {
+		t.addCitationChunkID(result.ID)
+	}
+	embedding = &embeddingVectors
+	return
+}
+
+// queryChunkVectors は、Chunkテーブルをベクトル検索し、検索結果をそのまま返します。
+// searchChunks と異なり、検索で得られたチャンクを出典として記録しません（呼び出し側で選別する場合に使用します）。
+func (t *GraphCompletionTool) queryChunkVectors(ctx context.Context, chunkTopk int, embeddingVectors []float32, timeRange *types.TimeRange) (results []*storage.QueryResult, err error) {
 	// Chunkテーブルを検索
 	// Emit Vector Search Start (Chunk)
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_SEARCH_VECTOR_START), event.QuerySearchVectorStartPayload{
@@ -597,7 +695,11 @@ func (t *GraphCompletionTool) getChunks(ctx context.Context, chunkTopk int, quer
 		TargetTable: string(types.TABLE_NAME_CHUNK),
 	})
 
-	results, err := t.VectorStorage.Query(ctx, types.TABLE_NAME_CHUNK, embeddingVectors, chunkTopk, t.memoryGroup)
+	if timeRange != nil {
+		results, err = t.VectorStorage.QueryChunksInTimeRange(ctx, embeddingVectors, chunkTopk, t.memoryGroup, *timeRange)
+	} else {
+		results, err = t.VectorStorage.Query(ctx, types.TABLE_NAME_CHUNK, embeddingVectors, chunkTopk, t.memoryGroup)
+	}
 	if err != nil {
 		err = fmt.Errorf("GraphCompletionTool: Failed to query chunks: %w", err)
 		return
@@ -614,20 +716,6 @@ func (t *GraphCompletionTool) getChunks(ctx context.Context, chunkTopk int, quer
 		TargetCount: len(results),
 		Targets:     strings.Join(targets, ", "),
 	})
-	// 結果が見つからない場合
-	if len(results) == 0 {
-		tmp := ""
-		chunks = &tmp
-		return
-	}
-	// 要約のリストを構築
-	var sb strings.Builder
-	for _, result := range results {
-		sb.WriteString("- " + result.Text + "\n\n")
-	}
-	tmp := strings.TrimSpace(sb.String())
-	chunks = &tmp
-	embedding = &embeddingVectors
 	return
 }
 
@@ -814,7 +902,7 @@ func (t *GraphCompletionTool) getEnglishGraphSummary(ctx context.Context, entity
 		PromptName:  "SUMMARIZE_GRAPH_ITSELF_EN_PROMPT",
 	})
 
-	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.SUMMARIZE_GRAPH_ITSELF_EN_PROMPT, summarizePrompt)
+	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_SUMMARIZE_GRAPH_ITSELF_EN), summarizePrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -852,7 +940,7 @@ func (t *GraphCompletionTool) getJapaneseGraphSummary(ctx context.Context, entit
 		PromptName:  "SUMMARIZE_GRAPH_ITSELF_JA_PROMPT",
 	})
 
-	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.SUMMARIZE_GRAPH_ITSELF_JA_PROMPT, summarizePrompt)
+	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_SUMMARIZE_GRAPH_ITSELF_JA), summarizePrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -890,7 +978,7 @@ func (t *GraphCompletionTool) getEnglishGraphSummaryToAnswer(ctx context.Context
 		PromptName:  "SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN_PROMPT",
 	})
 
-	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN_PROMPT, summarizePrompt)
+	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_EN), summarizePrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -928,7 +1016,7 @@ func (t *GraphCompletionTool) getJapaneseGraphSummaryToAnswer(ctx context.Contex
 		PromptName:  "SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA_PROMPT",
 	})
 
-	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA_PROMPT, summarizePrompt)
+	summaryContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_SUMMARIZE_GRAPH_EXPLANATION_TO_ANSWER_JA), summarizePrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -1147,7 +1235,7 @@ func (t *GraphCompletionTool) answerQueryByVectorAndGraphResultEN(ctx context.Co
 		PromptName:  "ANSWER_QUERY_WITH_HYBRID_RAG_EN_PROMPT",
 	})
 
-	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.ANSWER_QUERY_WITH_HYBRID_RAG_EN_PROMPT, finalUserPrompt)
+	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_HYBRID_RAG_EN), finalUserPrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -1179,7 +1267,7 @@ func (t *GraphCompletionTool) answerQueryByVectorAndGraphResultJA(ctx context.Co
 		PromptName:  "ANSWER_QUERY_WITH_HYBRID_RAG_JA_PROMPT",
 	})
 
-	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.ANSWER_QUERY_WITH_HYBRID_RAG_JA_PROMPT, finalUserPrompt)
+	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_HYBRID_RAG_JA), finalUserPrompt)
 
 	// Emit Generation End
 	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
@@ -1201,6 +1289,59 @@ func (t *GraphCompletionTool) answerQueryByVectorAndGraphResultJA(ctx context.Co
 	return
 }
 
+// addCitationChunkID は、出典として収集するチャンクIDを重複なく追加します。
+func (t *GraphCompletionTool) addCitationChunkID(chunkID string) {
+	if chunkID == "" || t.citationSeen == nil || t.citationSeen[chunkID] {
+		return
+	}
+	t.citationSeen[chunkID] = true
+	t.citationChunkIDs = append(t.citationChunkIDs, chunkID)
+}
+
+// attachEdgeProvenances は、トリプルの各エッジに出典チャンクIDを設定し、出典として収集します。
+// 出典の取得に失敗してもクエリ自体は失敗させません。
+func (t *GraphCompletionTool) attachEdgeProvenances(ctx context.Context, triples []*storage.Triple) {
+	if len(triples) == 0 {
+		return
+	}
+	edges := make([]*storage.Edge, 0, len(triples))
+	for _, triple := range triples {
+		edges = append(edges, triple.Edge)
+	}
+	provenances, err := t.GraphStorage.GetEdgeProvenances(ctx, edges, t.memoryGroup)
+	if err != nil {
+		utils.LogWarn(t.Logger, "GraphCompletionTool: Failed to get edge provenances", zap.Error(err))
+		return
+	}
+	chunkIDsByEdge := make(map[string][]string)
+	for _, p := range provenances {
+		key := p.SourceID + "|" + p.Type + "|" + p.TargetID
+		chunkIDsByEdge[key] = append(chunkIDsByEdge[key], p.ChunkID)
+	}
+	for _, edge := range edges {
+		edge.SourceChunkIDs = chunkIDsByEdge[edge.SourceID+"|"+edge.Type+"|"+edge.TargetID]
+		for _, chunkID := range edge.SourceChunkIDs {
+			t.addCitationChunkID(chunkID)
+		}
+	}
+}
+
+// resolveCitations は、収集したチャンクIDをファイル名・チャンク順序などの出典情報に変換します。
+// 出典の取得に失敗してもクエリ自体は失敗させず、空の出典を返します。
+func (t *GraphCompletionTool) resolveCitations(ctx context.Context) *[]*storage.Citation {
+	citations := []*storage.Citation{}
+	if len(t.citationChunkIDs) == 0 {
+		return &citations
+	}
+	resolved, err := t.VectorStorage.GetCitations(ctx, t.citationChunkIDs, t.memoryGroup)
+	if err != nil {
+		utils.LogWarn(t.Logger, "GraphCompletionTool: Failed to resolve citations", zap.Error(err))
+		return &citations
+	}
+	citations = append(citations, resolved...)
+	return &citations
+}
+
 /**
  * 与えられた知識グラフトリプルから、自然な英語の説明文を構成する（英語）
  */
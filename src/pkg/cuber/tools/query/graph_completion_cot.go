@@ -0,0 +1,249 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// cotCritique は、COT_CRITIQUE_PROMPT の出力です。
+type cotCritique struct {
+	Sufficient      bool     `json:"sufficient"`
+	Critique        string   `json:"critique"`
+	FollowUpQueries []string `json:"follow_up_queries"`
+}
+
+// cotContext は、推論ラウンドを通じて蓄積される検索結果です（チャンクとトリプルは重複なく保持）。
+type cotContext struct {
+	chunks      []string
+	chunkSeen   map[string]bool
+	triples     []*storage.Triple
+	tripleSeen  map[string]bool
+	queriesDone []string
+}
+
+// getGraphCompletionCoT は、回答案の批評と追加検索を繰り返して回答を生成します (QUERY_TYPE_GRAPH_COMPLETION_COT)。
+// 1回の検索では辿れない、複数の事実をつなぐ質問（マルチホップ質問）に回答するために使用します。
+//
+// 処理の流れ:
+//  1. 質問でチャンクとグラフを検索する
+//  2. 蓄積した検索結果から英語で回答案を作成する
+//  3. LLM が回答案を批評し、不足があれば追加の検索クエリを生成する
+//  4. 追加の検索クエリでチャンクとグラフを検索し、結果を蓄積して 2 に戻る（最大 rounds 回）
+//  5. 最終的な回答を指定の言語で生成する
+//
+// 各ラウンドの批評と追加クエリは EVENT_QUERY_REASONING_* イベントとして通知されます。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - chunkTopk: 1回の検索で取得するチャンク数
+//   - entityTopk: 1回の検索でグラフ探索の種とするエンティティ数
+//   - rounds: 批評と追加検索を繰り返す最大ラウンド数
+//   - query: 質問
+//   - config: クエリ設定
+//
+// 返り値:
+//   - embedding: 質問の埋め込みベクトル
+//   - answer: 回答
+//   - graph: 全ラウンドで取得したトリプル
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) getGraphCompletionCoT(ctx context.Context, chunkTopk int, entityTopk int, rounds int, query string, config types.QueryConfig) (embedding *[]float32, answer *string, graph *[]*storage.Triple, usage types.TokenUsage, err error) {
+	cc := &cotContext{
+		chunkSeen:  make(map[string]bool),
+		tripleSeen: make(map[string]bool),
+	}
+	// 1. 質問で検索
+	embedding, _, u, err := t.retrieveForCoT(ctx, cc, chunkTopk, entityTopk, query, nil, config)
+	usage.Add(u)
+	if err != nil {
+		return
+	}
+	graph = &cc.triples
+	if len(cc.chunks) == 0 && len(cc.triples) == 0 {
+		tmp := ""
+		answer = &tmp
+		return
+	}
+	var draft *string
+	stale := true // 最後の回答案の作成以降に検索結果が増えたかどうか
+	for round := 1; round <= rounds; round++ {
+		// 2. 回答案を作成
+		chunksText, graphText := cc.texts()
+		draft, u, err = t.answerQueryByVectorAndGraphResultEN(ctx, &chunksText, &graphText, query)
+		usage.Add(u)
+		if err != nil {
+			return
+		}
+		stale = false
+		// 3. 回答案を批評
+		critique, u, errr := t.critiqueDraftForCoT(ctx, query, *draft, cc.queriesDone)
+		usage.Add(u)
+		if errr != nil {
+			err = errr
+			return
+		}
+		if critique.Sufficient || len(critique.FollowUpQueries) == 0 {
+			// Emit Reasoning Sufficient
+			eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_REASONING_SUFFICIENT), event.QueryReasoningSufficientPayload{
+				BasePayload: event.NewBasePayload(t.memoryGroup),
+				Round:       round,
+				Critique:    critique.Critique,
+			})
+			break
+		}
+
+		// Emit Reasoning Follow Up
+		eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_REASONING_FOLLOW_UP), event.QueryReasoningFollowUpPayload{
+			BasePayload:     event.NewBasePayload(t.memoryGroup),
+			Round:           round,
+			Critique:        critique.Critique,
+			FollowUpQueries: strings.Join(critique.FollowUpQueries, "\n"),
+		})
+
+		// 4. 追加の検索クエリで検索
+		added := 0
+		for _, followUp := range critique.FollowUpQueries {
+			_, n, u, errr := t.retrieveForCoT(ctx, cc, chunkTopk, entityTopk, followUp, nil, config)
+			usage.Add(u)
+			if errr != nil {
+				err = errr
+				return
+			}
+			added += n
+		}
+		if added == 0 {
+			// 新しい情報が得られない場合は、これ以上ラウンドを重ねても回答は変わらない
+			utils.LogDebug(t.Logger, "GraphCompletionTool: CoT follow-up queries retrieved nothing new", zap.Int("round", round))
+			break
+		}
+		stale = true
+	}
+	// 5. 最終的な回答を生成
+	if config.IsEn && !stale {
+		answer = draft
+		return
+	}
+	chunksText, graphText := cc.texts()
+	if config.IsEn {
+		answer, u, err = t.answerQueryByVectorAndGraphResultEN(ctx, &chunksText, &graphText, query)
+	} else {
+		answer, u, err = t.answerQueryByVectorAndGraphResultJA(ctx, &chunksText, &graphText, query)
+	}
+	usage.Add(u)
+	return
+}
+
+// retrieveForCoT は、1つのクエリでチャンクとグラフを検索し、新しい結果を cotContext に蓄積します。
+// 返り値の added は、新たに追加されたチャンクとトリプルの合計数です。
+func (t *GraphCompletionTool) retrieveForCoT(ctx context.Context, cc *cotContext, chunkTopk int, entityTopk int, query string, embeddingVecs *[]float32, config types.QueryConfig) (embedding *[]float32, added int, usage types.TokenUsage, err error) {
+	cc.queriesDone = append(cc.queriesDone, query)
+	embedding, results, u, err := t.searchChunks(ctx, chunkTopk, query, embeddingVecs, nil)
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to query chunks: %w", err)
+		return
+	}
+	for _, result := range results {
+		if cc.chunkSeen[result.ID] {
+			continue
+		}
+		cc.chunkSeen[result.ID] = true
+		cc.chunks = append(cc.chunks, result.Text)
+		added++
+	}
+	_, triples, u, err := t.getGraph(ctx, entityTopk, query, embedding, config)
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to get graph: %w", err)
+		return
+	}
+	for _, triple := range *triples {
+		key := triple.Edge.SourceID + "|" + triple.Edge.Type + "|" + triple.Edge.TargetID
+		if cc.tripleSeen[key] {
+			continue
+		}
+		cc.tripleSeen[key] = true
+		cc.triples = append(cc.triples, triple)
+		added++
+	}
+	return
+}
+
+// critiqueDraftForCoT は、回答案を批評し、追加の検索クエリを生成します。
+// LLM の出力を解析できない場合は、回答案を十分とみなして推論を終了させます。
+func (t *GraphCompletionTool) critiqueDraftForCoT(ctx context.Context, query string, draft string, queriesDone []string) (critique cotCritique, usage types.TokenUsage, err error) {
+	systemPrompt := fmt.Sprintf(prompts.Get(ctx, prompts.NAME_COT_CRITIQUE), appconfig.COT_MAX_FOLLOW_UP_QUERIES)
+	userPrompt := fmt.Sprintf("User Question: %s\n\nDraft Answer:\n%s\n\nExecuted Queries:\n- %s", query, draft, strings.Join(queriesDone, "\n- "))
+
+	// Emit Generation Start (Critique)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  "COT_CRITIQUE_PROMPT",
+	})
+
+	content, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  u,
+		Response:    content,
+	})
+
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to critique draft answer: %w", err)
+		return
+	}
+	jsonStart := strings.Index(content, "{")
+	jsonEnd := strings.LastIndex(content, "}")
+	if jsonStart >= 0 && jsonEnd > jsonStart {
+		content = content[jsonStart : jsonEnd+1]
+	}
+	if errr := json.Unmarshal([]byte(content), &critique); errr != nil {
+		utils.LogWarn(t.Logger, "GraphCompletionTool: Failed to parse critique, treating draft as sufficient", zap.Error(errr), zap.String("response", content))
+		critique = cotCritique{Sufficient: true}
+		return
+	}
+	// 空のクエリと実行済みのクエリを除外し、最大数で打ち切る
+	done := make(map[string]bool, len(queriesDone))
+	for _, q := range queriesDone {
+		done[q] = true
+	}
+	followUps := []string{}
+	for _, q := range critique.FollowUpQueries {
+		q = utils.NormalizeForSearch(q)
+		if q == "" || done[q] {
+			continue
+		}
+		done[q] = true
+		followUps = append(followUps, q)
+		if len(followUps) >= appconfig.COT_MAX_FOLLOW_UP_QUERIES {
+			break
+		}
+	}
+	critique.FollowUpQueries = followUps
+	return
+}
+
+// texts は、蓄積したチャンクとトリプルを回答生成用のテキストに変換します。
+func (cc *cotContext) texts() (chunksText string, graphText string) {
+	var sb strings.Builder
+	for _, chunk := range cc.chunks {
+		sb.WriteString("- " + chunk + "\n\n")
+	}
+	chunksText = strings.TrimSpace(sb.String())
+	graphText = strings.TrimSpace(GenerateNaturalEnglishGraphExplanationByTriples(&cc.triples, &strings.Builder{}).String())
+	return
+}
@@ -0,0 +1,96 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCritiqueDraftForCoT(t *testing.T) {
+	queriesDone := []string{"where does alice work"}
+	tests := []struct {
+		name           string
+		response       string
+		wantSufficient bool
+		wantCritique   string
+		wantFollowUps  []string
+	}{
+		{
+			name:           "sufficient",
+			response:       `{"sufficient": true, "critique": "The draft answers the question.", "follow_up_queries": []}`,
+			wantSufficient: true,
+			wantCritique:   "The draft answers the question.",
+			wantFollowUps:  []string{},
+		},
+		{
+			name:          "follow-up queries",
+			response:      `{"sufficient": false, "critique": "The location of Acme is missing.", "follow_up_queries": ["where is acme located"]}`,
+			wantCritique:  "The location of Acme is missing.",
+			wantFollowUps: []string{"where is acme located"},
+		},
+		{
+			name:          "json wrapped in text",
+			response:      "Here is my critique:\n```json\n{\"sufficient\": false, \"critique\": \"c\", \"follow_up_queries\": [\"who founded acme\"]}\n```",
+			wantCritique:  "c",
+			wantFollowUps: []string{"who founded acme"},
+		},
+		{
+			name:          "follow-up queries are normalized and deduplicated",
+			response:      `{"sufficient": false, "critique": "c", "follow_up_queries": ["Who founded ACME", "who  founded acme", "", "Where does Alice work"]}`,
+			wantCritique:  "c",
+			wantFollowUps: []string{"who founded acme"},
+		},
+		{
+			name:          "follow-up queries are limited",
+			response:      `{"sufficient": false, "critique": "c", "follow_up_queries": ["q1", "q2", "q3", "q4"]}`,
+			wantCritique:  "c",
+			wantFollowUps: []string{"q1", "q2", "q3"},
+		},
+		{
+			name:           "unparseable response is treated as sufficient",
+			response:       "I think the draft is fine.",
+			wantSufficient: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &scriptedChatModel{responses: []string{tt.response}}
+			tool := NewGraphCompletionTool(nil, nil, llm, nil, nil, testMemoryGroup, "test-model", nil, nil)
+			got, _, err := tool.critiqueDraftForCoT(context.Background(), "Which city does Alice work in?", "Alice works at Acme.", queriesDone)
+			if err != nil {
+				t.Fatalf("critiqueDraftForCoT failed: %v", err)
+			}
+			if got.Sufficient != tt.wantSufficient || got.Critique != tt.wantCritique || !slices.Equal(got.FollowUpQueries, tt.wantFollowUps) {
+				t.Errorf("critique = %+v, want sufficient=%v critique=%q follow_up_queries=%q", got, tt.wantSufficient, tt.wantCritique, tt.wantFollowUps)
+			}
+			// 批評には、質問・回答案・実行済みのクエリが渡される
+			prompt := llm.lastUserPrompt(0)
+			for _, want := range []string{"Which city does Alice work in?", "Alice works at Acme.", "- where does alice work"} {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("Critique prompt does not contain %q:\n%s", want, prompt)
+				}
+			}
+		})
+	}
+}
+
+func TestCoTContextTexts(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{name: "no chunks", want: ""},
+		{name: "one chunk", chunks: []string{"Alice works at Acme."}, want: "- Alice works at Acme."},
+		{name: "chunks are separated by blank lines", chunks: []string{"a", "b"}, want: "- a\n\n- b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &cotContext{chunks: tt.chunks}
+			if got, _ := cc.texts(); got != tt.want {
+				t.Errorf("chunksText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
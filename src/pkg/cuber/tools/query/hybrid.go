@@ -0,0 +1,260 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// hybridRanking は、ハイブリッド検索で融合する1つのランキングです。
+type hybridRanking struct {
+	weight   float64  // 融合時の重み
+	chunkIDs []string // 関連度の高い順に並んだチャンクID
+}
+
+// getHybridAnswer は、ベクトル・FTS・グラフの各ランキングを Reciprocal Rank Fusion で融合したチャンクと、
+// グラフをコンテキストとして回答を生成します (QUERY_TYPE_HYBRID)。
+// 固有名詞や型番のように、意味は近くなくても字句が一致するチャンクを、ベクトル検索だけの場合より確実に拾うために使用します。
+//
+// 処理の流れ:
+//  1. 質問をベクトル化し、Chunk テーブルをベクトル検索する
+//  2. Chunk テーブルを FTS の3レイヤー（nouns, nouns_verbs, keywords）でそれぞれ検索する
+//  3. グラフを取得し、エッジの出典チャンクを「エッジの太さ × 両端ノードのサブグラフ内の次数」の合計で順位付けする
+//  4. 各ランキングの順位 r に対して weight / (HYBRID_RRF_K + r) を加算して融合し、上位 chunkTopk 件を選ぶ
+//  5. 融合したチャンクとグラフをコンテキストとして回答を生成する
+//
+// 各ランキングの候補数は chunkTopk × HYBRID_CANDIDATE_MULTIPLIER です。
+// FTS の失敗は致命的ではないため、ログを出力してそのランキングを除外します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - chunkTopk: 回答に使用するチャンク数
+//   - entityTopk: グラフ探索の種とするエンティティ数
+//   - query: 質問
+//   - config: クエリ設定（HybridWeights で各ランキングの重みを指定）
+//
+// 返り値:
+//   - embedding: 質問の埋め込みベクトル
+//   - answer: 回答
+//   - chunks: 融合後のチャンク（回答のコンテキストとして使用したもの）
+//   - graph: 取得したトリプル
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) getHybridAnswer(ctx context.Context, chunkTopk int, entityTopk int, query string, config types.QueryConfig) (embedding *[]float32, answer *string, chunks *string, graph *[]*storage.Triple, usage types.TokenUsage, err error) {
+	weights := config.HybridWeights
+	if weights.IsZero() {
+		weights = types.HybridWeights{
+			Vector:        appconfig.DEFAULT_HYBRID_WEIGHT_VECTOR,
+			FtsNouns:      appconfig.DEFAULT_HYBRID_WEIGHT_FTS_NOUNS,
+			FtsNounsVerbs: appconfig.DEFAULT_HYBRID_WEIGHT_FTS_NOUNS_VERBS,
+			FtsKeywords:   appconfig.DEFAULT_HYBRID_WEIGHT_FTS_KEYWORDS,
+			Graph:         appconfig.DEFAULT_HYBRID_WEIGHT_GRAPH,
+		}
+	}
+	candidateTopk := chunkTopk * appconfig.HYBRID_CANDIDATE_MULTIPLIER
+
+	// 1. 質問をベクトル化し、チャンクをベクトル検索
+	// Emit Embedding Start
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_EMBEDDING_START), event.QueryEmbeddingStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		Text:        query,
+	})
+	embeddingVectors, u, err := t.Embedder.EmbedQuery(ctx, query)
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to embed query: %w", err)
+		return
+	}
+	// Emit Embedding End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_EMBEDDING_END), event.QueryEmbeddingEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		Dimension:   len(embeddingVectors),
+	})
+	embedding = &embeddingVectors
+
+	texts := make(map[string]string)
+	var rankings []hybridRanking
+	var vectorCount int
+	if weights.Vector > 0 {
+		results, errr := t.queryChunkVectors(ctx, candidateTopk, embeddingVectors, nil)
+		if errr != nil {
+			err = errr
+			return
+		}
+		ranking := hybridRanking{weight: weights.Vector}
+		for _, result := range results {
+			texts[result.ID] = result.Text
+			ranking.chunkIDs = append(ranking.chunkIDs, result.ID)
+		}
+		rankings = append(rankings, ranking)
+		vectorCount = len(results)
+	}
+
+	// 2. FTS の各レイヤーでチャンクを検索
+	lexicalSeen := make(map[string]bool)
+	for _, layer := range []struct {
+		layer  types.FtsLayer
+		weight float64
+	}{
+		{types.FTS_LAYER_NOUNS, weights.FtsNouns},
+		{types.FTS_LAYER_NOUNS_VERBS, weights.FtsNounsVerbs},
+		{types.FTS_LAYER_ALL, weights.FtsKeywords},
+	} {
+		if layer.weight <= 0 {
+			continue
+		}
+		results, ftsErr := t.VectorStorage.FullTextSearch(ctx, types.TABLE_NAME_CHUNK, query, candidateTopk, t.memoryGroup, config.IsEn, layer.layer)
+		if ftsErr != nil {
+			// FTS エラーは致命的ではないためログのみ
+			utils.LogWarn(t.Logger, "GraphCompletionTool: Hybrid FTS failed", zap.String("layer", string(layer.layer)), zap.Error(ftsErr))
+			continue
+		}
+		ranking := hybridRanking{weight: layer.weight}
+		for _, result := range results {
+			ranking.chunkIDs = append(ranking.chunkIDs, result.ID)
+			lexicalSeen[result.ID] = true
+		}
+		rankings = append(rankings, ranking)
+	}
+
+	// 3. グラフを取得し、エッジの出典チャンクを順位付け
+	_, graph, u, err = t.getGraph(ctx, entityTopk, query, &embeddingVectors, config)
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to get graph: %w", err)
+		return
+	}
+	var graphCount int
+	if weights.Graph > 0 {
+		graphChunkIDs := rankChunksByGraphCentrality(*graph)
+		rankings = append(rankings, hybridRanking{weight: weights.Graph, chunkIDs: graphChunkIDs})
+		graphCount = len(graphChunkIDs)
+	}
+
+	// 4. 各ランキングを融合
+	fusedIDs := fuseRankings(rankings, appconfig.HYBRID_RRF_K, chunkTopk)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_HYBRID_FUSION_END), event.QueryHybridFusionEndPayload{
+		BasePayload:  event.NewBasePayload(t.memoryGroup),
+		VectorCount:  vectorCount,
+		LexicalCount: len(lexicalSeen),
+		GraphCount:   graphCount,
+		FusedCount:   len(fusedIDs),
+	})
+	if len(fusedIDs) == 0 && len(*graph) == 0 {
+		tmp := ""
+		answer = &tmp
+		chunks = &tmp
+		return
+	}
+	// ベクトル検索で得られなかったチャンクの本文を取得
+	var missingIDs []string
+	for _, id := range fusedIDs {
+		if _, ok := texts[id]; !ok {
+			missingIDs = append(missingIDs, id)
+		}
+	}
+	if len(missingIDs) > 0 {
+		fetched, errr := t.VectorStorage.GetChunkTextsByIDs(ctx, missingIDs, t.memoryGroup)
+		if errr != nil {
+			err = fmt.Errorf("GraphCompletionTool: Failed to get chunk texts: %w", errr)
+			return
+		}
+		for id, text := range fetched {
+			texts[id] = text
+		}
+	}
+	// 出典は融合後の順位を優先し、その後にグラフのエッジの出典を続ける
+	graphCitationIDs := t.citationChunkIDs
+	t.citationChunkIDs = nil
+	t.citationSeen = make(map[string]bool)
+	var chunksText strings.Builder
+	for _, id := range fusedIDs {
+		text, ok := texts[id]
+		if !ok {
+			continue
+		}
+		t.addCitationChunkID(id)
+		chunksText.WriteString("- " + text + "\n\n")
+	}
+	for _, id := range graphCitationIDs {
+		t.addCitationChunkID(id)
+	}
+	chunksStr := strings.TrimSpace(chunksText.String())
+	chunks = &chunksStr
+
+	// 5. 回答を生成
+	graphText := strings.TrimSpace(GenerateNaturalEnglishGraphExplanationByTriples(graph, &strings.Builder{}).String())
+	if config.IsEn {
+		answer, u, err = t.answerQueryByVectorAndGraphResultEN(ctx, chunks, &graphText, query)
+	} else {
+		answer, u, err = t.answerQueryByVectorAndGraphResultJA(ctx, chunks, &graphText, query)
+	}
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to answer query by hybrid result: %w", err)
+		return
+	}
+	return
+}
+
+// rankChunksByGraphCentrality は、トリプルのエッジの出典チャンクを、グラフ近傍での中心性の高い順に並べます。
+// チャンクのスコアは、そのチャンクを出典とする各エッジの「太さ × 両端ノードのサブグラフ内の次数の和」の合計です。
+// エッジの太さは Thickness（未計算の場合は Weight × Confidence）を使用します。
+func rankChunksByGraphCentrality(triples []*storage.Triple) []string {
+	degrees := make(map[string]int)
+	for _, triple := range triples {
+		degrees[triple.Edge.SourceID]++
+		degrees[triple.Edge.TargetID]++
+	}
+	scores := make(map[string]float64)
+	var chunkIDs []string
+	for _, triple := range triples {
+		thickness := triple.Edge.Thickness
+		if thickness == 0 {
+			thickness = triple.Edge.Weight * triple.Edge.Confidence
+		}
+		centrality := thickness * float64(degrees[triple.Edge.SourceID]+degrees[triple.Edge.TargetID])
+		for _, chunkID := range triple.Edge.SourceChunkIDs {
+			if _, ok := scores[chunkID]; !ok {
+				chunkIDs = append(chunkIDs, chunkID)
+			}
+			scores[chunkID] += centrality
+		}
+	}
+	sort.SliceStable(chunkIDs, func(i, j int) bool {
+		return scores[chunkIDs[i]] > scores[chunkIDs[j]]
+	})
+	return chunkIDs
+}
+
+// fuseRankings は、複数のランキングを重み付き Reciprocal Rank Fusion で融合し、上位 topk 件のチャンクIDを返します。
+// チャンクのスコアは、各ランキングでの順位 r（1始まり）に対する weight / (k + r) の合計です。
+// スコアが同じ場合は、先に現れたランキングでの出現順を優先します。
+func fuseRankings(rankings []hybridRanking, k float64, topk int) []string {
+	scores := make(map[string]float64)
+	var chunkIDs []string
+	for _, ranking := range rankings {
+		for i, chunkID := range ranking.chunkIDs {
+			if _, ok := scores[chunkID]; !ok {
+				chunkIDs = append(chunkIDs, chunkID)
+			}
+			scores[chunkID] += ranking.weight / (k + float64(i+1))
+		}
+	}
+	sort.SliceStable(chunkIDs, func(i, j int) bool {
+		return scores[chunkIDs[i]] > scores[chunkIDs[j]]
+	})
+	if len(chunkIDs) > topk {
+		chunkIDs = chunkIDs[:topk]
+	}
+	return chunkIDs
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"slices"
+	"testing"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+func TestFuseRankings(t *testing.T) {
+	tests := []struct {
+		name     string
+		rankings []hybridRanking
+		topk     int
+		want     []string
+	}{
+		{
+			name: "no rankings",
+			topk: 3,
+		},
+		{
+			name:     "single ranking keeps its order",
+			rankings: []hybridRanking{{weight: 1, chunkIDs: []string{"a", "b", "c"}}},
+			topk:     3,
+			want:     []string{"a", "b", "c"},
+		},
+		{
+			name: "chunk found by several rankings rises",
+			rankings: []hybridRanking{
+				{weight: 1, chunkIDs: []string{"a", "b", "c"}},
+				{weight: 1, chunkIDs: []string{"c", "d"}},
+			},
+			topk: 4,
+			// c = 1/63 + 1/61、a = 1/61、b と d は同点（1/62）のため先に現れた b を優先
+			want: []string{"c", "a", "b", "d"},
+		},
+		{
+			name: "weights",
+			rankings: []hybridRanking{
+				{weight: 0.5, chunkIDs: []string{"a"}},
+				{weight: 1, chunkIDs: []string{"b"}},
+			},
+			topk: 2,
+			want: []string{"b", "a"},
+		},
+		{
+			name:     "top k",
+			rankings: []hybridRanking{{weight: 1, chunkIDs: []string{"a", "b", "c"}}},
+			topk:     2,
+			want:     []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuseRankings(tt.rankings, appconfig.HYBRID_RRF_K, tt.topk); !slices.Equal(got, tt.want) {
+				t.Errorf("fuseRankings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankChunksByGraphCentrality(t *testing.T) {
+	edge := func(source, target string, thickness, weight, confidence float64, chunkIDs ...string) *storage.Triple {
+		return &storage.Triple{
+			Source: &storage.Node{ID: source},
+			Edge:   &storage.Edge{SourceID: source, TargetID: target, Type: "RELATED_TO", Thickness: thickness, Weight: weight, Confidence: confidence, SourceChunkIDs: chunkIDs},
+			Target: &storage.Node{ID: target},
+		}
+	}
+	tests := []struct {
+		name    string
+		triples []*storage.Triple
+		want    []string
+	}{
+		{
+			name: "no triples",
+		},
+		{
+			name:    "edge without source chunks",
+			triples: []*storage.Triple{edge("alice", "acme", 1, 1, 1)},
+		},
+		{
+			name: "scores are summed per chunk",
+			// 次数: alice=1, acme=2, bob=1, carol=1, globex=1
+			// 中心性: alice->acme = 0.5×3、bob->acme = (0.5×1.0)×3（Thickness 未計算）、carol->globex = 1.0×2
+			// スコア: c1 = 1.5 + 1.5、c2 = 1.5、c3 = 2
+			triples: []*storage.Triple{
+				edge("alice", "acme", 0.5, 1, 1, "c1"),
+				edge("bob", "acme", 0, 0.5, 1.0, "c2", "c1"),
+				edge("carol", "globex", 1.0, 1, 1, "c3"),
+			},
+			want: []string{"c1", "c3", "c2"},
+		},
+		{
+			name: "ties keep the order of appearance",
+			triples: []*storage.Triple{
+				edge("alice", "acme", 1, 1, 1, "c2"),
+				edge("bob", "globex", 1, 1, 1, "c1"),
+			},
+			want: []string{"c2", "c1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rankChunksByGraphCentrality(tt.triples); !slices.Equal(got, tt.want) {
+				t.Errorf("rankChunksByGraphCentrality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
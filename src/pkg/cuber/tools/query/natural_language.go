@@ -0,0 +1,173 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/consts"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/tools/cypher"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+	"go.uber.org/zap"
+)
+
+// getNaturalLanguageAnswer は、質問を Cypher クエリに変換してグラフに対して実行し、その結果から回答を生成します (QUERY_TYPE_NATURAL_LANGUAGE)。
+// 件数の集計など、ベクトル検索とグラフ要約では得られない正確な回答を得るために使用します。
+//
+// 処理の流れ:
+//  1. LLM が質問を読み取り専用の Cypher クエリに変換する
+//  2. tools/cypher で検証し、memory_group でスコープしてから実行する
+//  3. 検証・実行に失敗した場合は、エラー内容を LLM に渡して再生成する（最大 NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS 回）
+//  4. 実行結果を LLM に渡し、質問への回答として言い換える
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - query: 質問
+//   - isEn: true の場合は英語、false の場合は日本語で回答
+//
+// 返り値:
+//   - answer: 回答
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) getNaturalLanguageAnswer(ctx context.Context, query string, isEn bool) (answer *string, usage types.TokenUsage, err error) {
+	var (
+		cypherText string
+		result     *storage.CypherResult
+		lastErr    error
+	)
+	for attempt := 1; attempt <= appconfig.NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS; attempt++ {
+		userPrompt := fmt.Sprintf("Question: %s", query)
+		if lastErr != nil {
+			userPrompt = fmt.Sprintf("%s\n\nPrevious query:\n%s\n\nError:\n%s", userPrompt, cypherText, lastErr.Error())
+		}
+
+		// Emit Generation Start (Text-to-Cypher)
+		eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+			BasePayload: event.NewBasePayload(t.memoryGroup),
+			PromptName:  string(prompts.NAME_GENERATE_CYPHER),
+		})
+
+		content, u, genErr := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, prompts.Get(ctx, prompts.NAME_GENERATE_CYPHER), userPrompt)
+
+		// Emit Generation End
+		eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+			BasePayload: event.NewBasePayload(t.memoryGroup),
+			TokenUsage:  u,
+			Response:    content,
+		})
+
+		usage.Add(u)
+		if genErr != nil {
+			err = fmt.Errorf("GraphCompletionTool: Failed to generate cypher: %w", genErr)
+			return
+		}
+		cypherText = extractCypher(content)
+		if cypherText == "" {
+			lastErr = errors.New("the response did not contain a query")
+			continue
+		}
+		scoped, scopeErr := cypher.ScopeReadOnlyQuery(cypherText, t.memoryGroup)
+		if scopeErr != nil {
+			lastErr = scopeErr
+			utils.LogDebug(t.Logger, "GraphCompletionTool: Generated cypher rejected", zap.Int("attempt", attempt), zap.String("cypher", cypherText), zap.Error(scopeErr))
+			continue
+		}
+		result, lastErr = t.GraphStorage.ExecuteReadOnlyQuery(ctx, scoped, appconfig.CYPHER_QUERY_MAX_ROWS, appconfig.CYPHER_QUERY_TIMEOUT_MS)
+		if lastErr == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			return
+		}
+		utils.LogDebug(t.Logger, "GraphCompletionTool: Generated cypher failed", zap.Int("attempt", attempt), zap.String("cypher", cypherText), zap.Error(lastErr))
+	}
+	if lastErr != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to generate a valid cypher query after %d attempts: %w", appconfig.NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS, lastErr)
+		return
+	}
+	utils.LogDebug(t.Logger, "GraphCompletionTool: Executed generated cypher", zap.String("cypher", cypherText), zap.Int("rows", len(result.Rows)))
+
+	// 実行結果を回答に言い換える
+	resultText, err := t.formatCypherResultForPrompt(result)
+	if err != nil {
+		return
+	}
+	answerPrompt := fmt.Sprintf("User Question: %s\n\nCypher Query:\n%s\n\nQuery Result:\n%s", query, cypherText, resultText)
+	promptName := string(prompts.NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_JA)
+	systemPrompt := prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_JA)
+	if isEn {
+		promptName = string(prompts.NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_EN)
+		systemPrompt = prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_CYPHER_RESULT_EN)
+	}
+
+	// Emit Generation Start (Final Answer)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  promptName,
+	})
+
+	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, answerPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  u,
+		Response:    answerContent,
+	})
+
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to generate final answer: %w", err)
+		return
+	}
+	if answerContent == "" {
+		err = errors.New("GraphCompletionTool: No final answer generated.")
+		return
+	}
+	answer = &answerContent
+	return
+}
+
+// formatCypherResultForPrompt は、Cypher クエリの実行結果を LLM に渡す JSON 文字列に変換します。
+// 行数は NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS で打ち切り、ノードIDのメモリーグループ接尾辞は除去します。
+func (t *GraphCompletionTool) formatCypherResultForPrompt(result *storage.CypherResult) (string, error) {
+	rows := result.Rows
+	truncated := result.Truncated
+	if len(rows) > appconfig.NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS {
+		rows = rows[:appconfig.NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS]
+		truncated = true
+	}
+	var sb strings.Builder
+	enc := json.NewEncoder(&sb)
+	enc.SetEscapeHTML(false) // ID区切り文字（<::>）をエスケープさせない
+	if err := enc.Encode(storage.CypherResult{Columns: result.Columns, Rows: rows, Truncated: truncated}); err != nil {
+		return "", fmt.Errorf("GraphCompletionTool: Failed to marshal cypher result: %w", err)
+	}
+	return strings.ReplaceAll(sb.String(), consts.ID_MEMORY_GROUP_SEPARATOR+t.memoryGroup, ""), nil
+}
+
+// extractCypher は、LLMの出力から Cypher クエリ部分を取り出します。
+// Markdown のコードブロックで囲まれている場合は、その内側を返します。
+func extractCypher(content string) string {
+	content = strings.TrimSpace(content)
+	if start := strings.Index(content, "```"); start >= 0 {
+		body := content[start+3:]
+		if nl := strings.Index(body, "\n"); nl >= 0 {
+			body = body[nl+1:] // 言語指定（```cypher）の行を除去
+		}
+		if end := strings.Index(body, "```"); end >= 0 {
+			body = body[:end]
+		}
+		content = body
+	}
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,254 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	appconfig "github.com/t-kawata/mycute/config"
+	"github.com/t-kawata/mycute/pkg/cuber/consts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+const testMemoryGroup = "g1"
+
+// scriptedChatModel は、登録した応答を呼び出し順に返すテスト用のチャットモデルです。
+type scriptedChatModel struct {
+	responses []string   // 呼び出し順の応答
+	err       error      // 設定されている場合は常にこのエラーを返す
+	inputs    [][]string // 呼び出しごとのユーザープロンプト
+}
+
+func (m *scriptedChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	var prompts []string
+	for _, msg := range input {
+		if msg.Role == schema.User {
+			prompts = append(prompts, msg.Content)
+		}
+	}
+	m.inputs = append(m.inputs, prompts)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.inputs) > len(m.responses) {
+		return nil, errors.New("no more scripted responses")
+	}
+	return schema.AssistantMessage(m.responses[len(m.inputs)-1], nil), nil
+}
+
+func (m *scriptedChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *scriptedChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// lastUserPrompt は、呼び出し i 回目（0始まり）の最後のユーザープロンプトを返します。
+func (m *scriptedChatModel) lastUserPrompt(i int) string {
+	if i >= len(m.inputs) || len(m.inputs[i]) == 0 {
+		return ""
+	}
+	return m.inputs[i][len(m.inputs[i])-1]
+}
+
+// cypherGraphStorage は、ExecuteReadOnlyQuery だけを実装したテスト用のグラフストレージです。
+// 呼び出しごとに errs の同じ位置のエラー（nil の場合は result）を返します。
+type cypherGraphStorage struct {
+	storage.GraphStorage
+	result  *storage.CypherResult
+	errs    []error
+	queries []string
+}
+
+func (s *cypherGraphStorage) ExecuteReadOnlyQuery(ctx context.Context, query string, maxRows int, timeoutMs uint64) (*storage.CypherResult, error) {
+	s.queries = append(s.queries, query)
+	if i := len(s.queries) - 1; i < len(s.errs) && s.errs[i] != nil {
+		return nil, s.errs[i]
+	}
+	return s.result, nil
+}
+
+func TestGetNaturalLanguageAnswer(t *testing.T) {
+	const (
+		countQuery  = "MATCH (n:GraphNode) RETURN count(n) AS c"
+		scopedQuery = "MATCH (n:GraphNode {memory_group: 'g1'}) RETURN count(n) AS c"
+		writeQuery  = "MATCH (n:GraphNode) DELETE n"
+		answer      = "There are 2 nodes."
+	)
+	tests := []struct {
+		name         string
+		responses    []string
+		llmErr       error
+		execErrs     []error
+		wantAnswer   bool
+		wantQueries  []string // 実行されたクエリ
+		wantFeedback []string // 2回目の Cypher 生成のプロンプトに含まれるべき文字列
+	}{
+		{
+			name:        "first query succeeds",
+			responses:   []string{"```cypher\n" + countQuery + "\n```", answer},
+			wantAnswer:  true,
+			wantQueries: []string{scopedQuery},
+		},
+		{
+			name:         "write query is regenerated",
+			responses:    []string{writeQuery, countQuery, answer},
+			wantAnswer:   true,
+			wantQueries:  []string{scopedQuery},
+			wantFeedback: []string{"Previous query:\n" + writeQuery, "DELETE is not allowed"},
+		},
+		{
+			name:         "execution error is regenerated",
+			responses:    []string{countQuery, countQuery, answer},
+			execErrs:     []error{errors.New("Binder exception: Table Foo does not exist")},
+			wantAnswer:   true,
+			wantQueries:  []string{scopedQuery, scopedQuery},
+			wantFeedback: []string{"Error:\nBinder exception: Table Foo does not exist"},
+		},
+		{
+			name:         "empty response is regenerated",
+			responses:    []string{"", countQuery, answer},
+			wantAnswer:   true,
+			wantQueries:  []string{scopedQuery},
+			wantFeedback: []string{"the response did not contain a query"},
+		},
+		{
+			name:      "gives up after max attempts",
+			responses: []string{writeQuery, writeQuery, writeQuery, answer},
+		},
+		{
+			name:   "generation error",
+			llmErr: errors.New("rate limited"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &scriptedChatModel{responses: tt.responses, err: tt.llmErr}
+			graph := &cypherGraphStorage{
+				result: &storage.CypherResult{Columns: []string{"c"}, Rows: [][]any{{int64(2)}}},
+				errs:   tt.execErrs,
+			}
+			tool := NewGraphCompletionTool(nil, graph, llm, nil, nil, testMemoryGroup, "test-model", nil, nil)
+			got, _, err := tool.getNaturalLanguageAnswer(context.Background(), "How many nodes are there?", true)
+			if !tt.wantAnswer {
+				if err == nil {
+					t.Fatalf("Expected error, got answer %v", got)
+				}
+				if len(graph.queries) != 0 {
+					t.Errorf("Rejected queries were executed: %v", graph.queries)
+				}
+				if tt.llmErr == nil && len(llm.inputs) != appconfig.NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS {
+					t.Errorf("LLM calls = %d, want %d", len(llm.inputs), appconfig.NATURAL_LANGUAGE_CYPHER_MAX_ATTEMPTS)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getNaturalLanguageAnswer failed: %v", err)
+			}
+			if got == nil || *got != answer {
+				t.Errorf("answer = %v, want %q", got, answer)
+			}
+			if strings.Join(graph.queries, "\n") != strings.Join(tt.wantQueries, "\n") {
+				t.Errorf("Executed queries = %q, want %q", graph.queries, tt.wantQueries)
+			}
+			for _, want := range tt.wantFeedback {
+				if prompt := llm.lastUserPrompt(1); !strings.Contains(prompt, want) {
+					t.Errorf("Retry prompt does not contain %q:\n%s", want, prompt)
+				}
+			}
+			// 回答の生成には、モデルが生成したクエリ（スコープ前）と実行結果が渡される
+			if prompt := llm.lastUserPrompt(len(llm.inputs) - 1); !strings.Contains(prompt, countQuery) || !strings.Contains(prompt, `"rows":[[2]]`) {
+				t.Errorf("Unexpected answer prompt:\n%s", prompt)
+			}
+		})
+	}
+}
+
+func TestFormatCypherResultForPrompt(t *testing.T) {
+	sep := consts.ID_MEMORY_GROUP_SEPARATOR
+	manyRows := make([][]any, appconfig.NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS+1)
+	for i := range manyRows {
+		manyRows[i] = []any{i}
+	}
+	tests := []struct {
+		name          string
+		result        *storage.CypherResult
+		wantContains  []string
+		wantRows      int
+		wantTruncated bool
+	}{
+		{
+			name:         "memory group suffix is removed",
+			result:       &storage.CypherResult{Columns: []string{"id"}, Rows: [][]any{{"Alice" + sep + testMemoryGroup}}},
+			wantContains: []string{`"rows":[["Alice"]]`},
+			wantRows:     1,
+		},
+		{
+			name:          "truncated by storage",
+			result:        &storage.CypherResult{Columns: []string{"id"}, Rows: [][]any{{1}}, Truncated: true},
+			wantRows:      1,
+			wantTruncated: true,
+		},
+		{
+			name:          "truncated by context rows",
+			result:        &storage.CypherResult{Columns: []string{"i"}, Rows: manyRows},
+			wantRows:      appconfig.NATURAL_LANGUAGE_CYPHER_MAX_CONTEXT_ROWS,
+			wantTruncated: true,
+		},
+	}
+	tool := NewGraphCompletionTool(nil, nil, nil, nil, nil, testMemoryGroup, "test-model", nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.formatCypherResultForPrompt(tt.result)
+			if err != nil {
+				t.Fatalf("formatCypherResultForPrompt failed: %v", err)
+			}
+			if strings.Contains(got, sep) {
+				t.Errorf("Result contains the memory group separator: %s", got)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Result does not contain %q: %s", want, got)
+				}
+			}
+			var decoded storage.CypherResult
+			if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if len(decoded.Rows) != tt.wantRows || decoded.Truncated != tt.wantTruncated {
+				t.Errorf("rows = %d, truncated = %v, want %d, %v", len(decoded.Rows), decoded.Truncated, tt.wantRows, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestExtractCypher(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", "  MATCH (n) RETURN n  ", "MATCH (n) RETURN n"},
+		{"code block with language", "```cypher\nMATCH (n)\nRETURN n\n```", "MATCH (n)\nRETURN n"},
+		{"code block without language", "```\nMATCH (n) RETURN n\n```", "MATCH (n) RETURN n"},
+		{"text around code block", "Here is the query:\n```cypher\nMATCH (n) RETURN n\n```\nIt counts nodes.", "MATCH (n) RETURN n"},
+		{"unterminated code block", "```cypher\nMATCH (n) RETURN n", "MATCH (n) RETURN n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractCypher(tt.content); got != tt.want {
+				t.Errorf("extractCypher(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// NarratePaths は、2つのエンティティ間の経路を、チャットモデルで自然な文章として説明します。
+// 各経路は GenerateNatural*GraphExplanationByTriples で説明文に変換してから LLM に渡します。
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - sourceID: 起点のエンティティ
+//   - targetID: 終点のエンティティ
+//   - paths: 経路（storage.GraphStorage.FindPaths の結果）
+//   - isEn: true の場合は英語、false の場合は日本語で説明する
+//
+// 返り値:
+//   - narration: 説明文（経路がない場合は空）
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) NarratePaths(ctx context.Context, sourceID string, targetID string, paths []*storage.Path, isEn bool) (narration string, usage types.TokenUsage, err error) {
+	if len(paths) == 0 {
+		return
+	}
+	var pathsText strings.Builder
+	for i, path := range paths {
+		fmt.Fprintf(&pathsText, "## Path %d (%s)\n", i+1, strings.Join(path.NodeIDs, " - "))
+		if isEn {
+			GenerateNaturalEnglishGraphExplanationByTriples(&path.Triples, &pathsText)
+		} else {
+			GenerateNaturalJapaneseGraphExplanationByTriples(&path.Triples, &pathsText)
+		}
+		pathsText.WriteString("\n")
+	}
+	userPrompt := fmt.Sprintf("From: %s\nTo: %s\n\nPaths:\n%s", sourceID, targetID, pathsText.String())
+	promptName := string(prompts.NAME_PATH_NARRATION_JA)
+	systemPrompt := prompts.Get(ctx, prompts.NAME_PATH_NARRATION_JA)
+	if isEn {
+		promptName = string(prompts.NAME_PATH_NARRATION_EN)
+		systemPrompt = prompts.Get(ctx, prompts.NAME_PATH_NARRATION_EN)
+	}
+
+	// Emit Generation Start
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  promptName,
+	})
+
+	narration, usage, err = utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  usage,
+		Response:    narration,
+	})
+
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to narrate paths: %w", err)
+		return
+	}
+	if narration == "" {
+		err = errors.New("GraphCompletionTool: No narration generated.")
+	}
+	return
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/t-kawata/mycute/lib/eventbus"
+	"github.com/t-kawata/mycute/pkg/cuber/event"
+	"github.com/t-kawata/mycute/pkg/cuber/ontology"
+	"github.com/t-kawata/mycute/pkg/cuber/prompts"
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+	"github.com/t-kawata/mycute/pkg/cuber/utils"
+)
+
+// TEMPORAL_DATE_LAYOUT は、時系列検索で LLM に渡す観測日時の書式です。
+const TEMPORAL_DATE_LAYOUT = "2006-01-02 15:04"
+
+// getTemporalAnswer は、観測日時で絞り込んだチャンクとグラフから、期間を踏まえて回答を生成します (QUERY_TYPE_TEMPORAL)。
+// 「3月時点で正しかったこと」「先週から変わったこと」のような、時間を軸にした質問に回答するために使用します。
+//
+// 処理の流れ:
+//  1. 元データの取り込み日時が期間内のチャンクを検索する
+//  2. 観測時刻（エッジの unix）が期間内のトリプルを取得する
+//     排他的関係（オントロジーの cardinality=one、または utils.ExclusiveRelationType）の古い値も変化の履歴として残すため、矛盾解決は行わない
+//  3. トリプルを観測順のタイムラインと、置き換えられた排他的関係の一覧に変換する
+//  4. 期間・チャンク・タイムラインをコンテキストとして回答を生成する
+//
+// 引数:
+//   - ctx: コンテキスト
+//   - chunkTopk: 取得するチャンク数
+//   - entityTopk: グラフ探索の種とするエンティティ数
+//   - query: 質問
+//   - config: クエリ設定（TimeRange で期間を指定）
+//
+// 返り値:
+//   - embedding: 質問の埋め込みベクトル
+//   - answer: 回答
+//   - graph: 期間内のトリプル
+//   - usage: トークン使用量
+//   - err: エラー
+func (t *GraphCompletionTool) getTemporalAnswer(ctx context.Context, chunkTopk int, entityTopk int, query string, config types.QueryConfig) (embedding *[]float32, answer *string, graph *[]*storage.Triple, usage types.TokenUsage, err error) {
+	// 1. 期間内のチャンクを検索
+	embedding, results, u, err := t.searchChunks(ctx, chunkTopk, query, nil, &config.TimeRange)
+	usage.Add(u)
+	if err != nil {
+		return
+	}
+	// 2. 期間内のグラフを取得（置き換えられた関係も履歴として残すため、矛盾解決は行わない）
+	graphConfig := config
+	graphConfig.ConflictResolutionStage = 0
+	_, graph, u, err = t.getGraph(ctx, entityTopk, query, embedding, graphConfig)
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to get graph: %w", err)
+		return
+	}
+	if len(results) == 0 && len(*graph) == 0 {
+		tmp := ""
+		answer = &tmp
+		return
+	}
+	// 3. コンテキストを構築
+	var chunksText strings.Builder
+	for _, result := range results {
+		fmt.Fprintf(&chunksText, "- [ingested %s] %s\n\n", formatTemporalDate(result.ObservedAt), result.Text)
+	}
+	timeline, superseded := GenerateTemporalGraphExplanationByTriples(*graph, ontology.FromContext(ctx).IsExclusive)
+	userPrompt := fmt.Sprintf("User Question: %s\n\nTime Window: %s\n\nDocument Excerpts:\n%s\n\nKnowledge Graph Timeline:\n%s\n\nSuperseded Relations:\n%s",
+		query, describeTimeRange(config.TimeRange), strings.TrimSpace(chunksText.String()), timeline, superseded)
+
+	// 4. 回答を生成
+	promptName := string(prompts.NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA)
+	systemPrompt := prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_JA)
+	if config.IsEn {
+		promptName = string(prompts.NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN)
+		systemPrompt = prompts.Get(ctx, prompts.NAME_ANSWER_QUERY_WITH_TEMPORAL_CONTEXT_EN)
+	}
+
+	// Emit Generation Start (Final Answer)
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_START), event.QueryGenerationStartPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		PromptName:  promptName,
+	})
+
+	answerContent, u, err := utils.GenerateWithUsage(ctx, t.LLM, t.ModelName, systemPrompt, userPrompt)
+
+	// Emit Generation End
+	eventbus.Emit(t.EventBus, string(event.EVENT_QUERY_GENERATION_END), event.QueryGenerationEndPayload{
+		BasePayload: event.NewBasePayload(t.memoryGroup),
+		TokenUsage:  u,
+		Response:    answerContent,
+	})
+
+	usage.Add(u)
+	if err != nil {
+		err = fmt.Errorf("GraphCompletionTool: Failed to generate final answer: %w", err)
+		return
+	}
+	if answerContent == "" {
+		err = errors.New("GraphCompletionTool: No final answer generated.")
+		return
+	}
+	answer = &answerContent
+	return
+}
+
+// GenerateTemporalGraphExplanationByTriples は、トリプルを観測順のタイムラインと、
+// 複数の値が観測された排他的関係（isExclusive。メモリーグループのオントロジー、または utils.ExclusiveRelationType）の変遷に変換します（英語）。
+// 変遷は古い順に並び、最後の値がそれ以前の値を置き換えたものとして扱われます。
+func GenerateTemporalGraphExplanationByTriples(triples []*storage.Triple, isExclusive func(relationType string) bool) (timeline string, superseded string) {
+	sorted := make([]*storage.Triple, len(triples))
+	copy(sorted, triples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Edge.Unix < sorted[j].Edge.Unix
+	})
+
+	var timelineText strings.Builder
+	// 排他的関係を (SourceID, RelationType) でまとめる（観測順を保持）
+	exclusiveGroups := make(map[string][]*storage.Triple)
+	var exclusiveKeys []string
+	for _, triple := range sorted {
+		fmt.Fprintf(&timelineText, "- [observed %s] '%s' %s '%s'.\n",
+			formatTemporalDate(time.UnixMilli(triple.Edge.Unix)), triple.Source.ID, triple.Edge.Type, triple.Target.ID)
+		if !isExclusive(triple.Edge.Type) {
+			continue
+		}
+		key := triple.Edge.SourceID + "|" + triple.Edge.Type
+		if _, ok := exclusiveGroups[key]; !ok {
+			exclusiveKeys = append(exclusiveKeys, key)
+		}
+		exclusiveGroups[key] = append(exclusiveGroups[key], triple)
+	}
+
+	var supersededText strings.Builder
+	for _, key := range exclusiveKeys {
+		group := exclusiveGroups[key]
+		if len(group) < 2 {
+			continue
+		}
+		values := make([]string, 0, len(group))
+		for _, triple := range group {
+			values = append(values, fmt.Sprintf("'%s' (observed %s)", triple.Target.ID, formatTemporalDate(time.UnixMilli(triple.Edge.Unix))))
+		}
+		fmt.Fprintf(&supersededText, "- '%s' %s: %s\n", group[0].Source.ID, group[0].Edge.Type, strings.Join(values, " -> "))
+	}
+
+	timeline = strings.TrimSpace(timelineText.String())
+	if timeline == "" {
+		timeline = "(none)"
+	}
+	superseded = strings.TrimSpace(supersededText.String())
+	if superseded == "" {
+		superseded = "(none)"
+	}
+	return
+}
+
+// describeTimeRange は、期間を LLM に渡す英語の説明文に変換します。
+func describeTimeRange(r types.TimeRange) string {
+	switch {
+	case r.From.IsZero() && r.To.IsZero():
+		return "all time"
+	case r.To.IsZero():
+		return fmt.Sprintf("since %s", formatTemporalDate(r.From))
+	case r.From.IsZero():
+		return fmt.Sprintf("until %s", formatTemporalDate(r.To))
+	default:
+		return fmt.Sprintf("from %s to %s", formatTemporalDate(r.From), formatTemporalDate(r.To))
+	}
+}
+
+// formatTemporalDate は、日時を TEMPORAL_DATE_LAYOUT の書式（ローカル時刻）に変換します。
+// ゼロ値の場合は "unknown" を返します。
+func formatTemporalDate(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.In(time.Local).Format(TEMPORAL_DATE_LAYOUT)
+}
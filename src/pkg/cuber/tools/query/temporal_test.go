@@ -0,0 +1,109 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+	"github.com/t-kawata/mycute/pkg/cuber/types"
+)
+
+// newTemporalTriple は、指定した日時に観測されたトリプルを返します。
+func newTemporalTriple(source string, relationType string, target string, observedAt time.Time) *storage.Triple {
+	return &storage.Triple{
+		Source: &storage.Node{ID: source},
+		Edge:   &storage.Edge{SourceID: source, TargetID: target, Type: relationType, Unix: observedAt.UnixMilli()},
+		Target: &storage.Node{ID: target},
+	}
+}
+
+func TestGenerateTemporalGraphExplanationByTriples(t *testing.T) {
+	jan := time.Date(2025, 1, 10, 9, 0, 0, 0, time.Local)
+	mar := time.Date(2025, 3, 1, 18, 30, 0, 0, time.Local)
+	jun := time.Date(2025, 6, 20, 12, 0, 0, 0, time.Local)
+	isExclusive := func(relationType string) bool { return relationType == "WORKS_AT" }
+	tests := []struct {
+		name           string
+		triples        []*storage.Triple
+		wantTimeline   string
+		wantSuperseded string
+	}{
+		{
+			name:           "no triples",
+			wantTimeline:   "(none)",
+			wantSuperseded: "(none)",
+		},
+		{
+			name: "timeline is sorted by observation time",
+			triples: []*storage.Triple{
+				newTemporalTriple("Alice", "KNOWS", "Bob", mar),
+				newTemporalTriple("Alice", "LIVES_IN", "Tokyo", jan),
+			},
+			wantTimeline:   "- [observed 2025-01-10 09:00] 'Alice' LIVES_IN 'Tokyo'.\n- [observed 2025-03-01 18:30] 'Alice' KNOWS 'Bob'.",
+			wantSuperseded: "(none)",
+		},
+		{
+			name: "exclusive relation with several values",
+			triples: []*storage.Triple{
+				newTemporalTriple("Alice", "WORKS_AT", "Globex", jun),
+				newTemporalTriple("Alice", "WORKS_AT", "Acme", jan),
+				newTemporalTriple("Alice", "WORKS_AT", "Initech", mar),
+			},
+			wantTimeline: "- [observed 2025-01-10 09:00] 'Alice' WORKS_AT 'Acme'.\n" +
+				"- [observed 2025-03-01 18:30] 'Alice' WORKS_AT 'Initech'.\n" +
+				"- [observed 2025-06-20 12:00] 'Alice' WORKS_AT 'Globex'.",
+			wantSuperseded: "- 'Alice' WORKS_AT: 'Acme' (observed 2025-01-10 09:00) -> 'Initech' (observed 2025-03-01 18:30) -> 'Globex' (observed 2025-06-20 12:00)",
+		},
+		{
+			name: "exclusive relation is grouped by source",
+			triples: []*storage.Triple{
+				newTemporalTriple("Alice", "WORKS_AT", "Acme", jan),
+				newTemporalTriple("Bob", "WORKS_AT", "Globex", mar),
+			},
+			wantTimeline:   "- [observed 2025-01-10 09:00] 'Alice' WORKS_AT 'Acme'.\n- [observed 2025-03-01 18:30] 'Bob' WORKS_AT 'Globex'.",
+			wantSuperseded: "(none)",
+		},
+		{
+			name: "non-exclusive relation with several values",
+			triples: []*storage.Triple{
+				newTemporalTriple("Alice", "KNOWS", "Bob", jan),
+				newTemporalTriple("Alice", "KNOWS", "Carol", mar),
+			},
+			wantTimeline:   "- [observed 2025-01-10 09:00] 'Alice' KNOWS 'Bob'.\n- [observed 2025-03-01 18:30] 'Alice' KNOWS 'Carol'.",
+			wantSuperseded: "(none)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline, superseded := GenerateTemporalGraphExplanationByTriples(tt.triples, isExclusive)
+			if timeline != tt.wantTimeline {
+				t.Errorf("timeline =\n%s\nwant\n%s", timeline, tt.wantTimeline)
+			}
+			if superseded != tt.wantSuperseded {
+				t.Errorf("superseded =\n%s\nwant\n%s", superseded, tt.wantSuperseded)
+			}
+		})
+	}
+}
+
+func TestDescribeTimeRange(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2025, 3, 31, 23, 59, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		r    types.TimeRange
+		want string
+	}{
+		{"unbounded", types.TimeRange{}, "all time"},
+		{"from only", types.TimeRange{From: from}, "since 2025-03-01 00:00"},
+		{"to only", types.TimeRange{To: to}, "until 2025-03-31 23:59"},
+		{"from and to", types.TimeRange{From: from, To: to}, "from 2025-03-01 00:00 to 2025-03-31 23:59"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeTimeRange(tt.r); got != tt.want {
+				t.Errorf("describeTimeRange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
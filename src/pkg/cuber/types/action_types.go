@@ -6,4 +6,6 @@ const (
 	ACTION_TYPE_ABSORB ActionType = "absorb"
 	ACTION_TYPE_MEMIFY ActionType = "memify"
 	ACTION_TYPE_QUERY  ActionType = "query"
+	// Cube 全体を対象とする操作
+	ACTION_TYPE_MIGRATE_EMBEDDINGS ActionType = "migrate_embeddings"
 )
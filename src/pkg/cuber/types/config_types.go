@@ -1,6 +1,10 @@
 package types
 
-import "go.uber.org/zap"
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
 
 // CuberConfig は、Cuberサービスの初期化に必要な設定を保持する構造体です。
 // データベースの配置場所とLLMプロバイダーの接続情報を含みます。
@@ -10,6 +14,7 @@ type CuberConfig struct {
 
 	// LadybugDB Configuration
 	LadybugDBDatabasePath string // Path to LadybugDB database file (if different from default)
+	VectorSearchEfs       int    // ベクトルインデックス検索の探索幅 efs。大きいほど再現率が上がり遅くなる (Default: 200)
 
 	// Memify設定
 	MemifyMaxCharsForBulkProcess int // デフォルト: 50000
@@ -54,20 +59,59 @@ type CuberConfig struct {
 
 // CognifyConfig は、cognifyの設定を表す構造体です。
 type CognifyConfig struct {
-	ChunkSize    int // チャンクのサイズとなる文字数（トークン数でカウントするとユーザーが使いにくいのでやめた）
-	ChunkOverlap int // チャンクのオーバーラップとなる文字数（トークン数でカウントするとユーザーが使いにくいのでやめた）
+	ChunkSize           int     // チャンクのサイズとなる文字数（トークン数でカウントするとユーザーが使いにくいのでやめた）
+	ChunkOverlap        int     // チャンクのオーバーラップとなる文字数（トークン数でカウントするとユーザーが使いにくいのでやめた）
+	ChunkMaxRetries     int     // グラフ抽出に失敗したチャンクを再試行する回数（0の場合はデフォルト）
+	MaxFailedChunkRatio float64 // 再試行してもグラフ抽出に失敗したチャンクをスキップして続行できる割合（0〜1。0の場合は1チャンクでも失敗したらエラー）
 }
 
 type QueryConfig struct {
-	QueryType               QueryType // 検索タイプ
-	SummaryTopk             int       // 要約の上位k件を取得
-	ChunkTopk               int       // チャンクの上位k件を取得
-	EntityTopk              int       // エンティティの上位k件を対象にグラフを取得
-	IsEn                    bool      // true=English output, false=Japanese output
-	FtsLayer                FtsLayer  // FTS検索に使用するレイヤー（nouns, nouns_verbs, all）
-	FtsTopk                 int       // FTSによるエンティティ拡張数（デフォルト: 3）
-	ThicknessThreshold      float64   // 検索時に採用するエッジの最小「太さ」（デフォルト: 0.3）
-	ConflictResolutionStage uint8     // 矛盾解決ステージ: 0=なし, 1=Stage1のみ, 2=Stage1+Stage2
+	QueryType               QueryType     // 検索タイプ
+	SummaryTopk             int           // 要約の上位k件を取得
+	ChunkTopk               int           // チャンクの上位k件を取得
+	EntityTopk              int           // エンティティの上位k件を対象にグラフを取得
+	IsEn                    bool          // true=English output, false=Japanese output
+	FtsLayer                FtsLayer      // FTS検索に使用するレイヤー（nouns, nouns_verbs, all）
+	FtsTopk                 int           // FTSによるエンティティ拡張数（デフォルト: 3）
+	ThicknessThreshold      float64       // 検索時に採用するエッジの最小「太さ」（デフォルト: 0.3）
+	ConflictResolutionStage uint8         // 矛盾解決ステージ: 0=なし, 1=Stage1のみ, 2=Stage1+Stage2
+	CotRounds               int           // 推論型クエリの最大ラウンド数（0の場合は DEFAULT_COT_ROUNDS）
+	TimeRange               TimeRange     // 時系列検索の対象期間（QUERY_TYPE_TEMPORAL のみ使用）
+	HybridWeights           HybridWeights // ハイブリッド検索の各ランキングの重み（QUERY_TYPE_HYBRID のみ使用）
+	CommunityLevel          int           // グローバル検索で使用するコミュニティの階層（0が最上位。存在しない場合は最も細かい階層。QUERY_TYPE_GLOBAL のみ使用）
+}
+
+// HybridWeights は、ハイブリッド検索 (QUERY_TYPE_HYBRID) で各ランキングを融合する際の重みです。
+// 重みが0のランキングは使用しません。全ての重みが0の場合は、デフォルトの重み（DEFAULT_HYBRID_WEIGHT_*）を使用します。
+type HybridWeights struct {
+	Vector        float64 // チャンクのベクトル類似度
+	FtsNouns      float64 // FTS（名詞）
+	FtsNounsVerbs float64 // FTS（名詞 + 動詞）
+	FtsKeywords   float64 // FTS（全内容語）
+	Graph         float64 // グラフ近傍での中心性（チャンクを出典とするエッジの太さと次数）
+}
+
+// IsZero は、全ての重みが0かどうかを判定します。
+func (w HybridWeights) IsZero() bool {
+	return w.Vector == 0 && w.FtsNouns == 0 && w.FtsNounsVerbs == 0 && w.FtsKeywords == 0 && w.Graph == 0
+}
+
+// TimeRange は、時系列検索の対象期間を表します。
+// From / To がゼロ値の場合、その側の期間は無制限です（両端を含む）。
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ContainsUnixMilli は、Unixミリ秒で表される時刻が期間内かどうかを判定します。
+func (r TimeRange) ContainsUnixMilli(unixMilli int64) bool {
+	if !r.From.IsZero() && unixMilli < r.From.UnixMilli() {
+		return false
+	}
+	if !r.To.IsZero() && unixMilli > r.To.UnixMilli() {
+		return false
+	}
+	return true
 }
 
 // FtsLayerType はREST API用のFTSレイヤータイプです（uint8）。
@@ -15,4 +15,6 @@ type ChatModelConfig struct {
 	ApiKey      string   `json:"-"`
 	MaxTokens   int      `json:"max_tokens"`
 	Temperature *float64 `json:"temperature"` // pointer to distinguish 0 from nil
+	// Fallbacks are tried in order when this model still fails after retries (their own Fallbacks are ignored).
+	Fallbacks []ChatModelConfig `json:"fallbacks,omitempty"`
 }
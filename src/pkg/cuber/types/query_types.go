@@ -25,19 +25,22 @@ const (
 
 	// ========================================
 	// 未実装（将来のフェーズ）
+	// 実装済みになったものは VALID_QUERY_TYPES に追加される
 	// ========================================
 	QUERY_TYPE_CHUNKS                       QueryType = iota + 6 // チャンクのみを検索 (12から開始)
 	QUERY_TYPE_RAG_COMPLETION                                    // RAG（Retrieval-Augmented Generation）
 	QUERY_TYPE_CODE                                              // コード検索
-	QUERY_TYPE_CYCLER                                            // Cypherクエリ
-	QUERY_TYPE_NATURAL_LANGUAGE                                  // 自然言語クエリ
+	QUERY_TYPE_CYCLER                                            // Cypherクエリ（読み取り専用、memory_group でスコープ）
+	QUERY_TYPE_NATURAL_LANGUAGE                                  // 自然言語クエリ（LLM により Cypher に変換して実行）
 	QUERY_TYPE_GRAPH_COMPLETION_COT                              // Chain-of-Thought付きグラフ検索
 	QUERY_TYPE_GRAPH_COMPLETION_CONTEXT_EXT                      // コンテキスト拡張付きグラフ検索
 	QUERY_TYPE_FEELING_LUCKY                                     // ランダム検索
-	QUERY_TYPE_FEEDBACK                                          // フィードバックベース検索
+	QUERY_TYPE_FEEDBACK                                          // フィードバック（検索ではなく、POST /v1/cubes/feedback で回答の根拠となったエッジを強化・弱化する。QueryCube では使用不可）
 	QUERY_TYPE_TEMPORAL                                          // 時系列検索
 	QUERY_TYPE_CODING_RULES                                      // コーディングルール検索
 	QUERY_TYPE_CHUNKS_LEXICAL                                    // 字句ベースチャンク検索
+	QUERY_TYPE_HYBRID                                            // ハイブリッド検索（ベクトル・FTS・グラフのランキングを RRF で融合して回答）
+	QUERY_TYPE_GLOBAL                                            // グローバル検索（コミュニティの要約を map-reduce して、知識全体にわたる質問に回答）
 )
 
 var VALID_QUERY_TYPES = []QueryType{
@@ -52,6 +55,12 @@ var VALID_QUERY_TYPES = []QueryType{
 	QUERY_TYPE_GET_GRAPH_SUMMARY_TO_ANSWER,
 	QUERY_TYPE_ANSWER_BY_PRE_MADE_SUMMARIES_AND_GRAPH_SUMMARY,
 	QUERY_TYPE_ANSWER_BY_CHUNKS_AND_GRAPH_SUMMARY,
+	QUERY_TYPE_CYCLER,
+	QUERY_TYPE_NATURAL_LANGUAGE,
+	QUERY_TYPE_GRAPH_COMPLETION_COT,
+	QUERY_TYPE_TEMPORAL,
+	QUERY_TYPE_HYBRID,
+	QUERY_TYPE_GLOBAL,
 }
 
 // 文字列を渡して有効なクエリタイプかどうか判定する関数
@@ -84,6 +93,20 @@ func (q QueryType) String() string {
 		return "ANSWER_BY_PRE_MADE_SUMMARIES_AND_GRAPH_SUMMARY"
 	case QUERY_TYPE_ANSWER_BY_CHUNKS_AND_GRAPH_SUMMARY:
 		return "ANSWER_BY_CHUNKS_AND_GRAPH_SUMMARY"
+	case QUERY_TYPE_CYCLER:
+		return "CYCLER"
+	case QUERY_TYPE_NATURAL_LANGUAGE:
+		return "NATURAL_LANGUAGE"
+	case QUERY_TYPE_GRAPH_COMPLETION_COT:
+		return "GRAPH_COMPLETION_COT"
+	case QUERY_TYPE_FEEDBACK:
+		return "FEEDBACK"
+	case QUERY_TYPE_TEMPORAL:
+		return "TEMPORAL"
+	case QUERY_TYPE_HYBRID:
+		return "HYBRID"
+	case QUERY_TYPE_GLOBAL:
+		return "GLOBAL"
 	default:
 		return fmt.Sprintf("UNKNOWN_QUERY_TYPE_%d", q)
 	}
@@ -12,6 +12,26 @@ const (
 	TABLE_NAME_RULE       TableName = "Rule"
 	TABLE_NAME_UNKNOWN    TableName = "Unknown"
 	TABLE_NAME_CAPABILITY TableName = "Capability"
+	// エッジの出典（抽出元チャンク）
+	TABLE_NAME_EDGE_PROVENANCE TableName = "EdgeProvenance"
+	// 埋め込みキャッシュ
+	TABLE_NAME_EMBEDDING_CACHE TableName = "EmbeddingCache"
+	// プロンプトの上書き（メモリーグループごと）
+	TABLE_NAME_PROMPT_OVERRIDE TableName = "PromptOverride"
+	// オントロジー（メモリーグループごと）
+	TABLE_NAME_ONTOLOGY TableName = "Ontology"
+	// コミュニティ（グラフのクラスタリング結果と要約）
+	TABLE_NAME_COMMUNITY TableName = "Community"
 	// REL
 	TABLE_NAME_GRAPH_EDGE TableName = "GraphEdge"
 )
+
+// EMBEDDING_TABLE_NAMES は、embedding カラム（Cube の埋め込みモデルの次元数の FLOAT 配列）を持つテーブルです。
+var EMBEDDING_TABLE_NAMES = []TableName{
+	TABLE_NAME_CHUNK,
+	TABLE_NAME_ENTITY,
+	TABLE_NAME_SUMMARY,
+	TABLE_NAME_RULE,
+	TABLE_NAME_UNKNOWN,
+	TABLE_NAME_CAPABILITY,
+}
@@ -6,12 +6,18 @@ type TokenUsage struct {
 	OutputTokens int64 `json:"completion_tokens"`
 	// Details はモデルごとの使用量内訳を保持します。Keyはモデル名です。
 	Details map[string]TokenUsage `json:"details,omitempty"`
+	// EmbeddingCacheHits / EmbeddingCacheMisses は、埋め込みキャッシュのヒット数・ミス数です。
+	// キャッシュにヒットしたテキストはプロバイダーを呼び出さないため、トークン使用量には含まれません。
+	EmbeddingCacheHits   int64 `json:"embedding_cache_hits,omitempty"`
+	EmbeddingCacheMisses int64 `json:"embedding_cache_misses,omitempty"`
 }
 
 // Add は他の Usage を加算します。
 func (t *TokenUsage) Add(other TokenUsage) {
 	t.InputTokens += other.InputTokens
 	t.OutputTokens += other.OutputTokens
+	t.EmbeddingCacheHits += other.EmbeddingCacheHits
+	t.EmbeddingCacheMisses += other.EmbeddingCacheMisses
 	if t.Details == nil {
 		t.Details = make(map[string]TokenUsage)
 	}
@@ -19,6 +25,8 @@ func (t *TokenUsage) Add(other TokenUsage) {
 		if existing, ok := t.Details[model]; ok {
 			existing.InputTokens += usage.InputTokens
 			existing.OutputTokens += usage.OutputTokens
+			existing.EmbeddingCacheHits += usage.EmbeddingCacheHits
+			existing.EmbeddingCacheMisses += usage.EmbeddingCacheMisses
 			t.Details[model] = existing
 		} else {
 			t.Details[model] = usage
@@ -20,6 +20,15 @@ type TokenUsageAggregator struct {
 	ModelName  string // 集計時にモデル名をDetailsに記録する場合に使用
 }
 
+// answeringModelKey は、実際に応答するモデルの名前をコンテキストに設定するためのキーです。
+type answeringModelKey struct{}
+
+// WithAnsweringModel は、実際に応答するモデルの名前をコンテキストに設定します。
+// フォールバック先のモデルが応答した場合に、トークン使用量をそのモデル名で集計するために使用します。
+func WithAnsweringModel(ctx context.Context, modelName string) context.Context {
+	return context.WithValue(ctx, answeringModelKey{}, modelName)
+}
+
 // NewTokenUsageAggregator は新しい集計器を作成します。
 func NewTokenUsageAggregator(modelName string) *TokenUsageAggregator {
 	return &TokenUsageAggregator{
@@ -65,7 +74,11 @@ func (agg *TokenUsageAggregator) Handler() callbacks.Handler {
 				}
 
 				// 既存のモデル詳細があれば加算、なければ新規作成
+				// フォールバック先のモデルが応答した場合は、そのモデル名で集計する
 				modelKey := agg.ModelName
+				if name, ok := ctx.Value(answeringModelKey{}).(string); ok && name != "" {
+					modelKey = name
+				}
 				if modelKey == "" {
 					modelKey = "unknown_model"
 				}
@@ -144,21 +144,25 @@ type ConflictGroup struct {
 }
 
 // Stage1ConflictResolution は、決定論的ルールに基づいて矛盾を解決します。
-// 排他的関係リストに含まれる関係タイプについて、同一 (SourceID, RelationType) ペアの中で
+// 排他的な関係タイプについて、同一 (SourceID, RelationType) ペアの中で
 // 最高 Thickness スコアのエッジのみを残します。
 //
 // 引数:
 //   - triples: スコア付きトリプルのリスト
+//   - isExclusive: 関係タイプが排他的かどうかを判定する関数（メモリーグループのオントロジー。nil の場合は ExclusiveRelationType で判定）
 //   - logger: ロガー
 //
 // 戻り値:
 //   - resolved: Stage 1 で解決されたトリプルのリスト
 //   - discarded: Stage 1 で矛盾と判断され、削除対象となったトリプルのリスト
 //   - remainingConflicts: Stage 2 で解決が必要な矛盾グループ
-func Stage1ConflictResolution(triples []ScoredTriple, logger *zap.Logger, isEn bool) (resolved []ScoredTriple, discarded []DiscardedTriple, remainingConflicts []ConflictGroup) {
+func Stage1ConflictResolution(triples []ScoredTriple, isExclusive func(relationType string) bool, logger *zap.Logger, isEn bool) (resolved []ScoredTriple, discarded []DiscardedTriple, remainingConflicts []ConflictGroup) {
 	if len(triples) == 0 {
 		return triples, nil, nil
 	}
+	if isExclusive == nil {
+		isExclusive = func(relationType string) bool { return ExclusiveRelationType[relationType] }
+	}
 
 	// (SourceID, RelationType) でグループ化
 	groupMap := make(map[string][]ScoredTriple)
@@ -186,7 +190,7 @@ func Stage1ConflictResolution(triples []ScoredTriple, logger *zap.Logger, isEn b
 		}
 
 		// 一つのソースが特定の関係性にて複数のエッジを持つ場合
-		if ExclusiveRelationType[relationType] { // ステージ1の明示的排他対象関係だった場合
+		if isExclusive(relationType) { // ステージ1の明示的排他対象関係だった場合
 			// 最高スコアのエッジのみを残す
 			var best ScoredTriple
 			for _, st := range group {
@@ -323,9 +327,9 @@ func Stage2ConflictResolution(
 	// プロンプトの選択
 	var systemPrompt string
 	if isEn {
-		systemPrompt = prompts.ARBITRATE_CONFLICT_SYSTEM_EN_PROMPT
+		systemPrompt = prompts.Get(ctx, prompts.NAME_ARBITRATE_CONFLICT_EN)
 	} else {
-		systemPrompt = prompts.ARBITRATE_CONFLICT_SYSTEM_JA_PROMPT
+		systemPrompt = prompts.Get(ctx, prompts.NAME_ARBITRATE_CONFLICT_JA)
 	}
 
 	// 矛盾情報を JSON 形式で構築
@@ -354,7 +358,7 @@ func Stage2ConflictResolution(
 	}
 
 	// メッセージ構築（SystemプロンプトとUserプロンプトを分離）
-	userPrompt := fmt.Sprintf(prompts.ARBITRATE_CONFLICT_USER_PROMPT, string(conflictDataJSON))
+	userPrompt := fmt.Sprintf(prompts.Get(ctx, prompts.NAME_ARBITRATE_CONFLICT), string(conflictDataJSON))
 
 	// LLM 呼び出し（Eino Callback によるトークン使用量自動集計）
 	responseContent, usage, err := GenerateWithUsage(ctx, llm, modelName, systemPrompt, userPrompt)
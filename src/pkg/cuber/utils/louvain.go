@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"slices"
+)
+
+// WeightedEdge は、コミュニティ検出に使用する重み付きの無向エッジです。
+type WeightedEdge struct {
+	SourceID string
+	TargetID string
+	Weight   float64
+}
+
+// DetectCommunities は、Louvain 法で重み付きグラフのコミュニティを階層的に検出します。
+// 各階層では、モジュラリティが改善しなくなるまでノードを隣接するコミュニティに移動し、
+// 得られたコミュニティを1つのノードに集約して次の階層を検出します。
+// ノードは ID 順に処理するため、同じ入力に対して常に同じ結果を返します。
+//
+// 引数:
+//   - edges: 重み付きエッジ（向きは無視され、同じノード間のエッジの重みは合算されます。重みが0以下のエッジは無視されます）
+//   - resolution: 解像度パラメータ（大きいほど細かいコミュニティに分かれる。通常は 1.0）
+//   - maxLevels: 検出する階層の最大数
+//
+// 返り値:
+//   - []map[string]int: 階層ごとの「ノードID -> コミュニティ番号」（最初の要素が最も細かい階層）。
+//     コミュニティがそれ以上集約されなくなった時点で終了するため、要素数は maxLevels 以下になります。
+func DetectCommunities(edges []WeightedEdge, resolution float64, maxLevels int) []map[string]int {
+	// ノードに番号を振る（ID順）
+	idSet := make(map[string]bool)
+	for _, e := range edges {
+		if e.Weight <= 0 {
+			continue
+		}
+		idSet[e.SourceID] = true
+		idSet[e.TargetID] = true
+	}
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	// 隣接行列（無向のため両方向に加算。自己ループは 2 倍の重みで保持する）
+	adj := make([]map[int]float64, len(ids))
+	for i := range adj {
+		adj[i] = make(map[int]float64)
+	}
+	for _, e := range edges {
+		if e.Weight <= 0 {
+			continue
+		}
+		s, t := index[e.SourceID], index[e.TargetID]
+		adj[s][t] += e.Weight
+		adj[t][s] += e.Weight
+	}
+	// membership は、元のノードが現在の階層のどのノード（集約済みのコミュニティ）に属するかを表す
+	membership := make([]int, len(ids))
+	for i := range membership {
+		membership[i] = i
+	}
+	var levels []map[string]int
+	for level := 0; level < maxLevels && len(adj) > 1; level++ {
+		communities, count := louvainLocalMoving(adj, resolution)
+		if count == len(adj) {
+			// これ以上集約されない
+			break
+		}
+		assignment := make(map[string]int, len(ids))
+		for i, id := range ids {
+			membership[i] = communities[membership[i]]
+			assignment[id] = membership[i]
+		}
+		levels = append(levels, assignment)
+		adj = aggregateCommunities(adj, communities, count)
+	}
+	return levels
+}
+
+// louvainLocalMoving は、Louvain 法の局所移動フェーズを実行します。
+// 返り値は、ノードごとのコミュニティ番号（0 から連番）とコミュニティ数です。
+func louvainLocalMoving(adj []map[int]float64, resolution float64) ([]int, int) {
+	n := len(adj)
+	degree := make([]float64, n)
+	var m2 float64 // 全エッジの重みの合計の2倍
+	for i, row := range adj {
+		for _, w := range row {
+			degree[i] += w
+		}
+		m2 += degree[i]
+	}
+	community := make([]int, n)
+	total := make([]float64, n) // コミュニティごとの次数の合計
+	for i := range community {
+		community[i] = i
+		total[i] = degree[i]
+	}
+	if m2 == 0 {
+		return community, n
+	}
+	for moved := true; moved; {
+		moved = false
+		for i := 0; i < n; i++ {
+			// 隣接するコミュニティへのエッジの重みを集計（隣接ノードの番号順に処理して結果を決定的にする）
+			neighbors := make([]int, 0, len(adj[i]))
+			for j := range adj[i] {
+				neighbors = append(neighbors, j)
+			}
+			slices.Sort(neighbors)
+			links := make(map[int]float64)
+			var order []int
+			for _, j := range neighbors {
+				if j == i {
+					continue
+				}
+				c := community[j]
+				if _, ok := links[c]; !ok {
+					order = append(order, c)
+				}
+				links[c] += adj[i][j]
+			}
+			// ノードを現在のコミュニティから外し、モジュラリティの増分が最大のコミュニティに移動する
+			current := community[i]
+			total[current] -= degree[i]
+			best := current
+			bestGain := links[current] - resolution*total[current]*degree[i]/m2
+			for _, c := range order {
+				gain := links[c] - resolution*total[c]*degree[i]/m2
+				if gain > bestGain {
+					best, bestGain = c, gain
+				}
+			}
+			total[best] += degree[i]
+			if best != current {
+				community[i] = best
+				moved = true
+			}
+		}
+	}
+	// コミュニティ番号を出現順の連番に振り直す
+	renumber := make(map[int]int)
+	for i, c := range community {
+		if _, ok := renumber[c]; !ok {
+			renumber[c] = len(renumber)
+		}
+		community[i] = renumber[c]
+	}
+	return community, len(renumber)
+}
+
+// aggregateCommunities は、各コミュニティを1つのノードに集約したグラフを返します。
+// コミュニティ内のエッジは、集約したノードの自己ループになります。
+func aggregateCommunities(adj []map[int]float64, community []int, count int) []map[int]float64 {
+	aggregated := make([]map[int]float64, count)
+	for i := range aggregated {
+		aggregated[i] = make(map[int]float64)
+	}
+	for i, row := range adj {
+		for j, w := range row {
+			aggregated[community[i]][community[j]] += w
+		}
+	}
+	return aggregated
+}
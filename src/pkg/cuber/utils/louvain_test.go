@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// clique は、ノードを全て結ぶ重み weight のエッジを返します。
+func clique(weight float64, ids ...string) []WeightedEdge {
+	var edges []WeightedEdge
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			edges = append(edges, WeightedEdge{SourceID: ids[i], TargetID: ids[j], Weight: weight})
+		}
+	}
+	return edges
+}
+
+// biclique は、xs の各ノードと ys の各ノードを結ぶ重み weight のエッジを返します。
+func biclique(weight float64, xs, ys []string) []WeightedEdge {
+	var edges []WeightedEdge
+	for _, x := range xs {
+		for _, y := range ys {
+			edges = append(edges, WeightedEdge{SourceID: x, TargetID: y, Weight: weight})
+		}
+	}
+	return edges
+}
+
+// communityGroups は、コミュニティごとのノードIDを、ノードID順に並べて返します。
+func communityGroups(assignment map[string]int) [][]string {
+	byCommunity := map[int][]string{}
+	for id, c := range assignment {
+		byCommunity[c] = append(byCommunity[c], id)
+	}
+	var groups [][]string
+	for _, ids := range byCommunity {
+		slices.Sort(ids)
+		groups = append(groups, ids)
+	}
+	slices.SortFunc(groups, func(a, b []string) int { return slices.Compare(a, b) })
+	return groups
+}
+
+func TestDetectCommunities(t *testing.T) {
+	twoTriangles := append(append(clique(1, "a", "b", "c"), clique(1, "d", "e", "f")...), WeightedEdge{SourceID: "c", TargetID: "d", Weight: 0.1})
+	// 2つの三角形の組（a-b-c と d-e-f、g-h-i と j-k-l）の間を全て弱いエッジで結び、組同士はさらに弱い1本のエッジで結んだグラフ
+	fourTriangles := slices.Concat(
+		clique(1, "a", "b", "c"), clique(1, "d", "e", "f"), clique(1, "g", "h", "i"), clique(1, "j", "k", "l"),
+		biclique(0.3, []string{"a", "b", "c"}, []string{"d", "e", "f"}),
+		biclique(0.3, []string{"g", "h", "i"}, []string{"j", "k", "l"}),
+		[]WeightedEdge{{SourceID: "f", TargetID: "g", Weight: 0.05}},
+	)
+	tests := []struct {
+		name      string
+		edges     []WeightedEdge
+		maxLevels int
+		want      [][][]string // 階層ごとのコミュニティ
+	}{
+		{
+			name:      "no edges",
+			maxLevels: 3,
+		},
+		{
+			name:      "single edge",
+			edges:     []WeightedEdge{{SourceID: "a", TargetID: "b", Weight: 1}},
+			maxLevels: 3,
+			want:      [][][]string{{{"a", "b"}}},
+		},
+		{
+			name:      "non-positive weights are ignored",
+			edges:     []WeightedEdge{{SourceID: "a", TargetID: "b", Weight: 1}, {SourceID: "b", TargetID: "c", Weight: 0}, {SourceID: "c", TargetID: "d", Weight: -1}},
+			maxLevels: 3,
+			want:      [][][]string{{{"a", "b"}}},
+		},
+		{
+			name:      "weakly connected triangles",
+			edges:     twoTriangles,
+			maxLevels: 3,
+			want:      [][][]string{{{"a", "b", "c"}, {"d", "e", "f"}}},
+		},
+		{
+			name:      "hierarchy",
+			edges:     fourTriangles,
+			maxLevels: 3,
+			want: [][][]string{
+				{{"a", "b", "c"}, {"d", "e", "f"}, {"g", "h", "i"}, {"j", "k", "l"}},
+				{{"a", "b", "c", "d", "e", "f"}, {"g", "h", "i", "j", "k", "l"}},
+			},
+		},
+		{
+			name:      "max levels",
+			edges:     fourTriangles,
+			maxLevels: 1,
+			want:      [][][]string{{{"a", "b", "c"}, {"d", "e", "f"}, {"g", "h", "i"}, {"j", "k", "l"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := DetectCommunities(tt.edges, 1.0, tt.maxLevels)
+			var got [][][]string
+			for _, assignment := range levels {
+				got = append(got, communityGroups(assignment))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectCommunities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCommunitiesIsDeterministic(t *testing.T) {
+	edges := append(append(clique(1, "a", "b", "c"), clique(1, "d", "e", "f")...), WeightedEdge{SourceID: "c", TargetID: "d", Weight: 0.1})
+	want := DetectCommunities(edges, 1.0, 3)
+	// エッジの順序と向きを変えても、コミュニティ番号まで同じ結果になる
+	reversed := make([]WeightedEdge, 0, len(edges))
+	for _, e := range slices.Backward(edges) {
+		reversed = append(reversed, WeightedEdge{SourceID: e.TargetID, TargetID: e.SourceID, Weight: e.Weight})
+	}
+	if got := DetectCommunities(reversed, 1.0, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectCommunities() with reversed edges = %v, want %v", got, want)
+	}
+}
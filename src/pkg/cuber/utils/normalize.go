@@ -341,6 +341,28 @@ func NormalizeForSearch(text string) string {
 	return strings.TrimSpace(text)
 }
 
+// NormalizeForEntityMatch は、エンティティ名の表記ゆれを吸収した照合用のキーを返します。
+// NormalizeForSearch に加えて、ひらがなをカタカナに揃え、空白と中黒（・）を除去します。
+// 例: "ﾄﾖﾀ 自動車"、"とよた自動車"、"トヨタ・自動車" は同じキーになります。
+func NormalizeForEntityMatch(text string) string {
+	text = NormalizeForSearch(text)
+	if text == "" {
+		return ""
+	}
+	var buf strings.Builder
+	for _, r := range text {
+		switch {
+		case r == ' ' || r == '・':
+			continue
+		case r >= 'ぁ' && r <= 'ゖ':
+			buf.WriteRune(r + ('ァ' - 'ぁ'))
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
 func transformWidth(text string, t transform.Transformer) string {
 	res, _, _ := transform.String(t, text)
 	return res
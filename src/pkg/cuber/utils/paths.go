@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"container/heap"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ScoredPath は、KShortestPaths が返す経路です。
+type ScoredPath struct {
+	NodeIDs   []string       // 起点から終点までのノードID
+	Triples   []ScoredTriple // 経路を構成するトリプル（起点側から順に並ぶ）
+	Thickness float64        // 経路上のエッジの Thickness の最小値
+}
+
+// pathGraph は、KShortestPaths で使用する無向グラフです。
+type pathGraph struct {
+	triples []ScoredTriple
+	adj     map[string][]int // ノードID -> 接続するトリプルのインデックス
+	cost    []float64        // トリプルごとのコスト
+}
+
+// pathCandidate は、Yen のアルゴリズムで経路を表します。
+type pathCandidate struct {
+	nodes []string
+	edges []int
+	cost  float64
+}
+
+// key は、経路を一意に識別する文字列を返します。
+func (p *pathCandidate) key() string {
+	var sb strings.Builder
+	for _, e := range p.edges {
+		sb.WriteString(strconv.Itoa(e))
+		sb.WriteString(",")
+	}
+	return sb.String()
+}
+
+// KShortestPaths は、Yen のアルゴリズムで、起点から終点までの単純経路（同じノードを2度通らない経路）を最大 k 件返します。
+// エッジの向きは無視して辿ります。同じノード間に複数のエッジがある場合、エッジごとに別の経路として扱います。
+//
+// 経路はホップ数の少ない順に並び、ホップ数が同じ経路は Thickness の大きいエッジを通るものが優先されます。
+// そのため、各エッジのコストを「1 + (1 - Thickness) / (maxHops + 1)」とします。
+// Thickness による加算分は maxHops ホップの経路でも 1 未満になるため、ホップ数の大小が逆転することはありません。
+//
+// 引数:
+//   - triples: 辿ることのできるトリプル（Thickness は 0.0〜1.0）
+//   - sourceID: 起点のノードID
+//   - targetID: 終点のノードID
+//   - k: 返す経路の最大数
+//   - maxHops: 経路のホップ数の上限
+//
+// 返り値:
+//   - []ScoredPath: 経路（経路がない場合は空）
+func KShortestPaths(triples []ScoredTriple, sourceID, targetID string, k, maxHops int) []ScoredPath {
+	if k <= 0 || maxHops <= 0 || sourceID == targetID {
+		return nil
+	}
+	g := &pathGraph{
+		triples: triples,
+		adj:     make(map[string][]int),
+		cost:    make([]float64, len(triples)),
+	}
+	for i, st := range triples {
+		s, t := st.Triple.Source.ID, st.Triple.Target.ID
+		if s == t {
+			continue
+		}
+		g.adj[s] = append(g.adj[s], i)
+		g.adj[t] = append(g.adj[t], i)
+		g.cost[i] = 1 + (1-min(max(st.Thickness, 0), 1))/float64(maxHops+1)
+	}
+	first := g.shortestPath(sourceID, targetID, nil, nil)
+	if first == nil || len(first.edges) > maxHops {
+		return nil
+	}
+	found := []*pathCandidate{first}
+	seen := map[string]bool{first.key(): true}
+	var candidates []*pathCandidate
+	for len(found) < k {
+		prev := found[len(found)-1]
+		for i := 0; i < len(prev.edges); i++ {
+			spurNode := prev.nodes[i]
+			rootEdges := prev.edges[:i]
+			// 同じ根の経路で既に使われた、分岐点からのエッジを除外する
+			removedEdges := make(map[int]bool)
+			for _, p := range found {
+				if len(p.edges) > i && slices.Equal(p.edges[:i], rootEdges) {
+					removedEdges[p.edges[i]] = true
+				}
+			}
+			// 根の経路上のノード（分岐点を除く）を除外する
+			removedNodes := make(map[string]bool, i)
+			for _, n := range prev.nodes[:i] {
+				removedNodes[n] = true
+			}
+			spur := g.shortestPath(spurNode, targetID, removedNodes, removedEdges)
+			if spur == nil || i+len(spur.edges) > maxHops {
+				continue
+			}
+			candidate := &pathCandidate{
+				nodes: append(slices.Clone(prev.nodes[:i]), spur.nodes...),
+				edges: append(slices.Clone(rootEdges), spur.edges...),
+			}
+			for _, e := range candidate.edges {
+				candidate.cost += g.cost[e]
+			}
+			if key := candidate.key(); !seen[key] {
+				seen[key] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		// コストの最も小さい候補を採用する（同じコストの場合はキー順にして結果を決定的にする）
+		best := 0
+		for j, c := range candidates {
+			if c.cost < candidates[best].cost || (c.cost == candidates[best].cost && c.key() < candidates[best].key()) {
+				best = j
+			}
+		}
+		found = append(found, candidates[best])
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+	paths := make([]ScoredPath, 0, len(found))
+	for _, p := range found {
+		path := ScoredPath{NodeIDs: p.nodes, Thickness: 1}
+		for _, e := range p.edges {
+			path.Triples = append(path.Triples, g.triples[e])
+			path.Thickness = min(path.Thickness, g.triples[e].Thickness)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// shortestPath は、除外されたノードとエッジを通らない最小コストの経路を Dijkstra 法で求めます。
+// 経路がない場合は nil を返します。
+func (g *pathGraph) shortestPath(sourceID, targetID string, removedNodes map[string]bool, removedEdges map[int]bool) *pathCandidate {
+	dist := map[string]float64{sourceID: 0}
+	prevEdge := make(map[string]int)
+	visited := make(map[string]bool)
+	pq := &pathQueue{{node: sourceID}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(pathQueueItem)
+		if visited[item.node] {
+			continue
+		}
+		visited[item.node] = true
+		if item.node == targetID {
+			break
+		}
+		for _, e := range g.adj[item.node] {
+			if removedEdges[e] {
+				continue
+			}
+			next := g.triples[e].Triple.Target.ID
+			if next == item.node {
+				next = g.triples[e].Triple.Source.ID
+			}
+			if visited[next] || removedNodes[next] {
+				continue
+			}
+			d := item.dist + g.cost[e]
+			if cur, ok := dist[next]; !ok || d < cur {
+				dist[next] = d
+				prevEdge[next] = e
+				heap.Push(pq, pathQueueItem{node: next, dist: d})
+			}
+		}
+	}
+	if !visited[targetID] {
+		return nil
+	}
+	// 終点から起点へ辿って経路を組み立てる
+	p := &pathCandidate{nodes: []string{targetID}, cost: dist[targetID]}
+	for node := targetID; node != sourceID; {
+		e := prevEdge[node]
+		p.edges = append(p.edges, e)
+		if g.triples[e].Triple.Source.ID == node {
+			node = g.triples[e].Triple.Target.ID
+		} else {
+			node = g.triples[e].Triple.Source.ID
+		}
+		p.nodes = append(p.nodes, node)
+	}
+	slices.Reverse(p.nodes)
+	slices.Reverse(p.edges)
+	return p
+}
+
+// pathQueueItem は、Dijkstra 法の優先度付きキューの要素です。
+type pathQueueItem struct {
+	node string
+	dist float64
+}
+
+// pathQueue は、距離の小さい順に取り出す優先度付きキューです（container/heap で使用）。
+type pathQueue []pathQueueItem
+
+func (q pathQueue) Len() int { return len(q) }
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].node < q[j].node
+}
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x any)   { *q = append(*q, x.(pathQueueItem)) }
+func (q *pathQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
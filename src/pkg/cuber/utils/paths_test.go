@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/t-kawata/mycute/pkg/cuber/storage"
+)
+
+// testTriple は、source から target へのエッジを持つトリプルを作成します。
+func testTriple(source, edgeType, target string, thickness float64) ScoredTriple {
+	return ScoredTriple{
+		Triple: &storage.Triple{
+			Source: &storage.Node{ID: source},
+			Edge:   &storage.Edge{SourceID: source, TargetID: target, Type: edgeType},
+			Target: &storage.Node{ID: target},
+		},
+		Thickness: thickness,
+	}
+}
+
+// pathNodeIDs は、経路ごとのノードIDを返します。
+func pathNodeIDs(paths []ScoredPath) [][]string {
+	var ids [][]string
+	for _, p := range paths {
+		ids = append(ids, p.NodeIDs)
+	}
+	return ids
+}
+
+func TestKShortestPathsOrder(t *testing.T) {
+	triples := []ScoredTriple{
+		testTriple("A", "R", "E", 1.0),
+		testTriple("E", "R", "F", 1.0),
+		testTriple("F", "R", "C", 1.0),
+		testTriple("A", "R", "D", 1.0),
+		testTriple("D", "R", "C", 0.5),
+		testTriple("A", "R", "B", 0.9),
+		testTriple("B", "R", "C", 0.9),
+	}
+	paths := KShortestPaths(triples, "A", "C", 5, 3)
+	// ホップ数の少ない順。ホップ数が同じ場合は Thickness の大きいエッジを通る経路が先
+	want := [][]string{{"A", "B", "C"}, {"A", "D", "C"}, {"A", "E", "F", "C"}}
+	if got := pathNodeIDs(paths); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Unexpected paths: %v, want %v", got, want)
+	}
+	for i, wantThickness := range []float64{0.9, 0.5, 1.0} {
+		if paths[i].Thickness != wantThickness {
+			t.Errorf("Unexpected thickness of path %d: %v, want %v", i, paths[i].Thickness, wantThickness)
+		}
+		if len(paths[i].Triples) != len(paths[i].NodeIDs)-1 {
+			t.Errorf("Unexpected triples of path %d: %d", i, len(paths[i].Triples))
+		}
+	}
+	// ホップ数の上限を超える経路は返さない
+	if got := pathNodeIDs(KShortestPaths(triples, "A", "C", 5, 2)); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Unexpected paths with maxHops 2: %v", got)
+	}
+	// 返す経路は最大 k 件
+	if got := pathNodeIDs(KShortestPaths(triples, "A", "C", 1, 3)); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("Unexpected paths with k 1: %v", got)
+	}
+}
+
+func TestKShortestPathsTieBreaking(t *testing.T) {
+	// コストが同じ経路は、入力の順序によらず同じ順序で返す
+	triples := []ScoredTriple{
+		testTriple("A", "R", "X", 0.8),
+		testTriple("X", "R", "C", 0.8),
+		testTriple("A", "R", "Y", 0.8),
+		testTriple("Y", "R", "C", 0.8),
+		testTriple("A", "R", "Z", 0.8),
+		testTriple("Z", "R", "C", 0.8),
+	}
+	want := [][]string{{"A", "X", "C"}, {"A", "Y", "C"}, {"A", "Z", "C"}}
+	if got := pathNodeIDs(KShortestPaths(triples, "A", "C", 3, 2)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected paths: %v, want %v", got, want)
+	}
+	reversed := slices.Clone(triples)
+	slices.Reverse(reversed)
+	if got := pathNodeIDs(KShortestPaths(reversed, "A", "C", 3, 2)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected paths for reversed input: %v, want %v", got, want)
+	}
+}
+
+func TestKShortestPathsParallelEdgesAndDirection(t *testing.T) {
+	// 同じノード間の複数のエッジは別の経路になり、エッジの向きは無視して辿る
+	triples := []ScoredTriple{
+		testTriple("A", "KNOWS", "B", 0.4),
+		testTriple("A", "WORKS_WITH", "B", 0.9),
+		testTriple("C", "MANAGES", "B", 1.0),
+	}
+	paths := KShortestPaths(triples, "A", "C", 3, 2)
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 paths, got %v", pathNodeIDs(paths))
+	}
+	for i, wantType := range []string{"WORKS_WITH", "KNOWS"} {
+		if got := paths[i].Triples[0].Triple.Edge.Type; got != wantType {
+			t.Errorf("Unexpected first edge of path %d: %s, want %s", i, got, wantType)
+		}
+		// トリプルは保存されている向きのまま返す
+		if last := paths[i].Triples[1].Triple; last.Source.ID != "C" || last.Target.ID != "B" {
+			t.Errorf("Unexpected direction of path %d: %s -> %s", i, last.Source.ID, last.Target.ID)
+		}
+	}
+}
+
+func TestKShortestPathsNoPath(t *testing.T) {
+	triples := []ScoredTriple{
+		testTriple("A", "R", "B", 1.0),
+		testTriple("C", "R", "C", 1.0), // 自己ループは辿らない
+		testTriple("C", "R", "D", 1.0),
+	}
+	tests := []struct {
+		name           string
+		source, target string
+		k, maxHops     int
+	}{
+		{"disconnected", "A", "C", 3, 3},
+		{"same node", "A", "A", 3, 3},
+		{"zero k", "A", "B", 0, 3},
+		{"zero max hops", "A", "B", 3, 0},
+		{"unknown node", "A", "Z", 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KShortestPaths(triples, tt.source, tt.target, tt.k, tt.maxHops); len(got) != 0 {
+				t.Errorf("Expected no paths, got %v", pathNodeIDs(got))
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ func SearchCubesSQL(condsStr *string, isRead *bool) *string {
 		"    c1.`embedding_base_url`, " +
 		"    c1.`embedding_model`, " +
 		"    c1.`embedding_dimension`, " +
+		"    c1.`chat_model_fallbacks`, " +
 		"    0 AS dummy " +
 		"FROM " +
 		"    `cubes` AS c1 " +